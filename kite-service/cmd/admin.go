@@ -8,7 +8,9 @@ import (
 	"github.com/kitecloud/kite/kite-service/internal/api/session"
 	"github.com/kitecloud/kite/kite-service/internal/config"
 	"github.com/kitecloud/kite/kite-service/internal/db/postgres"
+	"github.com/kitecloud/kite/kite-service/internal/db/sqlite"
 	"github.com/kitecloud/kite/kite-service/internal/logging"
+	"github.com/kitecloud/kite/kite-service/internal/store"
 	"github.com/urfave/cli/v2"
 )
 
@@ -38,16 +40,31 @@ func adminImpersonateCMD(c *cli.Context) error {
 
 	logging.SetupLogger(cfg.Logging)
 
-	pg, err := postgres.New(postgres.BuildConnectionDSN(cfg.Database.Postgres), 1)
-	if err != nil {
-		slog.Error(
-			"Failed to create postgres client",
-			slog.String("error", err.Error()),
-		)
-		return fmt.Errorf("failed to create postgres client: %w", err)
+	var sessionStore store.SessionStore
+	if cfg.Database.Type == "sqlite" {
+		client, err := sqlite.New(cfg.Database.SQLite)
+		if err != nil {
+			slog.Error(
+				"Failed to create sqlite client",
+				slog.String("error", err.Error()),
+			)
+			return fmt.Errorf("failed to create sqlite client: %w", err)
+		}
+		defer client.Close()
+		sessionStore = client
+	} else {
+		pg, err := postgres.New(postgres.BuildConnectionDSN(cfg.Database.Postgres), 1)
+		if err != nil {
+			slog.Error(
+				"Failed to create postgres client",
+				slog.String("error", err.Error()),
+			)
+			return fmt.Errorf("failed to create postgres client: %w", err)
+		}
+		sessionStore = pg
 	}
 
-	sessionManager := session.NewSessionManager(session.SessionManagerConfig{}, pg)
+	sessionManager := session.NewSessionManager(session.SessionManagerConfig{}, sessionStore)
 
 	key, _, err := sessionManager.CreateSession(context.Background(), c.String("user_id"))
 	if err != nil {
@@ -7,7 +7,10 @@ import (
 	"github.com/urfave/cli/v2"
 )
 
-var databases = []string{"postgres"}
+var databases = []string{"postgres", "sqlite"}
+
+// backupDatabases are the databases that support backups to S3.
+var backupDatabases = []string{"postgres"}
 
 var databaseCMD cli.Command
 
@@ -108,7 +111,7 @@ func init() {
 	}
 
 	backupCommands := []*cli.Command{}
-	for _, db := range databases {
+	for _, db := range backupDatabases {
 		backupCommands = append(backupCommands, &cli.Command{
 			Name:  db,
 			Usage: fmt.Sprintf("Backup the %s database.", db),
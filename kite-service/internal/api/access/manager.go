@@ -12,6 +12,8 @@ type AccessManager struct {
 	messageStore        store.MessageStore
 	eventListenerStore  store.EventListenerStore
 	pluginInstanceStore store.PluginInstanceStore
+	moduleStore         store.ModuleStore
+	namespaceStore      store.NamespaceStore
 	planManager         *plan.PlanManager
 }
 
@@ -22,6 +24,8 @@ func NewAccessManager(
 	messageStore store.MessageStore,
 	eventListenerStore store.EventListenerStore,
 	pluginInstanceStore store.PluginInstanceStore,
+	moduleStore store.ModuleStore,
+	namespaceStore store.NamespaceStore,
 	planManager *plan.PlanManager,
 ) *AccessManager {
 	return &AccessManager{
@@ -31,6 +35,8 @@ func NewAccessManager(
 		messageStore:        messageStore,
 		eventListenerStore:  eventListenerStore,
 		pluginInstanceStore: pluginInstanceStore,
+		moduleStore:         moduleStore,
+		namespaceStore:      namespaceStore,
 		planManager:         planManager,
 	}
 }
@@ -156,3 +156,78 @@ func (m *AccessManager) PluginInstanceAccess(next handler.HandlerFunc) handler.H
 		return next(c)
 	}
 }
+
+func (m *AccessManager) ModuleAccess(next handler.HandlerFunc) handler.HandlerFunc {
+	return func(c *handler.Context) error {
+		moduleID := c.Param("moduleID")
+		appID := c.Param("appID")
+
+		module, err := m.moduleStore.ModuleMeta(c.Context(), moduleID)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return handler.ErrNotFound("unknown_module", "Module not found")
+			}
+			return err
+		}
+
+		// We assume that app access has already been checked
+		if module.AppID != appID {
+			return handler.ErrForbidden("missing_access", "Access to module missing")
+		}
+
+		c.Module = module
+		return next(c)
+	}
+}
+
+// NamespaceAccess only grants access to the owner and the members of the namespace.
+// Apps that have been granted access to a namespace can only use its variables in flows.
+func (m *AccessManager) NamespaceAccess(next handler.HandlerFunc) handler.HandlerFunc {
+	return func(c *handler.Context) error {
+		namespaceID := c.Param("namespaceID")
+
+		namespace, err := m.namespaceStore.Namespace(c.Context(), namespaceID)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return handler.ErrNotFound("unknown_namespace", "Namespace not found")
+			}
+			return err
+		}
+
+		if namespace.OwnerUserID != c.Session.UserID {
+			_, err := m.namespaceStore.NamespaceMember(c.Context(), namespace.ID, c.Session.UserID)
+			if err != nil {
+				if errors.Is(err, store.ErrNotFound) {
+					return handler.ErrForbidden("missing_access", "Access to namespace missing")
+				}
+				return err
+			}
+		}
+
+		c.Namespace = namespace
+		return next(c)
+	}
+}
+
+func (m *AccessManager) NamespaceVariableAccess(next handler.HandlerFunc) handler.HandlerFunc {
+	return func(c *handler.Context) error {
+		variableID := c.Param("variableID")
+		namespaceID := c.Param("namespaceID")
+
+		variable, err := m.variableStore.Variable(c.Context(), variableID)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return handler.ErrNotFound("unknown_variable", "Variable not found")
+			}
+			return err
+		}
+
+		// We assume that namespace access has already been checked
+		if variable.NamespaceID.String != namespaceID {
+			return handler.ErrForbidden("missing_access", "Access to variable missing")
+		}
+
+		c.Variable = variable
+		return next(c)
+	}
+}
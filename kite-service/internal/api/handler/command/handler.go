@@ -14,19 +14,23 @@ import (
 	"github.com/kitecloud/kite/kite-service/internal/store"
 	"github.com/kitecloud/kite/kite-service/internal/util"
 	"github.com/kitecloud/kite/kite-service/pkg/flow"
+	"gopkg.in/guregu/null.v4"
 )
 
 type CommandHandler struct {
 	commandStore   store.CommandStore
+	moduleStore    store.ModuleStore
 	commandManager *command.CommandManager
 }
 
 func NewCommandHandler(
 	commandStore store.CommandStore,
+	moduleStore store.ModuleStore,
 	commandManager *command.CommandManager,
 ) *CommandHandler {
 	return &CommandHandler{
 		commandStore:   commandStore,
+		moduleStore:    moduleStore,
 		commandManager: commandManager,
 	}
 }
@@ -66,11 +70,16 @@ func (h *CommandHandler) HandleCommandCreate(c *handler.Context, req wire.Comman
 		return nil, fmt.Errorf("failed to compile command: %w", err)
 	}
 
+	if err := h.checkModule(c, req.ModuleID); err != nil {
+		return nil, err
+	}
+
 	command, err := h.commandStore.CreateCommand(c.Context(), &model.Command{
 		ID:            util.UniqueID(),
 		Name:          cmdFlow.CommandName(),
 		Description:   cmdFlow.CommandDescription(),
 		AppID:         c.App.ID,
+		ModuleID:      req.ModuleID,
 		CreatorUserID: c.Session.UserID,
 		FlowSource:    req.FlowSource,
 		Enabled:       req.Enabled,
@@ -105,11 +114,16 @@ func (h *CommandHandler) HandleCommandsImport(c *handler.Context, req wire.Comma
 			return nil, fmt.Errorf("failed to compile command: %w", err)
 		}
 
+		if err := h.checkModule(c, cmd.ModuleID); err != nil {
+			return nil, err
+		}
+
 		command, err := h.commandStore.CreateCommand(c.Context(), &model.Command{
 			ID:            util.UniqueID(),
 			Name:          cmdFlow.CommandName(),
 			Description:   cmdFlow.CommandDescription(),
 			AppID:         c.App.ID,
+			ModuleID:      cmd.ModuleID,
 			CreatorUserID: c.Session.UserID,
 			FlowSource:    cmd.FlowSource,
 			Enabled:       cmd.Enabled,
@@ -132,11 +146,16 @@ func (h *CommandHandler) HandleCommandUpdate(c *handler.Context, req wire.Comman
 		return nil, fmt.Errorf("failed to compile command: %w", err)
 	}
 
+	if err := h.checkModule(c, req.ModuleID); err != nil {
+		return nil, err
+	}
+
 	command, err := h.commandStore.UpdateCommand(c.Context(), &model.Command{
 		ID:          c.Command.ID,
 		Name:        cmdFlow.CommandName(),
 		Description: cmdFlow.CommandDescription(),
 		FlowSource:  req.FlowSource,
+		ModuleID:    req.ModuleID,
 		Enabled:     req.Enabled,
 		UpdatedAt:   time.Now().UTC(),
 	})
@@ -156,6 +175,7 @@ func (h *CommandHandler) HandleCommandUpdateEnabled(c *handler.Context, req wire
 		Name:        c.Command.Name,
 		Description: c.Command.Description,
 		FlowSource:  c.Command.FlowSource,
+		ModuleID:    c.Command.ModuleID,
 		Enabled:     req.Enabled,
 		UpdatedAt:   time.Now().UTC(),
 	})
@@ -181,6 +201,27 @@ func (h *CommandHandler) HandleCommandDelete(c *handler.Context) (*wire.CommandD
 	return &wire.CommandDeleteResponse{}, nil
 }
 
+// checkModule makes sure that the module that should handle a command belongs to the app.
+func (h *CommandHandler) checkModule(c *handler.Context, moduleID null.String) error {
+	if !moduleID.Valid {
+		return nil
+	}
+
+	module, err := h.moduleStore.ModuleMeta(c.Context(), moduleID.String)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return handler.ErrNotFound("unknown_module", "Module not found")
+		}
+		return fmt.Errorf("failed to get module: %w", err)
+	}
+
+	if module.AppID != c.App.ID {
+		return handler.ErrNotFound("unknown_module", "Module not found")
+	}
+
+	return nil
+}
+
 func (h *CommandHandler) HandleCommandsDeploy(c *handler.Context) (*wire.CommandsDeployResponse, error) {
 	err := h.commandManager.DeployCommandsForApp(c.Context(), c.App.ID)
 	if err != nil {
@@ -23,6 +23,8 @@ type Context struct {
 	Message        *model.Message
 	EventListener  *model.EventListener
 	PluginInstance *model.PluginInstance
+	Module         *model.Module
+	Namespace      *model.Namespace
 }
 
 func (c *Context) Context() context.Context {
@@ -88,6 +90,10 @@ func (c *Context) FormFile(name string) (multipart.File, *multipart.FileHeader,
 	return c.r.FormFile(name)
 }
 
+func (c *Context) FormValue(name string) string {
+	return c.r.FormValue(name)
+}
+
 func (c *Context) JSON(status int, v interface{}) error {
 	c.w.Header().Set("Content-Type", "application/json")
 	c.w.WriteHeader(status)
@@ -88,7 +88,7 @@ func (g HandlerGroup) Handle(method string, path string, f HandlerFunc, middlewa
 	pattern := fmt.Sprintf("%s %s%s", method, g.pathPrefix, path)
 	pattern = strings.TrimRight(pattern, "/")
 
-	g.mux.Handle(pattern, APIHandler(f))
+	g.mux.Handle(pattern, instrumentHandler(pattern, APIHandler(f)))
 }
 
 func (g HandlerGroup) Get(path string, f HandlerFunc, middlewares ...MiddlewareFunc) {
@@ -0,0 +1,100 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/api/handler"
+)
+
+// readyCacheDuration is how long the result of the health checks is reused.
+// The ready route is public, so it must not allow hammering the dependencies.
+const readyCacheDuration = 5 * time.Second
+
+// HealthCheck returns an error if the checked dependency is unavailable.
+type HealthCheck func(ctx context.Context) error
+
+type HealthHandler struct {
+	checks map[string]HealthCheck
+
+	readyMu        sync.Mutex
+	ready          bool
+	readyCheckedAt time.Time
+}
+
+func NewHealthHandler(checks map[string]HealthCheck) *HealthHandler {
+	return &HealthHandler{
+		checks: checks,
+	}
+}
+
+type readyResponse struct {
+	Ready bool `json:"ready"`
+}
+
+// HandleLive reports that the process is running, it doesn't check any dependencies.
+func (h *HealthHandler) HandleLive(c *handler.Context) error {
+	return c.Send(http.StatusOK, []byte("OK"))
+}
+
+// HandleReady reports ready when all health checks succeed.
+// Only the status is returned, the errors of failed checks are logged instead.
+func (h *HealthHandler) HandleReady(c *handler.Context) error {
+	res := readyResponse{
+		Ready: h.isReady(),
+	}
+
+	if !res.Ready {
+		return c.JSON(http.StatusServiceUnavailable, res)
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// isReady returns the cached result of the health checks or runs them when the result is too old.
+// Concurrent requests wait for the running checks instead of starting their own.
+func (h *HealthHandler) isReady() bool {
+	h.readyMu.Lock()
+	defer h.readyMu.Unlock()
+
+	if time.Since(h.readyCheckedAt) < readyCacheDuration {
+		return h.ready
+	}
+
+	h.ready = h.runChecks()
+	h.readyCheckedAt = time.Now()
+	return h.ready
+}
+
+func (h *HealthHandler) runChecks() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ready := true
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := check(ctx); err != nil {
+				slog.Error(
+					"Health check failed",
+					slog.String("check", name),
+					slog.String("error", err.Error()),
+				)
+
+				mu.Lock()
+				defer mu.Unlock()
+				ready = false
+			}
+		}()
+	}
+	wg.Wait()
+
+	return ready
+}
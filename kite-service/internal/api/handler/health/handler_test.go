@@ -0,0 +1,38 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadyIsCached(t *testing.T) {
+	var calls atomic.Int32
+	var fail atomic.Bool
+
+	h := NewHealthHandler(map[string]HealthCheck{
+		"db": func(ctx context.Context) error {
+			calls.Add(1)
+			if fail.Load() {
+				return errors.New("connection refused")
+			}
+			return nil
+		},
+	})
+
+	assert.True(t, h.isReady())
+	assert.True(t, h.isReady())
+	assert.Equal(t, int32(1), calls.Load())
+
+	// A failing check is only noticed once the cached result has expired
+	fail.Store(true)
+	assert.True(t, h.isReady())
+
+	h.readyCheckedAt = time.Now().Add(-readyCacheDuration)
+	assert.False(t, h.isReady())
+	assert.Equal(t, int32(2), calls.Load())
+}
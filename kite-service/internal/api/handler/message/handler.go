@@ -3,10 +3,12 @@ package message
 import (
 	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/kitecloud/kite/kite-service/internal/api/handler"
 	"github.com/kitecloud/kite/kite-service/internal/api/wire"
+	"github.com/kitecloud/kite/kite-service/internal/core/messagesync"
 	"github.com/kitecloud/kite/kite-service/internal/model"
 	"github.com/kitecloud/kite/kite-service/internal/store"
 	"github.com/kitecloud/kite/kite-service/internal/util"
@@ -17,6 +19,7 @@ type MessageHandler struct {
 	messageInstanceStore store.MessageInstanceStore
 	assetStore           store.AssetStore
 	appStateManager      store.AppStateManager
+	syncManager          *messagesync.SyncManager
 }
 
 func NewMessageHandler(
@@ -24,12 +27,14 @@ func NewMessageHandler(
 	messageInstanceStore store.MessageInstanceStore,
 	assetStore store.AssetStore,
 	appStateManager store.AppStateManager,
+	syncManager *messagesync.SyncManager,
 ) *MessageHandler {
 	return &MessageHandler{
 		messageStore:         messageStore,
 		messageInstanceStore: messageInstanceStore,
 		assetStore:           assetStore,
 		appStateManager:      appStateManager,
+		syncManager:          syncManager,
 	}
 }
 
@@ -120,6 +125,10 @@ func (h *MessageHandler) HandleMessagesImport(c *handler.Context, req wire.Messa
 }
 
 func (h *MessageHandler) HandleMessageUpdate(c *handler.Context, req wire.MessageUpdateRequest) (*wire.MessageUpdateResponse, error) {
+	if req.SyncInstances && hasGeneratedAttachments(req.Data.Attachments) {
+		return nil, errGeneratedAttachment
+	}
+
 	message, err := h.messageStore.UpdateMessage(c.Context(), &model.Message{
 		ID:          c.Message.ID,
 		Name:        req.Name,
@@ -136,6 +145,18 @@ func (h *MessageHandler) HandleMessageUpdate(c *handler.Context, req wire.Messag
 		return nil, fmt.Errorf("failed to update message: %w", err)
 	}
 
+	if req.SyncInstances {
+		// The message has already been updated, so we don't want to fail the request here
+		if _, err := h.syncManager.StartSync(c.Context(), c.App.ID, message.ID); err != nil {
+			slog.Error(
+				"Failed to start message instance sync",
+				slog.String("app_id", c.App.ID),
+				slog.String("message_id", message.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
 	return wire.MessageToWire(message), nil
 }
 
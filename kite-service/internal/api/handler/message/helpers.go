@@ -6,13 +6,21 @@ import (
 	"fmt"
 
 	"github.com/diamondburned/arikawa/v3/utils/sendpart"
+	"github.com/kitecloud/kite/kite-service/internal/api/handler"
 	"github.com/kitecloud/kite/kite-service/pkg/message"
 )
 
+var errGeneratedAttachment = handler.ErrBadRequest("generated_attachment", "Messages with generated attachments can only be sent from flows")
+
 func (h *MessageHandler) attachmentsToFiles(ctx context.Context, attachments []message.MessageAttachment) ([]sendpart.File, error) {
 	res := make([]sendpart.File, 0, len(attachments))
 
 	for _, attachment := range attachments {
+		if attachment.IsGenerated() {
+			// Generated files can only be created inside flows
+			return nil, errGeneratedAttachment
+		}
+
 		asset, err := h.assetStore.AssetWithContent(ctx, attachment.AssetID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get asset: %w", err)
@@ -26,3 +34,12 @@ func (h *MessageHandler) attachmentsToFiles(ctx context.Context, attachments []m
 
 	return res, nil
 }
+
+func hasGeneratedAttachments(attachments []message.MessageAttachment) bool {
+	for _, attachment := range attachments {
+		if attachment.IsGenerated() {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package message
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
@@ -8,6 +9,7 @@ import (
 	"github.com/diamondburned/arikawa/v3/discord"
 	"github.com/kitecloud/kite/kite-service/internal/api/handler"
 	"github.com/kitecloud/kite/kite-service/internal/api/wire"
+	"github.com/kitecloud/kite/kite-service/internal/core/messagesync"
 	"github.com/kitecloud/kite/kite-service/internal/model"
 	"github.com/kitecloud/kite/kite-service/internal/store"
 	"github.com/kitecloud/kite/kite-service/pkg/message"
@@ -119,3 +121,31 @@ func (h *MessageHandler) HandleMessageInstanceDelete(c *handler.Context) (*wire.
 
 	return &wire.MessageInstanceDeleteResponse{}, nil
 }
+
+func (h *MessageHandler) HandleMessageInstanceSyncStart(c *handler.Context) (*wire.MessageInstanceSyncStartResponse, error) {
+	if hasGeneratedAttachments(c.Message.Data.Attachments) {
+		return nil, errGeneratedAttachment
+	}
+
+	job, err := h.syncManager.StartSync(c.Context(), c.App.ID, c.Message.ID)
+	if err != nil {
+		if errors.Is(err, messagesync.ErrSyncQueueFull) {
+			return nil, handler.ErrRateLimit("Too many message syncs are queued, try again later")
+		}
+		return nil, fmt.Errorf("failed to start message instance sync: %w", err)
+	}
+
+	return wire.MessageSyncJobToWire(job), nil
+}
+
+func (h *MessageHandler) HandleMessageInstanceSyncGet(c *handler.Context) (*wire.MessageInstanceSyncGetResponse, error) {
+	job, err := h.syncManager.Job(c.Context(), c.Message.ID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, handler.ErrNotFound("message_sync_not_found", "message instance sync not found")
+		}
+		return nil, fmt.Errorf("failed to get message instance sync: %w", err)
+	}
+
+	return wire.MessageSyncJobToWire(job), nil
+}
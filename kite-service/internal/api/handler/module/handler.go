@@ -0,0 +1,175 @@
+package module
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/api/handler"
+	"github.com/kitecloud/kite/kite-service/internal/api/wire"
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/internal/util"
+	"github.com/kitecloud/kite/kite-service/pkg/wasm"
+)
+
+type ModuleHandlerConfig struct {
+	MaxModuleSize    int64
+	MaxModulesPerApp int
+}
+
+type ModuleHandler struct {
+	config      ModuleHandlerConfig
+	moduleStore store.ModuleStore
+	runtime     *wasm.Runtime
+}
+
+func NewModuleHandler(moduleStore store.ModuleStore, runtime *wasm.Runtime, config ModuleHandlerConfig) *ModuleHandler {
+	return &ModuleHandler{
+		config:      config,
+		moduleStore: moduleStore,
+		runtime:     runtime,
+	}
+}
+
+func (h *ModuleHandler) HandleModuleList(c *handler.Context) (*wire.ModuleListResponse, error) {
+	modules, err := h.moduleStore.ModulesByApp(c.Context(), c.App.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get modules: %w", err)
+	}
+
+	res := make([]*wire.Module, len(modules))
+	for i, module := range modules {
+		res[i] = wire.ModuleToWire(module)
+	}
+
+	return &res, nil
+}
+
+func (h *ModuleHandler) HandleModuleGet(c *handler.Context) (*wire.ModuleGetResponse, error) {
+	return wire.ModuleToWire(c.Module), nil
+}
+
+func (h *ModuleHandler) HandleModuleCreate(c *handler.Context) (*wire.ModuleCreateResponse, error) {
+	if h.config.MaxModulesPerApp != 0 {
+		moduleCount, err := h.moduleStore.CountModulesByApp(c.Context(), c.App.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count modules: %w", err)
+		}
+
+		if moduleCount >= h.config.MaxModulesPerApp {
+			return nil, handler.ErrBadRequest("resource_limit", fmt.Sprintf("maximum number of modules (%d) reached", h.config.MaxModulesPerApp))
+		}
+	}
+
+	wasmBytes, err := h.readWasmFile(c)
+	if err != nil {
+		return nil, err
+	}
+
+	req := wire.ModuleUpdateRequest{
+		Name:        c.FormValue("name"),
+		Description: c.FormValue("description"),
+		Enabled:     true,
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	module, err := h.moduleStore.CreateModule(c.Context(), &model.Module{
+		ID:            util.UniqueID(),
+		Name:          req.Name,
+		Description:   req.Description,
+		Enabled:       req.Enabled,
+		AppID:         c.App.ID,
+		CreatorUserID: c.Session.UserID,
+		WasmBytes:     wasmBytes,
+		WasmHash:      util.HashBytes(wasmBytes),
+		CreatedAt:     time.Now().UTC(),
+		UpdatedAt:     time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create module: %w", err)
+	}
+
+	return wire.ModuleToWire(module), nil
+}
+
+func (h *ModuleHandler) HandleModuleUpdate(c *handler.Context, req wire.ModuleUpdateRequest) (*wire.ModuleUpdateResponse, error) {
+	module, err := h.moduleStore.UpdateModule(c.Context(), &model.Module{
+		ID:          c.Module.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		Enabled:     req.Enabled,
+		UpdatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, handler.ErrNotFound("unknown_module", "Module not found")
+		}
+		return nil, fmt.Errorf("failed to update module: %w", err)
+	}
+
+	return wire.ModuleToWire(module), nil
+}
+
+func (h *ModuleHandler) HandleModuleWasmUpdate(c *handler.Context) (*wire.ModuleWasmUpdateResponse, error) {
+	wasmBytes, err := h.readWasmFile(c)
+	if err != nil {
+		return nil, err
+	}
+
+	module, err := h.moduleStore.UpdateModuleWasm(c.Context(), &model.Module{
+		ID:        c.Module.ID,
+		WasmBytes: wasmBytes,
+		WasmHash:  util.HashBytes(wasmBytes),
+		UpdatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, handler.ErrNotFound("unknown_module", "Module not found")
+		}
+		return nil, fmt.Errorf("failed to update module binary: %w", err)
+	}
+
+	return wire.ModuleToWire(module), nil
+}
+
+func (h *ModuleHandler) HandleModuleDelete(c *handler.Context) (*wire.ModuleDeleteResponse, error) {
+	err := h.moduleStore.DeleteModule(c.Context(), c.Module.ID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, handler.ErrNotFound("unknown_module", "Module not found")
+		}
+		return nil, fmt.Errorf("failed to delete module: %w", err)
+	}
+
+	return &wire.ModuleDeleteResponse{}, nil
+}
+
+// readWasmFile reads the uploaded binary and makes sure that it can be executed by the runtime.
+func (h *ModuleHandler) readWasmFile(c *handler.Context) ([]byte, error) {
+	file, header, err := c.FormFile("file")
+	if err != nil {
+		return nil, handler.ErrBadRequest("invalid_form", "failed to get file from form")
+	}
+
+	if h.config.MaxModuleSize != 0 && header.Size > h.config.MaxModuleSize {
+		return nil, handler.ErrBadRequest(
+			"resource_limit",
+			fmt.Sprintf("file size exceeds maximum allowed size (%d)", h.config.MaxModuleSize),
+		)
+	}
+
+	wasmBytes, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read file: %w", err)
+	}
+
+	if err := h.runtime.Validate(c.Context(), wasmBytes); err != nil {
+		return nil, handler.ErrBadRequest("invalid_module", err.Error())
+	}
+
+	return wasmBytes, nil
+}
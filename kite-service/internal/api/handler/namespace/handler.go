@@ -0,0 +1,189 @@
+package namespace
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/api/handler"
+	"github.com/kitecloud/kite/kite-service/internal/api/wire"
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/internal/util"
+)
+
+type NamespaceHandlerConfig struct {
+	MaxNamespacesPerUser     int
+	MaxVariablesPerNamespace int
+	MaxMembersPerNamespace   int
+}
+
+type NamespaceHandler struct {
+	config             NamespaceHandlerConfig
+	namespaceStore     store.NamespaceStore
+	variableStore      store.VariableStore
+	variableValueStore store.VariableValueStore
+	userStore          store.UserStore
+}
+
+func NewNamespaceHandler(
+	namespaceStore store.NamespaceStore,
+	variableStore store.VariableStore,
+	variableValueStore store.VariableValueStore,
+	userStore store.UserStore,
+	config NamespaceHandlerConfig,
+) *NamespaceHandler {
+	return &NamespaceHandler{
+		config:             config,
+		namespaceStore:     namespaceStore,
+		variableStore:      variableStore,
+		variableValueStore: variableValueStore,
+		userStore:          userStore,
+	}
+}
+
+func (h *NamespaceHandler) HandleNamespaceList(c *handler.Context) (*wire.NamespaceListResponse, error) {
+	namespaces, err := h.namespaceStore.NamespacesByMember(c.Context(), c.Session.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespaces: %w", err)
+	}
+
+	res := make([]*wire.Namespace, len(namespaces))
+	for i, namespace := range namespaces {
+		res[i] = wire.NamespaceToWire(namespace)
+	}
+
+	return &res, nil
+}
+
+func (h *NamespaceHandler) HandleNamespaceGet(c *handler.Context) (*wire.NamespaceGetResponse, error) {
+	return wire.NamespaceToWire(c.Namespace), nil
+}
+
+func (h *NamespaceHandler) HandleNamespaceCreate(c *handler.Context, req wire.NamespaceCreateRequest) (*wire.NamespaceCreateResponse, error) {
+	if h.config.MaxNamespacesPerUser != 0 {
+		namespaces, err := h.namespaceStore.NamespacesByOwner(c.Context(), c.Session.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get namespaces: %w", err)
+		}
+
+		if len(namespaces) >= h.config.MaxNamespacesPerUser {
+			return nil, handler.ErrBadRequest("resource_limit", fmt.Sprintf("maximum number of namespaces (%d) reached", h.config.MaxNamespacesPerUser))
+		}
+	}
+
+	namespace, err := h.namespaceStore.CreateNamespace(c.Context(), &model.Namespace{
+		ID:          util.UniqueID(),
+		Name:        req.Name,
+		Description: req.Description,
+		OwnerUserID: c.Session.UserID,
+		CreatedAt:   time.Now().UTC(),
+		UpdatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create namespace: %w", err)
+	}
+
+	return wire.NamespaceToWire(namespace), nil
+}
+
+func (h *NamespaceHandler) HandleNamespaceUpdate(c *handler.Context, req wire.NamespaceUpdateRequest) (*wire.NamespaceUpdateResponse, error) {
+	namespace, err := h.namespaceStore.UpdateNamespace(c.Context(), &model.Namespace{
+		ID:          c.Namespace.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		UpdatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, handler.ErrNotFound("unknown_namespace", "Namespace not found")
+		}
+		return nil, fmt.Errorf("failed to update namespace: %w", err)
+	}
+
+	return wire.NamespaceToWire(namespace), nil
+}
+
+func (h *NamespaceHandler) HandleNamespaceDelete(c *handler.Context) (*wire.NamespaceDeleteResponse, error) {
+	if c.Namespace.OwnerUserID != c.Session.UserID {
+		return nil, handler.ErrForbidden("missing_permissions", "Only the owner can delete the namespace")
+	}
+
+	err := h.namespaceStore.DeleteNamespace(c.Context(), c.Namespace.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete namespace: %w", err)
+	}
+
+	return &wire.NamespaceDeleteResponse{}, nil
+}
+
+func (h *NamespaceHandler) HandleNamespaceGrantList(c *handler.Context) (*wire.NamespaceGrantListResponse, error) {
+	grants, err := h.namespaceStore.NamespaceGrantsByNamespace(c.Context(), c.Namespace.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace grants: %w", err)
+	}
+
+	res := make([]*wire.NamespaceGrant, len(grants))
+	for i, grant := range grants {
+		res[i] = wire.NamespaceGrantToWire(grant)
+	}
+
+	return &res, nil
+}
+
+// HandleNamespaceGrantUpdate grants the app access to the namespace.
+// The user must own or be a member of the namespace and have access to the app.
+func (h *NamespaceHandler) HandleNamespaceGrantUpdate(c *handler.Context, req wire.NamespaceGrantUpdateRequest) (*wire.NamespaceGrantUpdateResponse, error) {
+	grant, err := h.namespaceStore.UpsertNamespaceGrant(c.Context(), &model.NamespaceGrant{
+		NamespaceID: c.Namespace.ID,
+		AppID:       c.App.ID,
+		Access:      model.NamespaceAccess(req.Access),
+		CreatedAt:   time.Now().UTC(),
+		UpdatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update namespace grant: %w", err)
+	}
+
+	return wire.NamespaceGrantToWire(grant), nil
+}
+
+func (h *NamespaceHandler) HandleNamespaceGrantDelete(c *handler.Context) (*wire.NamespaceGrantDeleteResponse, error) {
+	err := h.namespaceStore.DeleteNamespaceGrant(c.Context(), c.Namespace.ID, c.App.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete namespace grant: %w", err)
+	}
+
+	return &wire.NamespaceGrantDeleteResponse{}, nil
+}
+
+// HandleSharedVariableList lists the variables of all namespaces that the app has been granted access to.
+func (h *NamespaceHandler) HandleSharedVariableList(c *handler.Context) (*wire.SharedVariableListResponse, error) {
+	grants, err := h.namespaceStore.NamespaceGrantsByApp(c.Context(), c.App.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace grants: %w", err)
+	}
+
+	res := []*wire.SharedVariable{}
+	for _, grant := range grants {
+		namespace, err := h.namespaceStore.Namespace(c.Context(), grant.NamespaceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get namespace: %w", err)
+		}
+
+		variables, err := h.variableStore.VariablesByNamespace(c.Context(), grant.NamespaceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get variables: %w", err)
+		}
+
+		for _, variable := range variables {
+			res = append(res, &wire.SharedVariable{
+				Variable:      wire.VariableToWire(variable),
+				NamespaceName: namespace.Name,
+				Access:        string(grant.Access),
+			})
+		}
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,87 @@
+package namespace
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/api/handler"
+	"github.com/kitecloud/kite/kite-service/internal/api/wire"
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+func (h *NamespaceHandler) HandleNamespaceMemberList(c *handler.Context) (*wire.NamespaceMemberListResponse, error) {
+	members, err := h.namespaceStore.NamespaceMembersByNamespace(c.Context(), c.Namespace.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace members: %w", err)
+	}
+
+	res := make([]*wire.NamespaceMember, len(members))
+	for i, member := range members {
+		res[i] = wire.NamespaceMemberToWire(member)
+	}
+
+	return &res, nil
+}
+
+// HandleNamespaceMemberCreate adds a user to the team of the namespace.
+// Only the owner can change the team.
+func (h *NamespaceHandler) HandleNamespaceMemberCreate(c *handler.Context, req wire.NamespaceMemberCreateRequest) (*wire.NamespaceMemberCreateResponse, error) {
+	if c.Namespace.OwnerUserID != c.Session.UserID {
+		return nil, handler.ErrForbidden("missing_permissions", "Only the owner can add members to the namespace")
+	}
+
+	if h.config.MaxMembersPerNamespace != 0 {
+		memberCount, err := h.namespaceStore.CountNamespaceMembersByNamespace(c.Context(), c.Namespace.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count namespace members: %w", err)
+		}
+
+		if memberCount >= h.config.MaxMembersPerNamespace {
+			return nil, handler.ErrBadRequest("resource_limit", fmt.Sprintf("maximum number of members (%d) reached", h.config.MaxMembersPerNamespace))
+		}
+	}
+
+	user, err := h.userStore.UserByDiscordID(c.Context(), req.DiscordUserID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, handler.ErrNotFound("unknown_user", "User not found")
+		}
+		return nil, err
+	}
+
+	if user.ID == c.Namespace.OwnerUserID {
+		return nil, handler.ErrBadRequest("cannot_add_owner", "Cannot add owner as member")
+	}
+
+	member, err := h.namespaceStore.CreateNamespaceMember(c.Context(), &model.NamespaceMember{
+		NamespaceID: c.Namespace.ID,
+		UserID:      user.ID,
+		CreatedAt:   time.Now().UTC(),
+		UpdatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create namespace member: %w", err)
+	}
+
+	member.User = user
+	return wire.NamespaceMemberToWire(member), nil
+}
+
+// HandleNamespaceMemberDelete removes a user from the team of the namespace.
+// Members can remove themselves, everyone else can only be removed by the owner.
+func (h *NamespaceHandler) HandleNamespaceMemberDelete(c *handler.Context) (*wire.NamespaceMemberDeleteResponse, error) {
+	userID := c.Param("userID")
+
+	if c.Namespace.OwnerUserID != c.Session.UserID && userID != c.Session.UserID {
+		return nil, handler.ErrForbidden("missing_permissions", "Only the owner can remove members from the namespace")
+	}
+
+	err := h.namespaceStore.DeleteNamespaceMember(c.Context(), c.Namespace.ID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete namespace member: %w", err)
+	}
+
+	return &wire.NamespaceMemberDeleteResponse{}, nil
+}
@@ -0,0 +1,119 @@
+package namespace
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/api/handler"
+	"github.com/kitecloud/kite/kite-service/internal/api/wire"
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/internal/util"
+	"gopkg.in/guregu/null.v4"
+)
+
+func (h *NamespaceHandler) HandleNamespaceVariableList(c *handler.Context) (*wire.VariableListResponse, error) {
+	variables, err := h.variableStore.VariablesByNamespace(c.Context(), c.Namespace.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variables: %w", err)
+	}
+
+	res := make([]*wire.Variable, len(variables))
+	for i, variable := range variables {
+		res[i] = wire.VariableToWire(variable)
+	}
+
+	return &res, nil
+}
+
+func (h *NamespaceHandler) HandleNamespaceVariableGet(c *handler.Context) (*wire.VariableGetResponse, error) {
+	return wire.VariableToWire(c.Variable), nil
+}
+
+func (h *NamespaceHandler) HandleNamespaceVariableCreate(c *handler.Context, req wire.VariableCreateRequest) (*wire.VariableCreateResponse, error) {
+	if h.config.MaxVariablesPerNamespace != 0 {
+		variables, err := h.variableStore.VariablesByNamespace(c.Context(), c.Namespace.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get variables: %w", err)
+		}
+
+		if len(variables) >= h.config.MaxVariablesPerNamespace {
+			return nil, handler.ErrBadRequest("resource_limit", fmt.Sprintf("maximum number of variables (%d) reached", h.config.MaxVariablesPerNamespace))
+		}
+	}
+
+	definition, err := req.Definition()
+	if err != nil {
+		return nil, handler.ErrBadRequest("invalid_variable", err.Error())
+	}
+
+	variable, err := h.variableStore.CreateVariable(c.Context(), &model.Variable{
+		ID:           util.UniqueID(),
+		Name:         req.Name,
+		Scoped:       req.Scoped,
+		NamespaceID:  null.StringFrom(c.Namespace.ID),
+		Type:         definition.Type,
+		Schema:       definition.Schema,
+		DefaultValue: definition.DefaultValue,
+		ValueTTL:     definition.ValueTTL,
+		CreatedAt:    time.Now().UTC(),
+		UpdatedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create variable: %w", err)
+	}
+
+	return wire.VariableToWire(variable), nil
+}
+
+func (h *NamespaceHandler) HandleNamespaceVariableUpdate(c *handler.Context, req wire.VariableUpdateRequest) (*wire.VariableUpdateResponse, error) {
+	definition, err := req.DefinitionFor(c.Variable)
+	if err != nil {
+		return nil, handler.ErrBadRequest("invalid_variable", err.Error())
+	}
+
+	if req.ResetsValues(c.Variable, definition) {
+		// Existing values are only deleted when the client confirmed it
+		if c.Variable.TotalValues.Int64 > 0 && !req.ResetValues {
+			return nil, handler.ErrBadRequest("variable_has_values", "changing the type or scope deletes all values of the variable, set reset_values to confirm")
+		}
+
+		err := h.variableValueStore.DeleteAllVariableValues(c.Context(), c.Variable.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete variable values: %w", err)
+		}
+	}
+
+	variable, err := h.variableStore.UpdateVariable(c.Context(), &model.Variable{
+		ID:           c.Variable.ID,
+		Name:         req.Name,
+		Scoped:       req.Scoped,
+		NamespaceID:  c.Variable.NamespaceID,
+		Type:         definition.Type,
+		Schema:       definition.Schema,
+		DefaultValue: definition.DefaultValue,
+		ValueTTL:     definition.ValueTTL,
+		UpdatedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, handler.ErrNotFound("unknown_variable", "Variable not found")
+		}
+		return nil, fmt.Errorf("failed to update variable: %w", err)
+	}
+
+	return wire.VariableToWire(variable), nil
+}
+
+func (h *NamespaceHandler) HandleNamespaceVariableDelete(c *handler.Context) (*wire.VariableDeleteResponse, error) {
+	err := h.variableStore.DeleteVariable(c.Context(), c.Variable.ID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, handler.ErrNotFound("unknown_variable", "Variable not found")
+		}
+		return nil, fmt.Errorf("failed to delete variable: %w", err)
+	}
+
+	return &wire.VariableDeleteResponse{}, nil
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+
 	"github.com/kitecloud/kite/kite-service/internal/api/handler"
 	"github.com/kitecloud/kite/kite-service/internal/api/wire"
 	"github.com/kitecloud/kite/kite-service/internal/model"
@@ -16,12 +18,18 @@ import (
 type PluginHandler struct {
 	pluginRegistry      *plugin.Registry
 	pluginInstanceStore store.PluginInstanceStore
+	messageStore        store.MessageStore
 }
 
-func NewPluginHandler(pluginRegistry *plugin.Registry, pluginInstanceStore store.PluginInstanceStore) *PluginHandler {
+func NewPluginHandler(
+	pluginRegistry *plugin.Registry,
+	pluginInstanceStore store.PluginInstanceStore,
+	messageStore store.MessageStore,
+) *PluginHandler {
 	return &PluginHandler{
 		pluginRegistry:      pluginRegistry,
 		pluginInstanceStore: pluginInstanceStore,
+		messageStore:        messageStore,
 	}
 }
 
@@ -55,6 +63,15 @@ func (h *PluginHandler) HandlePluginInstanceGet(c *handler.Context) (*wire.Plugi
 }
 
 func (h *PluginHandler) HandlePluginInstanceCreate(c *handler.Context, req wire.PluginInstanceCreateRequest) (*wire.PluginInstanceCreateResponse, error) {
+	pl := h.pluginRegistry.Plugin(req.PluginID)
+	if pl == nil {
+		return nil, handler.ErrNotFound("unknown_plugin", "Plugin not found")
+	}
+
+	if err := h.validateConfig(c, pl, req.Config); err != nil {
+		return nil, err
+	}
+
 	pluginInstance, err := h.pluginInstanceStore.CreatePluginInstance(c.Context(), &model.PluginInstance{
 		ID:                 util.UniqueID(),
 		PluginID:           req.PluginID,
@@ -74,6 +91,15 @@ func (h *PluginHandler) HandlePluginInstanceCreate(c *handler.Context, req wire.
 }
 
 func (h *PluginHandler) HandlePluginInstanceUpdate(c *handler.Context, req wire.PluginInstanceUpdateRequest) (*wire.PluginInstanceUpdateResponse, error) {
+	pl := h.pluginRegistry.Plugin(c.PluginInstance.PluginID)
+	if pl == nil {
+		return nil, handler.ErrNotFound("unknown_plugin", "Plugin not found")
+	}
+
+	if err := h.validateConfig(c, pl, req.Config); err != nil {
+		return nil, err
+	}
+
 	pluginInstance, err := h.pluginInstanceStore.UpdatePluginInstance(c.Context(), &model.PluginInstance{
 		ID:                 c.PluginInstance.ID,
 		AppID:              c.App.ID,
@@ -124,3 +150,28 @@ func (h *PluginHandler) HandlePluginInstanceDelete(c *handler.Context) (*wire.Pl
 
 	return &wire.PluginInstanceDeleteResponse{}, nil
 }
+
+// validateConfig checks the config values against the schema of the plugin
+// and makes sure that all referenced message templates belong to the app.
+func (h *PluginHandler) validateConfig(c *handler.Context, pl plugin.Plugin, values plugin.ConfigValues) error {
+	config := pl.Config()
+	if err := config.Validate(values); err != nil {
+		return validation.Errors{"config": err}
+	}
+
+	for _, messageID := range config.StringValuesOfType(values, plugin.ConfigFieldTypeMessageTemplate) {
+		msg, err := h.messageStore.Message(c.Context(), messageID)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return handler.ErrBadRequest("unknown_message", fmt.Sprintf("Message template %s not found", messageID))
+			}
+			return fmt.Errorf("failed to get message template: %w", err)
+		}
+
+		if msg.AppID != c.App.ID {
+			return handler.ErrBadRequest("unknown_message", fmt.Sprintf("Message template %s not found", messageID))
+		}
+	}
+
+	return nil
+}
@@ -54,13 +54,22 @@ func (h *VariableHandler) HandleVariableCreate(c *handler.Context, req wire.Vari
 		}
 	}
 
+	definition, err := req.Definition()
+	if err != nil {
+		return nil, handler.ErrBadRequest("invalid_variable", err.Error())
+	}
+
 	variable, err := h.variableStore.CreateVariable(c.Context(), &model.Variable{
-		ID:        util.UniqueID(),
-		Name:      req.Name,
-		Scoped:    req.Scoped,
-		AppID:     c.App.ID,
-		CreatedAt: time.Now().UTC(),
-		UpdatedAt: time.Now().UTC(),
+		ID:           util.UniqueID(),
+		Name:         req.Name,
+		Scoped:       req.Scoped,
+		AppID:        c.App.ID,
+		Type:         definition.Type,
+		Schema:       definition.Schema,
+		DefaultValue: definition.DefaultValue,
+		ValueTTL:     definition.ValueTTL,
+		CreatedAt:    time.Now().UTC(),
+		UpdatedAt:    time.Now().UTC(),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create variable: %w", err)
@@ -86,13 +95,22 @@ func (h *VariableHandler) HandleVariablesImport(c *handler.Context, req wire.Var
 	res := make([]*wire.Variable, len(req.Variables))
 
 	for i, v := range req.Variables {
+		definition, err := v.Definition()
+		if err != nil {
+			return nil, handler.ErrBadRequest("invalid_variable", fmt.Sprintf("variable %s: %s", v.Name, err))
+		}
+
 		variable, err := h.variableStore.CreateVariable(c.Context(), &model.Variable{
-			ID:        util.UniqueID(),
-			Name:      v.Name,
-			Scoped:    v.Scoped,
-			AppID:     c.App.ID,
-			CreatedAt: time.Now().UTC(),
-			UpdatedAt: time.Now().UTC(),
+			ID:           util.UniqueID(),
+			Name:         v.Name,
+			Scoped:       v.Scoped,
+			AppID:        c.App.ID,
+			Type:         definition.Type,
+			Schema:       definition.Schema,
+			DefaultValue: definition.DefaultValue,
+			ValueTTL:     definition.ValueTTL,
+			CreatedAt:    time.Now().UTC(),
+			UpdatedAt:    time.Now().UTC(),
 		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to create variable: %w", err)
@@ -105,8 +123,17 @@ func (h *VariableHandler) HandleVariablesImport(c *handler.Context, req wire.Var
 }
 
 func (h *VariableHandler) HandleVariableUpdate(c *handler.Context, req wire.VariableUpdateRequest) (*wire.VariableUpdateResponse, error) {
-	if req.Scoped != c.Variable.Scoped {
-		// If the scoped flag changes, delete all variable values.
+	definition, err := req.DefinitionFor(c.Variable)
+	if err != nil {
+		return nil, handler.ErrBadRequest("invalid_variable", err.Error())
+	}
+
+	if req.ResetsValues(c.Variable, definition) {
+		// Existing values are only deleted when the client confirmed it
+		if c.Variable.TotalValues.Int64 > 0 && !req.ResetValues {
+			return nil, handler.ErrBadRequest("variable_has_values", "changing the type or scope deletes all values of the variable, set reset_values to confirm")
+		}
+
 		err := h.variableValueStore.DeleteAllVariableValues(c.Context(), c.Variable.ID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to delete variable values: %w", err)
@@ -114,11 +141,15 @@ func (h *VariableHandler) HandleVariableUpdate(c *handler.Context, req wire.Vari
 	}
 
 	variable, err := h.variableStore.UpdateVariable(c.Context(), &model.Variable{
-		ID:        c.Variable.ID,
-		Name:      req.Name,
-		Scoped:    req.Scoped,
-		AppID:     c.App.ID,
-		UpdatedAt: time.Now().UTC(),
+		ID:           c.Variable.ID,
+		Name:         req.Name,
+		Scoped:       req.Scoped,
+		AppID:        c.App.ID,
+		Type:         definition.Type,
+		Schema:       definition.Schema,
+		DefaultValue: definition.DefaultValue,
+		ValueTTL:     definition.ValueTTL,
+		UpdatedAt:    time.Now().UTC(),
 	})
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
@@ -14,18 +14,23 @@ import (
 	"github.com/kitecloud/kite/kite-service/internal/api/handler/billing"
 	commandhandler "github.com/kitecloud/kite/kite-service/internal/api/handler/command"
 	eventlistener "github.com/kitecloud/kite/kite-service/internal/api/handler/event_listener"
+	"github.com/kitecloud/kite/kite-service/internal/api/handler/health"
 	"github.com/kitecloud/kite/kite-service/internal/api/handler/logs"
 	"github.com/kitecloud/kite/kite-service/internal/api/handler/message"
+	modulehandler "github.com/kitecloud/kite/kite-service/internal/api/handler/module"
+	"github.com/kitecloud/kite/kite-service/internal/api/handler/namespace"
 	pluginhandler "github.com/kitecloud/kite/kite-service/internal/api/handler/plugin"
 	"github.com/kitecloud/kite/kite-service/internal/api/handler/usage"
 	"github.com/kitecloud/kite/kite-service/internal/api/handler/user"
 	"github.com/kitecloud/kite/kite-service/internal/api/handler/variable"
 	"github.com/kitecloud/kite/kite-service/internal/api/session"
 	"github.com/kitecloud/kite/kite-service/internal/core/command"
+	"github.com/kitecloud/kite/kite-service/internal/core/messagesync"
 	"github.com/kitecloud/kite/kite-service/internal/core/plan"
 	"github.com/kitecloud/kite/kite-service/internal/store"
 	"github.com/kitecloud/kite/kite-service/internal/util"
 	"github.com/kitecloud/kite/kite-service/pkg/plugin"
+	"github.com/kitecloud/kite/kite-service/pkg/wasm"
 	kiteweb "github.com/merlinfuchs/kite/kite-web"
 )
 
@@ -45,11 +50,16 @@ func (s *APIServer) RegisterRoutes(
 	subscriptionStore store.SubscriptionStore,
 	entitlementStore store.EntitlementStore,
 	assetStore store.AssetStore,
+	moduleStore store.ModuleStore,
+	namespaceStore store.NamespaceStore,
 	appStateManager store.AppStateManager,
 	planManager *plan.PlanManager,
 	pluginRegistry *plugin.Registry,
 	tokenCrypt *util.SymmetricCrypt,
 	commandManager *command.CommandManager,
+	messageSyncManager *messagesync.SyncManager,
+	wasmRuntime *wasm.Runtime,
+	healthChecks map[string]health.HealthCheck,
 ) {
 	sessionManager := session.NewSessionManager(session.SessionManagerConfig{
 		StrictCookies: s.config.StrictCookies,
@@ -62,6 +72,8 @@ func (s *APIServer) RegisterRoutes(
 		messageStore,
 		eventListenerStore,
 		pluginInstanceStore,
+		moduleStore,
+		namespaceStore,
 		planManager,
 	)
 
@@ -91,6 +103,13 @@ func (s *APIServer) RegisterRoutes(
 		return c.Send(http.StatusOK, []byte("OK"))
 	})
 
+	// Health routes
+	healthHandler := health.NewHealthHandler(healthChecks)
+
+	healthGroup := v1Group.Group("/health")
+	healthGroup.Get("/live", healthHandler.HandleLive)
+	healthGroup.Get("/ready", healthHandler.HandleReady)
+
 	// Auth routes
 	authHandler := auth.NewAuthHandler(auth.AuthHandlerConfig{
 		SecureCookies:       s.config.SecureCookies,
@@ -183,7 +202,7 @@ func (s *APIServer) RegisterRoutes(
 	usageGroup.Get("/by-type", handler.Typed(usageHandler.HandleUsageByTypeList))
 
 	// Command routes
-	commandsHandler := commandhandler.NewCommandHandler(commandStore, commandManager)
+	commandsHandler := commandhandler.NewCommandHandler(commandStore, moduleStore, commandManager)
 
 	commandsGroup := appGroup.Group("/commands")
 	commandsGroup.Get("/", handler.Typed(commandsHandler.HandleCommandList))
@@ -215,7 +234,7 @@ func (s *APIServer) RegisterRoutes(
 	eventListenerGroup.Put("/enabled", handler.TypedWithBody(eventListenerHandler.HandleEventListenerUpdateEnabled))
 
 	// Plugin instance routes
-	pluginHandler := pluginhandler.NewPluginHandler(pluginRegistry, pluginInstanceStore)
+	pluginHandler := pluginhandler.NewPluginHandler(pluginRegistry, pluginInstanceStore, messageStore)
 
 	pluginsGroup := v1Group.Group("/plugins")
 	pluginsGroup.Get("/", handler.Typed(pluginHandler.HandlePluginList))
@@ -230,6 +249,22 @@ func (s *APIServer) RegisterRoutes(
 	pluginInstanceGroup.Delete("/", handler.Typed(pluginHandler.HandlePluginInstanceDelete))
 	pluginInstanceGroup.Put("/enabled", handler.TypedWithBody(pluginHandler.HandlePluginInstanceUpdateEnabled))
 
+	// Module routes
+	moduleHandler := modulehandler.NewModuleHandler(moduleStore, wasmRuntime, modulehandler.ModuleHandlerConfig{
+		MaxModuleSize:    int64(s.config.UserLimits.MaxModuleSize),
+		MaxModulesPerApp: s.config.UserLimits.MaxModulesPerApp,
+	})
+
+	modulesGroup := appGroup.Group("/modules")
+	modulesGroup.Get("/", handler.Typed(moduleHandler.HandleModuleList))
+	modulesGroup.Post("/", handler.Typed(moduleHandler.HandleModuleCreate))
+
+	moduleGroup := modulesGroup.Group("/{moduleID}", accessManager.ModuleAccess)
+	moduleGroup.Get("/", handler.Typed(moduleHandler.HandleModuleGet))
+	moduleGroup.Patch("/", handler.TypedWithBody(moduleHandler.HandleModuleUpdate))
+	moduleGroup.Put("/wasm", handler.Typed(moduleHandler.HandleModuleWasmUpdate))
+	moduleGroup.Delete("/", handler.Typed(moduleHandler.HandleModuleDelete))
+
 	// Variable routes
 	variablesHandler := variable.NewVariableHandler(variableStore, variableValueStore)
 
@@ -243,12 +278,52 @@ func (s *APIServer) RegisterRoutes(
 	variableGroup.Patch("/", handler.TypedWithBody(variablesHandler.HandleVariableUpdate))
 	variableGroup.Delete("/", handler.Typed(variablesHandler.HandleVariableDelete))
 
+	// Namespace routes
+	namespaceHandler := namespace.NewNamespaceHandler(namespaceStore, variableStore, variableValueStore, userStore, namespace.NamespaceHandlerConfig{
+		MaxNamespacesPerUser:     s.config.UserLimits.MaxNamespacesPerUser,
+		MaxVariablesPerNamespace: s.config.UserLimits.MaxVariablesPerNamespace,
+		MaxMembersPerNamespace:   s.config.UserLimits.MaxMembersPerNamespace,
+	})
+
+	appGroup.Get("/shared-variables", handler.Typed(namespaceHandler.HandleSharedVariableList))
+
+	namespacesGroup := v1Group.Group("/namespaces",
+		sessionManager.RequireSession,
+		handler.RateLimitByUser(60, time.Minute),
+	)
+	namespacesGroup.Get("/", handler.Typed(namespaceHandler.HandleNamespaceList))
+	namespacesGroup.Post("/", handler.TypedWithBody(namespaceHandler.HandleNamespaceCreate))
+
+	namespaceGroup := namespacesGroup.Group("/{namespaceID}", accessManager.NamespaceAccess)
+	namespaceGroup.Get("/", handler.Typed(namespaceHandler.HandleNamespaceGet))
+	namespaceGroup.Patch("/", handler.TypedWithBody(namespaceHandler.HandleNamespaceUpdate))
+	namespaceGroup.Delete("/", handler.Typed(namespaceHandler.HandleNamespaceDelete))
+	namespaceGroup.Get("/grants", handler.Typed(namespaceHandler.HandleNamespaceGrantList))
+	namespaceGroup.Get("/members", handler.Typed(namespaceHandler.HandleNamespaceMemberList))
+	namespaceGroup.Post("/members", handler.TypedWithBody(namespaceHandler.HandleNamespaceMemberCreate))
+	namespaceGroup.Delete("/members/{userID}", handler.Typed(namespaceHandler.HandleNamespaceMemberDelete))
+
+	// Granting access requires access to the app as well
+	namespaceGrantGroup := namespaceGroup.Group("/grants/{appID}", accessManager.AppAccess)
+	namespaceGrantGroup.Put("/", handler.TypedWithBody(namespaceHandler.HandleNamespaceGrantUpdate))
+	namespaceGrantGroup.Delete("/", handler.Typed(namespaceHandler.HandleNamespaceGrantDelete))
+
+	namespaceVariablesGroup := namespaceGroup.Group("/variables")
+	namespaceVariablesGroup.Get("/", handler.Typed(namespaceHandler.HandleNamespaceVariableList))
+	namespaceVariablesGroup.Post("/", handler.TypedWithBody(namespaceHandler.HandleNamespaceVariableCreate))
+
+	namespaceVariableGroup := namespaceVariablesGroup.Group("/{variableID}", accessManager.NamespaceVariableAccess)
+	namespaceVariableGroup.Get("/", handler.Typed(namespaceHandler.HandleNamespaceVariableGet))
+	namespaceVariableGroup.Patch("/", handler.TypedWithBody(namespaceHandler.HandleNamespaceVariableUpdate))
+	namespaceVariableGroup.Delete("/", handler.Typed(namespaceHandler.HandleNamespaceVariableDelete))
+
 	// Message routes
 	messageHandler := message.NewMessageHandler(
 		messageStore,
 		messageInstanceStore,
 		assetStore,
 		appStateManager,
+		messageSyncManager,
 	)
 
 	messagesGroup := appGroup.Group("/messages")
@@ -262,6 +337,8 @@ func (s *APIServer) RegisterRoutes(
 	messageGroup.Delete("/", handler.Typed(messageHandler.HandleMessageDelete))
 	messageGroup.Get("/instances", handler.Typed(messageHandler.HandleMessageInstanceList))
 	messageGroup.Post("/instances", handler.TypedWithBody(messageHandler.HandleMessageInstanceCreate))
+	messageGroup.Post("/instances/sync", handler.Typed(messageHandler.HandleMessageInstanceSyncStart))
+	messageGroup.Get("/instances/sync", handler.Typed(messageHandler.HandleMessageInstanceSyncGet))
 	messageGroup.Put("/instances/{instanceID}", handler.Typed(messageHandler.HandleMessageInstanceUpdate))
 	messageGroup.Delete("/instances/{instanceID}", handler.Typed(messageHandler.HandleMessageInstanceDelete))
 
@@ -5,12 +5,15 @@ import (
 	"log/slog"
 	"net/http"
 
+	"github.com/kitecloud/kite/kite-service/internal/api/handler/health"
 	"github.com/kitecloud/kite/kite-service/internal/config"
 	"github.com/kitecloud/kite/kite-service/internal/core/command"
+	"github.com/kitecloud/kite/kite-service/internal/core/messagesync"
 	"github.com/kitecloud/kite/kite-service/internal/core/plan"
 	"github.com/kitecloud/kite/kite-service/internal/store"
 	"github.com/kitecloud/kite/kite-service/internal/util"
 	"github.com/kitecloud/kite/kite-service/pkg/plugin"
+	"github.com/kitecloud/kite/kite-service/pkg/wasm"
 	"github.com/rs/cors"
 )
 
@@ -28,8 +31,13 @@ type APIServerConfig struct {
 }
 
 type APIUserLimitsConfig struct {
-	MaxAppsPerUser int
-	MaxAssetSize   int
+	MaxAppsPerUser           int
+	MaxAssetSize             int
+	MaxModuleSize            int
+	MaxModulesPerApp         int
+	MaxNamespacesPerUser     int
+	MaxVariablesPerNamespace int
+	MaxMembersPerNamespace   int
 }
 
 type BillingConfig struct {
@@ -63,11 +71,16 @@ func NewAPIServer(
 	subscriptionStore store.SubscriptionStore,
 	entitlementStore store.EntitlementStore,
 	assetStore store.AssetStore,
+	moduleStore store.ModuleStore,
+	namespaceStore store.NamespaceStore,
 	appStateManager store.AppStateManager,
 	planManager *plan.PlanManager,
 	pluginRegistry *plugin.Registry,
 	tokenCrypt *util.SymmetricCrypt,
 	commandManager *command.CommandManager,
+	messageSyncManager *messagesync.SyncManager,
+	wasmRuntime *wasm.Runtime,
+	healthChecks map[string]health.HealthCheck,
 ) *APIServer {
 	s := &APIServer{
 		config: config,
@@ -89,11 +102,16 @@ func NewAPIServer(
 		subscriptionStore,
 		entitlementStore,
 		assetStore,
+		moduleStore,
+		namespaceStore,
 		appStateManager,
 		planManager,
 		pluginRegistry,
 		tokenCrypt,
 		commandManager,
+		messageSyncManager,
+		wasmRuntime,
+		healthChecks,
 	)
 	return s
 }
@@ -31,6 +31,8 @@ type CommandListResponse = []*Command
 type CommandCreateRequest struct {
 	FlowSource flow.FlowData `json:"flow_source"`
 	Enabled    bool          `json:"enabled"`
+	// ModuleID is the module that handles the command instead of its flow.
+	ModuleID null.String `json:"module_id"`
 }
 
 func (req CommandCreateRequest) Validate() error {
@@ -56,6 +58,7 @@ type CommandsImportResponse = []*Command
 type CommandUpdateRequest struct {
 	FlowSource flow.FlowData `json:"flow_source"`
 	Enabled    bool          `json:"enabled"`
+	ModuleID   null.String   `json:"module_id"`
 }
 
 func (req CommandUpdateRequest) Validate() error {
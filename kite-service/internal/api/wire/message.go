@@ -37,14 +37,12 @@ type MessageCreateRequest struct {
 func (req *MessageCreateRequest) Sanitize() {
 	// Remove unused flow sources
 	newFlowSources := make(map[string]flow.FlowData, len(req.FlowSources))
-	for _, row := range req.Data.Components {
-		for _, comp := range row.Components {
-			flow, ok := req.FlowSources[comp.FlowSourceID]
-			if ok {
-				newFlowSources[comp.FlowSourceID] = flow
-			}
+	req.Data.EachComponent(func(comp *message.ComponentData) {
+		flow, ok := req.FlowSources[comp.FlowSourceID]
+		if ok {
+			newFlowSources[comp.FlowSourceID] = flow
 		}
-	}
+	})
 
 	req.FlowSources = newFlowSources
 }
@@ -53,6 +51,7 @@ func (req MessageCreateRequest) Validate() error {
 	return validation.ValidateStruct(&req,
 		validation.Field(&req.Name, validation.Required, validation.Length(1, 100)),
 		validation.Field(&req.Description, validation.Length(0, 255)),
+		validation.Field(&req.Data),
 	)
 }
 
@@ -81,19 +80,19 @@ type MessageUpdateRequest struct {
 	Description null.String              `json:"description"`
 	Data        message.MessageData      `json:"data"`
 	FlowSources map[string]flow.FlowData `json:"flow_sources"`
+	// SyncInstances re-renders and edits all instances of the message in the background
+	SyncInstances bool `json:"sync_instances"`
 }
 
 func (req *MessageUpdateRequest) Sanitize() {
 	// Remove unused flow sources
 	newFlowSources := make(map[string]flow.FlowData, len(req.FlowSources))
-	for _, row := range req.Data.Components {
-		for _, comp := range row.Components {
-			flow, ok := req.FlowSources[comp.FlowSourceID]
-			if ok {
-				newFlowSources[comp.FlowSourceID] = flow
-			}
+	req.Data.EachComponent(func(comp *message.ComponentData) {
+		flow, ok := req.FlowSources[comp.FlowSourceID]
+		if ok {
+			newFlowSources[comp.FlowSourceID] = flow
 		}
-	}
+	})
 
 	req.FlowSources = newFlowSources
 }
@@ -102,6 +101,7 @@ func (req MessageUpdateRequest) Validate() error {
 	return validation.ValidateStruct(&req,
 		validation.Field(&req.Name, validation.Required, validation.Length(1, 100)),
 		validation.Field(&req.Description, validation.Length(0, 255)),
+		validation.Field(&req.Data),
 	)
 }
 
@@ -170,3 +170,54 @@ func MessageInstanceToWire(instance *model.MessageInstance) *MessageInstance {
 		UpdatedAt:        instance.UpdatedAt,
 	}
 }
+
+type MessageSyncJob struct {
+	ID          string                      `json:"id"`
+	MessageID   string                      `json:"message_id"`
+	Status      string                      `json:"status"`
+	Error       null.String                 `json:"error"`
+	Results     []MessageInstanceSyncResult `json:"results"`
+	CreatedAt   time.Time                   `json:"created_at"`
+	StartedAt   null.Time                   `json:"started_at"`
+	CompletedAt null.Time                   `json:"completed_at"`
+}
+
+type MessageInstanceSyncResult struct {
+	InstanceID       uint64      `json:"instance_id"`
+	DiscordGuildID   string      `json:"discord_guild_id"`
+	DiscordChannelID string      `json:"discord_channel_id"`
+	Status           string      `json:"status"`
+	Error            null.String `json:"error"`
+}
+
+type MessageInstanceSyncStartResponse = MessageSyncJob
+
+type MessageInstanceSyncGetResponse = MessageSyncJob
+
+func MessageSyncJobToWire(job *model.MessageSyncJob) *MessageSyncJob {
+	if job == nil {
+		return nil
+	}
+
+	results := make([]MessageInstanceSyncResult, len(job.Results))
+	for i, result := range job.Results {
+		results[i] = MessageInstanceSyncResult{
+			InstanceID:       result.InstanceID,
+			DiscordGuildID:   result.DiscordGuildID,
+			DiscordChannelID: result.DiscordChannelID,
+			Status:           string(result.Status),
+			Error:            result.Error,
+		}
+	}
+
+	return &MessageSyncJob{
+		ID:          job.ID,
+		MessageID:   job.MessageID,
+		Status:      string(job.Status),
+		Error:       job.Error,
+		Results:     results,
+		CreatedAt:   job.CreatedAt,
+		StartedAt:   job.StartedAt,
+		CompletedAt: job.CompletedAt,
+	}
+}
@@ -0,0 +1,67 @@
+package wire
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/kitecloud/kite/kite-service/internal/model"
+)
+
+type Module struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	Enabled       bool      `json:"enabled"`
+	AppID         string    `json:"app_id"`
+	CreatorUserID string    `json:"creator_user_id"`
+	WasmHash      string    `json:"wasm_hash"`
+	WasmSize      int       `json:"wasm_size"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type ModuleGetResponse = Module
+
+type ModuleListResponse = []*Module
+
+// ModuleCreateResponse is returned after uploading a module as multipart form with the fields "file", "name" and "description".
+type ModuleCreateResponse = Module
+
+type ModuleUpdateRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+}
+
+func (req ModuleUpdateRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&req.Description, validation.Length(0, 1000)),
+	)
+}
+
+type ModuleUpdateResponse = Module
+
+// ModuleWasmUpdateResponse is returned after uploading a new binary as multipart form with the field "file".
+type ModuleWasmUpdateResponse = Module
+
+type ModuleDeleteResponse = Empty
+
+func ModuleToWire(module *model.Module) *Module {
+	if module == nil {
+		return nil
+	}
+
+	return &Module{
+		ID:            module.ID,
+		Name:          module.Name,
+		Description:   module.Description,
+		Enabled:       module.Enabled,
+		AppID:         module.AppID,
+		CreatorUserID: module.CreatorUserID,
+		WasmHash:      module.WasmHash,
+		WasmSize:      module.WasmSize,
+		CreatedAt:     module.CreatedAt,
+		UpdatedAt:     module.UpdatedAt,
+	}
+}
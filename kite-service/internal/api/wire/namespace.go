@@ -0,0 +1,158 @@
+package wire
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"gopkg.in/guregu/null.v4"
+)
+
+type Namespace struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Description null.String `json:"description"`
+	OwnerUserID string      `json:"owner_user_id"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+type NamespaceGetResponse = Namespace
+
+type NamespaceListResponse = []*Namespace
+
+type NamespaceCreateRequest struct {
+	Name        string      `json:"name"`
+	Description null.String `json:"description"`
+}
+
+func (req NamespaceCreateRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&req.Description, validation.Length(0, 200)),
+	)
+}
+
+type NamespaceCreateResponse = Namespace
+
+type NamespaceUpdateRequest struct {
+	Name        string      `json:"name"`
+	Description null.String `json:"description"`
+}
+
+func (req NamespaceUpdateRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&req.Description, validation.Length(0, 200)),
+	)
+}
+
+type NamespaceUpdateResponse = Namespace
+
+type NamespaceDeleteResponse = Empty
+
+type NamespaceGrant struct {
+	NamespaceID string    `json:"namespace_id"`
+	AppID       string    `json:"app_id"`
+	Access      string    `json:"access"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type NamespaceGrantListResponse = []*NamespaceGrant
+
+type NamespaceGrantUpdateRequest struct {
+	Access string `json:"access"`
+}
+
+func (req NamespaceGrantUpdateRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Access, validation.Required, validation.In(
+			string(model.NamespaceAccessRead),
+			string(model.NamespaceAccessReadWrite),
+		)),
+	)
+}
+
+type NamespaceGrantUpdateResponse = NamespaceGrant
+
+type NamespaceGrantDeleteResponse = Empty
+
+type NamespaceMember struct {
+	User      User      `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type NamespaceMemberListResponse = []*NamespaceMember
+
+type NamespaceMemberCreateRequest struct {
+	DiscordUserID string `json:"discord_user_id"`
+}
+
+func (req NamespaceMemberCreateRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.DiscordUserID, validation.Required),
+	)
+}
+
+type NamespaceMemberCreateResponse = NamespaceMember
+
+type NamespaceMemberDeleteResponse = Empty
+
+// SharedVariable is a variable from a namespace that an app has been granted access to.
+type SharedVariable struct {
+	Variable      *Variable `json:"variable"`
+	NamespaceName string    `json:"namespace_name"`
+	Access        string    `json:"access"`
+}
+
+type SharedVariableListResponse = []*SharedVariable
+
+func NamespaceToWire(namespace *model.Namespace) *Namespace {
+	if namespace == nil {
+		return nil
+	}
+
+	return &Namespace{
+		ID:          namespace.ID,
+		Name:        namespace.Name,
+		Description: namespace.Description,
+		OwnerUserID: namespace.OwnerUserID,
+		CreatedAt:   namespace.CreatedAt,
+		UpdatedAt:   namespace.UpdatedAt,
+	}
+}
+
+func NamespaceGrantToWire(grant *model.NamespaceGrant) *NamespaceGrant {
+	if grant == nil {
+		return nil
+	}
+
+	return &NamespaceGrant{
+		NamespaceID: grant.NamespaceID,
+		AppID:       grant.AppID,
+		Access:      string(grant.Access),
+		CreatedAt:   grant.CreatedAt,
+		UpdatedAt:   grant.UpdatedAt,
+	}
+}
+
+func NamespaceMemberToWire(member *model.NamespaceMember) *NamespaceMember {
+	if member == nil {
+		return nil
+	}
+
+	var user User
+	if member.User != nil {
+		user = *UserToWire(member.User, true)
+	} else {
+		user.ID = member.UserID
+	}
+
+	return &NamespaceMember{
+		User:      user,
+		CreatedAt: member.CreatedAt,
+		UpdatedAt: member.UpdatedAt,
+	}
+}
@@ -1,25 +1,54 @@
 package wire
 
 import (
+	"encoding/json"
 	"regexp"
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
 	"gopkg.in/guregu/null.v4"
 )
 
 var variableNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
 
+var variableTypes = []interface{}{
+	"",
+	string(thing.TypeAny),
+	string(thing.TypeString),
+	string(thing.TypeInt),
+	string(thing.TypeFloat),
+	string(thing.TypeBool),
+	string(thing.TypeTime),
+	string(thing.TypeDuration),
+	string(thing.TypeArray),
+	string(thing.TypeObject),
+	string(thing.TypeDiscordUser),
+	string(thing.TypeDiscordMember),
+	string(thing.TypeDiscordChannel),
+	string(thing.TypeDiscordGuild),
+	string(thing.TypeDiscordRole),
+	string(thing.TypeDiscordMessage),
+}
+
+// maxVariableValueTTLSeconds is one year.
+const maxVariableValueTTLSeconds = 365 * 24 * 60 * 60
+
 type Variable struct {
-	ID          string      `json:"id"`
-	Name        string      `json:"name"`
-	Scoped      bool        `json:"scoped"`
-	AppID       string      `json:"app_id"`
-	ModuleID    null.String `json:"module_id"`
-	TotalValues null.Int    `json:"total_values"`
-	CreatedAt   time.Time   `json:"created_at"`
-	UpdatedAt   time.Time   `json:"updated_at"`
+	ID              string        `json:"id"`
+	Name            string        `json:"name"`
+	Scoped          bool          `json:"scoped"`
+	AppID           string        `json:"app_id"`
+	NamespaceID     null.String   `json:"namespace_id"`
+	ModuleID        null.String   `json:"module_id"`
+	Type            string        `json:"type"`
+	Schema          *thing.Schema `json:"schema"`
+	DefaultValue    any           `json:"default_value"`
+	ValueTTLSeconds int           `json:"value_ttl_seconds"`
+	TotalValues     null.Int      `json:"total_values"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
 }
 
 type VariableGetResponse = Variable
@@ -27,8 +56,12 @@ type VariableGetResponse = Variable
 type VariableListResponse = []*Variable
 
 type VariableCreateRequest struct {
-	Name   string `json:"name"`
-	Scoped bool   `json:"scoped"`
+	Name            string          `json:"name"`
+	Scoped          bool            `json:"scoped"`
+	Type            string          `json:"type"`
+	Schema          json.RawMessage `json:"schema"`
+	DefaultValue    any             `json:"default_value"`
+	ValueTTLSeconds int             `json:"value_ttl_seconds"`
 }
 
 func (req VariableCreateRequest) Validate() error {
@@ -40,9 +73,21 @@ func (req VariableCreateRequest) Validate() error {
 			validation.Match(variableNameRegex).
 				Error("must only consist of letters, numbers, and underscores"),
 		),
+		validation.Field(&req.Type, validation.In(variableTypes...)),
+		validation.Field(&req.Schema, validation.By(validateVariableSchema)),
+		validation.Field(&req.DefaultValue, validation.By(func(interface{}) error {
+			_, err := req.Definition()
+			return err
+		})),
+		validation.Field(&req.ValueTTLSeconds, validation.Min(0), validation.Max(maxVariableValueTTLSeconds)),
 	)
 }
 
+// Definition returns a variable that only has the type related fields set.
+func (req VariableCreateRequest) Definition() (*model.Variable, error) {
+	return variableDefinition(req.Type, req.Schema, req.DefaultValue, req.ValueTTLSeconds)
+}
+
 type VariableCreateResponse = Variable
 
 type VariablesImportRequest struct {
@@ -58,8 +103,14 @@ func (req VariablesImportRequest) Validate() error {
 type VariablesImportResponse = []*Variable
 
 type VariableUpdateRequest struct {
-	Name   string `json:"name"`
-	Scoped bool   `json:"scoped"`
+	Name            string          `json:"name"`
+	Scoped          bool            `json:"scoped"`
+	Type            string          `json:"type"`
+	Schema          json.RawMessage `json:"schema"`
+	DefaultValue    any             `json:"default_value"`
+	ValueTTLSeconds int             `json:"value_ttl_seconds"`
+	// ResetValues confirms that all values are deleted when the type or the scoped flag changes.
+	ResetValues bool `json:"reset_values"`
 }
 
 func (req VariableUpdateRequest) Validate() error {
@@ -71,9 +122,36 @@ func (req VariableUpdateRequest) Validate() error {
 			validation.Match(variableNameRegex).
 				Error("must only consist of letters, numbers, and underscores"),
 		),
+		validation.Field(&req.Type, validation.In(variableTypes...)),
+		validation.Field(&req.Schema, validation.By(validateVariableSchema)),
+		validation.Field(&req.DefaultValue, validation.By(func(interface{}) error {
+			_, err := req.Definition()
+			return err
+		})),
+		validation.Field(&req.ValueTTLSeconds, validation.Min(0), validation.Max(maxVariableValueTTLSeconds)),
 	)
 }
 
+// Definition returns a variable that only has the type related fields set.
+func (req VariableUpdateRequest) Definition() (*model.Variable, error) {
+	return variableDefinition(req.Type, req.Schema, req.DefaultValue, req.ValueTTLSeconds)
+}
+
+// DefinitionFor is like Definition, but a missing type keeps the type of the current variable.
+func (req VariableUpdateRequest) DefinitionFor(current *model.Variable) (*model.Variable, error) {
+	typ := req.Type
+	if typ == "" {
+		typ = string(current.Type)
+	}
+	return variableDefinition(typ, req.Schema, req.DefaultValue, req.ValueTTLSeconds)
+}
+
+// ResetsValues reports whether the update deletes all values of the current variable,
+// because they don't match the new type or scoped flag anymore.
+func (req VariableUpdateRequest) ResetsValues(current *model.Variable, definition *model.Variable) bool {
+	return req.Scoped != current.Scoped || definition.Type != current.Type
+}
+
 type VariableUpdateResponse = Variable
 
 type VariableDeleteResponse = Empty
@@ -83,14 +161,69 @@ func VariableToWire(variable *model.Variable) *Variable {
 		return nil
 	}
 
+	var defaultValue any
+	if !variable.DefaultValue.IsNil() {
+		defaultValue = variable.DefaultValue.Plain()
+	}
+
 	return &Variable{
-		ID:          variable.ID,
-		Name:        variable.Name,
-		Scoped:      variable.Scoped,
-		AppID:       variable.AppID,
-		ModuleID:    variable.ModuleID,
-		CreatedAt:   variable.CreatedAt,
-		UpdatedAt:   variable.UpdatedAt,
-		TotalValues: variable.TotalValues,
+		ID:              variable.ID,
+		Name:            variable.Name,
+		Scoped:          variable.Scoped,
+		AppID:           variable.AppID,
+		NamespaceID:     variable.NamespaceID,
+		ModuleID:        variable.ModuleID,
+		Type:            string(variable.Type),
+		Schema:          variable.Schema,
+		DefaultValue:    defaultValue,
+		ValueTTLSeconds: int(variable.ValueTTL / time.Second),
+		CreatedAt:       variable.CreatedAt,
+		UpdatedAt:       variable.UpdatedAt,
+		TotalValues:     variable.TotalValues,
+	}
+}
+
+func validateVariableSchema(value interface{}) error {
+	raw, _ := value.(json.RawMessage)
+	if isJSONNull(raw) {
+		return nil
+	}
+
+	_, err := thing.ParseSchema(raw)
+	return err
+}
+
+// variableDefinition parses the type related fields of a variable and checks that the default value matches them.
+// The default value is plain JSON and is converted to the type of the variable.
+func variableDefinition(typ string, schema json.RawMessage, defaultValue any, ttlSeconds int) (*model.Variable, error) {
+	variable := &model.Variable{
+		Type:         thing.Type(typ),
+		DefaultValue: thing.Null,
+		ValueTTL:     time.Duration(ttlSeconds) * time.Second,
+	}
+	if variable.Type == "" {
+		variable.Type = thing.TypeAny
 	}
+
+	if !isJSONNull(schema) {
+		s, err := thing.ParseSchema(schema)
+		if err != nil {
+			return nil, err
+		}
+		variable.Schema = s
+	}
+
+	if defaultValue != nil {
+		value, err := variable.ConvertValue(thing.NewGuessTypeRecursive(defaultValue))
+		if err != nil {
+			return nil, err
+		}
+		variable.DefaultValue = value
+	}
+
+	return variable, nil
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return len(raw) == 0 || string(raw) == "null"
 }
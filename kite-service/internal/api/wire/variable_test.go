@@ -0,0 +1,32 @@
+package wire
+
+import (
+	"testing"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariableUpdateDefinition(t *testing.T) {
+	current := &model.Variable{Scoped: true, Type: thing.TypeInt}
+
+	// A missing type keeps the current type, so the values aren't reset
+	req := VariableUpdateRequest{Name: "balance", Scoped: true, DefaultValue: "5"}
+	definition, err := req.DefinitionFor(current)
+	require.NoError(t, err)
+	assert.Equal(t, thing.TypeInt, definition.Type)
+	assert.Equal(t, thing.NewInt(5), definition.DefaultValue)
+	assert.False(t, req.ResetsValues(current, definition))
+
+	req.Type = string(thing.TypeString)
+	definition, err = req.DefinitionFor(current)
+	require.NoError(t, err)
+	assert.True(t, req.ResetsValues(current, definition))
+
+	req = VariableUpdateRequest{Name: "balance", Scoped: false, Type: string(thing.TypeInt)}
+	definition, err = req.DefinitionFor(current)
+	require.NoError(t, err)
+	assert.True(t, req.ResetsValues(current, definition))
+}
@@ -1,18 +1,26 @@
 package config
 
-import "github.com/go-playground/validator/v10"
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+)
 
 type Config struct {
-	Logging    LoggingConfig    `toml:"logging"`
-	Database   DatabaseConfig   `toml:"database"`
-	API        APIConfig        `toml:"api"`
-	App        AppConfig        `toml:"app"`
-	UserLimits UserLimitsConfig `toml:"user_limits"`
-	Discord    DiscordConfig    `toml:"discord"`
-	Engine     EngineConfig     `toml:"engine"`
-	OpenAI     OpenAIConfig     `toml:"openai"`
-	Billing    BillingConfig    `toml:"billing"`
-	Encryption EncryptionConfig `toml:"encryption"`
+	Logging     LoggingConfig     `toml:"logging"`
+	Database    DatabaseConfig    `toml:"database"`
+	Cache       CacheConfig       `toml:"cache"`
+	API         APIConfig         `toml:"api"`
+	App         AppConfig         `toml:"app"`
+	UserLimits  UserLimitsConfig  `toml:"user_limits"`
+	Discord     DiscordConfig     `toml:"discord"`
+	Engine      EngineConfig      `toml:"engine"`
+	OpenAI      OpenAIConfig      `toml:"openai"`
+	Billing     BillingConfig     `toml:"billing"`
+	Encryption  EncryptionConfig  `toml:"encryption"`
+	Metrics     MetricsConfig     `toml:"metrics"`
+	Tracing     TracingConfig     `toml:"tracing"`
+	Maintenance MaintenanceConfig `toml:"maintenance"`
 
 	ClusterCount int `toml:"cluster_count"`
 	ClusterIndex int `toml:"cluster_index"`
@@ -20,7 +28,24 @@ type Config struct {
 
 func (cfg *Config) Validate() error {
 	validate := validator.New(validator.WithRequiredStructEnabled())
-	return validate.Struct(cfg)
+	if err := validate.Struct(cfg); err != nil {
+		return err
+	}
+
+	// Every cluster would have its own cache and wouldn't see the invalidations of the others
+	if cfg.ClusterCount > 1 && cfg.Cache.Type == "memory" {
+		return errors.New("the memory cache can only be used with a single cluster, use the redis cache instead")
+	}
+
+	// The database file and the objects are local to the process, other clusters can't share them
+	if cfg.ClusterCount > 1 && cfg.Database.Type == "sqlite" {
+		return errors.New("the sqlite database can only be used with a single cluster, use postgres instead")
+	}
+	if cfg.ClusterCount > 1 && cfg.Database.ObjectStore == "local" {
+		return errors.New("the local object store can only be used with a single cluster, use s3 instead")
+	}
+
+	return cfg.Database.validate(validate)
 }
 
 func (cfg *Config) IsPrimaryCluster() bool {
@@ -31,9 +56,52 @@ func LoadConfig(basePath string) (*Config, error) {
 	return loadConfig[*Config](basePath)
 }
 
+// DatabaseConfig selects the database and the object store, only the config of the selected ones is validated.
 type DatabaseConfig struct {
-	Postgres PostgresConfig `toml:"postgres"`
-	S3       S3Config       `toml:"s3"`
+	// Type is either "postgres" or "sqlite", sqlite can only be used with a single cluster
+	Type     string         `toml:"type" validate:"oneof=postgres sqlite"`
+	Postgres PostgresConfig `toml:"postgres" validate:"-"`
+	SQLite   SQLiteConfig   `toml:"sqlite" validate:"-"`
+	// ObjectStore is either "s3" or "local", local can only be used with a single cluster
+	ObjectStore string            `toml:"object_store" validate:"oneof=s3 local"`
+	S3          S3Config          `toml:"s3" validate:"-"`
+	Local       LocalObjectConfig `toml:"local" validate:"-"`
+}
+
+func (cfg *DatabaseConfig) validate(validate *validator.Validate) error {
+	var database any = cfg.Postgres
+	if cfg.Type == "sqlite" {
+		database = cfg.SQLite
+	}
+
+	if err := validate.Struct(database); err != nil {
+		return err
+	}
+
+	var objectStore any = cfg.S3
+	if cfg.ObjectStore == "local" {
+		objectStore = cfg.Local
+	}
+
+	return validate.Struct(objectStore)
+}
+
+type CacheConfig struct {
+	// Type is either "memory" for an in-process LRU cache or "redis" for a cache that is shared between processes.
+	// The memory cache can only be used with a single cluster.
+	Type string `toml:"type" validate:"oneof=memory redis"`
+	// MaxEntries is the max number of entries of the in-process cache
+	MaxEntries int         `toml:"max_entries" validate:"gte=1"`
+	TTLSeconds int         `toml:"ttl_seconds" validate:"gte=1"`
+	Redis      RedisConfig `toml:"redis"`
+}
+
+type RedisConfig struct {
+	Address   string `toml:"address"`
+	Username  string `toml:"username"`
+	Password  string `toml:"password"`
+	DB        int    `toml:"db"`
+	KeyPrefix string `toml:"key_prefix"`
 }
 
 type LoggingConfig struct {
@@ -51,6 +119,11 @@ type PostgresConfig struct {
 	Password string `toml:"password"`
 }
 
+type SQLiteConfig struct {
+	// Path is the path of the database file
+	Path string `toml:"path" validate:"required"`
+}
+
 type S3Config struct {
 	Endpoint        string `toml:"endpoint" validate:"required"`
 	AccessKeyID     string `toml:"access_key_id" validate:"required"`
@@ -59,6 +132,11 @@ type S3Config struct {
 	SSECKey         string `toml:"ssec_key"`
 }
 
+type LocalObjectConfig struct {
+	// Path is the directory where objects are stored, each bucket is a subdirectory
+	Path string `toml:"path" validate:"required"`
+}
+
 type APIConfig struct {
 	Host          string `toml:"host" validate:"required"`
 	Port          int    `toml:"port" validate:"required"`
@@ -67,6 +145,32 @@ type APIConfig struct {
 	StrictCookies bool   `toml:"strict_cookies"`
 }
 
+type MetricsConfig struct {
+	Enabled bool   `toml:"enabled"`
+	Host    string `toml:"host" validate:"required_if=Enabled true"`
+	Port    int    `toml:"port" validate:"required_if=Enabled true"`
+}
+
+type TracingConfig struct {
+	Enabled bool `toml:"enabled"`
+	// Endpoint is the host and port of the OTLP HTTP collector
+	Endpoint    string  `toml:"endpoint" validate:"required_if=Enabled true"`
+	Insecure    bool    `toml:"insecure"`
+	ServiceName string  `toml:"service_name"`
+	SampleRate  float64 `toml:"sample_rate" validate:"gte=0,lte=1"`
+}
+
+type MaintenanceConfig struct {
+	SweepIntervalMinutes int `toml:"sweep_interval_minutes" validate:"gte=1"`
+	// ResumePointTTLHours is the max age of resume points by resume point type, 0 keeps them until they expire
+	ResumePointTTLHours map[string]int `toml:"resume_point_ttl_hours"`
+	// AssetGracePeriodHours is how long a new asset is kept before it's deleted for not being referenced anywhere
+	AssetGracePeriodHours            int `toml:"asset_grace_period_hours" validate:"gte=1"`
+	EphemeralMessageInstanceTTLHours int `toml:"ephemeral_message_instance_ttl_hours"`
+	// MessageInstanceChecksPerSweep is the number of message instances that are checked against Discord per sweep
+	MessageInstanceChecksPerSweep int `toml:"message_instance_checks_per_sweep"`
+}
+
 type AppConfig struct {
 	PublicBaseURL string `toml:"public_base_url" validate:"required"`
 }
@@ -90,11 +194,27 @@ type EngineConfig struct {
 	MaxOperations int    `toml:"max_operations"`
 	MaxCredits    int    `toml:"max_credits"`
 	HTTPProxyURL  string `toml:"http_proxy_url"`
+
+	// Limits for WASM modules, 0 disables the limit
+	MaxModuleMemory     int   `toml:"max_module_memory"`
+	MaxModuleDurationMs int   `toml:"max_module_duration_ms"`
+	MaxModuleFuel       int64 `toml:"max_module_fuel"`
+	MaxModuleIOSize     int   `toml:"max_module_io_size"`
+
+	// Per app limits for flow executions, 0 disables the limit
+	MaxConcurrentFlowsPerApp   int     `toml:"max_concurrent_flows_per_app"`
+	MaxFlowExecutionsPerSecond float64 `toml:"max_flow_executions_per_second"`
+	FlowExecutionBurst         int     `toml:"flow_execution_burst"`
 }
 
 type UserLimitsConfig struct {
-	MaxAppsPerUser int `toml:"max_apps_per_user"`
-	MaxAssetSize   int `toml:"max_asset_size"`
+	MaxAppsPerUser           int `toml:"max_apps_per_user"`
+	MaxAssetSize             int `toml:"max_asset_size"`
+	MaxModuleSize            int `toml:"max_module_size"`
+	MaxModulesPerApp         int `toml:"max_modules_per_app"`
+	MaxNamespacesPerUser     int `toml:"max_namespaces_per_user"`
+	MaxVariablesPerNamespace int `toml:"max_variables_per_namespace"`
+	MaxMembersPerNamespace   int `toml:"max_members_per_namespace"`
 }
 
 type OpenAIConfig struct {
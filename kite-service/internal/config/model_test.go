@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig(t *testing.T) *Config {
+	k, err := defaultBase()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, k.UnmarshalWithConf("", &cfg, koanf.UnmarshalConf{Tag: "toml"}))
+
+	// The defaults don't contain any secrets
+	cfg.Discord.ClientID = "client_id"
+	cfg.Discord.ClientSecret = "client_secret"
+	cfg.Encryption.TokenEncryptionKey = "token_encryption_key"
+	return &cfg
+}
+
+func TestValidateCluster(t *testing.T) {
+	cfg := testConfig(t)
+	require.NoError(t, cfg.Validate())
+
+	// The memory cache isn't shared between clusters
+	cfg.ClusterCount = 2
+	require.ErrorContains(t, cfg.Validate(), "memory cache")
+
+	cfg.Cache.Type = "redis"
+	require.NoError(t, cfg.Validate())
+
+	// The sqlite database and the local object store aren't shared between clusters
+	cfg.Database.Type = "sqlite"
+	cfg.Database.SQLite.Path = "kite.db"
+	require.ErrorContains(t, cfg.Validate(), "sqlite")
+
+	cfg.Database.Type = "postgres"
+	cfg.Database.ObjectStore = "local"
+	cfg.Database.Local.Path = "objects"
+	require.ErrorContains(t, cfg.Validate(), "local object store")
+
+	cfg.ClusterCount = 1
+	cfg.Cache.Type = "memory"
+	cfg.Database.Type = "sqlite"
+	require.NoError(t, cfg.Validate())
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"strconv"
 	"sync"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/diamondburned/arikawa/v3/state"
 	"github.com/kitecloud/kite/kite-service/internal/model"
 	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/internal/util"
 	"github.com/kitecloud/kite/kite-service/pkg/message"
 	"gopkg.in/guregu/null.v4"
 )
@@ -26,9 +28,12 @@ type App struct {
 	pluginInstances map[string]*pluginInstance
 	commands        map[string]*Command
 	listeners       map[string]*EventListener
-	// TODO?: Cache messages (LRUCache<*MessageInstance>)
+	// messageInstances holds the compiled flows of recently used message instances by their ID
+	messageInstances *util.LRU[*MessageInstance]
 }
 
+const maxCachedMessageInstancesPerApp = 100
+
 func NewApp(
 	id string,
 	stores Env,
@@ -39,9 +44,29 @@ func NewApp(
 		commands:        make(map[string]*Command),
 		listeners:       make(map[string]*EventListener),
 		pluginInstances: make(map[string]*pluginInstance),
+
+		messageInstances: util.NewLRU[*MessageInstance](maxCachedMessageInstancesPerApp),
 	}
 }
 
+// messageInstance returns the compiled message instance.
+// The compiled flows are reused until the message instance is updated.
+func (a *App) messageInstance(msg *model.MessageInstance) (*MessageInstance, error) {
+	key := strconv.FormatUint(msg.ID, 10)
+
+	if instance, ok := a.messageInstances.Get(key); ok && instance.msg.UpdatedAt.Equal(msg.UpdatedAt) {
+		return instance, nil
+	}
+
+	instance, err := NewMessageInstance(a.id, msg, a.env)
+	if err != nil {
+		return nil, err
+	}
+
+	a.messageInstances.Set(key, instance, 0)
+	return instance, nil
+}
+
 func (a *App) AddPluginInstance(pluginInstance *model.PluginInstance) {
 	plugin := a.env.PluginRegistry.Plugin(pluginInstance.PluginID)
 	if plugin == nil {
@@ -63,7 +88,7 @@ func (a *App) AddPluginInstance(pluginInstance *model.PluginInstance) {
 			return
 		}
 	} else {
-		instance, err := plugin.Instance(context.TODO(), a.id, pluginInstance.Config)
+		instance, err := plugin.Instance(context.TODO(), a.id, plugin.Config().WithDefaults(pluginInstance.Config))
 		if err != nil {
 			slog.With("error", err).Error("failed to create module instance")
 			return
@@ -118,6 +143,7 @@ func (a *App) AddCommand(cmd *model.Command) {
 	a.Lock()
 	defer a.Unlock()
 	lockDiff := time.Since(lockStart)
+	lockWaitDuration.WithLabelValues("app").Observe(lockDiff.Seconds())
 	if lockDiff > 500*time.Millisecond {
 		slog.Warn(
 			"Locking app for adding command took too long",
@@ -177,8 +203,8 @@ func (a *App) RemoveDanglingEventListeners(listenerIDs []string) {
 	}
 }
 
-func (a *App) HandleEvent(appID string, session *state.State, event gateway.Event) {
-	a.dispatchEventToPlugins(session, event)
+func (a *App) HandleEvent(ctx context.Context, appID string, session *state.State, event gateway.Event) {
+	a.dispatchEventToPlugins(ctx, session, event)
 
 	switch e := event.(type) {
 	case *gateway.InteractionCreateEvent:
@@ -200,6 +226,7 @@ func (a *App) HandleEvent(appID string, session *state.State, event gateway.Even
 			a.RLock()
 			defer a.RUnlock()
 			lockDiff := time.Since(lockStart)
+			lockWaitDuration.WithLabelValues("app").Observe(lockDiff.Seconds())
 			if lockDiff > 100*time.Millisecond {
 				slog.Warn(
 					"Locking app took too long",
@@ -210,7 +237,7 @@ func (a *App) HandleEvent(appID string, session *state.State, event gateway.Even
 
 			for _, command := range a.commands {
 				if command.cmd.Name == fullName {
-					go command.HandleEvent(appID, session, event)
+					go command.HandleEvent(ctx, appID, session, event)
 					break
 				}
 			}
@@ -218,7 +245,7 @@ func (a *App) HandleEvent(appID string, session *state.State, event gateway.Even
 			customID := string(d.CustomID)
 			resumePointID, _, isResume := message.DecodeCustomIDMessageComponentResumePoint(customID)
 			if isResume {
-				resumePoint, err := a.env.ResumePointStore.ResumePoint(context.TODO(), resumePointID)
+				resumePoint, err := a.env.ResumePointStore.ResumePoint(ctx, resumePointID)
 				if err != nil {
 					if errors.Is(err, store.ErrNotFound) {
 						return
@@ -252,7 +279,7 @@ func (a *App) HandleEvent(appID string, session *state.State, event gateway.Even
 					}
 
 					go a.env.executeFlowEvent(
-						context.Background(),
+						ctx,
 						a.id,
 						node,
 						session,
@@ -267,7 +294,7 @@ func (a *App) HandleEvent(appID string, session *state.State, event gateway.Even
 			}
 
 			messageID := e.Message.ID.String()
-			messageInstnace, err := a.env.MessageInstanceStore.MessageInstanceByDiscordMessageID(context.TODO(), messageID)
+			messageInstnace, err := a.env.MessageInstanceStore.MessageInstanceByDiscordMessageID(ctx, messageID)
 			if err != nil {
 				if errors.Is(err, store.ErrNotFound) {
 					return
@@ -277,17 +304,13 @@ func (a *App) HandleEvent(appID string, session *state.State, event gateway.Even
 				return
 			}
 
-			instance, err := NewMessageInstance(
-				a.id,
-				messageInstnace,
-				a.env,
-			)
+			instance, err := a.messageInstance(messageInstnace)
 			if err != nil {
 				slog.With("error", err).Error("failed to create message instance")
 				return
 			}
 
-			go instance.HandleEvent(appID, session, event)
+			go instance.HandleEvent(ctx, appID, session, event)
 		case *discord.ModalInteraction:
 			customID := string(d.CustomID)
 			resumePointID, ok := message.DecodeCustomIDModalResumePoint(customID)
@@ -295,7 +318,7 @@ func (a *App) HandleEvent(appID string, session *state.State, event gateway.Even
 				return
 			}
 
-			resumePoint, err := a.env.ResumePointStore.ResumePoint(context.TODO(), resumePointID)
+			resumePoint, err := a.env.ResumePointStore.ResumePoint(ctx, resumePointID)
 			if err != nil {
 				if errors.Is(err, store.ErrNotFound) {
 					return
@@ -329,7 +352,7 @@ func (a *App) HandleEvent(appID string, session *state.State, event gateway.Even
 				}
 
 				go a.env.executeFlowEvent(
-					context.Background(),
+					ctx,
 					a.id,
 					node,
 					session,
@@ -343,7 +366,7 @@ func (a *App) HandleEvent(appID string, session *state.State, event gateway.Even
 
 			if resumePoint.MessageInstanceID.Valid {
 				messageInstance, err := a.env.MessageInstanceStore.MessageInstance(
-					context.TODO(),
+					ctx,
 					resumePoint.MessageID.String,
 					uint64(resumePoint.MessageInstanceID.Int64),
 				)
@@ -360,11 +383,7 @@ func (a *App) HandleEvent(appID string, session *state.State, event gateway.Even
 					return
 				}
 
-				instance, err := NewMessageInstance(
-					a.id,
-					messageInstance,
-					a.env,
-				)
+				instance, err := a.messageInstance(messageInstance)
 				if err != nil {
 					slog.Error(
 						"Failed to create message instance",
@@ -401,7 +420,7 @@ func (a *App) HandleEvent(appID string, session *state.State, event gateway.Even
 				}
 
 				go a.env.executeFlowEvent(
-					context.Background(),
+					ctx,
 					a.id,
 					node,
 					session,
@@ -426,7 +445,7 @@ func (a *App) HandleEvent(appID string, session *state.State, event gateway.Even
 				continue
 			}
 
-			go listener.HandleEvent(appID, session, event)
+			go listener.HandleEvent(ctx, appID, session, event)
 		}
 	}
 }
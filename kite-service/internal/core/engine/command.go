@@ -4,14 +4,24 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
 	"github.com/diamondburned/arikawa/v3/gateway"
 	"github.com/diamondburned/arikawa/v3/state"
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
 	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/pkg/eval"
 	"github.com/kitecloud/kite/kite-service/pkg/flow"
 	"gopkg.in/guregu/null.v4"
 )
 
+// moduleCommandFunction is the function that is called when a module handles a command.
+const moduleCommandFunction = "handle_command"
+
+const defaultCooldownMessage = "This command is on cooldown, try again {{discord_timestamp(cooldown.reset_at, 'R')}}."
+
 type Command struct {
 	cmd  *model.Command
 	flow *flow.CompiledFlowNode
@@ -40,18 +50,118 @@ func NewCommand(
 	}, nil
 }
 
-func (c *Command) HandleEvent(appID string, session *state.State, event gateway.Event) {
+func (c *Command) HandleEvent(ctx context.Context, appID string, session *state.State, event gateway.Event) {
 	links := entityLinks{
 		CommandID: null.NewString(c.cmd.ID, true),
 	}
 
-	c.env.executeFlowEvent(
-		context.Background(),
+	// The governor is checked first, so uses that are throttled don't count towards the cooldown
+	release, ok := c.env.acquireExecution(ctx, appID, session, event, links)
+	if !ok {
+		return
+	}
+
+	if !c.checkCooldown(ctx, session, event, links) {
+		release(0)
+		return
+	}
+
+	if c.cmd.ModuleID.Valid {
+		e, ok := event.(*gateway.InteractionCreateEvent)
+		if !ok {
+			release(0)
+			return
+		}
+
+		c.env.executeModuleCommand(ctx, c.cmd.AppID, c.cmd.ModuleID.String, session, e, links, release)
+		return
+	}
+
+	c.env.executeAcquiredFlowEvent(
+		ctx,
 		c.cmd.AppID,
 		c.flow,
 		session,
 		event,
 		links,
 		nil,
+		release,
 	)
 }
+
+// checkCooldown registers the command use in the cooldown bucket and returns false
+// if the command is on cooldown. The user is notified with an ephemeral response in that case.
+func (c *Command) checkCooldown(ctx context.Context, session *state.State, event gateway.Event, links entityLinks) bool {
+	e, ok := event.(*gateway.InteractionCreateEvent)
+	if !ok {
+		return true
+	}
+
+	cooldown := c.flow.CommandCooldown()
+	if cooldown == nil || c.env.CooldownStore == nil {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	key := c.cmd.ID + ":" + cooldown.BucketKey(&e.InteractionEvent)
+
+	hit, err := c.env.CooldownStore.HitCooldown(ctx, c.cmd.AppID, key, cooldown.Duration(), now)
+	if err != nil {
+		// We don't want to block commands when the cooldown store is unavailable
+		slog.Error(
+			"Failed to check command cooldown",
+			slog.String("app_id", c.cmd.AppID),
+			slog.String("command_id", c.cmd.ID),
+			slog.String("error", err.Error()),
+		)
+		return true
+	}
+
+	if hit.Count <= cooldown.MaxUses() {
+		return true
+	}
+
+	message := cooldown.Message
+	if message == "" {
+		message = defaultCooldownMessage
+	}
+
+	evalCtx := eval.NewContextFromInteraction(&e.InteractionEvent, session)
+	evalCtx.Env["cooldown"] = map[string]any{
+		"reset_at":  hit.ExpiresAt,
+		"remaining": hit.ExpiresAt.Sub(now),
+		"limit":     cooldown.MaxUses(),
+	}
+
+	content, err := eval.EvalTemplateToString(ctx, message, evalCtx)
+	if err != nil {
+		c.env.createLogEntry(
+			c.cmd.AppID,
+			model.LogLevelError,
+			fmt.Sprintf("Failed to evaluate cooldown message: %v", err),
+			links,
+		)
+		return false
+	}
+
+	err = session.RespondInteraction(e.ID, e.Token, api.InteractionResponse{
+		Type: api.MessageInteractionWithSource,
+		Data: &api.InteractionResponseData{
+			Content: option.NewNullableString(content),
+			Flags:   discord.EphemeralMessage,
+		},
+	})
+	if err != nil {
+		c.env.createLogEntry(
+			c.cmd.AppID,
+			model.LogLevelError,
+			fmt.Sprintf("Failed to respond with cooldown message: %v", err),
+			links,
+		)
+	}
+
+	return false
+}
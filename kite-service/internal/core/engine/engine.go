@@ -9,6 +9,7 @@ import (
 
 	"github.com/diamondburned/arikawa/v3/gateway"
 	"github.com/diamondburned/arikawa/v3/state"
+	"github.com/kitecloud/kite/kite-service/internal/tracing"
 	"github.com/kitecloud/kite/kite-service/internal/util"
 )
 
@@ -83,6 +84,9 @@ func (e *Engine) Run(ctx context.Context) {
 						slog.String("error", err.Error()),
 					)
 				}
+				if e.env.Governor != nil {
+					e.env.Governor.RemoveIdleApps()
+				}
 			}
 		}
 	}()
@@ -98,6 +102,7 @@ func (e *Engine) populatePlugins(ctx context.Context, lastUpdate time.Time) erro
 	e.Lock()
 	defer e.Unlock()
 	lockDiff := time.Since(lockStart)
+	lockWaitDuration.WithLabelValues("engine").Observe(lockDiff.Seconds())
 	if lockDiff > 5*time.Second {
 		slog.Warn(
 			"Locking engine for plugins took too long",
@@ -151,6 +156,7 @@ func (e *Engine) populateCommands(ctx context.Context, lastUpdate time.Time) err
 	e.Lock()
 	defer e.Unlock()
 	lockDiff := time.Since(lockStart)
+	lockWaitDuration.WithLabelValues("engine").Observe(lockDiff.Seconds())
 	if lockDiff > 5*time.Second {
 		slog.Warn(
 			"Locking engine for commands took too long",
@@ -240,12 +246,16 @@ func (e *Engine) removeDanglingEventListeners(ctx context.Context) error {
 }
 
 // HandleEvent blocks until the event is handled by the corresponding app.
-func (e *Engine) HandleEvent(appID string, session *state.State, event gateway.Event) {
+func (e *Engine) HandleEvent(ctx context.Context, appID string, session *state.State, event gateway.Event) {
+	ctx, span := tracing.Tracer().Start(ctx, "engine.HandleEvent")
+	defer span.End()
+
 	lockStart := time.Now()
 	e.RLock()
 	app := e.apps[appID]
 	e.RUnlock()
 	lockDiff := time.Since(lockStart)
+	lockWaitDuration.WithLabelValues("engine").Observe(lockDiff.Seconds())
 	if lockDiff > 500*time.Millisecond {
 		slog.Warn(
 			"Locking engine for handling event took too long",
@@ -255,7 +265,7 @@ func (e *Engine) HandleEvent(appID string, session *state.State, event gateway.E
 	}
 
 	if app != nil {
-		app.HandleEvent(appID, session, event)
+		app.HandleEvent(ctx, appID, session, event)
 	}
 }
 
@@ -263,6 +273,7 @@ type EngineConfig struct {
 	MaxStackDepth int
 	MaxOperations int
 	MaxCredits    int
+	MaxFileSize   int
 	ClusterCount  int
 	ClusterIndex  int
 }
@@ -41,7 +41,7 @@ func NewEventListener(
 	}, nil
 }
 
-func (l *EventListener) HandleEvent(appID string, session *state.State, event gateway.Event) {
+func (l *EventListener) HandleEvent(ctx context.Context, appID string, session *state.State, event gateway.Event) {
 	links := entityLinks{
 		EventListenerID: null.NewString(l.listener.ID, true),
 	}
@@ -52,7 +52,7 @@ func (l *EventListener) HandleEvent(appID string, session *state.State, event ga
 	}
 
 	l.env.executeFlowEvent(
-		context.Background(),
+		ctx,
 		l.listener.AppID,
 		l.flow,
 		session,
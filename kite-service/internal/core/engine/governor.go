@@ -0,0 +1,254 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// governorCreditRefreshInterval is how often the live credit counter is re-synced with the usage store.
+	governorCreditRefreshInterval = 5 * time.Minute
+	// governorThrottleLogInterval limits how often throttling is logged per app.
+	governorThrottleLogInterval = 30 * time.Second
+	// governorIdleExpiry is how long an app can be idle before its state is dropped.
+	governorIdleExpiry = 30 * time.Minute
+)
+
+var (
+	ErrTooManyConcurrentExecutions = errors.New("too many concurrent flow executions")
+	ErrExecutionRateLimited        = errors.New("flow execution rate limit exceeded")
+	ErrNoCreditsRemaining          = errors.New("no usage credits remaining")
+)
+
+// ThrottledError is returned when the governor doesn't allow a flow execution.
+// Report is only set for the first throttle of an app in a while, to avoid flooding the logs.
+type ThrottledError struct {
+	Reason error
+	Report bool
+}
+
+func (e *ThrottledError) Error() string {
+	return e.Reason.Error()
+}
+
+func (e *ThrottledError) Unwrap() error {
+	return e.Reason
+}
+
+// AppFeatureProvider is implemented by plan.PlanManager.
+type AppFeatureProvider interface {
+	AppFeatures(ctx context.Context, appID string) model.Features
+}
+
+type GovernorConfig struct {
+	// MaxConcurrentFlows is the max number of flows that can run at the same time per app, 0 means no limit.
+	MaxConcurrentFlows int
+	// MaxExecutionsPerSecond is the rate at which flow executions are refilled per app, 0 means no limit.
+	MaxExecutionsPerSecond float64
+	// ExecutionBurst is the number of flow executions that can be started at once per app.
+	ExecutionBurst int
+}
+
+// Governor limits the flow executions of each app so a single app can't degrade others on the same cluster.
+// It also keeps a live count of the credits used this month, so apps are stopped before they exceed their credits.
+type Governor struct {
+	sync.Mutex
+
+	config          GovernorConfig
+	usageStore      store.UsageStore
+	featureProvider AppFeatureProvider
+
+	apps map[string]*appGovernor
+}
+
+func NewGovernor(
+	config GovernorConfig,
+	usageStore store.UsageStore,
+	featureProvider AppFeatureProvider,
+) *Governor {
+	return &Governor{
+		config:          config,
+		usageStore:      usageStore,
+		featureProvider: featureProvider,
+		apps:            make(map[string]*appGovernor),
+	}
+}
+
+type appGovernor struct {
+	sync.Mutex
+
+	running int
+	limiter *rate.Limiter
+
+	creditsUsed     int
+	creditsLimit    int
+	creditsMonth    time.Time
+	creditsSyncedAt time.Time
+
+	lastUsedAt       time.Time
+	lastThrottleLogs map[error]time.Time
+}
+
+func (g *Governor) app(appID string) *appGovernor {
+	g.Lock()
+	defer g.Unlock()
+
+	app, ok := g.apps[appID]
+	if !ok {
+		limit := rate.Inf
+		if g.config.MaxExecutionsPerSecond > 0 {
+			limit = rate.Limit(g.config.MaxExecutionsPerSecond)
+		}
+
+		app = &appGovernor{
+			limiter:          rate.NewLimiter(limit, max(g.config.ExecutionBurst, 1)),
+			lastThrottleLogs: make(map[error]time.Time),
+		}
+		g.apps[appID] = app
+	}
+
+	return app
+}
+
+// Acquire checks if the app is allowed to execute a flow right now.
+// If it is, the returned release function must be called with the credits used once the execution is done.
+func (g *Governor) Acquire(ctx context.Context, appID string) (release func(creditsUsed int), err error) {
+	app := g.app(appID)
+
+	if err := g.syncCredits(ctx, appID, app); err != nil {
+		// We don't want to block executions because the usage store is unavailable
+		slog.Error(
+			"Failed to sync usage credits in governor",
+			slog.String("app_id", appID),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	app.Lock()
+	defer app.Unlock()
+
+	now := time.Now().UTC()
+	app.lastUsedAt = now
+
+	switch {
+	case app.creditsLimit > 0 && app.creditsUsed >= app.creditsLimit:
+		err = ErrNoCreditsRemaining
+	case g.config.MaxConcurrentFlows > 0 && app.running >= g.config.MaxConcurrentFlows:
+		err = ErrTooManyConcurrentExecutions
+	case !app.limiter.AllowN(now, 1):
+		err = ErrExecutionRateLimited
+	}
+
+	if err != nil {
+		throttledFlowExecutionsTotal.WithLabelValues(throttleReason(err)).Inc()
+
+		report := now.Sub(app.lastThrottleLogs[err]) > governorThrottleLogInterval
+		if report {
+			app.lastThrottleLogs[err] = now
+			slog.Warn(
+				"Throttled flow execution",
+				slog.String("app_id", appID),
+				slog.String("reason", throttleReason(err)),
+				slog.Int("running", app.running),
+				slog.Int("credits_used", app.creditsUsed),
+				slog.Int("credits_limit", app.creditsLimit),
+			)
+		}
+		return nil, &ThrottledError{Reason: err, Report: report}
+	}
+
+	app.running++
+	runningFlowExecutions.Inc()
+
+	var once sync.Once
+	return func(creditsUsed int) {
+		once.Do(func() {
+			app.Lock()
+			defer app.Unlock()
+
+			app.running--
+			app.creditsUsed += creditsUsed
+			runningFlowExecutions.Dec()
+		})
+	}, nil
+}
+
+// syncCredits loads the credits used and the credit limit from the usage store and plan,
+// when the app is seen for the first time, the month has changed, or the last sync is too old.
+func (g *Governor) syncCredits(ctx context.Context, appID string, app *appGovernor) error {
+	now := time.Now().UTC()
+	start, end := startAndEndOfMonth(now)
+
+	app.Lock()
+	needsSync := !app.creditsMonth.Equal(start) || now.Sub(app.creditsSyncedAt) > governorCreditRefreshInterval
+	if needsSync {
+		// Prevent concurrent executions from syncing at the same time
+		app.creditsSyncedAt = now
+	}
+	app.Unlock()
+
+	if !needsSync {
+		return nil
+	}
+
+	creditsUsed, err := g.usageStore.UsageCreditsUsedBetween(ctx, appID, start, end)
+	if err != nil {
+		return err
+	}
+
+	creditsLimit := 0
+	if g.featureProvider != nil {
+		creditsLimit = g.featureProvider.AppFeatures(ctx, appID).UsageCreditsPerMonth
+	}
+
+	app.Lock()
+	defer app.Unlock()
+
+	app.creditsUsed = creditsUsed
+	app.creditsLimit = creditsLimit
+	app.creditsMonth = start
+	return nil
+}
+
+// RemoveIdleApps drops the state of apps that haven't executed any flows recently.
+func (g *Governor) RemoveIdleApps() {
+	g.Lock()
+	defer g.Unlock()
+
+	for appID, app := range g.apps {
+		app.Lock()
+		idle := app.running == 0 && time.Since(app.lastUsedAt) > governorIdleExpiry
+		app.Unlock()
+
+		if idle {
+			delete(g.apps, appID)
+		}
+	}
+}
+
+func throttleReason(err error) string {
+	switch err {
+	case ErrNoCreditsRemaining:
+		return "credits"
+	case ErrTooManyConcurrentExecutions:
+		return "concurrency"
+	case ErrExecutionRateLimited:
+		return "rate_limit"
+	default:
+		return "unknown"
+	}
+}
+
+func startAndEndOfMonth(t time.Time) (time.Time, time.Time) {
+	year, month, _ := t.Date()
+	start := time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	end := start.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	return start, end
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+type testUsageStore struct {
+	store.UsageStore
+
+	creditsUsed int
+}
+
+func (s *testUsageStore) UsageCreditsUsedBetween(ctx context.Context, appID string, start time.Time, end time.Time) (int, error) {
+	return s.creditsUsed, nil
+}
+
+type testFeatureProvider struct {
+	features model.Features
+}
+
+func (p *testFeatureProvider) AppFeatures(ctx context.Context, appID string) model.Features {
+	return p.features
+}
+
+func TestGovernorConcurrency(t *testing.T) {
+	g := NewGovernor(GovernorConfig{MaxConcurrentFlows: 2}, &testUsageStore{}, nil)
+
+	release1, err := g.Acquire(context.Background(), "app")
+	require.NoError(t, err)
+	_, err = g.Acquire(context.Background(), "app")
+	require.NoError(t, err)
+
+	_, err = g.Acquire(context.Background(), "app")
+	require.ErrorIs(t, err, ErrTooManyConcurrentExecutions)
+
+	// Other apps are not affected
+	_, err = g.Acquire(context.Background(), "other")
+	require.NoError(t, err)
+
+	release1(0)
+	release1(0) // Releasing twice must not free another slot
+
+	_, err = g.Acquire(context.Background(), "app")
+	require.NoError(t, err)
+	_, err = g.Acquire(context.Background(), "app")
+	require.ErrorIs(t, err, ErrTooManyConcurrentExecutions)
+}
+
+func TestGovernorRateLimit(t *testing.T) {
+	g := NewGovernor(GovernorConfig{MaxExecutionsPerSecond: 1, ExecutionBurst: 3}, &testUsageStore{}, nil)
+
+	for range 3 {
+		release, err := g.Acquire(context.Background(), "app")
+		require.NoError(t, err)
+		release(0)
+	}
+
+	_, err := g.Acquire(context.Background(), "app")
+	require.ErrorIs(t, err, ErrExecutionRateLimited)
+
+	var throttleErr *ThrottledError
+	require.True(t, errors.As(err, &throttleErr))
+	require.True(t, throttleErr.Report)
+
+	// Only the first throttle in a while should be reported
+	_, err = g.Acquire(context.Background(), "app")
+	require.True(t, errors.As(err, &throttleErr))
+	require.False(t, throttleErr.Report)
+}
+
+func TestGovernorCredits(t *testing.T) {
+	g := NewGovernor(
+		GovernorConfig{},
+		&testUsageStore{creditsUsed: 90},
+		&testFeatureProvider{features: model.Features{UsageCreditsPerMonth: 100}},
+	)
+
+	release, err := g.Acquire(context.Background(), "app")
+	require.NoError(t, err)
+	release(10)
+
+	// The credits used are tracked live without waiting for the usage store
+	_, err = g.Acquire(context.Background(), "app")
+	require.ErrorIs(t, err, ErrNoCreditsRemaining)
+}
@@ -2,22 +2,32 @@ package engine
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"runtime/debug"
 	"time"
 
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
 	"github.com/diamondburned/arikawa/v3/gateway"
 	"github.com/diamondburned/arikawa/v3/state"
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
 	"github.com/kitecloud/kite/kite-service/internal/model"
 	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/internal/tracing"
 	"github.com/kitecloud/kite/kite-service/internal/util"
 	"github.com/kitecloud/kite/kite-service/pkg/eval"
 	"github.com/kitecloud/kite/kite-service/pkg/flow"
 	"github.com/kitecloud/kite/kite-service/pkg/plugin"
 	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/kitecloud/kite/kite-service/pkg/wasm"
 	"github.com/openai/openai-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/guregu/null.v4"
 )
 
@@ -33,8 +43,15 @@ type Env struct {
 	PluginInstanceStore  store.PluginInstanceStore
 	PluginValueStore     store.PluginValueStore
 	PluginRegistry       *plugin.Registry
+	VariableStore        store.VariableStore
 	VariableValueStore   store.VariableValueStore
+	NamespaceStore       store.NamespaceStore
+	ModuleStore          store.ModuleStore
+	WasmRuntime          *wasm.Runtime
 	ResumePointStore     store.ResumePointStore
+	CooldownStore        store.CooldownStore
+	AssetStore           store.AssetStore
+	Governor             *Governor
 	HttpClient           *http.Client
 	OpenaiClient         *openai.Client
 	TokenCrypt           *util.SymmetricCrypt
@@ -48,6 +65,20 @@ type entityLinks struct {
 	FlowSourceID      null.String // For message templates that have multiple flows
 }
 
+func (l entityLinks) traceAttributes(appID string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{attribute.String("app_id", appID)}
+	if l.CommandID.Valid {
+		attrs = append(attrs, attribute.String("command_id", l.CommandID.String))
+	}
+	if l.EventListenerID.Valid {
+		attrs = append(attrs, attribute.String("event_listener_id", l.EventListenerID.String))
+	}
+	if l.MessageID.Valid {
+		attrs = append(attrs, attribute.String("message_id", l.MessageID.String))
+	}
+	return attrs
+}
+
 func (s Env) flowProviders(appID string, session *state.State, links entityLinks) flow.FlowProviders {
 	var aiProvider provider.AIProvider = &provider.MockAIProvider{}
 	if s.OpenaiClient != nil {
@@ -65,12 +96,15 @@ func (s Env) flowProviders(appID string, session *state.State, links entityLinks
 		HTTP:            NewHTTPProvider(s.HttpClient),
 		AI:              aiProvider,
 		MessageTemplate: NewMessageTemplateProvider(s.MessageStore, s.MessageInstanceStore),
-		Variable:        NewVariableProvider(s.VariableValueStore),
+		Variable:        NewVariableProvider(appID, s.VariableStore, s.VariableValueStore, s.NamespaceStore),
+		Asset:           NewAssetProvider(appID, s.AppStore, s.AssetStore),
+		Module:          NewModuleProvider(appID, s.ModuleStore, s.VariableStore, s.WasmRuntime),
 		ResumePoint: NewResumePointProvider(
 			s.ResumePointStore,
 			appID,
 			links,
 		),
+		NodeObserver: metricsNodeObserver{},
 	}
 }
 
@@ -99,6 +133,7 @@ func (s Env) flowContext(
 				MaxStackDepth: s.Config.MaxStackDepth,
 				MaxOperations: s.Config.MaxOperations,
 				MaxCredits:    s.Config.MaxCredits,
+				MaxFileSize:   s.Config.MaxFileSize,
 			},
 			eval.NewContextFromInteraction(&e.InteractionEvent, session),
 			state,
@@ -115,6 +150,7 @@ func (s Env) flowContext(
 				MaxStackDepth: s.Config.MaxStackDepth,
 				MaxOperations: s.Config.MaxOperations,
 				MaxCredits:    s.Config.MaxCredits,
+				MaxFileSize:   s.Config.MaxFileSize,
 			},
 			eval.NewContextFromEvent(event, session),
 			state,
@@ -132,17 +168,59 @@ func (s Env) executeFlowEvent(
 	event gateway.Event,
 	links entityLinks,
 	state *flow.FlowContextState,
+) {
+	s.executeFlow(ctx, appID, node, session, event, links, state, nil)
+}
+
+// executeAcquiredFlowEvent is like executeFlowEvent, but for callers that have already acquired
+// the execution from the governor. The release function is called once the flow is done.
+func (s Env) executeAcquiredFlowEvent(
+	ctx context.Context,
+	appID string,
+	node *flow.CompiledFlowNode,
+	session *state.State,
+	event gateway.Event,
+	links entityLinks,
+	state *flow.FlowContextState,
+	release func(creditsUsed int),
+) {
+	s.executeFlow(ctx, appID, node, session, event, links, state, release)
+}
+
+func (s Env) executeFlow(
+	ctx context.Context,
+	appID string,
+	node *flow.CompiledFlowNode,
+	session *state.State,
+	event gateway.Event,
+	links entityLinks,
+	state *flow.FlowContextState,
+	release func(creditsUsed int),
 ) {
 	defer s.recoverPanic(appID, links)
 
+	ctx, span := tracing.Tracer().Start(ctx, "engine.executeFlowEvent", trace.WithAttributes(
+		links.traceAttributes(appID)...,
+	))
+	defer span.End()
+
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	fCtx := s.flowContext(ctx, appID, session, event, links, state)
 	defer fCtx.Cancel()
 
+	if release != nil {
+		defer func() {
+			release(fCtx.CreditsUsed())
+		}()
+	}
+
 	shouldExecute, err := node.FilterEvent(fCtx)
 	if err != nil {
+		flowExecutionsTotal.WithLabelValues("filter_error").Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to filter event")
 		s.createLogEntry(
 			appID,
 			model.LogLevelError,
@@ -156,23 +234,197 @@ func (s Env) executeFlowEvent(
 		return
 	}
 
+	if release == nil {
+		release, ok := s.acquireExecution(ctx, appID, session, event, links)
+		if !ok {
+			return
+		}
+		defer func() {
+			release(fCtx.CreditsUsed())
+		}()
+	}
+
 	err = node.Execute(fCtx)
+	s.finishExecution(span, appID, fCtx.CreditsUsed(), err, "Failed to execute flow event", links)
+}
+
+// executeModuleCommand lets a module handle the command instead of the flow of the command.
+// The module gets the interaction as its input and is responsible for responding to it.
+// The execution must have been acquired from the governor, release is called once the module is done.
+func (s Env) executeModuleCommand(
+	ctx context.Context,
+	appID string,
+	moduleID string,
+	session *state.State,
+	event *gateway.InteractionCreateEvent,
+	links entityLinks,
+	release func(creditsUsed int),
+) {
+	defer s.recoverPanic(appID, links)
+
+	ctx, span := tracing.Tracer().Start(ctx, "engine.executeModuleCommand", trace.WithAttributes(
+		append(links.traceAttributes(appID), attribute.String("module_id", moduleID))...,
+	))
+	defer span.End()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	fCtx := s.flowContext(ctx, appID, session, event, links, nil)
+	defer fCtx.Cancel()
+
+	defer func() {
+		release(fCtx.CreditsUsed())
+	}()
+
+	err := func() error {
+		input, err := json.Marshal(&event.InteractionEvent)
+		if err != nil {
+			return fmt.Errorf("failed to encode interaction: %w", err)
+		}
+
+		host := fCtx.ModuleHost()
+		if err := host.StartOperation(flow.ModuleCallCreditsCost); err != nil {
+			return err
+		}
+
+		_, err = fCtx.Module.CallModuleFunction(fCtx, moduleID, moduleCommandFunction, input, host)
+		return err
+	}()
+
+	s.finishExecution(span, appID, fCtx.CreditsUsed(), err, "Failed to execute module command", links)
+
 	if err != nil {
+		if respErr := respondModuleError(fCtx.Discord, &event.InteractionEvent); respErr != nil {
+			s.createLogEntry(
+				appID,
+				model.LogLevelError,
+				fmt.Sprintf("Failed to respond to failed module command: %v", respErr),
+				links,
+			)
+		}
+	}
+}
+
+// respondModuleError lets the user know that the module failed to handle the interaction.
+// Modules respond to interactions themselves, so the interaction would fail silently otherwise.
+func respondModuleError(discordProvider provider.DiscordProvider, interaction *discord.InteractionEvent) error {
+	// The context of the execution may be done already, for example when the module timed out.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	data := api.InteractionResponseData{
+		Content: option.NewNullableString("An error occurred while executing the command, please try again later."),
+		Flags:   discord.EphemeralMessage,
+	}
+
+	hasCreatedResponse, err := discordProvider.HasCreatedInteractionResponse(ctx, interaction.ID)
+	if err != nil {
+		return err
+	}
+
+	if hasCreatedResponse {
+		_, err = discordProvider.CreateInteractionFollowup(ctx, interaction.AppID, interaction.Token, data)
+		return err
+	}
+
+	_, err = discordProvider.CreateInteractionResponse(ctx, interaction.ID, interaction.Token, api.InteractionResponse{
+		Type: api.MessageInteractionWithSource,
+		Data: &data,
+	})
+	return err
+}
+
+// finishExecution records the outcome of a flow or module execution in the metrics, the trace
+// and the logs of the app and creates the usage record for the credits that have been used.
+func (s Env) finishExecution(span trace.Span, appID string, creditsUsed int, err error, failure string, links entityLinks) {
+	flowExecutionsTotal.WithLabelValues(outcomeLabel(err)).Inc()
+	creditsUsedTotal.Add(float64(creditsUsed))
+	span.SetAttributes(attribute.Int("credits_used", creditsUsed))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, failure)
 		s.createLogEntry(
 			appID,
 			model.LogLevelError,
-			fmt.Sprintf("Failed to execute flow event: %v", err),
+			fmt.Sprintf("%s: %v", failure, err),
 			links,
 		)
 	}
 
 	s.createUsageRecord(
 		appID,
-		fCtx.CreditsUsed(),
+		creditsUsed,
 		links,
 	)
 }
 
+// acquireExecution asks the governor if the app is allowed to start an execution right now.
+// Throttled interactions are answered with an ephemeral message, so the user isn't left with a failed interaction.
+func (s Env) acquireExecution(
+	ctx context.Context,
+	appID string,
+	session *state.State,
+	event gateway.Event,
+	links entityLinks,
+) (release func(creditsUsed int), ok bool) {
+	if s.Governor == nil {
+		return func(int) {}, true
+	}
+
+	release, err := s.Governor.Acquire(ctx, appID)
+	if err == nil {
+		return release, true
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("throttle_reason", throttleReason(errors.Unwrap(err))))
+
+	var throttleErr *ThrottledError
+	if errors.As(err, &throttleErr) && throttleErr.Report {
+		s.createLogEntry(
+			appID,
+			model.LogLevelWarn,
+			fmt.Sprintf("Execution has been throttled: %v", err),
+			links,
+		)
+	}
+
+	if e, ok := event.(*gateway.InteractionCreateEvent); ok && session != nil {
+		s.respondThrottled(appID, session, e, err, links)
+	}
+	return nil, false
+}
+
+func (s Env) respondThrottled(appID string, session *state.State, e *gateway.InteractionCreateEvent, err error, links entityLinks) {
+	switch e.Data.(type) {
+	case *discord.CommandInteraction, discord.ComponentInteraction, *discord.ModalInteraction:
+	default:
+		// Autocomplete and ping interactions can't be answered with a message
+		return
+	}
+
+	content := "This app is being rate limited, please try again in a moment."
+	if errors.Is(err, ErrNoCreditsRemaining) {
+		content = "This app has used up all of its credits for this month."
+	}
+
+	respErr := session.RespondInteraction(e.ID, e.Token, api.InteractionResponse{
+		Type: api.MessageInteractionWithSource,
+		Data: &api.InteractionResponseData{
+			Content: option.NewNullableString(content),
+			Flags:   discord.EphemeralMessage,
+		},
+	})
+	if respErr != nil {
+		s.createLogEntry(
+			appID,
+			model.LogLevelError,
+			fmt.Sprintf("Failed to respond to throttled interaction: %v", respErr),
+			links,
+		)
+	}
+}
+
 func (s Env) createLogEntry(appID string, level model.LogLevel, message string, links entityLinks) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testDiscordProvider struct {
+	provider.MockDiscordProvider
+
+	hasResponse bool
+	responses   []api.InteractionResponse
+	followups   []api.InteractionResponseData
+}
+
+func (p *testDiscordProvider) HasCreatedInteractionResponse(ctx context.Context, interactionID discord.InteractionID) (bool, error) {
+	return p.hasResponse, nil
+}
+
+func (p *testDiscordProvider) CreateInteractionResponse(ctx context.Context, interactionID discord.InteractionID, interactionToken string, response api.InteractionResponse) (*provider.InteractionResponseResource, error) {
+	p.responses = append(p.responses, response)
+	return nil, nil
+}
+
+func (p *testDiscordProvider) CreateInteractionFollowup(ctx context.Context, applicationID discord.AppID, token string, data api.InteractionResponseData) (*discord.Message, error) {
+	p.followups = append(p.followups, data)
+	return nil, nil
+}
+
+func TestRespondModuleError(t *testing.T) {
+	interaction := &discord.InteractionEvent{ID: 1, AppID: 2, Token: "token"}
+
+	// Interactions that the module didn't respond to get an ephemeral response
+	p := &testDiscordProvider{}
+	require.NoError(t, respondModuleError(p, interaction))
+	require.Len(t, p.responses, 1)
+	assert.Equal(t, api.MessageInteractionWithSource, p.responses[0].Type)
+	assert.Equal(t, discord.EphemeralMessage, p.responses[0].Data.Flags)
+	assert.Empty(t, p.followups)
+
+	// Interactions that the module already responded to get a followup
+	p = &testDiscordProvider{hasResponse: true}
+	require.NoError(t, respondModuleError(p, interaction))
+	assert.Empty(t, p.responses)
+	require.Len(t, p.followups, 1)
+	assert.Equal(t, discord.EphemeralMessage, p.followups[0].Flags)
+}
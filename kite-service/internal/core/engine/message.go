@@ -49,7 +49,7 @@ func NewMessageInstance(
 	}, nil
 }
 
-func (m *MessageInstance) HandleEvent(appID string, session *state.State, event gateway.Event) {
+func (m *MessageInstance) HandleEvent(ctx context.Context, appID string, session *state.State, event gateway.Event) {
 	i, ok := event.(*gateway.InteractionCreateEvent)
 	if !ok {
 		return
@@ -74,7 +74,7 @@ func (m *MessageInstance) HandleEvent(appID string, session *state.State, event
 	}
 
 	m.env.executeFlowEvent(
-		context.Background(),
+		ctx,
 		m.appID,
 		targetFlow,
 		session,
@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/pkg/flow"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	runningFlowExecutions = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "kite",
+		Subsystem: "engine",
+		Name:      "flow_executions_running",
+		Help:      "The number of flow executions that are currently running.",
+	})
+	throttledFlowExecutionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kite",
+		Subsystem: "engine",
+		Name:      "flow_executions_throttled_total",
+		Help:      "The number of flow executions that have been rejected by the governor.",
+	}, []string{"reason"})
+	flowExecutionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kite",
+		Subsystem: "engine",
+		Name:      "flow_executions_total",
+		Help:      "The number of flow executions by outcome.",
+	}, []string{"outcome"})
+	flowNodeExecutionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kite",
+		Subsystem: "engine",
+		Name:      "flow_node_executions_total",
+		Help:      "The number of executed flow nodes by node type and outcome.",
+	}, []string{"node_type", "outcome"})
+	flowNodeDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "kite",
+		Subsystem: "engine",
+		Name:      "flow_node_duration_seconds",
+		Help:      "The time it took to execute a flow node including its children.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 4, 8),
+	}, []string{"node_type"})
+	creditsUsedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "kite",
+		Subsystem: "engine",
+		Name:      "credits_used_total",
+		Help:      "The number of usage credits consumed by flow executions.",
+	})
+	lockWaitDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "kite",
+		Subsystem: "engine",
+		Name:      "lock_wait_seconds",
+		Help:      "The time spent waiting to acquire engine and app locks.",
+		Buckets:   prometheus.ExponentialBuckets(0.0001, 5, 8),
+	}, []string{"lock"})
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "kite",
+		Subsystem: "engine",
+		Name:      "http_request_duration_seconds",
+		Help:      "The duration of HTTP requests made by flows.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"status"})
+)
+
+func outcomeLabel(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
+
+func httpStatusLabel(status int) string {
+	if status == 0 {
+		return "error"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
+
+// metricsNodeObserver records the execution of every flow node.
+type metricsNodeObserver struct{}
+
+func (metricsNodeObserver) ObserveNode(ctx *flow.FlowContext, node *flow.CompiledFlowNode) func(err error) {
+	start := time.Now()
+	return func(err error) {
+		nodeType := string(node.Type)
+		flowNodeExecutionsTotal.WithLabelValues(nodeType, outcomeLabel(err)).Inc()
+		flowNodeDuration.WithLabelValues(nodeType).Observe(time.Since(start).Seconds())
+	}
+}
@@ -42,7 +42,7 @@ func newPluginInstance(
 func (p *pluginInstance) Update(ctx context.Context, model *model.PluginInstance) error {
 	p.model = model
 	p.eventTypes = computeEventTypes(p.plugin, model.EnabledResourceIDs)
-	return p.instance.Update(ctx, model.Config)
+	return p.instance.Update(ctx, p.plugin.Config().WithDefaults(model.Config))
 }
 
 func (p *pluginInstance) Close() error {
@@ -74,13 +74,22 @@ func (p *pluginInstance) Events() []plugin.Event {
 }
 
 func (p *pluginInstance) HandleEvent(ctx context.Context, session *state.State, event gateway.Event) {
+	if !p.handles(event) {
+		return
+	}
+
+	release, ok := p.env.acquireExecution(ctx, p.model.AppID, session, event, entityLinks{})
+	if !ok {
+		return
+	}
+	defer release(0)
+
 	var err error
 
 	switch e := event.(type) {
 	case *gateway.InteractionCreateEvent:
 		switch e.Data.(type) {
 		case *discord.CommandInteraction:
-			// TODO: Check if the command belongs to this plugin
 			err = p.instance.HandleCommand(p.pluginContext(ctx, session), e)
 		case discord.ComponentInteraction:
 			err = p.instance.HandleComponent(p.pluginContext(ctx, session), e)
@@ -88,11 +97,6 @@ func (p *pluginInstance) HandleEvent(ctx context.Context, session *state.State,
 			err = p.instance.HandleModal(p.pluginContext(ctx, session), e)
 		}
 	default:
-		wants := p.eventTypes[event.EventType()]
-		if !wants {
-			return
-		}
-
 		err = p.instance.HandleEvent(p.pluginContext(ctx, session), event)
 	}
 
@@ -107,12 +111,38 @@ func (p *pluginInstance) HandleEvent(ctx context.Context, session *state.State,
 	}
 }
 
+// handles returns true if the event is meant for this plugin instance.
+// Only those events count towards the limits of the app.
+func (p *pluginInstance) handles(event gateway.Event) bool {
+	e, ok := event.(*gateway.InteractionCreateEvent)
+	if !ok {
+		return p.eventTypes[event.EventType()]
+	}
+
+	switch d := e.Data.(type) {
+	case *discord.CommandInteraction:
+		for _, command := range p.Commands() {
+			if command.Data.Name == d.Name {
+				return true
+			}
+		}
+		return false
+	case discord.ComponentInteraction:
+		return plugin.IsPluginCustomID(p.plugin.ID(), string(d.ID()))
+	case *discord.ModalInteraction:
+		return plugin.IsPluginCustomID(p.plugin.ID(), string(d.CustomID))
+	default:
+		return false
+	}
+}
+
 func (p *pluginInstance) pluginContext(ctx context.Context, session *state.State) *pluginContext {
 	return &pluginContext{
 		Context:       ctx,
 		ValueProvider: NewValueProvider(p.model.ID, p.env.PluginValueStore),
 		appID:         p.model.AppID,
 		discord:       NewDiscordProvider(p.model.AppID, p.env.AppStore, session),
+		asset:         NewAssetProvider(p.model.AppID, p.env.AppStore, p.env.AssetStore),
 	}
 }
 
@@ -124,12 +154,12 @@ func computeEventTypes(pl plugin.Plugin, enabledResourceIDs []string) map[ws.Eve
 	return types
 }
 
-func (a *App) dispatchEventToPlugins(session *state.State, event gateway.Event) {
+func (a *App) dispatchEventToPlugins(ctx context.Context, session *state.State, event gateway.Event) {
 	a.RLock()
 	defer a.RUnlock()
 
 	for _, plugin := range a.pluginInstances {
-		go plugin.HandleEvent(context.TODO(), session, event)
+		go plugin.HandleEvent(ctx, session, event)
 	}
 }
 
@@ -140,8 +170,13 @@ type pluginContext struct {
 
 	appID   string
 	discord provider.DiscordProvider
+	asset   provider.AssetProvider
 }
 
 func (c *pluginContext) Discord() provider.DiscordProvider {
 	return c.discord
 }
+
+func (c *pluginContext) Asset() provider.AssetProvider {
+	return c.asset
+}
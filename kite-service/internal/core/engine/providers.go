@@ -23,6 +23,7 @@ import (
 	"github.com/kitecloud/kite/kite-service/pkg/message"
 	"github.com/kitecloud/kite/kite-service/pkg/provider"
 	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"github.com/kitecloud/kite/kite-service/pkg/wasm"
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/responses"
 	"gopkg.in/guregu/null.v4"
@@ -54,7 +55,7 @@ func NewDiscordProvider(
 }
 
 func (p *DiscordProvider) Member(ctx context.Context, guildID discord.GuildID, userID discord.UserID) (*discord.Member, error) {
-	member, err := p.session.Member(guildID, userID)
+	member, err := p.session.WithContext(ctx).Member(guildID, userID)
 	if err != nil {
 		if util.IsDiscordRestStatusCode(err, http.StatusNotFound) || errors.Is(err, disstore.ErrNotFound) {
 			return nil, provider.ErrNotFound
@@ -66,7 +67,7 @@ func (p *DiscordProvider) Member(ctx context.Context, guildID discord.GuildID, u
 }
 
 func (p *DiscordProvider) User(ctx context.Context, userID discord.UserID) (*discord.User, error) {
-	user, err := p.session.User(userID)
+	user, err := p.session.WithContext(ctx).User(userID)
 	if err != nil {
 		if util.IsDiscordRestStatusCode(err, http.StatusNotFound) || errors.Is(err, disstore.ErrNotFound) {
 			return nil, provider.ErrNotFound
@@ -78,7 +79,7 @@ func (p *DiscordProvider) User(ctx context.Context, userID discord.UserID) (*dis
 }
 
 func (p *DiscordProvider) Channel(ctx context.Context, channelID discord.ChannelID) (*discord.Channel, error) {
-	channel, err := p.session.Channel(channelID)
+	channel, err := p.session.WithContext(ctx).Channel(channelID)
 	if err != nil {
 		if util.IsDiscordRestStatusCode(err, http.StatusNotFound) || errors.Is(err, disstore.ErrNotFound) {
 			return nil, provider.ErrNotFound
@@ -90,7 +91,7 @@ func (p *DiscordProvider) Channel(ctx context.Context, channelID discord.Channel
 }
 
 func (p *DiscordProvider) Role(ctx context.Context, guildID discord.GuildID, roleID discord.RoleID) (*discord.Role, error) {
-	role, err := p.session.Role(guildID, roleID)
+	role, err := p.session.WithContext(ctx).Role(guildID, roleID)
 	if err != nil {
 		if util.IsDiscordRestStatusCode(err, http.StatusNotFound) || errors.Is(err, disstore.ErrNotFound) {
 			return nil, provider.ErrNotFound
@@ -102,7 +103,7 @@ func (p *DiscordProvider) Role(ctx context.Context, guildID discord.GuildID, rol
 }
 
 func (p *DiscordProvider) Guild(ctx context.Context, guildID discord.GuildID) (*discord.Guild, error) {
-	guild, err := p.session.Guild(guildID)
+	guild, err := p.session.WithContext(ctx).Guild(guildID)
 	if err != nil {
 		if util.IsDiscordRestStatusCode(err, http.StatusNotFound) || errors.Is(err, disstore.ErrNotFound) {
 			return nil, provider.ErrNotFound
@@ -114,7 +115,7 @@ func (p *DiscordProvider) Guild(ctx context.Context, guildID discord.GuildID) (*
 }
 
 func (p *DiscordProvider) Message(ctx context.Context, channelID discord.ChannelID, messageID discord.MessageID) (*discord.Message, error) {
-	msg, err := p.session.Message(channelID, messageID)
+	msg, err := p.session.WithContext(ctx).Message(channelID, messageID)
 	if err != nil {
 		if util.IsDiscordRestStatusCode(err, http.StatusNotFound) || errors.Is(err, disstore.ErrNotFound) {
 			return nil, provider.ErrNotFound
@@ -125,8 +126,21 @@ func (p *DiscordProvider) Message(ctx context.Context, channelID discord.Channel
 	return msg, nil
 }
 
+func (p *DiscordProvider) ChannelMessages(ctx context.Context, channelID discord.ChannelID, limit int) ([]discord.Message, error) {
+	// The state only caches the most recent messages, so they are always fetched from the API
+	msgs, err := p.session.WithContext(ctx).Client.Messages(channelID, uint(limit))
+	if err != nil {
+		if util.IsDiscordRestStatusCode(err, http.StatusNotFound) {
+			return nil, provider.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get messages: %w", err)
+	}
+
+	return msgs, nil
+}
+
 func (p *DiscordProvider) GuildRoles(ctx context.Context, guildID discord.GuildID) ([]discord.Role, error) {
-	roles, err := p.session.Roles(guildID)
+	roles, err := p.session.WithContext(ctx).Roles(guildID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get roles: %w", err)
 	}
@@ -146,7 +160,7 @@ func (p *DiscordProvider) CreateInteractionResponse(ctx context.Context, interac
 			Message *discord.Message            `json:"message,omitempty"`
 		} `json:"resource"`
 	}
-	if err := sendpart.POST(p.session.Client.Client, response, &res, endpoint); err != nil {
+	if err := sendpart.POST(p.session.Client.WithContext(ctx).Client, response, &res, endpoint); err != nil {
 		return nil, fmt.Errorf("failed to respond to interaction: %w", err)
 	}
 
@@ -159,7 +173,7 @@ func (p *DiscordProvider) CreateInteractionResponse(ctx context.Context, interac
 }
 
 func (p *DiscordProvider) EditInteractionResponse(ctx context.Context, applicationID discord.AppID, token string, response api.EditInteractionResponseData) (*discord.Message, error) {
-	msg, err := p.session.EditInteractionResponse(applicationID, token, response)
+	msg, err := p.session.WithContext(ctx).EditInteractionResponse(applicationID, token, response)
 	if err != nil {
 		return nil, fmt.Errorf("failed to edit interaction response: %w", err)
 	}
@@ -168,7 +182,7 @@ func (p *DiscordProvider) EditInteractionResponse(ctx context.Context, applicati
 }
 
 func (p *DiscordProvider) DeleteInteractionResponse(ctx context.Context, applicationID discord.AppID, token string) error {
-	err := p.session.DeleteInteractionResponse(applicationID, token)
+	err := p.session.WithContext(ctx).DeleteInteractionResponse(applicationID, token)
 	if err != nil {
 		return fmt.Errorf("failed to delete interaction response: %w", err)
 	}
@@ -177,7 +191,7 @@ func (p *DiscordProvider) DeleteInteractionResponse(ctx context.Context, applica
 }
 
 func (p *DiscordProvider) CreateInteractionFollowup(ctx context.Context, applicationID discord.AppID, token string, data api.InteractionResponseData) (*discord.Message, error) {
-	msg, err := p.session.FollowUpInteraction(applicationID, token, data)
+	msg, err := p.session.WithContext(ctx).FollowUpInteraction(applicationID, token, data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create interaction followup: %w", err)
 	}
@@ -186,7 +200,7 @@ func (p *DiscordProvider) CreateInteractionFollowup(ctx context.Context, applica
 }
 
 func (p *DiscordProvider) EditInteractionFollowup(ctx context.Context, applicationID discord.AppID, token string, messageID discord.MessageID, data api.EditInteractionResponseData) (*discord.Message, error) {
-	msg, err := p.session.EditInteractionFollowup(applicationID, messageID, token, data)
+	msg, err := p.session.WithContext(ctx).EditInteractionFollowup(applicationID, messageID, token, data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to edit interaction followup: %w", err)
 	}
@@ -195,7 +209,7 @@ func (p *DiscordProvider) EditInteractionFollowup(ctx context.Context, applicati
 }
 
 func (p *DiscordProvider) DeleteInteractionFollowup(ctx context.Context, applicationID discord.AppID, token string, messageID discord.MessageID) error {
-	err := p.session.DeleteInteractionFollowup(applicationID, messageID, token)
+	err := p.session.WithContext(ctx).DeleteInteractionFollowup(applicationID, messageID, token)
 	if err != nil {
 		return fmt.Errorf("failed to delete interaction followup: %w", err)
 	}
@@ -204,7 +218,7 @@ func (p *DiscordProvider) DeleteInteractionFollowup(ctx context.Context, applica
 }
 
 func (p *DiscordProvider) CreateMessage(ctx context.Context, channelID discord.ChannelID, message api.SendMessageData) (*discord.Message, error) {
-	msg, err := p.session.SendMessageComplex(channelID, message)
+	msg, err := p.session.WithContext(ctx).SendMessageComplex(channelID, message)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send message: %w", err)
 	}
@@ -213,7 +227,7 @@ func (p *DiscordProvider) CreateMessage(ctx context.Context, channelID discord.C
 }
 
 func (p *DiscordProvider) EditMessage(ctx context.Context, channelID discord.ChannelID, messageID discord.MessageID, message api.EditMessageData) (*discord.Message, error) {
-	msg, err := p.session.EditMessageComplex(channelID, messageID, message)
+	msg, err := p.session.WithContext(ctx).EditMessageComplex(channelID, messageID, message)
 	if err != nil {
 		return nil, fmt.Errorf("failed to edit message: %w", err)
 	}
@@ -227,7 +241,7 @@ func (p *DiscordProvider) DeleteMessage(
 	messageID discord.MessageID,
 	reason api.AuditLogReason,
 ) error {
-	err := p.session.DeleteMessage(channelID, messageID, reason)
+	err := p.session.WithContext(ctx).DeleteMessage(channelID, messageID, reason)
 	if err != nil {
 		return fmt.Errorf("failed to delete message: %w", err)
 	}
@@ -236,7 +250,7 @@ func (p *DiscordProvider) DeleteMessage(
 }
 
 func (p *DiscordProvider) CreateMessageReaction(ctx context.Context, channelID discord.ChannelID, messageID discord.MessageID, emoji discord.APIEmoji) error {
-	err := p.session.React(channelID, messageID, emoji)
+	err := p.session.WithContext(ctx).React(channelID, messageID, emoji)
 	if err != nil {
 		return fmt.Errorf("failed to create message reaction: %w", err)
 	}
@@ -245,7 +259,7 @@ func (p *DiscordProvider) CreateMessageReaction(ctx context.Context, channelID d
 }
 
 func (p *DiscordProvider) DeleteMessageReaction(ctx context.Context, channelID discord.ChannelID, messageID discord.MessageID, emoji discord.APIEmoji) error {
-	err := p.session.Unreact(channelID, messageID, emoji)
+	err := p.session.WithContext(ctx).Unreact(channelID, messageID, emoji)
 	if err != nil {
 		return fmt.Errorf("failed to delete message reaction: %w", err)
 	}
@@ -254,7 +268,7 @@ func (p *DiscordProvider) DeleteMessageReaction(ctx context.Context, channelID d
 }
 
 func (p *DiscordProvider) BanMember(ctx context.Context, guildID discord.GuildID, userID discord.UserID, data api.BanData) error {
-	err := p.session.Ban(guildID, userID, data)
+	err := p.session.WithContext(ctx).Ban(guildID, userID, data)
 	if err != nil {
 		return fmt.Errorf("failed to ban member: %w", err)
 	}
@@ -263,7 +277,7 @@ func (p *DiscordProvider) BanMember(ctx context.Context, guildID discord.GuildID
 }
 
 func (p *DiscordProvider) UnbanMember(ctx context.Context, guildID discord.GuildID, userID discord.UserID, reason api.AuditLogReason) error {
-	err := p.session.Unban(guildID, userID, reason)
+	err := p.session.WithContext(ctx).Unban(guildID, userID, reason)
 	if err != nil {
 		return fmt.Errorf("failed to unban member: %w", err)
 	}
@@ -272,7 +286,7 @@ func (p *DiscordProvider) UnbanMember(ctx context.Context, guildID discord.Guild
 }
 
 func (p *DiscordProvider) KickMember(ctx context.Context, guildID discord.GuildID, userID discord.UserID, reason api.AuditLogReason) error {
-	err := p.session.Kick(guildID, userID, reason)
+	err := p.session.WithContext(ctx).Kick(guildID, userID, reason)
 	if err != nil {
 		return fmt.Errorf("failed to kick member: %w", err)
 	}
@@ -281,7 +295,7 @@ func (p *DiscordProvider) KickMember(ctx context.Context, guildID discord.GuildI
 }
 
 func (p *DiscordProvider) EditMember(ctx context.Context, guildID discord.GuildID, userID discord.UserID, data api.ModifyMemberData) error {
-	err := p.session.ModifyMember(guildID, userID, data)
+	err := p.session.WithContext(ctx).ModifyMember(guildID, userID, data)
 	if err != nil {
 		return fmt.Errorf("failed to edit member: %w", err)
 	}
@@ -290,7 +304,7 @@ func (p *DiscordProvider) EditMember(ctx context.Context, guildID discord.GuildI
 }
 
 func (p *DiscordProvider) AddMemberRole(ctx context.Context, guildID discord.GuildID, userID discord.UserID, roleID discord.RoleID, reason api.AuditLogReason) error {
-	err := p.session.AddRole(guildID, userID, roleID, api.AddRoleData{
+	err := p.session.WithContext(ctx).AddRole(guildID, userID, roleID, api.AddRoleData{
 		AuditLogReason: reason,
 	})
 	if err != nil {
@@ -301,7 +315,7 @@ func (p *DiscordProvider) AddMemberRole(ctx context.Context, guildID discord.Gui
 }
 
 func (p *DiscordProvider) RemoveMemberRole(ctx context.Context, guildID discord.GuildID, userID discord.UserID, roleID discord.RoleID, reason api.AuditLogReason) error {
-	err := p.session.RemoveRole(guildID, userID, roleID, reason)
+	err := p.session.WithContext(ctx).RemoveRole(guildID, userID, roleID, reason)
 	if err != nil {
 		return fmt.Errorf("failed to remove role: %w", err)
 	}
@@ -310,7 +324,7 @@ func (p *DiscordProvider) RemoveMemberRole(ctx context.Context, guildID discord.
 }
 
 func (p *DiscordProvider) CreatePrivateChannel(ctx context.Context, userID discord.UserID) (*discord.Channel, error) {
-	channel, err := p.session.CreatePrivateChannel(userID)
+	channel, err := p.session.WithContext(ctx).CreatePrivateChannel(userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create DM channel: %w", err)
 	}
@@ -319,7 +333,7 @@ func (p *DiscordProvider) CreatePrivateChannel(ctx context.Context, userID disco
 }
 
 func (p *DiscordProvider) CreateChannel(ctx context.Context, guildID discord.GuildID, data api.CreateChannelData) (*discord.Channel, error) {
-	channel, err := p.session.CreateChannel(guildID, data)
+	channel, err := p.session.WithContext(ctx).CreateChannel(guildID, data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create channel: %w", err)
 	}
@@ -328,7 +342,7 @@ func (p *DiscordProvider) CreateChannel(ctx context.Context, guildID discord.Gui
 }
 
 func (p *DiscordProvider) EditChannel(ctx context.Context, channelID discord.ChannelID, data api.ModifyChannelData) error {
-	err := p.session.ModifyChannel(channelID, data)
+	err := p.session.WithContext(ctx).ModifyChannel(channelID, data)
 	if err != nil {
 		return fmt.Errorf("failed to edit channel: %w", err)
 	}
@@ -337,7 +351,7 @@ func (p *DiscordProvider) EditChannel(ctx context.Context, channelID discord.Cha
 }
 
 func (p *DiscordProvider) DeleteChannel(ctx context.Context, channelID discord.ChannelID, reason api.AuditLogReason) error {
-	err := p.session.DeleteChannel(channelID, reason)
+	err := p.session.WithContext(ctx).DeleteChannel(channelID, reason)
 	if err != nil {
 		return fmt.Errorf("failed to delete channel: %w", err)
 	}
@@ -346,7 +360,7 @@ func (p *DiscordProvider) DeleteChannel(ctx context.Context, channelID discord.C
 }
 
 func (p *DiscordProvider) StartThreadWithMessage(ctx context.Context, channelID discord.ChannelID, messageID discord.MessageID, data api.StartThreadData) (*discord.Channel, error) {
-	thread, err := p.session.StartThreadWithMessage(channelID, messageID, data)
+	thread, err := p.session.WithContext(ctx).StartThreadWithMessage(channelID, messageID, data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to start thread with message: %w", err)
 	}
@@ -355,7 +369,7 @@ func (p *DiscordProvider) StartThreadWithMessage(ctx context.Context, channelID
 }
 
 func (p *DiscordProvider) StartThreadWithoutMessage(ctx context.Context, channelID discord.ChannelID, data api.StartThreadData) (*discord.Channel, error) {
-	thread, err := p.session.StartThreadWithoutMessage(channelID, data)
+	thread, err := p.session.WithContext(ctx).StartThreadWithoutMessage(channelID, data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to start thread without message: %w", err)
 	}
@@ -364,7 +378,7 @@ func (p *DiscordProvider) StartThreadWithoutMessage(ctx context.Context, channel
 }
 
 func (p *DiscordProvider) AddThreadMember(ctx context.Context, channelID discord.ChannelID, userID discord.UserID) error {
-	err := p.session.AddThreadMember(channelID, userID)
+	err := p.session.WithContext(ctx).AddThreadMember(channelID, userID)
 	if err != nil {
 		return fmt.Errorf("failed to add thread member: %w", err)
 	}
@@ -373,7 +387,7 @@ func (p *DiscordProvider) AddThreadMember(ctx context.Context, channelID discord
 }
 
 func (p *DiscordProvider) RemoveThreadMember(ctx context.Context, channelID discord.ChannelID, userID discord.UserID) error {
-	err := p.session.RemoveThreadMember(channelID, userID)
+	err := p.session.WithContext(ctx).RemoveThreadMember(channelID, userID)
 	if err != nil {
 		return fmt.Errorf("failed to remove thread member: %w", err)
 	}
@@ -470,7 +484,16 @@ func NewHTTPProvider(client *http.Client) *HTTPProvider {
 }
 
 func (p *HTTPProvider) HTTPRequest(ctx context.Context, req *http.Request) (*http.Response, error) {
-	return p.client.Do(req)
+	start := time.Now()
+	resp, err := p.client.Do(req)
+
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	httpRequestDuration.WithLabelValues(httpStatusLabel(status)).Observe(time.Since(start).Seconds())
+
+	return resp, err
 }
 
 type AIProvider struct {
@@ -542,17 +565,124 @@ func (p *AIProvider) CreateResponse(ctx context.Context, opts provider.CreateRes
 	return resp.OutputText(), nil
 }
 
+func (p *AIProvider) ModerateContent(ctx context.Context, opts provider.ModerateContentOpts) (*provider.ModerationResult, error) {
+	inputs := make([]openai.ModerationMultiModalInputUnionParam, 0, len(opts.ImageURLs)+1)
+	if opts.Text != "" {
+		inputs = append(inputs, openai.ModerationMultiModalInputParamOfText(opts.Text))
+	}
+	for _, url := range opts.ImageURLs {
+		inputs = append(inputs, openai.ModerationMultiModalInputParamOfImageURL(
+			openai.ModerationImageURLInputImageURLParam{URL: url},
+		))
+	}
+
+	res := &provider.ModerationResult{
+		Categories:     map[string]bool{},
+		CategoryScores: map[string]float64{},
+	}
+	if len(inputs) == 0 {
+		return res, nil
+	}
+
+	resp, err := p.client.Moderations.New(ctx, openai.ModerationNewParams{
+		Model: openai.ModerationModelOmniModerationLatest,
+		Input: openai.ModerationNewParamsInputUnion{
+			OfModerationMultiModalArray: inputs,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create moderation: %w", err)
+	}
+
+	// The API returns one result per input, we merge them into a single result
+	// by taking the highest score for each category.
+	for _, result := range resp.Results {
+		var categories map[string]bool
+		if err := json.Unmarshal([]byte(result.Categories.RawJSON()), &categories); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal moderation categories: %w", err)
+		}
+
+		var scores map[string]float64
+		if err := json.Unmarshal([]byte(result.CategoryScores.RawJSON()), &scores); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal moderation category scores: %w", err)
+		}
+
+		res.Flagged = res.Flagged || result.Flagged
+		for category, flagged := range categories {
+			res.Categories[category] = res.Categories[category] || flagged
+		}
+		for category, score := range scores {
+			res.CategoryScores[category] = max(res.CategoryScores[category], score)
+		}
+	}
+
+	return res, nil
+}
+
 type VariableProvider struct {
+	appID              string
+	variableStore      store.VariableStore
 	variableValueStore store.VariableValueStore
+	namespaceStore     store.NamespaceStore
 }
 
-func NewVariableProvider(variableValueStore store.VariableValueStore) *VariableProvider {
+func NewVariableProvider(
+	appID string,
+	variableStore store.VariableStore,
+	variableValueStore store.VariableValueStore,
+	namespaceStore store.NamespaceStore,
+) *VariableProvider {
 	return &VariableProvider{
+		appID:              appID,
+		variableStore:      variableStore,
 		variableValueStore: variableValueStore,
+		namespaceStore:     namespaceStore,
+	}
+}
+
+// variable returns the variable if the app owns it or has been granted access to its namespace.
+// Writing to a shared variable requires read-write access.
+func (p *VariableProvider) variable(ctx context.Context, id string, write bool) (*model.Variable, error) {
+	variable, err := p.variableStore.VariableDefinition(ctx, id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, fmt.Errorf("unknown variable %s: %w", id, provider.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get variable: %w", err)
+	}
+
+	if variable.AppID == p.appID {
+		return variable, nil
+	}
+
+	if !variable.NamespaceID.Valid || p.namespaceStore == nil {
+		return nil, fmt.Errorf("unknown variable %s: %w", id, provider.ErrNotFound)
+	}
+
+	grant, err := p.namespaceStore.NamespaceGrant(ctx, variable.NamespaceID.String, p.appID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, fmt.Errorf("unknown variable %s: %w", id, provider.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get namespace grant: %w", err)
+	}
+
+	if write && !grant.Access.CanWrite() {
+		return nil, fmt.Errorf("variable %s is read-only for this app", variable.Name)
 	}
+
+	return variable, nil
 }
 
-func (p *VariableProvider) UpdateVariable(ctx context.Context, id string, scope null.String, operation provider.VariableOperation, value thing.Thing) (thing.Thing, error) {
+func (p *VariableProvider) UpdateVariable(ctx context.Context, id string, scope null.String, operation provider.VariableOperation, value thing.Thing, args provider.VariableOperationArgs) (thing.Thing, error) {
+	variable, err := p.variable(ctx, id, true)
+	if err != nil {
+		return thing.Null, err
+	}
+
+	args.Default = variable.DefaultValue
+	args.Convert = variable.ConvertValue
+
 	v := model.VariableValue{
 		VariableID: id,
 		Scope:      scope,
@@ -560,8 +690,11 @@ func (p *VariableProvider) UpdateVariable(ctx context.Context, id string, scope
 		CreatedAt:  time.Now().UTC(),
 		UpdatedAt:  time.Now().UTC(),
 	}
+	if variable.ValueTTL > 0 {
+		v.ExpiresAt = null.TimeFrom(v.UpdatedAt.Add(variable.ValueTTL))
+	}
 
-	newValue, err := p.variableValueStore.UpdateVariableValue(ctx, operation, v)
+	newValue, err := p.variableValueStore.UpdateVariableValue(ctx, operation, args, v)
 	if err != nil {
 		return thing.Null, fmt.Errorf("failed to %s variable value: %w", operation, err)
 	}
@@ -570,10 +703,18 @@ func (p *VariableProvider) UpdateVariable(ctx context.Context, id string, scope
 }
 
 func (p *VariableProvider) Variable(ctx context.Context, id string, scope null.String) (thing.Thing, error) {
+	variable, err := p.variable(ctx, id, false)
+	if err != nil {
+		return thing.Null, err
+	}
+
 	row, err := p.variableValueStore.VariableValue(ctx, id, scope)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
-			return thing.Null, provider.ErrNotFound
+			if variable.DefaultValue.IsNil() {
+				return thing.Null, provider.ErrNotFound
+			}
+			return variable.DefaultValue, nil
 		}
 		return thing.Null, fmt.Errorf("failed to get variable value: %w", err)
 	}
@@ -582,6 +723,10 @@ func (p *VariableProvider) Variable(ctx context.Context, id string, scope null.S
 }
 
 func (p *VariableProvider) DeleteVariable(ctx context.Context, id string, scope null.String) error {
+	if _, err := p.variable(ctx, id, true); err != nil {
+		return err
+	}
+
 	err := p.variableValueStore.DeleteVariableValue(ctx, id, scope)
 	if err != nil {
 		return fmt.Errorf("failed to delete variable value: %w", err)
@@ -590,6 +735,40 @@ func (p *VariableProvider) DeleteVariable(ctx context.Context, id string, scope
 	return nil
 }
 
+func (p *VariableProvider) QueryVariable(ctx context.Context, id string, query provider.VariableQuery) ([]provider.VariableScopeValue, error) {
+	if _, err := p.variable(ctx, id, false); err != nil {
+		return nil, err
+	}
+
+	rows, err := p.variableValueStore.VariableValuesByQuery(ctx, id, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query variable values: %w", err)
+	}
+
+	res := make([]provider.VariableScopeValue, len(rows))
+	for i, row := range rows {
+		res[i] = provider.VariableScopeValue{
+			Scope: row.Scope,
+			Value: row.Data,
+		}
+	}
+
+	return res, nil
+}
+
+func (p *VariableProvider) AggregateVariable(ctx context.Context, id string, scopePrefix string) (provider.VariableAggregate, error) {
+	if _, err := p.variable(ctx, id, false); err != nil {
+		return provider.VariableAggregate{}, err
+	}
+
+	res, err := p.variableValueStore.AggregateVariableValues(ctx, id, scopePrefix)
+	if err != nil {
+		return provider.VariableAggregate{}, fmt.Errorf("failed to aggregate variable values: %w", err)
+	}
+
+	return *res, nil
+}
+
 type MessageTemplateProvider struct {
 	messageStore         store.MessageStore
 	messageInstanceStore store.MessageInstanceStore
@@ -635,6 +814,101 @@ func (p *MessageTemplateProvider) LinkMessageTemplateInstance(ctx context.Contex
 	return nil
 }
 
+// temporaryAssetTTL is how long files that are generated by flows are kept around.
+const temporaryAssetTTL = 24 * time.Hour
+
+type AssetProvider struct {
+	appID      string
+	appStore   store.AppStore
+	assetStore store.AssetStore
+}
+
+func NewAssetProvider(appID string, appStore store.AppStore, assetStore store.AssetStore) *AssetProvider {
+	return &AssetProvider{
+		appID:      appID,
+		appStore:   appStore,
+		assetStore: assetStore,
+	}
+}
+
+func (p *AssetProvider) Asset(ctx context.Context, id string) (*provider.Asset, error) {
+	asset, err := p.assetStore.AssetWithContent(ctx, id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, provider.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get asset: %w", err)
+	}
+
+	if asset.AppID != p.appID {
+		return nil, provider.ErrNotFound
+	}
+
+	return &provider.Asset{
+		ID:          asset.ID,
+		Name:        asset.Name,
+		ContentType: asset.ContentType,
+		Content:     asset.Content,
+	}, nil
+}
+
+func (p *AssetProvider) CreateAsset(ctx context.Context, name string, contentType string, content []byte) (*provider.Asset, error) {
+	return p.createAsset(ctx, name, contentType, content, null.Time{})
+}
+
+// CreateTemporaryAsset reuses an existing temporary asset with the same name and content,
+// so sending the same generated file again doesn't upload it again.
+func (p *AssetProvider) CreateTemporaryAsset(ctx context.Context, name string, contentType string, content []byte) (*provider.Asset, error) {
+	now := time.Now().UTC()
+
+	// The asset must be kept for at least half of the TTL, so messages that reference it have time to be sent
+	existing, err := p.assetStore.TemporaryAssetByContentHash(ctx, p.appID, util.HashBytes(content), name, now.Add(temporaryAssetTTL/2))
+	if err == nil {
+		return &provider.Asset{
+			ID:          existing.ID,
+			Name:        existing.Name,
+			ContentType: existing.ContentType,
+			Content:     content,
+		}, nil
+	}
+	if !errors.Is(err, store.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get temporary asset: %w", err)
+	}
+
+	return p.createAsset(ctx, name, contentType, content, null.TimeFrom(now.Add(temporaryAssetTTL)))
+}
+
+func (p *AssetProvider) createAsset(ctx context.Context, name string, contentType string, content []byte, expiresAt null.Time) (*provider.Asset, error) {
+	app, err := p.appStore.App(ctx, p.appID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get app: %w", err)
+	}
+
+	asset, err := p.assetStore.CreateAsset(ctx, &model.Asset{
+		ID:            util.UniqueID(),
+		AppID:         p.appID,
+		CreatorUserID: app.OwnerUserID,
+		Name:          name,
+		ContentType:   contentType,
+		ContentHash:   util.HashBytes(content),
+		ContentSize:   len(content),
+		Content:       content,
+		CreatedAt:     time.Now().UTC(),
+		UpdatedAt:     time.Now().UTC(),
+		ExpiresAt:     expiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create asset: %w", err)
+	}
+
+	return &provider.Asset{
+		ID:          asset.ID,
+		Name:        asset.Name,
+		ContentType: asset.ContentType,
+		Content:     content,
+	}, nil
+}
+
 type ResumePointProvider struct {
 	resumePointStore store.ResumePointStore
 
@@ -664,8 +938,7 @@ func (p *ResumePointProvider) CreateResumePoint(ctx context.Context, s flow.Resu
 		expiresAt = null.NewTime(time.Now().UTC().Add(time.Hour*1), true)
 	}
 
-	// TODO: Implement some kind of expiration for other resume point types
-	// Maybe based on last usage?
+	// Other resume point types are removed by the maintenance janitor once they reach their TTL
 
 	err := p.resumePointStore.CreateResumePoint(ctx, &model.ResumePoint{
 		ID:                s.ID,
@@ -714,6 +987,38 @@ func (p *ValueProvider) UpdateValue(ctx context.Context, key string, op provider
 	return newValue.Value, nil
 }
 
+func (p *ValueProvider) SetValue(ctx context.Context, key string, value thing.Thing, ttl time.Duration) error {
+	err := p.pluginValueStore.SetPluginValue(ctx, p.pluginValue(key, value, ttl))
+	if err != nil {
+		return fmt.Errorf("failed to set plugin value: %w", err)
+	}
+
+	return nil
+}
+
+func (p *ValueProvider) SetValues(ctx context.Context, values []provider.KeyValue, ttl time.Duration) error {
+	pluginValues := make([]model.PluginValue, len(values))
+	for i, v := range values {
+		pluginValues[i] = p.pluginValue(v.Key, v.Value, ttl)
+	}
+
+	err := p.pluginValueStore.SetPluginValues(ctx, pluginValues)
+	if err != nil {
+		return fmt.Errorf("failed to set plugin values: %w", err)
+	}
+
+	return nil
+}
+
+func (p *ValueProvider) CompareAndSwapValue(ctx context.Context, key string, old thing.Thing, new thing.Thing, ttl time.Duration) (bool, error) {
+	swapped, err := p.pluginValueStore.CompareAndSwapPluginValue(ctx, old, p.pluginValue(key, new, ttl))
+	if err != nil {
+		return false, fmt.Errorf("failed to swap plugin value: %w", err)
+	}
+
+	return swapped, nil
+}
+
 func (p *ValueProvider) GetValue(ctx context.Context, key string) (thing.Thing, error) {
 	v, err := p.pluginValueStore.GetPluginValue(ctx, p.pluginInstanceID, key)
 	if err != nil {
@@ -726,6 +1031,20 @@ func (p *ValueProvider) GetValue(ctx context.Context, key string) (thing.Thing,
 	return v.Value, nil
 }
 
+func (p *ValueProvider) GetValues(ctx context.Context, keys []string) (map[string]thing.Thing, error) {
+	values, err := p.pluginValueStore.PluginValuesByKeys(ctx, p.pluginInstanceID, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plugin values: %w", err)
+	}
+
+	res := make(map[string]thing.Thing, len(values))
+	for _, v := range values {
+		res[v.Key] = v.Value
+	}
+
+	return res, nil
+}
+
 func (p *ValueProvider) DeleteValue(ctx context.Context, key string) error {
 	err := p.pluginValueStore.DeletePluginValue(ctx, p.pluginInstanceID, key)
 	if err != nil {
@@ -735,6 +1054,189 @@ func (p *ValueProvider) DeleteValue(ctx context.Context, key string) error {
 	return nil
 }
 
+func (p *ValueProvider) ListValuesByPrefix(ctx context.Context, prefix string, limit int, offset int) ([]provider.KeyValue, error) {
+	values, err := p.pluginValueStore.PluginValuesByKeyPrefix(ctx, p.pluginInstanceID, prefix, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plugin values: %w", err)
+	}
+
+	res := make([]provider.KeyValue, len(values))
+	for i, v := range values {
+		res[i] = provider.KeyValue{
+			Key:   v.Key,
+			Value: v.Value,
+		}
+	}
+
+	return res, nil
+}
+
+func (p *ValueProvider) CountValuesByPrefixAbove(ctx context.Context, prefix string, value float64) (int, error) {
+	count, err := p.pluginValueStore.CountPluginValuesByKeyPrefixAbove(ctx, p.pluginInstanceID, prefix, value)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count plugin values: %w", err)
+	}
+
+	return count, nil
+}
+
+func (p *ValueProvider) pluginValue(key string, value thing.Thing, ttl time.Duration) model.PluginValue {
+	now := time.Now().UTC()
+
+	v := model.PluginValue{
+		PluginInstanceID: p.pluginInstanceID,
+		Key:              key,
+		Value:            value,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if ttl != 0 {
+		v.ExpiresAt = null.TimeFrom(now.Add(ttl))
+	}
+
+	return v
+}
+
+type ModuleProvider struct {
+	appID         string
+	moduleStore   store.ModuleStore
+	variableStore store.VariableStore
+	runtime       *wasm.Runtime
+}
+
+func NewModuleProvider(
+	appID string,
+	moduleStore store.ModuleStore,
+	variableStore store.VariableStore,
+	runtime *wasm.Runtime,
+) *ModuleProvider {
+	return &ModuleProvider{
+		appID:         appID,
+		moduleStore:   moduleStore,
+		variableStore: variableStore,
+		runtime:       runtime,
+	}
+}
+
+func (p *ModuleProvider) CallModuleFunction(ctx context.Context, moduleID string, function string, input []byte, host *wasm.Host) ([]byte, error) {
+	if p.runtime == nil || p.moduleStore == nil {
+		return nil, fmt.Errorf("modules are not available")
+	}
+
+	module, err := p.moduleStore.ModuleMeta(ctx, moduleID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, fmt.Errorf("unknown module: %s", moduleID)
+		}
+		return nil, fmt.Errorf("failed to get module: %w", err)
+	}
+
+	if module.AppID != p.appID {
+		return nil, fmt.Errorf("unknown module: %s", moduleID)
+	}
+
+	if !module.Enabled {
+		return nil, fmt.Errorf("module %s is disabled", module.Name)
+	}
+
+	// Modules can pass arbitrary variable IDs, so we have to make sure they belong to the app.
+	scopedHost := *host
+	scopedHost.Variable = &moduleVariableProvider{
+		appID:            p.appID,
+		variableStore:    p.variableStore,
+		VariableProvider: host.Variable,
+	}
+
+	output, err := p.runtime.Call(ctx, wasm.Module{
+		Hash: module.WasmHash,
+		Load: func(ctx context.Context) ([]byte, error) {
+			return p.loadModuleWasm(ctx, module)
+		},
+	}, function, input, &scopedHost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call module function %s: %w", function, err)
+	}
+
+	return output, nil
+}
+
+// loadModuleWasm loads the binary of the module, which is only needed when it isn't compiled yet.
+func (p *ModuleProvider) loadModuleWasm(ctx context.Context, meta *model.Module) ([]byte, error) {
+	module, err := p.moduleStore.Module(ctx, meta.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get module: %w", err)
+	}
+
+	// The compiled module is cached by its hash, so it must not be replaced by a newer binary.
+	if module.WasmHash != meta.WasmHash {
+		return nil, fmt.Errorf("module %s has been updated, try again", meta.Name)
+	}
+
+	return module.WasmBytes, nil
+}
+
+type moduleVariableProvider struct {
+	provider.VariableProvider
+
+	appID         string
+	variableStore store.VariableStore
+}
+
+func (p *moduleVariableProvider) checkVariable(ctx context.Context, id string) error {
+	if p.VariableProvider == nil || p.variableStore == nil {
+		return fmt.Errorf("variables are not available")
+	}
+
+	variable, err := p.variableStore.VariableDefinition(ctx, id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return fmt.Errorf("unknown variable %s: %w", id, provider.ErrNotFound)
+		}
+		return fmt.Errorf("failed to get variable: %w", err)
+	}
+
+	if variable.AppID != p.appID {
+		return fmt.Errorf("unknown variable %s: %w", id, provider.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (p *moduleVariableProvider) UpdateVariable(ctx context.Context, id string, scope null.String, operation provider.VariableOperation, value thing.Thing, args provider.VariableOperationArgs) (thing.Thing, error) {
+	if err := p.checkVariable(ctx, id); err != nil {
+		return thing.Null, err
+	}
+	return p.VariableProvider.UpdateVariable(ctx, id, scope, operation, value, args)
+}
+
+func (p *moduleVariableProvider) Variable(ctx context.Context, id string, scope null.String) (thing.Thing, error) {
+	if err := p.checkVariable(ctx, id); err != nil {
+		return thing.Null, err
+	}
+	return p.VariableProvider.Variable(ctx, id, scope)
+}
+
+func (p *moduleVariableProvider) DeleteVariable(ctx context.Context, id string, scope null.String) error {
+	if err := p.checkVariable(ctx, id); err != nil {
+		return err
+	}
+	return p.VariableProvider.DeleteVariable(ctx, id, scope)
+}
+
+func (p *moduleVariableProvider) QueryVariable(ctx context.Context, id string, query provider.VariableQuery) ([]provider.VariableScopeValue, error) {
+	if err := p.checkVariable(ctx, id); err != nil {
+		return nil, err
+	}
+	return p.VariableProvider.QueryVariable(ctx, id, query)
+}
+
+func (p *moduleVariableProvider) AggregateVariable(ctx context.Context, id string, scopePrefix string) (provider.VariableAggregate, error) {
+	if err := p.checkVariable(ctx, id); err != nil {
+		return provider.VariableAggregate{}, err
+	}
+	return p.VariableProvider.AggregateVariable(ctx, id, scopePrefix)
+}
+
 type RobloxProvider struct {
 	client *http.Client
 }
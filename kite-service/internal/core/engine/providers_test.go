@@ -0,0 +1,169 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+type testVariableStore struct {
+	store.VariableStore
+
+	variables map[string]*model.Variable
+}
+
+func (s *testVariableStore) VariableDefinition(ctx context.Context, id string) (*model.Variable, error) {
+	v, ok := s.variables[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return v, nil
+}
+
+type testVariableValueStore struct {
+	store.VariableValueStore
+
+	values map[string]thing.Thing
+}
+
+func (s *testVariableValueStore) VariableValue(ctx context.Context, variableID string, scope null.String) (*model.VariableValue, error) {
+	v, ok := s.values[variableID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &model.VariableValue{VariableID: variableID, Scope: scope, Data: v}, nil
+}
+
+func (s *testVariableValueStore) UpdateVariableValue(ctx context.Context, operation model.VariableValueOperation, args model.VariableValueOperationArgs, value model.VariableValue) (*model.VariableValue, error) {
+	s.values[value.VariableID] = value.Data
+	return &value, nil
+}
+
+type testAssetStore struct {
+	store.AssetStore
+
+	assets []*model.Asset
+}
+
+func (s *testAssetStore) CreateAsset(ctx context.Context, asset *model.Asset) (*model.Asset, error) {
+	s.assets = append(s.assets, asset)
+	return asset, nil
+}
+
+func (s *testAssetStore) TemporaryAssetByContentHash(ctx context.Context, appID string, contentHash string, name string, expiresAfter time.Time) (*model.Asset, error) {
+	for _, asset := range s.assets {
+		if asset.AppID == appID && asset.ContentHash == contentHash && asset.Name == name && asset.ExpiresAt.Time.After(expiresAfter) {
+			return asset, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+type testAppStore struct {
+	store.AppStore
+}
+
+func (s *testAppStore) App(ctx context.Context, id string) (*model.App, error) {
+	return &model.App{ID: id, OwnerUserID: "user"}, nil
+}
+
+type testNamespaceStore struct {
+	store.NamespaceStore
+
+	// grants maps namespace IDs to the access of the test app.
+	grants map[string]model.NamespaceAccess
+}
+
+func (s *testNamespaceStore) NamespaceGrant(ctx context.Context, namespaceID string, appID string) (*model.NamespaceGrant, error) {
+	access, ok := s.grants[namespaceID]
+	if !ok || appID != "app" {
+		return nil, store.ErrNotFound
+	}
+	return &model.NamespaceGrant{NamespaceID: namespaceID, AppID: appID, Access: access}, nil
+}
+
+func TestVariableProviderAccess(t *testing.T) {
+	p := NewVariableProvider(
+		"app",
+		&testVariableStore{variables: map[string]*model.Variable{
+			"own":       {ID: "own", Name: "own", AppID: "app", DefaultValue: thing.NewInt(5)},
+			"other":     {ID: "other", Name: "other", AppID: "other_app"},
+			"shared_ro": {ID: "shared_ro", Name: "shared_ro", NamespaceID: null.StringFrom("ns_ro")},
+			"shared_rw": {ID: "shared_rw", Name: "shared_rw", NamespaceID: null.StringFrom("ns_rw")},
+			"ungranted": {ID: "ungranted", Name: "ungranted", NamespaceID: null.StringFrom("ns_other")},
+		}},
+		&testVariableValueStore{values: map[string]thing.Thing{
+			"shared_ro": thing.NewString("hello"),
+		}},
+		&testNamespaceStore{grants: map[string]model.NamespaceAccess{
+			"ns_ro": model.NamespaceAccessRead,
+			"ns_rw": model.NamespaceAccessReadWrite,
+		}},
+	)
+
+	ctx := context.Background()
+	write := func(id string) error {
+		_, err := p.UpdateVariable(ctx, id, null.String{}, provider.VariableOperationOverwrite, thing.NewInt(1), provider.VariableOperationArgs{})
+		return err
+	}
+
+	value, err := p.Variable(ctx, "own", null.String{})
+	require.NoError(t, err)
+	assert.Equal(t, thing.NewInt(5), value)
+	require.NoError(t, write("own"))
+
+	_, err = p.Variable(ctx, "other", null.String{})
+	require.ErrorIs(t, err, provider.ErrNotFound)
+	require.ErrorIs(t, write("other"), provider.ErrNotFound)
+
+	value, err = p.Variable(ctx, "shared_ro", null.String{})
+	require.NoError(t, err)
+	assert.Equal(t, thing.NewString("hello"), value)
+	require.EqualError(t, write("shared_ro"), "variable shared_ro is read-only for this app")
+
+	_, err = p.Variable(ctx, "shared_rw", null.String{})
+	require.ErrorIs(t, err, provider.ErrNotFound)
+	require.NoError(t, write("shared_rw"))
+
+	_, err = p.Variable(ctx, "ungranted", null.String{})
+	require.ErrorIs(t, err, provider.ErrNotFound)
+}
+
+func TestAssetProviderReusesTemporaryAssets(t *testing.T) {
+	ctx := context.Background()
+	assetStore := &testAssetStore{}
+	p := NewAssetProvider("app", &testAppStore{}, assetStore)
+
+	first, err := p.CreateTemporaryAsset(ctx, "report.csv", "text/csv", []byte("a,b"))
+	require.NoError(t, err)
+
+	second, err := p.CreateTemporaryAsset(ctx, "report.csv", "text/csv", []byte("a,b"))
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID)
+	assert.Equal(t, []byte("a,b"), second.Content)
+
+	// A different name or content is a different file
+	renamed, err := p.CreateTemporaryAsset(ctx, "other.csv", "text/csv", []byte("a,b"))
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, renamed.ID)
+
+	changed, err := p.CreateTemporaryAsset(ctx, "report.csv", "text/csv", []byte("a,c"))
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, changed.ID)
+
+	assert.Len(t, assetStore.assets, 3)
+
+	// Assets that expire soon aren't reused
+	assetStore.assets[0].ExpiresAt = null.TimeFrom(time.Now().Add(time.Minute))
+	refreshed, err := p.CreateTemporaryAsset(ctx, "report.csv", "text/csv", []byte("a,b"))
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, refreshed.ID)
+}
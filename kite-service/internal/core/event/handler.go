@@ -1,13 +1,15 @@
 package event
 
 import (
+	"context"
+
 	"github.com/diamondburned/arikawa/v3/gateway"
 	"github.com/diamondburned/arikawa/v3/state"
 	"github.com/kitecloud/kite/kite-service/internal/store"
 )
 
 type EventHandler interface {
-	HandleEvent(appID string, session *state.State, event gateway.Event)
+	HandleEvent(ctx context.Context, appID string, session *state.State, event gateway.Event)
 }
 
 type EventHandlerWrapper struct {
@@ -26,11 +28,11 @@ func NewEventHandlerWrapper(
 	}
 }
 
-func (h *EventHandlerWrapper) HandleEvent(appID string, session *state.State, event gateway.Event) {
+func (h *EventHandlerWrapper) HandleEvent(ctx context.Context, appID string, session *state.State, event gateway.Event) {
 	switch e := event.(type) {
 	case *gateway.MessageDeleteEvent:
-		h.HandleMessageDeleteEvent(appID, session, e)
+		h.HandleMessageDeleteEvent(ctx, appID, session, e)
 	}
 
-	h.next.HandleEvent(appID, session, event)
+	h.next.HandleEvent(ctx, appID, session, event)
 }
@@ -9,8 +9,8 @@ import (
 	"github.com/kitecloud/kite/kite-service/internal/store"
 )
 
-func (h *EventHandlerWrapper) HandleMessageDeleteEvent(appID string, session *state.State, event *gateway.MessageDeleteEvent) {
-	err := h.messageInstanceStore.DeleteMessageInstanceByDiscordMessageID(context.Background(), event.ID.String())
+func (h *EventHandlerWrapper) HandleMessageDeleteEvent(ctx context.Context, appID string, session *state.State, event *gateway.MessageDeleteEvent) {
+	err := h.messageInstanceStore.DeleteMessageInstanceByDiscordMessageID(ctx, event.ID.String())
 	if err != nil && err != store.ErrNotFound {
 		slog.With("error", err).Error("failed to delete message instance from message delete event")
 	}
@@ -6,16 +6,21 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/diamondburned/arikawa/v3/gateway"
 	"github.com/diamondburned/arikawa/v3/session"
 	"github.com/diamondburned/arikawa/v3/state"
 	"github.com/diamondburned/arikawa/v3/utils/httputil"
+	"github.com/diamondburned/arikawa/v3/utils/ws"
 	"github.com/kitecloud/kite/kite-service/internal/core/plan"
 	"github.com/kitecloud/kite/kite-service/internal/model"
 	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/internal/tracing"
 	"github.com/kitecloud/kite/kite-service/internal/util"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/guregu/null.v4"
 )
 
@@ -29,6 +34,9 @@ type Gateway struct {
 	app     *model.App
 	session *state.State
 
+	// connected makes sure the gateway is only counted once in gatewaysConnected.
+	connected atomic.Bool
+
 	ctx    context.Context
 	cancel context.CancelFunc
 }
@@ -41,6 +49,8 @@ func NewGateway(
 	eventHandler EventHandler,
 	tokenCrypt *util.SymmetricCrypt,
 ) (*Gateway, error) {
+	registerUnmarshalers()
+
 	session, err := createSession(tokenCrypt, app)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create session: %w", err)
@@ -84,10 +94,33 @@ func (g *Gateway) startGateway() {
 	g.session.AddIntents(intents)
 
 	g.session.AddHandler(func(e gateway.Event) {
-		g.eventHandler.HandleEvent(g.app.ID, g.session, e)
+		e = unwrapEvent(e)
+
+		// Flows can outlive the gateway, so we don't derive from the gateway context
+		ctx, span := tracing.Tracer().Start(
+			context.Background(),
+			"gateway."+string(e.EventType()),
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(attribute.String("app_id", g.app.ID)),
+		)
+		defer span.End()
+
+		g.eventHandler.HandleEvent(ctx, g.app.ID, g.session, e)
+	})
+
+	g.session.AddHandler(func(e *gateway.ResumedEvent) {
+		g.setConnected(true)
+	})
+
+	g.session.AddHandler(func(e *ws.CloseEvent) {
+		// arikawa will automatically reconnect after the connection has been closed
+		g.setConnected(false)
+		gatewayReconnectsTotal.Inc()
 	})
 
 	g.session.AddHandler(func(e *gateway.ReadyEvent) {
+		g.setConnected(true)
+
 		slog.Info(
 			"Received ready event",
 			slog.String("app_id", g.app.ID),
@@ -116,8 +149,21 @@ func (g *Gateway) startGateway() {
 	}
 }
 
+func (g *Gateway) setConnected(connected bool) {
+	if g.connected.Swap(connected) == connected {
+		return
+	}
+
+	if connected {
+		gatewaysConnected.Inc()
+	} else {
+		gatewaysConnected.Dec()
+	}
+}
+
 func (g *Gateway) Close() error {
 	g.cancel()
+	g.setConnected(false)
 	err := g.session.Close()
 
 	if err != nil && !errors.Is(err, session.ErrClosed) {
@@ -2,13 +2,16 @@ package gateway
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/diamondburned/arikawa/v3/api"
 	"github.com/diamondburned/arikawa/v3/discord"
 	"github.com/diamondburned/arikawa/v3/gateway"
 	"github.com/diamondburned/arikawa/v3/state"
+	"github.com/diamondburned/arikawa/v3/utils/httputil/httpdriver"
 	"github.com/kitecloud/kite/kite-service/internal/model"
 	"github.com/kitecloud/kite/kite-service/internal/util"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 const (
@@ -48,7 +51,12 @@ func createSession(tokenCrypt *util.SymmetricCrypt, app *model.App) (*state.Stat
 	gateway.DefaultGatewayOpts.AlwaysCloseGracefully = false
 
 	// TODO: configure state to only cache what we need
-	return state.NewWithIdentifier(identifier), nil
+	s := state.NewWithIdentifier(identifier)
+	s.Client.Client.Client = httpdriver.WrapClient(http.Client{
+		Transport: otelhttp.NewTransport(http.DefaultTransport),
+	})
+	s.Client.Client.OnResponse = append(s.Client.Client.OnResponse, observeDiscordResponse)
+	return s, nil
 }
 
 func presenceForApp(app *model.App) *gateway.UpdatePresenceCommand {
@@ -0,0 +1,74 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/utils/ws"
+	"github.com/kitecloud/kite/kite-service/pkg/message"
+)
+
+var registerUnmarshalersOnce sync.Once
+
+// registerUnmarshalers replaces the unmarshaler of interaction events.
+// arikawa fails to unmarshal modal submits that contain labels (selects and file uploads),
+// so interactions are unmarshaled by us instead.
+// The unmarshalers are shared by all gateways, so they are registered once when the first gateway is created.
+func registerUnmarshalers() {
+	registerUnmarshalersOnce.Do(func() {
+		gateway.OpUnmarshalers.Add(func() ws.Event { return new(interactionCreateEvent) })
+	})
+}
+
+// interactionCreateEvent is only used to unmarshal the event,
+// it's unwrapped into a gateway.InteractionCreateEvent before it's handled.
+type interactionCreateEvent struct {
+	gateway.InteractionCreateEvent
+}
+
+func (e *interactionCreateEvent) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	var interactionType discord.InteractionDataType
+	if err := json.Unmarshal(raw["type"], &interactionType); err != nil {
+		return fmt.Errorf("failed to unmarshal interaction type: %w", err)
+	}
+
+	if interactionType != discord.ModalInteractionType {
+		return json.Unmarshal(b, &e.InteractionCreateEvent)
+	}
+
+	data, err := message.ParseModalInteraction(raw["data"])
+	if err != nil {
+		return err
+	}
+
+	// Without the type and data arikawa only unmarshals the rest of the interaction
+	delete(raw, "type")
+	delete(raw, "data")
+
+	b, err = json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(b, &e.InteractionCreateEvent); err != nil {
+		return err
+	}
+
+	e.Data = data
+	return nil
+}
+
+func unwrapEvent(e gateway.Event) gateway.Event {
+	if e, ok := e.(*interactionCreateEvent); ok {
+		return &e.InteractionCreateEvent
+	}
+	return e
+}
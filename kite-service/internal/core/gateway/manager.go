@@ -17,7 +17,7 @@ import (
 )
 
 type EventHandler interface {
-	HandleEvent(appID string, session *state.State, event gateway.Event)
+	HandleEvent(ctx context.Context, appID string, session *state.State, event gateway.Event)
 }
 
 type GatewayManagerConfig struct {
@@ -35,8 +35,9 @@ type GatewayManager struct {
 	eventHandler EventHandler
 	tokenCrypt   *util.SymmetricCrypt
 
-	lastUpdate time.Time
-	gateways   map[string]*Gateway
+	lastUpdate    time.Time
+	lastPopulated time.Time
+	gateways      map[string]*Gateway
 }
 
 func NewGatewayManager(
@@ -98,6 +99,10 @@ func (m *GatewayManager) populateGateways(ctx context.Context) error {
 		return fmt.Errorf("failed to remove dangling apps: %w", err)
 	}
 
+	m.Lock()
+	m.lastPopulated = time.Now().UTC()
+	m.Unlock()
+
 	if len(apps) == 0 {
 		return nil
 	}
@@ -171,6 +176,8 @@ func (m *GatewayManager) removeDanglingGateways(ctx context.Context, appIDs []st
 		slog.Info("Removed dangling gateways", slog.Int("count", removed))
 	}
 
+	gatewaysTotal.Set(float64(len(m.gateways)))
+
 	return nil
 }
 
@@ -200,6 +207,23 @@ func (m *GatewayManager) addGateway(ctx context.Context, app *model.App) error {
 	}
 
 	m.gateways[app.ID] = g
+	gatewaysTotal.Set(float64(len(m.gateways)))
+
+	return nil
+}
+
+// Healthy returns an error if the gateways haven't been populated recently.
+func (m *GatewayManager) Healthy() error {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.lastPopulated.IsZero() {
+		return fmt.Errorf("gateways have not been populated yet")
+	}
+
+	if since := time.Since(m.lastPopulated); since > 1*time.Minute {
+		return fmt.Errorf("gateways have not been populated for %s", since.Round(time.Second))
+	}
 
 	return nil
 }
@@ -0,0 +1,59 @@
+package gateway
+
+import (
+	"strconv"
+
+	"github.com/diamondburned/arikawa/v3/utils/httputil"
+	"github.com/diamondburned/arikawa/v3/utils/httputil/httpdriver"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	gatewaysTotal = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "kite",
+		Subsystem: "gateway",
+		Name:      "gateways",
+		Help:      "The number of gateways managed by this cluster.",
+	})
+	gatewaysConnected = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "kite",
+		Subsystem: "gateway",
+		Name:      "connected",
+		Help:      "The number of gateways managed by this cluster that are currently connected.",
+	})
+	gatewayReconnectsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "kite",
+		Subsystem: "gateway",
+		Name:      "reconnects_total",
+		Help:      "The number of times a gateway has been disconnected and reconnected.",
+	})
+	discordRESTRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kite",
+		Subsystem: "discord",
+		Name:      "rest_requests_total",
+		Help:      "The number of requests made to the Discord REST API by status.",
+	}, []string{"status"})
+	discordRESTRateLimitsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "kite",
+		Subsystem: "discord",
+		Name:      "rest_rate_limits_total",
+		Help:      "The number of requests to the Discord REST API that have been rate limited.",
+	})
+)
+
+// observeDiscordResponse is called by arikawa after every request to the Discord REST API.
+var observeDiscordResponse httputil.ResponseFunc = func(req httpdriver.Request, resp httpdriver.Response) error {
+	if resp == nil {
+		discordRESTRequestsTotal.WithLabelValues("error").Inc()
+		return nil
+	}
+
+	status := resp.GetStatus()
+	if status == 429 {
+		discordRESTRateLimitsTotal.Inc()
+	}
+
+	discordRESTRequestsTotal.WithLabelValues(strconv.Itoa(status)).Inc()
+	return nil
+}
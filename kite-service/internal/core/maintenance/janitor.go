@@ -0,0 +1,360 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/internal/util"
+)
+
+const (
+	// assetChecksPerSweep is the max number of assets that are checked for references per sweep.
+	assetChecksPerSweep = 100
+)
+
+type JanitorConfig struct {
+	SweepInterval time.Duration
+	// ResumePointTTLs is the max age of resume points by type, types without a TTL are only deleted when they expire.
+	ResumePointTTLs map[model.ResumePointType]time.Duration
+	// AssetGracePeriod prevents assets from being deleted right after upload, before they are saved in a message.
+	AssetGracePeriod              time.Duration
+	EphemeralMessageInstanceTTL   time.Duration
+	MessageInstanceChecksPerSweep int
+}
+
+// Janitor periodically removes data that is no longer needed.
+// It must only run on the primary cluster.
+type Janitor struct {
+	config JanitorConfig
+
+	appStore             store.AppStore
+	resumePointStore     store.ResumePointStore
+	assetStore           store.AssetStore
+	messageStore         store.MessageStore
+	messageInstanceStore store.MessageInstanceStore
+	cooldownStore        store.CooldownStore
+	pluginValueStore     store.PluginValueStore
+	variableValueStore   store.VariableValueStore
+	tokenCrypt           *util.SymmetricCrypt
+
+	// messageInstanceCursor is the ID of the last message instance that has been checked,
+	// so each sweep continues where the previous one stopped.
+	messageInstanceCursor uint64
+	// assetCursor is the ID of the last asset that has been checked for references.
+	assetCursor string
+}
+
+func NewJanitor(
+	config JanitorConfig,
+	appStore store.AppStore,
+	resumePointStore store.ResumePointStore,
+	assetStore store.AssetStore,
+	messageStore store.MessageStore,
+	messageInstanceStore store.MessageInstanceStore,
+	cooldownStore store.CooldownStore,
+	pluginValueStore store.PluginValueStore,
+	variableValueStore store.VariableValueStore,
+	tokenCrypt *util.SymmetricCrypt,
+) *Janitor {
+	return &Janitor{
+		config:               config,
+		appStore:             appStore,
+		resumePointStore:     resumePointStore,
+		assetStore:           assetStore,
+		messageStore:         messageStore,
+		messageInstanceStore: messageInstanceStore,
+		cooldownStore:        cooldownStore,
+		pluginValueStore:     pluginValueStore,
+		variableValueStore:   variableValueStore,
+		tokenCrypt:           tokenCrypt,
+	}
+}
+
+func (j *Janitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.config.SweepInterval)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			case <-ticker.C:
+				j.Sweep(ctx)
+			}
+		}
+	}()
+}
+
+// Sweep runs all maintenance tasks once.
+func (j *Janitor) Sweep(ctx context.Context) {
+	j.runTask(ctx, "resume_points", j.sweepResumePoints)
+	if j.assetStore != nil {
+		j.runTask(ctx, "assets", j.sweepAssets)
+	}
+	j.runTask(ctx, "message_instances", j.sweepMessageInstances)
+	j.runTask(ctx, "cooldowns", j.sweepCooldowns)
+	j.runTask(ctx, "plugin_values", j.sweepPluginValues)
+	j.runTask(ctx, "variable_values", j.sweepVariableValues)
+}
+
+func (j *Janitor) runTask(ctx context.Context, task string, f func(ctx context.Context) (int, error)) {
+	startAt := time.Now()
+	deleted, err := f(ctx)
+	duration := time.Since(startAt)
+
+	sweepDuration.WithLabelValues(task).Observe(duration.Seconds())
+	sweepDeletedTotal.WithLabelValues(task).Add(float64(deleted))
+
+	if err != nil {
+		sweepErrorsTotal.WithLabelValues(task).Inc()
+		slog.Error(
+			"Failed to run maintenance task",
+			slog.String("task", task),
+			slog.Int("deleted", deleted),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	sweepLastSuccess.WithLabelValues(task).SetToCurrentTime()
+	slog.Info(
+		"Finished maintenance task",
+		slog.String("task", task),
+		slog.Int("deleted", deleted),
+		slog.Duration("duration", duration),
+	)
+}
+
+func (j *Janitor) sweepResumePoints(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+
+	deleted, err := j.resumePointStore.DeleteExpiredResumePoints(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired resume points: %w", err)
+	}
+
+	for resumePointType, ttl := range j.config.ResumePointTTLs {
+		if ttl <= 0 {
+			continue
+		}
+
+		n, err := j.resumePointStore.DeleteResumePointsCreatedBefore(ctx, resumePointType, now.Add(-ttl))
+		deleted += n
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete %s resume points: %w", resumePointType, err)
+		}
+	}
+
+	return deleted, nil
+}
+
+func (j *Janitor) sweepAssets(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+
+	deleted, err := j.assetStore.DeleteExpiredAssets(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired assets: %w", err)
+	}
+
+	n, err := j.deleteUnreferencedAssets(ctx, now.Add(-j.config.AssetGracePeriod))
+	deleted += n
+	if err != nil {
+		return deleted, fmt.Errorf("failed to delete unreferenced assets: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// deleteUnreferencedAssets checks the next batch of assets and deletes the ones that aren't referenced anywhere in their app.
+// The references are checked by the database once per app, no matter how many of its assets are checked.
+func (j *Janitor) deleteUnreferencedAssets(ctx context.Context, createdBefore time.Time) (int, error) {
+	assets, err := j.assetStore.AssetsWithoutExpiryAfterID(ctx, j.assetCursor, createdBefore, assetChecksPerSweep)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get assets: %w", err)
+	}
+
+	if len(assets) < assetChecksPerSweep {
+		// Start from the beginning again in the next sweep
+		j.assetCursor = ""
+	} else {
+		j.assetCursor = assets[len(assets)-1].ID
+	}
+
+	assetIDsByApp := make(map[string][]string)
+	for _, asset := range assets {
+		assetIDsByApp[asset.AppID] = append(assetIDsByApp[asset.AppID], asset.ID)
+	}
+
+	deleted := 0
+	for appID, assetIDs := range assetIDsByApp {
+		referencedIDs, err := j.assetStore.ReferencedAssetIDs(ctx, appID, assetIDs)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to get referenced assets of app %s: %w", appID, err)
+		}
+
+		referenced := make(map[string]bool, len(referencedIDs))
+		for _, id := range referencedIDs {
+			referenced[id] = true
+		}
+
+		for _, assetID := range assetIDs {
+			if referenced[assetID] {
+				continue
+			}
+
+			if err := j.assetStore.DeleteAsset(ctx, assetID); err != nil {
+				slog.Error(
+					"Failed to delete unreferenced asset",
+					slog.String("asset_id", assetID),
+					slog.String("error", err.Error()),
+				)
+				continue
+			}
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// sweepMessageInstances deletes old ephemeral message instances and message instances
+// whose Discord message has been deleted while the gateway of the app wasn't connected.
+func (j *Janitor) sweepMessageInstances(ctx context.Context) (int, error) {
+	deleted := 0
+
+	if j.config.EphemeralMessageInstanceTTL > 0 {
+		n, err := j.messageInstanceStore.DeleteEphemeralMessageInstancesBefore(ctx, time.Now().UTC().Add(-j.config.EphemeralMessageInstanceTTL))
+		if err != nil {
+			return 0, fmt.Errorf("failed to delete ephemeral message instances: %w", err)
+		}
+		deleted += n
+	}
+
+	if j.config.MessageInstanceChecksPerSweep <= 0 {
+		return deleted, nil
+	}
+
+	instances, err := j.messageInstanceStore.MessageInstancesAfterID(ctx, j.messageInstanceCursor, j.config.MessageInstanceChecksPerSweep)
+	if err != nil {
+		return deleted, fmt.Errorf("failed to get message instances: %w", err)
+	}
+
+	if len(instances) < j.config.MessageInstanceChecksPerSweep {
+		// Start from the beginning again in the next sweep
+		j.messageInstanceCursor = 0
+	} else {
+		j.messageInstanceCursor = instances[len(instances)-1].ID
+	}
+
+	// Clients are cached by message ID for this sweep, a nil client means the app can't be checked
+	clients := make(map[string]*api.Client)
+
+	for _, instance := range instances {
+		if ctx.Err() != nil {
+			return deleted, ctx.Err()
+		}
+
+		client, ok := clients[instance.MessageID]
+		if !ok {
+			client, err = j.messageClient(ctx, instance.MessageID)
+			if err != nil {
+				slog.Warn(
+					"Failed to get client to check message instances",
+					slog.String("message_id", instance.MessageID),
+					slog.String("error", err.Error()),
+				)
+			}
+			clients[instance.MessageID] = client
+		}
+		if client == nil {
+			continue
+		}
+
+		exists, err := messageExists(ctx, client, instance)
+		if err != nil {
+			slog.Warn(
+				"Failed to check if message of message instance exists",
+				slog.String("message_id", instance.MessageID),
+				slog.Uint64("instance_id", instance.ID),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		if exists {
+			continue
+		}
+
+		err = j.messageInstanceStore.DeleteMessageInstance(ctx, instance.MessageID, instance.ID)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			return deleted, fmt.Errorf("failed to delete message instance: %w", err)
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+func (j *Janitor) messageClient(ctx context.Context, messageID string) (*api.Client, error) {
+	msg, err := j.messageStore.Message(ctx, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	credentials, err := j.appStore.AppCredentials(ctx, msg.AppID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get app credentials: %w", err)
+	}
+
+	token, err := j.tokenCrypt.DecryptString(credentials.DiscordToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt token: %w", err)
+	}
+
+	return api.NewClient("Bot " + token), nil
+}
+
+func messageExists(ctx context.Context, client *api.Client, instance *model.MessageInstance) (bool, error) {
+	channelID, err := discord.ParseSnowflake(instance.DiscordChannelID)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse channel ID: %w", err)
+	}
+
+	messageID, err := discord.ParseSnowflake(instance.DiscordMessageID)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse message ID: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err = client.WithContext(ctx).Message(discord.ChannelID(channelID), discord.MessageID(messageID))
+	if err != nil {
+		if util.IsDiscordRestErrorCode(err, util.DiscordErrorUnknownMessage) ||
+			util.IsDiscordRestErrorCode(err, util.DiscordErrorUnknownChannel) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (j *Janitor) sweepCooldowns(ctx context.Context) (int, error) {
+	return j.cooldownStore.DeleteExpiredCooldowns(ctx, time.Now().UTC())
+}
+
+func (j *Janitor) sweepPluginValues(ctx context.Context) (int, error) {
+	return j.pluginValueStore.DeleteExpiredPluginValues(ctx, time.Now().UTC())
+}
+
+func (j *Janitor) sweepVariableValues(ctx context.Context) (int, error) {
+	return j.variableValueStore.DeleteExpiredVariableValues(ctx, time.Now().UTC())
+}
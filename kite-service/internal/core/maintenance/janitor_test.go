@@ -0,0 +1,88 @@
+package maintenance
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAssetStore struct {
+	store.AssetStore
+
+	assets  map[string]*model.Asset
+	sources map[string][]string
+}
+
+func (s *testAssetStore) AssetsWithoutExpiryAfterID(ctx context.Context, afterID string, createdBefore time.Time, limit int) ([]*model.Asset, error) {
+	var res []*model.Asset
+	for _, asset := range s.assets {
+		if asset.ID > afterID {
+			res = append(res, asset)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
+
+func (s *testAssetStore) ReferencedAssetIDs(ctx context.Context, appID string, assetIDs []string) ([]string, error) {
+	var res []string
+	for _, id := range assetIDs {
+		for _, source := range s.sources[appID] {
+			if strings.Contains(source, id) {
+				res = append(res, id)
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+func (s *testAssetStore) DeleteAsset(ctx context.Context, id string) error {
+	delete(s.assets, id)
+	return nil
+}
+
+func TestDeleteUnreferencedAssets(t *testing.T) {
+	assetStore := &testAssetStore{
+		assets: map[string]*model.Asset{
+			"aaaaaaaaaaaaaaaa": {ID: "aaaaaaaaaaaaaaaa", AppID: "app1"},
+			"bbbbbbbbbbbbbbbb": {ID: "bbbbbbbbbbbbbbbb", AppID: "app1"},
+			"cccccccccccccccc": {ID: "cccccccccccccccc", AppID: "app1"},
+			"dddddddddddddddd": {ID: "dddddddddddddddd", AppID: "app2"},
+			"eeeeeeeeeeeeeeee": {ID: "eeeeeeeeeeeeeeee", AppID: "app2"},
+		},
+		sources: map[string][]string{
+			"app1": {
+				`{"embeds":[{"image":{"url":"https://api.kite.onl/v1/assets/aaaaaaaaaaaaaaaa"}}]}`,
+			},
+			"app2": {
+				// Assets are only referenced by the app they belong to
+				`{"asset_id":"cccccccccccccccc"}`,
+				`{"t":"string","v":"eeeeeeeeeeeeeeee"}`,
+			},
+		},
+	}
+
+	j := NewJanitor(JanitorConfig{}, nil, nil, assetStore, nil, nil, nil, nil, nil, nil)
+
+	deleted, err := j.deleteUnreferencedAssets(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 3, deleted)
+
+	var remaining []string
+	for id := range assetStore.assets {
+		remaining = append(remaining, id)
+	}
+	sort.Strings(remaining)
+	assert.Equal(t, []string{"aaaaaaaaaaaaaaaa", "eeeeeeeeeeeeeeee"}, remaining)
+}
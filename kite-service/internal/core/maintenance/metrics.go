@@ -0,0 +1,34 @@
+package maintenance
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	sweepDeletedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kite",
+		Subsystem: "maintenance",
+		Name:      "deleted_total",
+		Help:      "The number of records deleted by the janitor by task.",
+	}, []string{"task"})
+	sweepErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kite",
+		Subsystem: "maintenance",
+		Name:      "errors_total",
+		Help:      "The number of failed janitor tasks by task.",
+	}, []string{"task"})
+	sweepDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "kite",
+		Subsystem: "maintenance",
+		Name:      "sweep_duration_seconds",
+		Help:      "The time it takes to run a janitor task.",
+		Buckets:   []float64{0.01, 0.1, 0.5, 1, 5, 10, 30, 60, 300},
+	}, []string{"task"})
+	sweepLastSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "kite",
+		Subsystem: "maintenance",
+		Name:      "last_success_timestamp_seconds",
+		Help:      "The unix timestamp of the last successful run of a janitor task.",
+	}, []string{"task"})
+)
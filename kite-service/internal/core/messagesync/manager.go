@@ -0,0 +1,390 @@
+package messagesync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/utils/sendpart"
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/internal/util"
+	"github.com/kitecloud/kite/kite-service/pkg/message"
+	"gopkg.in/guregu/null.v4"
+)
+
+const (
+	// maxPendingJobsPerApp is the max number of sync jobs of an app that can be waiting to be processed.
+	maxPendingJobsPerApp = 10
+	// maxConcurrentJobs is the max number of sync jobs that are processed at the same time by this cluster.
+	// Jobs of the same app are always processed one after another.
+	maxConcurrentJobs = 10
+	// pendingJobsBatchSize is the number of pending jobs that are loaded at once.
+	pendingJobsBatchSize = 500
+	// pollInterval is how often pending jobs are checked, in case they were created by another cluster.
+	pollInterval = 5 * time.Second
+	// staleJobTimeout is how long a running job can go without an update before it's considered abandoned and queued again.
+	staleJobTimeout = 5 * time.Minute
+	// syncJobExpiry is how long the result of a finished sync job is kept.
+	syncJobExpiry = 1 * time.Hour
+	// instanceEditTimeout is the max time a single message edit can take, including waiting for rate limits.
+	instanceEditTimeout = 30 * time.Second
+)
+
+var (
+	ErrSyncQueueFull = errors.New("sync queue is full")
+
+	errGeneratedAttachment = errors.New("messages with generated attachments can only be sent from flows")
+)
+
+type SyncManagerConfig struct {
+	ClusterCount int
+	ClusterIndex int
+}
+
+// SyncManager edits all instances of a message after the message has been changed.
+// Jobs are stored in the database and processed in the background by the cluster that runs the gateway of the app.
+// Jobs of the same app are processed one after another, so a message with many instances
+// doesn't exceed the Discord rate limits of the app, while different apps don't wait for each other.
+type SyncManager struct {
+	sync.Mutex
+
+	config               SyncManagerConfig
+	jobStore             store.MessageSyncJobStore
+	messageStore         store.MessageStore
+	messageInstanceStore store.MessageInstanceStore
+	assetStore           store.AssetStore
+	appStateManager      store.AppStateManager
+
+	// runningApps contains the IDs of apps that have a job running on this cluster
+	runningApps map[string]bool
+	notify      chan struct{}
+}
+
+func NewSyncManager(
+	jobStore store.MessageSyncJobStore,
+	messageStore store.MessageStore,
+	messageInstanceStore store.MessageInstanceStore,
+	assetStore store.AssetStore,
+	appStateManager store.AppStateManager,
+	config SyncManagerConfig,
+) *SyncManager {
+	return &SyncManager{
+		config:               config,
+		jobStore:             jobStore,
+		messageStore:         messageStore,
+		messageInstanceStore: messageInstanceStore,
+		assetStore:           assetStore,
+		appStateManager:      appStateManager,
+		runningApps:          make(map[string]bool),
+		notify:               make(chan struct{}, 1),
+	}
+}
+
+func (m *SyncManager) Run(ctx context.Context) {
+	pollTicker := time.NewTicker(pollInterval)
+	cleanupTicker := time.NewTicker(5 * time.Minute)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				pollTicker.Stop()
+				cleanupTicker.Stop()
+				return
+			case <-m.notify:
+				m.dispatchJobs(ctx)
+			case <-pollTicker.C:
+				m.dispatchJobs(ctx)
+			case <-cleanupTicker.C:
+				m.cleanupJobs(ctx)
+			}
+		}
+	}()
+}
+
+// StartSync queues a job to sync all instances of the message.
+// If there already is a job waiting for the message it's returned instead,
+// because the message is only loaded once the job starts.
+func (m *SyncManager) StartSync(ctx context.Context, appID string, messageID string) (*model.MessageSyncJob, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	job, err := m.jobStore.LatestMessageSyncJob(ctx, messageID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get latest sync job: %w", err)
+	}
+	if job != nil && job.Status == model.MessageSyncStatusPending {
+		return job, nil
+	}
+
+	pending, err := m.jobStore.CountPendingMessageSyncJobsByApp(ctx, appID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count pending sync jobs: %w", err)
+	}
+	if pending >= maxPendingJobsPerApp {
+		return nil, ErrSyncQueueFull
+	}
+
+	now := time.Now().UTC()
+	job = &model.MessageSyncJob{
+		ID:        util.UniqueID(),
+		AppID:     appID,
+		MessageID: messageID,
+		Status:    model.MessageSyncStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := m.jobStore.CreateMessageSyncJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create sync job: %w", err)
+	}
+
+	select {
+	case m.notify <- struct{}{}:
+	default:
+	}
+
+	return job, nil
+}
+
+// Job returns the latest sync job of the message.
+func (m *SyncManager) Job(ctx context.Context, messageID string) (*model.MessageSyncJob, error) {
+	return m.jobStore.LatestMessageSyncJob(ctx, messageID)
+}
+
+// dispatchJobs starts the pending jobs of apps that belong to this cluster and don't have a running job yet.
+func (m *SyncManager) dispatchJobs(ctx context.Context) {
+	jobs, err := m.jobStore.PendingMessageSyncJobs(ctx, pendingJobsBatchSize)
+	if err != nil {
+		slog.Error("Failed to get pending message sync jobs", slog.String("error", err.Error()))
+		return
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	for _, job := range jobs {
+		if len(m.runningApps) >= maxConcurrentJobs {
+			return
+		}
+
+		if m.runningApps[job.AppID] || util.CluserForKey(job.AppID, m.config.ClusterCount) != m.config.ClusterIndex {
+			continue
+		}
+
+		now := time.Now().UTC()
+		claimed, err := m.jobStore.ClaimMessageSyncJob(ctx, job.ID, now)
+		if err != nil {
+			slog.Error(
+				"Failed to claim message sync job",
+				slog.String("app_id", job.AppID),
+				slog.String("job_id", job.ID),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		job.Status = model.MessageSyncStatusRunning
+		job.StartedAt = null.TimeFrom(now)
+		job.UpdatedAt = now
+
+		m.runningApps[job.AppID] = true
+		go func() {
+			m.runJob(ctx, job)
+
+			m.Lock()
+			delete(m.runningApps, job.AppID)
+			m.Unlock()
+
+			// The app may have more jobs waiting
+			select {
+			case m.notify <- struct{}{}:
+			default:
+			}
+		}()
+	}
+}
+
+func (m *SyncManager) runJob(ctx context.Context, job *model.MessageSyncJob) {
+	err := m.syncInstances(ctx, job)
+	if ctx.Err() != nil {
+		// The job is picked up again once it's considered stale
+		return
+	}
+
+	job.Status = model.MessageSyncStatusCompleted
+	if err != nil {
+		job.Status = model.MessageSyncStatusFailed
+		job.Error = null.StringFrom(err.Error())
+
+		slog.Error(
+			"Failed to sync message instances",
+			slog.String("app_id", job.AppID),
+			slog.String("message_id", job.MessageID),
+			slog.String("error", err.Error()),
+		)
+	}
+	job.CompletedAt = null.TimeFrom(time.Now().UTC())
+
+	m.updateJob(ctx, job)
+}
+
+func (m *SyncManager) syncInstances(ctx context.Context, job *model.MessageSyncJob) error {
+	msg, err := m.messageStore.Message(ctx, job.MessageID)
+	if err != nil {
+		return fmt.Errorf("failed to get message: %w", err)
+	}
+
+	instances, err := m.messageInstanceStore.MessageInstancesByMessage(ctx, job.MessageID, false)
+	if err != nil {
+		return fmt.Errorf("failed to get message instances: %w", err)
+	}
+
+	client, err := m.appStateManager.AppClient(ctx, job.AppID)
+	if err != nil {
+		return fmt.Errorf("failed to get app client: %w", err)
+	}
+
+	// Assets are only downloaded once and re-uploaded for each instance
+	assets := make([]*model.Asset, 0, len(msg.Data.Attachments))
+	for _, attachment := range msg.Data.Attachments {
+		if attachment.IsGenerated() {
+			// Generated files can only be created inside flows
+			return errGeneratedAttachment
+		}
+
+		asset, err := m.assetStore.AssetWithContent(ctx, attachment.AssetID)
+		if err != nil {
+			return fmt.Errorf("failed to get asset: %w", err)
+		}
+		assets = append(assets, asset)
+	}
+
+	for _, instance := range instances {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		result := model.MessageInstanceSyncResult{
+			InstanceID:       instance.ID,
+			DiscordGuildID:   instance.DiscordGuildID,
+			DiscordChannelID: instance.DiscordChannelID,
+			Status:           model.MessageInstanceSyncStatusSuccess,
+		}
+
+		err := m.syncInstance(ctx, client, msg, assets, instance)
+		if err != nil {
+			if util.IsDiscordRestErrorCode(err, util.DiscordErrorUnknownMessage) ||
+				util.IsDiscordRestErrorCode(err, util.DiscordErrorUnknownChannel) {
+				result.Status = model.MessageInstanceSyncStatusDeleted
+				err = m.messageInstanceStore.DeleteMessageInstance(ctx, instance.MessageID, instance.ID)
+				if err != nil && !errors.Is(err, store.ErrNotFound) {
+					result.Status = model.MessageInstanceSyncStatusFailed
+					result.Error = null.StringFrom(fmt.Sprintf("failed to delete message instance: %v", err))
+				}
+			} else {
+				result.Status = model.MessageInstanceSyncStatusFailed
+				result.Error = null.StringFrom(err.Error())
+			}
+		}
+
+		syncedInstancesTotal.WithLabelValues(string(result.Status)).Inc()
+
+		// Storing the result also keeps the job from being considered stale
+		m.storeResult(ctx, job, result)
+	}
+
+	return nil
+}
+
+func (m *SyncManager) syncInstance(
+	ctx context.Context,
+	client *api.Client,
+	msg *model.Message,
+	assets []*model.Asset,
+	instance *model.MessageInstance,
+) error {
+	channelID, _ := strconv.ParseUint(instance.DiscordChannelID, 10, 64)
+	messageID, _ := strconv.ParseUint(instance.DiscordMessageID, 10, 64)
+
+	data := msg.Data.ToEditMessageData(message.ConvertOptions{})
+	data.Attachments = &[]discord.Attachment{}
+	data.Files = make([]sendpart.File, len(assets))
+	for i, asset := range assets {
+		data.Files[i] = sendpart.File{
+			Name:   asset.Name,
+			Reader: bytes.NewReader(asset.Content),
+		}
+	}
+
+	editCtx, cancel := context.WithTimeout(ctx, instanceEditTimeout)
+	defer cancel()
+
+	_, err := client.WithContext(editCtx).EditMessageComplex(discord.ChannelID(channelID), discord.MessageID(messageID), data)
+	if err != nil {
+		return fmt.Errorf("failed to edit message: %w", err)
+	}
+
+	_, err = m.messageInstanceStore.UpdateMessageInstance(ctx, &model.MessageInstance{
+		ID:          instance.ID,
+		MessageID:   instance.MessageID,
+		FlowSources: msg.FlowSources,
+		UpdatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update message instance: %w", err)
+	}
+
+	return nil
+}
+
+func (m *SyncManager) updateJob(ctx context.Context, job *model.MessageSyncJob) {
+	job.UpdatedAt = time.Now().UTC()
+
+	if err := m.jobStore.UpdateMessageSyncJob(ctx, job); err != nil {
+		slog.Error(
+			"Failed to update message sync job",
+			slog.String("app_id", job.AppID),
+			slog.String("job_id", job.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+func (m *SyncManager) storeResult(ctx context.Context, job *model.MessageSyncJob, result model.MessageInstanceSyncResult) {
+	job.UpdatedAt = time.Now().UTC()
+
+	if err := m.jobStore.CreateMessageSyncJobResult(ctx, job.ID, result, job.UpdatedAt); err != nil {
+		slog.Error(
+			"Failed to store message sync result",
+			slog.String("app_id", job.AppID),
+			slog.String("job_id", job.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+func (m *SyncManager) cleanupJobs(ctx context.Context) {
+	now := time.Now().UTC()
+
+	reset, err := m.jobStore.ResetStaleMessageSyncJobs(ctx, now.Add(-staleJobTimeout))
+	if err != nil {
+		slog.Error("Failed to reset stale message sync jobs", slog.String("error", err.Error()))
+	} else if reset > 0 {
+		slog.Warn("Reset stale message sync jobs", slog.Int("count", reset))
+	}
+
+	if _, err := m.jobStore.DeleteMessageSyncJobsCompletedBefore(ctx, now.Add(-syncJobExpiry)); err != nil {
+		slog.Error("Failed to delete expired message sync jobs", slog.String("error", err.Error()))
+	}
+}
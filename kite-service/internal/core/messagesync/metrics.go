@@ -0,0 +1,13 @@
+package messagesync
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var syncedInstancesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "kite",
+	Subsystem: "message_sync",
+	Name:      "instances_total",
+	Help:      "The number of message instances that have been synced by result status.",
+}, []string{"status"})
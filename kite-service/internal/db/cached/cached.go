@@ -0,0 +1,80 @@
+// Package cached wraps stores with a cache for hot lookups.
+// Reads are served from the cache when possible and writes invalidate or update the affected entries.
+// Errors of the cache are logged and never fail the request, the wrapped store is used instead.
+package cached
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+func getJSON[T any](ctx context.Context, cache store.Cache, key string) (T, bool) {
+	var res T
+
+	raw, err := cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			slog.Error(
+				"Failed to get value from cache",
+				slog.String("key", key),
+				slog.String("error", err.Error()),
+			)
+		}
+		return res, false
+	}
+
+	if err := json.Unmarshal(raw, &res); err != nil {
+		slog.Error(
+			"Failed to unmarshal cached value",
+			slog.String("key", key),
+			slog.String("error", err.Error()),
+		)
+		return res, false
+	}
+
+	return res, true
+}
+
+func setJSON(ctx context.Context, cache store.Cache, key string, value any, ttl time.Duration) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		slog.Error(
+			"Failed to marshal value for cache",
+			slog.String("key", key),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	if err := cache.Set(ctx, key, raw, ttl); err != nil {
+		slog.Error(
+			"Failed to set value in cache",
+			slog.String("key", key),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+func invalidate(ctx context.Context, cache store.Cache, keys ...string) {
+	if err := cache.Delete(ctx, keys...); err != nil {
+		slog.Error(
+			"Failed to invalidate cache keys",
+			slog.Any("keys", keys),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// ttlUntil caps the ttl so that the entry doesn't outlive the given time.
+func ttlUntil(ttl time.Duration, until time.Time) time.Duration {
+	remaining := time.Until(until)
+	if remaining < ttl {
+		return remaining
+	}
+	return ttl
+}
@@ -0,0 +1,320 @@
+package cached
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/kitecloud/kite/kite-service/internal/config"
+	"github.com/kitecloud/kite/kite-service/internal/db/memory"
+	"github.com/kitecloud/kite/kite-service/internal/db/redis"
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func testCaches(t *testing.T) map[string]store.Cache {
+	mr := miniredis.RunT(t)
+
+	redisClient, err := redis.New(config.RedisConfig{
+		Address:   mr.Addr(),
+		KeyPrefix: "test:",
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { redisClient.Close() })
+
+	return map[string]store.Cache{
+		"memory": memory.NewCache(100),
+		"redis":  redisClient,
+	}
+}
+
+type testVariableValueStore struct {
+	store.VariableValueStore
+
+	values map[string]*model.VariableValue
+	reads  int
+	// afterRead is called after the value has been read, before the cache is filled
+	afterRead func()
+}
+
+func (s *testVariableValueStore) VariableValue(ctx context.Context, variableID string, scope null.String) (*model.VariableValue, error) {
+	s.reads++
+
+	v, ok := s.values[variableID+"/"+scope.String]
+	if s.afterRead != nil {
+		s.afterRead()
+	}
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return v, nil
+}
+
+func (s *testVariableValueStore) UpdateVariableValue(ctx context.Context, operation model.VariableValueOperation, args model.VariableValueOperationArgs, value model.VariableValue) (*model.VariableValue, error) {
+	s.values[value.VariableID+"/"+value.Scope.String] = &value
+	return &value, nil
+}
+
+func (s *testVariableValueStore) DeleteAllVariableValues(ctx context.Context, variableID string) error {
+	for key, v := range s.values {
+		if v.VariableID == variableID {
+			delete(s.values, key)
+		}
+	}
+	return nil
+}
+
+func TestVariableValueStore(t *testing.T) {
+	for name, cache := range testCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			inner := &testVariableValueStore{values: map[string]*model.VariableValue{}}
+			s := NewVariableValueStore(inner, cache, time.Minute)
+
+			scope := null.StringFrom("guild")
+
+			// Missing values are cached as well
+			_, err := s.VariableValue(ctx, "var", scope)
+			assert.ErrorIs(t, err, store.ErrNotFound)
+			_, err = s.VariableValue(ctx, "var", scope)
+			assert.ErrorIs(t, err, store.ErrNotFound)
+			assert.Equal(t, 1, inner.reads)
+
+			// Updates invalidate the cached value
+			_, err = s.UpdateVariableValue(ctx, model.VariableValueOperation("overwrite"), model.VariableValueOperationArgs{}, model.VariableValue{
+				VariableID: "var",
+				Scope:      scope,
+				Data:       thing.NewInt(3),
+			})
+			require.NoError(t, err)
+
+			v, err := s.VariableValue(ctx, "var", scope)
+			require.NoError(t, err)
+			assert.Equal(t, thing.NewInt(3), v.Data)
+			_, err = s.VariableValue(ctx, "var", scope)
+			require.NoError(t, err)
+			assert.Equal(t, 2, inner.reads)
+
+			// Deleting all values makes the cached entries unreachable
+			require.NoError(t, s.DeleteAllVariableValues(ctx, "var"))
+
+			_, err = s.VariableValue(ctx, "var", scope)
+			assert.ErrorIs(t, err, store.ErrNotFound)
+			assert.Equal(t, 3, inner.reads)
+		})
+	}
+}
+
+func TestVariableValueStoreConcurrentWrite(t *testing.T) {
+	for name, cache := range testCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			inner := &testVariableValueStore{values: map[string]*model.VariableValue{
+				"var/": {VariableID: "var", Data: thing.NewInt(1)},
+			}}
+			s := NewVariableValueStore(inner, cache, time.Minute)
+
+			// The value is updated after it has been read but before the cache is filled with it
+			inner.afterRead = func() {
+				inner.afterRead = nil
+
+				_, err := s.UpdateVariableValue(ctx, model.VariableValueOperation("overwrite"), model.VariableValueOperationArgs{}, model.VariableValue{
+					VariableID: "var",
+					Data:       thing.NewInt(2),
+				})
+				require.NoError(t, err)
+			}
+
+			v, err := s.VariableValue(ctx, "var", null.String{})
+			require.NoError(t, err)
+			assert.Equal(t, thing.NewInt(1), v.Data)
+
+			// The stale value must not be served from the cache
+			v, err = s.VariableValue(ctx, "var", null.String{})
+			require.NoError(t, err)
+			assert.Equal(t, thing.NewInt(2), v.Data)
+			assert.Equal(t, 2, inner.reads)
+		})
+	}
+}
+
+func TestVariableValueStoreExpiry(t *testing.T) {
+	ctx := context.Background()
+	inner := &testVariableValueStore{values: map[string]*model.VariableValue{
+		"var/": {
+			VariableID: "var",
+			Data:       thing.NewString("soon gone"),
+			ExpiresAt:  null.TimeFrom(time.Now().Add(50 * time.Millisecond)),
+		},
+	}}
+	s := NewVariableValueStore(inner, memory.NewCache(100), time.Minute)
+
+	_, err := s.VariableValue(ctx, "var", null.String{})
+	require.NoError(t, err)
+
+	time.Sleep(60 * time.Millisecond)
+	delete(inner.values, "var/")
+
+	_, err = s.VariableValue(ctx, "var", null.String{})
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+type testVariableStore struct {
+	store.VariableStore
+
+	variables map[string]*model.Variable
+	reads     int
+}
+
+func (s *testVariableStore) VariableDefinition(ctx context.Context, id string) (*model.Variable, error) {
+	s.reads++
+
+	v, ok := s.variables[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return v, nil
+}
+
+func (s *testVariableStore) UpdateVariable(ctx context.Context, variable *model.Variable) (*model.Variable, error) {
+	s.variables[variable.ID] = variable
+	return variable, nil
+}
+
+func (s *testVariableStore) DeleteVariable(ctx context.Context, id string) error {
+	delete(s.variables, id)
+	return nil
+}
+
+func TestVariableStore(t *testing.T) {
+	for name, cache := range testCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			inner := &testVariableStore{variables: map[string]*model.Variable{
+				"var": {ID: "var", Name: "balance", Type: thing.TypeInt, DefaultValue: thing.NewInt(100)},
+			}}
+			s := NewVariableStore(inner, cache, time.Minute)
+
+			for range 2 {
+				v, err := s.VariableDefinition(ctx, "var")
+				require.NoError(t, err)
+				assert.Equal(t, thing.TypeInt, v.Type)
+				assert.Equal(t, thing.NewInt(100), v.DefaultValue)
+			}
+			assert.Equal(t, 1, inner.reads)
+
+			// Updates invalidate the cached definition
+			_, err := s.UpdateVariable(ctx, &model.Variable{ID: "var", Name: "balance", Type: thing.TypeFloat, DefaultValue: thing.Null})
+			require.NoError(t, err)
+
+			v, err := s.VariableDefinition(ctx, "var")
+			require.NoError(t, err)
+			assert.Equal(t, thing.TypeFloat, v.Type)
+			assert.Equal(t, 2, inner.reads)
+
+			require.NoError(t, s.DeleteVariable(ctx, "var"))
+
+			_, err = s.VariableDefinition(ctx, "var")
+			assert.ErrorIs(t, err, store.ErrNotFound)
+		})
+	}
+}
+
+type testMessageInstanceStore struct {
+	store.MessageInstanceStore
+
+	instances map[string]*model.MessageInstance
+	reads     int
+}
+
+func (s *testMessageInstanceStore) MessageInstanceByDiscordMessageID(ctx context.Context, discordMessageID string) (*model.MessageInstance, error) {
+	s.reads++
+
+	instance, ok := s.instances[discordMessageID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return instance, nil
+}
+
+func (s *testMessageInstanceStore) DeleteMessageInstanceByDiscordMessageID(ctx context.Context, discordMessageID string) error {
+	delete(s.instances, discordMessageID)
+	return nil
+}
+
+func TestMessageInstanceStore(t *testing.T) {
+	for name, cache := range testCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			inner := &testMessageInstanceStore{instances: map[string]*model.MessageInstance{
+				"1": {ID: 1, MessageID: "msg", DiscordMessageID: "1"},
+				"2": {ID: 2, MessageID: "msg", DiscordMessageID: "2", Ephemeral: true},
+			}}
+			s := NewMessageInstanceStore(inner, cache, time.Minute)
+
+			for range 2 {
+				instance, err := s.MessageInstanceByDiscordMessageID(ctx, "1")
+				require.NoError(t, err)
+				assert.Equal(t, uint64(1), instance.ID)
+			}
+			assert.Equal(t, 1, inner.reads)
+
+			// Ephemeral instances are not cached
+			for range 2 {
+				_, err := s.MessageInstanceByDiscordMessageID(ctx, "2")
+				require.NoError(t, err)
+			}
+			assert.Equal(t, 3, inner.reads)
+
+			require.NoError(t, s.DeleteMessageInstanceByDiscordMessageID(ctx, "1"))
+
+			_, err := s.MessageInstanceByDiscordMessageID(ctx, "1")
+			assert.ErrorIs(t, err, store.ErrNotFound)
+		})
+	}
+}
+
+type testEntitlementStore struct {
+	store.EntitlementStore
+
+	entitlements []*model.Entitlement
+}
+
+func (s *testEntitlementStore) ActiveEntitlements(ctx context.Context, appID string, now time.Time) ([]*model.Entitlement, error) {
+	var res []*model.Entitlement
+	for _, entitlement := range s.entitlements {
+		if !entitlement.EndsAt.Valid || entitlement.EndsAt.Time.After(now) {
+			res = append(res, entitlement)
+		}
+	}
+	return res, nil
+}
+
+func TestEntitlementStore(t *testing.T) {
+	for name, cache := range testCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Now().UTC()
+			inner := &testEntitlementStore{entitlements: []*model.Entitlement{
+				{ID: "forever", AppID: "app"},
+				{ID: "ending", AppID: "app", EndsAt: null.TimeFrom(now.Add(time.Hour))},
+			}}
+			s := NewEntitlementStore(inner, cache, 2*time.Hour)
+
+			entitlements, err := s.ActiveEntitlements(ctx, "app", now)
+			require.NoError(t, err)
+			assert.Len(t, entitlements, 2)
+
+			// Cached entitlements that have ended at the given time are not returned
+			entitlements, err = s.ActiveEntitlements(ctx, "app", now.Add(2*time.Hour))
+			require.NoError(t, err)
+			require.Len(t, entitlements, 1)
+			assert.Equal(t, "forever", entitlements[0].ID)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package cached
+
+import (
+	"context"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+// EntitlementStore caches the active entitlements of an app which are used to compute its features on every request.
+type EntitlementStore struct {
+	store.EntitlementStore
+
+	cache store.Cache
+	ttl   time.Duration
+}
+
+func NewEntitlementStore(inner store.EntitlementStore, cache store.Cache, ttl time.Duration) *EntitlementStore {
+	return &EntitlementStore{
+		EntitlementStore: inner,
+		cache:            cache,
+		ttl:              ttl,
+	}
+}
+
+func activeEntitlementsKey(appID string) string {
+	return "entitlements:active:" + appID
+}
+
+// ActiveEntitlements returns the cached entitlements that are still active at now.
+// The entry expires when the first of the entitlements ends, the cached entitlements are filtered as well
+// because the cache may keep the entry slightly longer and now isn't necessarily the current time.
+func (s *EntitlementStore) ActiveEntitlements(ctx context.Context, appID string, now time.Time) ([]*model.Entitlement, error) {
+	key := activeEntitlementsKey(appID)
+
+	if entitlements, ok := getJSON[[]*model.Entitlement](ctx, s.cache, key); ok {
+		active := make([]*model.Entitlement, 0, len(entitlements))
+		for _, entitlement := range entitlements {
+			if !entitlement.EndsAt.Valid || entitlement.EndsAt.Time.After(now) {
+				active = append(active, entitlement)
+			}
+		}
+		return active, nil
+	}
+
+	entitlements, err := s.EntitlementStore.ActiveEntitlements(ctx, appID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := s.ttl
+	for _, entitlement := range entitlements {
+		if entitlement.EndsAt.Valid {
+			ttl = ttlUntil(ttl, entitlement.EndsAt.Time)
+		}
+	}
+
+	if ttl > 0 {
+		setJSON(ctx, s.cache, key, entitlements, ttl)
+	}
+
+	return entitlements, nil
+}
+
+func (s *EntitlementStore) UpsertSubscriptionEntitlement(ctx context.Context, entitlement model.Entitlement) (*model.Entitlement, error) {
+	res, err := s.EntitlementStore.UpsertSubscriptionEntitlement(ctx, entitlement)
+	if err != nil {
+		return nil, err
+	}
+
+	invalidate(ctx, s.cache, activeEntitlementsKey(res.AppID))
+	return res, nil
+}
+
+func (s *EntitlementStore) UpdateSubscriptionEntitlement(ctx context.Context, entitlement model.Entitlement) (*model.Entitlement, error) {
+	res, err := s.EntitlementStore.UpdateSubscriptionEntitlement(ctx, entitlement)
+	if err != nil {
+		return nil, err
+	}
+
+	invalidate(ctx, s.cache, activeEntitlementsKey(res.AppID))
+	return res, nil
+}
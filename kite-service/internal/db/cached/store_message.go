@@ -0,0 +1,88 @@
+package cached
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+// MessageInstanceStore caches message instances by their Discord message ID which are looked up on every component interaction.
+// Ephemeral instances are not cached because they are removed in bulk by the janitor.
+type MessageInstanceStore struct {
+	store.MessageInstanceStore
+
+	cache store.Cache
+	ttl   time.Duration
+}
+
+func NewMessageInstanceStore(inner store.MessageInstanceStore, cache store.Cache, ttl time.Duration) *MessageInstanceStore {
+	return &MessageInstanceStore{
+		MessageInstanceStore: inner,
+		cache:                cache,
+		ttl:                  ttl,
+	}
+}
+
+func messageInstanceKey(discordMessageID string) string {
+	return "message_instance:discord:" + discordMessageID
+}
+
+func (s *MessageInstanceStore) MessageInstanceByDiscordMessageID(ctx context.Context, discordMessageID string) (*model.MessageInstance, error) {
+	key := messageInstanceKey(discordMessageID)
+
+	if instance, ok := getJSON[*model.MessageInstance](ctx, s.cache, key); ok && instance != nil {
+		return instance, nil
+	}
+
+	instance, err := s.MessageInstanceStore.MessageInstanceByDiscordMessageID(ctx, discordMessageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !instance.Ephemeral {
+		setJSON(ctx, s.cache, key, instance, s.ttl)
+	}
+
+	return instance, nil
+}
+
+func (s *MessageInstanceStore) UpdateMessageInstance(ctx context.Context, instance *model.MessageInstance) (*model.MessageInstance, error) {
+	res, err := s.MessageInstanceStore.UpdateMessageInstance(ctx, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	invalidate(ctx, s.cache, messageInstanceKey(res.DiscordMessageID))
+	return res, nil
+}
+
+func (s *MessageInstanceStore) DeleteMessageInstance(ctx context.Context, messageID string, instanceID uint64) error {
+	// We need the Discord message ID to invalidate the cache entry
+	instance, err := s.MessageInstanceStore.MessageInstance(ctx, messageID, instanceID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+
+	err = s.MessageInstanceStore.DeleteMessageInstance(ctx, messageID, instanceID)
+	if err != nil {
+		return err
+	}
+
+	if instance != nil {
+		invalidate(ctx, s.cache, messageInstanceKey(instance.DiscordMessageID))
+	}
+	return nil
+}
+
+func (s *MessageInstanceStore) DeleteMessageInstanceByDiscordMessageID(ctx context.Context, discordMessageID string) error {
+	err := s.MessageInstanceStore.DeleteMessageInstanceByDiscordMessageID(ctx, discordMessageID)
+	if err != nil {
+		return err
+	}
+
+	invalidate(ctx, s.cache, messageInstanceKey(discordMessageID))
+	return nil
+}
@@ -0,0 +1,210 @@
+package cached
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/internal/util"
+	"gopkg.in/guregu/null.v4"
+)
+
+// VariableValueStore caches single variable values which are read on every variable access in a flow.
+//
+// The keys of a value contain the version of its scope which is dropped after every write, instead of writing
+// the new value through. A read that raced with a write fills the entry of the old version which can't be reached anymore.
+// The keys of a variable also contain a generation which is replaced when all values of the variable are deleted,
+// that way the old entries can't be reached anymore without having to know their scopes.
+type VariableValueStore struct {
+	store.VariableValueStore
+
+	cache store.Cache
+	ttl   time.Duration
+}
+
+func NewVariableValueStore(inner store.VariableValueStore, cache store.Cache, ttl time.Duration) *VariableValueStore {
+	return &VariableValueStore{
+		VariableValueStore: inner,
+		cache:              cache,
+		ttl:                ttl,
+	}
+}
+
+// cachedVariableValue is stored in the cache, a nil value means that the value doesn't exist.
+type cachedVariableValue struct {
+	Value *model.VariableValue `json:"value"`
+}
+
+func variableGenerationKey(variableID string) string {
+	return "variable_value:" + variableID + ":generation"
+}
+
+// version returns the version that is stored under the key or creates a new one.
+// A new version must be created before the store is read, so that a write afterwards can drop it.
+func (s *VariableValueStore) version(ctx context.Context, key string) (string, bool) {
+	version, err := s.cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			return "", false
+		}
+
+		version = []byte(util.UniqueID())
+		if err := s.cache.Set(ctx, key, version, s.ttl); err != nil {
+			return "", false
+		}
+	}
+
+	return string(version), true
+}
+
+func (s *VariableValueStore) scopeVersionKey(ctx context.Context, variableID string, scope null.String) (string, bool) {
+	generation, ok := s.version(ctx, variableGenerationKey(variableID))
+	if !ok {
+		return "", false
+	}
+
+	key := "variable_value:" + variableID + ":" + generation
+	if scope.Valid {
+		return key + ":s:" + scope.String + ":version", true
+	}
+	return key + ":-:version", true
+}
+
+func (s *VariableValueStore) variableValueKey(ctx context.Context, variableID string, scope null.String) (string, bool) {
+	versionKey, ok := s.scopeVersionKey(ctx, variableID, scope)
+	if !ok {
+		return "", false
+	}
+
+	version, ok := s.version(ctx, versionKey)
+	if !ok {
+		return "", false
+	}
+
+	return versionKey + ":" + version, true
+}
+
+func (s *VariableValueStore) setCachedValue(ctx context.Context, key string, value *model.VariableValue) {
+	ttl := s.ttl
+	if value != nil && value.ExpiresAt.Valid {
+		ttl = ttlUntil(ttl, value.ExpiresAt.Time)
+	}
+
+	if ttl > 0 {
+		setJSON(ctx, s.cache, key, cachedVariableValue{Value: value}, ttl)
+	}
+}
+
+func (s *VariableValueStore) VariableValue(ctx context.Context, variableID string, scope null.String) (*model.VariableValue, error) {
+	key, ok := s.variableValueKey(ctx, variableID, scope)
+	if !ok {
+		return s.VariableValueStore.VariableValue(ctx, variableID, scope)
+	}
+
+	if cached, ok := getJSON[cachedVariableValue](ctx, s.cache, key); ok {
+		if cached.Value == nil || (cached.Value.ExpiresAt.Valid && !cached.Value.ExpiresAt.Time.After(time.Now())) {
+			return nil, store.ErrNotFound
+		}
+		return cached.Value, nil
+	}
+
+	value, err := s.VariableValueStore.VariableValue(ctx, variableID, scope)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			s.setCachedValue(ctx, key, nil)
+		}
+		return nil, err
+	}
+
+	s.setCachedValue(ctx, key, value)
+	return value, nil
+}
+
+func (s *VariableValueStore) SetVariableValue(ctx context.Context, value model.VariableValue) error {
+	err := s.VariableValueStore.SetVariableValue(ctx, value)
+	s.invalidateValue(ctx, value.VariableID, value.Scope)
+	return err
+}
+
+func (s *VariableValueStore) UpdateVariableValue(
+	ctx context.Context,
+	operation model.VariableValueOperation,
+	args model.VariableValueOperationArgs,
+	value model.VariableValue,
+) (*model.VariableValue, error) {
+	res, err := s.VariableValueStore.UpdateVariableValue(ctx, operation, args, value)
+	s.invalidateValue(ctx, value.VariableID, value.Scope)
+	return res, err
+}
+
+func (s *VariableValueStore) DeleteVariableValue(ctx context.Context, variableID string, scope null.String) error {
+	err := s.VariableValueStore.DeleteVariableValue(ctx, variableID, scope)
+	s.invalidateValue(ctx, variableID, scope)
+	return err
+}
+
+func (s *VariableValueStore) DeleteAllVariableValues(ctx context.Context, variableID string) error {
+	err := s.VariableValueStore.DeleteAllVariableValues(ctx, variableID)
+	invalidate(ctx, s.cache, variableGenerationKey(variableID))
+	return err
+}
+
+// invalidateValue drops the version of the scope, the next read creates a new version and reads the store again.
+func (s *VariableValueStore) invalidateValue(ctx context.Context, variableID string, scope null.String) {
+	if key, ok := s.scopeVersionKey(ctx, variableID, scope); ok {
+		invalidate(ctx, s.cache, key)
+	}
+}
+
+// VariableStore caches variable definitions which are read on every variable access in a flow or module.
+// Only variables that exist are cached, updates and deletes through the store invalidate them.
+// Variables that are deleted together with their app, module or namespace stay cached until the TTL expires,
+// writing their values fails because the variable doesn't exist anymore.
+type VariableStore struct {
+	store.VariableStore
+
+	cache store.Cache
+	ttl   time.Duration
+}
+
+func NewVariableStore(inner store.VariableStore, cache store.Cache, ttl time.Duration) *VariableStore {
+	return &VariableStore{
+		VariableStore: inner,
+		cache:         cache,
+		ttl:           ttl,
+	}
+}
+
+func variableKey(id string) string {
+	return "variable:" + id
+}
+
+func (s *VariableStore) VariableDefinition(ctx context.Context, id string) (*model.Variable, error) {
+	key := variableKey(id)
+
+	if variable, ok := getJSON[*model.Variable](ctx, s.cache, key); ok && variable != nil {
+		return variable, nil
+	}
+
+	variable, err := s.VariableStore.VariableDefinition(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	setJSON(ctx, s.cache, key, variable, s.ttl)
+	return variable, nil
+}
+
+func (s *VariableStore) UpdateVariable(ctx context.Context, variable *model.Variable) (*model.Variable, error) {
+	res, err := s.VariableStore.UpdateVariable(ctx, variable)
+	invalidate(ctx, s.cache, variableKey(variable.ID))
+	return res, err
+}
+
+func (s *VariableStore) DeleteVariable(ctx context.Context, id string) error {
+	err := s.VariableStore.DeleteVariable(ctx, id)
+	invalidate(ctx, s.cache, variableKey(id))
+	return err
+}
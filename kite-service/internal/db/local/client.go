@@ -0,0 +1,29 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/kitecloud/kite/kite-service/internal/config"
+)
+
+// Client stores objects as files on the local filesystem, each bucket is a directory below the root path.
+// It's meant for small self-hosted installs that don't want to run an S3 compatible server.
+type Client struct {
+	root string
+}
+
+func New(cfg config.LocalObjectConfig) (*Client, error) {
+	if err := os.MkdirAll(cfg.Path, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create object directory %s: %w", cfg.Path, err)
+	}
+
+	return &Client{root: cfg.Path}, nil
+}
+
+// Ping checks if the object directory is still accessible.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := os.Stat(c.root)
+	return err
+}
@@ -0,0 +1,120 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+func (c *Client) CreateBucketIfNotExists(ctx context.Context, bucket string) error {
+	dir, err := c.path(bucket)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create bucket %s: %w", bucket, err)
+	}
+
+	return nil
+}
+
+func (c *Client) UploadObject(ctx context.Context, bucket string, object *model.Object) error {
+	path, err := c.path(bucket, object.Name)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see a partially written object
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to upload object %s to bucket %s: %w", object.Name, bucket, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(object.Content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to upload object %s to bucket %s: %w", object.Name, bucket, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to upload object %s to bucket %s: %w", object.Name, bucket, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to upload object %s to bucket %s: %w", object.Name, bucket, err)
+	}
+
+	return nil
+}
+
+func (c *Client) UploadObjectIfNotExists(ctx context.Context, bucket string, object *model.Object) error {
+	path, err := c.path(bucket, object.Name)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if info != nil && info.Size() > 0 {
+		return nil
+	}
+
+	return c.UploadObject(ctx, bucket, object)
+}
+
+// DownloadObject detects the content type from the content, the local store doesn't keep any metadata.
+func (c *Client) DownloadObject(ctx context.Context, bucket string, name string) (*model.Object, error) {
+	path, err := c.path(bucket, name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &model.Object{
+		Name:        name,
+		ContentType: http.DetectContentType(data),
+		Content:     data,
+	}, nil
+}
+
+func (c *Client) DeleteObject(ctx context.Context, bucket string, name string) error {
+	path, err := c.path(bucket, name)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete object %s from bucket %s: %w", name, bucket, err)
+	}
+
+	return nil
+}
+
+// path joins the elements to the root path, elements must not contain path separators.
+func (c *Client) path(elems ...string) (string, error) {
+	for _, elem := range elems {
+		if elem == "" || elem == "." || elem == ".." || filepath.Base(elem) != elem {
+			return "", fmt.Errorf("invalid object path element %q", elem)
+		}
+	}
+
+	return filepath.Join(append([]string{c.root}, elems...)...), nil
+}
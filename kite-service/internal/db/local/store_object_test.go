@@ -0,0 +1,60 @@
+package local
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kitecloud/kite/kite-service/internal/config"
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testClient(t *testing.T) *Client {
+	c, err := New(config.LocalObjectConfig{Path: t.TempDir()})
+	require.NoError(t, err)
+
+	require.NoError(t, c.CreateBucketIfNotExists(context.Background(), "assets"))
+	return c
+}
+
+func TestObjectRoundTrip(t *testing.T) {
+	c := testClient(t)
+	ctx := context.Background()
+
+	err := c.UploadObject(ctx, "assets", &model.Object{
+		Name:    "hello",
+		Content: []byte("hello world"),
+	})
+	require.NoError(t, err)
+
+	// Existing objects are not replaced
+	err = c.UploadObjectIfNotExists(ctx, "assets", &model.Object{
+		Name:    "hello",
+		Content: []byte("other"),
+	})
+	require.NoError(t, err)
+
+	object, err := c.DownloadObject(ctx, "assets", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello world"), object.Content)
+	assert.Equal(t, "text/plain; charset=utf-8", object.ContentType)
+
+	require.NoError(t, c.DeleteObject(ctx, "assets", "hello"))
+	require.NoError(t, c.DeleteObject(ctx, "assets", "hello"))
+
+	_, err = c.DownloadObject(ctx, "assets", "hello")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func TestObjectInvalidPath(t *testing.T) {
+	c := testClient(t)
+	ctx := context.Background()
+
+	for _, name := range []string{"", ".", "..", "../hello", "a/b"} {
+		_, err := c.DownloadObject(ctx, "assets", name)
+		assert.Error(t, err, name)
+		assert.NotErrorIs(t, err, store.ErrNotFound, name)
+	}
+}
@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/internal/util"
+)
+
+// Cache is an in-process LRU cache.
+// It's not shared between processes, so writes in one process don't invalidate the entries of another.
+type Cache struct {
+	lru *util.LRU[[]byte]
+}
+
+func NewCache(maxEntries int) *Cache {
+	return &Cache{
+		lru: util.NewLRU[[]byte](maxEntries),
+	}
+}
+
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, ok := c.lru.Get(key)
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+
+	return slices.Clone(value), nil
+}
+
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.lru.Set(key, slices.Clone(value), ttl)
+	return nil
+}
+
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		c.lru.Delete(key)
+	}
+	return nil
+}
@@ -30,6 +30,7 @@ func New(connectionDSN string, clusterCount int) (*Client, error) {
 	config.MaxConnLifetime = 30 * time.Minute
 	config.MaxConnIdleTime = 5 * time.Minute
 	config.HealthCheckPeriod = 1 * time.Minute
+	config.ConnConfig.Tracer = queryTracer{}
 
 	db, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
@@ -43,6 +44,11 @@ func New(connectionDSN string, clusterCount int) (*Client, error) {
 	}, nil
 }
 
+// Ping checks if the database is reachable.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.DB.Ping(ctx)
+}
+
 func BuildConnectionDSN(cfg config.PostgresConfig) string {
 	dsn := fmt.Sprintf(
 		"host=%s port=%d dbname=%s user=%s sslmode=disable connect_timeout=4",
@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "kite",
+	Subsystem: "postgres",
+	Name:      "query_duration_seconds",
+	Help:      "The duration of Postgres queries by query name and outcome.",
+	Buckets:   prometheus.ExponentialBuckets(0.0005, 4, 8),
+}, []string{"query", "outcome"})
+
+// queryName extracts the name from queries generated by sqlc which start with "-- name: QueryName :one".
+func queryName(sql string) string {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(sql), "-- name: ")
+	if !ok {
+		return "unknown"
+	}
+
+	name, _, _ := strings.Cut(rest, " ")
+	return name
+}
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS cooldowns (
+    key TEXT PRIMARY KEY,
+    app_id TEXT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+
+    count INTEGER NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS cooldowns_app_id ON cooldowns (app_id);
+CREATE INDEX IF NOT EXISTS cooldowns_expires_at ON cooldowns (expires_at);
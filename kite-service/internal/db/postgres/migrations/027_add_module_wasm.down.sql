@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS modules_app_id;
+
+ALTER TABLE modules DROP COLUMN IF EXISTS wasm_hash;
+ALTER TABLE modules DROP COLUMN IF EXISTS wasm_bytes;
@@ -0,0 +1,4 @@
+ALTER TABLE modules ADD COLUMN IF NOT EXISTS wasm_bytes BYTEA NOT NULL DEFAULT '';
+ALTER TABLE modules ADD COLUMN IF NOT EXISTS wasm_hash TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS modules_app_id ON modules (app_id);
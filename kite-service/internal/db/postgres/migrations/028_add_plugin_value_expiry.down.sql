@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS plugin_values_plugin_instance_id_key_prefix;
+DROP INDEX IF EXISTS plugin_values_expires_at;
+
+ALTER TABLE plugin_values DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,4 @@
+ALTER TABLE plugin_values ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS plugin_values_expires_at ON plugin_values (expires_at);
+CREATE INDEX IF NOT EXISTS plugin_values_plugin_instance_id_key_prefix ON plugin_values (plugin_instance_id, key text_pattern_ops);
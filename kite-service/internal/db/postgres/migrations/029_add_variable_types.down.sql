@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS variable_values_expires_at;
+
+ALTER TABLE variable_values DROP COLUMN IF EXISTS expires_at;
+
+ALTER TABLE variables DROP COLUMN IF EXISTS value_ttl_seconds;
+ALTER TABLE variables DROP COLUMN IF EXISTS default_value;
+ALTER TABLE variables DROP COLUMN IF EXISTS schema;
+ALTER TABLE variables DROP COLUMN IF EXISTS type;
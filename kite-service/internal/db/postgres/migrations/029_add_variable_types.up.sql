@@ -0,0 +1,8 @@
+ALTER TABLE variables ADD COLUMN IF NOT EXISTS type TEXT NOT NULL DEFAULT 'any';
+ALTER TABLE variables ADD COLUMN IF NOT EXISTS schema JSONB;
+ALTER TABLE variables ADD COLUMN IF NOT EXISTS default_value JSONB;
+ALTER TABLE variables ADD COLUMN IF NOT EXISTS value_ttl_seconds INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE variable_values ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS variable_values_expires_at ON variable_values (expires_at);
@@ -0,0 +1,10 @@
+DELETE FROM variables WHERE namespace_id IS NOT NULL;
+
+DROP INDEX IF EXISTS variables_namespace_id;
+ALTER TABLE variables DROP CONSTRAINT IF EXISTS variables_namespace_id_name_key;
+ALTER TABLE variables DROP CONSTRAINT IF EXISTS variables_owner_check;
+ALTER TABLE variables DROP COLUMN IF EXISTS namespace_id;
+ALTER TABLE variables ALTER COLUMN app_id SET NOT NULL;
+
+DROP TABLE IF EXISTS namespace_grants;
+DROP TABLE IF EXISTS namespaces;
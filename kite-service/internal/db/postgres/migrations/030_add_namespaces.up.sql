@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS namespaces (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT,
+    owner_user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS namespaces_owner_user_id ON namespaces (owner_user_id);
+
+CREATE TABLE IF NOT EXISTS namespace_grants (
+    namespace_id TEXT NOT NULL REFERENCES namespaces(id) ON DELETE CASCADE,
+    app_id TEXT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    access TEXT NOT NULL,
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (namespace_id, app_id)
+);
+
+CREATE INDEX IF NOT EXISTS namespace_grants_app_id ON namespace_grants (app_id);
+
+-- Variables are either owned by an app or by a namespace
+ALTER TABLE variables ALTER COLUMN app_id DROP NOT NULL;
+ALTER TABLE variables ADD COLUMN IF NOT EXISTS namespace_id TEXT REFERENCES namespaces(id) ON DELETE CASCADE;
+ALTER TABLE variables ADD CONSTRAINT variables_owner_check CHECK ((app_id IS NULL) <> (namespace_id IS NULL));
+ALTER TABLE variables ADD CONSTRAINT variables_namespace_id_name_key UNIQUE (namespace_id, name);
+
+CREATE INDEX IF NOT EXISTS variables_namespace_id ON variables (namespace_id);
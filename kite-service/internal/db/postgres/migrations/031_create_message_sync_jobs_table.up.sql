@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS message_sync_jobs (
+    id TEXT PRIMARY KEY,
+    app_id TEXT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    message_id TEXT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    status TEXT NOT NULL,
+    error TEXT,
+    results JSONB NOT NULL,
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS message_sync_jobs_message_id ON message_sync_jobs (message_id, created_at);
+CREATE INDEX IF NOT EXISTS message_sync_jobs_status ON message_sync_jobs (status, created_at);
+CREATE INDEX IF NOT EXISTS message_sync_jobs_completed_at ON message_sync_jobs (completed_at);
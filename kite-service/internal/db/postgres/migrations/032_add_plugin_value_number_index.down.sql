@@ -0,0 +1 @@
+DROP INDEX IF EXISTS plugin_values_plugin_instance_id_number;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS variable_values_variable_id_number;
+DROP INDEX IF EXISTS variable_values_variable_id_number_asc;
@@ -0,0 +1,11 @@
+-- Variable queries sort scoped values by their number in both directions
+CREATE INDEX IF NOT EXISTS variable_values_variable_id_number ON variable_values (
+    variable_id,
+    (CASE WHEN jsonb_typeof(value->'v') = 'number' THEN (value->'v')::float8 END) DESC NULLS LAST,
+    scope
+);
+CREATE INDEX IF NOT EXISTS variable_values_variable_id_number_asc ON variable_values (
+    variable_id,
+    (CASE WHEN jsonb_typeof(value->'v') = 'number' THEN (value->'v')::float8 END) ASC NULLS LAST,
+    scope
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS namespace_members (
+    namespace_id TEXT NOT NULL REFERENCES namespaces(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (namespace_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS namespace_members_user_id ON namespace_members (user_id);
@@ -0,0 +1,3 @@
+ALTER TABLE message_sync_jobs ADD COLUMN IF NOT EXISTS results JSONB NOT NULL DEFAULT '[]';
+
+DROP TABLE IF EXISTS message_sync_job_results;
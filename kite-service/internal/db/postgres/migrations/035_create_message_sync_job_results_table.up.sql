@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS message_sync_job_results (
+    job_id TEXT NOT NULL REFERENCES message_sync_jobs(id) ON DELETE CASCADE,
+    instance_id BIGINT NOT NULL,
+    discord_guild_id TEXT NOT NULL,
+    discord_channel_id TEXT NOT NULL,
+    status TEXT NOT NULL,
+    error TEXT,
+
+    created_at TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (job_id, instance_id)
+);
+
+ALTER TABLE message_sync_jobs DROP COLUMN IF EXISTS results;
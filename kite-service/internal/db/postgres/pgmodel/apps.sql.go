@@ -243,8 +243,7 @@ func (q *Queries) GetAppEntities(ctx context.Context, appID string) ([]GetAppEnt
 
 const getAppsByCollaborator = `-- name: GetAppsByCollaborator :many
 SELECT a.id, a.name, a.description, a.enabled, a.owner_user_id, a.creator_user_id, a.discord_token, a.discord_id, a.created_at, a.updated_at, a.discord_status, a.disabled_reason FROM apps a
-LEFT JOIN collaborators c ON a.id = c.app_id
-WHERE a.owner_user_id = $1 OR c.user_id = $1
+WHERE a.owner_user_id = $1 OR a.id IN (SELECT app_id FROM collaborators WHERE user_id = $1)
 ORDER BY a.created_at DESC
 `
 
@@ -131,6 +131,51 @@ func (q *Queries) GetAsset(ctx context.Context, id string) (Asset, error) {
 	return i, err
 }
 
+const getAssetsWithoutExpiryAfterID = `-- name: GetAssetsWithoutExpiryAfterID :many
+SELECT id, name, content_hash, content_type, content_size, app_id, module_id, creator_user_id, created_at, updated_at, expires_at FROM assets
+WHERE id > $1 AND expires_at IS NULL AND module_id IS NULL AND created_at < $2
+ORDER BY id ASC
+LIMIT $3
+`
+
+type GetAssetsWithoutExpiryAfterIDParams struct {
+	ID        string
+	CreatedAt pgtype.Timestamp
+	Limit     int32
+}
+
+func (q *Queries) GetAssetsWithoutExpiryAfterID(ctx context.Context, arg GetAssetsWithoutExpiryAfterIDParams) ([]Asset, error) {
+	rows, err := q.db.Query(ctx, getAssetsWithoutExpiryAfterID, arg.ID, arg.CreatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Asset
+	for rows.Next() {
+		var i Asset
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.ContentHash,
+			&i.ContentType,
+			&i.ContentSize,
+			&i.AppID,
+			&i.ModuleID,
+			&i.CreatorUserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getExpiredAssets = `-- name: GetExpiredAssets :many
 SELECT id, name, content_hash, content_type, content_size, app_id, module_id, creator_user_id, created_at, updated_at, expires_at FROM assets WHERE expires_at < $1
 `
@@ -166,3 +211,99 @@ func (q *Queries) GetExpiredAssets(ctx context.Context, expiresAt pgtype.Timesta
 	}
 	return items, nil
 }
+
+const getReferencedAssetIDs = `-- name: GetReferencedAssetIDs :many
+SELECT asset_ids.id::text FROM unnest($1::text[]) AS asset_ids(id)
+WHERE EXISTS (
+    SELECT 1 FROM messages WHERE messages.app_id = $2
+    AND (messages.data::text LIKE '%' || asset_ids.id || '%' OR messages.flow_sources::text LIKE '%' || asset_ids.id || '%')
+) OR EXISTS (
+    SELECT 1 FROM message_instances
+    JOIN messages ON messages.id = message_instances.message_id
+    WHERE messages.app_id = $2 AND message_instances.flow_sources::text LIKE '%' || asset_ids.id || '%'
+) OR EXISTS (
+    SELECT 1 FROM commands WHERE commands.app_id = $2 AND commands.flow_source::text LIKE '%' || asset_ids.id || '%'
+) OR EXISTS (
+    SELECT 1 FROM event_listeners WHERE event_listeners.app_id = $2 AND event_listeners.flow_source::text LIKE '%' || asset_ids.id || '%'
+) OR EXISTS (
+    SELECT 1 FROM modules WHERE modules.app_id = $2 AND modules.resources::text LIKE '%' || asset_ids.id || '%'
+) OR EXISTS (
+    SELECT 1 FROM plugin_instances WHERE plugin_instances.app_id = $2 AND plugin_instances.config::text LIKE '%' || asset_ids.id || '%'
+) OR EXISTS (
+    SELECT 1 FROM plugin_values
+    JOIN plugin_instances ON plugin_instances.id = plugin_values.plugin_instance_id
+    WHERE plugin_instances.app_id = $2 AND plugin_values.value::text LIKE '%' || asset_ids.id || '%'
+) OR EXISTS (
+    SELECT 1 FROM variables
+    WHERE (variables.app_id = $2 OR variables.namespace_id IN (SELECT namespace_id FROM namespace_grants WHERE namespace_grants.app_id = $2))
+    AND variables.default_value::text LIKE '%' || asset_ids.id || '%'
+) OR EXISTS (
+    SELECT 1 FROM variable_values
+    JOIN variables ON variables.id = variable_values.variable_id
+    WHERE (variables.app_id = $2 OR variables.namespace_id IN (SELECT namespace_id FROM namespace_grants WHERE namespace_grants.app_id = $2))
+    AND variable_values.value::text LIKE '%' || asset_ids.id || '%'
+)
+`
+
+type GetReferencedAssetIDsParams struct {
+	AssetIds []string
+	AppID    string
+}
+
+func (q *Queries) GetReferencedAssetIDs(ctx context.Context, arg GetReferencedAssetIDsParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, getReferencedAssetIDs, arg.AssetIds, arg.AppID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var asset_ids_id string
+		if err := rows.Scan(&asset_ids_id); err != nil {
+			return nil, err
+		}
+		items = append(items, asset_ids_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTemporaryAssetByContentHash = `-- name: GetTemporaryAssetByContentHash :one
+SELECT id, name, content_hash, content_type, content_size, app_id, module_id, creator_user_id, created_at, updated_at, expires_at FROM assets
+WHERE app_id = $1 AND content_hash = $2 AND name = $3 AND expires_at > $4
+ORDER BY expires_at DESC
+LIMIT 1
+`
+
+type GetTemporaryAssetByContentHashParams struct {
+	AppID       string
+	ContentHash string
+	Name        string
+	ExpiresAt   pgtype.Timestamp
+}
+
+func (q *Queries) GetTemporaryAssetByContentHash(ctx context.Context, arg GetTemporaryAssetByContentHashParams) (Asset, error) {
+	row := q.db.QueryRow(ctx, getTemporaryAssetByContentHash,
+		arg.AppID,
+		arg.ContentHash,
+		arg.Name,
+		arg.ExpiresAt,
+	)
+	var i Asset
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ContentHash,
+		&i.ContentType,
+		&i.ContentSize,
+		&i.AppID,
+		&i.ModuleID,
+		&i.CreatorUserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
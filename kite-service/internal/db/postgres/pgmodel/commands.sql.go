@@ -240,7 +240,8 @@ UPDATE commands SET
     description = $3,
     enabled = $4,
     flow_source = $5,
-    updated_at = $6
+    module_id = $6,
+    updated_at = $7
 WHERE id = $1 RETURNING id, name, description, enabled, app_id, module_id, creator_user_id, flow_source, created_at, updated_at, last_deployed_at
 `
 
@@ -250,6 +251,7 @@ type UpdateCommandParams struct {
 	Description string
 	Enabled     bool
 	FlowSource  []byte
+	ModuleID    pgtype.Text
 	UpdatedAt   pgtype.Timestamp
 }
 
@@ -260,6 +262,7 @@ func (q *Queries) UpdateCommand(ctx context.Context, arg UpdateCommandParams) (C
 		arg.Description,
 		arg.Enabled,
 		arg.FlowSource,
+		arg.ModuleID,
 		arg.UpdatedAt,
 	)
 	var i Command
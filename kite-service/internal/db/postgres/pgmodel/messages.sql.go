@@ -146,6 +146,18 @@ func (q *Queries) DeleteMessage(ctx context.Context, id string) error {
 	return err
 }
 
+const deleteEphemeralMessageInstancesBefore = `-- name: DeleteEphemeralMessageInstancesBefore :execrows
+DELETE FROM message_instances WHERE ephemeral AND created_at < $1
+`
+
+func (q *Queries) DeleteEphemeralMessageInstancesBefore(ctx context.Context, createdAt pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteEphemeralMessageInstancesBefore, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteMessageInstance = `-- name: DeleteMessageInstance :exec
 DELETE FROM message_instances WHERE id = $1 AND message_id = $2
 `
@@ -240,6 +252,46 @@ func (q *Queries) GetMessageInstanceByDiscordMessageId(ctx context.Context, disc
 	return i, err
 }
 
+const getMessageInstancesAfterID = `-- name: GetMessageInstancesAfterID :many
+SELECT id, message_id, hidden, ephemeral, discord_guild_id, discord_channel_id, discord_message_id, flow_sources, created_at, updated_at FROM message_instances WHERE id > $1 AND NOT ephemeral ORDER BY id ASC LIMIT $2
+`
+
+type GetMessageInstancesAfterIDParams struct {
+	ID    int64
+	Limit int32
+}
+
+func (q *Queries) GetMessageInstancesAfterID(ctx context.Context, arg GetMessageInstancesAfterIDParams) ([]MessageInstance, error) {
+	rows, err := q.db.Query(ctx, getMessageInstancesAfterID, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MessageInstance
+	for rows.Next() {
+		var i MessageInstance
+		if err := rows.Scan(
+			&i.ID,
+			&i.MessageID,
+			&i.Hidden,
+			&i.Ephemeral,
+			&i.DiscordGuildID,
+			&i.DiscordChannelID,
+			&i.DiscordMessageID,
+			&i.FlowSources,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getMessageInstancesByMessage = `-- name: GetMessageInstancesByMessage :many
 SELECT id, message_id, hidden, ephemeral, discord_guild_id, discord_channel_id, discord_message_id, flow_sources, created_at, updated_at FROM message_instances WHERE message_id = $1 AND NOT hidden ORDER BY created_at DESC
 `
@@ -59,6 +59,13 @@ type Command struct {
 	LastDeployedAt pgtype.Timestamp
 }
 
+type Cooldown struct {
+	Key       string
+	AppID     string
+	Count     int32
+	ExpiresAt pgtype.Timestamp
+}
+
 type Entitlement struct {
 	ID             string
 	Type           string
@@ -122,6 +129,28 @@ type MessageInstance struct {
 	UpdatedAt        pgtype.Timestamp
 }
 
+type MessageSyncJob struct {
+	ID          string
+	AppID       string
+	MessageID   string
+	Status      string
+	Error       pgtype.Text
+	CreatedAt   pgtype.Timestamp
+	UpdatedAt   pgtype.Timestamp
+	StartedAt   pgtype.Timestamp
+	CompletedAt pgtype.Timestamp
+}
+
+type MessageSyncJobResult struct {
+	JobID            string
+	InstanceID       int64
+	DiscordGuildID   string
+	DiscordChannelID string
+	Status           string
+	Error            pgtype.Text
+	CreatedAt        pgtype.Timestamp
+}
+
 type Module struct {
 	ID            string
 	Name          string
@@ -132,6 +161,32 @@ type Module struct {
 	Resources     []byte
 	CreatedAt     pgtype.Timestamp
 	UpdatedAt     pgtype.Timestamp
+	WasmBytes     []byte
+	WasmHash      string
+}
+
+type Namespace struct {
+	ID          string
+	Name        string
+	Description pgtype.Text
+	OwnerUserID string
+	CreatedAt   pgtype.Timestamp
+	UpdatedAt   pgtype.Timestamp
+}
+
+type NamespaceGrant struct {
+	NamespaceID string
+	AppID       string
+	Access      string
+	CreatedAt   pgtype.Timestamp
+	UpdatedAt   pgtype.Timestamp
+}
+
+type NamespaceMember struct {
+	NamespaceID string
+	UserID      string
+	CreatedAt   pgtype.Timestamp
+	UpdatedAt   pgtype.Timestamp
 }
 
 type PluginInstance struct {
@@ -154,6 +209,7 @@ type PluginValue struct {
 	Value            []byte
 	CreatedAt        pgtype.Timestamp
 	UpdatedAt        pgtype.Timestamp
+	ExpiresAt        pgtype.Timestamp
 }
 
 type ResumePoint struct {
@@ -220,13 +276,18 @@ type User struct {
 }
 
 type Variable struct {
-	ID        string
-	Name      string
-	Scoped    bool
-	AppID     string
-	ModuleID  pgtype.Text
-	CreatedAt pgtype.Timestamp
-	UpdatedAt pgtype.Timestamp
+	ID              string
+	Name            string
+	Scoped          bool
+	AppID           pgtype.Text
+	ModuleID        pgtype.Text
+	CreatedAt       pgtype.Timestamp
+	UpdatedAt       pgtype.Timestamp
+	Type            string
+	Schema          []byte
+	DefaultValue    []byte
+	ValueTtlSeconds int32
+	NamespaceID     pgtype.Text
 }
 
 type VariableValue struct {
@@ -236,4 +297,5 @@ type VariableValue struct {
 	Value      []byte
 	CreatedAt  pgtype.Timestamp
 	UpdatedAt  pgtype.Timestamp
+	ExpiresAt  pgtype.Timestamp
 }
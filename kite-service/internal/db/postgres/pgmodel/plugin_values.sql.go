@@ -11,6 +11,44 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countPluginValuesByKeyPrefixAbove = `-- name: CountPluginValuesByKeyPrefixAbove :one
+SELECT COUNT(*) FROM plugin_values
+WHERE plugin_instance_id = $1 AND starts_with(key, $2::text)
+AND (expires_at IS NULL OR expires_at > $3)
+AND CASE WHEN jsonb_typeof(value->'v') = 'number' THEN (value->'v')::float8 END > $4::float8
+`
+
+type CountPluginValuesByKeyPrefixAboveParams struct {
+	PluginInstanceID string
+	KeyPrefix        string
+	Now              pgtype.Timestamp
+	Value            float64
+}
+
+func (q *Queries) CountPluginValuesByKeyPrefixAbove(ctx context.Context, arg CountPluginValuesByKeyPrefixAboveParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countPluginValuesByKeyPrefixAbove,
+		arg.PluginInstanceID,
+		arg.KeyPrefix,
+		arg.Now,
+		arg.Value,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteExpiredPluginValues = `-- name: DeleteExpiredPluginValues :execrows
+DELETE FROM plugin_values WHERE expires_at < $1
+`
+
+func (q *Queries) DeleteExpiredPluginValues(ctx context.Context, expiresAt pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredPluginValues, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deletePluginValue = `-- name: DeletePluginValue :exec
 DELETE FROM plugin_values WHERE plugin_instance_id = $1 AND key = $2
 `
@@ -26,16 +64,19 @@ func (q *Queries) DeletePluginValue(ctx context.Context, arg DeletePluginValuePa
 }
 
 const getPluginValue = `-- name: GetPluginValue :one
-SELECT id, plugin_instance_id, key, value, created_at, updated_at FROM plugin_values WHERE plugin_instance_id = $1 AND key = $2
+SELECT id, plugin_instance_id, key, value, created_at, updated_at, expires_at FROM plugin_values
+WHERE plugin_instance_id = $1 AND key = $2
+AND (expires_at IS NULL OR expires_at > $3)
 `
 
 type GetPluginValueParams struct {
 	PluginInstanceID string
 	Key              string
+	Now              pgtype.Timestamp
 }
 
 func (q *Queries) GetPluginValue(ctx context.Context, arg GetPluginValueParams) (PluginValue, error) {
-	row := q.db.QueryRow(ctx, getPluginValue, arg.PluginInstanceID, arg.Key)
+	row := q.db.QueryRow(ctx, getPluginValue, arg.PluginInstanceID, arg.Key, arg.Now)
 	var i PluginValue
 	err := row.Scan(
 		&i.ID,
@@ -44,12 +85,13 @@ func (q *Queries) GetPluginValue(ctx context.Context, arg GetPluginValueParams)
 		&i.Value,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ExpiresAt,
 	)
 	return i, err
 }
 
 const getPluginValueForUpdate = `-- name: GetPluginValueForUpdate :one
-SELECT id, plugin_instance_id, key, value, created_at, updated_at FROM plugin_values WHERE plugin_instance_id = $1 AND key = $2 FOR UPDATE
+SELECT id, plugin_instance_id, key, value, created_at, updated_at, expires_at FROM plugin_values WHERE plugin_instance_id = $1 AND key = $2 FOR UPDATE
 `
 
 type GetPluginValueForUpdateParams struct {
@@ -67,23 +109,160 @@ func (q *Queries) GetPluginValueForUpdate(ctx context.Context, arg GetPluginValu
 		&i.Value,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ExpiresAt,
 	)
 	return i, err
 }
 
+const getPluginValuesByKeyPrefix = `-- name: GetPluginValuesByKeyPrefix :many
+SELECT id, plugin_instance_id, key, value, created_at, updated_at, expires_at FROM plugin_values
+WHERE plugin_instance_id = $1 AND starts_with(key, $2::text)
+AND (expires_at IS NULL OR expires_at > $3)
+ORDER BY CASE WHEN jsonb_typeof(value->'v') = 'number' THEN (value->'v')::float8 END DESC NULLS LAST, key ASC
+LIMIT $4 OFFSET $5
+`
+
+type GetPluginValuesByKeyPrefixParams struct {
+	PluginInstanceID string
+	KeyPrefix        string
+	Now              pgtype.Timestamp
+	LimitCount       int32
+	OffsetCount      int32
+}
+
+func (q *Queries) GetPluginValuesByKeyPrefix(ctx context.Context, arg GetPluginValuesByKeyPrefixParams) ([]PluginValue, error) {
+	rows, err := q.db.Query(ctx, getPluginValuesByKeyPrefix,
+		arg.PluginInstanceID,
+		arg.KeyPrefix,
+		arg.Now,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PluginValue
+	for rows.Next() {
+		var i PluginValue
+		if err := rows.Scan(
+			&i.ID,
+			&i.PluginInstanceID,
+			&i.Key,
+			&i.Value,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPluginValuesByKeys = `-- name: GetPluginValuesByKeys :many
+SELECT id, plugin_instance_id, key, value, created_at, updated_at, expires_at FROM plugin_values
+WHERE plugin_instance_id = $1 AND key = ANY($2::text[])
+AND (expires_at IS NULL OR expires_at > $3)
+`
+
+type GetPluginValuesByKeysParams struct {
+	PluginInstanceID string
+	Keys             []string
+	Now              pgtype.Timestamp
+}
+
+func (q *Queries) GetPluginValuesByKeys(ctx context.Context, arg GetPluginValuesByKeysParams) ([]PluginValue, error) {
+	rows, err := q.db.Query(ctx, getPluginValuesByKeys, arg.PluginInstanceID, arg.Keys, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PluginValue
+	for rows.Next() {
+		var i PluginValue
+		if err := rows.Scan(
+			&i.ID,
+			&i.PluginInstanceID,
+			&i.Key,
+			&i.Value,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertPluginValueIfNotExists = `-- name: InsertPluginValueIfNotExists :execrows
+INSERT INTO plugin_values (
+    plugin_instance_id,
+    key,
+    value,
+    created_at,
+    updated_at,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) ON CONFLICT (plugin_instance_id, key) DO UPDATE SET
+    value = EXCLUDED.value,
+    created_at = EXCLUDED.created_at,
+    updated_at = EXCLUDED.updated_at,
+    expires_at = EXCLUDED.expires_at
+WHERE plugin_values.expires_at IS NOT NULL AND plugin_values.expires_at <= $7
+`
+
+type InsertPluginValueIfNotExistsParams struct {
+	PluginInstanceID string
+	Key              string
+	Value            []byte
+	CreatedAt        pgtype.Timestamp
+	UpdatedAt        pgtype.Timestamp
+	ExpiresAt        pgtype.Timestamp
+	Now              pgtype.Timestamp
+}
+
+func (q *Queries) InsertPluginValueIfNotExists(ctx context.Context, arg InsertPluginValueIfNotExistsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, insertPluginValueIfNotExists,
+		arg.PluginInstanceID,
+		arg.Key,
+		arg.Value,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.ExpiresAt,
+		arg.Now,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const setPluginValue = `-- name: SetPluginValue :one
 INSERT INTO plugin_values (
     plugin_instance_id,
     key,
     value,
     created_at,
-    updated_at
+    updated_at,
+    expires_at
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6
 ) ON CONFLICT (plugin_instance_id, key) DO UPDATE SET
     value = EXCLUDED.value,
-    updated_at = EXCLUDED.updated_at
-RETURNING id, plugin_instance_id, key, value, created_at, updated_at
+    updated_at = EXCLUDED.updated_at,
+    expires_at = EXCLUDED.expires_at
+RETURNING id, plugin_instance_id, key, value, created_at, updated_at, expires_at
 `
 
 type SetPluginValueParams struct {
@@ -92,6 +271,7 @@ type SetPluginValueParams struct {
 	Value            []byte
 	CreatedAt        pgtype.Timestamp
 	UpdatedAt        pgtype.Timestamp
+	ExpiresAt        pgtype.Timestamp
 }
 
 func (q *Queries) SetPluginValue(ctx context.Context, arg SetPluginValueParams) (PluginValue, error) {
@@ -101,6 +281,7 @@ func (q *Queries) SetPluginValue(ctx context.Context, arg SetPluginValueParams)
 		arg.Value,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		arg.ExpiresAt,
 	)
 	var i PluginValue
 	err := row.Scan(
@@ -110,6 +291,42 @@ func (q *Queries) SetPluginValue(ctx context.Context, arg SetPluginValueParams)
 		&i.Value,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ExpiresAt,
 	)
 	return i, err
 }
+
+const swapPluginValue = `-- name: SwapPluginValue :execrows
+UPDATE plugin_values SET
+    value = $1,
+    updated_at = $2,
+    expires_at = $3
+WHERE plugin_instance_id = $4 AND key = $5 AND value = $6::jsonb
+AND (expires_at IS NULL OR expires_at > $7)
+`
+
+type SwapPluginValueParams struct {
+	Value            []byte
+	UpdatedAt        pgtype.Timestamp
+	ExpiresAt        pgtype.Timestamp
+	PluginInstanceID string
+	Key              string
+	OldValue         []byte
+	Now              pgtype.Timestamp
+}
+
+func (q *Queries) SwapPluginValue(ctx context.Context, arg SwapPluginValueParams) (int64, error) {
+	result, err := q.db.Exec(ctx, swapPluginValue,
+		arg.Value,
+		arg.UpdatedAt,
+		arg.ExpiresAt,
+		arg.PluginInstanceID,
+		arg.Key,
+		arg.OldValue,
+		arg.Now,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
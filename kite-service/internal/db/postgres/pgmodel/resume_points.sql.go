@@ -62,13 +62,16 @@ func (q *Queries) CreateResumePoint(ctx context.Context, arg CreateResumePointPa
 	return err
 }
 
-const deleteExpiredResumePoints = `-- name: DeleteExpiredResumePoints :exec
+const deleteExpiredResumePoints = `-- name: DeleteExpiredResumePoints :execrows
 DELETE FROM resume_points WHERE expires_at < $1
 `
 
-func (q *Queries) DeleteExpiredResumePoints(ctx context.Context, expiresAt pgtype.Timestamp) error {
-	_, err := q.db.Exec(ctx, deleteExpiredResumePoints, expiresAt)
-	return err
+func (q *Queries) DeleteExpiredResumePoints(ctx context.Context, expiresAt pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredResumePoints, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const deleteResumePoint = `-- name: DeleteResumePoint :exec
@@ -80,6 +83,23 @@ func (q *Queries) DeleteResumePoint(ctx context.Context, id string) error {
 	return err
 }
 
+const deleteResumePointsByTypeCreatedBefore = `-- name: DeleteResumePointsByTypeCreatedBefore :execrows
+DELETE FROM resume_points WHERE type = $1 AND created_at < $2
+`
+
+type DeleteResumePointsByTypeCreatedBeforeParams struct {
+	Type      string
+	CreatedAt pgtype.Timestamp
+}
+
+func (q *Queries) DeleteResumePointsByTypeCreatedBefore(ctx context.Context, arg DeleteResumePointsByTypeCreatedBeforeParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteResumePointsByTypeCreatedBefore, arg.Type, arg.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const resumePoint = `-- name: ResumePoint :one
 SELECT id, type, app_id, command_id, event_listener_id, message_id, message_instance_id, flow_source_id, flow_node_id, flow_state, created_at, expires_at FROM resume_points WHERE id = $1
 `
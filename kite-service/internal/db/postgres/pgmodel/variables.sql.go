@@ -11,11 +11,50 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const aggregateVariableValues = `-- name: AggregateVariableValues :one
+SELECT
+    COUNT(*) AS total_count,
+    COALESCE(SUM(CASE WHEN jsonb_typeof(value->'v') = 'number' THEN (value->'v')::float8 END), 0)::float8 AS total_sum,
+    AVG(CASE WHEN jsonb_typeof(value->'v') = 'number' THEN (value->'v')::float8 END)::float8 AS average,
+    MIN(CASE WHEN jsonb_typeof(value->'v') = 'number' THEN (value->'v')::float8 END)::float8 AS min_value,
+    MAX(CASE WHEN jsonb_typeof(value->'v') = 'number' THEN (value->'v')::float8 END)::float8 AS max_value
+FROM variable_values
+WHERE variable_id = $1 AND scope IS NOT NULL AND starts_with(scope, $2::text)
+AND (expires_at IS NULL OR expires_at > $3)
+`
+
+type AggregateVariableValuesParams struct {
+	VariableID  string
+	ScopePrefix string
+	Now         pgtype.Timestamp
+}
+
+type AggregateVariableValuesRow struct {
+	TotalCount int64
+	TotalSum   float64
+	Average    pgtype.Float8
+	MinValue   pgtype.Float8
+	MaxValue   pgtype.Float8
+}
+
+func (q *Queries) AggregateVariableValues(ctx context.Context, arg AggregateVariableValuesParams) (AggregateVariableValuesRow, error) {
+	row := q.db.QueryRow(ctx, aggregateVariableValues, arg.VariableID, arg.ScopePrefix, arg.Now)
+	var i AggregateVariableValuesRow
+	err := row.Scan(
+		&i.TotalCount,
+		&i.TotalSum,
+		&i.Average,
+		&i.MinValue,
+		&i.MaxValue,
+	)
+	return i, err
+}
+
 const countVariablesByApp = `-- name: CountVariablesByApp :one
 SELECT COUNT(*) FROM variables WHERE app_id = $1
 `
 
-func (q *Queries) CountVariablesByApp(ctx context.Context, appID string) (int64, error) {
+func (q *Queries) CountVariablesByApp(ctx context.Context, appID pgtype.Text) (int64, error) {
 	row := q.db.QueryRow(ctx, countVariablesByApp, appID)
 	var count int64
 	err := row.Scan(&count)
@@ -30,20 +69,30 @@ INSERT INTO variables (
     app_id,
     module_id,
     created_at,
-    updated_at
+    updated_at,
+    type,
+    schema,
+    default_value,
+    value_ttl_seconds,
+    namespace_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
-) RETURNING id, name, scoped, app_id, module_id, created_at, updated_at
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+) RETURNING id, name, scoped, app_id, module_id, created_at, updated_at, type, schema, default_value, value_ttl_seconds, namespace_id
 `
 
 type CreateVariableParams struct {
-	ID        string
-	Name      string
-	Scoped    bool
-	AppID     string
-	ModuleID  pgtype.Text
-	CreatedAt pgtype.Timestamp
-	UpdatedAt pgtype.Timestamp
+	ID              string
+	Name            string
+	Scoped          bool
+	AppID           pgtype.Text
+	ModuleID        pgtype.Text
+	CreatedAt       pgtype.Timestamp
+	UpdatedAt       pgtype.Timestamp
+	Type            string
+	Schema          []byte
+	DefaultValue    []byte
+	ValueTtlSeconds int32
+	NamespaceID     pgtype.Text
 }
 
 func (q *Queries) CreateVariable(ctx context.Context, arg CreateVariableParams) (Variable, error) {
@@ -55,6 +104,11 @@ func (q *Queries) CreateVariable(ctx context.Context, arg CreateVariableParams)
 		arg.ModuleID,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		arg.Type,
+		arg.Schema,
+		arg.DefaultValue,
+		arg.ValueTtlSeconds,
+		arg.NamespaceID,
 	)
 	var i Variable
 	err := row.Scan(
@@ -65,6 +119,11 @@ func (q *Queries) CreateVariable(ctx context.Context, arg CreateVariableParams)
 		&i.ModuleID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Type,
+		&i.Schema,
+		&i.DefaultValue,
+		&i.ValueTtlSeconds,
+		&i.NamespaceID,
 	)
 	return i, err
 }
@@ -78,6 +137,18 @@ func (q *Queries) DeleteAllVariableValues(ctx context.Context, variableID string
 	return err
 }
 
+const deleteExpiredVariableValues = `-- name: DeleteExpiredVariableValues :execrows
+DELETE FROM variable_values WHERE expires_at < $1
+`
+
+func (q *Queries) DeleteExpiredVariableValues(ctx context.Context, expiresAt pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredVariableValues, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteVariable = `-- name: DeleteVariable :exec
 DELETE FROM variables WHERE id = $1
 `
@@ -88,7 +159,7 @@ func (q *Queries) DeleteVariable(ctx context.Context, id string) error {
 }
 
 const deleteVariableValue = `-- name: DeleteVariableValue :exec
-DELETE FROM variable_values WHERE variable_id = $1 AND scope = $2
+DELETE FROM variable_values WHERE variable_id = $1 AND scope IS NOT DISTINCT FROM $2
 `
 
 type DeleteVariableValueParams struct {
@@ -102,7 +173,7 @@ func (q *Queries) DeleteVariableValue(ctx context.Context, arg DeleteVariableVal
 }
 
 const getVariable = `-- name: GetVariable :one
-SELECT variables.id, variables.name, variables.scoped, variables.app_id, variables.module_id, variables.created_at, variables.updated_at, COUNT(variable_values.*) as total_values FROM variables 
+SELECT variables.id, variables.name, variables.scoped, variables.app_id, variables.module_id, variables.created_at, variables.updated_at, variables.type, variables.schema, variables.default_value, variables.value_ttl_seconds, variables.namespace_id, COUNT(variable_values.*) as total_values FROM variables 
 LEFT JOIN variable_values ON variables.id = variable_values.variable_id
 WHERE variables.id = $1
 GROUP BY variables.id
@@ -124,20 +195,25 @@ func (q *Queries) GetVariable(ctx context.Context, id string) (GetVariableRow, e
 		&i.Variable.ModuleID,
 		&i.Variable.CreatedAt,
 		&i.Variable.UpdatedAt,
+		&i.Variable.Type,
+		&i.Variable.Schema,
+		&i.Variable.DefaultValue,
+		&i.Variable.ValueTtlSeconds,
+		&i.Variable.NamespaceID,
 		&i.TotalValues,
 	)
 	return i, err
 }
 
 const getVariableByName = `-- name: GetVariableByName :one
-SELECT variables.id, variables.name, variables.scoped, variables.app_id, variables.module_id, variables.created_at, variables.updated_at, COUNT(variable_values.*) as total_values FROM variables 
+SELECT variables.id, variables.name, variables.scoped, variables.app_id, variables.module_id, variables.created_at, variables.updated_at, variables.type, variables.schema, variables.default_value, variables.value_ttl_seconds, variables.namespace_id, COUNT(variable_values.*) as total_values FROM variables 
 LEFT JOIN variable_values ON variables.id = variable_values.variable_id
 WHERE app_id = $1 AND name = $2
 GROUP BY variables.id
 `
 
 type GetVariableByNameParams struct {
-	AppID string
+	AppID pgtype.Text
 	Name  string
 }
 
@@ -157,22 +233,54 @@ func (q *Queries) GetVariableByName(ctx context.Context, arg GetVariableByNamePa
 		&i.Variable.ModuleID,
 		&i.Variable.CreatedAt,
 		&i.Variable.UpdatedAt,
+		&i.Variable.Type,
+		&i.Variable.Schema,
+		&i.Variable.DefaultValue,
+		&i.Variable.ValueTtlSeconds,
+		&i.Variable.NamespaceID,
 		&i.TotalValues,
 	)
 	return i, err
 }
 
+const getVariableDefinition = `-- name: GetVariableDefinition :one
+SELECT id, name, scoped, app_id, module_id, created_at, updated_at, type, schema, default_value, value_ttl_seconds, namespace_id FROM variables WHERE id = $1
+`
+
+func (q *Queries) GetVariableDefinition(ctx context.Context, id string) (Variable, error) {
+	row := q.db.QueryRow(ctx, getVariableDefinition, id)
+	var i Variable
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Scoped,
+		&i.AppID,
+		&i.ModuleID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Type,
+		&i.Schema,
+		&i.DefaultValue,
+		&i.ValueTtlSeconds,
+		&i.NamespaceID,
+	)
+	return i, err
+}
+
 const getVariableValue = `-- name: GetVariableValue :one
-SELECT id, variable_id, scope, value, created_at, updated_at FROM variable_values WHERE variable_id = $1 AND scope IS NOT DISTINCT FROM $2
+SELECT id, variable_id, scope, value, created_at, updated_at, expires_at FROM variable_values
+WHERE variable_id = $1 AND scope IS NOT DISTINCT FROM $2
+AND (expires_at IS NULL OR expires_at > $3)
 `
 
 type GetVariableValueParams struct {
 	VariableID string
 	Scope      pgtype.Text
+	Now        pgtype.Timestamp
 }
 
 func (q *Queries) GetVariableValue(ctx context.Context, arg GetVariableValueParams) (VariableValue, error) {
-	row := q.db.QueryRow(ctx, getVariableValue, arg.VariableID, arg.Scope)
+	row := q.db.QueryRow(ctx, getVariableValue, arg.VariableID, arg.Scope, arg.Now)
 	var i VariableValue
 	err := row.Scan(
 		&i.ID,
@@ -181,12 +289,13 @@ func (q *Queries) GetVariableValue(ctx context.Context, arg GetVariableValuePara
 		&i.Value,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ExpiresAt,
 	)
 	return i, err
 }
 
 const getVariableValueForUpdate = `-- name: GetVariableValueForUpdate :one
-SELECT id, variable_id, scope, value, created_at, updated_at FROM variable_values WHERE variable_id = $1 AND scope IS NOT DISTINCT FROM $2 FOR UPDATE
+SELECT id, variable_id, scope, value, created_at, updated_at, expires_at FROM variable_values WHERE variable_id = $1 AND scope IS NOT DISTINCT FROM $2 FOR UPDATE
 `
 
 type GetVariableValueForUpdateParams struct {
@@ -204,16 +313,173 @@ func (q *Queries) GetVariableValueForUpdate(ctx context.Context, arg GetVariable
 		&i.Value,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ExpiresAt,
 	)
 	return i, err
 }
 
 const getVariableValues = `-- name: GetVariableValues :many
-SELECT id, variable_id, scope, value, created_at, updated_at FROM variable_values WHERE variable_id = $1
+SELECT id, variable_id, scope, value, created_at, updated_at, expires_at FROM variable_values
+WHERE variable_id = $1 AND (expires_at IS NULL OR expires_at > $2)
+`
+
+type GetVariableValuesParams struct {
+	VariableID string
+	Now        pgtype.Timestamp
+}
+
+func (q *Queries) GetVariableValues(ctx context.Context, arg GetVariableValuesParams) ([]VariableValue, error) {
+	rows, err := q.db.Query(ctx, getVariableValues, arg.VariableID, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []VariableValue
+	for rows.Next() {
+		var i VariableValue
+		if err := rows.Scan(
+			&i.ID,
+			&i.VariableID,
+			&i.Scope,
+			&i.Value,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getVariableValuesByNumber = `-- name: GetVariableValuesByNumber :many
+SELECT id, variable_id, scope, value, created_at, updated_at, expires_at FROM variable_values
+WHERE variable_id = $1 AND scope IS NOT NULL AND starts_with(scope, $2::text)
+AND (expires_at IS NULL OR expires_at > $3)
+ORDER BY CASE WHEN jsonb_typeof(value->'v') = 'number' THEN (value->'v')::float8 END DESC NULLS LAST, scope ASC
+LIMIT $4 OFFSET $5
+`
+
+type GetVariableValuesByNumberParams struct {
+	VariableID  string
+	ScopePrefix string
+	Now         pgtype.Timestamp
+	LimitCount  int32
+	OffsetCount int32
+}
+
+func (q *Queries) GetVariableValuesByNumber(ctx context.Context, arg GetVariableValuesByNumberParams) ([]VariableValue, error) {
+	rows, err := q.db.Query(ctx, getVariableValuesByNumber,
+		arg.VariableID,
+		arg.ScopePrefix,
+		arg.Now,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []VariableValue
+	for rows.Next() {
+		var i VariableValue
+		if err := rows.Scan(
+			&i.ID,
+			&i.VariableID,
+			&i.Scope,
+			&i.Value,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getVariableValuesByNumberAsc = `-- name: GetVariableValuesByNumberAsc :many
+SELECT id, variable_id, scope, value, created_at, updated_at, expires_at FROM variable_values
+WHERE variable_id = $1 AND scope IS NOT NULL AND starts_with(scope, $2::text)
+AND (expires_at IS NULL OR expires_at > $3)
+ORDER BY CASE WHEN jsonb_typeof(value->'v') = 'number' THEN (value->'v')::float8 END ASC NULLS LAST, scope ASC
+LIMIT $4 OFFSET $5
+`
+
+type GetVariableValuesByNumberAscParams struct {
+	VariableID  string
+	ScopePrefix string
+	Now         pgtype.Timestamp
+	LimitCount  int32
+	OffsetCount int32
+}
+
+func (q *Queries) GetVariableValuesByNumberAsc(ctx context.Context, arg GetVariableValuesByNumberAscParams) ([]VariableValue, error) {
+	rows, err := q.db.Query(ctx, getVariableValuesByNumberAsc,
+		arg.VariableID,
+		arg.ScopePrefix,
+		arg.Now,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []VariableValue
+	for rows.Next() {
+		var i VariableValue
+		if err := rows.Scan(
+			&i.ID,
+			&i.VariableID,
+			&i.Scope,
+			&i.Value,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getVariableValuesByScope = `-- name: GetVariableValuesByScope :many
+SELECT id, variable_id, scope, value, created_at, updated_at, expires_at FROM variable_values
+WHERE variable_id = $1 AND scope IS NOT NULL AND starts_with(scope, $2::text)
+AND (expires_at IS NULL OR expires_at > $3)
+ORDER BY scope ASC
+LIMIT $4 OFFSET $5
 `
 
-func (q *Queries) GetVariableValues(ctx context.Context, variableID string) ([]VariableValue, error) {
-	rows, err := q.db.Query(ctx, getVariableValues, variableID)
+type GetVariableValuesByScopeParams struct {
+	VariableID  string
+	ScopePrefix string
+	Now         pgtype.Timestamp
+	LimitCount  int32
+	OffsetCount int32
+}
+
+func (q *Queries) GetVariableValuesByScope(ctx context.Context, arg GetVariableValuesByScopeParams) ([]VariableValue, error) {
+	rows, err := q.db.Query(ctx, getVariableValuesByScope,
+		arg.VariableID,
+		arg.ScopePrefix,
+		arg.Now,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -228,6 +494,57 @@ func (q *Queries) GetVariableValues(ctx context.Context, variableID string) ([]V
 			&i.Value,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getVariableValuesByScopeDesc = `-- name: GetVariableValuesByScopeDesc :many
+SELECT id, variable_id, scope, value, created_at, updated_at, expires_at FROM variable_values
+WHERE variable_id = $1 AND scope IS NOT NULL AND starts_with(scope, $2::text)
+AND (expires_at IS NULL OR expires_at > $3)
+ORDER BY scope DESC
+LIMIT $4 OFFSET $5
+`
+
+type GetVariableValuesByScopeDescParams struct {
+	VariableID  string
+	ScopePrefix string
+	Now         pgtype.Timestamp
+	LimitCount  int32
+	OffsetCount int32
+}
+
+func (q *Queries) GetVariableValuesByScopeDesc(ctx context.Context, arg GetVariableValuesByScopeDescParams) ([]VariableValue, error) {
+	rows, err := q.db.Query(ctx, getVariableValuesByScopeDesc,
+		arg.VariableID,
+		arg.ScopePrefix,
+		arg.Now,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []VariableValue
+	for rows.Next() {
+		var i VariableValue
+		if err := rows.Scan(
+			&i.ID,
+			&i.VariableID,
+			&i.Scope,
+			&i.Value,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExpiresAt,
 		); err != nil {
 			return nil, err
 		}
@@ -240,7 +557,7 @@ func (q *Queries) GetVariableValues(ctx context.Context, variableID string) ([]V
 }
 
 const getVariablesByApp = `-- name: GetVariablesByApp :many
-SELECT variables.id, variables.name, variables.scoped, variables.app_id, variables.module_id, variables.created_at, variables.updated_at, COUNT(variable_values.*) as total_values FROM variables 
+SELECT variables.id, variables.name, variables.scoped, variables.app_id, variables.module_id, variables.created_at, variables.updated_at, variables.type, variables.schema, variables.default_value, variables.value_ttl_seconds, variables.namespace_id, COUNT(variable_values.*) as total_values FROM variables 
 LEFT JOIN variable_values ON variables.id = variable_values.variable_id
 WHERE variables.app_id = $1 
 GROUP BY variables.id
@@ -252,7 +569,7 @@ type GetVariablesByAppRow struct {
 	TotalValues int64
 }
 
-func (q *Queries) GetVariablesByApp(ctx context.Context, appID string) ([]GetVariablesByAppRow, error) {
+func (q *Queries) GetVariablesByApp(ctx context.Context, appID pgtype.Text) ([]GetVariablesByAppRow, error) {
 	rows, err := q.db.Query(ctx, getVariablesByApp, appID)
 	if err != nil {
 		return nil, err
@@ -269,6 +586,58 @@ func (q *Queries) GetVariablesByApp(ctx context.Context, appID string) ([]GetVar
 			&i.Variable.ModuleID,
 			&i.Variable.CreatedAt,
 			&i.Variable.UpdatedAt,
+			&i.Variable.Type,
+			&i.Variable.Schema,
+			&i.Variable.DefaultValue,
+			&i.Variable.ValueTtlSeconds,
+			&i.Variable.NamespaceID,
+			&i.TotalValues,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getVariablesByNamespace = `-- name: GetVariablesByNamespace :many
+SELECT variables.id, variables.name, variables.scoped, variables.app_id, variables.module_id, variables.created_at, variables.updated_at, variables.type, variables.schema, variables.default_value, variables.value_ttl_seconds, variables.namespace_id, COUNT(variable_values.*) as total_values FROM variables 
+LEFT JOIN variable_values ON variables.id = variable_values.variable_id
+WHERE variables.namespace_id = $1 
+GROUP BY variables.id
+ORDER BY variables.created_at DESC
+`
+
+type GetVariablesByNamespaceRow struct {
+	Variable    Variable
+	TotalValues int64
+}
+
+func (q *Queries) GetVariablesByNamespace(ctx context.Context, namespaceID pgtype.Text) ([]GetVariablesByNamespaceRow, error) {
+	rows, err := q.db.Query(ctx, getVariablesByNamespace, namespaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetVariablesByNamespaceRow
+	for rows.Next() {
+		var i GetVariablesByNamespaceRow
+		if err := rows.Scan(
+			&i.Variable.ID,
+			&i.Variable.Name,
+			&i.Variable.Scoped,
+			&i.Variable.AppID,
+			&i.Variable.ModuleID,
+			&i.Variable.CreatedAt,
+			&i.Variable.UpdatedAt,
+			&i.Variable.Type,
+			&i.Variable.Schema,
+			&i.Variable.DefaultValue,
+			&i.Variable.ValueTtlSeconds,
+			&i.Variable.NamespaceID,
 			&i.TotalValues,
 		); err != nil {
 			return nil, err
@@ -281,19 +650,65 @@ func (q *Queries) GetVariablesByApp(ctx context.Context, appID string) ([]GetVar
 	return items, nil
 }
 
+const insertVariableValueIfNotExists = `-- name: InsertVariableValueIfNotExists :execrows
+INSERT INTO variable_values (
+    variable_id,
+    scope,
+    value,
+    created_at,
+    updated_at,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) ON CONFLICT (variable_id, scope) DO UPDATE SET
+    value = EXCLUDED.value,
+    created_at = EXCLUDED.created_at,
+    updated_at = EXCLUDED.updated_at,
+    expires_at = EXCLUDED.expires_at
+WHERE variable_values.expires_at IS NOT NULL AND variable_values.expires_at <= $7
+`
+
+type InsertVariableValueIfNotExistsParams struct {
+	VariableID string
+	Scope      pgtype.Text
+	Value      []byte
+	CreatedAt  pgtype.Timestamp
+	UpdatedAt  pgtype.Timestamp
+	ExpiresAt  pgtype.Timestamp
+	Now        pgtype.Timestamp
+}
+
+func (q *Queries) InsertVariableValueIfNotExists(ctx context.Context, arg InsertVariableValueIfNotExistsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, insertVariableValueIfNotExists,
+		arg.VariableID,
+		arg.Scope,
+		arg.Value,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.ExpiresAt,
+		arg.Now,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const setVariableValue = `-- name: SetVariableValue :one
 INSERT INTO variable_values (
     variable_id,
     scope,
     value,
     created_at,
-    updated_at
+    updated_at,
+    expires_at
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6
 ) ON CONFLICT (variable_id, scope) DO UPDATE SET
     value = EXCLUDED.value,
-    updated_at = EXCLUDED.updated_at
-RETURNING id, variable_id, scope, value, created_at, updated_at
+    updated_at = EXCLUDED.updated_at,
+    expires_at = EXCLUDED.expires_at
+RETURNING id, variable_id, scope, value, created_at, updated_at, expires_at
 `
 
 type SetVariableValueParams struct {
@@ -302,6 +717,7 @@ type SetVariableValueParams struct {
 	Value      []byte
 	CreatedAt  pgtype.Timestamp
 	UpdatedAt  pgtype.Timestamp
+	ExpiresAt  pgtype.Timestamp
 }
 
 func (q *Queries) SetVariableValue(ctx context.Context, arg SetVariableValueParams) (VariableValue, error) {
@@ -311,6 +727,7 @@ func (q *Queries) SetVariableValue(ctx context.Context, arg SetVariableValuePara
 		arg.Value,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		arg.ExpiresAt,
 	)
 	var i VariableValue
 	err := row.Scan(
@@ -320,6 +737,7 @@ func (q *Queries) SetVariableValue(ctx context.Context, arg SetVariableValuePara
 		&i.Value,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ExpiresAt,
 	)
 	return i, err
 }
@@ -328,15 +746,23 @@ const updateVariable = `-- name: UpdateVariable :one
 UPDATE variables SET
     name = $2,
     scoped = $3,
-    updated_at = $4
-WHERE id = $1 RETURNING id, name, scoped, app_id, module_id, created_at, updated_at
+    updated_at = $4,
+    type = $5,
+    schema = $6,
+    default_value = $7,
+    value_ttl_seconds = $8
+WHERE id = $1 RETURNING id, name, scoped, app_id, module_id, created_at, updated_at, type, schema, default_value, value_ttl_seconds, namespace_id
 `
 
 type UpdateVariableParams struct {
-	ID        string
-	Name      string
-	Scoped    bool
-	UpdatedAt pgtype.Timestamp
+	ID              string
+	Name            string
+	Scoped          bool
+	UpdatedAt       pgtype.Timestamp
+	Type            string
+	Schema          []byte
+	DefaultValue    []byte
+	ValueTtlSeconds int32
 }
 
 func (q *Queries) UpdateVariable(ctx context.Context, arg UpdateVariableParams) (Variable, error) {
@@ -345,6 +771,10 @@ func (q *Queries) UpdateVariable(ctx context.Context, arg UpdateVariableParams)
 		arg.Name,
 		arg.Scoped,
 		arg.UpdatedAt,
+		arg.Type,
+		arg.Schema,
+		arg.DefaultValue,
+		arg.ValueTtlSeconds,
 	)
 	var i Variable
 	err := row.Scan(
@@ -355,6 +785,11 @@ func (q *Queries) UpdateVariable(ctx context.Context, arg UpdateVariableParams)
 		&i.ModuleID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Type,
+		&i.Schema,
+		&i.DefaultValue,
+		&i.ValueTtlSeconds,
+		&i.NamespaceID,
 	)
 	return i, err
 }
@@ -12,8 +12,7 @@ SELECT * FROM apps WHERE owner_user_id = $1 ORDER BY created_at DESC;
 
 -- name: GetAppsByCollaborator :many
 SELECT a.* FROM apps a
-LEFT JOIN collaborators c ON a.id = c.app_id
-WHERE a.owner_user_id = @user_id OR c.user_id = @user_id
+WHERE a.owner_user_id = @user_id OR a.id IN (SELECT app_id FROM collaborators WHERE user_id = @user_id)
 ORDER BY a.created_at DESC;
 
 -- name: CountAppsByOwner :one
@@ -25,4 +25,48 @@ DELETE FROM assets WHERE id = $1 RETURNING *;
 SELECT * FROM assets WHERE expires_at < $1;
 
 -- name: CountAssetsByContentHash :one
-SELECT COUNT(*) FROM assets WHERE content_hash = $1;
\ No newline at end of file
+SELECT COUNT(*) FROM assets WHERE content_hash = $1;
+
+-- name: GetAssetsWithoutExpiryAfterID :many
+SELECT * FROM assets
+WHERE id > $1 AND expires_at IS NULL AND module_id IS NULL AND created_at < $2
+ORDER BY id ASC
+LIMIT $3;
+
+-- name: GetReferencedAssetIDs :many
+SELECT asset_ids.id::text FROM unnest(@asset_ids::text[]) AS asset_ids(id)
+WHERE EXISTS (
+    SELECT 1 FROM messages WHERE messages.app_id = @app_id
+    AND (messages.data::text LIKE '%' || asset_ids.id || '%' OR messages.flow_sources::text LIKE '%' || asset_ids.id || '%')
+) OR EXISTS (
+    SELECT 1 FROM message_instances
+    JOIN messages ON messages.id = message_instances.message_id
+    WHERE messages.app_id = @app_id AND message_instances.flow_sources::text LIKE '%' || asset_ids.id || '%'
+) OR EXISTS (
+    SELECT 1 FROM commands WHERE commands.app_id = @app_id AND commands.flow_source::text LIKE '%' || asset_ids.id || '%'
+) OR EXISTS (
+    SELECT 1 FROM event_listeners WHERE event_listeners.app_id = @app_id AND event_listeners.flow_source::text LIKE '%' || asset_ids.id || '%'
+) OR EXISTS (
+    SELECT 1 FROM modules WHERE modules.app_id = @app_id AND modules.resources::text LIKE '%' || asset_ids.id || '%'
+) OR EXISTS (
+    SELECT 1 FROM plugin_instances WHERE plugin_instances.app_id = @app_id AND plugin_instances.config::text LIKE '%' || asset_ids.id || '%'
+) OR EXISTS (
+    SELECT 1 FROM plugin_values
+    JOIN plugin_instances ON plugin_instances.id = plugin_values.plugin_instance_id
+    WHERE plugin_instances.app_id = @app_id AND plugin_values.value::text LIKE '%' || asset_ids.id || '%'
+) OR EXISTS (
+    SELECT 1 FROM variables
+    WHERE (variables.app_id = @app_id OR variables.namespace_id IN (SELECT namespace_id FROM namespace_grants WHERE namespace_grants.app_id = @app_id))
+    AND variables.default_value::text LIKE '%' || asset_ids.id || '%'
+) OR EXISTS (
+    SELECT 1 FROM variable_values
+    JOIN variables ON variables.id = variable_values.variable_id
+    WHERE (variables.app_id = @app_id OR variables.namespace_id IN (SELECT namespace_id FROM namespace_grants WHERE namespace_grants.app_id = @app_id))
+    AND variable_values.value::text LIKE '%' || asset_ids.id || '%'
+);
+
+-- name: GetTemporaryAssetByContentHash :one
+SELECT * FROM assets
+WHERE app_id = $1 AND content_hash = $2 AND name = $3 AND expires_at > $4
+ORDER BY expires_at DESC
+LIMIT 1;
@@ -29,7 +29,8 @@ UPDATE commands SET
     description = $3,
     enabled = $4,
     flow_source = $5,
-    updated_at = $6
+    module_id = $6,
+    updated_at = $7
 WHERE id = $1 RETURNING *;
 
 -- name: UpdateCommandsLastDeployedAt :exec
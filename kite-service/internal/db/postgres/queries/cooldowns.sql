@@ -0,0 +1,14 @@
+-- name: HitCooldown :one
+INSERT INTO cooldowns (
+    key,
+    app_id,
+    count,
+    expires_at
+) VALUES ($1, $2, 1, $3)
+ON CONFLICT (key) DO UPDATE SET
+    count = CASE WHEN cooldowns.expires_at <= sqlc.arg(now) THEN 1 ELSE cooldowns.count + 1 END,
+    expires_at = CASE WHEN cooldowns.expires_at <= sqlc.arg(now) THEN EXCLUDED.expires_at ELSE cooldowns.expires_at END
+RETURNING *;
+
+-- name: DeleteExpiredCooldowns :execrows
+DELETE FROM cooldowns WHERE expires_at < $1;
@@ -0,0 +1,85 @@
+-- name: CreateMessageSyncJob :one
+INSERT INTO message_sync_jobs (
+    id,
+    app_id,
+    message_id,
+    status,
+    error,
+    created_at,
+    updated_at,
+    started_at,
+    completed_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: UpdateMessageSyncJob :one
+UPDATE message_sync_jobs SET
+    status = $2,
+    error = $3,
+    updated_at = $4,
+    started_at = $5,
+    completed_at = $6
+WHERE id = $1 RETURNING *;
+
+-- name: GetLatestMessageSyncJob :one
+SELECT * FROM message_sync_jobs WHERE message_id = $1 ORDER BY created_at DESC LIMIT 1;
+
+-- name: CountPendingMessageSyncJobsByApp :one
+SELECT COUNT(*) FROM message_sync_jobs WHERE app_id = $1 AND status = 'pending';
+
+-- name: GetPendingMessageSyncJobs :many
+SELECT * FROM message_sync_jobs WHERE status = 'pending' ORDER BY created_at ASC LIMIT $1;
+
+-- name: ClaimMessageSyncJob :execrows
+UPDATE message_sync_jobs SET
+    status = 'running',
+    started_at = $2,
+    updated_at = $2
+WHERE id = $1 AND status = 'pending';
+
+-- name: ResetStaleMessageSyncJobs :one
+WITH reset AS (
+    UPDATE message_sync_jobs SET
+        status = 'pending',
+        started_at = NULL
+    WHERE status = 'running' AND updated_at < $1
+    RETURNING id
+), deleted AS (
+    DELETE FROM message_sync_job_results WHERE job_id IN (SELECT id FROM reset)
+)
+SELECT COUNT(*) FROM reset;
+
+-- name: DeleteMessageSyncJobsCompletedBefore :execrows
+DELETE FROM message_sync_jobs WHERE completed_at < $1;
+
+-- name: CreateMessageSyncJobResult :execrows
+WITH job AS (
+    UPDATE message_sync_jobs SET updated_at = @created_at::timestamp WHERE id = @job_id::text RETURNING id
+)
+INSERT INTO message_sync_job_results (
+    job_id,
+    instance_id,
+    discord_guild_id,
+    discord_channel_id,
+    status,
+    error,
+    created_at
+) SELECT
+    job.id,
+    @instance_id::bigint,
+    @discord_guild_id::text,
+    @discord_channel_id::text,
+    @status::text,
+    sqlc.narg(error)::text,
+    @created_at::timestamp
+FROM job
+ON CONFLICT (job_id, instance_id) DO UPDATE SET
+    discord_guild_id = EXCLUDED.discord_guild_id,
+    discord_channel_id = EXCLUDED.discord_channel_id,
+    status = EXCLUDED.status,
+    error = EXCLUDED.error,
+    created_at = EXCLUDED.created_at;
+
+-- name: GetMessageSyncJobResults :many
+SELECT * FROM message_sync_job_results WHERE job_id = $1 ORDER BY created_at ASC, instance_id ASC;
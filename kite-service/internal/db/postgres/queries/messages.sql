@@ -72,4 +72,10 @@ WHERE id = $1 AND message_id = $2 RETURNING *;
 DELETE FROM message_instances WHERE id = $1 AND message_id = $2;
 
 -- name: DeleteMessageInstanceByDiscordMessageId :exec
-DELETE FROM message_instances WHERE discord_message_id = $1;
\ No newline at end of file
+DELETE FROM message_instances WHERE discord_message_id = $1;
+
+-- name: GetMessageInstancesAfterID :many
+SELECT * FROM message_instances WHERE id > $1 AND NOT ephemeral ORDER BY id ASC LIMIT $2;
+
+-- name: DeleteEphemeralMessageInstancesBefore :execrows
+DELETE FROM message_instances WHERE ephemeral AND created_at < $1;
\ No newline at end of file
@@ -0,0 +1,48 @@
+-- name: GetModule :one
+SELECT * FROM modules WHERE id = $1;
+
+-- name: GetModuleMeta :one
+SELECT id, name, description, enabled, app_id, creator_user_id, resources, created_at, updated_at, wasm_hash, octet_length(wasm_bytes) AS wasm_size
+FROM modules WHERE id = $1;
+
+-- name: GetModulesByApp :many
+SELECT id, name, description, enabled, app_id, creator_user_id, resources, created_at, updated_at, wasm_hash, octet_length(wasm_bytes) AS wasm_size
+FROM modules WHERE app_id = $1 ORDER BY created_at DESC;
+
+-- name: CountModulesByApp :one
+SELECT COUNT(*) FROM modules WHERE app_id = $1;
+
+-- name: CreateModule :one
+INSERT INTO modules (
+    id,
+    name,
+    description,
+    enabled,
+    app_id,
+    creator_user_id,
+    resources,
+    wasm_bytes,
+    wasm_hash,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING *;
+
+-- name: UpdateModule :one
+UPDATE modules SET
+    name = $2,
+    description = $3,
+    enabled = $4,
+    updated_at = $5
+WHERE id = $1 RETURNING *;
+
+-- name: UpdateModuleWasm :one
+UPDATE modules SET
+    wasm_bytes = $2,
+    wasm_hash = $3,
+    updated_at = $4
+WHERE id = $1 RETURNING *;
+
+-- name: DeleteModule :exec
+DELETE FROM modules WHERE id = $1;
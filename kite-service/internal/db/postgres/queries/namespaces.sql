@@ -0,0 +1,86 @@
+-- name: GetNamespace :one
+SELECT * FROM namespaces WHERE id = $1;
+
+-- name: GetNamespacesByOwner :many
+SELECT * FROM namespaces WHERE owner_user_id = $1 ORDER BY created_at DESC;
+
+-- name: CreateNamespace :one
+INSERT INTO namespaces (
+    id,
+    name,
+    description,
+    owner_user_id,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: UpdateNamespace :one
+UPDATE namespaces SET
+    name = $2,
+    description = $3,
+    updated_at = $4
+WHERE id = $1 RETURNING *;
+
+-- name: DeleteNamespace :exec
+DELETE FROM namespaces WHERE id = $1;
+
+-- name: GetNamespaceGrant :one
+SELECT * FROM namespace_grants WHERE namespace_id = $1 AND app_id = $2;
+
+-- name: GetNamespaceGrantsByNamespace :many
+SELECT * FROM namespace_grants WHERE namespace_id = $1 ORDER BY created_at ASC;
+
+-- name: GetNamespaceGrantsByApp :many
+SELECT * FROM namespace_grants WHERE app_id = $1 ORDER BY created_at ASC;
+
+-- name: UpsertNamespaceGrant :one
+INSERT INTO namespace_grants (
+    namespace_id,
+    app_id,
+    access,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (namespace_id, app_id) DO UPDATE SET
+    access = EXCLUDED.access,
+    updated_at = EXCLUDED.updated_at
+RETURNING *;
+
+-- name: DeleteNamespaceGrant :exec
+DELETE FROM namespace_grants WHERE namespace_id = $1 AND app_id = $2;
+
+-- name: GetNamespacesByMember :many
+SELECT * FROM namespaces
+WHERE owner_user_id = @user_id OR id IN (SELECT namespace_id FROM namespace_members WHERE user_id = @user_id)
+ORDER BY created_at DESC;
+
+-- name: GetNamespaceMember :one
+SELECT sqlc.embed(namespace_members), sqlc.embed(users) FROM namespace_members
+LEFT JOIN users ON namespace_members.user_id = users.id
+WHERE namespace_id = $1 AND user_id = $2;
+
+-- name: GetNamespaceMembersByNamespace :many
+SELECT sqlc.embed(namespace_members), sqlc.embed(users) FROM namespace_members
+LEFT JOIN users ON namespace_members.user_id = users.id
+WHERE namespace_id = $1
+ORDER BY namespace_members.created_at ASC;
+
+-- name: CountNamespaceMembersByNamespace :one
+SELECT COUNT(*) FROM namespace_members WHERE namespace_id = $1;
+
+-- name: CreateNamespaceMember :one
+INSERT INTO namespace_members (
+    namespace_id,
+    user_id,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: DeleteNamespaceMember :exec
+DELETE FROM namespace_members WHERE namespace_id = $1 AND user_id = $2;
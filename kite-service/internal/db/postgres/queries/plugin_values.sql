@@ -4,19 +4,69 @@ INSERT INTO plugin_values (
     key,
     value,
     created_at,
-    updated_at
+    updated_at,
+    expires_at
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6
 ) ON CONFLICT (plugin_instance_id, key) DO UPDATE SET
     value = EXCLUDED.value,
-    updated_at = EXCLUDED.updated_at
+    updated_at = EXCLUDED.updated_at,
+    expires_at = EXCLUDED.expires_at
 RETURNING *;
 
 -- name: GetPluginValue :one
-SELECT * FROM plugin_values WHERE plugin_instance_id = $1 AND key = $2;
+SELECT * FROM plugin_values
+WHERE plugin_instance_id = @plugin_instance_id AND key = @key
+AND (expires_at IS NULL OR expires_at > @now);
 
 -- name: GetPluginValueForUpdate :one
 SELECT * FROM plugin_values WHERE plugin_instance_id = $1 AND key = $2 FOR UPDATE;
 
+-- name: GetPluginValuesByKeys :many
+SELECT * FROM plugin_values
+WHERE plugin_instance_id = @plugin_instance_id AND key = ANY(@keys::text[])
+AND (expires_at IS NULL OR expires_at > @now);
+
 -- name: DeletePluginValue :exec
 DELETE FROM plugin_values WHERE plugin_instance_id = $1 AND key = $2;
+
+-- name: GetPluginValuesByKeyPrefix :many
+SELECT * FROM plugin_values
+WHERE plugin_instance_id = @plugin_instance_id AND starts_with(key, @key_prefix::text)
+AND (expires_at IS NULL OR expires_at > @now)
+ORDER BY CASE WHEN jsonb_typeof(value->'v') = 'number' THEN (value->'v')::float8 END DESC NULLS LAST, key ASC
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: CountPluginValuesByKeyPrefixAbove :one
+SELECT COUNT(*) FROM plugin_values
+WHERE plugin_instance_id = @plugin_instance_id AND starts_with(key, @key_prefix::text)
+AND (expires_at IS NULL OR expires_at > @now)
+AND CASE WHEN jsonb_typeof(value->'v') = 'number' THEN (value->'v')::float8 END > @value::float8;
+
+-- name: SwapPluginValue :execrows
+UPDATE plugin_values SET
+    value = @value,
+    updated_at = @updated_at,
+    expires_at = @expires_at
+WHERE plugin_instance_id = @plugin_instance_id AND key = @key AND value = @old_value::jsonb
+AND (expires_at IS NULL OR expires_at > @now);
+
+-- name: InsertPluginValueIfNotExists :execrows
+INSERT INTO plugin_values (
+    plugin_instance_id,
+    key,
+    value,
+    created_at,
+    updated_at,
+    expires_at
+) VALUES (
+    @plugin_instance_id, @key, @value, @created_at, @updated_at, @expires_at
+) ON CONFLICT (plugin_instance_id, key) DO UPDATE SET
+    value = EXCLUDED.value,
+    created_at = EXCLUDED.created_at,
+    updated_at = EXCLUDED.updated_at,
+    expires_at = EXCLUDED.expires_at
+WHERE plugin_values.expires_at IS NOT NULL AND plugin_values.expires_at <= @now;
+
+-- name: DeleteExpiredPluginValues :execrows
+DELETE FROM plugin_values WHERE expires_at < $1;
@@ -18,8 +18,11 @@ VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
 -- name: DeleteResumePoint :exec
 DELETE FROM resume_points WHERE id = $1;
 
--- name: DeleteExpiredResumePoints :exec
+-- name: DeleteExpiredResumePoints :execrows
 DELETE FROM resume_points WHERE expires_at < $1;
 
+-- name: DeleteResumePointsByTypeCreatedBefore :execrows
+DELETE FROM resume_points WHERE type = $1 AND created_at < $2;
+
 -- name: ResumePoint :one
 SELECT * FROM resume_points WHERE id = $1;
@@ -4,6 +4,9 @@ LEFT JOIN variable_values ON variables.id = variable_values.variable_id
 WHERE variables.id = $1
 GROUP BY variables.id;
 
+-- name: GetVariableDefinition :one
+SELECT * FROM variables WHERE id = $1;
+
 -- name: GetVariableByName :one
 SELECT sqlc.embed(variables), COUNT(variable_values.*) as total_values FROM variables 
 LEFT JOIN variable_values ON variables.id = variable_values.variable_id
@@ -17,6 +20,13 @@ WHERE variables.app_id = $1
 GROUP BY variables.id
 ORDER BY variables.created_at DESC;
 
+-- name: GetVariablesByNamespace :many
+SELECT sqlc.embed(variables), COUNT(variable_values.*) as total_values FROM variables 
+LEFT JOIN variable_values ON variables.id = variable_values.variable_id
+WHERE variables.namespace_id = $1 
+GROUP BY variables.id
+ORDER BY variables.created_at DESC;
+
 -- name: CountVariablesByApp :one
 SELECT COUNT(*) FROM variables WHERE app_id = $1;
 
@@ -28,29 +38,80 @@ INSERT INTO variables (
     app_id,
     module_id,
     created_at,
-    updated_at
+    updated_at,
+    type,
+    schema,
+    default_value,
+    value_ttl_seconds,
+    namespace_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 ) RETURNING *;
 
 -- name: UpdateVariable :one
 UPDATE variables SET
     name = $2,
     scoped = $3,
-    updated_at = $4
+    updated_at = $4,
+    type = $5,
+    schema = $6,
+    default_value = $7,
+    value_ttl_seconds = $8
 WHERE id = $1 RETURNING *;
 
 -- name: DeleteVariable :exec
 DELETE FROM variables WHERE id = $1;
 
 -- name: GetVariableValue :one
-SELECT * FROM variable_values WHERE variable_id = $1 AND scope IS NOT DISTINCT FROM $2;
+SELECT * FROM variable_values
+WHERE variable_id = @variable_id AND scope IS NOT DISTINCT FROM @scope
+AND (expires_at IS NULL OR expires_at > @now);
 
 -- name: GetVariableValueForUpdate :one
 SELECT * FROM variable_values WHERE variable_id = $1 AND scope IS NOT DISTINCT FROM $2 FOR UPDATE;
 
 -- name: GetVariableValues :many
-SELECT * FROM variable_values WHERE variable_id = $1;
+SELECT * FROM variable_values
+WHERE variable_id = @variable_id AND (expires_at IS NULL OR expires_at > @now);
+
+-- name: GetVariableValuesByNumber :many
+SELECT * FROM variable_values
+WHERE variable_id = @variable_id AND scope IS NOT NULL AND starts_with(scope, @scope_prefix::text)
+AND (expires_at IS NULL OR expires_at > @now)
+ORDER BY CASE WHEN jsonb_typeof(value->'v') = 'number' THEN (value->'v')::float8 END DESC NULLS LAST, scope ASC
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: GetVariableValuesByNumberAsc :many
+SELECT * FROM variable_values
+WHERE variable_id = @variable_id AND scope IS NOT NULL AND starts_with(scope, @scope_prefix::text)
+AND (expires_at IS NULL OR expires_at > @now)
+ORDER BY CASE WHEN jsonb_typeof(value->'v') = 'number' THEN (value->'v')::float8 END ASC NULLS LAST, scope ASC
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: GetVariableValuesByScope :many
+SELECT * FROM variable_values
+WHERE variable_id = @variable_id AND scope IS NOT NULL AND starts_with(scope, @scope_prefix::text)
+AND (expires_at IS NULL OR expires_at > @now)
+ORDER BY scope ASC
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: GetVariableValuesByScopeDesc :many
+SELECT * FROM variable_values
+WHERE variable_id = @variable_id AND scope IS NOT NULL AND starts_with(scope, @scope_prefix::text)
+AND (expires_at IS NULL OR expires_at > @now)
+ORDER BY scope DESC
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: AggregateVariableValues :one
+SELECT
+    COUNT(*) AS total_count,
+    COALESCE(SUM(CASE WHEN jsonb_typeof(value->'v') = 'number' THEN (value->'v')::float8 END), 0)::float8 AS total_sum,
+    AVG(CASE WHEN jsonb_typeof(value->'v') = 'number' THEN (value->'v')::float8 END)::float8 AS average,
+    MIN(CASE WHEN jsonb_typeof(value->'v') = 'number' THEN (value->'v')::float8 END)::float8 AS min_value,
+    MAX(CASE WHEN jsonb_typeof(value->'v') = 'number' THEN (value->'v')::float8 END)::float8 AS max_value
+FROM variable_values
+WHERE variable_id = @variable_id AND scope IS NOT NULL AND starts_with(scope, @scope_prefix::text)
+AND (expires_at IS NULL OR expires_at > @now);
 
 -- name: SetVariableValue :one
 INSERT INTO variable_values (
@@ -58,16 +119,38 @@ INSERT INTO variable_values (
     scope,
     value,
     created_at,
-    updated_at
+    updated_at,
+    expires_at
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6
 ) ON CONFLICT (variable_id, scope) DO UPDATE SET
     value = EXCLUDED.value,
-    updated_at = EXCLUDED.updated_at
+    updated_at = EXCLUDED.updated_at,
+    expires_at = EXCLUDED.expires_at
 RETURNING *;
 
+-- name: InsertVariableValueIfNotExists :execrows
+INSERT INTO variable_values (
+    variable_id,
+    scope,
+    value,
+    created_at,
+    updated_at,
+    expires_at
+) VALUES (
+    @variable_id, @scope, @value, @created_at, @updated_at, @expires_at
+) ON CONFLICT (variable_id, scope) DO UPDATE SET
+    value = EXCLUDED.value,
+    created_at = EXCLUDED.created_at,
+    updated_at = EXCLUDED.updated_at,
+    expires_at = EXCLUDED.expires_at
+WHERE variable_values.expires_at IS NOT NULL AND variable_values.expires_at <= @now;
+
 -- name: DeleteVariableValue :exec
-DELETE FROM variable_values WHERE variable_id = $1 AND scope = $2;
+DELETE FROM variable_values WHERE variable_id = $1 AND scope IS NOT DISTINCT FROM $2;
 
 -- name: DeleteAllVariableValues :exec
-DELETE FROM variable_values WHERE variable_id = $1;
\ No newline at end of file
+DELETE FROM variable_values WHERE variable_id = $1;
+
+-- name: DeleteExpiredVariableValues :execrows
+DELETE FROM variable_values WHERE expires_at < $1;
@@ -126,27 +126,91 @@ func (s *AssetStore) DeleteAsset(ctx context.Context, id string) error {
 	return nil
 }
 
-func (s *AssetStore) DeleteExpiredAssets(ctx context.Context, timestamp time.Time) error {
+func (s *AssetStore) DeleteExpiredAssets(ctx context.Context, timestamp time.Time) (int, error) {
 	assets, err := s.pg.Q.GetExpiredAssets(ctx, pgtype.Timestamp{
 		Time:  timestamp.UTC(),
 		Valid: true,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to get expired assets: %w", err)
+		return 0, fmt.Errorf("failed to get expired assets: %w", err)
 	}
 
+	return s.deleteAssets(ctx, assets), nil
+}
+
+func (s *AssetStore) AssetsWithoutExpiryAfterID(ctx context.Context, afterID string, createdBefore time.Time, limit int) ([]*model.Asset, error) {
+	rows, err := s.pg.Q.GetAssetsWithoutExpiryAfterID(ctx, pgmodel.GetAssetsWithoutExpiryAfterIDParams{
+		ID: afterID,
+		CreatedAt: pgtype.Timestamp{
+			Time:  createdBefore.UTC(),
+			Valid: true,
+		},
+		Limit: int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assets without expiry: %w", err)
+	}
+
+	assets := make([]*model.Asset, len(rows))
+	for i, row := range rows {
+		asset, err := rowToAsset(row)
+		if err != nil {
+			return nil, err
+		}
+		assets[i] = asset
+	}
+
+	return assets, nil
+}
+
+func (s *AssetStore) TemporaryAssetByContentHash(ctx context.Context, appID string, contentHash string, name string, expiresAfter time.Time) (*model.Asset, error) {
+	row, err := s.pg.Q.GetTemporaryAssetByContentHash(ctx, pgmodel.GetTemporaryAssetByContentHashParams{
+		AppID:       appID,
+		ContentHash: contentHash,
+		Name:        name,
+		ExpiresAt: pgtype.Timestamp{
+			Time:  expiresAfter.UTC(),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return rowToAsset(row)
+}
+
+func (s *AssetStore) ReferencedAssetIDs(ctx context.Context, appID string, assetIDs []string) ([]string, error) {
+	ids, err := s.pg.Q.GetReferencedAssetIDs(ctx, pgmodel.GetReferencedAssetIDsParams{
+		AssetIds: assetIDs,
+		AppID:    appID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get referenced asset ids: %w", err)
+	}
+
+	return ids, nil
+}
+
+func (s *AssetStore) deleteAssets(ctx context.Context, assets []pgmodel.Asset) int {
+	deleted := 0
 	for _, asset := range assets {
 		err := s.DeleteAsset(ctx, asset.ID)
 		if err != nil {
 			slog.Error(
-				"failed to delete expired asset",
+				"failed to delete asset",
 				slog.String("asset_id", asset.ID),
 				slog.String("error", err.Error()),
 			)
+			continue
 		}
+		deleted++
 	}
 
-	return nil
+	return deleted
 }
 
 func rowToAsset(row pgmodel.Asset) (*model.Asset, error) {
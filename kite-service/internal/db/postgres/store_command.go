@@ -94,7 +94,11 @@ func (c *Client) UpdateCommand(ctx context.Context, command *model.Command) (*mo
 		Description: command.Description,
 		Enabled:     command.Enabled,
 		FlowSource:  flowSource,
-		UpdatedAt:   pgtype.Timestamp{Time: command.UpdatedAt.UTC(), Valid: true},
+		ModuleID: pgtype.Text{
+			String: command.ModuleID.String,
+			Valid:  command.ModuleID.Valid,
+		},
+		UpdatedAt: pgtype.Timestamp{Time: command.UpdatedAt.UTC(), Valid: true},
 	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kitecloud/kite/kite-service/internal/db/postgres/pgmodel"
+	"github.com/kitecloud/kite/kite-service/internal/model"
+)
+
+func (c *Client) HitCooldown(ctx context.Context, appID string, key string, window time.Duration, now time.Time) (*model.Cooldown, error) {
+	row, err := c.Q.HitCooldown(ctx, pgmodel.HitCooldownParams{
+		Key:       key,
+		AppID:     appID,
+		ExpiresAt: pgtype.Timestamp{Time: now.Add(window), Valid: true},
+		Now:       pgtype.Timestamp{Time: now, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to hit cooldown: %w", err)
+	}
+
+	return rowToCooldown(row), nil
+}
+
+func (c *Client) DeleteExpiredCooldowns(ctx context.Context, now time.Time) (int, error) {
+	deleted, err := c.Q.DeleteExpiredCooldowns(ctx, pgtype.Timestamp{Time: now, Valid: true})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired cooldowns: %w", err)
+	}
+	return int(deleted), nil
+}
+
+func rowToCooldown(row pgmodel.Cooldown) *model.Cooldown {
+	return &model.Cooldown{
+		Key:       row.Key,
+		AppID:     row.AppID,
+		Count:     int(row.Count),
+		ExpiresAt: row.ExpiresAt.Time,
+	}
+}
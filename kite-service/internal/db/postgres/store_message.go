@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -282,6 +283,39 @@ func (c *Client) DeleteMessageInstanceByDiscordMessageID(ctx context.Context, di
 	return nil
 }
 
+func (c *Client) MessageInstancesAfterID(ctx context.Context, afterID uint64, limit int) ([]*model.MessageInstance, error) {
+	rows, err := c.Q.GetMessageInstancesAfterID(ctx, pgmodel.GetMessageInstancesAfterIDParams{
+		ID:    int64(afterID),
+		Limit: int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make([]*model.MessageInstance, len(rows))
+	for i, row := range rows {
+		instance, err := rowToMessageInstance(row)
+		if err != nil {
+			return nil, err
+		}
+		instances[i] = instance
+	}
+
+	return instances, nil
+}
+
+func (c *Client) DeleteEphemeralMessageInstancesBefore(ctx context.Context, timestamp time.Time) (int, error) {
+	deleted, err := c.Q.DeleteEphemeralMessageInstancesBefore(ctx, pgtype.Timestamp{
+		Time:  timestamp.UTC(),
+		Valid: true,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(deleted), nil
+}
+
 func rowToMessageInstance(row pgmodel.MessageInstance) (*model.MessageInstance, error) {
 	var flowSources map[string]flow.FlowData
 	if err := json.Unmarshal(row.FlowSources, &flowSources); err != nil {
@@ -0,0 +1,165 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kitecloud/kite/kite-service/internal/db/postgres/pgmodel"
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"gopkg.in/guregu/null.v4"
+)
+
+func (c *Client) CreateMessageSyncJob(ctx context.Context, job *model.MessageSyncJob) error {
+	_, err := c.Q.CreateMessageSyncJob(ctx, pgmodel.CreateMessageSyncJobParams{
+		ID:          job.ID,
+		AppID:       job.AppID,
+		MessageID:   job.MessageID,
+		Status:      string(job.Status),
+		Error:       pgtype.Text{String: job.Error.String, Valid: job.Error.Valid},
+		CreatedAt:   pgtype.Timestamp{Time: job.CreatedAt.UTC(), Valid: true},
+		UpdatedAt:   pgtype.Timestamp{Time: job.UpdatedAt.UTC(), Valid: true},
+		StartedAt:   pgtype.Timestamp{Time: job.StartedAt.Time.UTC(), Valid: job.StartedAt.Valid},
+		CompletedAt: pgtype.Timestamp{Time: job.CompletedAt.Time.UTC(), Valid: job.CompletedAt.Valid},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create message sync job: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) UpdateMessageSyncJob(ctx context.Context, job *model.MessageSyncJob) error {
+	_, err := c.Q.UpdateMessageSyncJob(ctx, pgmodel.UpdateMessageSyncJobParams{
+		ID:          job.ID,
+		Status:      string(job.Status),
+		Error:       pgtype.Text{String: job.Error.String, Valid: job.Error.Valid},
+		UpdatedAt:   pgtype.Timestamp{Time: job.UpdatedAt.UTC(), Valid: true},
+		StartedAt:   pgtype.Timestamp{Time: job.StartedAt.Time.UTC(), Valid: job.StartedAt.Valid},
+		CompletedAt: pgtype.Timestamp{Time: job.CompletedAt.Time.UTC(), Valid: job.CompletedAt.Valid},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return store.ErrNotFound
+		}
+		return fmt.Errorf("failed to update message sync job: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) CreateMessageSyncJobResult(ctx context.Context, jobID string, result model.MessageInstanceSyncResult, now time.Time) error {
+	created, err := c.Q.CreateMessageSyncJobResult(ctx, pgmodel.CreateMessageSyncJobResultParams{
+		CreatedAt:        pgtype.Timestamp{Time: now.UTC(), Valid: true},
+		JobID:            jobID,
+		InstanceID:       int64(result.InstanceID),
+		DiscordGuildID:   result.DiscordGuildID,
+		DiscordChannelID: result.DiscordChannelID,
+		Status:           string(result.Status),
+		Error:            pgtype.Text{String: result.Error.String, Valid: result.Error.Valid},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create message sync job result: %w", err)
+	}
+	if created == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+func (c *Client) LatestMessageSyncJob(ctx context.Context, messageID string) (*model.MessageSyncJob, error) {
+	row, err := c.Q.GetLatestMessageSyncJob(ctx, messageID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	resultRows, err := c.Q.GetMessageSyncJobResults(ctx, row.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message sync job results: %w", err)
+	}
+
+	job := rowToMessageSyncJob(row)
+	job.Results = make([]model.MessageInstanceSyncResult, len(resultRows))
+	for i, resultRow := range resultRows {
+		job.Results[i] = model.MessageInstanceSyncResult{
+			InstanceID:       uint64(resultRow.InstanceID),
+			DiscordGuildID:   resultRow.DiscordGuildID,
+			DiscordChannelID: resultRow.DiscordChannelID,
+			Status:           model.MessageInstanceSyncStatus(resultRow.Status),
+			Error:            null.NewString(resultRow.Error.String, resultRow.Error.Valid),
+		}
+	}
+
+	return job, nil
+}
+
+func (c *Client) CountPendingMessageSyncJobsByApp(ctx context.Context, appID string) (int, error) {
+	count, err := c.Q.CountPendingMessageSyncJobsByApp(ctx, appID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending message sync jobs: %w", err)
+	}
+	return int(count), nil
+}
+
+func (c *Client) PendingMessageSyncJobs(ctx context.Context, limit int) ([]*model.MessageSyncJob, error) {
+	rows, err := c.Q.GetPendingMessageSyncJobs(ctx, int32(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending message sync jobs: %w", err)
+	}
+
+	jobs := make([]*model.MessageSyncJob, len(rows))
+	for i, row := range rows {
+		jobs[i] = rowToMessageSyncJob(row)
+	}
+
+	return jobs, nil
+}
+
+func (c *Client) ClaimMessageSyncJob(ctx context.Context, id string, now time.Time) (bool, error) {
+	claimed, err := c.Q.ClaimMessageSyncJob(ctx, pgmodel.ClaimMessageSyncJobParams{
+		ID:        id,
+		StartedAt: pgtype.Timestamp{Time: now.UTC(), Valid: true},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to claim message sync job: %w", err)
+	}
+	return claimed > 0, nil
+}
+
+func (c *Client) ResetStaleMessageSyncJobs(ctx context.Context, updatedBefore time.Time) (int, error) {
+	reset, err := c.Q.ResetStaleMessageSyncJobs(ctx, pgtype.Timestamp{Time: updatedBefore.UTC(), Valid: true})
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset stale message sync jobs: %w", err)
+	}
+	return int(reset), nil
+}
+
+func (c *Client) DeleteMessageSyncJobsCompletedBefore(ctx context.Context, before time.Time) (int, error) {
+	deleted, err := c.Q.DeleteMessageSyncJobsCompletedBefore(ctx, pgtype.Timestamp{Time: before.UTC(), Valid: true})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete message sync jobs: %w", err)
+	}
+	return int(deleted), nil
+}
+
+func rowToMessageSyncJob(row pgmodel.MessageSyncJob) *model.MessageSyncJob {
+	return &model.MessageSyncJob{
+		ID:          row.ID,
+		AppID:       row.AppID,
+		MessageID:   row.MessageID,
+		Status:      model.MessageSyncStatus(row.Status),
+		Error:       null.NewString(row.Error.String, row.Error.Valid),
+		CreatedAt:   row.CreatedAt.Time,
+		UpdatedAt:   row.UpdatedAt.Time,
+		StartedAt:   null.NewTime(row.StartedAt.Time, row.StartedAt.Valid),
+		CompletedAt: null.NewTime(row.CompletedAt.Time, row.CompletedAt.Valid),
+	}
+}
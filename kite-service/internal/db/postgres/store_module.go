@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kitecloud/kite/kite-service/internal/db/postgres/pgmodel"
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+func (c *Client) ModulesByApp(ctx context.Context, appID string) ([]*model.Module, error) {
+	rows, err := c.Q.GetModulesByApp(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	modules := make([]*model.Module, len(rows))
+	for i, row := range rows {
+		modules[i] = &model.Module{
+			ID:            row.ID,
+			Name:          row.Name,
+			Description:   row.Description,
+			Enabled:       row.Enabled,
+			AppID:         row.AppID,
+			CreatorUserID: row.CreatorUserID,
+			WasmHash:      row.WasmHash,
+			WasmSize:      int(row.WasmSize),
+			CreatedAt:     row.CreatedAt.Time,
+			UpdatedAt:     row.UpdatedAt.Time,
+		}
+	}
+
+	return modules, nil
+}
+
+func (c *Client) CountModulesByApp(ctx context.Context, appID string) (int, error) {
+	res, err := c.Q.CountModulesByApp(ctx, appID)
+	if err != nil {
+		return 0, err
+	}
+	return int(res), nil
+}
+
+func (c *Client) Module(ctx context.Context, id string) (*model.Module, error) {
+	row, err := c.Q.GetModule(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return rowToModule(row), nil
+}
+
+func (c *Client) ModuleMeta(ctx context.Context, id string) (*model.Module, error) {
+	row, err := c.Q.GetModuleMeta(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &model.Module{
+		ID:            row.ID,
+		Name:          row.Name,
+		Description:   row.Description,
+		Enabled:       row.Enabled,
+		AppID:         row.AppID,
+		CreatorUserID: row.CreatorUserID,
+		WasmHash:      row.WasmHash,
+		WasmSize:      int(row.WasmSize),
+		CreatedAt:     row.CreatedAt.Time,
+		UpdatedAt:     row.UpdatedAt.Time,
+	}, nil
+}
+
+func (c *Client) CreateModule(ctx context.Context, module *model.Module) (*model.Module, error) {
+	row, err := c.Q.CreateModule(ctx, pgmodel.CreateModuleParams{
+		ID:            module.ID,
+		Name:          module.Name,
+		Description:   module.Description,
+		Enabled:       module.Enabled,
+		AppID:         module.AppID,
+		CreatorUserID: module.CreatorUserID,
+		Resources:     []byte("{}"),
+		WasmBytes:     module.WasmBytes,
+		WasmHash:      module.WasmHash,
+		CreatedAt: pgtype.Timestamp{
+			Time:  module.CreatedAt.UTC(),
+			Valid: true,
+		},
+		UpdatedAt: pgtype.Timestamp{
+			Time:  module.UpdatedAt.UTC(),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rowToModule(row), nil
+}
+
+func (c *Client) UpdateModule(ctx context.Context, module *model.Module) (*model.Module, error) {
+	row, err := c.Q.UpdateModule(ctx, pgmodel.UpdateModuleParams{
+		ID:          module.ID,
+		Name:        module.Name,
+		Description: module.Description,
+		Enabled:     module.Enabled,
+		UpdatedAt: pgtype.Timestamp{
+			Time:  module.UpdatedAt.UTC(),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return rowToModule(row), nil
+}
+
+func (c *Client) UpdateModuleWasm(ctx context.Context, module *model.Module) (*model.Module, error) {
+	row, err := c.Q.UpdateModuleWasm(ctx, pgmodel.UpdateModuleWasmParams{
+		ID:        module.ID,
+		WasmBytes: module.WasmBytes,
+		WasmHash:  module.WasmHash,
+		UpdatedAt: pgtype.Timestamp{
+			Time:  module.UpdatedAt.UTC(),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return rowToModule(row), nil
+}
+
+func (c *Client) DeleteModule(ctx context.Context, id string) error {
+	return c.Q.DeleteModule(ctx, id)
+}
+
+func rowToModule(row pgmodel.Module) *model.Module {
+	return &model.Module{
+		ID:            row.ID,
+		Name:          row.Name,
+		Description:   row.Description,
+		Enabled:       row.Enabled,
+		AppID:         row.AppID,
+		CreatorUserID: row.CreatorUserID,
+		WasmBytes:     row.WasmBytes,
+		WasmHash:      row.WasmHash,
+		WasmSize:      len(row.WasmBytes),
+		CreatedAt:     row.CreatedAt.Time,
+		UpdatedAt:     row.UpdatedAt.Time,
+	}
+}
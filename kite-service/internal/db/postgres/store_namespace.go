@@ -0,0 +1,264 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kitecloud/kite/kite-service/internal/db/postgres/pgmodel"
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"gopkg.in/guregu/null.v4"
+)
+
+func (c *Client) NamespacesByOwner(ctx context.Context, ownerUserID string) ([]*model.Namespace, error) {
+	rows, err := c.Q.GetNamespacesByOwner(ctx, ownerUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := make([]*model.Namespace, len(rows))
+	for i, row := range rows {
+		namespaces[i] = rowToNamespace(row)
+	}
+
+	return namespaces, nil
+}
+
+func (c *Client) NamespacesByMember(ctx context.Context, userID string) ([]*model.Namespace, error) {
+	rows, err := c.Q.GetNamespacesByMember(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := make([]*model.Namespace, len(rows))
+	for i, row := range rows {
+		namespaces[i] = rowToNamespace(row)
+	}
+
+	return namespaces, nil
+}
+
+func (c *Client) Namespace(ctx context.Context, id string) (*model.Namespace, error) {
+	row, err := c.Q.GetNamespace(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return rowToNamespace(row), nil
+}
+
+func (c *Client) CreateNamespace(ctx context.Context, namespace *model.Namespace) (*model.Namespace, error) {
+	row, err := c.Q.CreateNamespace(ctx, pgmodel.CreateNamespaceParams{
+		ID:   namespace.ID,
+		Name: namespace.Name,
+		Description: pgtype.Text{
+			String: namespace.Description.String,
+			Valid:  namespace.Description.Valid,
+		},
+		OwnerUserID: namespace.OwnerUserID,
+		CreatedAt: pgtype.Timestamp{
+			Time:  namespace.CreatedAt.UTC(),
+			Valid: true,
+		},
+		UpdatedAt: pgtype.Timestamp{
+			Time:  namespace.UpdatedAt.UTC(),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rowToNamespace(row), nil
+}
+
+func (c *Client) UpdateNamespace(ctx context.Context, namespace *model.Namespace) (*model.Namespace, error) {
+	row, err := c.Q.UpdateNamespace(ctx, pgmodel.UpdateNamespaceParams{
+		ID:   namespace.ID,
+		Name: namespace.Name,
+		Description: pgtype.Text{
+			String: namespace.Description.String,
+			Valid:  namespace.Description.Valid,
+		},
+		UpdatedAt: pgtype.Timestamp{
+			Time:  namespace.UpdatedAt.UTC(),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return rowToNamespace(row), nil
+}
+
+func (c *Client) DeleteNamespace(ctx context.Context, id string) error {
+	return c.Q.DeleteNamespace(ctx, id)
+}
+
+func (c *Client) NamespaceGrant(ctx context.Context, namespaceID string, appID string) (*model.NamespaceGrant, error) {
+	row, err := c.Q.GetNamespaceGrant(ctx, pgmodel.GetNamespaceGrantParams{
+		NamespaceID: namespaceID,
+		AppID:       appID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return rowToNamespaceGrant(row), nil
+}
+
+func (c *Client) NamespaceGrantsByNamespace(ctx context.Context, namespaceID string) ([]*model.NamespaceGrant, error) {
+	rows, err := c.Q.GetNamespaceGrantsByNamespace(ctx, namespaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	grants := make([]*model.NamespaceGrant, len(rows))
+	for i, row := range rows {
+		grants[i] = rowToNamespaceGrant(row)
+	}
+
+	return grants, nil
+}
+
+func (c *Client) NamespaceGrantsByApp(ctx context.Context, appID string) ([]*model.NamespaceGrant, error) {
+	rows, err := c.Q.GetNamespaceGrantsByApp(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	grants := make([]*model.NamespaceGrant, len(rows))
+	for i, row := range rows {
+		grants[i] = rowToNamespaceGrant(row)
+	}
+
+	return grants, nil
+}
+
+func (c *Client) UpsertNamespaceGrant(ctx context.Context, grant *model.NamespaceGrant) (*model.NamespaceGrant, error) {
+	row, err := c.Q.UpsertNamespaceGrant(ctx, pgmodel.UpsertNamespaceGrantParams{
+		NamespaceID: grant.NamespaceID,
+		AppID:       grant.AppID,
+		Access:      string(grant.Access),
+		CreatedAt:   pgtype.Timestamp{Time: grant.CreatedAt.UTC(), Valid: true},
+		UpdatedAt:   pgtype.Timestamp{Time: grant.UpdatedAt.UTC(), Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rowToNamespaceGrant(row), nil
+}
+
+func (c *Client) DeleteNamespaceGrant(ctx context.Context, namespaceID string, appID string) error {
+	return c.Q.DeleteNamespaceGrant(ctx, pgmodel.DeleteNamespaceGrantParams{
+		NamespaceID: namespaceID,
+		AppID:       appID,
+	})
+}
+
+func (c *Client) NamespaceMember(ctx context.Context, namespaceID string, userID string) (*model.NamespaceMember, error) {
+	row, err := c.Q.GetNamespaceMember(ctx, pgmodel.GetNamespaceMemberParams{
+		NamespaceID: namespaceID,
+		UserID:      userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return rowToNamespaceMember(row.NamespaceMember, &row.User), nil
+}
+
+func (c *Client) NamespaceMembersByNamespace(ctx context.Context, namespaceID string) ([]*model.NamespaceMember, error) {
+	rows, err := c.Q.GetNamespaceMembersByNamespace(ctx, namespaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]*model.NamespaceMember, len(rows))
+	for i, row := range rows {
+		members[i] = rowToNamespaceMember(row.NamespaceMember, &row.User)
+	}
+
+	return members, nil
+}
+
+func (c *Client) CountNamespaceMembersByNamespace(ctx context.Context, namespaceID string) (int, error) {
+	count, err := c.Q.CountNamespaceMembersByNamespace(ctx, namespaceID)
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (c *Client) CreateNamespaceMember(ctx context.Context, member *model.NamespaceMember) (*model.NamespaceMember, error) {
+	row, err := c.Q.CreateNamespaceMember(ctx, pgmodel.CreateNamespaceMemberParams{
+		NamespaceID: member.NamespaceID,
+		UserID:      member.UserID,
+		CreatedAt:   pgtype.Timestamp{Time: member.CreatedAt.UTC(), Valid: true},
+		UpdatedAt:   pgtype.Timestamp{Time: member.UpdatedAt.UTC(), Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rowToNamespaceMember(row, nil), nil
+}
+
+func (c *Client) DeleteNamespaceMember(ctx context.Context, namespaceID string, userID string) error {
+	return c.Q.DeleteNamespaceMember(ctx, pgmodel.DeleteNamespaceMemberParams{
+		NamespaceID: namespaceID,
+		UserID:      userID,
+	})
+}
+
+func rowToNamespace(row pgmodel.Namespace) *model.Namespace {
+	return &model.Namespace{
+		ID:          row.ID,
+		Name:        row.Name,
+		Description: null.NewString(row.Description.String, row.Description.Valid),
+		OwnerUserID: row.OwnerUserID,
+		CreatedAt:   row.CreatedAt.Time,
+		UpdatedAt:   row.UpdatedAt.Time,
+	}
+}
+
+func rowToNamespaceGrant(row pgmodel.NamespaceGrant) *model.NamespaceGrant {
+	return &model.NamespaceGrant{
+		NamespaceID: row.NamespaceID,
+		AppID:       row.AppID,
+		Access:      model.NamespaceAccess(row.Access),
+		CreatedAt:   row.CreatedAt.Time,
+		UpdatedAt:   row.UpdatedAt.Time,
+	}
+}
+
+func rowToNamespaceMember(row pgmodel.NamespaceMember, user *pgmodel.User) *model.NamespaceMember {
+	var userModel *model.User
+	if user != nil {
+		userModel = rowToUser(*user)
+	}
+
+	return &model.NamespaceMember{
+		NamespaceID: row.NamespaceID,
+		UserID:      row.UserID,
+		User:        userModel,
+		CreatedAt:   row.CreatedAt.Time,
+		UpdatedAt:   row.UpdatedAt.Time,
+	}
+}
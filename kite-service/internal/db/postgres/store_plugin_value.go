@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -13,12 +14,14 @@ import (
 	"github.com/kitecloud/kite/kite-service/internal/store"
 	"github.com/kitecloud/kite/kite-service/pkg/provider"
 	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"gopkg.in/guregu/null.v4"
 )
 
 func (c *Client) GetPluginValue(ctx context.Context, pluginInstanceID, key string) (*model.PluginValue, error) {
 	row, err := c.Q.GetPluginValue(ctx, pgmodel.GetPluginValueParams{
 		PluginInstanceID: pluginInstanceID,
 		Key:              key,
+		Now:              pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
 	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -30,6 +33,28 @@ func (c *Client) GetPluginValue(ctx context.Context, pluginInstanceID, key strin
 	return rowToPluginValue(row)
 }
 
+func (c *Client) PluginValuesByKeys(ctx context.Context, pluginInstanceID string, keys []string) ([]*model.PluginValue, error) {
+	rows, err := c.Q.GetPluginValuesByKeys(ctx, pgmodel.GetPluginValuesByKeysParams{
+		PluginInstanceID: pluginInstanceID,
+		Keys:             keys,
+		Now:              pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]*model.PluginValue, len(rows))
+	for i, row := range rows {
+		value, err := rowToPluginValue(row)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+
+	return values, nil
+}
+
 func (c *Client) DeletePluginValue(ctx context.Context, pluginInstanceID, key string) error {
 	err := c.Q.DeletePluginValue(ctx, pgmodel.DeletePluginValueParams{
 		PluginInstanceID: pluginInstanceID,
@@ -39,11 +64,114 @@ func (c *Client) DeletePluginValue(ctx context.Context, pluginInstanceID, key st
 	return err
 }
 
+func (c *Client) PluginValuesByKeyPrefix(ctx context.Context, pluginInstanceID, keyPrefix string, limit int, offset int) ([]*model.PluginValue, error) {
+	rows, err := c.Q.GetPluginValuesByKeyPrefix(ctx, pgmodel.GetPluginValuesByKeyPrefixParams{
+		PluginInstanceID: pluginInstanceID,
+		KeyPrefix:        keyPrefix,
+		Now:              pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+		LimitCount:       int32(limit),
+		OffsetCount:      int32(offset),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]*model.PluginValue, len(rows))
+	for i, row := range rows {
+		value, err := rowToPluginValue(row)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+func (c *Client) CountPluginValuesByKeyPrefixAbove(ctx context.Context, pluginInstanceID, keyPrefix string, value float64) (int, error) {
+	count, err := c.Q.CountPluginValuesByKeyPrefixAbove(ctx, pgmodel.CountPluginValuesByKeyPrefixAboveParams{
+		PluginInstanceID: pluginInstanceID,
+		KeyPrefix:        keyPrefix,
+		Now:              pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+		Value:            value,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
 func (c *Client) SetPluginValue(ctx context.Context, value model.PluginValue) error {
 	_, err := c.setPluginValueWithTx(ctx, nil, value)
 	return err
 }
 
+func (c *Client) SetPluginValues(ctx context.Context, values []model.PluginValue) error {
+	tx, err := c.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, value := range values {
+		if _, err := c.setPluginValueWithTx(ctx, tx, value); err != nil {
+			return fmt.Errorf("failed to set plugin value: %w", err)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) CompareAndSwapPluginValue(ctx context.Context, old thing.Thing, value model.PluginValue) (bool, error) {
+	data, err := json.Marshal(value.Value)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal plugin value: %w", err)
+	}
+
+	now := pgtype.Timestamp{Time: time.Now().UTC(), Valid: true}
+	expiresAt := pgtype.Timestamp{Time: value.ExpiresAt.Time.UTC(), Valid: value.ExpiresAt.Valid}
+
+	var affected int64
+	if old.IsNil() {
+		affected, err = c.Q.InsertPluginValueIfNotExists(ctx, pgmodel.InsertPluginValueIfNotExistsParams{
+			PluginInstanceID: value.PluginInstanceID,
+			Key:              value.Key,
+			Value:            data,
+			CreatedAt:        pgtype.Timestamp{Time: value.CreatedAt, Valid: true},
+			UpdatedAt:        pgtype.Timestamp{Time: value.UpdatedAt, Valid: true},
+			ExpiresAt:        expiresAt,
+			Now:              now,
+		})
+	} else {
+		var oldData []byte
+		oldData, err = json.Marshal(old)
+		if err != nil {
+			return false, fmt.Errorf("failed to marshal old plugin value: %w", err)
+		}
+
+		affected, err = c.Q.SwapPluginValue(ctx, pgmodel.SwapPluginValueParams{
+			Value:            data,
+			UpdatedAt:        pgtype.Timestamp{Time: value.UpdatedAt, Valid: true},
+			ExpiresAt:        expiresAt,
+			PluginInstanceID: value.PluginInstanceID,
+			Key:              value.Key,
+			OldValue:         oldData,
+			Now:              now,
+		})
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
 func (c *Client) UpdatePluginValue(ctx context.Context, operation model.PluginValueOperation, value model.PluginValue) (*model.PluginValue, error) {
 	if operation == provider.VariableOperationOverwrite {
 		return c.setPluginValueWithTx(ctx, nil, value)
@@ -64,16 +192,13 @@ func (c *Client) UpdatePluginValue(ctx context.Context, operation model.PluginVa
 		return nil, fmt.Errorf("failed to get current plugin value: %w", err)
 	}
 
-	switch operation {
-	case provider.VariableOperationAppend:
-		value.Value = currentValue.Value.Append(value.Value)
-	case provider.VariableOperationPrepend:
-		value.Value = value.Value.Append(currentValue.Value)
-	case provider.VariableOperationIncrement:
-		value.Value = currentValue.Value.Add(value.Value)
-	case provider.VariableOperationDecrement:
-		value.Value = currentValue.Value.Sub(value.Value)
+	value.ExpiresAt = currentValue.ExpiresAt
+
+	newData, _, err := operation.Apply(&currentValue.Value, value.Value, provider.VariableOperationArgs{})
+	if err != nil {
+		return nil, err
 	}
+	value.Value = newData
 
 	newValue, err := c.setPluginValueWithTx(ctx, tx, value)
 	if err != nil {
@@ -106,6 +231,11 @@ func (c *Client) pluginValueWithTx(ctx context.Context, tx pgx.Tx, pluginInstanc
 		return nil, err
 	}
 
+	// The row is locked even when it has expired, so the caller can overwrite it
+	if row.ExpiresAt.Valid && !row.ExpiresAt.Time.After(time.Now().UTC()) {
+		return nil, store.ErrNotFound
+	}
+
 	return rowToPluginValue(row)
 }
 
@@ -126,6 +256,7 @@ func (c *Client) setPluginValueWithTx(ctx context.Context, tx pgx.Tx, value mode
 		Value:            data,
 		CreatedAt:        pgtype.Timestamp{Time: value.CreatedAt, Valid: true},
 		UpdatedAt:        pgtype.Timestamp{Time: value.UpdatedAt, Valid: true},
+		ExpiresAt:        pgtype.Timestamp{Time: value.ExpiresAt.Time.UTC(), Valid: value.ExpiresAt.Valid},
 	})
 	if err != nil {
 		return nil, err
@@ -134,6 +265,15 @@ func (c *Client) setPluginValueWithTx(ctx context.Context, tx pgx.Tx, value mode
 	return rowToPluginValue(row)
 }
 
+func (c *Client) DeleteExpiredPluginValues(ctx context.Context, now time.Time) (int, error) {
+	deleted, err := c.Q.DeleteExpiredPluginValues(ctx, pgtype.Timestamp{Time: now.UTC(), Valid: true})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(deleted), nil
+}
+
 func rowToPluginValue(row pgmodel.PluginValue) (*model.PluginValue, error) {
 	var data thing.Thing
 	err := json.Unmarshal(row.Value, &data)
@@ -148,5 +288,6 @@ func rowToPluginValue(row pgmodel.PluginValue) (*model.PluginValue, error) {
 		Value:            data,
 		CreatedAt:        row.CreatedAt.Time,
 		UpdatedAt:        row.UpdatedAt.Time,
+		ExpiresAt:        null.NewTime(row.ExpiresAt.Time, row.ExpiresAt.Valid),
 	}, nil
 }
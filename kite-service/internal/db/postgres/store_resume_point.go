@@ -47,8 +47,23 @@ func (c *Client) DeleteResumePoint(ctx context.Context, id string) error {
 	return c.Q.DeleteResumePoint(ctx, id)
 }
 
-func (c *Client) DeleteExpiredResumePoints(ctx context.Context, now time.Time) error {
-	return c.Q.DeleteExpiredResumePoints(ctx, pgtype.Timestamp{Time: now, Valid: true})
+func (c *Client) DeleteExpiredResumePoints(ctx context.Context, now time.Time) (int, error) {
+	deleted, err := c.Q.DeleteExpiredResumePoints(ctx, pgtype.Timestamp{Time: now, Valid: true})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired resume points: %w", err)
+	}
+	return int(deleted), nil
+}
+
+func (c *Client) DeleteResumePointsCreatedBefore(ctx context.Context, resumePointType model.ResumePointType, timestamp time.Time) (int, error) {
+	deleted, err := c.Q.DeleteResumePointsByTypeCreatedBefore(ctx, pgmodel.DeleteResumePointsByTypeCreatedBeforeParams{
+		Type:      string(resumePointType),
+		CreatedAt: pgtype.Timestamp{Time: timestamp.UTC(), Valid: true},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete resume points: %w", err)
+	}
+	return int(deleted), nil
 }
 
 func (c *Client) ResumePoint(ctx context.Context, id string) (*model.ResumePoint, error) {
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kitecloud/kite/kite-service/internal/config"
+	"github.com/kitecloud/kite/kite-service/internal/db/local"
+	"github.com/kitecloud/kite/kite-service/internal/store/storetest"
+	"github.com/stretchr/testify/require"
+)
+
+// skipEnv can be set to skip the tests where the Postgres binaries can't be downloaded.
+const skipEnv = "KITE_SKIP_POSTGRES_TESTS"
+
+// testStore combines the client with the asset store which is a separate store because it needs an object store.
+type testStore struct {
+	*Client
+	*AssetStore
+}
+
+// TestStore runs the store tests against an embedded Postgres server that only lives for the duration of the test.
+// Every test gets its own freshly migrated database.
+func TestStore(t *testing.T) {
+	if os.Getenv(skipEnv) != "" {
+		t.Skipf("%s is set", skipEnv)
+	}
+
+	port := freePort(t)
+	runtimeDir := t.TempDir()
+
+	server := embeddedpostgres.NewDatabase(embeddedpostgres.DefaultConfig().
+		Port(port).
+		RuntimePath(runtimeDir).
+		DataPath(filepath.Join(runtimeDir, "data")).
+		Logger(nil))
+	// The Postgres binaries are downloaded on first use and cached afterwards.
+	require.NoError(t, server.Start(), "set %s to skip the postgres tests", skipEnv)
+	t.Cleanup(func() {
+		require.NoError(t, server.Stop())
+	})
+
+	baseDSN := fmt.Sprintf("host=localhost port=%d user=postgres password=postgres sslmode=disable", port)
+
+	admin, err := pgxpool.New(context.Background(), baseDSN+" dbname=postgres")
+	require.NoError(t, err)
+	t.Cleanup(admin.Close)
+
+	databaseCount := 0
+
+	storetest.Run(t, func(t *testing.T) storetest.Store {
+		databaseCount++
+		dbName := fmt.Sprintf("kite_test_%d", databaseCount)
+
+		_, err := admin.Exec(context.Background(), "CREATE DATABASE "+dbName)
+		require.NoError(t, err)
+
+		c, err := New(baseDSN+" dbname="+dbName, 10)
+		require.NoError(t, err)
+		t.Cleanup(c.DB.Close)
+
+		migrater, err := c.GetMigrater()
+		require.NoError(t, err)
+		defer migrater.Close()
+
+		require.NoError(t, migrater.Up())
+
+		objectStore, err := local.New(config.LocalObjectConfig{Path: t.TempDir()})
+		require.NoError(t, err)
+
+		assetStore, err := NewAssetStore(context.Background(), c, objectStore)
+		require.NoError(t, err)
+
+		return testStore{Client: c, AssetStore: assetStore}
+	})
+}
+
+func freePort(t *testing.T) uint32 {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	return uint32(listener.Addr().(*net.TCPAddr).Port)
+}
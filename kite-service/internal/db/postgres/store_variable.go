@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -17,14 +18,36 @@ import (
 )
 
 func (c *Client) VariablesByApp(ctx context.Context, appID string) ([]*model.Variable, error) {
-	rows, err := c.Q.GetVariablesByApp(ctx, appID)
+	rows, err := c.Q.GetVariablesByApp(ctx, pgtype.Text{String: appID, Valid: true})
 	if err != nil {
 		return nil, err
 	}
 
 	variables := make([]*model.Variable, len(rows))
 	for i, row := range rows {
-		v := rowToVariable(row.Variable)
+		v, err := rowToVariable(row.Variable)
+		if err != nil {
+			return nil, err
+		}
+		v.TotalValues = null.NewInt(row.TotalValues, true)
+		variables[i] = v
+	}
+
+	return variables, nil
+}
+
+func (c *Client) VariablesByNamespace(ctx context.Context, namespaceID string) ([]*model.Variable, error) {
+	rows, err := c.Q.GetVariablesByNamespace(ctx, pgtype.Text{String: namespaceID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	variables := make([]*model.Variable, len(rows))
+	for i, row := range rows {
+		v, err := rowToVariable(row.Variable)
+		if err != nil {
+			return nil, err
+		}
 		v.TotalValues = null.NewInt(row.TotalValues, true)
 		variables[i] = v
 	}
@@ -33,7 +56,7 @@ func (c *Client) VariablesByApp(ctx context.Context, appID string) ([]*model.Var
 }
 
 func (c *Client) CountVariablesByApp(ctx context.Context, appID string) (int, error) {
-	res, err := c.Q.CountVariablesByApp(ctx, appID)
+	res, err := c.Q.CountVariablesByApp(ctx, pgtype.Text{String: appID, Valid: true})
 	if err != nil {
 		return 0, err
 	}
@@ -49,14 +72,29 @@ func (c *Client) Variable(ctx context.Context, id string) (*model.Variable, erro
 		return nil, err
 	}
 
-	v := rowToVariable(row.Variable)
+	v, err := rowToVariable(row.Variable)
+	if err != nil {
+		return nil, err
+	}
 	v.TotalValues = null.NewInt(row.TotalValues, true)
 	return v, nil
 }
 
+func (c *Client) VariableDefinition(ctx context.Context, id string) (*model.Variable, error) {
+	row, err := c.Q.GetVariableDefinition(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return rowToVariable(row)
+}
+
 func (c *Client) VariableByName(ctx context.Context, appID, name string) (*model.Variable, error) {
 	row, err := c.Q.GetVariableByName(ctx, pgmodel.GetVariableByNameParams{
-		AppID: appID,
+		AppID: pgtype.Text{String: appID, Valid: true},
 		Name:  name,
 	})
 	if err != nil {
@@ -66,17 +104,28 @@ func (c *Client) VariableByName(ctx context.Context, appID, name string) (*model
 		return nil, err
 	}
 
-	v := rowToVariable(row.Variable)
+	v, err := rowToVariable(row.Variable)
+	if err != nil {
+		return nil, err
+	}
 	v.TotalValues = null.NewInt(row.TotalValues, true)
 	return v, nil
 }
 
 func (c *Client) CreateVariable(ctx context.Context, variable *model.Variable) (*model.Variable, error) {
+	schema, defaultValue, err := marshalVariableDefinition(variable)
+	if err != nil {
+		return nil, err
+	}
+
 	row, err := c.Q.CreateVariable(ctx, pgmodel.CreateVariableParams{
 		ID:     variable.ID,
 		Name:   variable.Name,
 		Scoped: variable.Scoped,
-		AppID:  variable.AppID,
+		AppID: pgtype.Text{
+			String: variable.AppID,
+			Valid:  variable.AppID != "",
+		},
 		ModuleID: pgtype.Text{
 			String: variable.ModuleID.String,
 			Valid:  variable.ModuleID.Valid,
@@ -89,15 +138,28 @@ func (c *Client) CreateVariable(ctx context.Context, variable *model.Variable) (
 			Time:  variable.UpdatedAt.UTC(),
 			Valid: true,
 		},
+		Type:            string(variable.Type),
+		Schema:          schema,
+		DefaultValue:    defaultValue,
+		ValueTtlSeconds: int32(variable.ValueTTL.Seconds()),
+		NamespaceID: pgtype.Text{
+			String: variable.NamespaceID.String,
+			Valid:  variable.NamespaceID.Valid,
+		},
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return rowToVariable(row), nil
+	return rowToVariable(row)
 }
 
 func (c *Client) UpdateVariable(ctx context.Context, variable *model.Variable) (*model.Variable, error) {
+	schema, defaultValue, err := marshalVariableDefinition(variable)
+	if err != nil {
+		return nil, err
+	}
+
 	row, err := c.Q.UpdateVariable(ctx, pgmodel.UpdateVariableParams{
 		ID:     variable.ID,
 		Name:   variable.Name,
@@ -106,6 +168,10 @@ func (c *Client) UpdateVariable(ctx context.Context, variable *model.Variable) (
 			Time:  variable.UpdatedAt.UTC(),
 			Valid: true,
 		},
+		Type:            string(variable.Type),
+		Schema:          schema,
+		DefaultValue:    defaultValue,
+		ValueTtlSeconds: int32(variable.ValueTTL.Seconds()),
 	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -114,7 +180,7 @@ func (c *Client) UpdateVariable(ctx context.Context, variable *model.Variable) (
 		return nil, err
 	}
 
-	return rowToVariable(row), nil
+	return rowToVariable(row)
 }
 
 func (c *Client) DeleteVariable(ctx context.Context, id string) error {
@@ -129,20 +195,59 @@ func (c *Client) DeleteVariable(ctx context.Context, id string) error {
 	return nil
 }
 
-func rowToVariable(row pgmodel.Variable) *model.Variable {
-	return &model.Variable{
-		ID:        row.ID,
-		Name:      row.Name,
-		Scoped:    row.Scoped,
-		AppID:     row.AppID,
-		ModuleID:  null.NewString(row.ModuleID.String, row.ModuleID.Valid),
-		CreatedAt: row.CreatedAt.Time,
-		UpdatedAt: row.UpdatedAt.Time,
+func marshalVariableDefinition(variable *model.Variable) (schema []byte, defaultValue []byte, err error) {
+	if variable.Schema != nil {
+		schema, err = json.Marshal(variable.Schema)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal variable schema: %w", err)
+		}
 	}
+
+	if !variable.DefaultValue.IsNil() {
+		defaultValue, err = json.Marshal(variable.DefaultValue)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal variable default value: %w", err)
+		}
+	}
+
+	return schema, defaultValue, nil
+}
+
+func rowToVariable(row pgmodel.Variable) (*model.Variable, error) {
+	v := &model.Variable{
+		ID:           row.ID,
+		Name:         row.Name,
+		Scoped:       row.Scoped,
+		AppID:        row.AppID.String,
+		NamespaceID:  null.NewString(row.NamespaceID.String, row.NamespaceID.Valid),
+		ModuleID:     null.NewString(row.ModuleID.String, row.ModuleID.Valid),
+		Type:         thing.Type(row.Type),
+		DefaultValue: thing.Null,
+		ValueTTL:     time.Duration(row.ValueTtlSeconds) * time.Second,
+		CreatedAt:    row.CreatedAt.Time,
+		UpdatedAt:    row.UpdatedAt.Time,
+	}
+
+	if len(row.Schema) != 0 {
+		if err := json.Unmarshal(row.Schema, &v.Schema); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal variable schema: %w", err)
+		}
+	}
+
+	if len(row.DefaultValue) != 0 {
+		if err := json.Unmarshal(row.DefaultValue, &v.DefaultValue); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal variable default value: %w", err)
+		}
+	}
+
+	return v, nil
 }
 
 func (c *Client) VariableValues(ctx context.Context, variableID string) ([]*model.VariableValue, error) {
-	rows, err := c.Q.GetVariableValues(ctx, variableID)
+	rows, err := c.Q.GetVariableValues(ctx, pgmodel.GetVariableValuesParams{
+		VariableID: variableID,
+		Now:        pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -163,6 +268,7 @@ func (c *Client) VariableValue(ctx context.Context, variableID string, scope nul
 	row, err := c.Q.GetVariableValue(ctx, pgmodel.GetVariableValueParams{
 		VariableID: variableID,
 		Scope:      pgtype.Text{String: scope.String, Valid: scope.Valid},
+		Now:        pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
 	})
 
 	if err != nil {
@@ -180,13 +286,83 @@ func (c *Client) VariableValue(ctx context.Context, variableID string, scope nul
 	return &v, nil
 }
 
+// VariableValuesByQuery uses a separate query for each order, so each of them can use an index.
+func (c *Client) VariableValuesByQuery(ctx context.Context, variableID string, query model.VariableValueQuery) ([]*model.VariableValue, error) {
+	params := pgmodel.GetVariableValuesByScopeParams{
+		VariableID:  variableID,
+		ScopePrefix: query.ScopePrefix,
+		Now:         pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+		LimitCount:  int32(query.Limit),
+		OffsetCount: int32(query.Offset),
+	}
+
+	var rows []pgmodel.VariableValue
+	var err error
+	switch query.Order {
+	case provider.VariableQueryOrderValueDesc:
+		rows, err = c.Q.GetVariableValuesByNumber(ctx, pgmodel.GetVariableValuesByNumberParams(params))
+	case provider.VariableQueryOrderValueAsc:
+		rows, err = c.Q.GetVariableValuesByNumberAsc(ctx, pgmodel.GetVariableValuesByNumberAscParams(params))
+	case provider.VariableQueryOrderScopeDesc:
+		rows, err = c.Q.GetVariableValuesByScopeDesc(ctx, pgmodel.GetVariableValuesByScopeDescParams(params))
+	default:
+		rows, err = c.Q.GetVariableValuesByScope(ctx, params)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]*model.VariableValue, len(rows))
+	for i, row := range rows {
+		v, err := rowToVariableValue(row)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = &v
+	}
+
+	return values, nil
+}
+
+func (c *Client) AggregateVariableValues(ctx context.Context, variableID string, scopePrefix string) (*model.VariableValueAggregate, error) {
+	row, err := c.Q.AggregateVariableValues(ctx, pgmodel.AggregateVariableValuesParams{
+		VariableID:  variableID,
+		ScopePrefix: scopePrefix,
+		Now:         pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.VariableValueAggregate{
+		Count:   int(row.TotalCount),
+		Sum:     row.TotalSum,
+		Average: null.NewFloat(row.Average.Float64, row.Average.Valid),
+		Min:     null.NewFloat(row.MinValue.Float64, row.MinValue.Valid),
+		Max:     null.NewFloat(row.MaxValue.Float64, row.MaxValue.Valid),
+	}, nil
+}
+
 func (c *Client) SetVariableValue(ctx context.Context, value model.VariableValue) error {
 	_, err := c.setVariableValueWithTx(ctx, nil, value)
 	return err
 }
 
-func (c *Client) UpdateVariableValue(ctx context.Context, operation model.VariableValueOperation, value model.VariableValue) (*model.VariableValue, error) {
-	if operation == provider.VariableOperationOverwrite {
+// UpdateVariableValue applies the operation in a single transaction that locks the value,
+// so concurrent updates of the same value are applied one after another and none get lost.
+func (c *Client) UpdateVariableValue(
+	ctx context.Context,
+	operation model.VariableValueOperation,
+	args model.VariableValueOperationArgs,
+	value model.VariableValue,
+) (*model.VariableValue, error) {
+	if operation.IsOverwrite() {
+		data, _, err := operation.Apply(nil, value.Data, args)
+		if err != nil {
+			return nil, err
+		}
+
+		value.Data = data
 		return c.setVariableValueWithTx(ctx, nil, value)
 	}
 
@@ -197,25 +373,64 @@ func (c *Client) UpdateVariableValue(ctx context.Context, operation model.Variab
 	defer tx.Rollback(ctx)
 
 	currentValue, err := c.variableValueWithTx(ctx, tx, value.VariableID, value.Scope)
-	if err != nil {
-		if errors.Is(err, store.ErrNotFound) {
-			// Current trasaction is rolled back, we set the value outside of the transaction
-			return c.setVariableValueWithTx(ctx, nil, value)
-		}
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
 		return nil, fmt.Errorf("failed to get current variable value: %w", err)
 	}
 
-	switch operation {
-	case provider.VariableOperationAppend:
-		value.Data = currentValue.Data.Append(value.Data)
-	case provider.VariableOperationPrepend:
-		value.Data = value.Data.Append(currentValue.Data)
-	case provider.VariableOperationIncrement:
-		value.Data = currentValue.Data.Add(value.Data)
-	case provider.VariableOperationDecrement:
-		value.Data = currentValue.Data.Sub(value.Data)
+	if currentValue == nil {
+		newData, ok, err := operation.Apply(nil, value.Data, args)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			value.Data = thing.Null
+			return &value, nil
+		}
+
+		data, err := json.Marshal(newData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal variable value: %w", err)
+		}
+
+		inserted, err := c.Q.WithTx(tx).InsertVariableValueIfNotExists(ctx, pgmodel.InsertVariableValueIfNotExistsParams{
+			VariableID: value.VariableID,
+			Scope:      pgtype.Text{String: value.Scope.String, Valid: value.Scope.Valid},
+			Value:      data,
+			CreatedAt:  pgtype.Timestamp{Time: value.CreatedAt, Valid: true},
+			UpdatedAt:  pgtype.Timestamp{Time: value.UpdatedAt, Valid: true},
+			ExpiresAt:  pgtype.Timestamp{Time: value.ExpiresAt.Time.UTC(), Valid: value.ExpiresAt.Valid},
+			Now:        pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert variable value: %w", err)
+		}
+
+		if inserted != 0 {
+			err = tx.Commit(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to commit transaction: %w", err)
+			}
+
+			value.Data = newData
+			return &value, nil
+		}
+
+		// The value has been created concurrently, so we apply the operation to it instead
+		currentValue, err = c.variableValueWithTx(ctx, tx, value.VariableID, value.Scope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current variable value: %w", err)
+		}
+	}
+
+	newData, ok, err := operation.Apply(&currentValue.Data, value.Data, args)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return currentValue, nil
 	}
 
+	value.Data = newData
 	newValue, err := c.setVariableValueWithTx(ctx, tx, value)
 	if err != nil {
 		return nil, fmt.Errorf("failed to set variable value: %w", err)
@@ -253,6 +468,18 @@ func (c *Client) DeleteAllVariableValues(ctx context.Context, variableID string)
 	return nil
 }
 
+func (c *Client) DeleteExpiredVariableValues(ctx context.Context, now time.Time) (int, error) {
+	deleted, err := c.Q.DeleteExpiredVariableValues(ctx, pgtype.Timestamp{
+		Time:  now.UTC(),
+		Valid: true,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(deleted), nil
+}
+
 func (c *Client) variableValueWithTx(ctx context.Context, tx pgx.Tx, variableID string, scope null.String) (*model.VariableValue, error) {
 	q := c.Q
 	if tx != nil {
@@ -271,6 +498,11 @@ func (c *Client) variableValueWithTx(ctx context.Context, tx pgx.Tx, variableID
 		return nil, err
 	}
 
+	// The row is locked even when it has expired, so the caller can overwrite it
+	if row.ExpiresAt.Valid && !row.ExpiresAt.Time.After(time.Now().UTC()) {
+		return nil, store.ErrNotFound
+	}
+
 	v, err := rowToVariableValue(row)
 	if err != nil {
 		return nil, err
@@ -296,6 +528,7 @@ func (c *Client) setVariableValueWithTx(ctx context.Context, tx pgx.Tx, value mo
 		Value:      data,
 		CreatedAt:  pgtype.Timestamp{Time: value.CreatedAt, Valid: true},
 		UpdatedAt:  pgtype.Timestamp{Time: value.UpdatedAt, Valid: true},
+		ExpiresAt:  pgtype.Timestamp{Time: value.ExpiresAt.Time.UTC(), Valid: value.ExpiresAt.Valid},
 	})
 	if err != nil {
 		return nil, err
@@ -323,5 +556,6 @@ func rowToVariableValue(row pgmodel.VariableValue) (model.VariableValue, error)
 		Data:       data,
 		CreatedAt:  row.CreatedAt.Time,
 		UpdatedAt:  row.UpdatedAt.Time,
+		ExpiresAt:  null.NewTime(row.ExpiresAt.Time, row.ExpiresAt.Valid),
 	}, nil
 }
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kitecloud/kite/kite-service/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	name string
+	time time.Time
+}
+
+// queryTracer records the duration of every query that is executed by the pool
+// and creates a span for it when the query is part of a trace.
+type queryTracer struct{}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	name := queryName(data.SQL)
+
+	// Background queries would otherwise each start their own trace
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		ctx, _ = tracing.Tracer().Start(ctx, "postgres."+name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", "postgresql"),
+				attribute.String("db.operation.name", name),
+			),
+		)
+	}
+
+	return context.WithValue(ctx, queryStartKey{}, queryStart{
+		name: name,
+		time: time.Now(),
+	})
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	// This is a no-op span if no span was started for the query
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+
+	outcome := "success"
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		outcome = "error"
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, "query failed")
+	}
+
+	queryDuration.WithLabelValues(start.name, outcome).Observe(time.Since(start.time).Seconds())
+}
@@ -0,0 +1,85 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/config"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/redis/go-redis/v9"
+)
+
+type Client struct {
+	client    redis.UniversalClient
+	keyPrefix string
+}
+
+func New(cfg config.RedisConfig) (*Client, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Address,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to ping redis: %w", err)
+	}
+
+	return &Client{
+		client:    client,
+		keyPrefix: cfg.KeyPrefix,
+	}, nil
+}
+
+func (c *Client) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
+func (c *Client) Close() error {
+	return c.client.Close()
+}
+
+func (c *Client) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.client.Get(ctx, c.keyPrefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get key %s: %w", key, err)
+	}
+
+	return value, nil
+}
+
+func (c *Client) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	err := c.client.Set(ctx, c.keyPrefix+key, value, ttl).Err()
+	if err != nil {
+		return fmt.Errorf("failed to set key %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (c *Client) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.keyPrefix + key
+	}
+
+	err := c.client.Del(ctx, prefixed...).Err()
+	if err != nil {
+		return fmt.Errorf("failed to delete keys: %w", err)
+	}
+
+	return nil
+}
@@ -65,3 +65,9 @@ func New(cfg config.S3Config) (*Client, error) {
 		encryption: encryption,
 	}, nil
 }
+
+// Ping checks if S3 is reachable by checking that the backup bucket exists.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.client.BucketExists(ctx, dbBackupBucket)
+	return err
+}
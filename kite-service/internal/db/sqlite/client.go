@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	"github.com/kitecloud/kite/kite-service/internal/config"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type Client struct {
+	DB   *sql.DB
+	path string
+}
+
+func New(cfg config.SQLiteConfig) (*Client, error) {
+	db, err := sql.Open("sqlite3", BuildConnectionDSN(cfg.Path))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open sqlite db: %v", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("Failed to connect to sqlite db: %v", err)
+	}
+
+	return &Client{
+		DB:   db,
+		path: cfg.Path,
+	}, nil
+}
+
+// Ping checks if the database is reachable.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.DB.PingContext(ctx)
+}
+
+func (c *Client) Close() error {
+	return c.DB.Close()
+}
+
+// BuildConnectionDSN enables WAL mode and foreign keys.
+// Transactions take the write lock immediately so concurrent read-modify-write transactions wait for each other instead of failing.
+func BuildConnectionDSN(path string) string {
+	params := url.Values{}
+	params.Set("_journal_mode", "WAL")
+	params.Set("_busy_timeout", "5000")
+	params.Set("_foreign_keys", "on")
+	params.Set("_txlock", "immediate")
+
+	return "file:" + path + "?" + params.Encode()
+}
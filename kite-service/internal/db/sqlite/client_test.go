@@ -0,0 +1,172 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/kitecloud/kite/kite-service/internal/config"
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func testClient(t *testing.T) *Client {
+	c, err := New(config.SQLiteConfig{
+		Path: filepath.Join(t.TempDir(), "kite.db"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+
+	migrater, err := c.GetMigrater()
+	require.NoError(t, err)
+	defer migrater.Close()
+
+	require.NoError(t, migrater.Up())
+	return c
+}
+
+func testVariable(t *testing.T, c *Client) *model.Variable {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	user, err := c.UpsertUser(ctx, &model.User{
+		ID:              "user",
+		Email:           "user@example.com",
+		DisplayName:     "User",
+		DiscordID:       "1",
+		DiscordUsername: "user",
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	})
+	require.NoError(t, err)
+
+	app, err := c.CreateApp(ctx, &model.App{
+		ID:            "app",
+		Name:          "App",
+		Enabled:       true,
+		OwnerUserID:   user.ID,
+		CreatorUserID: user.ID,
+		DiscordToken:  "token",
+		DiscordID:     "2",
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	require.NoError(t, err)
+
+	variable, err := c.CreateVariable(ctx, &model.Variable{
+		ID:           "variable",
+		Name:         "counter",
+		Scoped:       true,
+		AppID:        app.ID,
+		Type:         thing.TypeAny,
+		DefaultValue: thing.Null,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	})
+	require.NoError(t, err)
+
+	return variable
+}
+
+func TestMigrations(t *testing.T) {
+	c := testClient(t)
+
+	migrater, err := c.GetMigrater()
+	require.NoError(t, err)
+	defer migrater.Close()
+
+	require.NoError(t, migrater.Down())
+	require.NoError(t, migrater.Up())
+
+	err = migrater.Up()
+	assert.True(t, errors.Is(err, migrate.ErrNoChange))
+}
+
+func TestUserAndApp(t *testing.T) {
+	c := testClient(t)
+	ctx := context.Background()
+
+	variable := testVariable(t, c)
+
+	user, err := c.UserByDiscordID(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "user", user.ID)
+
+	apps, err := c.AppsByUser(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	assert.Equal(t, variable.AppID, apps[0].ID)
+
+	_, err = c.App(ctx, "missing")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func TestVariableValueNullScope(t *testing.T) {
+	c := testClient(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	variable := testVariable(t, c)
+
+	for _, data := range []thing.Thing{thing.NewString("first"), thing.NewString("second")} {
+		err := c.SetVariableValue(ctx, model.VariableValue{
+			VariableID: variable.ID,
+			Data:       data,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+		require.NoError(t, err)
+	}
+
+	value, err := c.VariableValue(ctx, variable.ID, null.String{})
+	require.NoError(t, err)
+	assert.Equal(t, "second", value.Data.String())
+
+	values, err := c.VariableValues(ctx, variable.ID)
+	require.NoError(t, err)
+	assert.Len(t, values, 1)
+
+	require.NoError(t, c.DeleteVariableValue(ctx, variable.ID, null.String{}))
+
+	_, err = c.VariableValue(ctx, variable.ID, null.String{})
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func TestUpdateVariableValueConcurrent(t *testing.T) {
+	c := testClient(t)
+	ctx := context.Background()
+
+	variable := testVariable(t, c)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			now := time.Now().UTC()
+			_, err := c.UpdateVariableValue(ctx, provider.VariableOperationIncrement, model.VariableValueOperationArgs{}, model.VariableValue{
+				VariableID: variable.ID,
+				Scope:      null.StringFrom("scope"),
+				Data:       thing.NewInt(1),
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	value, err := c.VariableValue(ctx, variable.ID, null.StringFrom("scope"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), value.Data.Int())
+}
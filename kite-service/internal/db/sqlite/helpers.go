@@ -0,0 +1,126 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// timestampFormat has a fixed width so timestamps can be compared as strings.
+// The columns are declared as TIMESTAMP which makes the driver parse them back into time.Time.
+const timestampFormat = "2006-01-02 15:04:05.000000"
+
+// thingNumber extracts the number of a thing stored in the value column, it's NULL for all other types.
+const thingNumber = "CASE WHEN json_type(value, '$.v') IN ('integer', 'real') THEN json_extract(value, '$.v') END"
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(timestampFormat)
+}
+
+func nullTimestamp(t null.Time) any {
+	if !t.Valid {
+		return nil
+	}
+	return timestamp(t.Time)
+}
+
+func nullString(s null.String) any {
+	if !s.Valid {
+		return nil
+	}
+	return s.String
+}
+
+func marshalJSON(v any) (string, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+
+// jsonEqual compares two JSON documents semantically, ignoring whitespace and key order like jsonb equality in Postgres.
+func jsonEqual(a, b []byte) bool {
+	var va, vb any
+	if err := json.Unmarshal(a, &va); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// queryRows runs the query and scans every row with the given function.
+func queryRows[T any](ctx context.Context, db querier, scan func(rowScanner) (T, error), query string, args ...any) ([]T, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []T{}
+	for rows.Next() {
+		v, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func queryCount(ctx context.Context, db querier, query string, args ...any) (int, error) {
+	var count int
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func execAffected(ctx context.Context, db querier, query string, args ...any) (int, error) {
+	res, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+// querier is implemented by *sql.DB and *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (c *Client) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := c.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
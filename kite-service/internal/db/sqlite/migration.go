@@ -0,0 +1,98 @@
+package sqlite
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+type Migrater struct {
+	m     *migrate.Migrate
+	close func() error
+}
+
+func (mig *Migrater) Up() error {
+	return mig.m.Up()
+}
+
+func (mig *Migrater) Down() error {
+	return mig.m.Down()
+}
+
+func (mig *Migrater) Version() (uint, bool, error) {
+	return mig.m.Version()
+}
+
+func (mig *Migrater) To(version uint) error {
+	return mig.m.Migrate(version)
+}
+
+func (mig *Migrater) Force(version int) error {
+	return mig.m.Force(version)
+}
+
+func (mig *Migrater) List() ([]string, error) {
+	dirEntries, err := migrationsFS.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	migrationFiles := make([]string, 0)
+	for _, entry := range dirEntries {
+		migrationFiles = append(migrationFiles, entry.Name())
+	}
+	return migrationFiles, nil
+}
+
+func (mig *Migrater) Close() error {
+	return mig.close()
+}
+
+func (mig *Migrater) SetLogger(logger migrate.Logger) {
+	mig.m.Log = logger
+}
+
+func (c *Client) GetMigrater() (*Migrater, error) {
+	d, err := iofs.New(migrationsFS, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite migrations iofs: %w", err)
+	}
+
+	// The driver closes the database when the migrater is closed, so it gets its own connection pool
+	db, err := sql.Open("sqlite3", BuildConnectionDSN(c.path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite db: %w", err)
+	}
+
+	driver, err := sqlite3.WithInstance(db, &sqlite3.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite migration: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance(
+		"iofs", d,
+		"sqlite3", driver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SQLite migrate instance: %w", err)
+	}
+
+	close := func() error {
+		err1, err2 := m.Close()
+		if err1 != nil || err2 != nil {
+			return fmt.Errorf("source close error: %v, driver close error: %v", err1, err2)
+		}
+		return nil
+	}
+
+	return &Migrater{
+		m:     m,
+		close: close,
+	}, nil
+}
@@ -0,0 +1,22 @@
+DROP TABLE IF EXISTS cooldowns;
+DROP TABLE IF EXISTS plugin_values;
+DROP TABLE IF EXISTS plugin_instances;
+DROP TABLE IF EXISTS entitlements;
+DROP TABLE IF EXISTS subscriptions;
+DROP TABLE IF EXISTS resume_points;
+DROP TABLE IF EXISTS usage_records;
+DROP TABLE IF EXISTS logs;
+DROP TABLE IF EXISTS event_listeners;
+DROP TABLE IF EXISTS assets;
+DROP TABLE IF EXISTS message_instances;
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS variable_values;
+DROP TABLE IF EXISTS variables;
+DROP TABLE IF EXISTS namespace_grants;
+DROP TABLE IF EXISTS namespaces;
+DROP TABLE IF EXISTS commands;
+DROP TABLE IF EXISTS modules;
+DROP TABLE IF EXISTS collaborators;
+DROP TABLE IF EXISTS apps;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,394 @@
+-- SQLite has no separate types for JSON and timestamps.
+-- JSON is stored as TEXT, timestamps as TEXT in the format "YYYY-MM-DD HH:MM:SS.SSSSSS" (UTC).
+-- The schema matches the Postgres schema after migration 030.
+
+CREATE TABLE IF NOT EXISTS users (
+    id TEXT PRIMARY KEY,
+    email TEXT NOT NULL UNIQUE,
+    display_name TEXT NOT NULL,
+
+    discord_id TEXT NOT NULL UNIQUE,
+    discord_username TEXT NOT NULL,
+    discord_avatar TEXT,
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    key_hash TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS apps (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    disabled_reason TEXT,
+
+    owner_user_id TEXT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    creator_user_id TEXT NOT NULL,
+
+    discord_token TEXT NOT NULL,
+    discord_id TEXT NOT NULL UNIQUE,
+    discord_status TEXT,
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS apps_owner_user_id ON apps (owner_user_id);
+
+CREATE TABLE IF NOT EXISTS collaborators (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    app_id TEXT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    role TEXT NOT NULL,
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (user_id, app_id)
+);
+
+CREATE INDEX IF NOT EXISTS collaborators_user_id ON collaborators (user_id);
+CREATE INDEX IF NOT EXISTS collaborators_app_id ON collaborators (app_id);
+
+CREATE TABLE IF NOT EXISTS modules (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+
+    app_id TEXT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    creator_user_id TEXT NOT NULL,
+
+    resources TEXT NOT NULL,
+    wasm_bytes BLOB NOT NULL DEFAULT '',
+    wasm_hash TEXT NOT NULL DEFAULT '',
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS modules_app_id ON modules (app_id);
+
+CREATE TABLE IF NOT EXISTS commands (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+
+    app_id TEXT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    module_id TEXT REFERENCES modules(id) ON DELETE SET NULL,
+    creator_user_id TEXT NOT NULL,
+
+    flow_source TEXT NOT NULL,
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    last_deployed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS commands_app_id ON commands (app_id);
+CREATE INDEX IF NOT EXISTS commands_module_id ON commands (module_id);
+
+CREATE TABLE IF NOT EXISTS namespaces (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT,
+    owner_user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS namespaces_owner_user_id ON namespaces (owner_user_id);
+
+CREATE TABLE IF NOT EXISTS namespace_grants (
+    namespace_id TEXT NOT NULL REFERENCES namespaces(id) ON DELETE CASCADE,
+    app_id TEXT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    access TEXT NOT NULL,
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (namespace_id, app_id)
+);
+
+CREATE INDEX IF NOT EXISTS namespace_grants_app_id ON namespace_grants (app_id);
+
+CREATE TABLE IF NOT EXISTS variables (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    scoped BOOLEAN NOT NULL DEFAULT FALSE,
+    type TEXT NOT NULL DEFAULT 'any',
+    schema TEXT,
+    default_value TEXT,
+    value_ttl_seconds INTEGER NOT NULL DEFAULT 0,
+
+    -- Variables are either owned by an app or by a namespace
+    app_id TEXT REFERENCES apps(id) ON DELETE CASCADE,
+    namespace_id TEXT REFERENCES namespaces(id) ON DELETE CASCADE,
+    module_id TEXT REFERENCES modules(id) ON DELETE SET NULL,
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+
+    UNIQUE (app_id, name),
+    UNIQUE (namespace_id, name),
+    CHECK ((app_id IS NULL) <> (namespace_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS variables_app_id ON variables (app_id);
+CREATE INDEX IF NOT EXISTS variables_namespace_id ON variables (namespace_id);
+CREATE INDEX IF NOT EXISTS variables_module_id ON variables (module_id);
+
+CREATE TABLE IF NOT EXISTS variable_values (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    variable_id TEXT NOT NULL REFERENCES variables(id) ON DELETE CASCADE,
+    scope TEXT, -- resolved guild, user, member, channel id, or custom
+    value TEXT NOT NULL,
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP
+);
+
+-- SQLite treats NULLs as distinct in unique constraints, so the unscoped value needs its own index
+CREATE UNIQUE INDEX IF NOT EXISTS variable_values_variable_id_scope ON variable_values (variable_id, scope) WHERE scope IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS variable_values_variable_id_unscoped ON variable_values (variable_id) WHERE scope IS NULL;
+CREATE INDEX IF NOT EXISTS variable_values_expires_at ON variable_values (expires_at);
+
+CREATE TABLE IF NOT EXISTS messages (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT,
+
+    data TEXT NOT NULL, -- message data
+    flow_sources TEXT NOT NULL, -- map of flow source ids to flow source objects
+
+    app_id TEXT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    module_id TEXT REFERENCES modules(id) ON DELETE SET NULL,
+    creator_user_id TEXT NOT NULL,
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS messages_app_id ON messages (app_id);
+CREATE INDEX IF NOT EXISTS messages_module_id ON messages (module_id);
+
+CREATE TABLE IF NOT EXISTS message_instances (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    message_id TEXT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+
+    hidden BOOLEAN NOT NULL DEFAULT FALSE,
+    ephemeral BOOLEAN NOT NULL DEFAULT FALSE,
+
+    discord_guild_id TEXT NOT NULL,
+    discord_channel_id TEXT NOT NULL,
+    discord_message_id TEXT NOT NULL UNIQUE,
+
+    flow_sources TEXT NOT NULL, -- snapshot from the message when sent
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS message_instances_message_id ON message_instances (message_id);
+
+CREATE TABLE IF NOT EXISTS assets (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    content_hash TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    content_size INTEGER NOT NULL,
+
+    app_id TEXT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    module_id TEXT REFERENCES modules(id) ON DELETE SET NULL,
+    creator_user_id TEXT NOT NULL,
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS assets_app_id ON assets (app_id);
+CREATE INDEX IF NOT EXISTS assets_module_id ON assets (module_id);
+CREATE INDEX IF NOT EXISTS assets_content_hash ON assets (content_hash);
+
+CREATE TABLE IF NOT EXISTS event_listeners (
+    id TEXT PRIMARY KEY,
+    source TEXT NOT NULL,
+    type TEXT NOT NULL,
+    description TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+
+    app_id TEXT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    module_id TEXT REFERENCES modules(id) ON DELETE SET NULL,
+    creator_user_id TEXT NOT NULL,
+
+    filter TEXT,
+    flow_source TEXT NOT NULL,
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS event_listeners_app_id ON event_listeners (app_id);
+CREATE INDEX IF NOT EXISTS event_listeners_module_id ON event_listeners (module_id);
+
+CREATE TABLE IF NOT EXISTS logs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    app_id TEXT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+
+    message TEXT NOT NULL,
+    level TEXT NOT NULL,
+
+    command_id TEXT REFERENCES commands(id) ON DELETE SET NULL,
+    event_listener_id TEXT REFERENCES event_listeners(id) ON DELETE SET NULL,
+    message_id TEXT REFERENCES messages(id) ON DELETE SET NULL,
+
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS logs_app_id ON logs (app_id);
+CREATE INDEX IF NOT EXISTS logs_command_id ON logs (command_id);
+CREATE INDEX IF NOT EXISTS logs_event_listener_id ON logs (event_listener_id);
+CREATE INDEX IF NOT EXISTS logs_message_id ON logs (message_id);
+
+CREATE TABLE IF NOT EXISTS usage_records (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    type TEXT NOT NULL,
+
+    app_id TEXT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    command_id TEXT REFERENCES commands(id) ON DELETE SET NULL,
+    event_listener_id TEXT REFERENCES event_listeners(id) ON DELETE SET NULL,
+    message_id TEXT REFERENCES messages(id) ON DELETE SET NULL,
+
+    credits_used INTEGER NOT NULL,
+
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS usage_records_app_id ON usage_records (app_id);
+CREATE INDEX IF NOT EXISTS usage_records_command_id ON usage_records (command_id);
+CREATE INDEX IF NOT EXISTS usage_records_event_listener_id ON usage_records (event_listener_id);
+CREATE INDEX IF NOT EXISTS usage_records_message_id ON usage_records (message_id);
+
+CREATE TABLE IF NOT EXISTS resume_points (
+    id TEXT PRIMARY KEY,
+    type TEXT NOT NULL,
+
+    app_id TEXT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    command_id TEXT REFERENCES commands(id) ON DELETE SET NULL,
+    event_listener_id TEXT REFERENCES event_listeners(id) ON DELETE SET NULL,
+    message_id TEXT REFERENCES messages(id) ON DELETE SET NULL,
+    message_instance_id INTEGER REFERENCES message_instances(id) ON DELETE SET NULL,
+
+    flow_source_id TEXT, -- Message templates have multiple flows
+    flow_node_id TEXT NOT NULL,
+    flow_state TEXT NOT NULL,
+
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS resume_points_app_id ON resume_points (app_id);
+CREATE INDEX IF NOT EXISTS resume_points_command_id ON resume_points (command_id);
+CREATE INDEX IF NOT EXISTS resume_points_event_listener_id ON resume_points (event_listener_id);
+CREATE INDEX IF NOT EXISTS resume_points_message_id ON resume_points (message_id);
+CREATE INDEX IF NOT EXISTS resume_points_message_instance_id ON resume_points (message_instance_id);
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id TEXT PRIMARY KEY,
+    display_name TEXT NOT NULL,
+    source TEXT NOT NULL, -- always "lemonsqueezy"
+    status TEXT NOT NULL, -- "on_trial", "active", "paused", "past_due", "unpaid", "canceled", "expired"
+    status_formatted TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    renews_at TIMESTAMP NOT NULL,
+    trial_ends_at TIMESTAMP,
+    ends_at TIMESTAMP,
+
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    lemonsqueezy_subscription_id TEXT UNIQUE,
+    lemonsqueezy_customer_id TEXT,
+    lemonsqueezy_order_id TEXT,
+    lemonsqueezy_product_id TEXT,
+    lemonsqueezy_variant_id TEXT
+);
+
+CREATE INDEX IF NOT EXISTS subscriptions_user_id ON subscriptions (user_id);
+
+CREATE TABLE IF NOT EXISTS entitlements (
+    id TEXT PRIMARY KEY,
+    type TEXT NOT NULL, -- "subscription", "manual"
+
+    subscription_id TEXT REFERENCES subscriptions(id) ON DELETE CASCADE,
+    app_id TEXT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+
+    plan_id TEXT NOT NULL,
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP,
+
+    UNIQUE (subscription_id, app_id)
+);
+
+CREATE INDEX IF NOT EXISTS entitlements_subscription_id ON entitlements (subscription_id);
+CREATE INDEX IF NOT EXISTS entitlements_app_id ON entitlements (app_id);
+
+CREATE TABLE IF NOT EXISTS plugin_instances (
+    id TEXT PRIMARY KEY,
+    plugin_id TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+
+    app_id TEXT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    creator_user_id TEXT NOT NULL,
+
+    config TEXT NOT NULL,
+    enabled_resource_ids TEXT NOT NULL, -- JSON array of strings
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    last_deployed_at TIMESTAMP,
+
+    UNIQUE (plugin_id, app_id)
+);
+
+CREATE INDEX IF NOT EXISTS plugin_instances_app_id ON plugin_instances (app_id);
+CREATE INDEX IF NOT EXISTS plugin_instances_plugin_id ON plugin_instances (plugin_id);
+
+CREATE TABLE IF NOT EXISTS plugin_values (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    plugin_instance_id TEXT NOT NULL REFERENCES plugin_instances(id) ON DELETE CASCADE,
+
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP,
+
+    UNIQUE (plugin_instance_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS plugin_values_expires_at ON plugin_values (expires_at);
+
+CREATE TABLE IF NOT EXISTS cooldowns (
+    key TEXT PRIMARY KEY,
+    app_id TEXT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+
+    count INTEGER NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS cooldowns_app_id ON cooldowns (app_id);
+CREATE INDEX IF NOT EXISTS cooldowns_expires_at ON cooldowns (expires_at);
@@ -0,0 +1,6 @@
+-- Leaderboards sort plugin values by their number and count the values above a number
+CREATE INDEX IF NOT EXISTS plugin_values_plugin_instance_id_number ON plugin_values (
+    plugin_instance_id,
+    (CASE WHEN json_type(value, '$.v') IN ('integer', 'real') THEN json_extract(value, '$.v') END) DESC,
+    key
+);
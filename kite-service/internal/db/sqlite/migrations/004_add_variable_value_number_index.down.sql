@@ -0,0 +1 @@
+DROP INDEX IF EXISTS variable_values_variable_id_number;
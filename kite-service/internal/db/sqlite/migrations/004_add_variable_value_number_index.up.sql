@@ -0,0 +1,6 @@
+-- Variable queries sort scoped values by their number
+CREATE INDEX IF NOT EXISTS variable_values_variable_id_number ON variable_values (
+    variable_id,
+    (CASE WHEN json_type(value, '$.v') IN ('integer', 'real') THEN json_extract(value, '$.v') END) DESC,
+    scope
+);
@@ -0,0 +1,199 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+const appColumns = "id, name, description, enabled, disabled_reason, owner_user_id, creator_user_id, discord_token, discord_id, discord_status, created_at, updated_at"
+
+func (c *Client) AllApps(ctx context.Context) ([]*model.App, error) {
+	return queryRows(ctx, c.DB, scanApp, "SELECT "+appColumns+" FROM apps")
+}
+
+func (c *Client) AppsByUser(ctx context.Context, userID string) ([]*model.App, error) {
+	return queryRows(ctx, c.DB, scanApp, `
+		SELECT `+appColumns+` FROM apps
+		WHERE owner_user_id = ?1 OR id IN (SELECT app_id FROM collaborators WHERE user_id = ?1)
+		ORDER BY created_at DESC`,
+		userID,
+	)
+}
+
+func (c *Client) CountAppsByUser(ctx context.Context, userID string) (int, error) {
+	return queryCount(ctx, c.DB, "SELECT COUNT(*) FROM apps WHERE owner_user_id = ?", userID)
+}
+
+func (c *Client) App(ctx context.Context, id string) (*model.App, error) {
+	app, err := scanApp(c.DB.QueryRowContext(ctx, "SELECT "+appColumns+" FROM apps WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return app, nil
+}
+
+func (c *Client) AppCredentials(ctx context.Context, id string) (*model.AppCredentials, error) {
+	var credentials model.AppCredentials
+	err := c.DB.QueryRowContext(ctx, "SELECT discord_id, discord_token FROM apps WHERE id = ?", id).Scan(
+		&credentials.DiscordID,
+		&credentials.DiscordToken,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &credentials, nil
+}
+
+func (c *Client) CreateApp(ctx context.Context, app *model.App) (*model.App, error) {
+	return scanApp(c.DB.QueryRowContext(ctx, `
+		INSERT INTO apps (id, name, description, owner_user_id, creator_user_id, discord_token, discord_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING `+appColumns,
+		app.ID,
+		app.Name,
+		nullString(app.Description),
+		app.OwnerUserID,
+		app.CreatorUserID,
+		app.DiscordToken,
+		app.DiscordID,
+		timestamp(app.CreatedAt),
+		timestamp(app.UpdatedAt),
+	))
+}
+
+func (c *Client) UpdateApp(ctx context.Context, opts store.AppUpdateOpts) (*model.App, error) {
+	var rawStatus any
+	if opts.DiscordStatus != nil {
+		raw, err := json.Marshal(opts.DiscordStatus)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal discord status: %w", err)
+		}
+		rawStatus = string(raw)
+	}
+
+	app, err := scanApp(c.DB.QueryRowContext(ctx, `
+		UPDATE apps SET
+			name = ?,
+			description = ?,
+			discord_token = ?,
+			discord_status = ?,
+			enabled = ?,
+			disabled_reason = ?,
+			updated_at = ?
+		WHERE id = ?
+		RETURNING `+appColumns,
+		opts.Name,
+		nullString(opts.Description),
+		opts.DiscordToken,
+		rawStatus,
+		opts.Enabled,
+		nullString(opts.DisabledReason),
+		timestamp(opts.UpdatedAt),
+		opts.ID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return app, nil
+}
+
+func (c *Client) DisableApp(ctx context.Context, opts store.AppDisableOpts) error {
+	_, err := c.DB.ExecContext(ctx,
+		"UPDATE apps SET enabled = FALSE, disabled_reason = ?, updated_at = ? WHERE id = ?",
+		nullString(opts.DisabledReason),
+		timestamp(opts.UpdatedAt),
+		opts.ID,
+	)
+	return err
+}
+
+func (c *Client) DeleteApp(ctx context.Context, id string) error {
+	_, err := c.DB.ExecContext(ctx, "DELETE FROM apps WHERE id = ?", id)
+	return err
+}
+
+func (c *Client) EnabledAppIDs(ctx context.Context) ([]string, error) {
+	return queryRows(ctx, c.DB, scanString, "SELECT id FROM apps WHERE enabled = TRUE")
+}
+
+func (c *Client) EnabledAppsUpdatedSince(ctx context.Context, updatedSince time.Time) ([]*model.App, error) {
+	return queryRows(ctx, c.DB, scanApp,
+		"SELECT "+appColumns+" FROM apps WHERE enabled = TRUE AND updated_at > ?",
+		timestamp(updatedSince),
+	)
+}
+
+func (c *Client) AppEntities(ctx context.Context, appID string) ([]*model.AppEntity, error) {
+	return queryRows(ctx, c.DB, func(row rowScanner) (*model.AppEntity, error) {
+		var entity model.AppEntity
+		if err := row.Scan(&entity.ID, &entity.Type, &entity.Name); err != nil {
+			return nil, err
+		}
+		return &entity, nil
+	}, `
+		SELECT id, 'command' AS type, name FROM commands WHERE app_id = ?1
+		UNION ALL
+		SELECT id, 'event_listener' AS type, type AS name FROM event_listeners WHERE app_id = ?1
+		UNION ALL
+		SELECT id, 'message' AS type, name FROM messages WHERE app_id = ?1
+		UNION ALL
+		SELECT id, 'variable' AS type, name FROM variables WHERE app_id = ?1`,
+		appID,
+	)
+}
+
+func scanApp(row rowScanner) (*model.App, error) {
+	var app model.App
+	var rawStatus sql.NullString
+	err := row.Scan(
+		&app.ID,
+		&app.Name,
+		&app.Description,
+		&app.Enabled,
+		&app.DisabledReason,
+		&app.OwnerUserID,
+		&app.CreatorUserID,
+		&app.DiscordToken,
+		&app.DiscordID,
+		&rawStatus,
+		&app.CreatedAt,
+		&app.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if rawStatus.Valid {
+		app.DiscordStatus = &model.AppDiscordStatus{}
+		if err := json.Unmarshal([]byte(rawStatus.String), app.DiscordStatus); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal discord status: %w", err)
+		}
+	}
+
+	return &app, nil
+}
+
+func scanString(row rowScanner) (string, error) {
+	var s string
+	err := row.Scan(&s)
+	return s, err
+}
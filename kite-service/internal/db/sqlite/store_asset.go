@@ -0,0 +1,253 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+const assetBucketName = "kite-assets"
+
+const assetColumns = "id, name, content_hash, content_type, content_size, app_id, module_id, creator_user_id, created_at, updated_at, expires_at"
+
+type AssetStore struct {
+	c           *Client
+	objectStore store.ObjectStore
+}
+
+func NewAssetStore(ctx context.Context, c *Client, objectStore store.ObjectStore) (*AssetStore, error) {
+	store := &AssetStore{c: c, objectStore: objectStore}
+
+	err := objectStore.CreateBucketIfNotExists(ctx, assetBucketName)
+	if err != nil {
+		return store, fmt.Errorf("failed to create asset bucket: %w", err)
+	}
+
+	return store, nil
+}
+
+func (s *AssetStore) CreateAsset(ctx context.Context, asset *model.Asset) (*model.Asset, error) {
+	res, err := scanAsset(s.c.DB.QueryRowContext(ctx,
+		"INSERT INTO assets ("+assetColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING "+assetColumns,
+		asset.ID,
+		asset.Name,
+		asset.ContentHash,
+		asset.ContentType,
+		asset.ContentSize,
+		asset.AppID,
+		nullString(asset.ModuleID),
+		asset.CreatorUserID,
+		timestamp(asset.CreatedAt),
+		timestamp(asset.UpdatedAt),
+		nullTimestamp(asset.ExpiresAt),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create asset: %w", err)
+	}
+
+	err = s.objectStore.UploadObject(ctx, assetBucketName, &model.Object{
+		Name:        asset.ContentHash,
+		Content:     asset.Content,
+		ContentType: asset.ContentType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload asset object: %w", err)
+	}
+
+	return res, nil
+}
+
+func (s *AssetStore) Asset(ctx context.Context, id string) (*model.Asset, error) {
+	asset, err := scanAsset(s.c.DB.QueryRowContext(ctx, "SELECT "+assetColumns+" FROM assets WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return asset, nil
+}
+
+func (s *AssetStore) AssetWithContent(ctx context.Context, id string) (*model.Asset, error) {
+	asset, err := s.Asset(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := s.objectStore.DownloadObject(ctx, assetBucketName, asset.ContentHash)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to download asset object: %w", err)
+	}
+
+	asset.Content = object.Content
+	return asset, nil
+}
+
+func (s *AssetStore) DeleteAsset(ctx context.Context, id string) error {
+	asset, err := scanAsset(s.c.DB.QueryRowContext(ctx, "DELETE FROM assets WHERE id = ? RETURNING "+assetColumns, id))
+	if err != nil {
+		return fmt.Errorf("failed to delete asset: %w", err)
+	}
+
+	remainingCount, err := queryCount(ctx, s.c.DB, "SELECT COUNT(*) FROM assets WHERE content_hash = ?", asset.ContentHash)
+	if err != nil {
+		return fmt.Errorf("failed to count assets by content hash: %w", err)
+	}
+
+	if remainingCount == 0 {
+		err = s.objectStore.DeleteObject(ctx, assetBucketName, asset.ContentHash)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			return fmt.Errorf("failed to delete asset object: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *AssetStore) DeleteExpiredAssets(ctx context.Context, now time.Time) (int, error) {
+	assets, err := queryRows(ctx, s.c.DB, scanAsset,
+		"SELECT "+assetColumns+" FROM assets WHERE expires_at < ?",
+		timestamp(now),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get expired assets: %w", err)
+	}
+
+	return s.deleteAssets(ctx, assets), nil
+}
+
+func (s *AssetStore) AssetsWithoutExpiryAfterID(ctx context.Context, afterID string, createdBefore time.Time, limit int) ([]*model.Asset, error) {
+	assets, err := queryRows(ctx, s.c.DB, scanAsset, `
+		SELECT `+assetColumns+` FROM assets
+		WHERE id > ? AND expires_at IS NULL AND module_id IS NULL AND created_at < ?
+		ORDER BY id ASC
+		LIMIT ?`,
+		afterID,
+		timestamp(createdBefore),
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assets without expiry: %w", err)
+	}
+
+	return assets, nil
+}
+
+func (s *AssetStore) TemporaryAssetByContentHash(ctx context.Context, appID string, contentHash string, name string, expiresAfter time.Time) (*model.Asset, error) {
+	asset, err := scanAsset(s.c.DB.QueryRowContext(ctx, `
+		SELECT `+assetColumns+` FROM assets
+		WHERE app_id = ? AND content_hash = ? AND name = ? AND expires_at > ?
+		ORDER BY expires_at DESC
+		LIMIT 1`,
+		appID,
+		contentHash,
+		name,
+		timestamp(expiresAfter),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return asset, nil
+}
+
+func (s *AssetStore) ReferencedAssetIDs(ctx context.Context, appID string, assetIDs []string) ([]string, error) {
+	rawAssetIDs, err := json.Marshal(assetIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal asset ids: %w", err)
+	}
+
+	ids, err := queryRows(ctx, s.c.DB, scanString, `
+		SELECT asset_ids.value FROM json_each(?1) AS asset_ids
+		WHERE EXISTS (
+			SELECT 1 FROM messages WHERE messages.app_id = ?2
+			AND (instr(messages.data, asset_ids.value) > 0 OR instr(messages.flow_sources, asset_ids.value) > 0)
+		) OR EXISTS (
+			SELECT 1 FROM message_instances
+			JOIN messages ON messages.id = message_instances.message_id
+			WHERE messages.app_id = ?2 AND instr(message_instances.flow_sources, asset_ids.value) > 0
+		) OR EXISTS (
+			SELECT 1 FROM commands WHERE commands.app_id = ?2 AND instr(commands.flow_source, asset_ids.value) > 0
+		) OR EXISTS (
+			SELECT 1 FROM event_listeners WHERE event_listeners.app_id = ?2 AND instr(event_listeners.flow_source, asset_ids.value) > 0
+		) OR EXISTS (
+			SELECT 1 FROM modules WHERE modules.app_id = ?2 AND instr(modules.resources, asset_ids.value) > 0
+		) OR EXISTS (
+			SELECT 1 FROM plugin_instances WHERE plugin_instances.app_id = ?2 AND instr(plugin_instances.config, asset_ids.value) > 0
+		) OR EXISTS (
+			SELECT 1 FROM plugin_values
+			JOIN plugin_instances ON plugin_instances.id = plugin_values.plugin_instance_id
+			WHERE plugin_instances.app_id = ?2 AND instr(plugin_values.value, asset_ids.value) > 0
+		) OR EXISTS (
+			SELECT 1 FROM variables
+			WHERE (variables.app_id = ?2 OR variables.namespace_id IN (SELECT namespace_id FROM namespace_grants WHERE namespace_grants.app_id = ?2))
+			AND instr(variables.default_value, asset_ids.value) > 0
+		) OR EXISTS (
+			SELECT 1 FROM variable_values
+			JOIN variables ON variables.id = variable_values.variable_id
+			WHERE (variables.app_id = ?2 OR variables.namespace_id IN (SELECT namespace_id FROM namespace_grants WHERE namespace_grants.app_id = ?2))
+			AND instr(variable_values.value, asset_ids.value) > 0
+		)`,
+		string(rawAssetIDs),
+		appID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get referenced asset ids: %w", err)
+	}
+
+	return ids, nil
+}
+
+func (s *AssetStore) deleteAssets(ctx context.Context, assets []*model.Asset) int {
+	deleted := 0
+	for _, asset := range assets {
+		err := s.DeleteAsset(ctx, asset.ID)
+		if err != nil {
+			slog.Error(
+				"failed to delete asset",
+				slog.String("asset_id", asset.ID),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		deleted++
+	}
+
+	return deleted
+}
+
+func scanAsset(row rowScanner) (*model.Asset, error) {
+	var asset model.Asset
+	err := row.Scan(
+		&asset.ID,
+		&asset.Name,
+		&asset.ContentHash,
+		&asset.ContentType,
+		&asset.ContentSize,
+		&asset.AppID,
+		&asset.ModuleID,
+		&asset.CreatorUserID,
+		&asset.CreatedAt,
+		&asset.UpdatedAt,
+		&asset.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &asset, nil
+}
@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+const collaboratorColumns = "app_id, user_id, role, created_at, updated_at"
+
+const collaboratorWithUserColumns = `
+	collaborators.app_id, collaborators.user_id, collaborators.role, collaborators.created_at, collaborators.updated_at,
+	users.id, users.email, users.display_name, users.discord_id, users.discord_username, users.discord_avatar, users.created_at, users.updated_at`
+
+func (c *Client) Collaborator(ctx context.Context, appID string, userID string) (*model.AppCollaborator, error) {
+	collaborator, err := scanCollaboratorWithUser(c.DB.QueryRowContext(ctx, `
+		SELECT `+collaboratorWithUserColumns+` FROM collaborators
+		JOIN users ON collaborators.user_id = users.id
+		WHERE app_id = ? AND user_id = ?`,
+		appID,
+		userID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return collaborator, nil
+}
+
+func (c *Client) CollaboratorsByApp(ctx context.Context, appID string) ([]*model.AppCollaborator, error) {
+	return queryRows(ctx, c.DB, scanCollaboratorWithUser, `
+		SELECT `+collaboratorWithUserColumns+` FROM collaborators
+		JOIN users ON collaborators.user_id = users.id
+		WHERE app_id = ?`,
+		appID,
+	)
+}
+
+func (c *Client) CountCollaboratorsByApp(ctx context.Context, appID string) (int, error) {
+	return queryCount(ctx, c.DB, "SELECT COUNT(*) FROM collaborators WHERE app_id = ?", appID)
+}
+
+func (c *Client) CreateCollaborator(ctx context.Context, collaborator *model.AppCollaborator) (*model.AppCollaborator, error) {
+	return scanCollaborator(c.DB.QueryRowContext(ctx,
+		"INSERT INTO collaborators ("+collaboratorColumns+") VALUES (?, ?, ?, ?, ?) RETURNING "+collaboratorColumns,
+		collaborator.AppID,
+		collaborator.UserID,
+		string(collaborator.Role),
+		timestamp(collaborator.CreatedAt),
+		timestamp(collaborator.UpdatedAt),
+	))
+}
+
+func (c *Client) UpdateCollaborator(ctx context.Context, collaborator *model.AppCollaborator) (*model.AppCollaborator, error) {
+	res, err := scanCollaborator(c.DB.QueryRowContext(ctx,
+		"UPDATE collaborators SET role = ?, updated_at = ? WHERE app_id = ? AND user_id = ? RETURNING "+collaboratorColumns,
+		string(collaborator.Role),
+		timestamp(collaborator.UpdatedAt),
+		collaborator.AppID,
+		collaborator.UserID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *Client) DeleteCollaborator(ctx context.Context, appID string, userID string) error {
+	_, err := c.DB.ExecContext(ctx, "DELETE FROM collaborators WHERE app_id = ? AND user_id = ?", appID, userID)
+	return err
+}
+
+func scanCollaborator(row rowScanner) (*model.AppCollaborator, error) {
+	var collaborator model.AppCollaborator
+	err := row.Scan(
+		&collaborator.AppID,
+		&collaborator.UserID,
+		&collaborator.Role,
+		&collaborator.CreatedAt,
+		&collaborator.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &collaborator, nil
+}
+
+func scanCollaboratorWithUser(row rowScanner) (*model.AppCollaborator, error) {
+	var collaborator model.AppCollaborator
+	var user model.User
+	err := row.Scan(
+		&collaborator.AppID,
+		&collaborator.UserID,
+		&collaborator.Role,
+		&collaborator.CreatedAt,
+		&collaborator.UpdatedAt,
+		&user.ID,
+		&user.Email,
+		&user.DisplayName,
+		&user.DiscordID,
+		&user.DiscordUsername,
+		&user.DiscordAvatar,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	collaborator.User = &user
+	return &collaborator, nil
+}
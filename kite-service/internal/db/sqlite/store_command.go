@@ -0,0 +1,152 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+const commandColumns = "id, name, description, enabled, app_id, module_id, creator_user_id, flow_source, created_at, updated_at, last_deployed_at"
+
+func (c *Client) CommandsByApp(ctx context.Context, appID string) ([]*model.Command, error) {
+	return queryRows(ctx, c.DB, scanCommand,
+		"SELECT "+commandColumns+" FROM commands WHERE app_id = ? ORDER BY created_at DESC",
+		appID,
+	)
+}
+
+func (c *Client) CountCommandsByApp(ctx context.Context, appID string) (int, error) {
+	return queryCount(ctx, c.DB, "SELECT COUNT(*) FROM commands WHERE app_id = ?", appID)
+}
+
+func (c *Client) Command(ctx context.Context, id string) (*model.Command, error) {
+	cmd, err := scanCommand(c.DB.QueryRowContext(ctx, "SELECT "+commandColumns+" FROM commands WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return cmd, nil
+}
+
+func (c *Client) CreateCommand(ctx context.Context, command *model.Command) (*model.Command, error) {
+	flowSource, err := marshalJSON(command.FlowSource)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanCommand(c.DB.QueryRowContext(ctx, `
+		INSERT INTO commands (id, name, description, enabled, app_id, module_id, creator_user_id, flow_source, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING `+commandColumns,
+		command.ID,
+		command.Name,
+		command.Description,
+		command.Enabled,
+		command.AppID,
+		nullString(command.ModuleID),
+		command.CreatorUserID,
+		flowSource,
+		timestamp(command.CreatedAt),
+		timestamp(command.UpdatedAt),
+	))
+}
+
+func (c *Client) UpdateCommand(ctx context.Context, command *model.Command) (*model.Command, error) {
+	flowSource, err := marshalJSON(command.FlowSource)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd, err := scanCommand(c.DB.QueryRowContext(ctx, `
+		UPDATE commands SET
+			name = ?,
+			description = ?,
+			enabled = ?,
+			flow_source = ?,
+			module_id = ?,
+			updated_at = ?
+		WHERE id = ?
+		RETURNING `+commandColumns,
+		command.Name,
+		command.Description,
+		command.Enabled,
+		flowSource,
+		nullString(command.ModuleID),
+		timestamp(command.UpdatedAt),
+		command.ID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return cmd, nil
+}
+
+func (c *Client) UpdateCommandsLastDeployedAt(ctx context.Context, appID string, lastDeployedAt time.Time) error {
+	_, err := c.DB.ExecContext(ctx,
+		"UPDATE commands SET last_deployed_at = ? WHERE app_id = ?",
+		timestamp(lastDeployedAt),
+		appID,
+	)
+	return err
+}
+
+func (c *Client) EnabledCommandsUpdatedSince(ctx context.Context, updatedSince time.Time) ([]*model.Command, error) {
+	return queryRows(ctx, c.DB, scanCommand,
+		"SELECT "+commandColumns+" FROM commands WHERE enabled = TRUE AND updated_at > ?",
+		timestamp(updatedSince),
+	)
+}
+
+func (c *Client) EnabledCommandIDs(ctx context.Context) ([]string, error) {
+	return queryRows(ctx, c.DB, scanString, "SELECT id FROM commands WHERE enabled = TRUE")
+}
+
+func (c *Client) DeleteCommand(ctx context.Context, id string) error {
+	_, err := c.DB.ExecContext(ctx, "DELETE FROM commands WHERE id = ?", id)
+	return err
+}
+
+func (c *Client) DinstinctAppIDsWithUndeployedCommands(ctx context.Context) ([]string, error) {
+	return queryRows(ctx, c.DB, scanString,
+		"SELECT DISTINCT app_id FROM commands WHERE last_deployed_at IS NULL OR last_deployed_at < updated_at",
+	)
+}
+
+func scanCommand(row rowScanner) (*model.Command, error) {
+	var cmd model.Command
+	var flowSource string
+	err := row.Scan(
+		&cmd.ID,
+		&cmd.Name,
+		&cmd.Description,
+		&cmd.Enabled,
+		&cmd.AppID,
+		&cmd.ModuleID,
+		&cmd.CreatorUserID,
+		&flowSource,
+		&cmd.CreatedAt,
+		&cmd.UpdatedAt,
+		&cmd.LastDeployedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(flowSource), &cmd.FlowSource); err != nil {
+		return nil, err
+	}
+
+	return &cmd, nil
+}
@@ -0,0 +1,43 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+)
+
+func (c *Client) HitCooldown(ctx context.Context, appID string, key string, window time.Duration, now time.Time) (*model.Cooldown, error) {
+	var cooldown model.Cooldown
+	err := c.DB.QueryRowContext(ctx, `
+		INSERT INTO cooldowns (key, app_id, count, expires_at)
+		VALUES (?1, ?2, 1, ?3)
+		ON CONFLICT (key) DO UPDATE SET
+			count = CASE WHEN cooldowns.expires_at <= ?4 THEN 1 ELSE cooldowns.count + 1 END,
+			expires_at = CASE WHEN cooldowns.expires_at <= ?4 THEN excluded.expires_at ELSE cooldowns.expires_at END
+		RETURNING key, app_id, count, expires_at`,
+		key,
+		appID,
+		timestamp(now.Add(window)),
+		timestamp(now),
+	).Scan(
+		&cooldown.Key,
+		&cooldown.AppID,
+		&cooldown.Count,
+		&cooldown.ExpiresAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hit cooldown: %w", err)
+	}
+
+	return &cooldown, nil
+}
+
+func (c *Client) DeleteExpiredCooldowns(ctx context.Context, now time.Time) (int, error) {
+	deleted, err := execAffected(ctx, c.DB, "DELETE FROM cooldowns WHERE expires_at < ?", timestamp(now))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired cooldowns: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+)
+
+const entitlementColumns = "id, type, subscription_id, app_id, plan_id, created_at, updated_at, ends_at"
+
+func (c *Client) Entitlements(ctx context.Context, appID string) ([]*model.Entitlement, error) {
+	return queryRows(ctx, c.DB, scanEntitlement,
+		"SELECT "+entitlementColumns+" FROM entitlements WHERE app_id = ? ORDER BY created_at DESC",
+		appID,
+	)
+}
+
+func (c *Client) ActiveEntitlements(ctx context.Context, appID string, now time.Time) ([]*model.Entitlement, error) {
+	return queryRows(ctx, c.DB, scanEntitlement,
+		"SELECT "+entitlementColumns+" FROM entitlements WHERE app_id = ? AND (ends_at IS NULL OR ends_at > ?) ORDER BY created_at DESC",
+		appID,
+		timestamp(now),
+	)
+}
+
+func (c *Client) UpsertSubscriptionEntitlement(ctx context.Context, entitlement model.Entitlement) (*model.Entitlement, error) {
+	return scanEntitlement(c.DB.QueryRowContext(ctx, `
+		INSERT INTO entitlements (`+entitlementColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (subscription_id, app_id) DO UPDATE SET
+			plan_id = excluded.plan_id,
+			updated_at = excluded.updated_at,
+			ends_at = excluded.ends_at
+		RETURNING `+entitlementColumns,
+		entitlement.ID,
+		entitlement.Type,
+		nullString(entitlement.SubscriptionID),
+		entitlement.AppID,
+		entitlement.PlanID,
+		timestamp(entitlement.CreatedAt),
+		timestamp(entitlement.UpdatedAt),
+		nullTimestamp(entitlement.EndsAt),
+	))
+}
+
+func (c *Client) UpdateSubscriptionEntitlement(ctx context.Context, entitlement model.Entitlement) (*model.Entitlement, error) {
+	return scanEntitlement(c.DB.QueryRowContext(ctx, `
+		UPDATE entitlements SET
+			plan_id = ?,
+			updated_at = ?,
+			ends_at = ?
+		WHERE subscription_id = ?
+		RETURNING `+entitlementColumns,
+		entitlement.PlanID,
+		timestamp(entitlement.UpdatedAt),
+		nullTimestamp(entitlement.EndsAt),
+		nullString(entitlement.SubscriptionID),
+	))
+}
+
+func scanEntitlement(row rowScanner) (*model.Entitlement, error) {
+	var entitlement model.Entitlement
+	err := row.Scan(
+		&entitlement.ID,
+		&entitlement.Type,
+		&entitlement.SubscriptionID,
+		&entitlement.AppID,
+		&entitlement.PlanID,
+		&entitlement.CreatedAt,
+		&entitlement.UpdatedAt,
+		&entitlement.EndsAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entitlement, nil
+}
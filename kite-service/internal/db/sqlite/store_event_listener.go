@@ -0,0 +1,163 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+const eventListenerColumns = "id, source, type, description, enabled, app_id, module_id, creator_user_id, filter, flow_source, created_at, updated_at"
+
+func (c *Client) EventListenersByApp(ctx context.Context, appID string) ([]*model.EventListener, error) {
+	return queryRows(ctx, c.DB, scanEventListener,
+		"SELECT "+eventListenerColumns+" FROM event_listeners WHERE app_id = ? ORDER BY created_at DESC",
+		appID,
+	)
+}
+
+func (c *Client) CountEventListenersByApp(ctx context.Context, appID string) (int, error) {
+	return queryCount(ctx, c.DB, "SELECT COUNT(*) FROM event_listeners WHERE app_id = ?", appID)
+}
+
+func (c *Client) EventListener(ctx context.Context, id string) (*model.EventListener, error) {
+	listener, err := scanEventListener(c.DB.QueryRowContext(ctx, "SELECT "+eventListenerColumns+" FROM event_listeners WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return listener, nil
+}
+
+func (c *Client) CreateEventListener(ctx context.Context, listener *model.EventListener) (*model.EventListener, error) {
+	flowSource, err := marshalJSON(listener.FlowSource)
+	if err != nil {
+		return nil, err
+	}
+
+	rawFilter, err := marshalEventListenerFilter(listener.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanEventListener(c.DB.QueryRowContext(ctx,
+		"INSERT INTO event_listeners ("+eventListenerColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING "+eventListenerColumns,
+		listener.ID,
+		string(listener.Source),
+		string(listener.Type),
+		listener.Description,
+		listener.Enabled,
+		listener.AppID,
+		nullString(listener.ModuleID),
+		listener.CreatorUserID,
+		rawFilter,
+		flowSource,
+		timestamp(listener.CreatedAt),
+		timestamp(listener.UpdatedAt),
+	))
+}
+
+func (c *Client) UpdateEventListener(ctx context.Context, listener *model.EventListener) (*model.EventListener, error) {
+	flowSource, err := marshalJSON(listener.FlowSource)
+	if err != nil {
+		return nil, err
+	}
+
+	rawFilter, err := marshalEventListenerFilter(listener.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := scanEventListener(c.DB.QueryRowContext(ctx, `
+		UPDATE event_listeners SET
+			enabled = ?,
+			type = ?,
+			filter = ?,
+			description = ?,
+			flow_source = ?,
+			updated_at = ?
+		WHERE id = ?
+		RETURNING `+eventListenerColumns,
+		listener.Enabled,
+		string(listener.Type),
+		rawFilter,
+		listener.Description,
+		flowSource,
+		timestamp(listener.UpdatedAt),
+		listener.ID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *Client) EnabledEventListenersUpdatedSince(ctx context.Context, updatedSince time.Time) ([]*model.EventListener, error) {
+	return queryRows(ctx, c.DB, scanEventListener,
+		"SELECT "+eventListenerColumns+" FROM event_listeners WHERE enabled = TRUE AND updated_at > ?",
+		timestamp(updatedSince),
+	)
+}
+
+func (c *Client) EnabledEventListenerIDs(ctx context.Context) ([]string, error) {
+	return queryRows(ctx, c.DB, scanString, "SELECT id FROM event_listeners WHERE enabled = TRUE")
+}
+
+func (c *Client) DeleteEventListener(ctx context.Context, id string) error {
+	_, err := c.DB.ExecContext(ctx, "DELETE FROM event_listeners WHERE id = ?", id)
+	return err
+}
+
+func marshalEventListenerFilter(filter *model.EventListenerFilter) (any, error) {
+	if filter == nil {
+		return nil, nil
+	}
+	return marshalJSON(filter)
+}
+
+func scanEventListener(row rowScanner) (*model.EventListener, error) {
+	var listener model.EventListener
+	var rawFilter sql.NullString
+	var flowSource string
+	err := row.Scan(
+		&listener.ID,
+		&listener.Source,
+		&listener.Type,
+		&listener.Description,
+		&listener.Enabled,
+		&listener.AppID,
+		&listener.ModuleID,
+		&listener.CreatorUserID,
+		&rawFilter,
+		&flowSource,
+		&listener.CreatedAt,
+		&listener.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(flowSource), &listener.FlowSource); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal flow source: %w", err)
+	}
+
+	if rawFilter.Valid {
+		if err := json.Unmarshal([]byte(rawFilter.String), &listener.Filter); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal filter: %w", err)
+		}
+	}
+
+	return &listener, nil
+}
@@ -0,0 +1,110 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+)
+
+const logColumns = "id, app_id, message, level, command_id, event_listener_id, message_id, created_at"
+
+func (c *Client) CreateLogEntry(ctx context.Context, entry model.LogEntry) error {
+	_, err := c.DB.ExecContext(ctx,
+		"INSERT INTO logs (app_id, message, level, command_id, event_listener_id, message_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		entry.AppID,
+		entry.Message,
+		string(entry.Level),
+		nullString(entry.CommandID),
+		nullString(entry.EventListenerID),
+		nullString(entry.MessageID),
+		timestamp(entry.CreatedAt),
+	)
+	return err
+}
+
+func (c *Client) LogEntriesByApp(ctx context.Context, appID string, beforeID int64, limit int) ([]*model.LogEntry, error) {
+	return queryRows(ctx, c.DB, scanLogEntry, `
+		SELECT `+logColumns+` FROM logs
+		WHERE app_id = ?1 AND (?2 = 0 OR id < ?2)
+		ORDER BY created_at DESC LIMIT ?3`,
+		appID,
+		beforeID,
+		limit,
+	)
+}
+
+func (c *Client) LogEntriesByCommand(ctx context.Context, appID string, commandID string, beforeID int64, limit int) ([]*model.LogEntry, error) {
+	return c.logEntriesBy(ctx, "command_id", appID, commandID, beforeID, limit)
+}
+
+func (c *Client) LogEntriesByEvent(ctx context.Context, appID string, eventID string, beforeID int64, limit int) ([]*model.LogEntry, error) {
+	return c.logEntriesBy(ctx, "event_listener_id", appID, eventID, beforeID, limit)
+}
+
+func (c *Client) LogEntriesByMessage(ctx context.Context, appID string, messageID string, beforeID int64, limit int) ([]*model.LogEntry, error) {
+	return c.logEntriesBy(ctx, "message_id", appID, messageID, beforeID, limit)
+}
+
+// logEntriesBy is only ever called with a constant column name.
+func (c *Client) logEntriesBy(ctx context.Context, column string, appID string, id string, beforeID int64, limit int) ([]*model.LogEntry, error) {
+	return queryRows(ctx, c.DB, scanLogEntry, `
+		SELECT `+logColumns+` FROM logs
+		WHERE app_id = ?1 AND `+column+` = ?2 AND (?3 = 0 OR id < ?3)
+		ORDER BY created_at DESC LIMIT ?4`,
+		appID,
+		id,
+		beforeID,
+		limit,
+	)
+}
+
+func (c *Client) LogSummary(ctx context.Context, appID string, start time.Time, end time.Time) (*model.LogSummary, error) {
+	var summary model.LogSummary
+	err := c.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*),
+			COALESCE(SUM(CASE WHEN level = 'error' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN level = 'warn' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN level = 'info' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN level = 'debug' THEN 1 ELSE 0 END), 0)
+		FROM logs WHERE app_id = ? AND created_at >= ? AND created_at < ?`,
+		appID,
+		timestamp(start),
+		timestamp(end),
+	).Scan(
+		&summary.TotalEntries,
+		&summary.TotalErrors,
+		&summary.TotalWarnings,
+		&summary.TotalInfos,
+		&summary.TotalDebugs,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &summary, nil
+}
+
+func (c *Client) DeleteLogEntriesBefore(ctx context.Context, before time.Time) error {
+	_, err := c.DB.ExecContext(ctx, "DELETE FROM logs WHERE created_at < ?", timestamp(before))
+	return err
+}
+
+func scanLogEntry(row rowScanner) (*model.LogEntry, error) {
+	var entry model.LogEntry
+	err := row.Scan(
+		&entry.ID,
+		&entry.AppID,
+		&entry.Message,
+		&entry.Level,
+		&entry.CommandID,
+		&entry.EventListenerID,
+		&entry.MessageID,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
@@ -0,0 +1,274 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+const messageColumns = "id, name, description, app_id, module_id, creator_user_id, data, flow_sources, created_at, updated_at"
+
+const messageInstanceColumns = "id, message_id, hidden, ephemeral, discord_guild_id, discord_channel_id, discord_message_id, flow_sources, created_at, updated_at"
+
+func (c *Client) MessagesByApp(ctx context.Context, appID string) ([]*model.Message, error) {
+	return queryRows(ctx, c.DB, scanMessage,
+		"SELECT "+messageColumns+" FROM messages WHERE app_id = ? ORDER BY created_at DESC",
+		appID,
+	)
+}
+
+func (c *Client) CountMessagesByApp(ctx context.Context, appID string) (int, error) {
+	return queryCount(ctx, c.DB, "SELECT COUNT(*) FROM messages WHERE app_id = ?", appID)
+}
+
+func (c *Client) Message(ctx context.Context, id string) (*model.Message, error) {
+	msg, err := scanMessage(c.DB.QueryRowContext(ctx, "SELECT "+messageColumns+" FROM messages WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+func (c *Client) CreateMessage(ctx context.Context, msg *model.Message) (*model.Message, error) {
+	flowSources, err := marshalJSON(msg.FlowSources)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := marshalJSON(msg.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanMessage(c.DB.QueryRowContext(ctx,
+		"INSERT INTO messages ("+messageColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING "+messageColumns,
+		msg.ID,
+		msg.Name,
+		nullString(msg.Description),
+		msg.AppID,
+		nullString(msg.ModuleID),
+		msg.CreatorUserID,
+		data,
+		flowSources,
+		timestamp(msg.CreatedAt),
+		timestamp(msg.UpdatedAt),
+	))
+}
+
+func (c *Client) UpdateMessage(ctx context.Context, msg *model.Message) (*model.Message, error) {
+	flowSources, err := marshalJSON(msg.FlowSources)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := marshalJSON(msg.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := scanMessage(c.DB.QueryRowContext(ctx, `
+		UPDATE messages SET
+			name = ?,
+			description = ?,
+			data = ?,
+			flow_sources = ?,
+			updated_at = ?
+		WHERE id = ?
+		RETURNING `+messageColumns,
+		msg.Name,
+		nullString(msg.Description),
+		data,
+		flowSources,
+		timestamp(msg.UpdatedAt),
+		msg.ID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *Client) DeleteMessage(ctx context.Context, id string) error {
+	_, err := c.DB.ExecContext(ctx, "DELETE FROM messages WHERE id = ?", id)
+	return err
+}
+
+func scanMessage(row rowScanner) (*model.Message, error) {
+	var msg model.Message
+	var data, flowSources string
+	err := row.Scan(
+		&msg.ID,
+		&msg.Name,
+		&msg.Description,
+		&msg.AppID,
+		&msg.ModuleID,
+		&msg.CreatorUserID,
+		&data,
+		&flowSources,
+		&msg.CreatedAt,
+		&msg.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(flowSources), &msg.FlowSources); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(data), &msg.Data); err != nil {
+		return nil, err
+	}
+
+	return &msg, nil
+}
+
+func (c *Client) MessageInstance(ctx context.Context, messageID string, instanceID uint64) (*model.MessageInstance, error) {
+	instance, err := scanMessageInstance(c.DB.QueryRowContext(ctx,
+		"SELECT "+messageInstanceColumns+" FROM message_instances WHERE id = ? AND message_id = ?",
+		int64(instanceID),
+		messageID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return instance, nil
+}
+
+func (c *Client) MessageInstancesByMessage(ctx context.Context, messageID string, includeHidden bool) ([]*model.MessageInstance, error) {
+	return queryRows(ctx, c.DB, scanMessageInstance,
+		"SELECT "+messageInstanceColumns+" FROM message_instances WHERE message_id = ? AND (? OR NOT hidden) ORDER BY created_at DESC",
+		messageID,
+		includeHidden,
+	)
+}
+
+func (c *Client) MessageInstanceByDiscordMessageID(ctx context.Context, discordMessageID string) (*model.MessageInstance, error) {
+	instance, err := scanMessageInstance(c.DB.QueryRowContext(ctx,
+		"SELECT "+messageInstanceColumns+" FROM message_instances WHERE discord_message_id = ?",
+		discordMessageID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return instance, nil
+}
+
+func (c *Client) CreateMessageInstance(ctx context.Context, instance *model.MessageInstance) (*model.MessageInstance, error) {
+	flowSources, err := marshalJSON(instance.FlowSources)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanMessageInstance(c.DB.QueryRowContext(ctx, `
+		INSERT INTO message_instances (message_id, discord_guild_id, discord_channel_id, discord_message_id, ephemeral, hidden, flow_sources, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING `+messageInstanceColumns,
+		instance.MessageID,
+		instance.DiscordGuildID,
+		instance.DiscordChannelID,
+		instance.DiscordMessageID,
+		instance.Ephemeral,
+		instance.Hidden,
+		flowSources,
+		timestamp(instance.CreatedAt),
+		timestamp(instance.UpdatedAt),
+	))
+}
+
+func (c *Client) UpdateMessageInstance(ctx context.Context, instance *model.MessageInstance) (*model.MessageInstance, error) {
+	flowSources, err := marshalJSON(instance.FlowSources)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := scanMessageInstance(c.DB.QueryRowContext(ctx,
+		"UPDATE message_instances SET flow_sources = ?, updated_at = ? WHERE id = ? AND message_id = ? RETURNING "+messageInstanceColumns,
+		flowSources,
+		timestamp(instance.UpdatedAt),
+		int64(instance.ID),
+		instance.MessageID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *Client) DeleteMessageInstance(ctx context.Context, messageID string, instanceID uint64) error {
+	_, err := c.DB.ExecContext(ctx, "DELETE FROM message_instances WHERE id = ? AND message_id = ?", int64(instanceID), messageID)
+	return err
+}
+
+func (c *Client) DeleteMessageInstanceByDiscordMessageID(ctx context.Context, discordMessageID string) error {
+	_, err := c.DB.ExecContext(ctx, "DELETE FROM message_instances WHERE discord_message_id = ?", discordMessageID)
+	return err
+}
+
+func (c *Client) MessageInstancesAfterID(ctx context.Context, afterID uint64, limit int) ([]*model.MessageInstance, error) {
+	return queryRows(ctx, c.DB, scanMessageInstance,
+		"SELECT "+messageInstanceColumns+" FROM message_instances WHERE id > ? AND NOT ephemeral ORDER BY id ASC LIMIT ?",
+		int64(afterID),
+		limit,
+	)
+}
+
+func (c *Client) DeleteEphemeralMessageInstancesBefore(ctx context.Context, before time.Time) (int, error) {
+	return execAffected(ctx, c.DB,
+		"DELETE FROM message_instances WHERE ephemeral AND created_at < ?",
+		timestamp(before),
+	)
+}
+
+func scanMessageInstance(row rowScanner) (*model.MessageInstance, error) {
+	var instance model.MessageInstance
+	var id int64
+	var flowSources string
+	err := row.Scan(
+		&id,
+		&instance.MessageID,
+		&instance.Hidden,
+		&instance.Ephemeral,
+		&instance.DiscordGuildID,
+		&instance.DiscordChannelID,
+		&instance.DiscordMessageID,
+		&flowSources,
+		&instance.CreatedAt,
+		&instance.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	instance.ID = uint64(id)
+	if err := json.Unmarshal([]byte(flowSources), &instance.FlowSources); err != nil {
+		return nil, err
+	}
+
+	return &instance, nil
+}
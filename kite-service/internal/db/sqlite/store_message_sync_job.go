@@ -0,0 +1,223 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+const messageSyncJobColumns = "id, app_id, message_id, status, error, created_at, updated_at, started_at, completed_at"
+
+const messageSyncJobResultColumns = "instance_id, discord_guild_id, discord_channel_id, status, error"
+
+func (c *Client) CreateMessageSyncJob(ctx context.Context, job *model.MessageSyncJob) error {
+	_, err := c.DB.ExecContext(ctx,
+		"INSERT INTO message_sync_jobs ("+messageSyncJobColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		job.ID,
+		job.AppID,
+		job.MessageID,
+		string(job.Status),
+		nullString(job.Error),
+		timestamp(job.CreatedAt),
+		timestamp(job.UpdatedAt),
+		nullTimestamp(job.StartedAt),
+		nullTimestamp(job.CompletedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create message sync job: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) UpdateMessageSyncJob(ctx context.Context, job *model.MessageSyncJob) error {
+	updated, err := execAffected(ctx, c.DB,
+		"UPDATE message_sync_jobs SET status = ?, error = ?, updated_at = ?, started_at = ?, completed_at = ? WHERE id = ?",
+		string(job.Status),
+		nullString(job.Error),
+		timestamp(job.UpdatedAt),
+		nullTimestamp(job.StartedAt),
+		nullTimestamp(job.CompletedAt),
+		job.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update message sync job: %w", err)
+	}
+	if updated == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+func (c *Client) CreateMessageSyncJobResult(ctx context.Context, jobID string, result model.MessageInstanceSyncResult, now time.Time) error {
+	return c.withTx(ctx, func(tx *sql.Tx) error {
+		updated, err := execAffected(ctx, tx, "UPDATE message_sync_jobs SET updated_at = ? WHERE id = ?", timestamp(now), jobID)
+		if err != nil {
+			return fmt.Errorf("failed to update message sync job: %w", err)
+		}
+		if updated == 0 {
+			return store.ErrNotFound
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO message_sync_job_results (job_id, `+messageSyncJobResultColumns+`, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (job_id, instance_id) DO UPDATE SET
+				discord_guild_id = excluded.discord_guild_id,
+				discord_channel_id = excluded.discord_channel_id,
+				status = excluded.status,
+				error = excluded.error,
+				created_at = excluded.created_at`,
+			jobID,
+			int64(result.InstanceID),
+			result.DiscordGuildID,
+			result.DiscordChannelID,
+			string(result.Status),
+			nullString(result.Error),
+			timestamp(now),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create message sync job result: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (c *Client) LatestMessageSyncJob(ctx context.Context, messageID string) (*model.MessageSyncJob, error) {
+	job, err := scanMessageSyncJob(c.DB.QueryRowContext(ctx,
+		"SELECT "+messageSyncJobColumns+" FROM message_sync_jobs WHERE message_id = ? ORDER BY created_at DESC LIMIT 1",
+		messageID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	job.Results, err = queryRows(ctx, c.DB, scanMessageSyncJobResult,
+		"SELECT "+messageSyncJobResultColumns+" FROM message_sync_job_results WHERE job_id = ? ORDER BY created_at ASC, instance_id ASC",
+		job.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message sync job results: %w", err)
+	}
+
+	return job, nil
+}
+
+func (c *Client) CountPendingMessageSyncJobsByApp(ctx context.Context, appID string) (int, error) {
+	count, err := queryCount(ctx, c.DB,
+		"SELECT COUNT(*) FROM message_sync_jobs WHERE app_id = ? AND status = ?",
+		appID,
+		string(model.MessageSyncStatusPending),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending message sync jobs: %w", err)
+	}
+	return count, nil
+}
+
+func (c *Client) PendingMessageSyncJobs(ctx context.Context, limit int) ([]*model.MessageSyncJob, error) {
+	jobs, err := queryRows(ctx, c.DB, scanMessageSyncJob,
+		"SELECT "+messageSyncJobColumns+" FROM message_sync_jobs WHERE status = ? ORDER BY created_at ASC LIMIT ?",
+		string(model.MessageSyncStatusPending),
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending message sync jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func (c *Client) ClaimMessageSyncJob(ctx context.Context, id string, now time.Time) (bool, error) {
+	claimed, err := execAffected(ctx, c.DB,
+		"UPDATE message_sync_jobs SET status = ?1, started_at = ?2, updated_at = ?2 WHERE id = ?3 AND status = ?4",
+		string(model.MessageSyncStatusRunning),
+		timestamp(now),
+		id,
+		string(model.MessageSyncStatusPending),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim message sync job: %w", err)
+	}
+	return claimed > 0, nil
+}
+
+func (c *Client) ResetStaleMessageSyncJobs(ctx context.Context, updatedBefore time.Time) (int, error) {
+	var reset int
+	err := c.withTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			"DELETE FROM message_sync_job_results WHERE job_id IN (SELECT id FROM message_sync_jobs WHERE status = ? AND updated_at < ?)",
+			string(model.MessageSyncStatusRunning),
+			timestamp(updatedBefore),
+		)
+		if err != nil {
+			return err
+		}
+
+		reset, err = execAffected(ctx, tx,
+			"UPDATE message_sync_jobs SET status = ?, started_at = NULL WHERE status = ? AND updated_at < ?",
+			string(model.MessageSyncStatusPending),
+			string(model.MessageSyncStatusRunning),
+			timestamp(updatedBefore),
+		)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset stale message sync jobs: %w", err)
+	}
+	return reset, nil
+}
+
+func (c *Client) DeleteMessageSyncJobsCompletedBefore(ctx context.Context, before time.Time) (int, error) {
+	deleted, err := execAffected(ctx, c.DB, "DELETE FROM message_sync_jobs WHERE completed_at < ?", timestamp(before))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete message sync jobs: %w", err)
+	}
+	return deleted, nil
+}
+
+func scanMessageSyncJob(row rowScanner) (*model.MessageSyncJob, error) {
+	var job model.MessageSyncJob
+	err := row.Scan(
+		&job.ID,
+		&job.AppID,
+		&job.MessageID,
+		&job.Status,
+		&job.Error,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&job.StartedAt,
+		&job.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func scanMessageSyncJobResult(row rowScanner) (model.MessageInstanceSyncResult, error) {
+	var result model.MessageInstanceSyncResult
+	var instanceID int64
+	err := row.Scan(
+		&instanceID,
+		&result.DiscordGuildID,
+		&result.DiscordChannelID,
+		&result.Status,
+		&result.Error,
+	)
+	if err != nil {
+		return result, err
+	}
+
+	result.InstanceID = uint64(instanceID)
+	return result, nil
+}
@@ -0,0 +1,161 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+const (
+	moduleColumns     = "id, name, description, enabled, app_id, creator_user_id, wasm_bytes, wasm_hash, created_at, updated_at"
+	moduleMetaColumns = "id, name, description, enabled, app_id, creator_user_id, wasm_hash, length(wasm_bytes), created_at, updated_at"
+)
+
+func (c *Client) ModulesByApp(ctx context.Context, appID string) ([]*model.Module, error) {
+	return queryRows(ctx, c.DB, scanModuleMeta, `
+		SELECT `+moduleMetaColumns+`
+		FROM modules WHERE app_id = ? ORDER BY created_at DESC`,
+		appID,
+	)
+}
+
+func (c *Client) CountModulesByApp(ctx context.Context, appID string) (int, error) {
+	return queryCount(ctx, c.DB, "SELECT COUNT(*) FROM modules WHERE app_id = ?", appID)
+}
+
+func (c *Client) Module(ctx context.Context, id string) (*model.Module, error) {
+	module, err := scanModule(c.DB.QueryRowContext(ctx, "SELECT "+moduleColumns+" FROM modules WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return module, nil
+}
+
+func (c *Client) ModuleMeta(ctx context.Context, id string) (*model.Module, error) {
+	module, err := scanModuleMeta(c.DB.QueryRowContext(ctx, "SELECT "+moduleMetaColumns+" FROM modules WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return module, nil
+}
+
+func (c *Client) CreateModule(ctx context.Context, module *model.Module) (*model.Module, error) {
+	return scanModule(c.DB.QueryRowContext(ctx, `
+		INSERT INTO modules (id, name, description, enabled, app_id, creator_user_id, resources, wasm_bytes, wasm_hash, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, '{}', ?, ?, ?, ?)
+		RETURNING `+moduleColumns,
+		module.ID,
+		module.Name,
+		module.Description,
+		module.Enabled,
+		module.AppID,
+		module.CreatorUserID,
+		wasmBytes(module.WasmBytes),
+		module.WasmHash,
+		timestamp(module.CreatedAt),
+		timestamp(module.UpdatedAt),
+	))
+}
+
+func (c *Client) UpdateModule(ctx context.Context, module *model.Module) (*model.Module, error) {
+	res, err := scanModule(c.DB.QueryRowContext(ctx,
+		"UPDATE modules SET name = ?, description = ?, enabled = ?, updated_at = ? WHERE id = ? RETURNING "+moduleColumns,
+		module.Name,
+		module.Description,
+		module.Enabled,
+		timestamp(module.UpdatedAt),
+		module.ID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *Client) UpdateModuleWasm(ctx context.Context, module *model.Module) (*model.Module, error) {
+	res, err := scanModule(c.DB.QueryRowContext(ctx,
+		"UPDATE modules SET wasm_bytes = ?, wasm_hash = ?, updated_at = ? WHERE id = ? RETURNING "+moduleColumns,
+		wasmBytes(module.WasmBytes),
+		module.WasmHash,
+		timestamp(module.UpdatedAt),
+		module.ID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *Client) DeleteModule(ctx context.Context, id string) error {
+	_, err := c.DB.ExecContext(ctx, "DELETE FROM modules WHERE id = ?", id)
+	return err
+}
+
+// wasmBytes makes sure a missing binary is stored as an empty blob instead of NULL.
+func wasmBytes(b []byte) []byte {
+	if b == nil {
+		return []byte{}
+	}
+	return b
+}
+
+func scanModule(row rowScanner) (*model.Module, error) {
+	var module model.Module
+	err := row.Scan(
+		&module.ID,
+		&module.Name,
+		&module.Description,
+		&module.Enabled,
+		&module.AppID,
+		&module.CreatorUserID,
+		&module.WasmBytes,
+		&module.WasmHash,
+		&module.CreatedAt,
+		&module.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	module.WasmSize = len(module.WasmBytes)
+	return &module, nil
+}
+
+func scanModuleMeta(row rowScanner) (*model.Module, error) {
+	var module model.Module
+	err := row.Scan(
+		&module.ID,
+		&module.Name,
+		&module.Description,
+		&module.Enabled,
+		&module.AppID,
+		&module.CreatorUserID,
+		&module.WasmHash,
+		&module.WasmSize,
+		&module.CreatedAt,
+		&module.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &module, nil
+}
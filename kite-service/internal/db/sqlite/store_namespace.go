@@ -0,0 +1,255 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+const namespaceColumns = "id, name, description, owner_user_id, created_at, updated_at"
+
+const namespaceGrantColumns = "namespace_id, app_id, access, created_at, updated_at"
+
+const namespaceMemberColumns = "namespace_id, user_id, created_at, updated_at"
+
+const namespaceMemberWithUserColumns = `
+	namespace_members.namespace_id, namespace_members.user_id, namespace_members.created_at, namespace_members.updated_at,
+	users.id, users.email, users.display_name, users.discord_id, users.discord_username, users.discord_avatar, users.created_at, users.updated_at`
+
+func (c *Client) NamespacesByOwner(ctx context.Context, ownerUserID string) ([]*model.Namespace, error) {
+	return queryRows(ctx, c.DB, scanNamespace,
+		"SELECT "+namespaceColumns+" FROM namespaces WHERE owner_user_id = ? ORDER BY created_at DESC",
+		ownerUserID,
+	)
+}
+
+func (c *Client) NamespacesByMember(ctx context.Context, userID string) ([]*model.Namespace, error) {
+	return queryRows(ctx, c.DB, scanNamespace, `
+		SELECT `+namespaceColumns+` FROM namespaces
+		WHERE owner_user_id = ? OR id IN (SELECT namespace_id FROM namespace_members WHERE user_id = ?)
+		ORDER BY created_at DESC`,
+		userID,
+		userID,
+	)
+}
+
+func (c *Client) Namespace(ctx context.Context, id string) (*model.Namespace, error) {
+	namespace, err := scanNamespace(c.DB.QueryRowContext(ctx, "SELECT "+namespaceColumns+" FROM namespaces WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return namespace, nil
+}
+
+func (c *Client) CreateNamespace(ctx context.Context, namespace *model.Namespace) (*model.Namespace, error) {
+	return scanNamespace(c.DB.QueryRowContext(ctx,
+		"INSERT INTO namespaces ("+namespaceColumns+") VALUES (?, ?, ?, ?, ?, ?) RETURNING "+namespaceColumns,
+		namespace.ID,
+		namespace.Name,
+		nullString(namespace.Description),
+		namespace.OwnerUserID,
+		timestamp(namespace.CreatedAt),
+		timestamp(namespace.UpdatedAt),
+	))
+}
+
+func (c *Client) UpdateNamespace(ctx context.Context, namespace *model.Namespace) (*model.Namespace, error) {
+	res, err := scanNamespace(c.DB.QueryRowContext(ctx,
+		"UPDATE namespaces SET name = ?, description = ?, updated_at = ? WHERE id = ? RETURNING "+namespaceColumns,
+		namespace.Name,
+		nullString(namespace.Description),
+		timestamp(namespace.UpdatedAt),
+		namespace.ID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *Client) DeleteNamespace(ctx context.Context, id string) error {
+	_, err := c.DB.ExecContext(ctx, "DELETE FROM namespaces WHERE id = ?", id)
+	return err
+}
+
+func (c *Client) NamespaceGrant(ctx context.Context, namespaceID string, appID string) (*model.NamespaceGrant, error) {
+	grant, err := scanNamespaceGrant(c.DB.QueryRowContext(ctx,
+		"SELECT "+namespaceGrantColumns+" FROM namespace_grants WHERE namespace_id = ? AND app_id = ?",
+		namespaceID,
+		appID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return grant, nil
+}
+
+func (c *Client) NamespaceGrantsByNamespace(ctx context.Context, namespaceID string) ([]*model.NamespaceGrant, error) {
+	return queryRows(ctx, c.DB, scanNamespaceGrant,
+		"SELECT "+namespaceGrantColumns+" FROM namespace_grants WHERE namespace_id = ? ORDER BY created_at ASC",
+		namespaceID,
+	)
+}
+
+func (c *Client) NamespaceGrantsByApp(ctx context.Context, appID string) ([]*model.NamespaceGrant, error) {
+	return queryRows(ctx, c.DB, scanNamespaceGrant,
+		"SELECT "+namespaceGrantColumns+" FROM namespace_grants WHERE app_id = ? ORDER BY created_at ASC",
+		appID,
+	)
+}
+
+func (c *Client) UpsertNamespaceGrant(ctx context.Context, grant *model.NamespaceGrant) (*model.NamespaceGrant, error) {
+	return scanNamespaceGrant(c.DB.QueryRowContext(ctx, `
+		INSERT INTO namespace_grants (`+namespaceGrantColumns+`)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (namespace_id, app_id) DO UPDATE SET
+			access = excluded.access,
+			updated_at = excluded.updated_at
+		RETURNING `+namespaceGrantColumns,
+		grant.NamespaceID,
+		grant.AppID,
+		string(grant.Access),
+		timestamp(grant.CreatedAt),
+		timestamp(grant.UpdatedAt),
+	))
+}
+
+func (c *Client) DeleteNamespaceGrant(ctx context.Context, namespaceID string, appID string) error {
+	_, err := c.DB.ExecContext(ctx, "DELETE FROM namespace_grants WHERE namespace_id = ? AND app_id = ?", namespaceID, appID)
+	return err
+}
+
+func (c *Client) NamespaceMember(ctx context.Context, namespaceID string, userID string) (*model.NamespaceMember, error) {
+	member, err := scanNamespaceMemberWithUser(c.DB.QueryRowContext(ctx, `
+		SELECT `+namespaceMemberWithUserColumns+` FROM namespace_members
+		JOIN users ON namespace_members.user_id = users.id
+		WHERE namespace_id = ? AND user_id = ?`,
+		namespaceID,
+		userID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func (c *Client) NamespaceMembersByNamespace(ctx context.Context, namespaceID string) ([]*model.NamespaceMember, error) {
+	return queryRows(ctx, c.DB, scanNamespaceMemberWithUser, `
+		SELECT `+namespaceMemberWithUserColumns+` FROM namespace_members
+		JOIN users ON namespace_members.user_id = users.id
+		WHERE namespace_id = ?
+		ORDER BY namespace_members.created_at ASC`,
+		namespaceID,
+	)
+}
+
+func (c *Client) CountNamespaceMembersByNamespace(ctx context.Context, namespaceID string) (int, error) {
+	return queryCount(ctx, c.DB, "SELECT COUNT(*) FROM namespace_members WHERE namespace_id = ?", namespaceID)
+}
+
+func (c *Client) CreateNamespaceMember(ctx context.Context, member *model.NamespaceMember) (*model.NamespaceMember, error) {
+	return scanNamespaceMember(c.DB.QueryRowContext(ctx,
+		"INSERT INTO namespace_members ("+namespaceMemberColumns+") VALUES (?, ?, ?, ?) RETURNING "+namespaceMemberColumns,
+		member.NamespaceID,
+		member.UserID,
+		timestamp(member.CreatedAt),
+		timestamp(member.UpdatedAt),
+	))
+}
+
+func (c *Client) DeleteNamespaceMember(ctx context.Context, namespaceID string, userID string) error {
+	_, err := c.DB.ExecContext(ctx, "DELETE FROM namespace_members WHERE namespace_id = ? AND user_id = ?", namespaceID, userID)
+	return err
+}
+
+func scanNamespace(row rowScanner) (*model.Namespace, error) {
+	var namespace model.Namespace
+	err := row.Scan(
+		&namespace.ID,
+		&namespace.Name,
+		&namespace.Description,
+		&namespace.OwnerUserID,
+		&namespace.CreatedAt,
+		&namespace.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &namespace, nil
+}
+
+func scanNamespaceGrant(row rowScanner) (*model.NamespaceGrant, error) {
+	var grant model.NamespaceGrant
+	err := row.Scan(
+		&grant.NamespaceID,
+		&grant.AppID,
+		&grant.Access,
+		&grant.CreatedAt,
+		&grant.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &grant, nil
+}
+
+func scanNamespaceMember(row rowScanner) (*model.NamespaceMember, error) {
+	var member model.NamespaceMember
+	err := row.Scan(
+		&member.NamespaceID,
+		&member.UserID,
+		&member.CreatedAt,
+		&member.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+func scanNamespaceMemberWithUser(row rowScanner) (*model.NamespaceMember, error) {
+	var member model.NamespaceMember
+	var user model.User
+	err := row.Scan(
+		&member.NamespaceID,
+		&member.UserID,
+		&member.CreatedAt,
+		&member.UpdatedAt,
+		&user.ID,
+		&user.Email,
+		&user.DisplayName,
+		&user.DiscordID,
+		&user.DiscordUsername,
+		&user.DiscordAvatar,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	member.User = &user
+	return &member, nil
+}
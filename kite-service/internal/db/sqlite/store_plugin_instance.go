@@ -0,0 +1,173 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+const pluginInstanceColumns = "id, plugin_id, enabled, app_id, creator_user_id, config, enabled_resource_ids, created_at, updated_at, last_deployed_at"
+
+func (c *Client) PluginInstancesByApp(ctx context.Context, appID string) ([]*model.PluginInstance, error) {
+	return queryRows(ctx, c.DB, scanPluginInstance,
+		"SELECT "+pluginInstanceColumns+" FROM plugin_instances WHERE app_id = ? ORDER BY created_at DESC",
+		appID,
+	)
+}
+
+func (c *Client) CountPluginInstancesByApp(ctx context.Context, appID string) (int, error) {
+	return queryCount(ctx, c.DB, "SELECT COUNT(*) FROM plugin_instances WHERE app_id = ?", appID)
+}
+
+func (c *Client) PluginInstance(ctx context.Context, appID string, pluginID string) (*model.PluginInstance, error) {
+	instance, err := scanPluginInstance(c.DB.QueryRowContext(ctx,
+		"SELECT "+pluginInstanceColumns+" FROM plugin_instances WHERE app_id = ? AND plugin_id = ?",
+		appID,
+		pluginID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return instance, nil
+}
+
+func (c *Client) CreatePluginInstance(ctx context.Context, instance *model.PluginInstance) (*model.PluginInstance, error) {
+	config, err := marshalJSON(instance.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceIDs, err := marshalStringSlice(instance.EnabledResourceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanPluginInstance(c.DB.QueryRowContext(ctx, `
+		INSERT INTO plugin_instances (id, plugin_id, enabled, app_id, creator_user_id, config, enabled_resource_ids, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING `+pluginInstanceColumns,
+		instance.ID,
+		instance.PluginID,
+		instance.Enabled,
+		instance.AppID,
+		instance.CreatorUserID,
+		config,
+		resourceIDs,
+		timestamp(instance.CreatedAt),
+		timestamp(instance.UpdatedAt),
+	))
+}
+
+func (c *Client) UpdatePluginInstance(ctx context.Context, instance *model.PluginInstance) (*model.PluginInstance, error) {
+	config, err := marshalJSON(instance.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceIDs, err := marshalStringSlice(instance.EnabledResourceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := scanPluginInstance(c.DB.QueryRowContext(ctx, `
+		UPDATE plugin_instances SET
+			enabled = ?,
+			config = ?,
+			enabled_resource_ids = ?,
+			updated_at = ?
+		WHERE app_id = ? AND plugin_id = ?
+		RETURNING `+pluginInstanceColumns,
+		instance.Enabled,
+		config,
+		resourceIDs,
+		timestamp(instance.UpdatedAt),
+		instance.AppID,
+		instance.PluginID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *Client) UpdatePluginInstancesLastDeployedAt(ctx context.Context, appID string, lastDeployedAt time.Time) error {
+	_, err := c.DB.ExecContext(ctx,
+		"UPDATE plugin_instances SET last_deployed_at = ? WHERE app_id = ?",
+		timestamp(lastDeployedAt),
+		appID,
+	)
+	return err
+}
+
+func (c *Client) EnabledPluginInstancesUpdatedSince(ctx context.Context, updatedSince time.Time) ([]*model.PluginInstance, error) {
+	return queryRows(ctx, c.DB, scanPluginInstance,
+		"SELECT "+pluginInstanceColumns+" FROM plugin_instances WHERE enabled = TRUE AND updated_at > ?",
+		timestamp(updatedSince),
+	)
+}
+
+func (c *Client) EnabledPluginInstanceIDs(ctx context.Context) ([]string, error) {
+	return queryRows(ctx, c.DB, scanString, "SELECT id FROM plugin_instances WHERE enabled = TRUE")
+}
+
+func (c *Client) DeletePluginInstance(ctx context.Context, appID string, pluginID string) error {
+	_, err := c.DB.ExecContext(ctx, "DELETE FROM plugin_instances WHERE app_id = ? AND plugin_id = ?", appID, pluginID)
+	return err
+}
+
+func (c *Client) DinstinctAppIDsWithUndeployedPluginInstances(ctx context.Context) ([]string, error) {
+	return queryRows(ctx, c.DB, scanString,
+		"SELECT DISTINCT app_id FROM plugin_instances WHERE last_deployed_at IS NULL OR last_deployed_at < updated_at",
+	)
+}
+
+// marshalStringSlice stores a nil slice as an empty JSON array, like an empty TEXT[] in Postgres.
+func marshalStringSlice(s []string) (string, error) {
+	if s == nil {
+		s = []string{}
+	}
+	return marshalJSON(s)
+}
+
+func scanPluginInstance(row rowScanner) (*model.PluginInstance, error) {
+	var instance model.PluginInstance
+	var config, resourceIDs string
+	err := row.Scan(
+		&instance.ID,
+		&instance.PluginID,
+		&instance.Enabled,
+		&instance.AppID,
+		&instance.CreatorUserID,
+		&config,
+		&resourceIDs,
+		&instance.CreatedAt,
+		&instance.UpdatedAt,
+		&instance.LastDeployedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(config), &instance.Config); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(resourceIDs), &instance.EnabledResourceIDs); err != nil {
+		return nil, err
+	}
+
+	return &instance, nil
+}
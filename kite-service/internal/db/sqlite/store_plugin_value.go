@@ -0,0 +1,268 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+)
+
+const pluginValueColumns = "id, plugin_instance_id, key, value, created_at, updated_at, expires_at"
+
+func (c *Client) GetPluginValue(ctx context.Context, pluginInstanceID, key string) (*model.PluginValue, error) {
+	value, err := scanPluginValue(c.DB.QueryRowContext(ctx, `
+		SELECT `+pluginValueColumns+` FROM plugin_values
+		WHERE plugin_instance_id = ? AND key = ? AND (expires_at IS NULL OR expires_at > ?)`,
+		pluginInstanceID,
+		key,
+		timestamp(time.Now()),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func (c *Client) PluginValuesByKeys(ctx context.Context, pluginInstanceID string, keys []string) ([]*model.PluginValue, error) {
+	rawKeys, err := marshalStringSlice(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return queryRows(ctx, c.DB, scanPluginValue, `
+		SELECT `+pluginValueColumns+` FROM plugin_values
+		WHERE plugin_instance_id = ? AND key IN (SELECT value FROM json_each(?))
+		AND (expires_at IS NULL OR expires_at > ?)`,
+		pluginInstanceID,
+		rawKeys,
+		timestamp(time.Now()),
+	)
+}
+
+func (c *Client) DeletePluginValue(ctx context.Context, pluginInstanceID, key string) error {
+	_, err := c.DB.ExecContext(ctx, "DELETE FROM plugin_values WHERE plugin_instance_id = ? AND key = ?", pluginInstanceID, key)
+	return err
+}
+
+func (c *Client) PluginValuesByKeyPrefix(ctx context.Context, pluginInstanceID, keyPrefix string, limit int, offset int) ([]*model.PluginValue, error) {
+	return queryRows(ctx, c.DB, scanPluginValue, `
+		SELECT `+pluginValueColumns+` FROM plugin_values
+		WHERE plugin_instance_id = ?1 AND substr(key, 1, length(?2)) = ?2
+		AND (expires_at IS NULL OR expires_at > ?3)
+		ORDER BY `+thingNumber+` DESC NULLS LAST, key ASC
+		LIMIT ?4 OFFSET ?5`,
+		pluginInstanceID,
+		keyPrefix,
+		timestamp(time.Now()),
+		limit,
+		offset,
+	)
+}
+
+func (c *Client) CountPluginValuesByKeyPrefixAbove(ctx context.Context, pluginInstanceID, keyPrefix string, value float64) (int, error) {
+	return queryCount(ctx, c.DB, `
+		SELECT COUNT(*) FROM plugin_values
+		WHERE plugin_instance_id = ?1 AND substr(key, 1, length(?2)) = ?2
+		AND (expires_at IS NULL OR expires_at > ?3)
+		AND `+thingNumber+` > ?4`,
+		pluginInstanceID,
+		keyPrefix,
+		timestamp(time.Now()),
+		value,
+	)
+}
+
+func (c *Client) SetPluginValue(ctx context.Context, value model.PluginValue) error {
+	_, err := setPluginValue(ctx, c.DB, value)
+	return err
+}
+
+func (c *Client) SetPluginValues(ctx context.Context, values []model.PluginValue) error {
+	return c.withTx(ctx, func(tx *sql.Tx) error {
+		for _, value := range values {
+			if _, err := setPluginValue(ctx, tx, value); err != nil {
+				return fmt.Errorf("failed to set plugin value: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (c *Client) CompareAndSwapPluginValue(ctx context.Context, old thing.Thing, value model.PluginValue) (bool, error) {
+	swapped := false
+	err := c.withTx(ctx, func(tx *sql.Tx) error {
+		current, err := pluginValue(ctx, tx, value.PluginInstanceID, value.Key)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			return fmt.Errorf("failed to get current plugin value: %w", err)
+		}
+
+		if old.IsNil() {
+			if current != nil {
+				return nil
+			}
+
+			// An expired value may still exist, it's replaced as a whole
+			_, err := tx.ExecContext(ctx,
+				"DELETE FROM plugin_values WHERE plugin_instance_id = ? AND key = ?",
+				value.PluginInstanceID,
+				value.Key,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to delete expired plugin value: %w", err)
+			}
+		} else {
+			if current == nil {
+				return nil
+			}
+
+			oldData, err := json.Marshal(old)
+			if err != nil {
+				return fmt.Errorf("failed to marshal old plugin value: %w", err)
+			}
+			currentData, err := json.Marshal(current.Value)
+			if err != nil {
+				return fmt.Errorf("failed to marshal current plugin value: %w", err)
+			}
+			if !jsonEqual(oldData, currentData) {
+				return nil
+			}
+
+			// Only the value and expiry are swapped, the value keeps its creation time
+			value.CreatedAt = current.CreatedAt
+		}
+
+		if _, err := setPluginValue(ctx, tx, value); err != nil {
+			return err
+		}
+
+		swapped = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return swapped, nil
+}
+
+func (c *Client) UpdatePluginValue(ctx context.Context, operation model.PluginValueOperation, value model.PluginValue) (*model.PluginValue, error) {
+	if operation == provider.VariableOperationOverwrite {
+		return setPluginValue(ctx, c.DB, value)
+	}
+
+	var newValue *model.PluginValue
+	err := c.withTx(ctx, func(tx *sql.Tx) error {
+		currentValue, err := pluginValue(ctx, tx, value.PluginInstanceID, value.Key)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				newValue, err = setPluginValue(ctx, tx, value)
+				return err
+			}
+			return fmt.Errorf("failed to get current plugin value: %w", err)
+		}
+
+		value.ExpiresAt = currentValue.ExpiresAt
+
+		newData, _, err := operation.Apply(&currentValue.Value, value.Value, provider.VariableOperationArgs{})
+		if err != nil {
+			return err
+		}
+		value.Value = newData
+
+		newValue, err = setPluginValue(ctx, tx, value)
+		if err != nil {
+			return fmt.Errorf("failed to set plugin value: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newValue, nil
+}
+
+func (c *Client) DeleteExpiredPluginValues(ctx context.Context, now time.Time) (int, error) {
+	return execAffected(ctx, c.DB, "DELETE FROM plugin_values WHERE expires_at < ?", timestamp(now))
+}
+
+// pluginValue returns the current value, expired values are reported as not found.
+// Transactions are started with BEGIN IMMEDIATE so the row can't change until the transaction ends.
+func pluginValue(ctx context.Context, db querier, pluginInstanceID string, key string) (*model.PluginValue, error) {
+	value, err := scanPluginValue(db.QueryRowContext(ctx,
+		"SELECT "+pluginValueColumns+" FROM plugin_values WHERE plugin_instance_id = ? AND key = ?",
+		pluginInstanceID,
+		key,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	if value.ExpiresAt.Valid && !value.ExpiresAt.Time.After(time.Now().UTC()) {
+		return nil, store.ErrNotFound
+	}
+
+	return value, nil
+}
+
+func setPluginValue(ctx context.Context, db querier, value model.PluginValue) (*model.PluginValue, error) {
+	data, err := marshalJSON(value.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal plugin value: %w", err)
+	}
+
+	return scanPluginValue(db.QueryRowContext(ctx, `
+		INSERT INTO plugin_values (plugin_instance_id, key, value, created_at, updated_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (plugin_instance_id, key) DO UPDATE SET
+			value = excluded.value,
+			updated_at = excluded.updated_at,
+			expires_at = excluded.expires_at
+		RETURNING `+pluginValueColumns,
+		value.PluginInstanceID,
+		value.Key,
+		data,
+		timestamp(value.CreatedAt),
+		timestamp(value.UpdatedAt),
+		nullTimestamp(value.ExpiresAt),
+	))
+}
+
+func scanPluginValue(row rowScanner) (*model.PluginValue, error) {
+	var value model.PluginValue
+	var id int64
+	var data string
+	err := row.Scan(
+		&id,
+		&value.PluginInstanceID,
+		&value.Key,
+		&data,
+		&value.CreatedAt,
+		&value.UpdatedAt,
+		&value.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	value.ID = uint64(id)
+	if err := json.Unmarshal([]byte(data), &value.Value); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal plugin value: %w", err)
+	}
+
+	return &value, nil
+}
@@ -0,0 +1,113 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+const resumePointColumns = "id, type, app_id, command_id, event_listener_id, message_id, message_instance_id, flow_source_id, flow_node_id, flow_state, created_at, expires_at"
+
+func (c *Client) CreateResumePoint(ctx context.Context, resumePoint *model.ResumePoint) error {
+	flowState, err := marshalJSON(resumePoint.FlowState)
+	if err != nil {
+		return fmt.Errorf("failed to marshal flow state: %w", err)
+	}
+
+	var messageInstanceID any
+	if resumePoint.MessageInstanceID.Valid {
+		messageInstanceID = resumePoint.MessageInstanceID.Int64
+	}
+
+	_, err = c.DB.ExecContext(ctx,
+		"INSERT INTO resume_points ("+resumePointColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		resumePoint.ID,
+		string(resumePoint.Type),
+		resumePoint.AppID,
+		nullString(resumePoint.CommandID),
+		nullString(resumePoint.EventListenerID),
+		nullString(resumePoint.MessageID),
+		messageInstanceID,
+		nullString(resumePoint.FlowSourceID),
+		resumePoint.FlowNodeID,
+		flowState,
+		timestamp(resumePoint.CreatedAt),
+		nullTimestamp(resumePoint.ExpiresAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create resume point: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) DeleteResumePoint(ctx context.Context, id string) error {
+	_, err := c.DB.ExecContext(ctx, "DELETE FROM resume_points WHERE id = ?", id)
+	return err
+}
+
+func (c *Client) DeleteExpiredResumePoints(ctx context.Context, now time.Time) (int, error) {
+	deleted, err := execAffected(ctx, c.DB, "DELETE FROM resume_points WHERE expires_at < ?", timestamp(now))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired resume points: %w", err)
+	}
+	return deleted, nil
+}
+
+func (c *Client) DeleteResumePointsCreatedBefore(ctx context.Context, resumePointType model.ResumePointType, createdBefore time.Time) (int, error) {
+	deleted, err := execAffected(ctx, c.DB,
+		"DELETE FROM resume_points WHERE type = ? AND created_at < ?",
+		string(resumePointType),
+		timestamp(createdBefore),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete resume points: %w", err)
+	}
+	return deleted, nil
+}
+
+func (c *Client) ResumePoint(ctx context.Context, id string) (*model.ResumePoint, error) {
+	resumePoint, err := scanResumePoint(c.DB.QueryRowContext(ctx, "SELECT "+resumePointColumns+" FROM resume_points WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return resumePoint, nil
+}
+
+func scanResumePoint(row rowScanner) (*model.ResumePoint, error) {
+	var resumePoint model.ResumePoint
+	var flowState string
+	err := row.Scan(
+		&resumePoint.ID,
+		&resumePoint.Type,
+		&resumePoint.AppID,
+		&resumePoint.CommandID,
+		&resumePoint.EventListenerID,
+		&resumePoint.MessageID,
+		&resumePoint.MessageInstanceID,
+		&resumePoint.FlowSourceID,
+		&resumePoint.FlowNodeID,
+		&flowState,
+		&resumePoint.CreatedAt,
+		&resumePoint.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(flowState), &resumePoint.FlowState); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal flow state: %w", err)
+	}
+
+	return &resumePoint, nil
+}
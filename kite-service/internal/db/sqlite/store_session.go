@@ -0,0 +1,54 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+const sessionColumns = "key_hash, user_id, created_at, expires_at"
+
+func (c *Client) CreateSession(ctx context.Context, session *model.Session) (*model.Session, error) {
+	return scanSession(c.DB.QueryRowContext(ctx,
+		"INSERT INTO sessions ("+sessionColumns+") VALUES (?, ?, ?, ?) RETURNING "+sessionColumns,
+		session.KeyHash,
+		session.UserID,
+		timestamp(session.CreatedAt),
+		timestamp(session.ExpiresAt),
+	))
+}
+
+func (c *Client) DeleteSession(ctx context.Context, keyHash string) error {
+	_, err := c.DB.ExecContext(ctx, "DELETE FROM sessions WHERE key_hash = ?", keyHash)
+	return err
+}
+
+func (c *Client) Session(ctx context.Context, keyHash string) (*model.Session, error) {
+	session, err := scanSession(c.DB.QueryRowContext(ctx, "SELECT "+sessionColumns+" FROM sessions WHERE key_hash = ?", keyHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func scanSession(row rowScanner) (*model.Session, error) {
+	var session model.Session
+	err := row.Scan(
+		&session.KeyHash,
+		&session.UserID,
+		&session.CreatedAt,
+		&session.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
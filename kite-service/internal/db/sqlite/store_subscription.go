@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+const subscriptionColumns = `id, display_name, source, status, status_formatted, created_at, updated_at, renews_at, trial_ends_at, ends_at, user_id,
+	lemonsqueezy_subscription_id, lemonsqueezy_customer_id, lemonsqueezy_order_id, lemonsqueezy_product_id, lemonsqueezy_variant_id`
+
+func (c *Client) Subscriptions(ctx context.Context, userID string) ([]*model.Subscription, error) {
+	return queryRows(ctx, c.DB, scanSubscription,
+		"SELECT "+subscriptionColumns+" FROM subscriptions WHERE user_id = ? ORDER BY created_at DESC",
+		userID,
+	)
+}
+
+func (c *Client) SubscriptionsByAppID(ctx context.Context, appID string) ([]*model.Subscription, error) {
+	return queryRows(ctx, c.DB, scanSubscription, `
+		SELECT `+subscriptionColumns+` FROM subscriptions
+		WHERE id IN (SELECT subscription_id FROM entitlements WHERE app_id = ?)
+		ORDER BY created_at DESC`,
+		appID,
+	)
+}
+
+func (c *Client) AllSubscriptions(ctx context.Context) ([]*model.Subscription, error) {
+	return queryRows(ctx, c.DB, scanSubscription, "SELECT "+subscriptionColumns+" FROM subscriptions ORDER BY created_at DESC")
+}
+
+func (c *Client) Subscription(ctx context.Context, subscriptionID string) (*model.Subscription, error) {
+	sub, err := scanSubscription(c.DB.QueryRowContext(ctx, "SELECT "+subscriptionColumns+" FROM subscriptions WHERE id = ?", subscriptionID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+func (c *Client) UpsertLemonSqueezySubscription(ctx context.Context, sub model.Subscription) (*model.Subscription, error) {
+	return scanSubscription(c.DB.QueryRowContext(ctx, `
+		INSERT INTO subscriptions (`+subscriptionColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (lemonsqueezy_subscription_id) DO UPDATE SET
+			display_name = excluded.display_name,
+			status = excluded.status,
+			status_formatted = excluded.status_formatted,
+			renews_at = excluded.renews_at,
+			trial_ends_at = excluded.trial_ends_at,
+			ends_at = excluded.ends_at,
+			updated_at = excluded.updated_at,
+			lemonsqueezy_customer_id = excluded.lemonsqueezy_customer_id,
+			lemonsqueezy_order_id = excluded.lemonsqueezy_order_id,
+			lemonsqueezy_product_id = excluded.lemonsqueezy_product_id,
+			lemonsqueezy_variant_id = excluded.lemonsqueezy_variant_id
+		RETURNING `+subscriptionColumns,
+		sub.ID,
+		sub.DisplayName,
+		string(sub.Source),
+		sub.Status,
+		sub.StatusFormatted,
+		timestamp(sub.CreatedAt),
+		timestamp(sub.UpdatedAt),
+		timestamp(sub.RenewsAt),
+		nullTimestamp(sub.TrialEndsAt),
+		nullTimestamp(sub.EndsAt),
+		sub.UserID,
+		nullString(sub.LemonsqueezySubscriptionID),
+		nullString(sub.LemonsqueezyCustomerID),
+		nullString(sub.LemonsqueezyOrderID),
+		nullString(sub.LemonsqueezyProductID),
+		nullString(sub.LemonsqueezyVariantID),
+	))
+}
+
+func scanSubscription(row rowScanner) (*model.Subscription, error) {
+	var sub model.Subscription
+	err := row.Scan(
+		&sub.ID,
+		&sub.DisplayName,
+		&sub.Source,
+		&sub.Status,
+		&sub.StatusFormatted,
+		&sub.CreatedAt,
+		&sub.UpdatedAt,
+		&sub.RenewsAt,
+		&sub.TrialEndsAt,
+		&sub.EndsAt,
+		&sub.UserID,
+		&sub.LemonsqueezySubscriptionID,
+		&sub.LemonsqueezyCustomerID,
+		&sub.LemonsqueezyOrderID,
+		&sub.LemonsqueezyProductID,
+		&sub.LemonsqueezyVariantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sub, nil
+}
@@ -0,0 +1,31 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kitecloud/kite/kite-service/internal/config"
+	"github.com/kitecloud/kite/kite-service/internal/db/local"
+	"github.com/kitecloud/kite/kite-service/internal/store/storetest"
+	"github.com/stretchr/testify/require"
+)
+
+// testStore combines the client with the asset store which is a separate store because it needs an object store.
+type testStore struct {
+	*Client
+	*AssetStore
+}
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) storetest.Store {
+		c := testClient(t)
+
+		objectStore, err := local.New(config.LocalObjectConfig{Path: t.TempDir()})
+		require.NoError(t, err)
+
+		assetStore, err := NewAssetStore(context.Background(), c, objectStore)
+		require.NoError(t, err)
+
+		return testStore{Client: c, AssetStore: assetStore}
+	})
+}
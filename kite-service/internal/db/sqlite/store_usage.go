@@ -0,0 +1,146 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+)
+
+const usageRecordColumns = "id, type, app_id, command_id, event_listener_id, message_id, credits_used, created_at"
+
+func (c *Client) CreateUsageRecord(ctx context.Context, record model.UsageRecord) error {
+	_, err := c.DB.ExecContext(ctx,
+		"INSERT INTO usage_records (type, app_id, command_id, event_listener_id, message_id, credits_used, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		string(record.Type),
+		record.AppID,
+		nullString(record.CommandID),
+		nullString(record.EventListenerID),
+		nullString(record.MessageID),
+		record.CreditsUsed,
+		timestamp(record.CreatedAt),
+	)
+	return err
+}
+
+func (c *Client) UsageRecordsBetween(ctx context.Context, appID string, start time.Time, end time.Time) ([]model.UsageRecord, error) {
+	return queryRows(ctx, c.DB, func(row rowScanner) (model.UsageRecord, error) {
+		var record model.UsageRecord
+		err := row.Scan(
+			&record.ID,
+			&record.Type,
+			&record.AppID,
+			&record.CommandID,
+			&record.EventListenerID,
+			&record.MessageID,
+			&record.CreditsUsed,
+			&record.CreatedAt,
+		)
+		return record, err
+	}, `
+		SELECT `+usageRecordColumns+` FROM usage_records
+		WHERE app_id = ? AND created_at BETWEEN ? AND ?
+		ORDER BY created_at DESC`,
+		appID,
+		timestamp(start),
+		timestamp(end),
+	)
+}
+
+func (c *Client) UsageCreditsUsedBetween(ctx context.Context, appID string, start time.Time, end time.Time) (int, error) {
+	return queryCount(ctx, c.DB,
+		"SELECT COALESCE(SUM(credits_used), 0) FROM usage_records WHERE app_id = ? AND created_at BETWEEN ? AND ?",
+		appID,
+		timestamp(start),
+		timestamp(end),
+	)
+}
+
+func (c *Client) UsageCreditsUsedByTypeBetween(ctx context.Context, appID string, start time.Time, end time.Time) ([]model.UsageCreditsUsedByType, error) {
+	return queryRows(ctx, c.DB, func(row rowScanner) (model.UsageCreditsUsedByType, error) {
+		var res model.UsageCreditsUsedByType
+		err := row.Scan(&res.Type, &res.CreditsUsed)
+		return res, err
+	}, `
+		SELECT type, SUM(credits_used) FROM usage_records
+		WHERE app_id = ? AND created_at BETWEEN ? AND ?
+		GROUP BY type`,
+		appID,
+		timestamp(start),
+		timestamp(end),
+	)
+}
+
+func (c *Client) UsageCreditsUsedByDayBetween(ctx context.Context, appID string, start time.Time, end time.Time) ([]model.UsageCreditsUsedByDay, error) {
+	type dayCredits struct {
+		date        string
+		creditsUsed int
+	}
+
+	rows, err := queryRows(ctx, c.DB, func(row rowScanner) (dayCredits, error) {
+		var res dayCredits
+		err := row.Scan(&res.date, &res.creditsUsed)
+		return res, err
+	}, `
+		SELECT substr(created_at, 1, 10), SUM(credits_used) FROM usage_records
+		WHERE app_id = ? AND created_at BETWEEN ? AND ?
+		GROUP BY substr(created_at, 1, 10)`,
+		appID,
+		timestamp(start),
+		timestamp(end),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	creditsByDate := make(map[string]int, len(rows))
+	for _, row := range rows {
+		creditsByDate[row.date] = row.creditsUsed
+	}
+
+	// SQLite has no generate_series, so the days without usage are filled in here
+	records := []model.UsageCreditsUsedByDay{}
+	for dt := start.UTC(); !dt.After(end.UTC()); dt = dt.AddDate(0, 0, 1) {
+		date := time.Date(dt.Year(), dt.Month(), dt.Day(), 0, 0, 0, 0, time.UTC)
+		records = append(records, model.UsageCreditsUsedByDay{
+			Date:        date,
+			CreditsUsed: creditsByDate[date.Format(time.DateOnly)],
+		})
+	}
+
+	return records, nil
+}
+
+func (c *Client) AllUsageCreditsUsedBetween(ctx context.Context, start time.Time, end time.Time) (map[string]int, error) {
+	type appCredits struct {
+		appID       string
+		creditsUsed int
+	}
+
+	rows, err := queryRows(ctx, c.DB, func(row rowScanner) (appCredits, error) {
+		var res appCredits
+		err := row.Scan(&res.appID, &res.creditsUsed)
+		return res, err
+	}, `
+		SELECT app_id, SUM(credits_used) FROM usage_records
+		WHERE created_at BETWEEN ? AND ?
+		GROUP BY app_id`,
+		timestamp(start),
+		timestamp(end),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]int, len(rows))
+	for _, row := range rows {
+		res[row.appID] = row.creditsUsed
+	}
+
+	return res, nil
+}
+
+func (c *Client) DeleteUsageRecordsBefore(ctx context.Context, before time.Time) error {
+	_, err := c.DB.ExecContext(ctx, "DELETE FROM usage_records WHERE created_at < ?", timestamp(before))
+	return err
+}
@@ -0,0 +1,76 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+const userColumns = "id, email, display_name, discord_id, discord_username, discord_avatar, created_at, updated_at"
+
+func (c *Client) User(ctx context.Context, id string) (*model.User, error) {
+	return c.user(ctx, "SELECT "+userColumns+" FROM users WHERE id = ?", id)
+}
+
+func (c *Client) UserByEmail(ctx context.Context, email string) (*model.User, error) {
+	return c.user(ctx, "SELECT "+userColumns+" FROM users WHERE email = ?", email)
+}
+
+func (c *Client) UserByDiscordID(ctx context.Context, discordID string) (*model.User, error) {
+	return c.user(ctx, "SELECT "+userColumns+" FROM users WHERE discord_id = ?", discordID)
+}
+
+func (c *Client) user(ctx context.Context, query string, args ...any) (*model.User, error) {
+	user, err := scanUser(c.DB.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (c *Client) UpsertUser(ctx context.Context, user *model.User) (*model.User, error) {
+	return scanUser(c.DB.QueryRowContext(ctx, `
+		INSERT INTO users (`+userColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (discord_id) DO UPDATE SET
+			email = excluded.email,
+			display_name = excluded.display_name,
+			discord_username = excluded.discord_username,
+			discord_avatar = excluded.discord_avatar,
+			updated_at = excluded.updated_at
+		RETURNING `+userColumns,
+		user.ID,
+		user.Email,
+		user.DisplayName,
+		user.DiscordID,
+		user.DiscordUsername,
+		nullString(user.DiscordAvatar),
+		timestamp(user.CreatedAt),
+		timestamp(user.UpdatedAt),
+	))
+}
+
+func scanUser(row rowScanner) (*model.User, error) {
+	var user model.User
+	err := row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.DisplayName,
+		&user.DiscordID,
+		&user.DiscordUsername,
+		&user.DiscordAvatar,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
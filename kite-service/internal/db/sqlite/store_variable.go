@@ -0,0 +1,476 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"gopkg.in/guregu/null.v4"
+)
+
+const variableColumns = "id, name, scoped, type, schema, default_value, value_ttl_seconds, app_id, namespace_id, module_id, created_at, updated_at"
+
+const variableWithTotalValuesQuery = `
+	SELECT ` + variableColumns + `,
+		(SELECT COUNT(*) FROM variable_values WHERE variable_values.variable_id = variables.id)
+	FROM variables`
+
+const variableValueColumns = "id, variable_id, scope, value, created_at, updated_at, expires_at"
+
+func (c *Client) VariablesByApp(ctx context.Context, appID string) ([]*model.Variable, error) {
+	return queryRows(ctx, c.DB, scanVariableWithTotalValues,
+		variableWithTotalValuesQuery+" WHERE app_id = ? ORDER BY created_at DESC",
+		appID,
+	)
+}
+
+func (c *Client) VariablesByNamespace(ctx context.Context, namespaceID string) ([]*model.Variable, error) {
+	return queryRows(ctx, c.DB, scanVariableWithTotalValues,
+		variableWithTotalValuesQuery+" WHERE namespace_id = ? ORDER BY created_at DESC",
+		namespaceID,
+	)
+}
+
+func (c *Client) CountVariablesByApp(ctx context.Context, appID string) (int, error) {
+	return queryCount(ctx, c.DB, "SELECT COUNT(*) FROM variables WHERE app_id = ?", appID)
+}
+
+func (c *Client) Variable(ctx context.Context, id string) (*model.Variable, error) {
+	v, err := scanVariableWithTotalValues(c.DB.QueryRowContext(ctx, variableWithTotalValuesQuery+" WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (c *Client) VariableDefinition(ctx context.Context, id string) (*model.Variable, error) {
+	v, err := scanVariable(c.DB.QueryRowContext(ctx, "SELECT "+variableColumns+" FROM variables WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (c *Client) VariableByName(ctx context.Context, appID, name string) (*model.Variable, error) {
+	v, err := scanVariableWithTotalValues(c.DB.QueryRowContext(ctx,
+		variableWithTotalValuesQuery+" WHERE app_id = ? AND name = ?",
+		appID,
+		name,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (c *Client) CreateVariable(ctx context.Context, variable *model.Variable) (*model.Variable, error) {
+	schema, defaultValue, err := marshalVariableDefinition(variable)
+	if err != nil {
+		return nil, err
+	}
+
+	var appID any
+	if variable.AppID != "" {
+		appID = variable.AppID
+	}
+
+	return scanVariable(c.DB.QueryRowContext(ctx,
+		"INSERT INTO variables ("+variableColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING "+variableColumns,
+		variable.ID,
+		variable.Name,
+		variable.Scoped,
+		string(variable.Type),
+		schema,
+		defaultValue,
+		int(variable.ValueTTL.Seconds()),
+		appID,
+		nullString(variable.NamespaceID),
+		nullString(variable.ModuleID),
+		timestamp(variable.CreatedAt),
+		timestamp(variable.UpdatedAt),
+	))
+}
+
+func (c *Client) UpdateVariable(ctx context.Context, variable *model.Variable) (*model.Variable, error) {
+	schema, defaultValue, err := marshalVariableDefinition(variable)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := scanVariable(c.DB.QueryRowContext(ctx, `
+		UPDATE variables SET
+			name = ?,
+			scoped = ?,
+			updated_at = ?,
+			type = ?,
+			schema = ?,
+			default_value = ?,
+			value_ttl_seconds = ?
+		WHERE id = ?
+		RETURNING `+variableColumns,
+		variable.Name,
+		variable.Scoped,
+		timestamp(variable.UpdatedAt),
+		string(variable.Type),
+		schema,
+		defaultValue,
+		int(variable.ValueTTL.Seconds()),
+		variable.ID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (c *Client) DeleteVariable(ctx context.Context, id string) error {
+	_, err := c.DB.ExecContext(ctx, "DELETE FROM variables WHERE id = ?", id)
+	return err
+}
+
+func marshalVariableDefinition(variable *model.Variable) (schema any, defaultValue any, err error) {
+	if variable.Schema != nil {
+		schema, err = marshalJSON(variable.Schema)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal variable schema: %w", err)
+		}
+	}
+
+	if !variable.DefaultValue.IsNil() {
+		defaultValue, err = marshalJSON(variable.DefaultValue)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal variable default value: %w", err)
+		}
+	}
+
+	return schema, defaultValue, nil
+}
+
+func scanVariable(row rowScanner) (*model.Variable, error) {
+	return scanVariableRow(row, false)
+}
+
+func scanVariableWithTotalValues(row rowScanner) (*model.Variable, error) {
+	return scanVariableRow(row, true)
+}
+
+func scanVariableRow(row rowScanner, withTotalValues bool) (*model.Variable, error) {
+	v := model.Variable{DefaultValue: thing.Null}
+	var schema, defaultValue, appID sql.NullString
+	var ttlSeconds int64
+	var totalValues int64
+
+	dest := []any{
+		&v.ID,
+		&v.Name,
+		&v.Scoped,
+		&v.Type,
+		&schema,
+		&defaultValue,
+		&ttlSeconds,
+		&appID,
+		&v.NamespaceID,
+		&v.ModuleID,
+		&v.CreatedAt,
+		&v.UpdatedAt,
+	}
+	if withTotalValues {
+		dest = append(dest, &totalValues)
+	}
+
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	v.AppID = appID.String
+	v.ValueTTL = time.Duration(ttlSeconds) * time.Second
+	if withTotalValues {
+		v.TotalValues = null.IntFrom(totalValues)
+	}
+
+	if schema.Valid {
+		if err := json.Unmarshal([]byte(schema.String), &v.Schema); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal variable schema: %w", err)
+		}
+	}
+
+	if defaultValue.Valid {
+		if err := json.Unmarshal([]byte(defaultValue.String), &v.DefaultValue); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal variable default value: %w", err)
+		}
+	}
+
+	return &v, nil
+}
+
+func (c *Client) VariableValues(ctx context.Context, variableID string) ([]*model.VariableValue, error) {
+	return queryRows(ctx, c.DB, scanVariableValue, `
+		SELECT `+variableValueColumns+` FROM variable_values
+		WHERE variable_id = ? AND (expires_at IS NULL OR expires_at > ?)`,
+		variableID,
+		timestamp(time.Now()),
+	)
+}
+
+func (c *Client) VariableValue(ctx context.Context, variableID string, scope null.String) (*model.VariableValue, error) {
+	v, err := scanVariableValue(c.DB.QueryRowContext(ctx, `
+		SELECT `+variableValueColumns+` FROM variable_values
+		WHERE variable_id = ? AND scope IS ? AND (expires_at IS NULL OR expires_at > ?)`,
+		variableID,
+		nullString(scope),
+		timestamp(time.Now()),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (c *Client) VariableValuesByQuery(ctx context.Context, variableID string, query model.VariableValueQuery) ([]*model.VariableValue, error) {
+	var orderBy string
+	switch query.Order {
+	case provider.VariableQueryOrderValueAsc:
+		orderBy = thingNumber + " ASC NULLS LAST, scope ASC"
+	case provider.VariableQueryOrderValueDesc:
+		orderBy = thingNumber + " DESC NULLS LAST, scope ASC"
+	case provider.VariableQueryOrderScopeDesc:
+		orderBy = "scope DESC"
+	default:
+		orderBy = "scope ASC"
+	}
+
+	return queryRows(ctx, c.DB, scanVariableValue, `
+		SELECT `+variableValueColumns+` FROM variable_values
+		WHERE variable_id = ?1 AND scope IS NOT NULL AND substr(scope, 1, length(?2)) = ?2
+		AND (expires_at IS NULL OR expires_at > ?3)
+		ORDER BY `+orderBy+`
+		LIMIT ?4 OFFSET ?5`,
+		variableID,
+		query.ScopePrefix,
+		timestamp(time.Now()),
+		query.Limit,
+		query.Offset,
+	)
+}
+
+func (c *Client) AggregateVariableValues(ctx context.Context, variableID string, scopePrefix string) (*model.VariableValueAggregate, error) {
+	var res model.VariableValueAggregate
+	err := c.DB.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(`+thingNumber+`), 0.0),
+			AVG(`+thingNumber+`),
+			MIN(`+thingNumber+`),
+			MAX(`+thingNumber+`)
+		FROM variable_values
+		WHERE variable_id = ?1 AND scope IS NOT NULL AND substr(scope, 1, length(?2)) = ?2
+		AND (expires_at IS NULL OR expires_at > ?3)`,
+		variableID,
+		scopePrefix,
+		timestamp(time.Now()),
+	).Scan(
+		&res.Count,
+		&res.Sum,
+		&res.Average,
+		&res.Min,
+		&res.Max,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (c *Client) SetVariableValue(ctx context.Context, value model.VariableValue) error {
+	_, err := setVariableValue(ctx, c.DB, value)
+	return err
+}
+
+// UpdateVariableValue applies the operation in a single immediate transaction which holds the write lock,
+// so concurrent updates of the same value are applied one after another and none get lost.
+func (c *Client) UpdateVariableValue(
+	ctx context.Context,
+	operation model.VariableValueOperation,
+	args model.VariableValueOperationArgs,
+	value model.VariableValue,
+) (*model.VariableValue, error) {
+	if operation.IsOverwrite() {
+		data, _, err := operation.Apply(nil, value.Data, args)
+		if err != nil {
+			return nil, err
+		}
+
+		value.Data = data
+		return setVariableValue(ctx, c.DB, value)
+	}
+
+	var res *model.VariableValue
+	err := c.withTx(ctx, func(tx *sql.Tx) error {
+		currentValue, err := variableValue(ctx, tx, value.VariableID, value.Scope)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			return fmt.Errorf("failed to get current variable value: %w", err)
+		}
+
+		var current *thing.Thing
+		if currentValue != nil {
+			current = &currentValue.Data
+		} else {
+			// An expired value may still exist, it's replaced as a whole
+			_, err := tx.ExecContext(ctx,
+				"DELETE FROM variable_values WHERE variable_id = ? AND scope IS ?",
+				value.VariableID,
+				nullString(value.Scope),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to delete expired variable value: %w", err)
+			}
+		}
+
+		newData, ok, err := operation.Apply(current, value.Data, args)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if currentValue != nil {
+				res = currentValue
+			} else {
+				value.Data = thing.Null
+				res = &value
+			}
+			return nil
+		}
+
+		value.Data = newData
+		res, err = setVariableValue(ctx, tx, value)
+		if err != nil {
+			return fmt.Errorf("failed to set variable value: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *Client) DeleteVariableValue(ctx context.Context, variableID string, scope null.String) error {
+	_, err := c.DB.ExecContext(ctx,
+		"DELETE FROM variable_values WHERE variable_id = ? AND scope IS ?",
+		variableID,
+		nullString(scope),
+	)
+	return err
+}
+
+func (c *Client) DeleteAllVariableValues(ctx context.Context, variableID string) error {
+	_, err := c.DB.ExecContext(ctx, "DELETE FROM variable_values WHERE variable_id = ?", variableID)
+	return err
+}
+
+func (c *Client) DeleteExpiredVariableValues(ctx context.Context, now time.Time) (int, error) {
+	return execAffected(ctx, c.DB, "DELETE FROM variable_values WHERE expires_at < ?", timestamp(now))
+}
+
+// variableValue returns the current value, expired values are reported as not found.
+func variableValue(ctx context.Context, db querier, variableID string, scope null.String) (*model.VariableValue, error) {
+	v, err := scanVariableValue(db.QueryRowContext(ctx,
+		"SELECT "+variableValueColumns+" FROM variable_values WHERE variable_id = ? AND scope IS ?",
+		variableID,
+		nullString(scope),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	if v.ExpiresAt.Valid && !v.ExpiresAt.Time.After(time.Now().UTC()) {
+		return nil, store.ErrNotFound
+	}
+
+	return v, nil
+}
+
+func setVariableValue(ctx context.Context, db querier, value model.VariableValue) (*model.VariableValue, error) {
+	data, err := marshalJSON(value.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal variable value: %w", err)
+	}
+
+	// The unique index on (variable_id, scope) is split in two partial indexes, the conflict target must match one of them
+	conflictTarget := "(variable_id, scope) WHERE scope IS NOT NULL"
+	if !value.Scope.Valid {
+		conflictTarget = "(variable_id) WHERE scope IS NULL"
+	}
+
+	return scanVariableValue(db.QueryRowContext(ctx, `
+		INSERT INTO variable_values (variable_id, scope, value, created_at, updated_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT `+conflictTarget+` DO UPDATE SET
+			value = excluded.value,
+			updated_at = excluded.updated_at,
+			expires_at = excluded.expires_at
+		RETURNING `+variableValueColumns,
+		value.VariableID,
+		nullString(value.Scope),
+		data,
+		timestamp(value.CreatedAt),
+		timestamp(value.UpdatedAt),
+		nullTimestamp(value.ExpiresAt),
+	))
+}
+
+func scanVariableValue(row rowScanner) (*model.VariableValue, error) {
+	var v model.VariableValue
+	var id int64
+	var data string
+	err := row.Scan(
+		&id,
+		&v.VariableID,
+		&v.Scope,
+		&data,
+		&v.CreatedAt,
+		&v.UpdatedAt,
+		&v.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	v.ID = uint64(id)
+	if err := json.Unmarshal([]byte(data), &v.Data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal variable value: %w", err)
+	}
+
+	return &v, nil
+}
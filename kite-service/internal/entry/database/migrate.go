@@ -8,6 +8,7 @@ import (
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/kitecloud/kite/kite-service/internal/config"
 	"github.com/kitecloud/kite/kite-service/internal/db/postgres"
+	"github.com/kitecloud/kite/kite-service/internal/db/sqlite"
 	"github.com/kitecloud/kite/kite-service/internal/logging"
 	"github.com/kitecloud/kite/kite-service/internal/store"
 )
@@ -43,6 +44,21 @@ func RunDatabaseMigrations(database string, operation string, opts DatabaseMigra
 		}
 		migrater = pgMigrater
 		defer migrater.Close()
+	case "sqlite":
+		client, err := sqlite.New(cfg.Database.SQLite)
+		if err != nil {
+			l.With("error", err).Error("Failed to create sqlite client")
+			os.Exit(1)
+		}
+		defer client.Close()
+
+		sqliteMigrater, err := client.GetMigrater()
+		if err != nil {
+			l.With("error", err).Error("Failed to get migrater")
+			os.Exit(1)
+		}
+		migrater = sqliteMigrater
+		defer migrater.Close()
 	default:
 		return fmt.Errorf("unsupported database: %s", database)
 	}
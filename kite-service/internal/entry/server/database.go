@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kitecloud/kite/kite-service/internal/api/handler/health"
+	"github.com/kitecloud/kite/kite-service/internal/config"
+	"github.com/kitecloud/kite/kite-service/internal/db/local"
+	"github.com/kitecloud/kite/kite-service/internal/db/postgres"
+	"github.com/kitecloud/kite/kite-service/internal/db/s3"
+	"github.com/kitecloud/kite/kite-service/internal/db/sqlite"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+)
+
+// database is implemented by every supported database backend.
+type database interface {
+	store.UserStore
+	store.SessionStore
+	store.AppStore
+	store.LogStore
+	store.UsageStore
+	store.CommandStore
+	store.VariableStore
+	store.VariableValueStore
+	store.MessageStore
+	store.MessageInstanceStore
+	store.MessageSyncJobStore
+	store.EventListenerStore
+	store.PluginInstanceStore
+	store.PluginValueStore
+	store.SubscriptionStore
+	store.EntitlementStore
+	store.ModuleStore
+	store.NamespaceStore
+	store.ResumePointStore
+	store.CooldownStore
+	Ping(ctx context.Context) error
+}
+
+type objectStore interface {
+	store.ObjectStore
+	Ping(ctx context.Context) error
+}
+
+func openDatabase(cfg *config.Config, healthChecks map[string]health.HealthCheck) (database, error) {
+	switch cfg.Database.Type {
+	case "sqlite":
+		client, err := sqlite.New(cfg.Database.SQLite)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create sqlite client: %w", err)
+		}
+
+		healthChecks["sqlite"] = client.Ping
+		return client, nil
+	default:
+		client, err := postgres.New(postgres.BuildConnectionDSN(cfg.Database.Postgres), cfg.ClusterCount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create postgres client: %w", err)
+		}
+
+		healthChecks["postgres"] = client.Ping
+		return client, nil
+	}
+}
+
+func openObjectStore(cfg *config.Config, healthChecks map[string]health.HealthCheck) (objectStore, error) {
+	switch cfg.Database.ObjectStore {
+	case "local":
+		client, err := local.New(cfg.Database.Local)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create local object store: %w", err)
+		}
+
+		healthChecks["local"] = client.Ping
+		return client, nil
+	default:
+		client, err := s3.New(cfg.Database.S3)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create S3 client: %w", err)
+		}
+
+		healthChecks["s3"] = client.Ping
+		return client, nil
+	}
+}
+
+func newAssetStore(ctx context.Context, db database, objectStore store.ObjectStore) (store.AssetStore, error) {
+	switch db := db.(type) {
+	case *sqlite.Client:
+		return sqlite.NewAssetStore(ctx, db, objectStore)
+	case *postgres.Client:
+		return postgres.NewAssetStore(ctx, db, objectStore)
+	default:
+		return nil, fmt.Errorf("unsupported database type %T", db)
+	}
+}
@@ -1,13 +1,22 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/diamondburned/arikawa/v3/api"
 	"github.com/diamondburned/arikawa/v3/utils/httputil"
 	"github.com/kitecloud/kite/kite-service/internal/config"
+	"github.com/kitecloud/kite/kite-service/internal/core/maintenance"
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 func patchDiscordProxyURL(cfg *config.Config) {
@@ -37,7 +46,11 @@ func patchDiscordProxyURL(cfg *config.Config) {
 	api.EndpointTOTP = api.EndpointAuth + "mfa/totp"
 }
 
+// engineHTTPClient returns the client for requests to URLs chosen by users.
+// Requests are traced, but the trace context isn't sent along so our trace IDs don't leak to third parties.
 func engineHTTPClient(cfg *config.Config) *http.Client {
+	noPropagation := otelhttp.WithPropagators(propagation.NewCompositeTextMapPropagator())
+
 	if cfg.Engine.HTTPProxyURL != "" {
 		proxyURL, err := url.Parse(cfg.Engine.HTTPProxyURL)
 		if err != nil {
@@ -48,11 +61,52 @@ func engineHTTPClient(cfg *config.Config) *http.Client {
 		slog.Info("Using HTTP proxy for Engine", "url", cfg.Engine.HTTPProxyURL)
 
 		return &http.Client{
-			Transport: &http.Transport{
+			Transport: otelhttp.NewTransport(&http.Transport{
 				Proxy: http.ProxyURL(proxyURL),
-			},
+			}, noPropagation),
+		}
+	}
+
+	return &http.Client{
+		Transport: otelhttp.NewTransport(http.DefaultTransport, noPropagation),
+	}
+}
+
+// startMetricsServer serves the Prometheus metrics on a separate address so they aren't exposed publicly.
+func startMetricsServer(ctx context.Context, cfg config.MetricsConfig) {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.Handler())
+
+	address := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+	server := &http.Server{
+		Addr:    address,
+		Handler: mux,
+	}
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	go func() {
+		slog.With("address", address).Info("Starting metrics server")
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.With("error", err).Error("Failed to start metrics server")
 		}
+	}()
+}
+
+func janitorConfig(cfg config.MaintenanceConfig) maintenance.JanitorConfig {
+	resumePointTTLs := make(map[model.ResumePointType]time.Duration, len(cfg.ResumePointTTLHours))
+	for resumePointType, hours := range cfg.ResumePointTTLHours {
+		resumePointTTLs[model.ResumePointType(resumePointType)] = time.Duration(hours) * time.Hour
 	}
 
-	return &http.Client{}
+	return maintenance.JanitorConfig{
+		SweepInterval:                 time.Duration(cfg.SweepIntervalMinutes) * time.Minute,
+		ResumePointTTLs:               resumePointTTLs,
+		AssetGracePeriod:              time.Duration(cfg.AssetGracePeriodHours) * time.Hour,
+		EphemeralMessageInstanceTTL:   time.Duration(cfg.EphemeralMessageInstanceTTLHours) * time.Hour,
+		MessageInstanceChecksPerSweep: cfg.MessageInstanceChecksPerSweep,
+	}
 }
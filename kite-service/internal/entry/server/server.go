@@ -4,49 +4,97 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"time"
 
 	"github.com/kitecloud/kite/kite-service/internal/api"
+	"github.com/kitecloud/kite/kite-service/internal/api/handler/health"
 	"github.com/kitecloud/kite/kite-service/internal/config"
 	"github.com/kitecloud/kite/kite-service/internal/core/command"
 	"github.com/kitecloud/kite/kite-service/internal/core/engine"
 	"github.com/kitecloud/kite/kite-service/internal/core/event"
 	"github.com/kitecloud/kite/kite-service/internal/core/gateway"
+	"github.com/kitecloud/kite/kite-service/internal/core/maintenance"
+	"github.com/kitecloud/kite/kite-service/internal/core/messagesync"
 	"github.com/kitecloud/kite/kite-service/internal/core/plan"
 	"github.com/kitecloud/kite/kite-service/internal/core/usage"
-	"github.com/kitecloud/kite/kite-service/internal/db/postgres"
-	"github.com/kitecloud/kite/kite-service/internal/db/s3"
+	"github.com/kitecloud/kite/kite-service/internal/db/cached"
+	"github.com/kitecloud/kite/kite-service/internal/db/memory"
+	"github.com/kitecloud/kite/kite-service/internal/db/redis"
 	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/internal/tracing"
 	"github.com/kitecloud/kite/kite-service/internal/util"
 	"github.com/kitecloud/kite/kite-service/pkg/plugin"
 	"github.com/kitecloud/kite/kite-service/pkg/plugin/counting"
+	"github.com/kitecloud/kite/kite-service/pkg/plugin/leveling"
+	"github.com/kitecloud/kite/kite-service/pkg/plugin/roles"
 	"github.com/kitecloud/kite/kite-service/pkg/plugin/starboard"
+	"github.com/kitecloud/kite/kite-service/pkg/plugin/ticket"
+	"github.com/kitecloud/kite/kite-service/pkg/wasm"
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 func StartServer(c context.Context, cfg *config.Config) error {
 	patchDiscordProxyURL(cfg)
 
-	pg, err := postgres.New(postgres.BuildConnectionDSN(cfg.Database.Postgres), cfg.ClusterCount)
+	healthChecks := map[string]health.HealthCheck{}
+
+	db, err := openDatabase(cfg, healthChecks)
 	if err != nil {
-		slog.With("error", err).Error("Failed to create postgres client")
-		return fmt.Errorf("failed to create postgres client: %w", err)
+		slog.With("error", err).Error("Failed to create database client")
+		return err
 	}
 
-	s3Client, err := s3.New(cfg.Database.S3)
+	objectStore, err := openObjectStore(cfg, healthChecks)
 	if err != nil {
-		slog.With("error", err).Error("Failed to create S3 client")
-		return fmt.Errorf("failed to create S3 client: %w", err)
+		slog.With("error", err).Error("Failed to create object store")
+		return err
 	}
 
-	assetStore, err := postgres.NewAssetStore(context.Background(), pg, s3Client)
+	assetStore, err := newAssetStore(context.Background(), db, objectStore)
 	if err != nil {
 		slog.With("error", err).Warn("Failed to create asset store, continuing without support for assets")
 	}
 
+	var cache store.Cache
+	switch cfg.Cache.Type {
+	case "redis":
+		redisClient, err := redis.New(cfg.Cache.Redis)
+		if err != nil {
+			slog.With("error", err).Error("Failed to create redis client")
+			return fmt.Errorf("failed to create redis client: %w", err)
+		}
+		defer redisClient.Close()
+
+		cache = redisClient
+		healthChecks["redis"] = redisClient.Ping
+	default:
+		cache = memory.NewCache(cfg.Cache.MaxEntries)
+	}
+
+	cacheTTL := time.Duration(cfg.Cache.TTLSeconds) * time.Second
+	messageInstanceStore := cached.NewMessageInstanceStore(db, cache, cacheTTL)
+	variableStore := cached.NewVariableStore(db, cache, cacheTTL)
+	variableValueStore := cached.NewVariableValueStore(db, cache, cacheTTL)
+	entitlementStore := cached.NewEntitlementStore(db, cache, cacheTTL)
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	shutdownTracing, err := tracing.SetupTracing(ctx, cfg.Tracing)
+	if err != nil {
+		slog.With("error", err).Error("Failed to setup tracing")
+		return fmt.Errorf("failed to setup tracing: %w", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	if cfg.Metrics.Enabled {
+		startMetricsServer(ctx, cfg.Metrics)
+	}
+
 	tokenCrypt, err := util.NewSymmetricCrypt(cfg.Encryption.TokenEncryptionKey)
 	if err != nil {
 		slog.With("error", err).Error("Failed to create token crypt")
@@ -55,68 +103,119 @@ func StartServer(c context.Context, cfg *config.Config) error {
 
 	var openaiClient openai.Client
 	if cfg.OpenAI.APIKey != "" {
-		openaiClient = openai.NewClient(option.WithAPIKey(cfg.OpenAI.APIKey))
+		openaiClient = openai.NewClient(
+			option.WithAPIKey(cfg.OpenAI.APIKey),
+			option.WithHTTPClient(&http.Client{
+				Transport: otelhttp.NewTransport(http.DefaultTransport),
+			}),
+		)
 	}
 
 	pluginRegistry := plugin.NewRegistry()
 	pluginRegistry.Register(
 		counting.NewCountingPlugin(),
+		leveling.NewLevelingPlugin(),
+		roles.NewRolesPlugin(),
 		starboard.NewStarboardPlugin(),
+		ticket.NewTicketPlugin(),
+	)
+
+	billingPlans := make([]model.Plan, len(cfg.Billing.Plans))
+	for i, plan := range cfg.Billing.Plans {
+		billingPlans[i] = model.Plan(plan)
+	}
+
+	planManager := plan.NewPlanManager(entitlementStore, db, db, billingPlans, plan.PlanManagerConfig{
+		DiscordBotToken: cfg.Discord.BotToken,
+		DiscordGuildID:  cfg.Discord.GuildID,
+	})
+
+	governor := engine.NewGovernor(
+		engine.GovernorConfig{
+			MaxConcurrentFlows:     cfg.Engine.MaxConcurrentFlowsPerApp,
+			MaxExecutionsPerSecond: cfg.Engine.MaxFlowExecutionsPerSecond,
+			ExecutionBurst:         cfg.Engine.FlowExecutionBurst,
+		},
+		db,
+		planManager,
 	)
 
+	wasmRuntime, err := wasm.NewRuntime(ctx, wasm.RuntimeConfig{
+		MaxMemory:   cfg.Engine.MaxModuleMemory,
+		MaxDuration: time.Duration(cfg.Engine.MaxModuleDurationMs) * time.Millisecond,
+		MaxFuel:     cfg.Engine.MaxModuleFuel,
+		MaxIOSize:   cfg.Engine.MaxModuleIOSize,
+	})
+	if err != nil {
+		slog.With("error", err).Error("Failed to create WASM runtime")
+		return fmt.Errorf("failed to create WASM runtime: %w", err)
+	}
+	defer wasmRuntime.Close(context.Background())
+
 	engine := engine.NewEngine(
 		engine.Env{
 			Config: engine.EngineConfig{
 				MaxStackDepth: cfg.Engine.MaxStackDepth,
 				MaxOperations: cfg.Engine.MaxOperations,
 				MaxCredits:    cfg.Engine.MaxCredits,
+				MaxFileSize:   cfg.UserLimits.MaxAssetSize,
 				ClusterCount:  cfg.ClusterCount,
 				ClusterIndex:  cfg.ClusterIndex,
 			},
-			AppStore:             pg,
-			LogStore:             pg,
-			UsageStore:           pg,
-			MessageStore:         pg,
-			MessageInstanceStore: pg,
-			CommandStore:         pg,
-			EventListenerStore:   pg,
-			PluginInstanceStore:  pg,
-			PluginValueStore:     pg,
+			AppStore:             db,
+			LogStore:             db,
+			UsageStore:           db,
+			MessageStore:         db,
+			MessageInstanceStore: messageInstanceStore,
+			CommandStore:         db,
+			EventListenerStore:   db,
+			PluginInstanceStore:  db,
+			PluginValueStore:     db,
 			PluginRegistry:       pluginRegistry,
-			VariableValueStore:   pg,
-			ResumePointStore:     pg,
+			VariableStore:        variableStore,
+			VariableValueStore:   variableValueStore,
+			NamespaceStore:       db,
+			ModuleStore:          db,
+			ResumePointStore:     db,
+			CooldownStore:        db,
+			AssetStore:           assetStore,
+			Governor:             governor,
 			HttpClient:           engineHTTPClient(cfg),
 			OpenaiClient:         &openaiClient,
 			TokenCrypt:           tokenCrypt,
+			WasmRuntime:          wasmRuntime,
 		},
 	)
 	engine.Run(ctx)
 
-	commandManager := command.NewCommandManager(pg, pg, pg, pluginRegistry, tokenCrypt)
-
-	handler := event.NewEventHandlerWrapper(engine, pg)
+	commandManager := command.NewCommandManager(db, db, db, pluginRegistry, tokenCrypt)
 
-	billingPlans := make([]model.Plan, len(cfg.Billing.Plans))
-	for i, plan := range cfg.Billing.Plans {
-		billingPlans[i] = model.Plan(plan)
-	}
+	handler := event.NewEventHandlerWrapper(engine, messageInstanceStore)
 
-	planManager := plan.NewPlanManager(pg, pg, pg, billingPlans, plan.PlanManagerConfig{
-		DiscordBotToken: cfg.Discord.BotToken,
-		DiscordGuildID:  cfg.Discord.GuildID,
+	gateway := gateway.NewGatewayManager(db, db, planManager, handler, tokenCrypt, gateway.GatewayManagerConfig{
+		ClusterCount: cfg.ClusterCount,
+		ClusterIndex: cfg.ClusterIndex,
 	})
+	gateway.Run(ctx)
+
+	usage := usage.NewUsageManager(db, db, db, planManager)
 
-	gateway := gateway.NewGatewayManager(pg, pg, planManager, handler, tokenCrypt, gateway.GatewayManagerConfig{
+	messageSyncManager := messagesync.NewSyncManager(db, db, messageInstanceStore, assetStore, gateway, messagesync.SyncManagerConfig{
 		ClusterCount: cfg.ClusterCount,
 		ClusterIndex: cfg.ClusterIndex,
 	})
-	gateway.Run(ctx)
+	messageSyncManager.Run(ctx)
 
-	usage := usage.NewUsageManager(pg, pg, pg, planManager)
+	janitor := maintenance.NewJanitor(janitorConfig(cfg.Maintenance), db, db, assetStore, db, messageInstanceStore, db, db, variableValueStore, tokenCrypt)
 
 	if cfg.IsPrimaryCluster() {
 		planManager.Run(ctx)
 		usage.Run(ctx)
+		janitor.Run(ctx)
+	}
+
+	healthChecks["gateway"] = func(ctx context.Context) error {
+		return gateway.Healthy()
 	}
 
 	apiServer := api.NewAPIServer(api.APIServerConfig{
@@ -129,7 +228,12 @@ func StartServer(c context.Context, cfg *config.Config) error {
 		DiscordClientID:     cfg.Discord.ClientID,
 		DiscordClientSecret: cfg.Discord.ClientSecret,
 		UserLimits: api.APIUserLimitsConfig{
-			MaxAppsPerUser: cfg.UserLimits.MaxAppsPerUser,
+			MaxAppsPerUser:           cfg.UserLimits.MaxAppsPerUser,
+			MaxModuleSize:            cfg.UserLimits.MaxModuleSize,
+			MaxModulesPerApp:         cfg.UserLimits.MaxModulesPerApp,
+			MaxNamespacesPerUser:     cfg.UserLimits.MaxNamespacesPerUser,
+			MaxVariablesPerNamespace: cfg.UserLimits.MaxVariablesPerNamespace,
+			MaxMembersPerNamespace:   cfg.UserLimits.MaxMembersPerNamespace,
 		},
 		Billing: api.BillingConfig{
 			LemonSqueezyAPIKey:        cfg.Billing.LemonSqueezyAPIKey,
@@ -139,8 +243,9 @@ func StartServer(c context.Context, cfg *config.Config) error {
 			Plans:                     cfg.Billing.Plans,
 		},
 	},
-		pg, pg, pg, pg, pg, pg, pg, pg, pg, pg, pg, pg, pg, pg,
-		assetStore, gateway, planManager, pluginRegistry, tokenCrypt, commandManager,
+		db, db, db, db, db, db, variableStore, variableValueStore, db, messageInstanceStore, db, db, db, entitlementStore,
+		assetStore, db, db, gateway, planManager, pluginRegistry, tokenCrypt, commandManager, messageSyncManager, wasmRuntime,
+		healthChecks,
 	)
 	address := fmt.Sprintf("%s:%d", cfg.API.Host, cfg.API.Port)
 	if err := apiServer.Serve(ctx, address); err != nil {
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+type Cooldown struct {
+	Key       string
+	AppID     string
+	Count     int
+	ExpiresAt time.Time
+}
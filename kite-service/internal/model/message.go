@@ -33,3 +33,43 @@ type MessageInstance struct {
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
 }
+
+type MessageSyncStatus string
+
+const (
+	MessageSyncStatusPending   MessageSyncStatus = "pending"
+	MessageSyncStatusRunning   MessageSyncStatus = "running"
+	MessageSyncStatusCompleted MessageSyncStatus = "completed"
+	MessageSyncStatusFailed    MessageSyncStatus = "failed"
+)
+
+// MessageSyncJob re-renders a message and edits all of its instances.
+type MessageSyncJob struct {
+	ID          string
+	AppID       string
+	MessageID   string
+	Status      MessageSyncStatus
+	Error       null.String
+	Results     []MessageInstanceSyncResult
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	StartedAt   null.Time
+	CompletedAt null.Time
+}
+
+type MessageInstanceSyncStatus string
+
+const (
+	MessageInstanceSyncStatusSuccess MessageInstanceSyncStatus = "success"
+	MessageInstanceSyncStatusFailed  MessageInstanceSyncStatus = "failed"
+	// MessageInstanceSyncStatusDeleted means the Discord message doesn't exist anymore and the instance has been deleted.
+	MessageInstanceSyncStatusDeleted MessageInstanceSyncStatus = "deleted"
+)
+
+type MessageInstanceSyncResult struct {
+	InstanceID       uint64                    `json:"instance_id"`
+	DiscordGuildID   string                    `json:"discord_guild_id"`
+	DiscordChannelID string                    `json:"discord_channel_id"`
+	Status           MessageInstanceSyncStatus `json:"status"`
+	Error            null.String               `json:"error"`
+}
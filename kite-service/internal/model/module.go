@@ -5,8 +5,16 @@ import (
 )
 
 type Module struct {
-	ID        string
-	WasmBytes []byte
-	WasmHash  string
+	ID            string
+	Name          string
+	Description   string
+	Enabled       bool
+	AppID         string
+	CreatorUserID string
+	WasmBytes     []byte
+	WasmHash      string
+	// WasmSize is the size of the WASM binary in bytes, it's also set when WasmBytes isn't loaded.
+	WasmSize  int
 	CreatedAt time.Time
+	UpdatedAt time.Time
 }
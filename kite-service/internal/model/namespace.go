@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// Namespace is a collection of variables that is owned by a user or their team and can be shared between apps.
+type Namespace struct {
+	ID          string
+	Name        string
+	Description null.String
+	OwnerUserID string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type NamespaceAccess string
+
+const (
+	NamespaceAccessRead      NamespaceAccess = "read"
+	NamespaceAccessReadWrite NamespaceAccess = "read_write"
+)
+
+func (a NamespaceAccess) CanWrite() bool {
+	return a == NamespaceAccessReadWrite
+}
+
+// NamespaceGrant gives an app access to the variables of a namespace.
+type NamespaceGrant struct {
+	NamespaceID string
+	AppID       string
+	Access      NamespaceAccess
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NamespaceMember is a user of the team that manages a namespace together with its owner.
+// Members can manage the variables and grants of the namespace, but only the owner can delete it or change the team.
+type NamespaceMember struct {
+	NamespaceID string
+	UserID      string
+	User        *User
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/kitecloud/kite/kite-service/pkg/provider"
 	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"gopkg.in/guregu/null.v4"
 )
 
 type PluginValue struct {
@@ -14,6 +15,8 @@ type PluginValue struct {
 	Value            thing.Thing `json:"value"`
 	CreatedAt        time.Time   `json:"created_at"`
 	UpdatedAt        time.Time   `json:"updated_at"`
+	// ExpiresAt is the time after which the value is treated as if it didn't exist.
+	ExpiresAt null.Time `json:"expires_at"`
 }
 
 type PluginValueOperation = provider.VariableOperation
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/kitecloud/kite/kite-service/pkg/provider"
@@ -9,16 +10,42 @@ import (
 )
 
 type Variable struct {
-	ID          string
-	Name        string
-	Scoped      bool
+	ID     string
+	Name   string
+	Scoped bool
+	// AppID is empty for variables that are owned by a namespace.
 	AppID       string
+	NamespaceID null.String
 	ModuleID    null.String
+	// Type is the type of all values, thing.TypeAny allows values of any type.
+	Type thing.Type
+	// Schema optionally validates values in addition to the type.
+	Schema *thing.Schema
+	// DefaultValue is used when there is no value, it's thing.Null if the variable has no default.
+	DefaultValue thing.Thing
+	// ValueTTL is how long values are kept after they have been written, 0 means forever.
+	ValueTTL    time.Duration
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	TotalValues null.Int
 }
 
+// ConvertValue converts the value to the type of the variable and validates it against the schema.
+func (v *Variable) ConvertValue(value thing.Thing) (thing.Thing, error) {
+	value, err := value.ConvertTo(v.Type)
+	if err != nil {
+		return thing.Null, fmt.Errorf("value doesn't match type %s of variable %s: %w", v.Type, v.Name, err)
+	}
+
+	if v.Schema != nil {
+		if err := v.Schema.Validate(value); err != nil {
+			return thing.Null, fmt.Errorf("value doesn't match schema of variable %s: %w", v.Name, err)
+		}
+	}
+
+	return value, nil
+}
+
 type VariableValue struct {
 	ID         uint64
 	VariableID string
@@ -26,6 +53,14 @@ type VariableValue struct {
 	Data       thing.Thing
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
+	// ExpiresAt is the time after which the value is treated as if it didn't exist.
+	ExpiresAt null.Time
 }
 
 type VariableValueOperation = provider.VariableOperation
+
+type VariableValueOperationArgs = provider.VariableOperationArgs
+
+type VariableValueQuery = provider.VariableQuery
+
+type VariableValueAggregate = provider.VariableAggregate
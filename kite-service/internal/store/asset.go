@@ -12,5 +12,13 @@ type AssetStore interface {
 	Asset(ctx context.Context, id string) (*model.Asset, error)
 	AssetWithContent(ctx context.Context, id string) (*model.Asset, error)
 	DeleteAsset(ctx context.Context, id string) error
-	DeleteExpiredAssets(ctx context.Context, timestamp time.Time) error
+	DeleteExpiredAssets(ctx context.Context, timestamp time.Time) (int, error)
+	// TemporaryAssetByContentHash returns the temporary asset of the app with the given content and name
+	// that expires last, if it doesn't expire before the given time.
+	TemporaryAssetByContentHash(ctx context.Context, appID string, contentHash string, name string, expiresAfter time.Time) (*model.Asset, error)
+	// AssetsWithoutExpiryAfterID returns the assets created before the given time that don't expire and don't belong to a module, ordered by ID.
+	AssetsWithoutExpiryAfterID(ctx context.Context, afterID string, createdBefore time.Time, limit int) ([]*model.Asset, error)
+	// ReferencedAssetIDs returns the IDs of the given assets that are referenced by anything in the app,
+	// like messages, flows, modules, plugin configs, plugin values and variable values.
+	ReferencedAssetIDs(ctx context.Context, appID string, assetIDs []string) ([]string, error)
 }
@@ -0,0 +1,15 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// Cache is a key-value store for data that can be recomputed from the other stores.
+// Get returns ErrNotFound if the key doesn't exist or has expired.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores the value for the key, a ttl of 0 keeps the value until it's evicted.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
@@ -0,0 +1,15 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+)
+
+type CooldownStore interface {
+	// HitCooldown registers a use of the cooldown bucket and returns its state for the current window.
+	// A new window of the given length is started if the previous one has expired.
+	HitCooldown(ctx context.Context, appID string, key string, window time.Duration, now time.Time) (*model.Cooldown, error)
+	DeleteExpiredCooldowns(ctx context.Context, now time.Time) (int, error)
+}
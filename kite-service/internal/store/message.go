@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/kitecloud/kite/kite-service/internal/model"
 )
@@ -23,4 +24,7 @@ type MessageInstanceStore interface {
 	UpdateMessageInstance(ctx context.Context, instance *model.MessageInstance) (*model.MessageInstance, error)
 	DeleteMessageInstance(ctx context.Context, messageID string, instanceID uint64) error
 	DeleteMessageInstanceByDiscordMessageID(ctx context.Context, discordMessageID string) error
+	// MessageInstancesAfterID returns non-ephemeral message instances ordered by ID, used to iterate over all instances in batches.
+	MessageInstancesAfterID(ctx context.Context, afterID uint64, limit int) ([]*model.MessageInstance, error)
+	DeleteEphemeralMessageInstancesBefore(ctx context.Context, timestamp time.Time) (int, error)
 }
@@ -0,0 +1,29 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+)
+
+type MessageSyncJobStore interface {
+	CreateMessageSyncJob(ctx context.Context, job *model.MessageSyncJob) error
+	// UpdateMessageSyncJob updates the status of the job. Results are stored with CreateMessageSyncJobResult.
+	UpdateMessageSyncJob(ctx context.Context, job *model.MessageSyncJob) error
+	// CreateMessageSyncJobResult stores the result of one instance and marks the job as updated at the given time,
+	// which keeps it from being considered stale.
+	CreateMessageSyncJobResult(ctx context.Context, jobID string, result model.MessageInstanceSyncResult, now time.Time) error
+	// LatestMessageSyncJob returns the most recently created sync job of the message together with its results.
+	LatestMessageSyncJob(ctx context.Context, messageID string) (*model.MessageSyncJob, error)
+	CountPendingMessageSyncJobsByApp(ctx context.Context, appID string) (int, error)
+	// PendingMessageSyncJobs returns the oldest pending sync jobs of all apps.
+	PendingMessageSyncJobs(ctx context.Context, limit int) ([]*model.MessageSyncJob, error)
+	// ClaimMessageSyncJob marks a pending sync job as running.
+	// It returns false if the job isn't pending anymore, because another worker has claimed it first.
+	ClaimMessageSyncJob(ctx context.Context, id string, now time.Time) (bool, error)
+	// ResetStaleMessageSyncJobs moves running sync jobs that haven't been updated since the given time back to pending,
+	// so jobs of a worker that has stopped are picked up again. The results of these jobs are deleted.
+	ResetStaleMessageSyncJobs(ctx context.Context, updatedBefore time.Time) (int, error)
+	DeleteMessageSyncJobsCompletedBefore(ctx context.Context, before time.Time) (int, error)
+}
@@ -0,0 +1,20 @@
+package store
+
+import (
+	"context"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+)
+
+type ModuleStore interface {
+	// ModulesByApp returns the modules of the app without their WASM binaries.
+	ModulesByApp(ctx context.Context, appID string) ([]*model.Module, error)
+	CountModulesByApp(ctx context.Context, appID string) (int, error)
+	Module(ctx context.Context, id string) (*model.Module, error)
+	// ModuleMeta returns the module without its WASM binary.
+	ModuleMeta(ctx context.Context, id string) (*model.Module, error)
+	CreateModule(ctx context.Context, module *model.Module) (*model.Module, error)
+	UpdateModule(ctx context.Context, module *model.Module) (*model.Module, error)
+	UpdateModuleWasm(ctx context.Context, module *model.Module) (*model.Module, error)
+	DeleteModule(ctx context.Context, id string) error
+}
@@ -0,0 +1,30 @@
+package store
+
+import (
+	"context"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+)
+
+type NamespaceStore interface {
+	NamespacesByOwner(ctx context.Context, ownerUserID string) ([]*model.Namespace, error)
+	// NamespacesByMember returns the namespaces that the user owns or is a member of.
+	NamespacesByMember(ctx context.Context, userID string) ([]*model.Namespace, error)
+	Namespace(ctx context.Context, id string) (*model.Namespace, error)
+	CreateNamespace(ctx context.Context, namespace *model.Namespace) (*model.Namespace, error)
+	UpdateNamespace(ctx context.Context, namespace *model.Namespace) (*model.Namespace, error)
+	DeleteNamespace(ctx context.Context, id string) error
+
+	NamespaceGrant(ctx context.Context, namespaceID string, appID string) (*model.NamespaceGrant, error)
+	NamespaceGrantsByNamespace(ctx context.Context, namespaceID string) ([]*model.NamespaceGrant, error)
+	NamespaceGrantsByApp(ctx context.Context, appID string) ([]*model.NamespaceGrant, error)
+	// UpsertNamespaceGrant creates the grant or updates the access of an existing grant.
+	UpsertNamespaceGrant(ctx context.Context, grant *model.NamespaceGrant) (*model.NamespaceGrant, error)
+	DeleteNamespaceGrant(ctx context.Context, namespaceID string, appID string) error
+
+	NamespaceMember(ctx context.Context, namespaceID string, userID string) (*model.NamespaceMember, error)
+	NamespaceMembersByNamespace(ctx context.Context, namespaceID string) ([]*model.NamespaceMember, error)
+	CountNamespaceMembersByNamespace(ctx context.Context, namespaceID string) (int, error)
+	CreateNamespaceMember(ctx context.Context, member *model.NamespaceMember) (*model.NamespaceMember, error)
+	DeleteNamespaceMember(ctx context.Context, namespaceID string, userID string) error
+}
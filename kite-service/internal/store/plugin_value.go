@@ -2,13 +2,33 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
 )
 
+// PluginValueStore stores the values of plugin instances.
+// Expired values are treated as if they didn't exist by all methods.
 type PluginValueStore interface {
 	SetPluginValue(ctx context.Context, value model.PluginValue) error
+	// SetPluginValues sets all values in a single transaction.
+	SetPluginValues(ctx context.Context, values []model.PluginValue) error
+	// UpdatePluginValue applies the operation to the current value.
+	// Overwriting replaces the expiry of the value, all other operations keep it.
 	UpdatePluginValue(ctx context.Context, operation model.PluginValueOperation, value model.PluginValue) (*model.PluginValue, error)
+	// CompareAndSwapPluginValue only sets the value when the current value equals old.
+	// thing.Null as old means that the value must not exist. It returns whether the value has been set.
+	CompareAndSwapPluginValue(ctx context.Context, old thing.Thing, value model.PluginValue) (bool, error)
 	GetPluginValue(ctx context.Context, pluginInstanceID, key string) (*model.PluginValue, error)
+	// PluginValuesByKeys returns the values for the given keys, keys without a value are omitted.
+	PluginValuesByKeys(ctx context.Context, pluginInstanceID string, keys []string) ([]*model.PluginValue, error)
 	DeletePluginValue(ctx context.Context, pluginInstanceID, key string) error
+	// PluginValuesByKeyPrefix returns the values whose key starts with the given prefix.
+	// Numeric values come first ordered from highest to lowest, followed by all other values.
+	PluginValuesByKeyPrefix(ctx context.Context, pluginInstanceID, keyPrefix string, limit int, offset int) ([]*model.PluginValue, error)
+	// CountPluginValuesByKeyPrefixAbove returns the number of numeric values whose key starts with
+	// the given prefix and which are greater than the given value.
+	CountPluginValuesByKeyPrefixAbove(ctx context.Context, pluginInstanceID, keyPrefix string, value float64) (int, error)
+	DeleteExpiredPluginValues(ctx context.Context, now time.Time) (int, error)
 }
@@ -10,6 +10,7 @@ import (
 type ResumePointStore interface {
 	CreateResumePoint(ctx context.Context, resumePoint *model.ResumePoint) error
 	DeleteResumePoint(ctx context.Context, id string) error
-	DeleteExpiredResumePoints(ctx context.Context, timestamp time.Time) error
+	DeleteExpiredResumePoints(ctx context.Context, timestamp time.Time) (int, error)
+	DeleteResumePointsCreatedBefore(ctx context.Context, resumePointType model.ResumePointType, timestamp time.Time) (int, error)
 	ResumePoint(ctx context.Context, id string) (*model.ResumePoint, error)
 }
@@ -0,0 +1,312 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/internal/util"
+	"github.com/kitecloud/kite/kite-service/pkg/flow"
+	"github.com/kitecloud/kite/kite-service/pkg/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func testUsers(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+
+	res, err := s.User(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.Email, res.Email)
+	assert.Equal(t, user.DiscordUsername, res.DiscordUsername)
+	assert.False(t, res.DiscordAvatar.Valid)
+
+	res, err = s.UserByEmail(ctx, user.Email)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, res.ID)
+
+	// Upserting by Discord ID keeps the original user ID
+	updated, err := s.UpsertUser(ctx, &model.User{
+		ID:              util.UniqueID(),
+		Email:           "updated@example.com",
+		DisplayName:     "Updated",
+		DiscordID:       user.DiscordID,
+		DiscordUsername: "updated",
+		DiscordAvatar:   null.StringFrom("avatar"),
+		CreatedAt:       now(),
+		UpdatedAt:       now(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, updated.ID)
+	assert.Equal(t, "updated@example.com", updated.Email)
+	assert.Equal(t, null.StringFrom("avatar"), updated.DiscordAvatar)
+
+	res, err = s.UserByDiscordID(ctx, user.DiscordID)
+	require.NoError(t, err)
+	assert.Equal(t, "Updated", res.DisplayName)
+
+	_, err = s.User(ctx, "missing")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+	_, err = s.UserByEmail(ctx, "missing@example.com")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+	_, err = s.UserByDiscordID(ctx, "missing")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func testSessions(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	keyHash := util.HashKey(util.SecureKey())
+
+	session, err := s.CreateSession(ctx, &model.Session{
+		KeyHash:   keyHash,
+		UserID:    user.ID,
+		CreatedAt: now(),
+		ExpiresAt: now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	res, err := s.Session(ctx, keyHash)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, res.UserID)
+	assertTimeEqual(t, session.ExpiresAt, res.ExpiresAt)
+
+	require.NoError(t, s.DeleteSession(ctx, keyHash))
+
+	_, err = s.Session(ctx, keyHash)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func testApps(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	assert.True(t, app.Enabled)
+	assert.Nil(t, app.DiscordStatus)
+
+	credentials, err := s.AppCredentials(ctx, app.ID)
+	require.NoError(t, err)
+	assert.Equal(t, app.DiscordID, credentials.DiscordID)
+	assert.Equal(t, app.DiscordToken, credentials.DiscordToken)
+
+	updatedAt := now().Add(time.Second)
+	status := &model.AppDiscordStatus{
+		Status:       "idle",
+		ActivityType: 1,
+		ActivityName: "Testing",
+	}
+	updated, err := s.UpdateApp(ctx, store.AppUpdateOpts{
+		ID:            app.ID,
+		Name:          "Updated",
+		Description:   null.StringFrom("Description"),
+		DiscordToken:  "new_token",
+		DiscordStatus: status,
+		Enabled:       true,
+		UpdatedAt:     updatedAt,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Updated", updated.Name)
+	assert.Equal(t, null.StringFrom("Description"), updated.Description)
+	assert.True(t, status.Equals(updated.DiscordStatus))
+
+	res, err := s.App(ctx, app.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "new_token", res.DiscordToken)
+	assert.True(t, status.Equals(res.DiscordStatus))
+	assertTimeEqual(t, updatedAt, res.UpdatedAt)
+
+	apps, err := s.EnabledAppsUpdatedSince(ctx, app.UpdatedAt)
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	assert.Equal(t, app.ID, apps[0].ID)
+
+	ids, err := s.EnabledAppIDs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{app.ID}, ids)
+
+	err = s.DisableApp(ctx, store.AppDisableOpts{
+		ID:             app.ID,
+		DisabledReason: null.StringFrom("Disabled"),
+		UpdatedAt:      updatedAt.Add(time.Second),
+	})
+	require.NoError(t, err)
+
+	res, err = s.App(ctx, app.ID)
+	require.NoError(t, err)
+	assert.False(t, res.Enabled)
+	assert.Equal(t, null.StringFrom("Disabled"), res.DisabledReason)
+
+	ids, err = s.EnabledAppIDs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+
+	apps, err = s.EnabledAppsUpdatedSince(ctx, app.UpdatedAt)
+	require.NoError(t, err)
+	assert.Empty(t, apps)
+
+	require.NoError(t, s.DeleteApp(ctx, app.ID))
+
+	_, err = s.App(ctx, app.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+	_, err = s.AppCredentials(ctx, app.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func testAppsByUser(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	owner := createUser(t, s)
+	collaborator := createUser(t, s)
+	other := createUser(t, s)
+
+	app := createApp(t, s, owner.ID)
+	otherApp := createApp(t, s, other.ID)
+
+	for _, user := range []*model.User{collaborator, other} {
+		_, err := s.CreateCollaborator(ctx, &model.AppCollaborator{
+			AppID:     app.ID,
+			UserID:    user.ID,
+			Role:      model.AppCollaboratorRoleAdmin,
+			CreatedAt: now(),
+			UpdatedAt: now(),
+		})
+		require.NoError(t, err)
+	}
+
+	// The owner must only get the app once, no matter how many collaborators it has
+	apps, err := s.AppsByUser(ctx, owner.ID)
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	assert.Equal(t, app.ID, apps[0].ID)
+
+	apps, err = s.AppsByUser(ctx, collaborator.ID)
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	assert.Equal(t, app.ID, apps[0].ID)
+
+	apps, err = s.AppsByUser(ctx, other.ID)
+	require.NoError(t, err)
+	appIDs := make([]string, len(apps))
+	for i, a := range apps {
+		appIDs[i] = a.ID
+	}
+	assert.ElementsMatch(t, []string{app.ID, otherApp.ID}, appIDs)
+
+	// Only owned apps are counted
+	count, err := s.CountAppsByUser(ctx, owner.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	count, err = s.CountAppsByUser(ctx, collaborator.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func testCollaborators(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	owner := createUser(t, s)
+	user := createUser(t, s)
+	app := createApp(t, s, owner.ID)
+
+	_, err := s.Collaborator(ctx, app.ID, user.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	_, err = s.CreateCollaborator(ctx, &model.AppCollaborator{
+		AppID:     app.ID,
+		UserID:    user.ID,
+		Role:      model.AppCollaboratorRoleAdmin,
+		CreatedAt: now(),
+		UpdatedAt: now(),
+	})
+	require.NoError(t, err)
+
+	collaborator, err := s.Collaborator(ctx, app.ID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.AppCollaboratorRoleAdmin, collaborator.Role)
+	require.NotNil(t, collaborator.User)
+	assert.Equal(t, user.Email, collaborator.User.Email)
+
+	_, err = s.UpdateCollaborator(ctx, &model.AppCollaborator{
+		AppID:     app.ID,
+		UserID:    user.ID,
+		Role:      model.AppCollaboratorRoleOwner,
+		UpdatedAt: now(),
+	})
+	require.NoError(t, err)
+
+	collaborators, err := s.CollaboratorsByApp(ctx, app.ID)
+	require.NoError(t, err)
+	require.Len(t, collaborators, 1)
+	assert.Equal(t, model.AppCollaboratorRoleOwner, collaborators[0].Role)
+	require.NotNil(t, collaborators[0].User)
+	assert.Equal(t, user.ID, collaborators[0].User.ID)
+
+	count, err := s.CountCollaboratorsByApp(ctx, app.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	require.NoError(t, s.DeleteCollaborator(ctx, app.ID, user.ID))
+
+	count, err = s.CountCollaboratorsByApp(ctx, app.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func testAppEntities(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	otherApp := createApp(t, s, user.ID)
+
+	command := createCommand(t, s, app, "ping")
+	variable := createVariable(t, s, app, false)
+	createCommand(t, s, otherApp, "other")
+
+	msg, err := s.CreateMessage(ctx, &model.Message{
+		ID:            util.UniqueID(),
+		Name:          "Welcome",
+		AppID:         app.ID,
+		CreatorUserID: user.ID,
+		Data:          message.MessageData{Content: "Hello"},
+		FlowSources:   map[string]flow.FlowData{},
+		CreatedAt:     now(),
+		UpdatedAt:     now(),
+	})
+	require.NoError(t, err)
+
+	listener, err := s.CreateEventListener(ctx, &model.EventListener{
+		ID:            util.UniqueID(),
+		Source:        model.EventSourceDiscord,
+		Type:          model.EventListenerTypeDiscordMessageCreate,
+		Enabled:       true,
+		AppID:         app.ID,
+		CreatorUserID: user.ID,
+		FlowSource:    flow.FlowData{},
+		CreatedAt:     now(),
+		UpdatedAt:     now(),
+	})
+	require.NoError(t, err)
+
+	entities, err := s.AppEntities(ctx, app.ID)
+	require.NoError(t, err)
+
+	res := make([]model.AppEntity, len(entities))
+	for i, entity := range entities {
+		res[i] = *entity
+	}
+	assert.ElementsMatch(t, []model.AppEntity{
+		{ID: command.ID, Type: model.AppEntityTypeCommand, Name: command.Name},
+		{ID: listener.ID, Type: model.AppEntityTypeEventListener, Name: string(listener.Type)},
+		{ID: msg.ID, Type: model.AppEntityTypeMessage, Name: msg.Name},
+		{ID: variable.ID, Type: model.AppEntityTypeVariable, Name: variable.Name},
+	}, res)
+}
@@ -0,0 +1,154 @@
+package storetest
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/util"
+	"github.com/kitecloud/kite/kite-service/pkg/flow"
+	"github.com/kitecloud/kite/kite-service/pkg/message"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func createAsset(t *testing.T, s Store, app *model.App, id string, createdAt time.Time, expiresAt null.Time) *model.Asset {
+	t.Helper()
+
+	content := []byte("content " + id)
+	asset, err := s.CreateAsset(context.Background(), &model.Asset{
+		ID:            id,
+		AppID:         app.ID,
+		CreatorUserID: app.OwnerUserID,
+		Name:          id + ".txt",
+		ContentType:   "text/plain",
+		ContentHash:   util.UniqueID(),
+		ContentSize:   len(content),
+		Content:       content,
+		CreatedAt:     createdAt,
+		UpdatedAt:     createdAt,
+		ExpiresAt:     expiresAt,
+	})
+	require.NoError(t, err)
+	return asset
+}
+
+func testAssetsWithoutExpiry(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+
+	createdAt := now().Add(-time.Hour)
+	createAsset(t, s, app, "asset_1", createdAt, null.Time{})
+	createAsset(t, s, app, "asset_2", createdAt, null.Time{})
+	createAsset(t, s, app, "asset_3", createdAt, null.Time{})
+	// Temporary and recently created assets are never returned
+	createAsset(t, s, app, "asset_4", createdAt, null.TimeFrom(now().Add(time.Hour)))
+	createAsset(t, s, app, "asset_5", now(), null.Time{})
+
+	assets, err := s.AssetsWithoutExpiryAfterID(ctx, "", now().Add(-time.Minute), 2)
+	require.NoError(t, err)
+	require.Len(t, assets, 2)
+	assert.Equal(t, "asset_1", assets[0].ID)
+	assert.Equal(t, "asset_2", assets[1].ID)
+
+	assets, err = s.AssetsWithoutExpiryAfterID(ctx, assets[1].ID, now().Add(-time.Minute), 2)
+	require.NoError(t, err)
+	require.Len(t, assets, 1)
+	assert.Equal(t, "asset_3", assets[0].ID)
+	assert.Equal(t, app.ID, assets[0].AppID)
+}
+
+func testReferencedAssetIDs(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	otherApp := createApp(t, s, user.ID)
+
+	ids := make([]string, 6)
+	for i := range ids {
+		ids[i] = util.UniqueID()
+		createAsset(t, s, app, ids[i], now(), null.Time{})
+	}
+
+	// Referenced by a message
+	_, err := s.CreateMessage(ctx, &model.Message{
+		ID:            util.UniqueID(),
+		Name:          "Message",
+		AppID:         app.ID,
+		CreatorUserID: user.ID,
+		Data:          message.MessageData{Content: "https://api.kite.onl/v1/assets/" + ids[0]},
+		FlowSources:   map[string]flow.FlowData{},
+		CreatedAt:     now(),
+		UpdatedAt:     now(),
+	})
+	require.NoError(t, err)
+
+	// Referenced by a plugin value
+	instance := createPluginInstance(t, s, app, "leveling")
+	setPluginValue(t, s, instance.ID, "key", thing.NewString(ids[1]), null.Time{})
+
+	// Referenced by a value of a namespace variable that the app has been granted access to
+	namespace, err := s.CreateNamespace(ctx, &model.Namespace{
+		ID:          util.UniqueID(),
+		Name:        "Shared",
+		OwnerUserID: user.ID,
+		CreatedAt:   now(),
+		UpdatedAt:   now(),
+	})
+	require.NoError(t, err)
+	_, err = s.UpsertNamespaceGrant(ctx, &model.NamespaceGrant{
+		NamespaceID: namespace.ID,
+		AppID:       app.ID,
+		Access:      model.NamespaceAccessRead,
+		CreatedAt:   now(),
+		UpdatedAt:   now(),
+	})
+	require.NoError(t, err)
+	variable, err := s.CreateVariable(ctx, &model.Variable{
+		ID:           util.UniqueID(),
+		Name:         "shared",
+		Scoped:       true,
+		NamespaceID:  null.StringFrom(namespace.ID),
+		Type:         thing.TypeAny,
+		DefaultValue: thing.Null,
+		CreatedAt:    now(),
+		UpdatedAt:    now(),
+	})
+	require.NoError(t, err)
+	setVariableValue(t, s, variable.ID, null.StringFrom("user"), thing.NewString(ids[2]))
+
+	// Referenced by the default value of a variable of the app
+	_, err = s.CreateVariable(ctx, &model.Variable{
+		ID:           util.UniqueID(),
+		Name:         "image",
+		AppID:        app.ID,
+		Type:         thing.TypeString,
+		DefaultValue: thing.NewString(ids[3]),
+		CreatedAt:    now(),
+		UpdatedAt:    now(),
+	})
+	require.NoError(t, err)
+
+	// References of other apps don't count
+	otherVariable := createVariable(t, s, otherApp, false)
+	setVariableValue(t, s, otherVariable.ID, null.String{}, thing.NewString(ids[4]))
+
+	referenced, err := s.ReferencedAssetIDs(ctx, app.ID, ids)
+	require.NoError(t, err)
+	sort.Strings(referenced)
+
+	expected := []string{ids[0], ids[1], ids[2], ids[3]}
+	sort.Strings(expected)
+	assert.Equal(t, expected, referenced)
+
+	referenced, err = s.ReferencedAssetIDs(ctx, app.ID, []string{ids[5]})
+	require.NoError(t, err)
+	assert.Empty(t, referenced)
+}
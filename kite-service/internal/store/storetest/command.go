@@ -0,0 +1,166 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/internal/util"
+	"github.com/kitecloud/kite/kite-service/pkg/flow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCommands(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+
+	command := createCommand(t, s, app, "ping")
+	assert.False(t, command.LastDeployedAt.Valid)
+
+	res, err := s.Command(ctx, command.ID)
+	require.NoError(t, err)
+	assert.Equal(t, command.Name, res.Name)
+	assert.Equal(t, app.ID, res.AppID)
+
+	command.Description = "Updated"
+	command.Enabled = false
+	command.FlowSource = flow.FlowData{
+		Nodes: []flow.FlowNode{{ID: "root", Type: flow.FlowNodeTypeEntryCommand}},
+		Edges: []flow.FlowEdge{},
+	}
+	command.UpdatedAt = now().Add(time.Second)
+	updated, err := s.UpdateCommand(ctx, command)
+	require.NoError(t, err)
+	assert.Equal(t, "Updated", updated.Description)
+	assert.False(t, updated.Enabled)
+	require.Len(t, updated.FlowSource.Nodes, 1)
+	assert.Equal(t, "root", updated.FlowSource.Nodes[0].ID)
+
+	other := createCommand(t, s, app, "pong")
+
+	commands, err := s.CommandsByApp(ctx, app.ID)
+	require.NoError(t, err)
+	assert.Len(t, commands, 2)
+
+	count, err := s.CountCommandsByApp(ctx, app.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Only enabled commands are returned
+	ids, err := s.EnabledCommandIDs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{other.ID}, ids)
+
+	commands, err = s.EnabledCommandsUpdatedSince(ctx, other.UpdatedAt.Add(-time.Second))
+	require.NoError(t, err)
+	require.Len(t, commands, 1)
+	assert.Equal(t, other.ID, commands[0].ID)
+
+	commands, err = s.EnabledCommandsUpdatedSince(ctx, other.UpdatedAt)
+	require.NoError(t, err)
+	assert.Empty(t, commands)
+
+	require.NoError(t, s.DeleteCommand(ctx, command.ID))
+
+	_, err = s.Command(ctx, command.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	_, err = s.UpdateCommand(ctx, command)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func testCommandDeployment(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	otherApp := createApp(t, s, user.ID)
+
+	command := createCommand(t, s, app, "ping")
+	createCommand(t, s, otherApp, "pong")
+
+	appIDs, err := s.DinstinctAppIDsWithUndeployedCommands(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{app.ID, otherApp.ID}, appIDs)
+
+	deployedAt := now().Add(time.Second)
+	require.NoError(t, s.UpdateCommandsLastDeployedAt(ctx, app.ID, deployedAt))
+
+	res, err := s.Command(ctx, command.ID)
+	require.NoError(t, err)
+	require.True(t, res.LastDeployedAt.Valid)
+	assertTimeEqual(t, deployedAt, res.LastDeployedAt.Time)
+
+	appIDs, err = s.DinstinctAppIDsWithUndeployedCommands(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{otherApp.ID}, appIDs)
+
+	// Updating a command after it has been deployed requires a new deployment
+	command.UpdatedAt = deployedAt.Add(time.Second)
+	_, err = s.UpdateCommand(ctx, command)
+	require.NoError(t, err)
+
+	appIDs, err = s.DinstinctAppIDsWithUndeployedCommands(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{app.ID, otherApp.ID}, appIDs)
+}
+
+func testEventListeners(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+
+	listener, err := s.CreateEventListener(ctx, &model.EventListener{
+		ID:            util.UniqueID(),
+		Source:        model.EventSourceDiscord,
+		Type:          model.EventListenerTypeDiscordMessageCreate,
+		Description:   "Listener",
+		Enabled:       true,
+		AppID:         app.ID,
+		CreatorUserID: user.ID,
+		FlowSource:    flow.FlowData{},
+		CreatedAt:     now(),
+		UpdatedAt:     now(),
+	})
+	require.NoError(t, err)
+
+	res, err := s.EventListener(ctx, listener.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.EventSourceDiscord, res.Source)
+	assert.Equal(t, model.EventListenerTypeDiscordMessageCreate, res.Type)
+
+	listeners, err := s.EnabledEventListenersUpdatedSince(ctx, listener.UpdatedAt.Add(-time.Second))
+	require.NoError(t, err)
+	assert.Len(t, listeners, 1)
+
+	listener.Type = model.EventListenerTypeDiscordGuildMemberAdd
+	listener.Enabled = false
+	listener.UpdatedAt = now().Add(time.Second)
+	updated, err := s.UpdateEventListener(ctx, listener)
+	require.NoError(t, err)
+	assert.Equal(t, model.EventListenerTypeDiscordGuildMemberAdd, updated.Type)
+	assert.False(t, updated.Enabled)
+
+	ids, err := s.EnabledEventListenerIDs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+
+	listeners, err = s.EventListenersByApp(ctx, app.ID)
+	require.NoError(t, err)
+	assert.Len(t, listeners, 1)
+
+	count, err := s.CountEventListenersByApp(ctx, app.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	require.NoError(t, s.DeleteEventListener(ctx, listener.ID))
+
+	_, err = s.EventListener(ctx, listener.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
@@ -0,0 +1,50 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCooldowns(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+
+	start := now()
+	window := time.Minute
+
+	cooldown, err := s.HitCooldown(ctx, app.ID, "key", window, start)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cooldown.Count)
+	assert.Equal(t, app.ID, cooldown.AppID)
+	assertTimeEqual(t, start.Add(window), cooldown.ExpiresAt)
+
+	// Hits within the window count towards it without extending it
+	cooldown, err = s.HitCooldown(ctx, app.ID, "key", window, start.Add(30*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 2, cooldown.Count)
+	assertTimeEqual(t, start.Add(window), cooldown.ExpiresAt)
+
+	cooldown, err = s.HitCooldown(ctx, app.ID, "other", window, start)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cooldown.Count)
+
+	// A hit after the window has expired starts a new window
+	cooldown, err = s.HitCooldown(ctx, app.ID, "key", window, start.Add(window))
+	require.NoError(t, err)
+	assert.Equal(t, 1, cooldown.Count)
+	assertTimeEqual(t, start.Add(2*window), cooldown.ExpiresAt)
+
+	deleted, err := s.DeleteExpiredCooldowns(ctx, start.Add(window+time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	cooldown, err = s.HitCooldown(ctx, app.ID, "key", window, start.Add(window+time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 2, cooldown.Count)
+}
@@ -0,0 +1,111 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func createLogEntry(t *testing.T, s Store, entry model.LogEntry) {
+	t.Helper()
+
+	require.NoError(t, s.CreateLogEntry(context.Background(), entry))
+}
+
+func testLogs(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	command := createCommand(t, s, app, "ping")
+	msg := createMessage(t, s, app)
+
+	start := now().Add(-time.Hour)
+
+	createLogEntry(t, s, model.LogEntry{AppID: app.ID, Level: model.LogLevelError, Message: "error", CommandID: null.StringFrom(command.ID), CreatedAt: start.Add(time.Minute)})
+	createLogEntry(t, s, model.LogEntry{AppID: app.ID, Level: model.LogLevelWarn, Message: "warn", MessageID: null.StringFrom(msg.ID), CreatedAt: start.Add(2 * time.Minute)})
+	createLogEntry(t, s, model.LogEntry{AppID: app.ID, Level: model.LogLevelInfo, Message: "info", CreatedAt: start.Add(3 * time.Minute)})
+	createLogEntry(t, s, model.LogEntry{AppID: app.ID, Level: model.LogLevelInfo, Message: "old", CreatedAt: start.Add(-time.Hour)})
+
+	entries, err := s.LogEntriesByCommand(ctx, app.ID, command.ID, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "error", entries[0].Message)
+	assert.Equal(t, model.LogLevelError, entries[0].Level)
+	assert.Equal(t, null.StringFrom(command.ID), entries[0].CommandID)
+
+	entries, err = s.LogEntriesByMessage(ctx, app.ID, msg.ID, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "warn", entries[0].Message)
+
+	entries, err = s.LogEntriesByEvent(ctx, app.ID, "missing", 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// The summary includes the start and excludes the end
+	summary, err := s.LogSummary(ctx, app.ID, start, start.Add(3*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, model.LogSummary{
+		TotalEntries:  2,
+		TotalErrors:   1,
+		TotalWarnings: 1,
+	}, *summary)
+
+	summary, err = s.LogSummary(ctx, app.ID, start.Add(-2*time.Hour), now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), summary.TotalEntries)
+	assert.Equal(t, int64(2), summary.TotalInfos)
+
+	require.NoError(t, s.DeleteLogEntriesBefore(ctx, start))
+
+	entries, err = s.LogEntriesByApp(ctx, app.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
+
+func testLogPagination(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	otherApp := createApp(t, s, user.ID)
+
+	start := now().Add(-time.Hour)
+	for i := 0; i < 5; i++ {
+		createLogEntry(t, s, model.LogEntry{
+			AppID:     app.ID,
+			Level:     model.LogLevelInfo,
+			Message:   string(rune('a' + i)),
+			CreatedAt: start.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	createLogEntry(t, s, model.LogEntry{AppID: otherApp.ID, Level: model.LogLevelInfo, Message: "other", CreatedAt: start})
+
+	// Pages go from the newest to the oldest entry, the ID of the last entry is the cursor for the next page
+	var pages [][]string
+	beforeID := int64(0)
+	for {
+		entries, err := s.LogEntriesByApp(ctx, app.ID, beforeID, 2)
+		require.NoError(t, err)
+		if len(entries) == 0 {
+			break
+		}
+
+		page := make([]string, len(entries))
+		for i, entry := range entries {
+			page[i] = entry.Message
+		}
+		pages = append(pages, page)
+		beforeID = entries[len(entries)-1].ID
+
+		require.Less(t, len(pages), 5, "pagination doesn't end")
+	}
+
+	assert.Equal(t, [][]string{{"e", "d"}, {"c", "b"}, {"a"}}, pages)
+}
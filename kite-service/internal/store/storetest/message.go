@@ -0,0 +1,233 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/internal/util"
+	"github.com/kitecloud/kite/kite-service/pkg/flow"
+	"github.com/kitecloud/kite/kite-service/pkg/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func createMessage(t *testing.T, s Store, app *model.App) *model.Message {
+	t.Helper()
+
+	msg, err := s.CreateMessage(context.Background(), &model.Message{
+		ID:            util.UniqueID(),
+		Name:          "Welcome",
+		AppID:         app.ID,
+		CreatorUserID: app.OwnerUserID,
+		Data:          message.MessageData{Content: "Hello"},
+		FlowSources:   map[string]flow.FlowData{},
+		CreatedAt:     now(),
+		UpdatedAt:     now(),
+	})
+	require.NoError(t, err)
+	return msg
+}
+
+func createMessageInstance(t *testing.T, s Store, msg *model.Message, discordMessageID string, ephemeral bool, createdAt time.Time) *model.MessageInstance {
+	t.Helper()
+
+	instance, err := s.CreateMessageInstance(context.Background(), &model.MessageInstance{
+		MessageID:        msg.ID,
+		DiscordGuildID:   "1",
+		DiscordChannelID: "2",
+		DiscordMessageID: discordMessageID,
+		Ephemeral:        ephemeral,
+		FlowSources:      map[string]flow.FlowData{},
+		CreatedAt:        createdAt,
+		UpdatedAt:        createdAt,
+	})
+	require.NoError(t, err)
+	return instance
+}
+
+func testMessages(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+
+	msg := createMessage(t, s, app)
+	assert.False(t, msg.Description.Valid)
+
+	res, err := s.Message(ctx, msg.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", res.Data.Content)
+
+	msg.Description = null.StringFrom("Description")
+	msg.Data = message.MessageData{Content: "Updated"}
+	msg.FlowSources = map[string]flow.FlowData{
+		"button": {Nodes: []flow.FlowNode{{ID: "root"}}},
+	}
+	msg.UpdatedAt = now().Add(time.Second)
+	_, err = s.UpdateMessage(ctx, msg)
+	require.NoError(t, err)
+
+	res, err = s.Message(ctx, msg.ID)
+	require.NoError(t, err)
+	assert.Equal(t, null.StringFrom("Description"), res.Description)
+	assert.Equal(t, "Updated", res.Data.Content)
+	require.Contains(t, res.FlowSources, "button")
+	assert.Equal(t, "root", res.FlowSources["button"].Nodes[0].ID)
+
+	messages, err := s.MessagesByApp(ctx, app.ID)
+	require.NoError(t, err)
+	assert.Len(t, messages, 1)
+
+	count, err := s.CountMessagesByApp(ctx, app.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	require.NoError(t, s.DeleteMessage(ctx, msg.ID))
+
+	_, err = s.Message(ctx, msg.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func testMessageInstances(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	msg := createMessage(t, s, app)
+
+	instance := createMessageInstance(t, s, msg, "100", false, now())
+	ephemeral := createMessageInstance(t, s, msg, "101", true, now().Add(-time.Hour))
+	assert.NotZero(t, instance.ID)
+	assert.NotEqual(t, instance.ID, ephemeral.ID)
+
+	res, err := s.MessageInstance(ctx, msg.ID, instance.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "100", res.DiscordMessageID)
+	assert.False(t, res.Hidden)
+
+	res, err = s.MessageInstanceByDiscordMessageID(ctx, "101")
+	require.NoError(t, err)
+	assert.Equal(t, ephemeral.ID, res.ID)
+	assert.True(t, res.Ephemeral)
+
+	instance.FlowSources = map[string]flow.FlowData{"button": {}}
+	instance.UpdatedAt = now().Add(time.Second)
+	_, err = s.UpdateMessageInstance(ctx, instance)
+	require.NoError(t, err)
+
+	res, err = s.MessageInstance(ctx, msg.ID, instance.ID)
+	require.NoError(t, err)
+	assert.Contains(t, res.FlowSources, "button")
+
+	instances, err := s.MessageInstancesByMessage(ctx, msg.ID, true)
+	require.NoError(t, err)
+	assert.Len(t, instances, 2)
+
+	// Ephemeral instances are skipped when iterating
+	instances, err = s.MessageInstancesAfterID(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Equal(t, instance.ID, instances[0].ID)
+
+	instances, err = s.MessageInstancesAfterID(ctx, instance.ID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, instances)
+
+	deleted, err := s.DeleteEphemeralMessageInstancesBefore(ctx, now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	_, err = s.MessageInstance(ctx, msg.ID, ephemeral.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	// Instances are only found together with their message
+	_, err = s.MessageInstance(ctx, "other", instance.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	require.NoError(t, s.DeleteMessageInstanceByDiscordMessageID(ctx, "100"))
+
+	_, err = s.MessageInstanceByDiscordMessageID(ctx, "100")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	other := createMessageInstance(t, s, msg, "102", false, now())
+	require.NoError(t, s.DeleteMessageInstance(ctx, msg.ID, other.ID))
+
+	instances, err = s.MessageInstancesByMessage(ctx, msg.ID, true)
+	require.NoError(t, err)
+	assert.Empty(t, instances)
+}
+
+func testModules(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+
+	wasm := []byte{0x00, 0x61, 0x73, 0x6d}
+	module, err := s.CreateModule(ctx, &model.Module{
+		ID:            util.UniqueID(),
+		Name:          "Module",
+		Description:   "Description",
+		Enabled:       true,
+		AppID:         app.ID,
+		CreatorUserID: user.ID,
+		WasmBytes:     wasm,
+		WasmHash:      util.HashModuleWASMBytes(wasm),
+		CreatedAt:     now(),
+		UpdatedAt:     now(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, len(wasm), module.WasmSize)
+
+	res, err := s.Module(ctx, module.ID)
+	require.NoError(t, err)
+	assert.Equal(t, wasm, res.WasmBytes)
+
+	// Listing modules doesn't load the binaries, but still reports their size
+	modules, err := s.ModulesByApp(ctx, app.ID)
+	require.NoError(t, err)
+	require.Len(t, modules, 1)
+	assert.Empty(t, modules[0].WasmBytes)
+	assert.Equal(t, len(wasm), modules[0].WasmSize)
+	assert.Equal(t, module.WasmHash, modules[0].WasmHash)
+
+	meta, err := s.ModuleMeta(ctx, module.ID)
+	require.NoError(t, err)
+	assert.Empty(t, meta.WasmBytes)
+	assert.Equal(t, len(wasm), meta.WasmSize)
+	assert.Equal(t, module.WasmHash, meta.WasmHash)
+	assert.Equal(t, app.ID, meta.AppID)
+
+	module.Name = "Updated"
+	module.Enabled = false
+	module.UpdatedAt = now().Add(time.Second)
+	updated, err := s.UpdateModule(ctx, module)
+	require.NoError(t, err)
+	assert.Equal(t, "Updated", updated.Name)
+	assert.False(t, updated.Enabled)
+	assert.Equal(t, wasm, updated.WasmBytes)
+
+	newWasm := append(append([]byte{}, wasm...), 0x01, 0x00, 0x00, 0x00)
+	module.WasmBytes = newWasm
+	module.WasmHash = util.HashModuleWASMBytes(newWasm)
+	updated, err = s.UpdateModuleWasm(ctx, module)
+	require.NoError(t, err)
+	assert.Equal(t, newWasm, updated.WasmBytes)
+	assert.Equal(t, len(newWasm), updated.WasmSize)
+
+	count, err := s.CountModulesByApp(ctx, app.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	require.NoError(t, s.DeleteModule(ctx, module.ID))
+
+	_, err = s.Module(ctx, module.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	_, err = s.ModuleMeta(ctx, module.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
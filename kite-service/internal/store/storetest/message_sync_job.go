@@ -0,0 +1,179 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func createMessageSyncJob(t *testing.T, s Store, msg *model.Message, createdAt time.Time) *model.MessageSyncJob {
+	t.Helper()
+
+	job := &model.MessageSyncJob{
+		ID:        util.UniqueID(),
+		AppID:     msg.AppID,
+		MessageID: msg.ID,
+		Status:    model.MessageSyncStatusPending,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+	require.NoError(t, s.CreateMessageSyncJob(context.Background(), job))
+	return job
+}
+
+func testMessageSyncJobs(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	msg := createMessage(t, s, app)
+
+	_, err := s.LatestMessageSyncJob(ctx, msg.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	first := createMessageSyncJob(t, s, msg, now().Add(-time.Minute))
+	second := createMessageSyncJob(t, s, msg, now())
+
+	latest, err := s.LatestMessageSyncJob(ctx, msg.ID)
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, latest.ID)
+	assert.Equal(t, model.MessageSyncStatusPending, latest.Status)
+	assert.Empty(t, latest.Results)
+
+	count, err := s.CountPendingMessageSyncJobsByApp(ctx, app.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	pending, err := s.PendingMessageSyncJobs(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, first.ID, pending[0].ID, "oldest jobs come first")
+
+	claimed, err := s.ClaimMessageSyncJob(ctx, first.ID, now())
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = s.ClaimMessageSyncJob(ctx, first.ID, now())
+	require.NoError(t, err)
+	assert.False(t, claimed, "running jobs can't be claimed again")
+
+	completedAt := now()
+	first.Status = model.MessageSyncStatusFailed
+	first.Error = null.StringFrom("boom")
+	first.UpdatedAt = completedAt
+	first.StartedAt = null.TimeFrom(completedAt)
+	first.CompletedAt = null.TimeFrom(completedAt)
+	require.NoError(t, s.UpdateMessageSyncJob(ctx, first))
+
+	pending, err = s.PendingMessageSyncJobs(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, second.ID, pending[0].ID)
+
+	deleted, err := s.DeleteMessageSyncJobsCompletedBefore(ctx, completedAt.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	err = s.UpdateMessageSyncJob(ctx, first)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func testMessageSyncJobResults(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	msg := createMessage(t, s, app)
+
+	job := createMessageSyncJob(t, s, msg, now().Add(-time.Hour))
+	claimed, err := s.ClaimMessageSyncJob(ctx, job.ID, now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	results := []model.MessageInstanceSyncResult{
+		{InstanceID: 1, DiscordGuildID: "1", DiscordChannelID: "2", Status: model.MessageInstanceSyncStatusFailed, Error: null.StringFrom("timeout")},
+		{InstanceID: 2, DiscordGuildID: "1", DiscordChannelID: "3", Status: model.MessageInstanceSyncStatusFailed, Error: null.StringFrom("missing access")},
+	}
+	require.NoError(t, s.CreateMessageSyncJobResult(ctx, job.ID, results[0], now().Add(-time.Second)))
+	require.NoError(t, s.CreateMessageSyncJobResult(ctx, job.ID, results[1], now().Add(-time.Second)))
+
+	// A job that is picked up again overwrites the result of the instance
+	results[0] = model.MessageInstanceSyncResult{InstanceID: 1, DiscordGuildID: "1", DiscordChannelID: "2", Status: model.MessageInstanceSyncStatusSuccess}
+	storedAt := now()
+	require.NoError(t, s.CreateMessageSyncJobResult(ctx, job.ID, results[0], storedAt))
+
+	stored, err := s.LatestMessageSyncJob(ctx, msg.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, results, stored.Results)
+	assertTimeEqual(t, storedAt, stored.UpdatedAt)
+
+	reset, err := s.ResetStaleMessageSyncJobs(ctx, now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 0, reset, "storing a result keeps the job from being stale")
+
+	job.Status = model.MessageSyncStatusCompleted
+	job.UpdatedAt = now()
+	job.CompletedAt = null.TimeFrom(now())
+	require.NoError(t, s.UpdateMessageSyncJob(ctx, job))
+
+	stored, err = s.LatestMessageSyncJob(ctx, msg.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.MessageSyncStatusCompleted, stored.Status)
+	assert.ElementsMatch(t, results, stored.Results)
+	require.True(t, stored.CompletedAt.Valid)
+	assertTimeEqual(t, job.CompletedAt.Time, stored.CompletedAt.Time)
+
+	err = s.CreateMessageSyncJobResult(ctx, util.UniqueID(), results[0], now())
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func testMessageSyncJobStaleReset(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	msg := createMessage(t, s, app)
+	staleMsg := createMessage(t, s, app)
+
+	stale := createMessageSyncJob(t, s, staleMsg, now().Add(-time.Hour))
+	active := createMessageSyncJob(t, s, msg, now().Add(-time.Hour))
+
+	claimed, err := s.ClaimMessageSyncJob(ctx, stale.ID, now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	err = s.CreateMessageSyncJobResult(ctx, stale.ID, model.MessageInstanceSyncResult{
+		InstanceID:       1,
+		DiscordGuildID:   "1",
+		DiscordChannelID: "2",
+		Status:           model.MessageInstanceSyncStatusSuccess,
+	}, now().Add(-time.Hour))
+	require.NoError(t, err)
+
+	claimed, err = s.ClaimMessageSyncJob(ctx, active.ID, now())
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	reset, err := s.ResetStaleMessageSyncJobs(ctx, now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, reset)
+
+	pending, err := s.PendingMessageSyncJobs(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, stale.ID, pending[0].ID)
+	assert.False(t, pending[0].StartedAt.Valid)
+
+	// The job starts over, so the results of the stopped worker are dropped
+	latest, err := s.LatestMessageSyncJob(ctx, staleMsg.ID)
+	require.NoError(t, err)
+	assert.Equal(t, stale.ID, latest.ID)
+	assert.Empty(t, latest.Results)
+}
@@ -0,0 +1,171 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/internal/util"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func testNamespaces(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	otherApp := createApp(t, s, user.ID)
+
+	namespace, err := s.CreateNamespace(ctx, &model.Namespace{
+		ID:          util.UniqueID(),
+		Name:        "Shared",
+		OwnerUserID: user.ID,
+		CreatedAt:   now(),
+		UpdatedAt:   now(),
+	})
+	require.NoError(t, err)
+
+	namespace.Description = null.StringFrom("Description")
+	namespace.UpdatedAt = now().Add(time.Second)
+	_, err = s.UpdateNamespace(ctx, namespace)
+	require.NoError(t, err)
+
+	res, err := s.Namespace(ctx, namespace.ID)
+	require.NoError(t, err)
+	assert.Equal(t, null.StringFrom("Description"), res.Description)
+
+	namespaces, err := s.NamespacesByOwner(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, namespaces, 1)
+	assert.Equal(t, namespace.ID, namespaces[0].ID)
+
+	// Variables of a namespace don't belong to any app
+	variable, err := s.CreateVariable(ctx, &model.Variable{
+		ID:           util.UniqueID(),
+		Name:         "shared",
+		Scoped:       true,
+		NamespaceID:  null.StringFrom(namespace.ID),
+		Type:         thing.TypeAny,
+		DefaultValue: thing.Null,
+		CreatedAt:    now(),
+		UpdatedAt:    now(),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, variable.AppID)
+
+	variables, err := s.VariablesByNamespace(ctx, namespace.ID)
+	require.NoError(t, err)
+	require.Len(t, variables, 1)
+	assert.Equal(t, variable.ID, variables[0].ID)
+
+	variables, err = s.VariablesByApp(ctx, app.ID)
+	require.NoError(t, err)
+	assert.Empty(t, variables)
+
+	_, err = s.NamespaceGrant(ctx, namespace.ID, app.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	for _, a := range []*model.App{app, otherApp} {
+		_, err = s.UpsertNamespaceGrant(ctx, &model.NamespaceGrant{
+			NamespaceID: namespace.ID,
+			AppID:       a.ID,
+			Access:      model.NamespaceAccessRead,
+			CreatedAt:   now(),
+			UpdatedAt:   now(),
+		})
+		require.NoError(t, err)
+	}
+
+	// Upserting an existing grant changes its access
+	grant, err := s.UpsertNamespaceGrant(ctx, &model.NamespaceGrant{
+		NamespaceID: namespace.ID,
+		AppID:       app.ID,
+		Access:      model.NamespaceAccessReadWrite,
+		CreatedAt:   now(),
+		UpdatedAt:   now(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, model.NamespaceAccessReadWrite, grant.Access)
+
+	grant, err = s.NamespaceGrant(ctx, namespace.ID, app.ID)
+	require.NoError(t, err)
+	assert.True(t, grant.Access.CanWrite())
+
+	grants, err := s.NamespaceGrantsByNamespace(ctx, namespace.ID)
+	require.NoError(t, err)
+	assert.Len(t, grants, 2)
+
+	grants, err = s.NamespaceGrantsByApp(ctx, otherApp.ID)
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+	assert.Equal(t, model.NamespaceAccessRead, grants[0].Access)
+
+	require.NoError(t, s.DeleteNamespaceGrant(ctx, namespace.ID, otherApp.ID))
+
+	_, err = s.NamespaceGrant(ctx, namespace.ID, otherApp.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	// Members of the team see the namespace next to their own namespaces
+	member := createUser(t, s)
+
+	_, err = s.NamespaceMember(ctx, namespace.ID, member.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	_, err = s.CreateNamespaceMember(ctx, &model.NamespaceMember{
+		NamespaceID: namespace.ID,
+		UserID:      member.ID,
+		CreatedAt:   now(),
+		UpdatedAt:   now(),
+	})
+	require.NoError(t, err)
+
+	namespaceMember, err := s.NamespaceMember(ctx, namespace.ID, member.ID)
+	require.NoError(t, err)
+	require.NotNil(t, namespaceMember.User)
+	assert.Equal(t, member.DiscordID, namespaceMember.User.DiscordID)
+
+	members, err := s.NamespaceMembersByNamespace(ctx, namespace.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.Equal(t, member.ID, members[0].UserID)
+
+	count, err := s.CountNamespaceMembersByNamespace(ctx, namespace.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	namespaces, err = s.NamespacesByMember(ctx, member.ID)
+	require.NoError(t, err)
+	require.Len(t, namespaces, 1)
+	assert.Equal(t, namespace.ID, namespaces[0].ID)
+
+	namespaces, err = s.NamespacesByMember(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Len(t, namespaces, 1)
+
+	namespaces, err = s.NamespacesByOwner(ctx, member.ID)
+	require.NoError(t, err)
+	assert.Empty(t, namespaces)
+
+	require.NoError(t, s.DeleteNamespaceMember(ctx, namespace.ID, member.ID))
+
+	namespaces, err = s.NamespacesByMember(ctx, member.ID)
+	require.NoError(t, err)
+	assert.Empty(t, namespaces)
+
+	// Deleting the namespace deletes its variables and grants
+	require.NoError(t, s.DeleteNamespace(ctx, namespace.ID))
+
+	_, err = s.Namespace(ctx, namespace.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+	_, err = s.Variable(ctx, variable.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	grants, err = s.NamespaceGrantsByApp(ctx, app.ID)
+	require.NoError(t, err)
+	assert.Empty(t, grants)
+}
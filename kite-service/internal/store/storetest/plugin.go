@@ -0,0 +1,295 @@
+package storetest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func setPluginValue(t *testing.T, s Store, pluginInstanceID string, key string, value thing.Thing, expiresAt null.Time) {
+	t.Helper()
+
+	err := s.SetPluginValue(context.Background(), model.PluginValue{
+		PluginInstanceID: pluginInstanceID,
+		Key:              key,
+		Value:            value,
+		CreatedAt:        now(),
+		UpdatedAt:        now(),
+		ExpiresAt:        expiresAt,
+	})
+	require.NoError(t, err)
+}
+
+func testPluginInstances(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+
+	instance := createPluginInstance(t, s, app, "counting")
+	other := createPluginInstance(t, s, app, "welcome")
+
+	res, err := s.PluginInstance(ctx, app.ID, "counting")
+	require.NoError(t, err)
+	assert.Equal(t, instance.ID, res.ID)
+	assert.Empty(t, res.EnabledResourceIDs)
+
+	instance.Enabled = false
+	instance.Config = map[string]json.RawMessage{"channel": json.RawMessage(`"123"`)}
+	instance.EnabledResourceIDs = []string{"count"}
+	instance.UpdatedAt = now().Add(time.Second)
+	_, err = s.UpdatePluginInstance(ctx, instance)
+	require.NoError(t, err)
+
+	res, err = s.PluginInstance(ctx, app.ID, "counting")
+	require.NoError(t, err)
+	assert.False(t, res.Enabled)
+	assert.JSONEq(t, `"123"`, string(res.Config["channel"]))
+	assert.Equal(t, []string{"count"}, res.EnabledResourceIDs)
+
+	instances, err := s.PluginInstancesByApp(ctx, app.ID)
+	require.NoError(t, err)
+	assert.Len(t, instances, 2)
+
+	ids, err := s.EnabledPluginInstanceIDs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{other.ID}, ids)
+
+	instances, err = s.EnabledPluginInstancesUpdatedSince(ctx, other.UpdatedAt.Add(-time.Second))
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Equal(t, other.ID, instances[0].ID)
+
+	appIDs, err := s.DinstinctAppIDsWithUndeployedPluginInstances(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{app.ID}, appIDs)
+
+	deployedAt := now().Add(2 * time.Second)
+	require.NoError(t, s.UpdatePluginInstancesLastDeployedAt(ctx, app.ID, deployedAt))
+
+	appIDs, err = s.DinstinctAppIDsWithUndeployedPluginInstances(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, appIDs)
+
+	res, err = s.PluginInstance(ctx, app.ID, "welcome")
+	require.NoError(t, err)
+	require.True(t, res.LastDeployedAt.Valid)
+	assertTimeEqual(t, deployedAt, res.LastDeployedAt.Time)
+
+	require.NoError(t, s.DeletePluginInstance(ctx, app.ID, "counting"))
+
+	_, err = s.PluginInstance(ctx, app.ID, "counting")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func testPluginValues(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	instance := createPluginInstance(t, s, app, "counting")
+
+	setPluginValue(t, s, instance.ID, "a", thing.NewInt(1), null.Time{})
+	setPluginValue(t, s, instance.ID, "a", thing.NewInt(2), null.Time{})
+
+	value, err := s.GetPluginValue(ctx, instance.ID, "a")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), value.Value.Int())
+
+	err = s.SetPluginValues(ctx, []model.PluginValue{
+		{PluginInstanceID: instance.ID, Key: "b", Value: thing.NewString("b"), CreatedAt: now(), UpdatedAt: now()},
+		{PluginInstanceID: instance.ID, Key: "c", Value: thing.NewString("c"), CreatedAt: now(), UpdatedAt: now()},
+	})
+	require.NoError(t, err)
+
+	// Keys without a value are omitted
+	values, err := s.PluginValuesByKeys(ctx, instance.ID, []string{"a", "c", "missing"})
+	require.NoError(t, err)
+	keys := make([]string, len(values))
+	for i, v := range values {
+		keys[i] = v.Key
+	}
+	assert.ElementsMatch(t, []string{"a", "c"}, keys)
+
+	require.NoError(t, s.DeletePluginValue(ctx, instance.ID, "a"))
+
+	_, err = s.GetPluginValue(ctx, instance.ID, "a")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	// Compare and swap with thing.Null only succeeds if there is no value
+	swapped, err := s.CompareAndSwapPluginValue(ctx, thing.Null, model.PluginValue{
+		PluginInstanceID: instance.ID,
+		Key:              "a",
+		Value:            thing.NewInt(1),
+		CreatedAt:        now(),
+		UpdatedAt:        now(),
+	})
+	require.NoError(t, err)
+	assert.True(t, swapped)
+
+	swapped, err = s.CompareAndSwapPluginValue(ctx, thing.Null, model.PluginValue{
+		PluginInstanceID: instance.ID,
+		Key:              "a",
+		Value:            thing.NewInt(2),
+		CreatedAt:        now(),
+		UpdatedAt:        now(),
+	})
+	require.NoError(t, err)
+	assert.False(t, swapped)
+
+	swapped, err = s.CompareAndSwapPluginValue(ctx, thing.NewInt(3), model.PluginValue{
+		PluginInstanceID: instance.ID,
+		Key:              "a",
+		Value:            thing.NewInt(4),
+		CreatedAt:        now(),
+		UpdatedAt:        now(),
+	})
+	require.NoError(t, err)
+	assert.False(t, swapped)
+
+	swapped, err = s.CompareAndSwapPluginValue(ctx, thing.NewInt(1), model.PluginValue{
+		PluginInstanceID: instance.ID,
+		Key:              "a",
+		Value:            thing.NewInt(4),
+		CreatedAt:        now(),
+		UpdatedAt:        now(),
+	})
+	require.NoError(t, err)
+	assert.True(t, swapped)
+
+	value, err = s.GetPluginValue(ctx, instance.ID, "a")
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), value.Value.Int())
+
+	// Expired values are treated as if they didn't exist
+	setPluginValue(t, s, instance.ID, "expired", thing.NewInt(1), null.TimeFrom(now().Add(-time.Minute)))
+
+	_, err = s.GetPluginValue(ctx, instance.ID, "expired")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	values, err = s.PluginValuesByKeys(ctx, instance.ID, []string{"expired"})
+	require.NoError(t, err)
+	assert.Empty(t, values)
+
+	swapped, err = s.CompareAndSwapPluginValue(ctx, thing.Null, model.PluginValue{
+		PluginInstanceID: instance.ID,
+		Key:              "expired",
+		Value:            thing.NewInt(2),
+		CreatedAt:        now(),
+		UpdatedAt:        now(),
+	})
+	require.NoError(t, err)
+	assert.True(t, swapped)
+
+	setPluginValue(t, s, instance.ID, "old", thing.NewInt(1), null.TimeFrom(now().Add(-time.Minute)))
+
+	deleted, err := s.DeleteExpiredPluginValues(ctx, now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	value, err = s.GetPluginValue(ctx, instance.ID, "expired")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), value.Value.Int())
+}
+
+func testPluginValueOperations(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	instance := createPluginInstance(t, s, app, "counting")
+
+	update := func(operation model.PluginValueOperation, value thing.Thing, expiresAt null.Time) *model.PluginValue {
+		t.Helper()
+
+		res, err := s.UpdatePluginValue(ctx, operation, model.PluginValue{
+			PluginInstanceID: instance.ID,
+			Key:              "count",
+			Value:            value,
+			CreatedAt:        now(),
+			UpdatedAt:        now(),
+			ExpiresAt:        expiresAt,
+		})
+		require.NoError(t, err)
+		return res
+	}
+
+	expiresAt := null.TimeFrom(now().Add(time.Hour))
+
+	res := update(provider.VariableOperationIncrement, thing.NewInt(2), expiresAt)
+	assert.Equal(t, int64(2), res.Value.Int())
+	assert.True(t, res.ExpiresAt.Valid)
+
+	// Operations other than overwrite keep the expiry of the current value
+	res = update(provider.VariableOperationIncrement, thing.NewInt(3), null.Time{})
+	assert.Equal(t, int64(5), res.Value.Int())
+	require.True(t, res.ExpiresAt.Valid)
+	assertTimeEqual(t, expiresAt.Time, res.ExpiresAt.Time)
+
+	res = update(provider.VariableOperationDecrement, thing.NewInt(1), null.Time{})
+	assert.Equal(t, int64(4), res.Value.Int())
+
+	res = update(provider.VariableOperationMultiply, thing.NewInt(3), null.Time{})
+	assert.Equal(t, int64(12), res.Value.Int())
+	assert.True(t, res.ExpiresAt.Valid)
+
+	res = update(provider.VariableOperationOverwrite, thing.NewString("a"), null.Time{})
+	assert.Equal(t, "a", res.Value.String())
+	assert.False(t, res.ExpiresAt.Valid)
+
+	res = update(provider.VariableOperationAppend, thing.NewString("b"), null.Time{})
+	assert.Equal(t, "ab", res.Value.String())
+
+	value, err := s.GetPluginValue(ctx, instance.ID, "count")
+	require.NoError(t, err)
+	assert.Equal(t, "ab", value.Value.String())
+}
+
+func testPluginValuesByKeyPrefix(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	instance := createPluginInstance(t, s, app, "leaderboard")
+
+	setPluginValue(t, s, instance.ID, "score:a", thing.NewInt(10), null.Time{})
+	setPluginValue(t, s, instance.ID, "score:b", thing.NewFloat(30.5), null.Time{})
+	setPluginValue(t, s, instance.ID, "score:c", thing.NewString("text"), null.Time{})
+	setPluginValue(t, s, instance.ID, "score:d", thing.NewInt(20), null.Time{})
+	setPluginValue(t, s, instance.ID, "score:e", thing.NewInt(50), null.TimeFrom(now().Add(-time.Minute)))
+	setPluginValue(t, s, instance.ID, "other", thing.NewInt(100), null.Time{})
+
+	keys := func(limit int, offset int) []string {
+		t.Helper()
+
+		values, err := s.PluginValuesByKeyPrefix(ctx, instance.ID, "score:", limit, offset)
+		require.NoError(t, err)
+
+		res := make([]string, len(values))
+		for i, v := range values {
+			res[i] = v.Key
+		}
+		return res
+	}
+
+	// Numeric values come first from highest to lowest, expired values are skipped
+	assert.Equal(t, []string{"score:b", "score:d", "score:a", "score:c"}, keys(10, 0))
+	assert.Equal(t, []string{"score:d", "score:a"}, keys(2, 1))
+
+	count, err := s.CountPluginValuesByKeyPrefixAbove(ctx, instance.ID, "score:", 15)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	count, err = s.CountPluginValuesByKeyPrefixAbove(ctx, instance.ID, "score:", 100)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
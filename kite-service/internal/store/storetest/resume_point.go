@@ -0,0 +1,110 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/internal/util"
+	"github.com/kitecloud/kite/kite-service/pkg/flow"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func createResumePoint(t *testing.T, s Store, app *model.App, resumePointType model.ResumePointType, createdAt time.Time, expiresAt null.Time) *model.ResumePoint {
+	t.Helper()
+
+	resumePoint := &model.ResumePoint{
+		ID:         util.UniqueID(),
+		Type:       resumePointType,
+		AppID:      app.ID,
+		FlowNodeID: "node",
+		FlowState:  *flow.NewFlowContextState(),
+		CreatedAt:  createdAt,
+		ExpiresAt:  expiresAt,
+	}
+	require.NoError(t, s.CreateResumePoint(context.Background(), resumePoint))
+	return resumePoint
+}
+
+func testResumePoints(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	command := createCommand(t, s, app, "ping")
+	msg := createMessage(t, s, app)
+	instance := createMessageInstance(t, s, msg, "100", false, now())
+
+	state := flow.NewFlowContextState()
+	state.Temporaries["answer"] = thing.NewInt(42)
+
+	resumePoint := &model.ResumePoint{
+		ID:                util.UniqueID(),
+		Type:              model.ResumePointTypeMessageComponents,
+		AppID:             app.ID,
+		CommandID:         null.StringFrom(command.ID),
+		MessageID:         null.StringFrom(msg.ID),
+		MessageInstanceID: null.IntFrom(int64(instance.ID)),
+		FlowSourceID:      null.StringFrom("button"),
+		FlowNodeID:        "node",
+		FlowState:         *state,
+		CreatedAt:         now(),
+		ExpiresAt:         null.TimeFrom(now().Add(time.Hour)),
+	}
+	require.NoError(t, s.CreateResumePoint(ctx, resumePoint))
+
+	res, err := s.ResumePoint(ctx, resumePoint.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.ResumePointTypeMessageComponents, res.Type)
+	assert.Equal(t, null.StringFrom(command.ID), res.CommandID)
+	assert.False(t, res.EventListenerID.Valid)
+	assert.Equal(t, null.IntFrom(int64(instance.ID)), res.MessageInstanceID)
+	assert.Equal(t, null.StringFrom("button"), res.FlowSourceID)
+	assert.Equal(t, "node", res.FlowNodeID)
+	assert.Equal(t, int64(42), res.FlowState.Temporaries["answer"].Int())
+	require.True(t, res.ExpiresAt.Valid)
+	assertTimeEqual(t, resumePoint.ExpiresAt.Time, res.ExpiresAt.Time)
+
+	require.NoError(t, s.DeleteResumePoint(ctx, resumePoint.ID))
+
+	_, err = s.ResumePoint(ctx, resumePoint.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func testResumePointExpiry(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+
+	expired := createResumePoint(t, s, app, model.ResumePointTypeModal, now(), null.TimeFrom(now().Add(-time.Minute)))
+	active := createResumePoint(t, s, app, model.ResumePointTypeModal, now(), null.TimeFrom(now().Add(time.Hour)))
+	forever := createResumePoint(t, s, app, model.ResumePointTypeMessageComponents, now().Add(-time.Hour), null.Time{})
+	oldModal := createResumePoint(t, s, app, model.ResumePointTypeModal, now().Add(-time.Hour), null.Time{})
+
+	// Resume points without an expiry never expire
+	deleted, err := s.DeleteExpiredResumePoints(ctx, now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	_, err = s.ResumePoint(ctx, expired.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	// Only resume points of the given type are deleted
+	deleted, err = s.DeleteResumePointsCreatedBefore(ctx, model.ResumePointTypeModal, now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	_, err = s.ResumePoint(ctx, oldModal.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	for _, resumePoint := range []*model.ResumePoint{active, forever} {
+		_, err = s.ResumePoint(ctx, resumePoint.ID)
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,216 @@
+// Package storetest is a conformance test suite for the database backends.
+// Every backend runs the same tests against an empty database, so a new backend or a changed query
+// is verified to behave exactly like the existing ones.
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/internal/util"
+	"github.com/kitecloud/kite/kite-service/pkg/flow"
+	"github.com/kitecloud/kite/kite-service/pkg/plugin"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+// Store is implemented by every database backend.
+type Store interface {
+	store.UserStore
+	store.SessionStore
+	store.AppStore
+	store.LogStore
+	store.UsageStore
+	store.CommandStore
+	store.VariableStore
+	store.VariableValueStore
+	store.MessageStore
+	store.MessageInstanceStore
+	store.MessageSyncJobStore
+	store.EventListenerStore
+	store.PluginInstanceStore
+	store.PluginValueStore
+	store.SubscriptionStore
+	store.EntitlementStore
+	store.ModuleStore
+	store.NamespaceStore
+	store.ResumePointStore
+	store.CooldownStore
+	store.AssetStore
+}
+
+// Factory returns an empty store, it's called once for every test.
+// Cleaning up the store should be registered with t.Cleanup.
+type Factory func(t *testing.T) Store
+
+type storeTest struct {
+	name string
+	run  func(t *testing.T, s Store)
+}
+
+var tests = []storeTest{
+	{"Users", testUsers},
+	{"Sessions", testSessions},
+	{"Apps", testApps},
+	{"AppsByUser", testAppsByUser},
+	{"Collaborators", testCollaborators},
+	{"AppEntities", testAppEntities},
+	{"Commands", testCommands},
+	{"CommandDeployment", testCommandDeployment},
+	{"EventListeners", testEventListeners},
+	{"Messages", testMessages},
+	{"MessageInstances", testMessageInstances},
+	{"MessageSyncJobs", testMessageSyncJobs},
+	{"MessageSyncJobResults", testMessageSyncJobResults},
+	{"MessageSyncJobStaleReset", testMessageSyncJobStaleReset},
+	{"Modules", testModules},
+	{"AssetsWithoutExpiry", testAssetsWithoutExpiry},
+	{"ReferencedAssetIDs", testReferencedAssetIDs},
+	{"Variables", testVariables},
+	{"VariableValues", testVariableValues},
+	{"VariableValueOperations", testVariableValueOperations},
+	{"VariableValueConcurrentUpdates", testVariableValueConcurrentUpdates},
+	{"VariableValueExpiry", testVariableValueExpiry},
+	{"VariableValueQuery", testVariableValueQuery},
+	{"VariableValueAggregate", testVariableValueAggregate},
+	{"Namespaces", testNamespaces},
+	{"PluginInstances", testPluginInstances},
+	{"PluginValues", testPluginValues},
+	{"PluginValueOperations", testPluginValueOperations},
+	{"PluginValuesByKeyPrefix", testPluginValuesByKeyPrefix},
+	{"Logs", testLogs},
+	{"LogPagination", testLogPagination},
+	{"Usage", testUsage},
+	{"UsageByDay", testUsageByDay},
+	{"ResumePoints", testResumePoints},
+	{"ResumePointExpiry", testResumePointExpiry},
+	{"Cooldowns", testCooldowns},
+	{"Subscriptions", testSubscriptions},
+	{"Entitlements", testEntitlements},
+}
+
+// Run runs all conformance tests against stores created by newStore.
+func Run(t *testing.T, newStore Factory) {
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.run(t, newStore(t))
+		})
+	}
+}
+
+// now is truncated to the precision that all backends can store.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+func assertTimeEqual(t *testing.T, expected time.Time, actual time.Time) {
+	t.Helper()
+	assert.True(t, expected.Equal(actual), "expected %s, got %s", expected, actual)
+}
+
+func createUser(t *testing.T, s Store) *model.User {
+	t.Helper()
+
+	id := util.UniqueID()
+	user, err := s.UpsertUser(context.Background(), &model.User{
+		ID:              id,
+		Email:           id + "@example.com",
+		DisplayName:     "User " + id,
+		DiscordID:       id,
+		DiscordUsername: "user_" + id,
+		CreatedAt:       now(),
+		UpdatedAt:       now(),
+	})
+	require.NoError(t, err)
+	return user
+}
+
+func createApp(t *testing.T, s Store, ownerUserID string) *model.App {
+	t.Helper()
+
+	id := util.UniqueID()
+	app, err := s.CreateApp(context.Background(), &model.App{
+		ID:            id,
+		Name:          "App " + id,
+		OwnerUserID:   ownerUserID,
+		CreatorUserID: ownerUserID,
+		DiscordToken:  "token_" + id,
+		DiscordID:     id,
+		CreatedAt:     now(),
+		UpdatedAt:     now(),
+	})
+	require.NoError(t, err)
+	return app
+}
+
+func createCommand(t *testing.T, s Store, app *model.App, name string) *model.Command {
+	t.Helper()
+
+	command, err := s.CreateCommand(context.Background(), &model.Command{
+		ID:            util.UniqueID(),
+		Name:          name,
+		Description:   "Command " + name,
+		Enabled:       true,
+		AppID:         app.ID,
+		CreatorUserID: app.OwnerUserID,
+		FlowSource:    flow.FlowData{Nodes: []flow.FlowNode{}, Edges: []flow.FlowEdge{}},
+		CreatedAt:     now(),
+		UpdatedAt:     now(),
+	})
+	require.NoError(t, err)
+	return command
+}
+
+func createVariable(t *testing.T, s Store, app *model.App, scoped bool) *model.Variable {
+	t.Helper()
+
+	id := util.UniqueID()
+	variable, err := s.CreateVariable(context.Background(), &model.Variable{
+		ID:           id,
+		Name:         "variable_" + id,
+		Scoped:       scoped,
+		AppID:        app.ID,
+		Type:         thing.TypeAny,
+		DefaultValue: thing.Null,
+		CreatedAt:    now(),
+		UpdatedAt:    now(),
+	})
+	require.NoError(t, err)
+	return variable
+}
+
+func setVariableValue(t *testing.T, s Store, variableID string, scope null.String, data thing.Thing) {
+	t.Helper()
+
+	err := s.SetVariableValue(context.Background(), model.VariableValue{
+		VariableID: variableID,
+		Scope:      scope,
+		Data:       data,
+		CreatedAt:  now(),
+		UpdatedAt:  now(),
+	})
+	require.NoError(t, err)
+}
+
+func createPluginInstance(t *testing.T, s Store, app *model.App, pluginID string) *model.PluginInstance {
+	t.Helper()
+
+	instance, err := s.CreatePluginInstance(context.Background(), &model.PluginInstance{
+		ID:                 util.UniqueID(),
+		PluginID:           pluginID,
+		Enabled:            true,
+		AppID:              app.ID,
+		CreatorUserID:      app.OwnerUserID,
+		Config:             plugin.ConfigValues{},
+		EnabledResourceIDs: []string{},
+		CreatedAt:          now(),
+		UpdatedAt:          now(),
+	})
+	require.NoError(t, err)
+	return instance
+}
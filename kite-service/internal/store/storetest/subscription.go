@@ -0,0 +1,152 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func upsertSubscription(t *testing.T, s Store, user *model.User, lemonSqueezyID string, status string) *model.Subscription {
+	t.Helper()
+
+	sub, err := s.UpsertLemonSqueezySubscription(context.Background(), model.Subscription{
+		ID:                         util.UniqueID(),
+		DisplayName:                "Premium",
+		Source:                     model.SubscriptionSourceLemonSqueezy,
+		Status:                     status,
+		StatusFormatted:            status,
+		CreatedAt:                  now(),
+		UpdatedAt:                  now(),
+		RenewsAt:                   now().AddDate(0, 1, 0),
+		UserID:                     user.ID,
+		LemonsqueezySubscriptionID: null.StringFrom(lemonSqueezyID),
+		LemonsqueezyCustomerID:     null.StringFrom("customer"),
+		LemonsqueezyProductID:      null.StringFrom("product"),
+		LemonsqueezyVariantID:      null.StringFrom("variant"),
+	})
+	require.NoError(t, err)
+	return sub
+}
+
+func testSubscriptions(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+
+	sub := upsertSubscription(t, s, user, "ls_1", "active")
+	assert.Equal(t, model.SubscriptionSourceLemonSqueezy, sub.Source)
+
+	// Upserting the same Lemon Squeezy subscription updates it and keeps its ID
+	updated := upsertSubscription(t, s, user, "ls_1", "cancelled")
+	assert.Equal(t, sub.ID, updated.ID)
+	assert.Equal(t, "cancelled", updated.Status)
+
+	res, err := s.Subscription(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "cancelled", res.Status)
+	assert.Equal(t, null.StringFrom("ls_1"), res.LemonsqueezySubscriptionID)
+
+	other := upsertSubscription(t, s, user, "ls_2", "active")
+
+	subs, err := s.Subscriptions(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Len(t, subs, 2)
+
+	subs, err = s.AllSubscriptions(ctx)
+	require.NoError(t, err)
+	assert.Len(t, subs, 2)
+
+	_, err = s.UpsertSubscriptionEntitlement(ctx, model.Entitlement{
+		ID:             util.UniqueID(),
+		Type:           "subscription",
+		SubscriptionID: null.StringFrom(other.ID),
+		AppID:          app.ID,
+		PlanID:         "premium",
+		CreatedAt:      now(),
+		UpdatedAt:      now(),
+	})
+	require.NoError(t, err)
+
+	subs, err = s.SubscriptionsByAppID(ctx, app.ID)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, other.ID, subs[0].ID)
+}
+
+func testEntitlements(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	sub := upsertSubscription(t, s, user, "ls_1", "active")
+	other := upsertSubscription(t, s, user, "ls_2", "active")
+
+	entitlement, err := s.UpsertSubscriptionEntitlement(ctx, model.Entitlement{
+		ID:             util.UniqueID(),
+		Type:           "subscription",
+		SubscriptionID: null.StringFrom(sub.ID),
+		AppID:          app.ID,
+		PlanID:         "basic",
+		CreatedAt:      now(),
+		UpdatedAt:      now(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "basic", entitlement.PlanID)
+
+	// Upserting the entitlement of the same subscription and app updates it
+	updated, err := s.UpsertSubscriptionEntitlement(ctx, model.Entitlement{
+		ID:             util.UniqueID(),
+		Type:           "subscription",
+		SubscriptionID: null.StringFrom(sub.ID),
+		AppID:          app.ID,
+		PlanID:         "premium",
+		CreatedAt:      now(),
+		UpdatedAt:      now(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entitlement.ID, updated.ID)
+	assert.Equal(t, "premium", updated.PlanID)
+
+	_, err = s.UpsertSubscriptionEntitlement(ctx, model.Entitlement{
+		ID:             util.UniqueID(),
+		Type:           "subscription",
+		SubscriptionID: null.StringFrom(other.ID),
+		AppID:          app.ID,
+		PlanID:         "basic",
+		CreatedAt:      now(),
+		UpdatedAt:      now(),
+	})
+	require.NoError(t, err)
+
+	entitlements, err := s.Entitlements(ctx, app.ID)
+	require.NoError(t, err)
+	assert.Len(t, entitlements, 2)
+
+	endsAt := now().Add(-time.Minute)
+	updated, err = s.UpdateSubscriptionEntitlement(ctx, model.Entitlement{
+		SubscriptionID: null.StringFrom(other.ID),
+		PlanID:         "basic",
+		UpdatedAt:      now(),
+		EndsAt:         null.TimeFrom(endsAt),
+	})
+	require.NoError(t, err)
+	require.True(t, updated.EndsAt.Valid)
+	assertTimeEqual(t, endsAt, updated.EndsAt.Time)
+
+	// Entitlements that have ended aren't active anymore
+	entitlements, err = s.ActiveEntitlements(ctx, app.ID, now())
+	require.NoError(t, err)
+	require.Len(t, entitlements, 1)
+	assert.Equal(t, entitlement.ID, entitlements[0].ID)
+
+	entitlements, err = s.ActiveEntitlements(ctx, app.ID, endsAt.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, entitlements, 2)
+}
@@ -0,0 +1,100 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+// createUsageRecords creates usage records for two apps over three days starting at the returned day.
+func createUsageRecords(t *testing.T, s Store, app *model.App, otherApp *model.App, command *model.Command) time.Time {
+	t.Helper()
+
+	day := now().AddDate(0, 0, -10).Truncate(24 * time.Hour)
+
+	records := []model.UsageRecord{
+		{Type: model.UsageRecordTypeCommandFlowExecution, AppID: app.ID, CommandID: null.StringFrom(command.ID), CreditsUsed: 5, CreatedAt: day.Add(time.Hour)},
+		{Type: model.UsageRecordTypeEventListenerFlowExecution, AppID: app.ID, CreditsUsed: 3, CreatedAt: day.Add(2 * time.Hour)},
+		{Type: model.UsageRecordTypeMessageFlowExecution, AppID: app.ID, CreditsUsed: 2, CreatedAt: day.AddDate(0, 0, 2).Add(time.Hour)},
+		{Type: model.UsageRecordTypeCommandFlowExecution, AppID: otherApp.ID, CreditsUsed: 7, CreatedAt: day.Add(time.Hour)},
+		{Type: model.UsageRecordTypeCommandFlowExecution, AppID: app.ID, CreditsUsed: 100, CreatedAt: day.AddDate(0, 0, -1)},
+	}
+	for _, record := range records {
+		require.NoError(t, s.CreateUsageRecord(context.Background(), record))
+	}
+
+	return day
+}
+
+func testUsage(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	otherApp := createApp(t, s, user.ID)
+	command := createCommand(t, s, app, "ping")
+
+	day := createUsageRecords(t, s, app, otherApp, command)
+	end := day.AddDate(0, 0, 3)
+
+	records, err := s.UsageRecordsBetween(ctx, app.ID, day, end)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, model.UsageRecordTypeMessageFlowExecution, records[0].Type)
+	assert.Equal(t, null.StringFrom(command.ID), records[2].CommandID)
+	assert.Equal(t, 5, records[2].CreditsUsed)
+	assertTimeEqual(t, day.Add(time.Hour), records[2].CreatedAt)
+
+	credits, err := s.UsageCreditsUsedBetween(ctx, app.ID, day, end)
+	require.NoError(t, err)
+	assert.Equal(t, 10, credits)
+
+	credits, err = s.UsageCreditsUsedBetween(ctx, app.ID, end, end.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Equal(t, 0, credits)
+
+	byType, err := s.UsageCreditsUsedByTypeBetween(ctx, app.ID, day, end)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []model.UsageCreditsUsedByType{
+		{Type: model.UsageRecordTypeCommandFlowExecution, CreditsUsed: 5},
+		{Type: model.UsageRecordTypeEventListenerFlowExecution, CreditsUsed: 3},
+		{Type: model.UsageRecordTypeMessageFlowExecution, CreditsUsed: 2},
+	}, byType)
+
+	all, err := s.AllUsageCreditsUsedBetween(ctx, day, end)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{app.ID: 10, otherApp.ID: 7}, all)
+
+	require.NoError(t, s.DeleteUsageRecordsBefore(ctx, day))
+
+	records, err = s.UsageRecordsBetween(ctx, app.ID, day.AddDate(0, 0, -2), end)
+	require.NoError(t, err)
+	assert.Len(t, records, 3)
+}
+
+func testUsageByDay(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	otherApp := createApp(t, s, user.ID)
+	command := createCommand(t, s, app, "ping")
+
+	day := createUsageRecords(t, s, app, otherApp, command)
+
+	// Every day in the range is included, days without usage have 0 credits
+	byDay, err := s.UsageCreditsUsedByDayBetween(ctx, app.ID, day, day.AddDate(0, 0, 3))
+	require.NoError(t, err)
+	require.Len(t, byDay, 4)
+
+	expected := []int{8, 0, 2, 0}
+	for i, usage := range byDay {
+		assertTimeEqual(t, day.AddDate(0, 0, i), usage.Date)
+		assert.Equal(t, expected[i], usage.CreditsUsed, "day %d", i)
+	}
+}
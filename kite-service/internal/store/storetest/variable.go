@@ -0,0 +1,485 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/internal/model"
+	"github.com/kitecloud/kite/kite-service/internal/store"
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func testVariables(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+
+	variable := createVariable(t, s, app, true)
+	assert.Equal(t, thing.TypeAny, variable.Type)
+	assert.Nil(t, variable.Schema)
+	assert.True(t, variable.DefaultValue.IsNil())
+	assert.Zero(t, variable.ValueTTL)
+
+	setVariableValue(t, s, variable.ID, null.StringFrom("a"), thing.NewInt(1))
+	setVariableValue(t, s, variable.ID, null.StringFrom("b"), thing.NewInt(2))
+
+	res, err := s.Variable(ctx, variable.ID)
+	require.NoError(t, err)
+	assert.Equal(t, null.IntFrom(2), res.TotalValues)
+
+	res, err = s.VariableByName(ctx, app.ID, variable.Name)
+	require.NoError(t, err)
+	assert.Equal(t, variable.ID, res.ID)
+
+	variable.Name = "counter"
+	variable.Type = thing.TypeInt
+	variable.Schema = &thing.Schema{Type: "integer"}
+	variable.DefaultValue = thing.NewInt(10)
+	variable.ValueTTL = time.Hour
+	variable.UpdatedAt = now().Add(time.Second)
+	_, err = s.UpdateVariable(ctx, variable)
+	require.NoError(t, err)
+
+	res, err = s.VariableByName(ctx, app.ID, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, thing.TypeInt, res.Type)
+	require.NotNil(t, res.Schema)
+	assert.Equal(t, "integer", res.Schema.Type)
+	assert.Equal(t, int64(10), res.DefaultValue.Int())
+	assert.Equal(t, time.Hour, res.ValueTTL)
+
+	// The definition has the same fields, but doesn't count the values
+	res, err = s.VariableDefinition(ctx, variable.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "counter", res.Name)
+	assert.Equal(t, thing.TypeInt, res.Type)
+	assert.Equal(t, int64(10), res.DefaultValue.Int())
+	assert.Equal(t, time.Hour, res.ValueTTL)
+	assert.False(t, res.TotalValues.Valid)
+
+	createVariable(t, s, app, false)
+
+	variables, err := s.VariablesByApp(ctx, app.ID)
+	require.NoError(t, err)
+	assert.Len(t, variables, 2)
+
+	count, err := s.CountVariablesByApp(ctx, app.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Deleting the variable deletes all of its values
+	require.NoError(t, s.DeleteVariable(ctx, variable.ID))
+
+	_, err = s.Variable(ctx, variable.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+	_, err = s.VariableDefinition(ctx, variable.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+	_, err = s.VariableByName(ctx, app.ID, "counter")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	values, err := s.VariableValues(ctx, variable.ID)
+	require.NoError(t, err)
+	assert.Empty(t, values)
+}
+
+func testVariableValues(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+
+	// Values without a scope must be unique just like scoped values
+	unscoped := createVariable(t, s, app, false)
+	setVariableValue(t, s, unscoped.ID, null.String{}, thing.NewString("first"))
+	setVariableValue(t, s, unscoped.ID, null.String{}, thing.NewString("second"))
+
+	value, err := s.VariableValue(ctx, unscoped.ID, null.String{})
+	require.NoError(t, err)
+	assert.Equal(t, "second", value.Data.String())
+	assert.False(t, value.Scope.Valid)
+
+	values, err := s.VariableValues(ctx, unscoped.ID)
+	require.NoError(t, err)
+	assert.Len(t, values, 1)
+
+	require.NoError(t, s.DeleteVariableValue(ctx, unscoped.ID, null.String{}))
+
+	_, err = s.VariableValue(ctx, unscoped.ID, null.String{})
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	scoped := createVariable(t, s, app, true)
+	setVariableValue(t, s, scoped.ID, null.StringFrom("a"), thing.NewInt(1))
+	setVariableValue(t, s, scoped.ID, null.StringFrom("b"), thing.NewInt(2))
+
+	value, err = s.VariableValue(ctx, scoped.ID, null.StringFrom("b"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), value.Data.Int())
+	assert.Equal(t, null.StringFrom("b"), value.Scope)
+
+	// The null scope is different from every other scope
+	_, err = s.VariableValue(ctx, scoped.ID, null.String{})
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	require.NoError(t, s.DeleteVariableValue(ctx, scoped.ID, null.StringFrom("a")))
+
+	values, err = s.VariableValues(ctx, scoped.ID)
+	require.NoError(t, err)
+	require.Len(t, values, 1)
+	assert.Equal(t, null.StringFrom("b"), values[0].Scope)
+
+	require.NoError(t, s.DeleteAllVariableValues(ctx, scoped.ID))
+
+	values, err = s.VariableValues(ctx, scoped.ID)
+	require.NoError(t, err)
+	assert.Empty(t, values)
+}
+
+func testVariableValueOperations(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+
+	intArray := func(values ...int) thing.Thing {
+		items := make([]thing.Thing, len(values))
+		for i, v := range values {
+			items[i] = thing.NewInt(v)
+		}
+		return thing.NewArray(items)
+	}
+
+	tests := []struct {
+		name      string
+		operation model.VariableValueOperation
+		args      model.VariableValueOperationArgs
+		current   *thing.Thing
+		value     thing.Thing
+		// expected is the returned value and nil if the value mustn't exist afterwards
+		expected *thing.Thing
+	}{
+		{name: "overwrite", operation: provider.VariableOperationOverwrite, current: ptr(thing.NewInt(5)), value: thing.NewInt(2), expected: ptr(thing.NewInt(2))},
+		{name: "increment", operation: provider.VariableOperationIncrement, current: ptr(thing.NewInt(5)), value: thing.NewInt(2), expected: ptr(thing.NewInt(7))},
+		{name: "increment missing", operation: provider.VariableOperationIncrement, value: thing.NewInt(2), expected: ptr(thing.NewInt(2))},
+		{name: "increment default", operation: provider.VariableOperationIncrement, args: model.VariableValueOperationArgs{Default: thing.NewInt(10)}, value: thing.NewInt(2), expected: ptr(thing.NewInt(12))},
+		{name: "decrement", operation: provider.VariableOperationDecrement, current: ptr(thing.NewInt(5)), value: thing.NewInt(2), expected: ptr(thing.NewInt(3))},
+		{name: "multiply", operation: provider.VariableOperationMultiply, current: ptr(thing.NewInt(5)), value: thing.NewInt(3), expected: ptr(thing.NewInt(15))},
+		{name: "append", operation: provider.VariableOperationAppend, current: ptr(thing.NewString("a")), value: thing.NewString("b"), expected: ptr(thing.NewString("ab"))},
+		{name: "prepend", operation: provider.VariableOperationPrepend, current: ptr(thing.NewString("a")), value: thing.NewString("b"), expected: ptr(thing.NewString("ba"))},
+		{name: "min smaller", operation: provider.VariableOperationMin, current: ptr(thing.NewInt(5)), value: thing.NewInt(3), expected: ptr(thing.NewInt(3))},
+		{name: "min larger", operation: provider.VariableOperationMin, current: ptr(thing.NewInt(5)), value: thing.NewInt(8), expected: ptr(thing.NewInt(5))},
+		{name: "max larger", operation: provider.VariableOperationMax, current: ptr(thing.NewInt(5)), value: thing.NewInt(8), expected: ptr(thing.NewInt(8))},
+		{name: "max smaller", operation: provider.VariableOperationMax, current: ptr(thing.NewInt(5)), value: thing.NewInt(3), expected: ptr(thing.NewInt(5))},
+		{name: "set if absent existing", operation: provider.VariableOperationSetIfAbsent, current: ptr(thing.NewInt(5)), value: thing.NewInt(8), expected: ptr(thing.NewInt(5))},
+		{name: "set if absent missing", operation: provider.VariableOperationSetIfAbsent, value: thing.NewInt(8), expected: ptr(thing.NewInt(8))},
+		{name: "compare and swap equal", operation: provider.VariableOperationCompareAndSwap, args: model.VariableValueOperationArgs{Expected: ptr(thing.NewInt(5))}, current: ptr(thing.NewInt(5)), value: thing.NewInt(8), expected: ptr(thing.NewInt(8))},
+		{name: "compare and swap different", operation: provider.VariableOperationCompareAndSwap, args: model.VariableValueOperationArgs{Expected: ptr(thing.NewInt(4))}, current: ptr(thing.NewInt(5)), value: thing.NewInt(8), expected: ptr(thing.NewInt(5))},
+		{name: "compare and swap missing", operation: provider.VariableOperationCompareAndSwap, args: model.VariableValueOperationArgs{Expected: ptr(thing.Null)}, value: thing.NewInt(8), expected: ptr(thing.NewInt(8))},
+		{name: "compare and swap expected missing", operation: provider.VariableOperationCompareAndSwap, args: model.VariableValueOperationArgs{Expected: ptr(thing.NewInt(5))}, value: thing.NewInt(8)},
+		{name: "remove item", operation: provider.VariableOperationRemoveItem, current: ptr(intArray(1, 2, 1)), value: thing.NewInt(1), expected: ptr(intArray(2))},
+		{name: "remove item missing", operation: provider.VariableOperationRemoveItem, value: thing.NewInt(1)},
+		{name: "set key", operation: provider.VariableOperationSetKey, args: model.VariableValueOperationArgs{Key: "b"}, current: ptr(thing.NewObject(map[string]thing.Thing{"a": thing.NewInt(1)})), value: thing.NewInt(2), expected: ptr(thing.NewObject(map[string]thing.Thing{"a": thing.NewInt(1), "b": thing.NewInt(2)}))},
+		{name: "set key missing", operation: provider.VariableOperationSetKey, args: model.VariableValueOperationArgs{Key: "a"}, value: thing.NewInt(1), expected: ptr(thing.NewObject(map[string]thing.Thing{"a": thing.NewInt(1)}))},
+		{name: "delete key", operation: provider.VariableOperationDeleteKey, args: model.VariableValueOperationArgs{Key: "a"}, current: ptr(thing.NewObject(map[string]thing.Thing{"a": thing.NewInt(1), "b": thing.NewInt(2)})), expected: ptr(thing.NewObject(map[string]thing.Thing{"b": thing.NewInt(2)}))},
+		{name: "push", operation: provider.VariableOperationPush, args: model.VariableValueOperationArgs{MaxLength: 2}, current: ptr(intArray(1, 2)), value: thing.NewInt(3), expected: ptr(intArray(2, 3))},
+		{name: "push missing", operation: provider.VariableOperationPush, value: thing.NewInt(3), expected: ptr(intArray(3))},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			variable := createVariable(t, s, app, true)
+			scope := null.StringFrom("scope")
+
+			if test.current != nil {
+				setVariableValue(t, s, variable.ID, scope, *test.current)
+			}
+
+			res, err := s.UpdateVariableValue(ctx, test.operation, test.args, model.VariableValue{
+				VariableID: variable.ID,
+				Scope:      scope,
+				Data:       test.value,
+				CreatedAt:  now(),
+				UpdatedAt:  now(),
+			})
+			require.NoError(t, err)
+
+			stored, err := s.VariableValue(ctx, variable.ID, scope)
+			if test.expected == nil {
+				assert.True(t, res.Data.IsNil())
+				assert.ErrorIs(t, err, store.ErrNotFound)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected.String(), res.Data.String())
+			assert.Equal(t, test.expected.String(), stored.Data.String())
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		variable := createVariable(t, s, app, true)
+		scope := null.StringFrom("scope")
+		setVariableValue(t, s, variable.ID, scope, thing.NewInt(5))
+
+		// Operations that fail and rejected conversions leave the value unchanged
+		_, err := s.UpdateVariableValue(ctx, provider.VariableOperationRemoveItem, model.VariableValueOperationArgs{}, model.VariableValue{
+			VariableID: variable.ID,
+			Scope:      scope,
+			Data:       thing.NewInt(5),
+			CreatedAt:  now(),
+			UpdatedAt:  now(),
+		})
+		assert.Error(t, err)
+
+		errRejected := errors.New("rejected")
+		_, err = s.UpdateVariableValue(ctx, provider.VariableOperationIncrement, model.VariableValueOperationArgs{
+			Convert: func(thing.Thing) (thing.Thing, error) {
+				return thing.Null, errRejected
+			},
+		}, model.VariableValue{
+			VariableID: variable.ID,
+			Scope:      scope,
+			Data:       thing.NewInt(1),
+			CreatedAt:  now(),
+			UpdatedAt:  now(),
+		})
+		assert.ErrorIs(t, err, errRejected)
+
+		value, err := s.VariableValue(ctx, variable.ID, scope)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), value.Data.Int())
+	})
+}
+
+func testVariableValueConcurrentUpdates(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	variable := createVariable(t, s, app, true)
+
+	const updates = 20
+
+	// None of the increments may get lost, even when the value doesn't exist yet
+	var wg sync.WaitGroup
+	for i := 0; i < updates; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := s.UpdateVariableValue(ctx, provider.VariableOperationIncrement, model.VariableValueOperationArgs{}, model.VariableValue{
+				VariableID: variable.ID,
+				Scope:      null.StringFrom("scope"),
+				Data:       thing.NewInt(1),
+				CreatedAt:  now(),
+				UpdatedAt:  now(),
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	value, err := s.VariableValue(ctx, variable.ID, null.StringFrom("scope"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(updates), value.Data.Int())
+}
+
+func testVariableValueExpiry(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	variable := createVariable(t, s, app, true)
+
+	setExpiringValue := func(scope string, data thing.Thing, expiresAt time.Time) {
+		err := s.SetVariableValue(ctx, model.VariableValue{
+			VariableID: variable.ID,
+			Scope:      null.StringFrom(scope),
+			Data:       data,
+			CreatedAt:  now(),
+			UpdatedAt:  now(),
+			ExpiresAt:  null.TimeFrom(expiresAt),
+		})
+		require.NoError(t, err)
+	}
+
+	setExpiringValue("expired", thing.NewInt(5), now().Add(-time.Minute))
+	setExpiringValue("active", thing.NewInt(1), now().Add(time.Hour))
+
+	_, err := s.VariableValue(ctx, variable.ID, null.StringFrom("expired"))
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	value, err := s.VariableValue(ctx, variable.ID, null.StringFrom("active"))
+	require.NoError(t, err)
+	assert.True(t, value.ExpiresAt.Valid)
+
+	values, err := s.VariableValues(ctx, variable.ID)
+	require.NoError(t, err)
+	require.Len(t, values, 1)
+	assert.Equal(t, null.StringFrom("active"), values[0].Scope)
+
+	values, err = s.VariableValuesByQuery(ctx, variable.ID, model.VariableValueQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, values, 1)
+
+	aggregate, err := s.AggregateVariableValues(ctx, variable.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, aggregate.Count)
+
+	// Operations treat an expired value as if it didn't exist
+	value, err = s.UpdateVariableValue(ctx, provider.VariableOperationIncrement, model.VariableValueOperationArgs{}, model.VariableValue{
+		VariableID: variable.ID,
+		Scope:      null.StringFrom("expired"),
+		Data:       thing.NewInt(1),
+		CreatedAt:  now(),
+		UpdatedAt:  now(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), value.Data.Int())
+
+	value, err = s.VariableValue(ctx, variable.ID, null.StringFrom("expired"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), value.Data.Int())
+	assert.False(t, value.ExpiresAt.Valid)
+
+	setExpiringValue("swap", thing.NewInt(5), now().Add(-time.Minute))
+	value, err = s.UpdateVariableValue(ctx, provider.VariableOperationCompareAndSwap, model.VariableValueOperationArgs{
+		Expected: ptr(thing.Null),
+	}, model.VariableValue{
+		VariableID: variable.ID,
+		Scope:      null.StringFrom("swap"),
+		Data:       thing.NewInt(8),
+		CreatedAt:  now(),
+		UpdatedAt:  now(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), value.Data.Int())
+
+	setExpiringValue("old", thing.NewInt(5), now().Add(-time.Minute))
+
+	deleted, err := s.DeleteExpiredVariableValues(ctx, now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	values, err = s.VariableValues(ctx, variable.ID)
+	require.NoError(t, err)
+	assert.Len(t, values, 3)
+}
+
+func testVariableValueQuery(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	variable := createVariable(t, s, app, true)
+
+	setVariableValue(t, s, variable.ID, null.StringFrom("guild:1:user:a"), thing.NewInt(10))
+	setVariableValue(t, s, variable.ID, null.StringFrom("guild:1:user:b"), thing.NewFloat(30.5))
+	setVariableValue(t, s, variable.ID, null.StringFrom("guild:1:user:c"), thing.NewString("text"))
+	setVariableValue(t, s, variable.ID, null.StringFrom("guild:2:user:a"), thing.NewInt(20))
+	setVariableValue(t, s, variable.ID, null.String{}, thing.NewInt(1000))
+
+	scopes := func(query model.VariableValueQuery) []string {
+		t.Helper()
+
+		values, err := s.VariableValuesByQuery(ctx, variable.ID, query)
+		require.NoError(t, err)
+
+		res := make([]string, len(values))
+		for i, value := range values {
+			res[i] = value.Scope.String
+		}
+		return res
+	}
+
+	// The unscoped value isn't included, even without a prefix
+	assert.Equal(t, []string{"guild:1:user:a", "guild:1:user:b", "guild:1:user:c", "guild:2:user:a"}, scopes(model.VariableValueQuery{
+		Limit: 10,
+	}))
+	assert.Equal(t, []string{"guild:1:user:b", "guild:2:user:a", "guild:1:user:a", "guild:1:user:c"}, scopes(model.VariableValueQuery{
+		Order: provider.VariableQueryOrderValueDesc,
+		Limit: 10,
+	}))
+
+	// Non-numeric values always come last when ordering by value
+	assert.Equal(t, []string{"guild:1:user:b", "guild:1:user:a", "guild:1:user:c"}, scopes(model.VariableValueQuery{
+		ScopePrefix: "guild:1:",
+		Order:       provider.VariableQueryOrderValueDesc,
+		Limit:       10,
+	}))
+	assert.Equal(t, []string{"guild:1:user:a", "guild:1:user:b", "guild:1:user:c"}, scopes(model.VariableValueQuery{
+		ScopePrefix: "guild:1:",
+		Order:       provider.VariableQueryOrderValueAsc,
+		Limit:       10,
+	}))
+	assert.Equal(t, []string{"guild:1:user:c", "guild:1:user:b", "guild:1:user:a"}, scopes(model.VariableValueQuery{
+		ScopePrefix: "guild:1:",
+		Order:       provider.VariableQueryOrderScopeDesc,
+		Limit:       10,
+	}))
+
+	assert.Equal(t, []string{"guild:1:user:a"}, scopes(model.VariableValueQuery{
+		ScopePrefix: "guild:1:",
+		Order:       provider.VariableQueryOrderValueDesc,
+		Limit:       1,
+		Offset:      1,
+	}))
+
+	assert.Empty(t, scopes(model.VariableValueQuery{
+		ScopePrefix: "guild:3:",
+		Limit:       10,
+	}))
+}
+
+func testVariableValueAggregate(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	user := createUser(t, s)
+	app := createApp(t, s, user.ID)
+	variable := createVariable(t, s, app, true)
+
+	setVariableValue(t, s, variable.ID, null.StringFrom("guild:1:user:a"), thing.NewInt(10))
+	setVariableValue(t, s, variable.ID, null.StringFrom("guild:1:user:b"), thing.NewFloat(30.5))
+	setVariableValue(t, s, variable.ID, null.StringFrom("guild:1:user:c"), thing.NewString("text"))
+	setVariableValue(t, s, variable.ID, null.StringFrom("guild:2:user:a"), thing.NewInt(-20))
+	setVariableValue(t, s, variable.ID, null.String{}, thing.NewInt(-1000))
+
+	// Non-numeric values are counted, but not included in the other aggregations
+	aggregate, err := s.AggregateVariableValues(ctx, variable.ID, "guild:1:")
+	require.NoError(t, err)
+	assert.Equal(t, 3, aggregate.Count)
+	assert.InDelta(t, 40.5, aggregate.Sum, 0.0001)
+	assert.InDelta(t, 20.25, aggregate.Average.Float64, 0.0001)
+	assert.InDelta(t, 10, aggregate.Min.Float64, 0.0001)
+	assert.InDelta(t, 30.5, aggregate.Max.Float64, 0.0001)
+
+	// The unscoped value isn't included, even without a prefix
+	aggregate, err = s.AggregateVariableValues(ctx, variable.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, 4, aggregate.Count)
+	assert.InDelta(t, -20, aggregate.Min.Float64, 0.0001)
+
+	aggregate, err = s.AggregateVariableValues(ctx, variable.ID, "guild:3:")
+	require.NoError(t, err)
+	assert.Equal(t, model.VariableValueAggregate{}, *aggregate)
+
+	// Without numeric values only the count and sum have a value
+	aggregate, err = s.AggregateVariableValues(ctx, variable.ID, "guild:1:user:c")
+	require.NoError(t, err)
+	assert.Equal(t, 1, aggregate.Count)
+	assert.Zero(t, aggregate.Sum)
+	assert.False(t, aggregate.Average.Valid)
+	assert.False(t, aggregate.Min.Valid)
+	assert.False(t, aggregate.Max.Valid)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/kitecloud/kite/kite-service/internal/model"
 	"gopkg.in/guregu/null.v4"
@@ -9,8 +10,11 @@ import (
 
 type VariableStore interface {
 	VariablesByApp(ctx context.Context, appID string) ([]*model.Variable, error)
+	VariablesByNamespace(ctx context.Context, namespaceID string) ([]*model.Variable, error)
 	CountVariablesByApp(ctx context.Context, appID string) (int, error)
 	Variable(ctx context.Context, id string) (*model.Variable, error)
+	// VariableDefinition returns the variable without counting its values, it's used whenever flows and modules access a variable.
+	VariableDefinition(ctx context.Context, id string) (*model.Variable, error)
 	VariableByName(ctx context.Context, appID, name string) (*model.Variable, error)
 	CreateVariable(ctx context.Context, variable *model.Variable) (*model.Variable, error)
 	UpdateVariable(ctx context.Context, variable *model.Variable) (*model.Variable, error)
@@ -21,7 +25,13 @@ type VariableValueStore interface {
 	VariableValues(ctx context.Context, variableID string) ([]*model.VariableValue, error)
 	VariableValue(ctx context.Context, variableID string, scope null.String) (*model.VariableValue, error)
 	SetVariableValue(ctx context.Context, value model.VariableValue) error
-	UpdateVariableValue(ctx context.Context, operation model.VariableValueOperation, value model.VariableValue) (*model.VariableValue, error)
+	// UpdateVariableValue atomically applies the operation to the current value and returns the resulting value.
+	UpdateVariableValue(ctx context.Context, operation model.VariableValueOperation, args model.VariableValueOperationArgs, value model.VariableValue) (*model.VariableValue, error)
+	// VariableValuesByQuery returns the values of the variable that match the query.
+	VariableValuesByQuery(ctx context.Context, variableID string, query model.VariableValueQuery) ([]*model.VariableValue, error)
+	// AggregateVariableValues aggregates the values of the variable whose scope starts with the prefix.
+	AggregateVariableValues(ctx context.Context, variableID string, scopePrefix string) (*model.VariableValueAggregate, error)
 	DeleteVariableValue(ctx context.Context, variableID string, scope null.String) error
 	DeleteAllVariableValues(ctx context.Context, variableID string) error
+	DeleteExpiredVariableValues(ctx context.Context, now time.Time) (int, error)
 }
@@ -6,6 +6,11 @@ import (
 	"github.com/diamondburned/arikawa/v3/utils/httputil"
 )
 
+const (
+	DiscordErrorUnknownChannel httputil.ErrorCode = 10003
+	DiscordErrorUnknownMessage httputil.ErrorCode = 10008
+)
+
 func IsDiscordRestErrorCode(err error, code httputil.ErrorCode) bool {
 	if err == nil {
 		return false
@@ -4,12 +4,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/diamondburned/arikawa/v3/discord"
 	"github.com/diamondburned/arikawa/v3/gateway"
 	"github.com/diamondburned/arikawa/v3/state"
 	"github.com/diamondburned/arikawa/v3/utils/ws"
 	"github.com/expr-lang/expr/ast"
+	"github.com/kitecloud/kite/kite-service/pkg/message"
 	"github.com/kitecloud/kite/kite-service/pkg/thing"
 )
 
@@ -64,7 +66,7 @@ func NewContextFromInteraction(i *discord.InteractionEvent, session *state.State
 	interactionEnv := NewInteractionEnv(i)
 
 	return Context{
-		Env: Env{
+		Env: withFunctions(Env{
 			"interaction": interactionEnv,
 			"channel":     interactionEnv.Channel,
 			"guild":       interactionEnv.Guild,
@@ -86,11 +88,11 @@ func NewContextFromInteraction(i *discord.InteractionEvent, session *state.State
 				}
 
 				if component, ok := interactionEnv.Components[customID]; ok {
-					return component.Value
+					return component.Input()
 				}
 				return nil
 			},
-		},
+		}),
 	}
 }
 
@@ -181,6 +183,12 @@ func (c CommandEnv) String() string {
 type ComponentEnv struct {
 	CustomID string `expr:"custom_id" json:"custom_id"`
 	Value    string `expr:"value" json:"value"`
+	// Values contains the selected values of a select menu.
+	Values []string `expr:"values" json:"values,omitempty"`
+	// Attachments contains the uploaded files of a file upload.
+	Attachments []*AttachmentEnv `expr:"attachments" json:"attachments,omitempty"`
+
+	componentType discord.ComponentType
 }
 
 func NewComponentsEnv(i *discord.InteractionEvent) map[string]*ComponentEnv {
@@ -212,14 +220,55 @@ func NewComponentEnv(component discord.InteractiveComponent) *ComponentEnv {
 	switch c := component.(type) {
 	case *discord.TextInputComponent:
 		return &ComponentEnv{
-			CustomID: string(c.CustomID),
-			Value:    c.Value,
+			CustomID:      string(c.CustomID),
+			Value:         c.Value,
+			componentType: c.Type(),
+		}
+	case *message.ModalSubmitComponent:
+		env := &ComponentEnv{
+			CustomID:      string(c.CustomID),
+			Value:         strings.Join(c.Values, ", "),
+			Values:        c.Values,
+			componentType: c.ComponentType,
+		}
+
+		if c.ComponentType == message.ComponentTypeFileUpload {
+			env.Attachments = make([]*AttachmentEnv, len(c.Attachments))
+			urls := make([]string, len(c.Attachments))
+			for i := range c.Attachments {
+				env.Attachments[i] = NewAttachmentEnv(&c.Attachments[i])
+				urls[i] = c.Attachments[i].URL
+			}
+			env.Value = strings.Join(urls, ", ")
 		}
+
+		return env
 	}
 
 	return nil
 }
 
+// Input returns the submitted value with its type: a string for text inputs,
+// an array of the selected values for select menus and an array of attachments for file uploads.
+func (c ComponentEnv) Input() any {
+	switch c.componentType {
+	case discord.TextInputComponentType:
+		return c.Value
+	case message.ComponentTypeFileUpload:
+		res := make([]any, len(c.Attachments))
+		for i, attachment := range c.Attachments {
+			res[i] = attachment
+		}
+		return res
+	default:
+		res := make([]any, len(c.Values))
+		for i, value := range c.Values {
+			res[i] = value
+		}
+		return res
+	}
+}
+
 func (c ComponentEnv) String() string {
 	return c.Value
 }
@@ -295,7 +344,7 @@ func NewContext(env Env) Context {
 
 func NewContextFromEvent(event ws.Event, session *state.State) Context {
 	return Context{
-		Env: Env{
+		Env: withFunctions(Env{
 			"event":   NewEventEnv(event),
 			"user":    NewEventEnv(event).User,
 			"member":  NewEventEnv(event).Member,
@@ -304,7 +353,7 @@ func NewContextFromEvent(event ws.Event, session *state.State) Context {
 			"server":  NewEventEnv(event).Guild,
 			"message": NewEventEnv(event).Message,
 			"app":     NewAppEnv(session),
-		},
+		}),
 	}
 }
 
@@ -437,16 +486,23 @@ func (r RoleEnv) String() string {
 type MessageEnv struct {
 	og discord.Message
 
-	ID      string `expr:"id" json:"id"`
-	Content string `expr:"content" json:"content"`
+	ID          string           `expr:"id" json:"id"`
+	Content     string           `expr:"content" json:"content"`
+	Attachments []*AttachmentEnv `expr:"attachments" json:"attachments"`
 }
 
 func NewMessageEnv(msg discord.Message) *MessageEnv {
+	attachments := make([]*AttachmentEnv, len(msg.Attachments))
+	for i := range msg.Attachments {
+		attachments[i] = NewAttachmentEnv(&msg.Attachments[i])
+	}
+
 	return &MessageEnv{
 		og: msg,
 
-		ID:      msg.ID.String(),
-		Content: msg.Content,
+		ID:          msg.ID.String(),
+		Content:     msg.Content,
+		Attachments: attachments,
 	}
 }
 
@@ -564,6 +620,10 @@ func NewThingEnv(t thing.Thing) any {
 		return t.Float()
 	case thing.TypeBool:
 		return t.Bool()
+	case thing.TypeTime:
+		return t.Time()
+	case thing.TypeDuration:
+		return t.Duration()
 	case thing.TypeDiscordMessage:
 		return NewMessageEnv(t.DiscordMessage())
 	case thing.TypeDiscordUser:
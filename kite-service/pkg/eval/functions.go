@@ -0,0 +1,488 @@
+package eval
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"maps"
+	"math"
+	"math/rand"
+	"net/url"
+	"reflect"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+)
+
+const (
+	maxRegexPatternLength = 1000
+	maxRegexInputLength   = 100_000
+	regexTimeout          = 250 * time.Millisecond
+)
+
+var errRegexTimeout = errors.New("regex evaluation timed out")
+
+var mentionRegex = regexp.MustCompile(`^<(?:@!?|@&|#)(\d+)>$`)
+
+var markdownEscapeReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`*`, `\*`,
+	`_`, `\_`,
+	`~`, `\~`,
+	"`", "\\`",
+	`|`, `\|`,
+	`>`, `\>`,
+	`#`, `\#`,
+	`-`, `\-`,
+	`[`, `\[`,
+	`]`, `\]`,
+)
+
+// withFunctions adds the Kite specific helper functions to the environment.
+func withFunctions(env Env) Env {
+	maps.Copy(env, timeFunctions())
+	maps.Copy(env, Env{
+		"json_parse":      jsonParse,
+		"json_stringify":  jsonStringify,
+		"regex_match":     regexMatch,
+		"regex_find":      regexFind,
+		"regex_find_all":  regexFindAll,
+		"regex_replace":   regexReplace,
+		"url_encode":      url.QueryEscape,
+		"url_decode":      url.QueryUnescape,
+		"base64_encode":   base64Encode,
+		"base64_decode":   base64Decode,
+		"hash":            hashString,
+		"random_choice":   randomChoice,
+		"shuffle":         shuffle,
+		"format_number":   formatNumber,
+		"parse_mention":   parseMention,
+		"escape_markdown": escapeMarkdown,
+	})
+	return env
+}
+
+// jsonParse decodes a JSON string into a value that can be accessed in expressions.
+func jsonParse(raw string) (any, error) {
+	var v any
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	return toFunctionEnv(NewThingEnv(thing.NewGuessTypeRecursive(v))), nil
+}
+
+func jsonStringify(v any) (string, error) {
+	if t, ok := v.(thing.Thing); ok {
+		v = t.Value
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to stringify JSON: %w", err)
+	}
+
+	return string(raw), nil
+}
+
+func compileRegex(pattern string, input string) (*regexp.Regexp, error) {
+	if len(pattern) > maxRegexPatternLength {
+		return nil, fmt.Errorf("regex pattern exceeds max length of %d", maxRegexPatternLength)
+	}
+	if len(input) > maxRegexInputLength {
+		return nil, fmt.Errorf("regex input exceeds max length of %d", maxRegexInputLength)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %w", err)
+	}
+	return re, nil
+}
+
+// runWithTimeout runs f with a context that is cancelled after the timeout.
+// Go regexes run in linear time, but large inputs can still block a flow for too long,
+// so the regex helpers stop reading their input as soon as the context is done.
+func runWithTimeout[T any](ctx context.Context, timeout time.Duration, f func(ctx context.Context) T) (T, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res := f(ctx)
+	if err := ctx.Err(); err != nil {
+		var zero T
+		if errors.Is(err, context.DeadlineExceeded) {
+			return zero, errRegexTimeout
+		}
+		return zero, err
+	}
+
+	return res, nil
+}
+
+// contextRuneReader reads runes from a string until the context is done.
+type contextRuneReader struct {
+	ctx   context.Context
+	s     string
+	pos   int
+	reads int
+}
+
+func newContextRuneReader(ctx context.Context, s string) *contextRuneReader {
+	return &contextRuneReader{ctx: ctx, s: s}
+}
+
+func (r *contextRuneReader) ReadRune() (rune, int, error) {
+	r.reads++
+	if r.reads%256 == 0 {
+		if err := r.ctx.Err(); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if r.pos >= len(r.s) {
+		return 0, 0, io.EOF
+	}
+
+	c, size := utf8.DecodeRuneInString(r.s[r.pos:])
+	r.pos += size
+	return c, size, nil
+}
+
+// findAllSubmatchIndex is like regexp.FindAllStringSubmatchIndex but stops when the context is done.
+func findAllSubmatchIndex(ctx context.Context, re *regexp.Regexp, input string) [][]int {
+	// Searching from an offset makes the regex see the offset as the start of the text,
+	// which changes the meaning of ^ and \b. Those patterns are matched in one go instead.
+	if hasStartAssertion(re) {
+		return re.FindAllStringSubmatchIndex(input, -1)
+	}
+
+	var res [][]int
+	pos, prevMatchEnd := 0, -1
+	for pos <= len(input) && ctx.Err() == nil {
+		loc := re.FindReaderSubmatchIndex(newContextRuneReader(ctx, input[pos:]))
+		if loc == nil {
+			break
+		}
+		for i := range loc {
+			if loc[i] >= 0 {
+				loc[i] += pos
+			}
+		}
+
+		// Same rules as the regexp package: empty matches directly after a match are ignored
+		if loc[1] == pos {
+			if loc[0] != prevMatchEnd {
+				res = append(res, loc)
+			}
+			_, width := utf8.DecodeRuneInString(input[pos:])
+			pos += max(width, 1)
+		} else {
+			res = append(res, loc)
+			pos = loc[1]
+		}
+		prevMatchEnd = loc[1]
+	}
+
+	return res
+}
+
+func hasStartAssertion(re *regexp.Regexp) bool {
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return true
+	}
+
+	var walk func(re *syntax.Regexp) bool
+	walk = func(re *syntax.Regexp) bool {
+		switch re.Op {
+		case syntax.OpBeginLine, syntax.OpBeginText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+			return true
+		}
+		for _, sub := range re.Sub {
+			if walk(sub) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(parsed)
+}
+
+func regexMatch(ctx context.Context, pattern string, input string) (bool, error) {
+	re, err := compileRegex(pattern, input)
+	if err != nil {
+		return false, err
+	}
+
+	return runWithTimeout(ctx, regexTimeout, func(ctx context.Context) bool {
+		return re.MatchReader(newContextRuneReader(ctx, input))
+	})
+}
+
+// regexFind returns the first match followed by its capture groups.
+func regexFind(ctx context.Context, pattern string, input string) (listEnv, error) {
+	re, err := compileRegex(pattern, input)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := runWithTimeout(ctx, regexTimeout, func(ctx context.Context) []int {
+		return re.FindReaderSubmatchIndex(newContextRuneReader(ctx, input))
+	})
+	if err != nil || loc == nil {
+		return nil, err
+	}
+
+	res := make(listEnv, len(loc)/2)
+	for i := range res {
+		res[i] = ""
+		if loc[2*i] >= 0 {
+			res[i] = input[loc[2*i]:loc[2*i+1]]
+		}
+	}
+	return res, nil
+}
+
+func regexFindAll(ctx context.Context, pattern string, input string) (listEnv, error) {
+	re, err := compileRegex(pattern, input)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := runWithTimeout(ctx, regexTimeout, func(ctx context.Context) [][]int {
+		return findAllSubmatchIndex(ctx, re, input)
+	})
+	if err != nil || matches == nil {
+		return nil, err
+	}
+
+	res := make(listEnv, len(matches))
+	for i, loc := range matches {
+		res[i] = input[loc[0]:loc[1]]
+	}
+	return res, nil
+}
+
+// regexReplace replaces all matches, the replacement can reference groups with $1 or ${name}.
+func regexReplace(ctx context.Context, pattern string, input string, replacement string) (string, error) {
+	re, err := compileRegex(pattern, input)
+	if err != nil {
+		return "", err
+	}
+
+	return runWithTimeout(ctx, regexTimeout, func(ctx context.Context) string {
+		var b []byte
+		last := 0
+		for _, loc := range findAllSubmatchIndex(ctx, re, input) {
+			b = append(b, input[last:loc[0]]...)
+			b = re.ExpandString(b, replacement, input, loc)
+			last = loc[1]
+		}
+		b = append(b, input[last:]...)
+		return string(b)
+	})
+}
+
+func base64Encode(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func base64Decode(s string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		// Also accept URL safe and unpadded input
+		raw, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+		if err != nil {
+			return "", fmt.Errorf("failed to decode base64: %w", err)
+		}
+	}
+
+	return string(raw), nil
+}
+
+// hashString returns the hex encoded hash of s, the algorithm defaults to sha256.
+func hashString(s string, algorithm ...string) (string, error) {
+	alg := "sha256"
+	if len(algorithm) > 0 {
+		alg = strings.ToLower(algorithm[0])
+	}
+
+	var h hash.Hash
+	switch alg {
+	case "md5":
+		h = md5.New()
+	case "sha1":
+		h = sha1.New()
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return "", fmt.Errorf("unknown hash algorithm: %s", alg)
+	}
+
+	h.Write([]byte(s))
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func randomChoice(items any) (any, error) {
+	list, err := toList(items)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) == 0 {
+		return nil, nil
+	}
+
+	return list[rand.Intn(len(list))], nil
+}
+
+// shuffle returns a shuffled copy of the given array.
+func shuffle(items any) (listEnv, error) {
+	list, err := toList(items)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(listEnv, len(list))
+	copy(res, list)
+	rand.Shuffle(len(res), func(i, j int) {
+		res[i], res[j] = res[j], res[i]
+	})
+
+	return res, nil
+}
+
+// formatNumber formats a number with thousands separators and the given number of decimals.
+func formatNumber(value any, decimals ...int) (string, error) {
+	var n float64
+	switch v := value.(type) {
+	case int:
+		n = float64(v)
+	case int64:
+		n = float64(v)
+	case float64:
+		n = v
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid number: %s", v)
+		}
+		n = f
+	case thing.Thing:
+		n = v.Float()
+	default:
+		return "", fmt.Errorf("invalid number type: %T", value)
+	}
+
+	d := 0
+	if len(decimals) > 0 {
+		d = min(max(decimals[0], 0), 10)
+	}
+
+	raw := strconv.FormatFloat(math.Abs(n), 'f', d, 64)
+	intPart, fracPart, _ := strings.Cut(raw, ".")
+
+	var b strings.Builder
+	if n < 0 && strings.Trim(raw, "0.") != "" {
+		b.WriteByte('-')
+	}
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	if fracPart != "" {
+		b.WriteByte('.')
+		b.WriteString(fracPart)
+	}
+
+	return b.String(), nil
+}
+
+// parseMention extracts the ID from a user, role or channel mention.
+// Plain IDs are returned as is, anything else returns an empty string.
+func parseMention(s string) string {
+	s = strings.TrimSpace(s)
+
+	if matches := mentionRegex.FindStringSubmatch(s); matches != nil {
+		return matches[1]
+	}
+
+	if _, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return s
+	}
+
+	return ""
+}
+
+func escapeMarkdown(s string) string {
+	return markdownEscapeReplacer.Replace(s)
+}
+
+// listEnv and objectEnv are the arrays and objects returned by the helper functions.
+// They can be accessed like any other value in expressions and are converted back into things
+// when they are the result of an expression.
+type listEnv []any
+
+func (l listEnv) Thing() thing.Thing {
+	return thing.NewGuessTypeRecursive([]any(l))
+}
+
+type objectEnv map[string]any
+
+func (o objectEnv) Thing() thing.Thing {
+	return thing.NewGuessTypeRecursive(map[string]any(o))
+}
+
+func toFunctionEnv(v any) any {
+	switch v := v.(type) {
+	case []any:
+		res := make(listEnv, len(v))
+		for i, item := range v {
+			res[i] = toFunctionEnv(item)
+		}
+		return res
+	case map[string]any:
+		res := make(objectEnv, len(v))
+		for key, item := range v {
+			res[key] = toFunctionEnv(item)
+		}
+		return res
+	default:
+		return v
+	}
+}
+
+func toList(items any) ([]any, error) {
+	if t, ok := items.(thing.Thing); ok {
+		items = NewThingEnv(t)
+	}
+
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expected array, got %T", items)
+	}
+
+	res := make([]any, v.Len())
+	for i := range res {
+		res[i] = v.Index(i).Interface()
+	}
+	return res, nil
+}
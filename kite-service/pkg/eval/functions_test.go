@@ -0,0 +1,200 @@
+package eval
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func evalWithFunctions(t *testing.T, expression string) thing.Thing {
+	t.Helper()
+
+	res, err := Eval(context.Background(), expression, NewContext(withFunctions(Env{})))
+	require.NoError(t, err)
+	return res
+}
+
+func TestJSONFunctions(t *testing.T) {
+	res := evalWithFunctions(t, `json_parse('{"a": 1, "b": [true, "x"], "c": {"d": 1.5}}')`)
+	assert.Equal(t, thing.NewObject(map[string]thing.Thing{
+		"a": thing.NewInt(1),
+		"b": thing.NewArray([]thing.Thing{thing.NewBool(true), thing.NewString("x")}),
+		"c": thing.NewObject(map[string]thing.Thing{"d": thing.NewFloat(1.5)}),
+	}), res)
+
+	res = evalWithFunctions(t, `json_parse('{"a": {"b": 2}}').a.b`)
+	assert.Equal(t, thing.NewInt(2), res)
+
+	res = evalWithFunctions(t, `json_stringify({"a": [1, 2]})`)
+	assert.Equal(t, thing.NewString(`{"a":[1,2]}`), res)
+
+	_, err := Eval(context.Background(), `json_parse('{invalid')`, NewContext(withFunctions(Env{})))
+	assert.Error(t, err)
+}
+
+func TestRegexFunctions(t *testing.T) {
+	assert.Equal(t, thing.NewBool(true), evalWithFunctions(t, `regex_match("^h.llo$", "hello")`))
+	assert.Equal(t, thing.NewBool(false), evalWithFunctions(t, `regex_match("^h.llo$", "hi")`))
+	assert.Equal(t, thing.NewArray([]thing.Thing{
+		thing.NewString("id=42"),
+		thing.NewString("42"),
+	}), evalWithFunctions(t, `regex_find("id=(\\d+)", "user id=42 here")`))
+	assert.Equal(t, thing.NewArray([]thing.Thing{
+		thing.NewString("1"),
+		thing.NewString("22"),
+	}), evalWithFunctions(t, `regex_find_all("\\d+", "a1b22c")`))
+	assert.Equal(t, thing.NewString("b-a"), evalWithFunctions(t, `regex_replace("(\\w)-(\\w)", "a-b", "$2-$1")`))
+
+	_, err := Eval(context.Background(), `regex_match("(", "x")`, NewContext(withFunctions(Env{})))
+	assert.Error(t, err)
+
+	_, err = regexMatch(context.Background(), "a", strings.Repeat("a", maxRegexInputLength+1))
+	assert.Error(t, err)
+}
+
+// The regex helpers search match by match, they must find the same matches as the regexp package.
+func TestRegexFindAllMatchesStdlib(t *testing.T) {
+	cases := []struct {
+		pattern string
+		input   string
+	}{
+		{`a*`, "baaacaa"},
+		{`x*`, "héllo"},
+		{`\d+`, "1 22 333"},
+		{`^\w`, "ab cd"},
+		{`(?m)^\w`, "ab\ncd"},
+		{`\bc`, "abc c"},
+		{`(a)|(b)`, "abab"},
+	}
+
+	for _, c := range cases {
+		re := regexp.MustCompile(c.pattern)
+
+		matches, err := regexFindAll(context.Background(), c.pattern, c.input)
+		require.NoError(t, err)
+		assert.Equal(t, stdlibList(re.FindAllString(c.input, -1)), matches, c.pattern)
+
+		replaced, err := regexReplace(context.Background(), c.pattern, c.input, "[$0]")
+		require.NoError(t, err)
+		assert.Equal(t, re.ReplaceAllString(c.input, "[$0]"), replaced, c.pattern)
+	}
+}
+
+func TestRegexTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	input := strings.Repeat("a", maxRegexInputLength)
+
+	_, err := regexMatch(ctx, "b", input)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = regexFindAll(ctx, "b", input)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = runWithTimeout(context.Background(), time.Millisecond, func(ctx context.Context) bool {
+		<-ctx.Done()
+		return true
+	})
+	assert.ErrorIs(t, err, errRegexTimeout)
+}
+
+func stdlibList(matches []string) listEnv {
+	if matches == nil {
+		return nil
+	}
+
+	res := make(listEnv, len(matches))
+	for i, m := range matches {
+		res[i] = m
+	}
+	return res
+}
+
+func TestEncodingFunctions(t *testing.T) {
+	assert.Equal(t, thing.NewString("a+b%26c"), evalWithFunctions(t, `url_encode("a b&c")`))
+	assert.Equal(t, thing.NewString("a b&c"), evalWithFunctions(t, `url_decode("a+b%26c")`))
+	assert.Equal(t, thing.NewString("aGVsbG8="), evalWithFunctions(t, `base64_encode("hello")`))
+	assert.Equal(t, thing.NewString("hello"), evalWithFunctions(t, `base64_decode("aGVsbG8=")`))
+	assert.Equal(t, thing.NewString("hello"), evalWithFunctions(t, `base64_decode("aGVsbG8")`))
+}
+
+func TestHashFunction(t *testing.T) {
+	assert.Equal(t,
+		thing.NewString("2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"),
+		evalWithFunctions(t, `hash("hello")`),
+	)
+	assert.Equal(t,
+		thing.NewString("5d41402abc4b2a76b9719d911017c592"),
+		evalWithFunctions(t, `hash("hello", "md5")`),
+	)
+
+	_, err := Eval(context.Background(), `hash("hello", "crc")`, NewContext(withFunctions(Env{})))
+	assert.Error(t, err)
+}
+
+func TestRandomFunctions(t *testing.T) {
+	res := evalWithFunctions(t, `random_choice(["a", "b", "c"])`)
+	assert.Contains(t, []string{"a", "b", "c"}, res.String())
+
+	res = evalWithFunctions(t, `random_choice([])`)
+	assert.True(t, res.IsNil())
+
+	res = evalWithFunctions(t, `shuffle([1, 2, 3, 4])`)
+	assert.ElementsMatch(t, []thing.Thing{
+		thing.NewInt(1), thing.NewInt(2), thing.NewInt(3), thing.NewInt(4),
+	}, res.Array())
+}
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{expression: `format_number(1234567)`, expected: "1,234,567"},
+		{expression: `format_number(1234.567, 2)`, expected: "1,234.57"},
+		{expression: `format_number(-1000)`, expected: "-1,000"},
+		{expression: `format_number(999)`, expected: "999"},
+		{expression: `format_number("1000000.5", 1)`, expected: "1,000,000.5"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			assert.Equal(t, thing.NewString(test.expected), evalWithFunctions(t, test.expression))
+		})
+	}
+}
+
+func TestParseMention(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "<@123>", expected: "123"},
+		{input: "<@!123>", expected: "123"},
+		{input: "<@&456>", expected: "456"},
+		{input: "<#789>", expected: "789"},
+		{input: "123", expected: "123"},
+		{input: "hello", expected: ""},
+		{input: "<@abc>", expected: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			assert.Equal(t, test.expected, parseMention(test.input))
+		})
+	}
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	assert.Equal(t,
+		thing.NewString(`\*\*bold\*\* \_it\_ \~\~s\~\~ \`+"`code\\`"+` \|\|spoiler\|\|`),
+		evalWithFunctions(t, "escape_markdown(\"**bold** _it_ ~~s~~ `code` ||spoiler||\")"),
+	)
+}
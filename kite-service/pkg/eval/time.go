@@ -0,0 +1,254 @@
+package eval
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+)
+
+var timeLayouts = map[string]string{
+	"date":     "2006-01-02",
+	"time":     "15:04:05",
+	"datetime": "2006-01-02 15:04:05",
+	"rfc3339":  time.RFC3339,
+	"rfc1123":  time.RFC1123,
+	"kitchen":  time.Kitchen,
+}
+
+var discordTimestampStyles = map[string]bool{
+	"t": true,
+	"T": true,
+	"d": true,
+	"D": true,
+	"f": true,
+	"F": true,
+	"R": true,
+}
+
+func timeFunctions() Env {
+	return Env{
+		"parse_time":        parseTime,
+		"parse_duration":    parseDuration,
+		"format_time":       formatTime,
+		"discord_timestamp": discordTimestamp,
+		"add_duration":      addDuration,
+		"time_before":       timeBefore,
+		"time_after":        timeAfter,
+		"time_since":        timeSince,
+		"time_until":        timeUntil,
+		"snowflake_time":    snowflakeTime,
+	}
+}
+
+// parseTime parses a time from a string, a unix timestamp in seconds or an existing time.
+// An optional layout can be passed to parse non RFC3339 strings.
+func parseTime(value any, layout ...string) (time.Time, error) {
+	if len(layout) > 0 {
+		str, ok := value.(string)
+		if !ok {
+			return time.Time{}, fmt.Errorf("value must be a string when a layout is given")
+		}
+
+		l := layout[0]
+		if named, ok := timeLayouts[l]; ok {
+			l = named
+		}
+
+		t, err := time.Parse(l, str)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse time: %w", err)
+		}
+		return t.UTC(), nil
+	}
+
+	return toTime(value)
+}
+
+// parseDuration parses a duration like "1h30m" or "2d", numbers are interpreted as seconds.
+func parseDuration(value any) (time.Duration, error) {
+	return toDuration(value)
+}
+
+// formatTime formats a time using a named layout (e.g. "date") or a Go layout string.
+// The special layout "unix" returns the unix timestamp in seconds.
+func formatTime(value any, layout ...string) (string, error) {
+	t, err := toTime(value)
+	if err != nil {
+		return "", err
+	}
+
+	l := time.RFC3339
+	if len(layout) > 0 {
+		l = layout[0]
+	}
+
+	if l == "unix" {
+		return strconv.FormatInt(t.Unix(), 10), nil
+	}
+
+	if named, ok := timeLayouts[l]; ok {
+		l = named
+	}
+
+	return t.Format(l), nil
+}
+
+// discordTimestamp returns a Discord timestamp markdown like <t:1700000000:R>.
+func discordTimestamp(value any, style ...string) (string, error) {
+	t, err := toTime(value)
+	if err != nil {
+		return "", err
+	}
+
+	if len(style) == 0 || style[0] == "" {
+		return fmt.Sprintf("<t:%d>", t.Unix()), nil
+	}
+
+	if !discordTimestampStyles[style[0]] {
+		return "", fmt.Errorf("invalid timestamp style: %s", style[0])
+	}
+
+	return fmt.Sprintf("<t:%d:%s>", t.Unix(), style[0]), nil
+}
+
+func addDuration(value any, duration any) (time.Time, error) {
+	t, err := toTime(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	d, err := toDuration(duration)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return t.Add(d), nil
+}
+
+func timeBefore(a any, b any) (bool, error) {
+	ta, err := toTime(a)
+	if err != nil {
+		return false, err
+	}
+
+	tb, err := toTime(b)
+	if err != nil {
+		return false, err
+	}
+
+	return ta.Before(tb), nil
+}
+
+func timeAfter(a any, b any) (bool, error) {
+	ta, err := toTime(a)
+	if err != nil {
+		return false, err
+	}
+
+	tb, err := toTime(b)
+	if err != nil {
+		return false, err
+	}
+
+	return ta.After(tb), nil
+}
+
+func timeSince(value any) (time.Duration, error) {
+	t, err := toTime(value)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Since(t), nil
+}
+
+func timeUntil(value any) (time.Duration, error) {
+	t, err := toTime(value)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Until(t), nil
+}
+
+// snowflakeTime extracts the creation time from any Discord ID or object with an ID.
+func snowflakeTime(value any) (time.Time, error) {
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case int:
+		raw = strconv.Itoa(v)
+	case int64:
+		raw = strconv.FormatInt(v, 10)
+	case float64:
+		raw = strconv.FormatInt(int64(v), 10)
+	case thing.Thing:
+		raw = v.Snowflake().String()
+	case fmt.Stringer:
+		// All Discord object envs return their ID from String()
+		raw = v.String()
+	default:
+		return time.Time{}, fmt.Errorf("invalid snowflake type: %T", value)
+	}
+
+	id, err := discord.ParseSnowflake(strings.TrimSpace(raw))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid snowflake: %w", err)
+	}
+
+	return id.Time().UTC(), nil
+}
+
+func toTime(value any) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC(), nil
+	case thing.Thing:
+		return v.Time(), nil
+	case string:
+		if unix, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.Unix(unix, 0).UTC(), nil
+		}
+
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse time: %w", err)
+		}
+		return t.UTC(), nil
+	case int:
+		return time.Unix(int64(v), 0).UTC(), nil
+	case int64:
+		return time.Unix(v, 0).UTC(), nil
+	case float64:
+		return time.UnixMilli(int64(v * 1000)).UTC(), nil
+	default:
+		return time.Time{}, fmt.Errorf("invalid time type: %T", value)
+	}
+}
+
+func toDuration(value any) (time.Duration, error) {
+	switch v := value.(type) {
+	case time.Duration:
+		return v, nil
+	case thing.Thing:
+		if v.Type == thing.TypeString {
+			return thing.ParseDuration(v.String())
+		}
+		return v.Duration(), nil
+	case string:
+		return thing.ParseDuration(v)
+	case int:
+		return time.Duration(v) * time.Second, nil
+	case int64:
+		return time.Duration(v) * time.Second, nil
+	case float64:
+		return time.Duration(v * float64(time.Second)), nil
+	default:
+		return 0, fmt.Errorf("invalid duration type: %T", value)
+	}
+}
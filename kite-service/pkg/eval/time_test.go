@@ -0,0 +1,77 @@
+package eval
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeFunctions(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   thing.Thing
+	}{
+		{name: "parse rfc3339", expression: `parse_time("2024-01-01T10:00:00Z")`, expected: thing.NewTime(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC))},
+		{name: "parse unix", expression: `parse_time(1704103200)`, expected: thing.NewTime(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC))},
+		{name: "parse layout", expression: `parse_time("2024-01-01", "date")`, expected: thing.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))},
+		{name: "parse duration", expression: `parse_duration("1h30m")`, expected: thing.NewDuration(90 * time.Minute)},
+		{name: "parse duration days", expression: `parse_duration("2d")`, expected: thing.NewDuration(48 * time.Hour)},
+		{name: "format date", expression: `format_time(1704103200, "date")`, expected: thing.NewString("2024-01-01")},
+		{name: "format unix", expression: `format_time("2024-01-01T10:00:00Z", "unix")`, expected: thing.NewString("1704103200")},
+		{name: "discord timestamp", expression: `discord_timestamp(1704103200, "R")`, expected: thing.NewString("<t:1704103200:R>")},
+		{name: "discord timestamp default", expression: `discord_timestamp(1704103200)`, expected: thing.NewString("<t:1704103200>")},
+		{name: "add duration", expression: `add_duration(1704103200, "1d")`, expected: thing.NewTime(time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC))},
+		{name: "before", expression: `time_before(1704103200, "2024-01-02T00:00:00Z")`, expected: thing.NewBool(true)},
+		{name: "after", expression: `time_after(1704103200, "2024-01-02T00:00:00Z")`, expected: thing.NewBool(false)},
+		{name: "snowflake", expression: `snowflake_time("175928847299117063")`, expected: thing.NewTime(time.Date(2016, 4, 30, 11, 18, 25, 796000000, time.UTC))},
+		{name: "since in past", expression: `time_since(0) > duration("1h")`, expected: thing.NewBool(true)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := Eval(context.Background(), test.expression, NewContext(withFunctions(Env{})))
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestTimeFunctionsWithThings(t *testing.T) {
+	env := Env{
+		"days":    thing.NewString("2d"),
+		"seconds": thing.NewString("60"),
+		"invalid": thing.NewString("forever"),
+	}
+
+	res, err := Eval(context.Background(), `add_duration(1704103200, days)`, NewContext(withFunctions(env)))
+	require.NoError(t, err)
+	assert.Equal(t, thing.NewTime(time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)), res)
+
+	res, err = Eval(context.Background(), `parse_duration(seconds)`, NewContext(withFunctions(env)))
+	require.NoError(t, err)
+	assert.Equal(t, thing.NewDuration(time.Minute), res)
+
+	_, err = Eval(context.Background(), `add_duration(1704103200, invalid)`, NewContext(withFunctions(env)))
+	assert.Error(t, err)
+}
+
+func TestTimeFunctionErrors(t *testing.T) {
+	expressions := []string{
+		`parse_time("not a time")`,
+		`parse_duration("forever")`,
+		`discord_timestamp(0, "x")`,
+		`snowflake_time("abc")`,
+	}
+
+	for _, expression := range expressions {
+		t.Run(expression, func(t *testing.T) {
+			_, err := Eval(context.Background(), expression, NewContext(withFunctions(Env{})))
+			assert.Error(t, err)
+		})
+	}
+}
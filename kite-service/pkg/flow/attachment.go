@@ -0,0 +1,271 @@
+package flow
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/utils/sendpart"
+	"github.com/kitecloud/kite/kite-service/pkg/eval"
+	"github.com/kitecloud/kite/kite-service/pkg/message"
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+)
+
+// defaultMaxFileSize is used to limit downloads when no file size limit has been configured.
+const defaultMaxFileSize = 25 * 1024 * 1024 // 25MB
+
+const (
+	// generatedFileCreditsCost is the cost of storing a generated file, every full MB costs an additional credit.
+	generatedFileCreditsCost = 1
+	// attachmentDownloadCreditsCost is the cost of downloading an attachment, the same as an HTTP request.
+	attachmentDownloadCreditsCost = 3
+)
+
+type generatedFile struct {
+	name        string
+	contentType string
+	content     []byte
+}
+
+// prepareMessageFiles loads the assets and generates the files for the attachments of a message.
+// Generated files are stored as temporary assets before they are sent.
+func prepareMessageFiles(ctx *FlowContext, attachments []message.MessageAttachment) ([]sendpart.File, error) {
+	if len(attachments) == 0 {
+		return nil, nil
+	}
+
+	if ctx.Asset == nil {
+		return nil, &FlowError{
+			Code:    FlowNodeErrorUnknown,
+			Message: "asset provider is not available",
+		}
+	}
+
+	files := make([]sendpart.File, 0, len(attachments))
+	for _, attachment := range attachments {
+		if !attachment.IsGenerated() {
+			asset, err := ctx.Asset.Asset(ctx, attachment.AssetID)
+			if err != nil {
+				if errors.Is(err, provider.ErrNotFound) {
+					return nil, fmt.Errorf("attachment asset %s not found", attachment.AssetID)
+				}
+				return nil, err
+			}
+
+			files = append(files, sendpart.File{
+				Name:   asset.Name,
+				Reader: bytes.NewReader(asset.Content),
+			})
+			continue
+		}
+
+		content, err := ctx.EvalTemplate(attachment.Content)
+		if err != nil {
+			return nil, err
+		}
+
+		generated, err := generateFiles(ctx, attachment.Filename, content)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range generated {
+			if err := ctx.increaseCredits(fileCreditsCost(len(file.content))); err != nil {
+				return nil, err
+			}
+
+			asset, err := ctx.Asset.CreateTemporaryAsset(ctx, file.name, file.contentType, file.content)
+			if err != nil {
+				return nil, err
+			}
+
+			files = append(files, sendpart.File{
+				Name:   asset.Name,
+				Reader: bytes.NewReader(asset.Content),
+			})
+		}
+	}
+
+	return files, nil
+}
+
+// generateFiles turns the result of an attachment template into files.
+// Text and HTTP responses become a single file, attachments of Discord messages are downloaded
+// and keep their original name unless a filename has been set for a single attachment.
+func generateFiles(ctx *FlowContext, filename string, content thing.Thing) ([]generatedFile, error) {
+	attachments := attachmentsFromThing(content)
+	if attachments != nil {
+		if len(attachments) == 1 && filename != "" {
+			attachments[0].Filename = filename
+		}
+
+		res := make([]generatedFile, 0, len(attachments))
+		for _, attachment := range attachments {
+			file, err := downloadAttachment(ctx, attachment)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, *file)
+		}
+		return res, nil
+	}
+
+	var file generatedFile
+	if content.Type == thing.TypeHTTPResponse {
+		resp := content.Value.(thing.HTTPResponseValue)
+		file = generatedFile{
+			name:        filename,
+			contentType: resp.Headers["Content-Type"],
+			content:     resp.Body,
+		}
+	} else {
+		file = generatedFile{
+			name:        filename,
+			contentType: "text/plain; charset=utf-8",
+			content:     []byte(content.String()),
+		}
+	}
+
+	if file.name == "" {
+		file.name = "file" + extensionByType(file.contentType)
+	}
+
+	if err := checkFileSize(ctx, len(file.content)); err != nil {
+		return nil, err
+	}
+
+	return []generatedFile{file}, nil
+}
+
+// attachmentsFromThing returns the Discord attachments that the thing refers to.
+// It returns nil if the thing doesn't refer to any attachments.
+func attachmentsFromThing(t thing.Thing) []eval.AttachmentEnv {
+	switch t.Type {
+	case thing.TypeDiscordMessage:
+		msg := t.Value.(discord.Message)
+		if len(msg.Attachments) == 0 {
+			return nil
+		}
+
+		res := make([]eval.AttachmentEnv, len(msg.Attachments))
+		for i := range msg.Attachments {
+			res[i] = *eval.NewAttachmentEnv(&msg.Attachments[i])
+		}
+		return res
+	case thing.TypeAny:
+		switch v := t.Value.(type) {
+		case *eval.AttachmentEnv:
+			return []eval.AttachmentEnv{*v}
+		case []*eval.AttachmentEnv:
+			res := make([]eval.AttachmentEnv, len(v))
+			for i, a := range v {
+				res[i] = *a
+			}
+			return res
+		}
+	case thing.TypeArray:
+		var res []eval.AttachmentEnv
+		for _, item := range t.Array() {
+			attachments := attachmentsFromThing(item)
+			if attachments == nil {
+				return nil
+			}
+			res = append(res, attachments...)
+		}
+		return res
+	}
+
+	return nil
+}
+
+func downloadAttachment(ctx *FlowContext, attachment eval.AttachmentEnv) (*generatedFile, error) {
+	if ctx.HTTP == nil {
+		return nil, &FlowError{
+			Code:    FlowNodeErrorUnknown,
+			Message: "http provider is not available",
+		}
+	}
+
+	if err := ctx.increaseCredits(attachmentDownloadCreditsCost); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, attachment.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := ctx.HTTP.HTTPRequest(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download attachment: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download attachment: %s", resp.Status)
+	}
+
+	maxSize := int64(ctx.MaxFileSize)
+	if maxSize == 0 {
+		maxSize = defaultMaxFileSize
+	}
+
+	// Read one more byte than allowed to detect files that are too large
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+
+	if int64(len(content)) > maxSize {
+		return nil, &FlowError{
+			Code:    FlowNodeErrorMaxFileSizeReached,
+			Message: fmt.Sprintf("attachment %s exceeds the max file size of %d bytes", attachment.Filename, maxSize),
+		}
+	}
+
+	return &generatedFile{
+		name:        attachment.Filename,
+		contentType: resp.Header.Get("Content-Type"),
+		content:     content,
+	}, nil
+}
+
+func fileCreditsCost(size int) int {
+	return generatedFileCreditsCost + size/(1024*1024)
+}
+
+func checkFileSize(ctx *FlowContext, size int) error {
+	if ctx.MaxFileSize != 0 && size > ctx.MaxFileSize {
+		return &FlowError{
+			Code:    FlowNodeErrorMaxFileSizeReached,
+			Message: fmt.Sprintf("file size of %d bytes exceeds the max file size of %d bytes", size, ctx.MaxFileSize),
+		}
+	}
+	return nil
+}
+
+func extensionByType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	switch mediaType {
+	case "text/plain":
+		// mime returns .asc for text/plain on some systems
+		return ".txt"
+	case "application/json":
+		return ".json"
+	}
+
+	extensions, err := mime.ExtensionsByType(mediaType)
+	if err != nil || len(extensions) == 0 {
+		return ""
+	}
+	return extensions[0]
+}
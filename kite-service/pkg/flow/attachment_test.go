@@ -0,0 +1,100 @@
+package flow
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/pkg/eval"
+	"github.com/kitecloud/kite/kite-service/pkg/message"
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepareMessageFiles(t *testing.T) {
+	ctx := NewContext(
+		context.Background(),
+		5*time.Second,
+		&TestContextData{},
+		FlowProviders{
+			Asset: &provider.MockAssetProvider{},
+			Log:   &provider.MockLogProvider{},
+		},
+		FlowContextLimits{
+			MaxStackDepth: 10,
+			MaxOperations: 1000,
+			MaxCredits:    1000,
+			MaxFileSize:   16,
+		},
+		eval.NewContext(eval.Env{}),
+		nil,
+	)
+	defer ctx.Cancel()
+
+	ctx.StoreNodeResult(&CompiledFlowNode{ID: "1"}, thing.NewHTTPResponse(thing.HTTPResponseValue{
+		StatusCode: 200,
+		Body:       []byte(`{"ok":true}`),
+		Headers:    map[string]string{"Content-Type": "application/json"},
+	}))
+
+	files, err := prepareMessageFiles(ctx, []message.MessageAttachment{
+		{Filename: "report.csv", Content: "a,b\n1,2"},
+		{Content: "{{nodes[1].result}}"},
+	})
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	assert.Equal(t, "report.csv", files[0].Name)
+	content, err := io.ReadAll(files[0].Reader)
+	require.NoError(t, err)
+	assert.Equal(t, "a,b\n1,2", string(content))
+
+	assert.Equal(t, "file.json", files[1].Name)
+	content, err = io.ReadAll(files[1].Reader)
+	require.NoError(t, err)
+	assert.Equal(t, `{"ok":true}`, string(content))
+	assert.Equal(t, 2*generatedFileCreditsCost, ctx.CreditsUsed())
+
+	_, err = prepareMessageFiles(ctx, []message.MessageAttachment{
+		{Filename: "large.txt", Content: "this text is too large"},
+	})
+	var flowErr *FlowError
+	require.ErrorAs(t, err, &flowErr)
+	assert.Equal(t, FlowNodeErrorMaxFileSizeReached, flowErr.Code)
+}
+
+func TestPrepareMessageFilesCredits(t *testing.T) {
+	ctx := NewContext(
+		context.Background(),
+		5*time.Second,
+		&TestContextData{},
+		FlowProviders{
+			Asset: &provider.MockAssetProvider{},
+			Log:   &provider.MockLogProvider{},
+		},
+		FlowContextLimits{
+			MaxCredits: 1,
+		},
+		eval.NewContext(eval.Env{}),
+		nil,
+	)
+	defer ctx.Cancel()
+
+	_, err := prepareMessageFiles(ctx, []message.MessageAttachment{
+		{Filename: "a.txt", Content: "a"},
+		{Filename: "b.txt", Content: "b"},
+	})
+	var flowErr *FlowError
+	require.ErrorAs(t, err, &flowErr)
+	assert.Equal(t, FlowNodeErrorMaxCreditsReached, flowErr.Code)
+}
+
+func TestFileCreditsCost(t *testing.T) {
+	assert.Equal(t, 1, fileCreditsCost(0))
+	assert.Equal(t, 1, fileCreditsCost(1024*1024-1))
+	assert.Equal(t, 2, fileCreditsCost(1024*1024))
+	assert.Equal(t, 26, fileCreditsCost(25*1024*1024))
+}
@@ -132,6 +132,10 @@ func (n *CompiledFlowNode) IsCommandContexts() bool {
 	return n.Type == FlowNodeTypeOptionCommandContexts
 }
 
+func (n *CompiledFlowNode) IsCommandCooldown() bool {
+	return n.Type == FlowNodeTypeOptionCommandCooldown
+}
+
 func (n *CompiledFlowNode) IsEventFilter() bool {
 	return n.Type == FlowNodeTypeOptionEventFilter
 }
@@ -359,6 +363,16 @@ func (n *CompiledFlowNode) CommandPermissions() *discord.Permissions {
 	return nil
 }
 
+func (n *CompiledFlowNode) CommandCooldown() *CommandCooldownData {
+	for _, node := range n.Parents.Default {
+		if node.IsCommandCooldown() && node.Data.CommandCooldownData != nil {
+			return node.Data.CommandCooldownData
+		}
+	}
+
+	return nil
+}
+
 func (n *CompiledFlowNode) CommandContexts() []discord.InteractionContext {
 	// True when disabled
 	var guild, botDM, privateChannel bool
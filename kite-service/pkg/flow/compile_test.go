@@ -3,6 +3,7 @@ package flow
 import (
 	"testing"
 
+	"github.com/diamondburned/arikawa/v3/discord"
 	"github.com/kitecloud/kite/kite-service/pkg/message"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -77,3 +78,50 @@ func TestFlowCompileCommand(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, expected, got)
 }
+
+func TestFlowCompileCommandCooldown(t *testing.T) {
+	input := FlowData{
+		Nodes: append([]FlowNode{
+			{
+				ID:   "2",
+				Type: FlowNodeTypeOptionCommandCooldown,
+				Data: FlowNodeData{
+					CommandCooldownData: &CommandCooldownData{
+						Bucket:          CommandCooldownBucketMember,
+						DurationSeconds: 60,
+					},
+				},
+			},
+		}, flowCommandInput.Nodes...),
+		Edges: append([]FlowEdge{
+			{
+				Source: "2",
+				Target: "0",
+			},
+		}, flowCommandInput.Edges...),
+	}
+
+	got, err := CompileCommand(input)
+	require.NoError(t, err)
+
+	cooldown := got.CommandCooldown()
+	require.NotNil(t, cooldown)
+	assert.Equal(t, 1, cooldown.MaxUses())
+
+	guildInteraction := &discord.InteractionEvent{
+		GuildID:   1,
+		ChannelID: 2,
+		Member:    &discord.Member{User: discord.User{ID: 3}},
+	}
+	dmInteraction := &discord.InteractionEvent{
+		ChannelID: 2,
+		User:      &discord.User{ID: 3},
+	}
+
+	assert.Equal(t, "member:1:3", cooldown.BucketKey(guildInteraction))
+	assert.Equal(t, "user:3", cooldown.BucketKey(dmInteraction))
+
+	cooldown.Bucket = CommandCooldownBucketGuild
+	assert.Equal(t, "guild:1", cooldown.BucketKey(guildInteraction))
+	assert.Equal(t, "channel:2", cooldown.BucketKey(dmInteraction))
+}
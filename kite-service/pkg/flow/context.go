@@ -49,7 +49,7 @@ func NewContext(
 	}
 	evalCtx.Patchers = append(evalCtx.Patchers, &nodeEvalPatcher{})
 
-	return &FlowContext{
+	c := &FlowContext{
 		Context: ctx,
 		Cancel:  cancel,
 		Data:    data,
@@ -59,6 +59,21 @@ func NewContext(
 		FlowContextLimits: limits,
 		FlowContextState:  *state,
 	}
+
+	variableEvalEnv := &variableEvalEnv{
+		ctx:      ctx,
+		provider: providers.Variable,
+		startOperation: func(credits int) error {
+			if err := c.startOperation(credits); err != nil {
+				return err
+			}
+			c.endOperation()
+			return nil
+		},
+	}
+	evalCtx.Env["variable"] = variableEvalEnv.GetVariable
+
+	return c
 }
 
 type FlowContextData interface {
@@ -75,6 +90,8 @@ type FlowContextLimits struct {
 	MaxStackDepth int
 	MaxOperations int
 	MaxCredits    int
+	// MaxFileSize is the maximum size of files that are generated by the flow in bytes, 0 means no limit.
+	MaxFileSize int
 
 	stackDepth int
 	operations int
@@ -3,7 +3,12 @@ package flow
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/diamondburned/arikawa/v3/api"
 	"github.com/diamondburned/arikawa/v3/discord"
@@ -12,6 +17,7 @@ import (
 	"github.com/kitecloud/kite/kite-service/pkg/eval"
 	"github.com/kitecloud/kite/kite-service/pkg/message"
 	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
 	"github.com/sashabaranov/go-openai"
 	"gopkg.in/guregu/null.v4"
 )
@@ -47,6 +53,7 @@ const (
 	FlowNodeTypeOptionCommandArgument    FlowNodeType = "option_command_argument"
 	FlowNodeTypeOptionCommandPermissions FlowNodeType = "option_command_permissions"
 	FlowNodeTypeOptionCommandContexts    FlowNodeType = "option_command_contexts"
+	FlowNodeTypeOptionCommandCooldown    FlowNodeType = "option_command_cooldown"
 	FlowNodeTypeOptionEventFilter        FlowNodeType = "option_event_filter"
 
 	FlowNodeTypeActionResponseCreate        FlowNodeType = "action_response_create"
@@ -83,12 +90,15 @@ const (
 	FlowNodeTypeActionHTTPRequest           FlowNodeType = "action_http_request"
 	FlowNodeTypeActionAIChatCompletion      FlowNodeType = "action_ai_chat_completion"
 	FlowNodeTypeActionAISearchWeb           FlowNodeType = "action_ai_web_search"
+	FlowNodeTypeActionAIModeration          FlowNodeType = "action_ai_moderation"
 	FlowNodeTypeActionExpressionEvaluate    FlowNodeType = "action_expression_evaluate"
 	FlowNodeTypeActionRandomGenerate        FlowNodeType = "action_random_generate"
 	FlowNodeTypeActionLog                   FlowNodeType = "action_log"
 	FlowNodeTypeActionVariableSet           FlowNodeType = "action_variable_set"
 	FlowNodeTypeActionVariableDelete        FlowNodeType = "action_variable_delete"
 	FlowNodeTypeActionVariableGet           FlowNodeType = "action_variable_get"
+	FlowNodeTypeActionVariableQuery         FlowNodeType = "action_variable_query"
+	FlowNodeTypeActionModuleCall            FlowNodeType = "action_module_call"
 
 	FlowNodeTypeControlConditionCompare     FlowNodeType = "control_condition_compare"
 	FlowNodeTypeControlConditionItemCompare FlowNodeType = "control_condition_item_compare"
@@ -155,6 +165,9 @@ type FlowNodeData struct {
 	// Command Installations
 	CommandDisabledIntegrations []CommandDisabledIntegrationType `json:"command_disabled_integrations,omitempty"`
 
+	// Command Cooldown
+	CommandCooldownData *CommandCooldownData `json:"command_cooldown_data,omitempty"`
+
 	// Guild Get
 	GuildTarget string `json:"guild_target,omitempty"`
 
@@ -193,6 +206,13 @@ type FlowNodeData struct {
 	VariableScope     string                     `json:"variable_scope,omitempty"`
 	VariableValue     string                     `json:"variable_value,omitempty"`
 	VariableOperation provider.VariableOperation `json:"variable_operation,omitempty"`
+	// Variable Set with the set_key, delete_key, compare_and_swap or push operation
+	VariableKey       string `json:"variable_key,omitempty"`
+	VariableExpected  string `json:"variable_expected,omitempty"`
+	VariableMaxLength int    `json:"variable_max_length,omitempty"`
+
+	// Variable Query
+	VariableQueryData *VariableQueryData `json:"variable_query_data,omitempty"`
 
 	// HTTP Request
 	HTTPRequestData *HTTPRequestData `json:"http_request_data,omitempty"`
@@ -200,6 +220,12 @@ type FlowNodeData struct {
 	// AI Chat Completion
 	AIChatCompletionData *AIChatCompletionData `json:"ai_chat_completion_data,omitempty"`
 
+	// Module Call
+	ModuleCallData *ModuleCallData `json:"module_call_data,omitempty"`
+
+	// AI Moderation
+	AIModerationData *AIModerationData `json:"ai_moderation_data,omitempty"`
+
 	// Random Generate
 	RandomMin string `json:"random_min,omitempty"`
 	RandomMax string `json:"random_max,omitempty"`
@@ -264,6 +290,11 @@ func (d FlowNodeData) Validate(nodeType FlowNodeType) error {
 			validation.Length(1, 100),
 		)),
 
+		// Command Cooldown
+		validation.Field(&d.CommandCooldownData, validation.When(nodeType == FlowNodeTypeOptionCommandCooldown,
+			validation.Required,
+		)),
+
 		// Event Entry
 		validation.Field(&d.EventType, validation.When(nodeType == FlowNodeTypeEntryEvent,
 			validation.Required,
@@ -277,6 +308,27 @@ func (d FlowNodeData) Validate(nodeType FlowNodeType) error {
 		validation.Field(&d.AIChatCompletionData, validation.When(nodeType == FlowNodeTypeActionAIChatCompletion,
 			validation.Required,
 		)),
+
+		// AI Moderation
+		validation.Field(&d.AIModerationData, validation.When(nodeType == FlowNodeTypeActionAIModeration,
+			validation.Required,
+		)),
+		// Variable Set
+		validation.Field(&d.VariableExpected, validation.When(
+			nodeType == FlowNodeTypeActionVariableSet && d.VariableOperation == provider.VariableOperationCompareAndSwap,
+			validation.Required.Error("is required for compare and swap, use set if absent to only set missing values"),
+		)),
+
+		// Variable Query
+		validation.Field(&d.VariableQueryData, validation.When(nodeType == FlowNodeTypeActionVariableQuery,
+			validation.Required,
+		)),
+
+		// Message
+		validation.Field(&d.MessageData),
+
+		// Modal
+		validation.Field(&d.ModalData),
 	)
 }
 
@@ -345,6 +397,73 @@ const (
 	RobloxLookupTypeName RobloxLookupType = "username"
 )
 
+type CommandCooldownBucket string
+
+const (
+	CommandCooldownBucketUser    CommandCooldownBucket = "user"
+	CommandCooldownBucketMember  CommandCooldownBucket = "member"
+	CommandCooldownBucketChannel CommandCooldownBucket = "channel"
+	CommandCooldownBucketGuild   CommandCooldownBucket = "guild"
+	CommandCooldownBucketGlobal  CommandCooldownBucket = "global"
+)
+
+type CommandCooldownData struct {
+	Bucket          CommandCooldownBucket `json:"bucket,omitempty"`
+	DurationSeconds int                   `json:"duration_seconds,omitempty"`
+	// Limit is the number of uses allowed per window, 0 or 1 means a simple cooldown.
+	Limit int `json:"limit,omitempty"`
+	// Message is sent as an ephemeral response when the cooldown is active.
+	Message string `json:"message,omitempty"`
+}
+
+func (d CommandCooldownData) Validate() error {
+	return validation.ValidateStruct(&d,
+		validation.Field(&d.Bucket, validation.Required, validation.In(
+			CommandCooldownBucketUser,
+			CommandCooldownBucketMember,
+			CommandCooldownBucketChannel,
+			CommandCooldownBucketGuild,
+			CommandCooldownBucketGlobal,
+		)),
+		validation.Field(&d.DurationSeconds, validation.Required, validation.Min(1), validation.Max(30*24*60*60)),
+		validation.Field(&d.Limit, validation.Min(0), validation.Max(1000)),
+		validation.Field(&d.Message, validation.Length(0, 2000)),
+	)
+}
+
+func (d CommandCooldownData) Duration() time.Duration {
+	return time.Duration(d.DurationSeconds) * time.Second
+}
+
+func (d CommandCooldownData) MaxUses() int {
+	return max(d.Limit, 1)
+}
+
+// BucketKey returns the key that identifies the cooldown bucket for the given interaction.
+// Buckets that don't apply outside of guilds fall back to the next narrower bucket.
+func (d CommandCooldownData) BucketKey(i *discord.InteractionEvent) string {
+	userID := i.SenderID()
+
+	switch d.Bucket {
+	case CommandCooldownBucketMember:
+		if i.GuildID.IsValid() {
+			return fmt.Sprintf("member:%s:%s", i.GuildID, userID)
+		}
+		return fmt.Sprintf("user:%s", userID)
+	case CommandCooldownBucketChannel:
+		return fmt.Sprintf("channel:%s", i.ChannelID)
+	case CommandCooldownBucketGuild:
+		if i.GuildID.IsValid() {
+			return fmt.Sprintf("guild:%s", i.GuildID)
+		}
+		return fmt.Sprintf("channel:%s", i.ChannelID)
+	case CommandCooldownBucketGlobal:
+		return "global"
+	default:
+		return fmt.Sprintf("user:%s", userID)
+	}
+}
+
 type CommandArgumentChoiceData struct {
 	Name  string `json:"name,omitempty"`
 	Value string `json:"value,omitempty"`
@@ -465,16 +584,105 @@ type ModalData struct {
 	Components []ModalComponentData `json:"components,omitempty"`
 }
 
+// maxModalComponents is the max number of inputs that Discord allows in a modal.
+const maxModalComponents = 5
+
+func (d ModalData) Validate() error {
+	return validation.ValidateStruct(&d,
+		validation.Field(&d.Components,
+			validation.Length(0, maxModalComponents),
+			// Every input is sent as its own label, so the inputs of all rows count towards the limit
+			validation.By(func(value any) error {
+				count := 0
+				for _, row := range d.Components {
+					count += len(row.Components)
+				}
+				if count > maxModalComponents {
+					return fmt.Errorf("modals can have at most %d inputs", maxModalComponents)
+				}
+				return nil
+			}),
+		),
+	)
+}
+
 type ModalComponentData struct {
+	// Type is one of the message component types that can be used in modals, text inputs are used by default.
+	Type        int                  `json:"type,omitempty"`
 	CustomID    string               `json:"custom_id,omitempty"`
 	Style       int                  `json:"style,omitempty"`
 	Label       string               `json:"label,omitempty"`
+	Description string               `json:"description,omitempty"`
 	MinLength   int                  `json:"min_length,omitempty"`
 	MaxLength   int                  `json:"max_length,omitempty"`
 	Required    bool                 `json:"required,omitempty"`
 	Value       string               `json:"value,omitempty"`
 	Placeholder string               `json:"placeholder,omitempty"`
 	Components  []ModalComponentData `json:"components,omitempty"`
+
+	// Select Menu and File Upload
+	MinValues  int                           `json:"min_values,omitempty"`
+	MaxValues  int                           `json:"max_values,omitempty"`
+	Options    []ModalSelectOptionData       `json:"options,omitempty"`
+	Validation *ModalComponentValidationData `json:"validation,omitempty"`
+}
+
+func (d ModalComponentData) Validate() error {
+	return validation.ValidateStruct(&d,
+		validation.Field(&d.Type, validation.In(
+			0,
+			message.ComponentTypeTextInput,
+			message.ComponentTypeStringSelect,
+			message.ComponentTypeUserSelect,
+			message.ComponentTypeRoleSelect,
+			message.ComponentTypeMentionableSelect,
+			message.ComponentTypeChannelSelect,
+			message.ComponentTypeFileUpload,
+		)),
+		validation.Field(&d.Description, validation.Length(0, 100)),
+		validation.Field(&d.Options, validation.When(d.Type == message.ComponentTypeStringSelect,
+			validation.Required,
+			validation.Length(1, 25),
+		)),
+		validation.Field(&d.Validation),
+		validation.Field(&d.Components),
+	)
+}
+
+// IsTextInput returns true if the component is a text input, which is also the case for components without a type.
+func (d *ModalComponentData) IsTextInput() bool {
+	return d.Type == 0 || d.Type == message.ComponentTypeTextInput
+}
+
+type ModalSelectOptionData struct {
+	Label       string `json:"label,omitempty"`
+	Value       string `json:"value,omitempty"`
+	Description string `json:"description,omitempty"`
+	Default     bool   `json:"default,omitempty"`
+}
+
+// ModalComponentValidationData contains rules that the submitted value of a text input must meet.
+// If a rule isn't met the flow continues with the "invalid" handle of the modal node.
+type ModalComponentValidationData struct {
+	// Pattern is a regular expression that must match the value.
+	Pattern string `json:"pattern,omitempty"`
+	// Min and Max require the value to be a number in the range.
+	Min null.Float `json:"min,omitempty"`
+	Max null.Float `json:"max,omitempty"`
+	// ErrorMessage replaces the default error message.
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+func (d ModalComponentValidationData) Validate() error {
+	return validation.ValidateStruct(&d,
+		validation.Field(&d.Pattern, validation.Length(0, 1000), validation.By(func(value interface{}) error {
+			if _, err := regexp.Compile(d.Pattern); err != nil {
+				return errors.New("must be a valid regular expression")
+			}
+			return nil
+		})),
+		validation.Field(&d.ErrorMessage, validation.Length(0, 1000)),
+	)
 }
 
 type HTTPRequestData struct {
@@ -490,6 +698,58 @@ type HTTPRequestDataKeyValue struct {
 	Value string `json:"value"`
 }
 
+type VariableQueryMode string
+
+const (
+	VariableQueryModeList    VariableQueryMode = "list"
+	VariableQueryModeCount   VariableQueryMode = "count"
+	VariableQueryModeSum     VariableQueryMode = "sum"
+	VariableQueryModeAverage VariableQueryMode = "average"
+	VariableQueryModeMin     VariableQueryMode = "min"
+	VariableQueryModeMax     VariableQueryMode = "max"
+)
+
+const (
+	variableQueryDefaultLimit = 10
+	variableQueryMaxLimit     = 100
+)
+
+type VariableQueryData struct {
+	// Mode defaults to list, which returns an array of {scope, value} objects.
+	// All other modes return a single number.
+	Mode        VariableQueryMode           `json:"mode,omitempty"`
+	ScopePrefix string                      `json:"scope_prefix,omitempty"`
+	Order       provider.VariableQueryOrder `json:"order,omitempty"`
+	Limit       string                      `json:"limit,omitempty"`
+	Offset      string                      `json:"offset,omitempty"`
+}
+
+func (d VariableQueryData) Validate() error {
+	return validation.ValidateStruct(&d,
+		validation.Field(&d.Mode, validation.In(
+			VariableQueryModeList,
+			VariableQueryModeCount,
+			VariableQueryModeSum,
+			VariableQueryModeAverage,
+			VariableQueryModeMin,
+			VariableQueryModeMax,
+		)),
+		validation.Field(&d.Order, validation.In(
+			provider.VariableQueryOrderValueDesc,
+			provider.VariableQueryOrderValueAsc,
+			provider.VariableQueryOrderScopeAsc,
+			provider.VariableQueryOrderScopeDesc,
+		)),
+	)
+}
+
+type ModuleCallData struct {
+	ModuleID string `json:"module_id,omitempty"`
+	Function string `json:"function,omitempty"`
+	// Input is evaluated as a template and passed to the function, it's encoded as a JSON string if it isn't valid JSON.
+	Input string `json:"input,omitempty"`
+}
+
 type AIChatCompletionData struct {
 	Model               string `json:"model,omitempty"`
 	SystemPrompt        string `json:"system_prompt,omitempty"`
@@ -504,6 +764,52 @@ func (d AIChatCompletionData) Validate() error {
 	)
 }
 
+type AIModerationData struct {
+	Text string `json:"text,omitempty"`
+	// ImageURLs can contain templates that evaluate to a single URL or an array of URLs / attachments.
+	ImageURLs []string `json:"image_urls,omitempty"`
+	// Threshold overrides the provider's flagging when set. Content is flagged if any category score reaches it.
+	Threshold string `json:"threshold,omitempty"`
+}
+
+func (d AIModerationData) Validate() error {
+	return validation.ValidateStruct(&d,
+		validation.Field(&d.Text, validation.Required.When(len(d.ImageURLs) == 0), validation.Length(0, 2000)),
+		validation.Field(&d.ImageURLs, validation.Length(0, 10)),
+		validation.Field(&d.Threshold, validation.By(func(value any) error {
+			// Templates can only be checked once they are evaluated
+			if d.Threshold == "" || strings.Contains(d.Threshold, "{{") {
+				return nil
+			}
+			_, err := parseModerationThreshold(thing.NewString(d.Threshold))
+			return err
+		})),
+	)
+}
+
+// parseModerationThreshold returns the threshold as a number between 0 and 1.
+// Anything else would flag all or no content, so it's rejected instead.
+func parseModerationThreshold(value thing.Thing) (float64, error) {
+	var threshold float64
+	switch value.Type {
+	case thing.TypeInt, thing.TypeFloat:
+		threshold = value.Float()
+	case thing.TypeString:
+		var err error
+		threshold, err = strconv.ParseFloat(strings.TrimSpace(value.String()), 64)
+		if err != nil {
+			return 0, errors.New("must be a number between 0 and 1")
+		}
+	default:
+		return 0, errors.New("must be a number between 0 and 1")
+	}
+
+	if !(threshold >= 0 && threshold <= 1) {
+		return 0, errors.New("must be a number between 0 and 1")
+	}
+	return threshold, nil
+}
+
 type FlowNodePosition struct {
 	X float64 `json:"x"`
 	Y float64 `json:"y"`
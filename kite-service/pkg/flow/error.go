@@ -12,6 +12,8 @@ const (
 	FlowNodeErrorMaxCreditsReached       FlowNodeErrorCode = "max_credits_reached"
 	FlowNodeErrorMaxExecutionTimeReached FlowNodeErrorCode = "max_execution_time_reached"
 	FlowNodeErrorTimeout                 FlowNodeErrorCode = "timeout"
+	FlowNodeErrorMaxFileSizeReached      FlowNodeErrorCode = "max_file_size_reached"
+	FlowNodeErrorInvalidThreshold        FlowNodeErrorCode = "invalid_threshold"
 )
 
 type FlowError struct {
@@ -34,6 +36,10 @@ func (e *FlowErrorTrace) Error() string {
 	return fmt.Sprintf("Flow error (%s): %s", e.NodeType, e.Next.Error())
 }
 
+func (e *FlowErrorTrace) Unwrap() error {
+	return e.Next
+}
+
 func traceError(node *CompiledFlowNode, err error) error {
 	if err == nil {
 		return nil
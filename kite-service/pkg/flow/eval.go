@@ -1,11 +1,15 @@
 package flow
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/expr-lang/expr/ast"
 	"github.com/kitecloud/kite/kite-service/pkg/eval"
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
 	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"gopkg.in/guregu/null.v4"
 )
 
 type nodeEvalEnv struct {
@@ -28,6 +32,45 @@ func (e *nodeEvalEnv) GetNode(rawID any) (any, error) {
 	}, nil
 }
 
+// variableEvalCreditsCost is the cost of reading a variable in an expression, it's the same as for the Get Variable node.
+const variableEvalCreditsCost = 1
+
+type variableEvalEnv struct {
+	ctx      context.Context
+	provider provider.VariableProvider
+	// startOperation charges every read and counts it against the operation limit of the flow.
+	startOperation func(credits int) error
+}
+
+// GetVariable returns the value of a variable by its ID and optional scope.
+// This includes shared variables from namespaces that the app has access to.
+func (e *variableEvalEnv) GetVariable(id string, scope ...string) (any, error) {
+	if e.provider == nil {
+		return nil, fmt.Errorf("variables are not available")
+	}
+
+	if e.startOperation != nil {
+		if err := e.startOperation(variableEvalCreditsCost); err != nil {
+			return nil, err
+		}
+	}
+
+	var s null.String
+	if len(scope) != 0 {
+		s = null.StringFrom(scope[0])
+	}
+
+	value, err := e.provider.Variable(e.ctx, id, s)
+	if err != nil {
+		if errors.Is(err, provider.ErrNotFound) {
+			return eval.NewThingEnv(thing.Null), nil
+		}
+		return nil, err
+	}
+
+	return eval.NewThingEnv(value), nil
+}
+
 func (ctx *FlowContext) EvalTemplate(template string) (thing.Thing, error) {
 	res, err := eval.EvalTemplate(ctx, template, ctx.EvalCtx)
 	if err != nil {
@@ -21,10 +21,49 @@ import (
 	"github.com/kitecloud/kite/kite-service/pkg/message"
 	"github.com/kitecloud/kite/kite-service/pkg/provider"
 	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/guregu/null.v4"
 )
 
+var tracer = otel.Tracer("github.com/kitecloud/kite/kite-service/pkg/flow")
+
 func (n *CompiledFlowNode) Execute(ctx *FlowContext) error {
+	if n == nil {
+		return n.execute(ctx)
+	}
+
+	// Child nodes and provider calls will use the node span as their parent
+	parent := ctx.Context
+	var span trace.Span
+	ctx.Context, span = tracer.Start(parent, "flow.node."+string(n.Type), trace.WithAttributes(
+		attribute.String("node_id", n.ID),
+		attribute.String("node_type", string(n.Type)),
+	))
+	defer func() {
+		ctx.Context = parent
+		span.End()
+	}()
+
+	var done func(error)
+	if ctx.NodeObserver != nil {
+		done = ctx.NodeObserver.ObserveNode(ctx, n)
+	}
+
+	err := n.execute(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "node execution failed")
+	}
+	if done != nil {
+		done(err)
+	}
+	return err
+}
+
+func (n *CompiledFlowNode) execute(ctx *FlowContext) error {
 	if n == nil {
 		// TODO: Figure out why nodes are some times nil, this is probably a bug in the compiler?
 		return fmt.Errorf("node is nil")
@@ -164,6 +203,7 @@ func (n *CompiledFlowNode) Execute(ctx *FlowContext) error {
 					Content:    responseData.Content,
 					Embeds:     responseData.Embeds,
 					Components: responseData.Components,
+					Files:      responseData.Files,
 				})
 				if err != nil {
 					return traceError(n, err)
@@ -197,6 +237,7 @@ func (n *CompiledFlowNode) Execute(ctx *FlowContext) error {
 				api.EditInteractionResponseData{
 					Content: responseData.Content,
 					Embeds:  responseData.Embeds,
+					Files:   responseData.Files,
 				},
 			)
 			if err != nil {
@@ -292,7 +333,13 @@ func (n *CompiledFlowNode) Execute(ctx *FlowContext) error {
 				return traceError(n, err)
 			}
 
-			err = n.ExecuteChildren(ctx)
+			if msg := n.validateModalSubmit(ctx); msg != "" {
+				// The error message can be used to tell the user what's wrong with their input
+				ctx.StoreNodeResult(n, thing.NewString(msg))
+				err = n.ExecuteChildrenByHandle(ctx, "invalid")
+			} else {
+				err = n.ExecuteChildren(ctx)
+			}
 			if err != nil {
 				createDefaultErrorResponse(ctx, err)
 				return traceError(n, err)
@@ -321,23 +368,7 @@ func (n *CompiledFlowNode) Execute(ctx *FlowContext) error {
 			return traceError(n, fmt.Errorf("failed to suspend: %w", err))
 		}
 
-		componentRows := make(discord.ContainerComponents, len(n.Data.ModalData.Components))
-		for i, row := range n.Data.ModalData.Components {
-			r := make(discord.ActionRowComponent, len(row.Components))
-			for j, component := range row.Components {
-				r[j] = &discord.TextInputComponent{
-					CustomID:     discord.ComponentID(component.CustomID),
-					Label:        component.Label,
-					Style:        discord.TextInputStyle(component.Style),
-					Required:     component.Required,
-					LengthLimits: [2]int{component.MinLength, component.MaxLength},
-					Value:        component.Value,
-					Placeholder:  component.Placeholder,
-				}
-			}
-
-			componentRows[i] = discord.ContainerComponent(&r)
-		}
+		componentRows := n.Data.ModalData.ToComponents()
 
 		resp := api.InteractionResponse{
 			Type: api.ModalResponse,
@@ -428,6 +459,7 @@ func (n *CompiledFlowNode) Execute(ctx *FlowContext) error {
 			api.EditMessageData{
 				Content: option.NewNullableString(messageData.Content),
 				Embeds:  &messageData.Embeds,
+				Files:   messageData.Files,
 			},
 		)
 		if err != nil {
@@ -1164,12 +1196,33 @@ func (n *CompiledFlowNode) Execute(ctx *FlowContext) error {
 			return traceError(n, err)
 		}
 
+		args := provider.VariableOperationArgs{
+			MaxLength: n.Data.VariableMaxLength,
+		}
+
+		if n.Data.VariableKey != "" {
+			key, err := ctx.EvalTemplate(n.Data.VariableKey)
+			if err != nil {
+				return traceError(n, err)
+			}
+			args.Key = key.String()
+		}
+
+		if n.Data.VariableExpected != "" {
+			expected, err := ctx.EvalTemplate(n.Data.VariableExpected)
+			if err != nil {
+				return traceError(n, err)
+			}
+			args.Expected = &expected
+		}
+
 		newValue, err := ctx.Variable.UpdateVariable(
 			ctx,
 			n.Data.VariableID,
 			null.NewString(scope.String(), !scope.IsEmpty()),
 			n.Data.VariableOperation,
 			value,
+			args,
 		)
 		if err != nil {
 			return traceError(n, err)
@@ -1210,6 +1263,88 @@ func (n *CompiledFlowNode) Execute(ctx *FlowContext) error {
 
 		ctx.StoreNodeResult(n, val)
 		return n.ExecuteChildren(ctx)
+	case FlowNodeTypeActionVariableQuery:
+		data := n.Data.VariableQueryData
+		if data == nil {
+			return &FlowError{
+				Code:    FlowNodeErrorUnknown,
+				Message: "variable_query_data is nil",
+			}
+		}
+
+		scopePrefix, err := ctx.EvalTemplate(data.ScopePrefix)
+		if err != nil {
+			return traceError(n, err)
+		}
+
+		var res thing.Thing
+		if data.Mode == "" || data.Mode == VariableQueryModeList {
+			limit, err := ctx.EvalTemplate(data.Limit)
+			if err != nil {
+				return traceError(n, err)
+			}
+
+			offset, err := ctx.EvalTemplate(data.Offset)
+			if err != nil {
+				return traceError(n, err)
+			}
+
+			query := provider.VariableQuery{
+				ScopePrefix: scopePrefix.String(),
+				Order:       data.Order,
+				Limit:       int(limit.Int()),
+				Offset:      max(int(offset.Int()), 0),
+			}
+			if query.Limit <= 0 {
+				query.Limit = variableQueryDefaultLimit
+			}
+			query.Limit = min(query.Limit, variableQueryMaxLimit)
+
+			values, err := ctx.Variable.QueryVariable(ctx, n.Data.VariableID, query)
+			if err != nil {
+				return traceError(n, err)
+			}
+
+			items := make([]thing.Thing, len(values))
+			for i, v := range values {
+				scope := thing.Null
+				if v.Scope.Valid {
+					scope = thing.NewString(v.Scope.String)
+				}
+
+				items[i] = thing.NewObject(map[string]thing.Thing{
+					"scope": scope,
+					"value": v.Value,
+				})
+			}
+			res = thing.NewArray(items)
+		} else {
+			aggregate, err := ctx.Variable.AggregateVariable(ctx, n.Data.VariableID, scopePrefix.String())
+			if err != nil {
+				return traceError(n, err)
+			}
+
+			switch data.Mode {
+			case VariableQueryModeCount:
+				res = thing.NewInt(aggregate.Count)
+			case VariableQueryModeSum:
+				res = thing.NewFloat(aggregate.Sum)
+			case VariableQueryModeAverage:
+				res = nullFloatThing(aggregate.Average)
+			case VariableQueryModeMin:
+				res = nullFloatThing(aggregate.Min)
+			case VariableQueryModeMax:
+				res = nullFloatThing(aggregate.Max)
+			default:
+				return &FlowError{
+					Code:    FlowNodeErrorUnknown,
+					Message: fmt.Sprintf("unknown variable query mode: %s", data.Mode),
+				}
+			}
+		}
+
+		ctx.StoreNodeResult(n, res)
+		return n.ExecuteChildren(ctx)
 	case FlowNodeTypeActionHTTPRequest:
 		if n.Data.HTTPRequestData == nil {
 			return &FlowError{
@@ -1277,6 +1412,31 @@ func (n *CompiledFlowNode) Execute(ctx *FlowContext) error {
 
 		ctx.StoreNodeResult(n, result)
 		return n.ExecuteChildren(ctx)
+	case FlowNodeTypeActionModuleCall:
+		data := n.Data.ModuleCallData
+		if data == nil || data.ModuleID == "" || data.Function == "" {
+			return &FlowError{
+				Code:    FlowNodeErrorUnknown,
+				Message: "module_call_data is nil",
+			}
+		}
+
+		if ctx.Module == nil {
+			return traceError(n, fmt.Errorf("modules are not available"))
+		}
+
+		input, err := ctx.EvalTemplate(data.Input)
+		if err != nil {
+			return traceError(n, err)
+		}
+
+		output, err := ctx.Module.CallModuleFunction(ctx, data.ModuleID, data.Function, moduleInput(input.String()), ctx.ModuleHost())
+		if err != nil {
+			return traceError(n, err)
+		}
+
+		ctx.StoreNodeResult(n, moduleOutput(output))
+		return n.ExecuteChildren(ctx)
 	case FlowNodeTypeActionAIChatCompletion:
 		data := n.Data.AIChatCompletionData
 		if data == nil || data.Prompt == "" {
@@ -1349,6 +1509,96 @@ func (n *CompiledFlowNode) Execute(ctx *FlowContext) error {
 		}
 
 		ctx.StoreNodeResult(n, thing.NewString(response))
+		return n.ExecuteChildren(ctx)
+	case FlowNodeTypeActionAIModeration:
+		data := n.Data.AIModerationData
+		if data == nil {
+			return &FlowError{
+				Code:    FlowNodeErrorUnknown,
+				Message: "ai_moderation_data is nil",
+			}
+		}
+
+		text, err := ctx.EvalTemplate(data.Text)
+		if err != nil {
+			return traceError(n, err)
+		}
+
+		imageURLs := make([]string, 0, len(data.ImageURLs))
+		for _, rawURL := range data.ImageURLs {
+			url, err := ctx.EvalTemplate(rawURL)
+			if err != nil {
+				return traceError(n, err)
+			}
+
+			if url.Type == thing.TypeArray {
+				for _, item := range url.Array() {
+					if item.String() != "" {
+						imageURLs = append(imageURLs, item.String())
+					}
+				}
+			} else if url.String() != "" {
+				imageURLs = append(imageURLs, url.String())
+			}
+		}
+
+		res, err := ctx.AI.ModerateContent(ctx, provider.ModerateContentOpts{
+			Text:      text.String(),
+			ImageURLs: imageURLs,
+		})
+		if err != nil {
+			return traceError(n, err)
+		}
+
+		flagged := res.Flagged
+		if data.Threshold != "" {
+			value, err := ctx.EvalTemplate(data.Threshold)
+			if err != nil {
+				return traceError(n, err)
+			}
+
+			threshold, err := parseModerationThreshold(value)
+			if err != nil {
+				return traceError(n, &FlowError{
+					Code:    FlowNodeErrorInvalidThreshold,
+					Message: fmt.Sprintf("threshold %q %s", value.String(), err),
+				})
+			}
+
+			flagged = false
+			for _, score := range res.CategoryScores {
+				if score >= threshold {
+					flagged = true
+					break
+				}
+			}
+		}
+
+		categories := make(map[string]thing.Thing, len(res.Categories))
+		for category, value := range res.Categories {
+			categories[category] = thing.NewBool(value)
+		}
+
+		scores := make(map[string]thing.Thing, len(res.CategoryScores))
+		for category, score := range res.CategoryScores {
+			scores[category] = thing.NewFloat(score)
+		}
+
+		ctx.StoreNodeResult(n, thing.NewObject(map[string]thing.Thing{
+			"flagged":    thing.NewBool(flagged),
+			"categories": thing.NewObject(categories),
+			"scores":     thing.NewObject(scores),
+		}))
+
+		handle := "clean"
+		if flagged {
+			handle = "flagged"
+		}
+
+		if err := n.ExecuteChildrenByHandle(ctx, handle); err != nil {
+			return err
+		}
+
 		return n.ExecuteChildren(ctx)
 	case FlowNodeTypeActionRandomGenerate:
 		min, err := ctx.EvalTemplate(n.Data.RandomMin)
@@ -1686,8 +1936,12 @@ func (n *CompiledFlowNode) CreditsCost() int {
 		default:
 			return 25
 		}
+	case FlowNodeTypeActionAIModeration:
+		return 5
 	case FlowNodeTypeActionHTTPRequest:
 		return 3
+	case FlowNodeTypeActionModuleCall:
+		return ModuleCallCreditsCost
 	}
 
 	if n.IsAction() {
@@ -1741,10 +1995,29 @@ func (n *CompiledFlowNode) autoDeferInteraction(ctx *FlowContext) error {
 		responseFlags |= discord.EphemeralMessage
 	}
 
-	go ctx.Discord.AutoDeferInteraction(ctx, interaction.ID, interaction.Token, responseFlags)
+	// Pass the underlying context because the flow context is modified while nodes are executed
+	go ctx.Discord.AutoDeferInteraction(ctx.Context, interaction.ID, interaction.Token, responseFlags)
 	return nil
 }
 
+func (n *CompiledFlowNode) validateModalSubmit(ctx *FlowContext) string {
+	if n.Data.ModalData == nil {
+		return ""
+	}
+
+	interaction := ctx.Data.Interaction()
+	if interaction == nil {
+		return ""
+	}
+
+	data, ok := interaction.Data.(*discord.ModalInteraction)
+	if !ok {
+		return ""
+	}
+
+	return n.Data.ModalData.ValidateSubmit(data)
+}
+
 func (n *CompiledFlowNode) resumeFromComponent(ctx *FlowContext) error {
 	interaction := ctx.Data.Interaction()
 	if interaction == nil {
@@ -1820,7 +2093,7 @@ func (n *CompiledFlowNode) prepareMessageResponseData(ctx *FlowContext) (api.Int
 	}
 
 	var resumePointID string
-	if n.Data.MessageTemplateID == "" && len(data.Components) > 0 {
+	if n.Data.MessageTemplateID == "" && data.HasComponents() {
 		resumePointID = util.UniqueID()
 	}
 
@@ -1833,6 +2106,11 @@ func (n *CompiledFlowNode) prepareMessageResponseData(ctx *FlowContext) (api.Int
 		},
 	})
 
+	responseData.Files, err = prepareMessageFiles(ctx, data.Attachments)
+	if err != nil {
+		return api.InteractionResponseData{}, "", err
+	}
+
 	return responseData, resumePointID, nil
 }
 
@@ -1843,7 +2121,7 @@ func (n *CompiledFlowNode) prepareMessageSendData(ctx *FlowContext) (api.SendMes
 	}
 
 	var resumePointID string
-	if n.Data.MessageTemplateID == "" && len(data.Components) > 0 {
+	if n.Data.MessageTemplateID == "" && data.HasComponents() {
 		// The resume point will be created after the message has been sent, we just need the ID here already
 		resumePointID = util.UniqueID()
 	}
@@ -1857,6 +2135,11 @@ func (n *CompiledFlowNode) prepareMessageSendData(ctx *FlowContext) (api.SendMes
 		},
 	})
 
+	sendData.Files, err = prepareMessageFiles(ctx, data.Attachments)
+	if err != nil {
+		return api.SendMessageData{}, "", err
+	}
+
 	return sendData, resumePointID, nil
 }
 
@@ -1889,3 +2172,11 @@ func createDefaultErrorResponse(fCtx *FlowContext, err error) {
 		})
 	}
 }
+
+// nullFloatThing returns thing.Null for an invalid float, e.g. the minimum of a variable without numeric values.
+func nullFloatThing(v null.Float) thing.Thing {
+	if !v.Valid {
+		return thing.Null
+	}
+	return thing.NewFloat(v.Float64)
+}
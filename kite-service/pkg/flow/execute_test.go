@@ -2,6 +2,7 @@ package flow
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -12,8 +13,12 @@ import (
 	"github.com/kitecloud/kite/kite-service/pkg/eval"
 	"github.com/kitecloud/kite/kite-service/pkg/message"
 	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"github.com/kitecloud/kite/kite-service/pkg/wasm"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/guregu/null.v4"
 )
 
 var flowCommandTest = CompiledFlowNode{
@@ -97,14 +102,310 @@ func TestFlowExecuteCommand(t *testing.T) {
 	assert.Equal(t, "Pong!", discordProvider.response.Data.Content.Val)
 }
 
+func TestFlowExecuteAIModeration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name      string
+		threshold string
+		response  string
+	}{
+		{name: "provider flagged", threshold: "", response: "flagged"},
+		{name: "below threshold", threshold: "0.9", response: "clean"},
+		{name: "above threshold", threshold: "0.5", response: "flagged"},
+		{name: "template threshold", threshold: "{{ 0.8 }}", response: "clean"},
+		{name: "invalid threshold", threshold: "{{ 'high' }}"},
+		{name: "threshold out of range", threshold: "{{ 80 }}"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			discordProvider := &TestDiscordProvider{}
+
+			c := NewContext(
+				ctx,
+				5*time.Second,
+				&TestContextData{},
+				FlowProviders{
+					Discord: discordProvider,
+					AI:      &TestAIProvider{},
+					Log:     &provider.MockLogProvider{},
+				}, FlowContextLimits{
+					MaxStackDepth: 10,
+					MaxOperations: 1000,
+					MaxCredits:    1000,
+				},
+				eval.NewContext(eval.Env{}),
+				nil,
+			)
+			defer c.Cancel()
+
+			node := &CompiledFlowNode{
+				ID:   "0",
+				Type: FlowNodeTypeActionAIModeration,
+				Data: FlowNodeData{
+					AIModerationData: &AIModerationData{
+						Text:      "some text",
+						Threshold: test.threshold,
+					},
+				},
+				Children: ConnectedFlowNodes{
+					Handles: map[string][]*CompiledFlowNode{
+						"flagged": {{
+							ID:   "1",
+							Type: FlowNodeTypeActionResponseCreate,
+							Data: FlowNodeData{
+								MessageData: &message.MessageData{Content: "flagged"},
+							},
+						}},
+						"clean": {{
+							ID:   "2",
+							Type: FlowNodeTypeActionResponseCreate,
+							Data: FlowNodeData{
+								MessageData: &message.MessageData{Content: "clean"},
+							},
+						}},
+					},
+				},
+			}
+
+			err := node.Execute(c)
+			if test.response == "" {
+				var flowErr *FlowError
+				require.ErrorAs(t, err, &flowErr)
+				assert.Equal(t, FlowNodeErrorInvalidThreshold, flowErr.Code)
+				assert.Nil(t, discordProvider.response.Data, "no branch runs")
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, discordProvider.response.Data)
+			require.NotNil(t, discordProvider.response.Data.Content)
+			assert.Equal(t, test.response, discordProvider.response.Data.Content.Val)
+
+			result := c.GetNodeState("0").Result.Object()
+			assert.Equal(t, 0.7, result["scores"].Object()["harassment"].Float())
+		})
+	}
+}
+
+func TestAIModerationDataValidate(t *testing.T) {
+	for _, threshold := range []string{"", "0", "0.75", "1", "{{ var('threshold') }}"} {
+		data := AIModerationData{Text: "text", Threshold: threshold}
+		assert.NoError(t, data.Validate(), threshold)
+	}
+
+	for _, threshold := range []string{"high", "1.5", "-0.1", "NaN"} {
+		data := AIModerationData{Text: "text", Threshold: threshold}
+		assert.Error(t, data.Validate(), threshold)
+	}
+}
+
+func TestFlowExecuteModuleCall(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	moduleProvider := &TestModuleProvider{hostCalls: 3}
+
+	c := NewContext(
+		ctx,
+		5*time.Second,
+		&TestContextData{},
+		FlowProviders{
+			Module: moduleProvider,
+			Log:    &provider.MockLogProvider{},
+		}, FlowContextLimits{
+			MaxStackDepth: 10,
+			MaxOperations: 1000,
+			MaxCredits:    1000,
+		},
+		eval.NewContext(eval.Env{}),
+		nil,
+	)
+	defer c.Cancel()
+
+	node := &CompiledFlowNode{
+		ID:   "0",
+		Type: FlowNodeTypeActionModuleCall,
+		Data: FlowNodeData{
+			ModuleCallData: &ModuleCallData{
+				ModuleID: "module",
+				Function: "greet",
+				Input:    "kite",
+			},
+		},
+	}
+
+	err := node.Execute(c)
+	require.NoError(t, err)
+	assert.Equal(t, `"kite"`, string(moduleProvider.input))
+	assert.Equal(t, "Hello kite", c.GetNodeState("0").Result.Object()["greeting"].String())
+	// 5 credits for the node and 1 for each host call
+	assert.Equal(t, 8, c.CreditsUsed())
+
+	c = NewContext(
+		ctx,
+		5*time.Second,
+		&TestContextData{},
+		FlowProviders{
+			Module: moduleProvider,
+			Log:    &provider.MockLogProvider{},
+		}, FlowContextLimits{
+			MaxStackDepth: 10,
+			MaxOperations: 1000,
+			MaxCredits:    6,
+		},
+		eval.NewContext(eval.Env{}),
+		nil,
+	)
+	defer c.Cancel()
+
+	err = node.Execute(c)
+	require.ErrorContains(t, err, string(FlowNodeErrorMaxCreditsReached))
+}
+
+func TestFlowExecuteVariableQuery(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	variableProvider := &TestVariableProvider{
+		values: []provider.VariableScopeValue{
+			{Scope: null.StringFrom("user:1"), Value: thing.NewInt(30)},
+			{Scope: null.StringFrom("user:2"), Value: thing.NewInt(10)},
+		},
+	}
+
+	c := NewContext(
+		ctx,
+		5*time.Second,
+		&TestContextData{},
+		FlowProviders{
+			Variable: variableProvider,
+			Log:      &provider.MockLogProvider{},
+		}, FlowContextLimits{
+			MaxStackDepth: 10,
+			MaxOperations: 1000,
+			MaxCredits:    1000,
+		},
+		eval.NewContext(eval.Env{}),
+		nil,
+	)
+	defer c.Cancel()
+
+	node := &CompiledFlowNode{
+		ID:   "0",
+		Type: FlowNodeTypeActionVariableQuery,
+		Data: FlowNodeData{
+			VariableID: "points",
+			VariableQueryData: &VariableQueryData{
+				ScopePrefix: "user:",
+				Order:       provider.VariableQueryOrderValueDesc,
+				Limit:       "1000",
+				Offset:      "1",
+			},
+		},
+	}
+
+	err := node.Execute(c)
+	require.NoError(t, err)
+	assert.Equal(t, provider.VariableQuery{
+		ScopePrefix: "user:",
+		Order:       provider.VariableQueryOrderValueDesc,
+		Limit:       variableQueryMaxLimit,
+		Offset:      1,
+	}, variableProvider.query)
+
+	items := c.GetNodeState("0").Result.Array()
+	require.Len(t, items, 2)
+	assert.Equal(t, "user:1", items[0].Object()["scope"].String())
+	assert.Equal(t, int64(30), items[0].Object()["value"].Int())
+
+	node.Data.VariableQueryData = &VariableQueryData{Mode: VariableQueryModeAverage}
+	err = node.Execute(c)
+	require.NoError(t, err)
+	assert.Equal(t, 20.0, c.GetNodeState("0").Result.Float())
+
+	// Without values there is no average
+	variableProvider.values = nil
+	err = node.Execute(c)
+	require.NoError(t, err)
+	assert.True(t, c.GetNodeState("0").Result.IsNil())
+}
+
+type TestVariableProvider struct {
+	provider.MockVariableProvider
+
+	values []provider.VariableScopeValue
+	query  provider.VariableQuery
+}
+
+func (p *TestVariableProvider) QueryVariable(ctx context.Context, id string, query provider.VariableQuery) ([]provider.VariableScopeValue, error) {
+	p.query = query
+	return p.values, nil
+}
+
+func (p *TestVariableProvider) AggregateVariable(ctx context.Context, id string, scopePrefix string) (provider.VariableAggregate, error) {
+	res := provider.VariableAggregate{Count: len(p.values)}
+	for _, v := range p.values {
+		res.Sum += v.Value.Float()
+	}
+	if res.Count > 0 {
+		res.Average = null.FloatFrom(res.Sum / float64(res.Count))
+	}
+	return res, nil
+}
+
+type TestModuleProvider struct {
+	hostCalls int
+	input     []byte
+}
+
+func (p *TestModuleProvider) CallModuleFunction(ctx context.Context, moduleID string, function string, input []byte, host *wasm.Host) ([]byte, error) {
+	p.input = input
+
+	for i := 0; i < p.hostCalls; i++ {
+		if err := host.StartOperation(1); err != nil {
+			return nil, err
+		}
+	}
+
+	var name string
+	if err := json.Unmarshal(input, &name); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(map[string]string{"greeting": "Hello " + name})
+}
+
+type TestAIProvider struct {
+	provider.MockAIProvider
+}
+
+func (p *TestAIProvider) ModerateContent(ctx context.Context, opts provider.ModerateContentOpts) (*provider.ModerationResult, error) {
+	return &provider.ModerationResult{
+		Flagged: true,
+		Categories: map[string]bool{
+			"harassment": true,
+			"violence":   false,
+		},
+		CategoryScores: map[string]float64{
+			"harassment": 0.7,
+			"violence":   0.1,
+		},
+	}, nil
+}
+
 type TestDiscordProvider struct {
 	provider.MockDiscordProvider
 
-	response api.InteractionResponse
+	response            api.InteractionResponse
+	responseSpanContext trace.SpanContext
 }
 
 func (p *TestDiscordProvider) CreateInteractionResponse(ctx context.Context, interactionID discord.InteractionID, interactionToken string, response api.InteractionResponse) (*provider.InteractionResponseResource, error) {
 	p.response = response
+	p.responseSpanContext = trace.SpanContextFromContext(ctx)
 	return nil, nil
 }
 
@@ -137,3 +438,99 @@ func (d *TestContextData) MessageComponentData() discord.ComponentInteraction {
 func (d *TestContextData) Event() ws.Event {
 	return &gateway.InteractionCreateEvent{}
 }
+
+type TestScopedVariableProvider struct {
+	provider.MockVariableProvider
+
+	values map[string]thing.Thing
+}
+
+func (p *TestScopedVariableProvider) Variable(ctx context.Context, id string, scope null.String) (thing.Thing, error) {
+	value, ok := p.values[id+"/"+scope.String]
+	if !ok {
+		return thing.Null, provider.ErrNotFound
+	}
+	return value, nil
+}
+
+func TestFlowEvalVariable(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	c := NewContext(
+		ctx,
+		5*time.Second,
+		&TestContextData{},
+		FlowProviders{
+			Variable: &TestScopedVariableProvider{values: map[string]thing.Thing{
+				"coins/":       thing.NewInt(3),
+				"coins/user:1": thing.NewInt(7),
+			}},
+			Log: &provider.MockLogProvider{},
+		}, FlowContextLimits{
+			MaxStackDepth: 10,
+			MaxOperations: 1000,
+			MaxCredits:    1000,
+		},
+		eval.NewContext(eval.Env{}),
+		nil,
+	)
+	defer c.Cancel()
+
+	res, err := c.EvalTemplate(`{{ variable("coins") + variable("coins", "user:1") }}`)
+	require.NoError(t, err)
+	assert.Equal(t, "10", res.String())
+
+	// Missing values behave like missing temporary variables
+	res, err = c.EvalTemplate(`{{ variable("missing") }}`)
+	require.NoError(t, err)
+	expected, err := c.EvalTemplate(`{{ var("missing") }}`)
+	require.NoError(t, err)
+	assert.Equal(t, expected, res)
+}
+
+func TestFlowEvalVariableLimits(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	newContext := func(limits FlowContextLimits) *FlowContext {
+		return NewContext(
+			ctx,
+			5*time.Second,
+			&TestContextData{},
+			FlowProviders{
+				Variable: &TestScopedVariableProvider{values: map[string]thing.Thing{
+					"coins/": thing.NewInt(3),
+				}},
+				Log: &provider.MockLogProvider{},
+			},
+			limits,
+			eval.NewContext(eval.Env{}),
+			nil,
+		)
+	}
+
+	c := newContext(FlowContextLimits{
+		MaxStackDepth: 10,
+		MaxOperations: 1000,
+		MaxCredits:    2,
+	})
+	defer c.Cancel()
+
+	_, err := c.EvalTemplate(`{{ variable("coins") + variable("coins") }}`)
+	require.NoError(t, err)
+	assert.Equal(t, 2, c.CreditsUsed())
+
+	_, err = c.EvalTemplate(`{{ variable("coins") }}`)
+	require.ErrorContains(t, err, string(FlowNodeErrorMaxCreditsReached))
+
+	c = newContext(FlowContextLimits{
+		MaxStackDepth: 10,
+		MaxOperations: 2,
+		MaxCredits:    1000,
+	})
+	defer c.Cancel()
+
+	_, err = c.EvalTemplate(`{{ variable("coins") + variable("coins") + variable("coins") }}`)
+	require.ErrorContains(t, err, string(FlowNodeErrorMaxOperationsReached))
+}
@@ -0,0 +1,139 @@
+package flow
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/kitecloud/kite/kite-service/pkg/message"
+)
+
+// ToComponents converts the modal into label components.
+// Labels are required for select menus and file uploads and also work for text inputs.
+func (d *ModalData) ToComponents() discord.ContainerComponents {
+	res := make(discord.ContainerComponents, 0, len(d.Components))
+	for _, row := range d.Components {
+		for _, component := range row.Components {
+			res = append(res, component.toLabel())
+		}
+	}
+	return res
+}
+
+func (d *ModalComponentData) toLabel() discord.ContainerComponent {
+	typ := d.Type
+	component := map[string]any{
+		"custom_id": d.CustomID,
+		"required":  d.Required,
+	}
+
+	if d.IsTextInput() {
+		typ = message.ComponentTypeTextInput
+		component["style"] = max(d.Style, int(discord.TextInputShortStyle))
+		if d.MinLength > 0 {
+			component["min_length"] = d.MinLength
+		}
+		if d.MaxLength > 0 {
+			component["max_length"] = d.MaxLength
+		}
+		if d.Value != "" {
+			component["value"] = d.Value
+		}
+	} else {
+		if d.MinValues > 0 {
+			component["min_values"] = d.MinValues
+		}
+		if d.MaxValues > 0 {
+			component["max_values"] = d.MaxValues
+		}
+	}
+
+	if d.Placeholder != "" && typ != message.ComponentTypeFileUpload {
+		component["placeholder"] = d.Placeholder
+	}
+
+	if typ == message.ComponentTypeStringSelect {
+		options := make([]any, len(d.Options))
+		for i, option := range d.Options {
+			o := map[string]any{
+				"label":   option.Label,
+				"value":   option.Value,
+				"default": option.Default,
+			}
+			if option.Description != "" {
+				o["description"] = option.Description
+			}
+			options[i] = o
+		}
+		component["options"] = options
+	}
+
+	label := map[string]any{
+		"label":     d.Label,
+		"component": message.NewRawComponent(typ, component),
+	}
+	if d.Description != "" {
+		label["description"] = d.Description
+	}
+
+	return message.NewRawComponent(message.ComponentTypeLabel, label)
+}
+
+// ValidateSubmit checks the submitted values of the text inputs against their validation rules.
+// It returns the error message of the first rule that isn't met or an empty string if all values are valid.
+func (d *ModalData) ValidateSubmit(interaction *discord.ModalInteraction) string {
+	for _, row := range d.Components {
+		for _, component := range row.Components {
+			if component.Validation == nil || !component.IsTextInput() {
+				continue
+			}
+
+			input, ok := interaction.Components.Find(discord.ComponentID(component.CustomID)).(*discord.TextInputComponent)
+			if !ok || input.Value == "" {
+				// Optional inputs that haven't been filled out are always valid
+				continue
+			}
+
+			if msg := component.Validation.check(component.Label, input.Value); msg != "" {
+				return msg
+			}
+		}
+	}
+
+	return ""
+}
+
+func (d *ModalComponentValidationData) check(label string, value string) string {
+	if d.Pattern != "" {
+		re, err := regexp.Compile(d.Pattern)
+		if err != nil || !re.MatchString(value) {
+			return d.errorMessage(fmt.Sprintf("%s has an invalid format.", label))
+		}
+	}
+
+	if d.Min.Valid || d.Max.Valid {
+		n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return d.errorMessage(fmt.Sprintf("%s must be a number.", label))
+		}
+
+		if d.Min.Valid && n < d.Min.Float64 {
+			return d.errorMessage(fmt.Sprintf("%s must be at least %g.", label, d.Min.Float64))
+		}
+
+		if d.Max.Valid && n > d.Max.Float64 {
+			return d.errorMessage(fmt.Sprintf("%s must be at most %g.", label, d.Max.Float64))
+		}
+	}
+
+	return ""
+}
+
+func (d *ModalComponentValidationData) errorMessage(fallback string) string {
+	if d.ErrorMessage != "" {
+		return d.ErrorMessage
+	}
+	return fallback
+}
@@ -0,0 +1,98 @@
+package flow
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/kitecloud/kite/kite-service/pkg/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+var testModal = ModalData{
+	Title: "Application",
+	Components: []ModalComponentData{
+		{Components: []ModalComponentData{{
+			CustomID:   "age",
+			Label:      "Age",
+			Validation: &ModalComponentValidationData{Min: null.FloatFrom(13), Max: null.FloatFrom(120)},
+		}}},
+		{Components: []ModalComponentData{{
+			CustomID:   "username",
+			Label:      "Username",
+			Validation: &ModalComponentValidationData{Pattern: `^[a-z]+$`, ErrorMessage: "Invalid username!"},
+		}}},
+		{Components: []ModalComponentData{{
+			Type:     message.ComponentTypeStringSelect,
+			CustomID: "team",
+			Label:    "Team",
+			Options:  []ModalSelectOptionData{{Label: "Red", Value: "red"}},
+		}}},
+	},
+}
+
+func testModalSubmit(t *testing.T, age string, username string) *discord.ModalInteraction {
+	raw, err := json.Marshal(map[string]any{
+		"custom_id": "resume:abc",
+		"components": []any{
+			map[string]any{"type": 18, "component": map[string]any{"type": 4, "custom_id": "age", "value": age}},
+			map[string]any{"type": 18, "component": map[string]any{"type": 4, "custom_id": "username", "value": username}},
+			map[string]any{"type": 18, "component": map[string]any{"type": 3, "custom_id": "team", "values": []string{"red"}}},
+		},
+	})
+	require.NoError(t, err)
+
+	data, err := message.ParseModalInteraction(raw)
+	require.NoError(t, err)
+	return data
+}
+
+func TestModalToComponents(t *testing.T) {
+	raw, err := json.Marshal(testModal.ToComponents())
+	require.NoError(t, err)
+
+	var components []map[string]any
+	require.NoError(t, json.Unmarshal(raw, &components))
+	require.Len(t, components, 3)
+
+	for _, c := range components {
+		assert.EqualValues(t, message.ComponentTypeLabel, c["type"])
+	}
+
+	assert.EqualValues(t, message.ComponentTypeTextInput, components[0]["component"].(map[string]any)["type"])
+
+	team := components[2]["component"].(map[string]any)
+	assert.EqualValues(t, message.ComponentTypeStringSelect, team["type"])
+	assert.Len(t, team["options"], 1)
+}
+
+func TestModalValidateSubmit(t *testing.T) {
+	assert.Empty(t, testModal.ValidateSubmit(testModalSubmit(t, "20", "kite")))
+	assert.Equal(t, "Age must be a number.", testModal.ValidateSubmit(testModalSubmit(t, "twenty", "kite")))
+	assert.Equal(t, "Age must be at least 13.", testModal.ValidateSubmit(testModalSubmit(t, "5", "kite")))
+	assert.Equal(t, "Invalid username!", testModal.ValidateSubmit(testModalSubmit(t, "20", "Kite!")))
+
+	assert.NoError(t, testModal.Validate())
+
+	invalid := ModalData{Components: []ModalComponentData{{Components: []ModalComponentData{{
+		Validation: &ModalComponentValidationData{Pattern: "("},
+	}}}}}
+	assert.Error(t, invalid.Validate())
+}
+
+func TestModalValidateComponentCount(t *testing.T) {
+	row := ModalComponentData{Components: []ModalComponentData{
+		{CustomID: "a", Label: "A"},
+		{CustomID: "b", Label: "B"},
+		{CustomID: "c", Label: "C"},
+	}}
+
+	valid := ModalData{Components: []ModalComponentData{row}}
+	assert.NoError(t, valid.Validate())
+
+	// Each row is within the limit, but the modal has 6 inputs in total
+	invalid := ModalData{Components: []ModalComponentData{row, row}}
+	assert.Error(t, invalid.Validate())
+}
@@ -0,0 +1,55 @@
+package flow
+
+import (
+	"encoding/json"
+
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"github.com/kitecloud/kite/kite-service/pkg/wasm"
+)
+
+// ModuleCallCreditsCost is the base cost of calling a module function, host calls of the module are charged separately.
+const ModuleCallCreditsCost = 5
+
+// ModuleHost returns the host for WebAssembly modules that are called by the flow.
+// Host calls of the module count towards the limits of the flow like the equivalent nodes.
+func (c *FlowContext) ModuleHost() *wasm.Host {
+	return &wasm.Host{
+		Discord:  c.Discord,
+		HTTP:     c.HTTP,
+		Log:      c.Log,
+		Variable: c.Variable,
+		StartOperation: func(credits int) error {
+			if err := c.startOperation(credits); err != nil {
+				return err
+			}
+			c.endOperation()
+			return nil
+		},
+	}
+}
+
+func moduleInput(input string) []byte {
+	if input == "" {
+		return []byte("null")
+	}
+
+	if json.Valid([]byte(input)) {
+		return []byte(input)
+	}
+
+	raw, _ := json.Marshal(input)
+	return raw
+}
+
+func moduleOutput(output []byte) thing.Thing {
+	if len(output) == 0 {
+		return thing.Null
+	}
+
+	var v any
+	if err := json.Unmarshal(output, &v); err != nil {
+		return thing.NewString(string(output))
+	}
+
+	return thing.NewGuessTypeRecursive(v)
+}
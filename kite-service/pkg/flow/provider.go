@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/kitecloud/kite/kite-service/pkg/wasm"
 )
 
 type FlowProviders struct {
@@ -14,13 +15,34 @@ type FlowProviders struct {
 	Log             provider.LogProvider
 	Variable        provider.VariableProvider
 	MessageTemplate provider.MessageTemplateProvider
+	Asset           provider.AssetProvider
+	Module          ModuleProvider
 	ResumePoint     ResumePointProvider
+	NodeObserver    NodeObserver
+}
+
+// NodeObserver is notified about every node that is executed, it can be nil.
+// The returned function is called with the result once the node is done.
+type NodeObserver interface {
+	ObserveNode(ctx *FlowContext, node *CompiledFlowNode) func(err error)
 }
 
 type ResumePointProvider interface {
 	CreateResumePoint(ctx context.Context, p ResumePoint) (ResumePoint, error)
 }
 
+// ModuleProvider provides access to the WebAssembly modules of the app.
+type ModuleProvider interface {
+	// CallModuleFunction calls an exported function of the module with a JSON input and returns its JSON output.
+	CallModuleFunction(ctx context.Context, moduleID string, function string, input []byte, host *wasm.Host) ([]byte, error)
+}
+
+type MockModuleProvider struct{}
+
+func (m *MockModuleProvider) CallModuleFunction(ctx context.Context, moduleID string, function string, input []byte, host *wasm.Host) ([]byte, error) {
+	return nil, nil
+}
+
 type MockResumePointProvider struct{}
 
 func (m *MockResumePointProvider) CreateResumePoint(ctx context.Context, p ResumePoint) (ResumePoint, error) {
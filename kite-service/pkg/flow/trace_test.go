@@ -0,0 +1,75 @@
+package flow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/pkg/eval"
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestFlowExecuteTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(tp)
+	defer tp.Shutdown(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ctx, root := tp.Tracer("test").Start(ctx, "root")
+
+	discordProvider := &TestDiscordProvider{}
+
+	c := NewContext(
+		ctx,
+		5*time.Second,
+		&TestContextData{},
+		FlowProviders{
+			Discord: discordProvider,
+			Log:     &provider.MockLogProvider{},
+		}, FlowContextLimits{
+			MaxStackDepth: 10,
+			MaxOperations: 1000,
+			MaxCredits:    1000,
+		},
+		eval.NewContext(eval.Env{}),
+		nil,
+	)
+	defer c.Cancel()
+
+	err := flowCommandTest.Execute(c)
+	require.NoError(t, err)
+	root.End()
+
+	spans := exporter.GetSpans().Snapshots()
+	spansByNodeID := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range spans {
+		for _, attr := range span.Attributes() {
+			if attr.Key == "node_id" {
+				spansByNodeID[attr.Value.AsString()] = span
+			}
+		}
+	}
+
+	require.Len(t, spansByNodeID, 4)
+	require.Equal(t, "flow.node."+string(FlowNodeTypeEntryCommand), spansByNodeID["0"].Name())
+
+	// Every node span is a child of the span of its parent node
+	require.Equal(t, root.SpanContext().SpanID(), spansByNodeID["0"].Parent().SpanID())
+	require.Equal(t, spansByNodeID["0"].SpanContext().SpanID(), spansByNodeID["1"].Parent().SpanID())
+	require.Equal(t, spansByNodeID["1"].SpanContext().SpanID(), spansByNodeID["2"].Parent().SpanID())
+	require.Equal(t, spansByNodeID["2"].SpanContext().SpanID(), spansByNodeID["3"].Parent().SpanID())
+
+	// Provider calls receive the span of the node that made them
+	require.Equal(t, spansByNodeID["3"].SpanContext().SpanID(), discordProvider.responseSpanContext.SpanID())
+
+	// The flow context is restored once the nodes are done
+	require.Equal(t, root.SpanContext().SpanID(), trace.SpanContextFromContext(c).SpanID())
+}
@@ -1,6 +1,8 @@
 package message
 
 import (
+	"encoding/json"
+
 	"github.com/diamondburned/arikawa/v3/api"
 	"github.com/diamondburned/arikawa/v3/discord"
 	"github.com/diamondburned/arikawa/v3/utils/json/option"
@@ -15,6 +17,14 @@ func (m *MessageData) ToSendMessageData(opts ConvertOptions) api.SendMessageData
 		return api.SendMessageData{}
 	}
 
+	if m.IsComponentsV2() {
+		return api.SendMessageData{
+			Flags:           discord.MessageFlags(m.Flags | MessageFlagIsComponentsV2),
+			Components:      m.toLayoutComponents(opts),
+			AllowedMentions: m.AllowedMentions.ToAllowedMentions(),
+		}
+	}
+
 	embeds := make([]discord.Embed, len(m.Embeds))
 	for i, embed := range m.Embeds {
 		embeds[i] = embed.ToEmbed()
@@ -39,6 +49,19 @@ func (m *MessageData) ToEditMessageData(opts ConvertOptions) api.EditMessageData
 		return api.EditMessageData{}
 	}
 
+	if m.IsComponentsV2() {
+		// Content and embeds must be removed when an existing message is changed to layout components
+		flags := discord.MessageFlags(m.Flags | MessageFlagIsComponentsV2)
+		components := m.toLayoutComponents(opts)
+		return api.EditMessageData{
+			Content:         option.NullString,
+			Flags:           &flags,
+			Embeds:          &[]discord.Embed{},
+			Components:      &components,
+			AllowedMentions: m.AllowedMentions.ToAllowedMentions(),
+		}
+	}
+
 	embeds := make([]discord.Embed, len(m.Embeds))
 	for i, embed := range m.Embeds {
 		embeds[i] = embed.ToEmbed()
@@ -69,6 +92,15 @@ func (m *MessageData) ToInteractionResponseData(opts ConvertOptions) api.Interac
 		return api.InteractionResponseData{}
 	}
 
+	if m.IsComponentsV2() {
+		components := m.toLayoutComponents(opts)
+		return api.InteractionResponseData{
+			Flags:           discord.MessageFlags(m.Flags | MessageFlagIsComponentsV2),
+			Components:      &components,
+			AllowedMentions: m.AllowedMentions.ToAllowedMentions(),
+		}
+	}
+
 	embeds := make([]discord.Embed, len(m.Embeds))
 	for i, embed := range m.Embeds {
 		embeds[i] = embed.ToEmbed()
@@ -265,3 +297,125 @@ func (a *AllowedMentionsData) ToAllowedMentions() *api.AllowedMentions {
 }
 
 type componentIDFactory func(component *ComponentData) discord.ComponentID
+
+func (m *MessageData) toLayoutComponents(opts ConvertOptions) discord.ContainerComponents {
+	components := make(discord.ContainerComponents, 0, len(m.LayoutComponents))
+	for _, component := range m.LayoutComponents {
+		if c := component.ToComponent(opts); c != nil {
+			components = append(components, c)
+		}
+	}
+	return components
+}
+
+func (c *LayoutComponentData) ToComponent(opts ConvertOptions) discord.ContainerComponent {
+	if c == nil {
+		return nil
+	}
+
+	switch c.Type {
+	case ComponentTypeActionRow:
+		row := ComponentRowData{Components: c.Components}
+		return row.ToComponent(opts)
+	case ComponentTypeSection:
+		children := make([]any, 0, len(c.Children))
+		for _, child := range c.Children {
+			if child.Type == ComponentTypeTextDisplay {
+				children = append(children, child.ToComponent(opts))
+			}
+		}
+
+		var accessory any
+		if c.Accessory != nil {
+			if c.Accessory.Button != nil {
+				accessory = c.Accessory.Button.ToComponent(opts)
+			} else if c.Accessory.Thumbnail != nil {
+				accessory = c.Accessory.Thumbnail.toJSON()
+			}
+		}
+
+		return NewRawComponent(ComponentTypeSection, map[string]any{
+			"components": children,
+			"accessory":  accessory,
+		})
+	case ComponentTypeTextDisplay:
+		return NewRawComponent(ComponentTypeTextDisplay, map[string]any{
+			"content": c.Content,
+		})
+	case ComponentTypeMediaGallery:
+		items := make([]any, len(c.Items))
+		for i, item := range c.Items {
+			items[i] = mediaJSON(item.URL, item.Description, item.Spoiler)
+		}
+
+		return NewRawComponent(ComponentTypeMediaGallery, map[string]any{
+			"items": items,
+		})
+	case ComponentTypeSeparator:
+		return NewRawComponent(ComponentTypeSeparator, map[string]any{
+			"divider": !c.HideDivider,
+			"spacing": max(c.Spacing, 1),
+		})
+	case ComponentTypeContainer:
+		children := make([]any, 0, len(c.Children))
+		for _, child := range c.Children {
+			if child := child.ToComponent(opts); child != nil {
+				children = append(children, child)
+			}
+		}
+
+		var accentColor any
+		if c.AccentColor != 0 {
+			accentColor = c.AccentColor
+		}
+
+		return NewRawComponent(ComponentTypeContainer, map[string]any{
+			"components":   children,
+			"accent_color": accentColor,
+			"spoiler":      c.Spoiler,
+		})
+	}
+
+	return nil
+}
+
+func (t *ThumbnailData) toJSON() map[string]any {
+	res := mediaJSON(t.URL, t.Description, t.Spoiler)
+	res["type"] = ComponentTypeThumbnail
+	return res
+}
+
+func mediaJSON(url string, description string, spoiler bool) map[string]any {
+	res := map[string]any{
+		"media":   map[string]any{"url": url},
+		"spoiler": spoiler,
+	}
+	if description != "" {
+		res["description"] = description
+	}
+	return res
+}
+
+// RawComponent is used for the components that arikawa doesn't support yet.
+// The action row is only embedded so the type satisfies discord.ContainerComponent,
+// the component is marshaled from data instead.
+type RawComponent struct {
+	discord.ActionRowComponent
+
+	typ  discord.ComponentType
+	data map[string]any
+}
+
+// NewRawComponent creates a component of the given type which is marshaled from data.
+func NewRawComponent(typ int, data map[string]any) *RawComponent {
+	data["type"] = typ
+	return &RawComponent{typ: discord.ComponentType(typ), data: data}
+}
+
+func (c *RawComponent) Type() discord.ComponentType {
+	return c.typ
+}
+
+func (c *RawComponent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.data)
+}
@@ -20,13 +20,22 @@ func (m *MessageData) Copy() MessageData {
 		attachments[i] = attachment.Copy()
 	}
 
+	var layoutComponents []LayoutComponentData
+	if m.LayoutComponents != nil {
+		layoutComponents = make([]LayoutComponentData, len(m.LayoutComponents))
+		for i, component := range m.LayoutComponents {
+			layoutComponents[i] = component.Copy()
+		}
+	}
+
 	return MessageData{
-		Content:         m.Content,
-		Flags:           m.Flags,
-		Embeds:          embeds,
-		Attachments:     attachments,
-		Components:      components,
-		AllowedMentions: m.AllowedMentions.Copy(),
+		Content:          m.Content,
+		Flags:            m.Flags,
+		Embeds:           embeds,
+		Attachments:      attachments,
+		Components:       components,
+		AllowedMentions:  m.AllowedMentions.Copy(),
+		LayoutComponents: layoutComponents,
 	}
 }
 
@@ -160,7 +169,9 @@ func (c ComponentSelectOptionData) Copy() ComponentSelectOptionData {
 
 func (c MessageAttachment) Copy() MessageAttachment {
 	return MessageAttachment{
-		AssetID: c.AssetID,
+		AssetID:  c.AssetID,
+		Filename: c.Filename,
+		Content:  c.Content,
 	}
 }
 
@@ -176,3 +187,59 @@ func (a *AllowedMentionsData) Copy() *AllowedMentionsData {
 		Parse: parse,
 	}
 }
+
+func (c LayoutComponentData) Copy() LayoutComponentData {
+	var components []ComponentData
+	if c.Components != nil {
+		components = make([]ComponentData, len(c.Components))
+		for i, component := range c.Components {
+			components[i] = component.Copy()
+		}
+	}
+
+	var children []LayoutComponentData
+	if c.Children != nil {
+		children = make([]LayoutComponentData, len(c.Children))
+		for i, child := range c.Children {
+			children[i] = child.Copy()
+		}
+	}
+
+	var items []MediaGalleryItemData
+	if c.Items != nil {
+		items = make([]MediaGalleryItemData, len(c.Items))
+		copy(items, c.Items)
+	}
+
+	return LayoutComponentData{
+		ID:          c.ID,
+		Type:        c.Type,
+		Components:  components,
+		Children:    children,
+		Accessory:   c.Accessory.Copy(),
+		Content:     c.Content,
+		Items:       items,
+		HideDivider: c.HideDivider,
+		Spacing:     c.Spacing,
+		AccentColor: c.AccentColor,
+		Spoiler:     c.Spoiler,
+	}
+}
+
+func (a *SectionAccessoryData) Copy() *SectionAccessoryData {
+	if a == nil {
+		return nil
+	}
+
+	res := &SectionAccessoryData{}
+	if a.Button != nil {
+		button := a.Button.Copy()
+		res.Button = &button
+	}
+	if a.Thumbnail != nil {
+		thumbnail := *a.Thumbnail
+		res.Thumbnail = &thumbnail
+	}
+
+	return res
+}
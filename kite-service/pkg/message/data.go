@@ -4,6 +4,28 @@ import (
 	"time"
 )
 
+const (
+	ComponentTypeActionRow         = 1
+	ComponentTypeButton            = 2
+	ComponentTypeStringSelect      = 3
+	ComponentTypeTextInput         = 4
+	ComponentTypeUserSelect        = 5
+	ComponentTypeRoleSelect        = 6
+	ComponentTypeMentionableSelect = 7
+	ComponentTypeChannelSelect     = 8
+	ComponentTypeSection           = 9
+	ComponentTypeTextDisplay       = 10
+	ComponentTypeThumbnail         = 11
+	ComponentTypeMediaGallery      = 12
+	ComponentTypeSeparator         = 14
+	ComponentTypeContainer         = 17
+	ComponentTypeLabel             = 18
+	ComponentTypeFileUpload        = 19
+)
+
+// MessageFlagIsComponentsV2 tells Discord that the message uses layout components instead of content and embeds.
+const MessageFlagIsComponentsV2 = 1 << 15
+
 type MessageData struct {
 	Content         string               `json:"content,omitempty"`
 	Flags           int                  `json:"flags,omitempty"`
@@ -11,6 +33,37 @@ type MessageData struct {
 	Embeds          []EmbedData          `json:"embeds,omitempty"`
 	Components      []ComponentRowData   `json:"components,omitempty"`
 	AllowedMentions *AllowedMentionsData `json:"allowed_mentions,omitempty"`
+
+	// LayoutComponents replace the content, embeds and components of the message when set.
+	LayoutComponents []LayoutComponentData `json:"layout_components,omitempty"`
+}
+
+// IsComponentsV2 returns true if the message is sent with layout components.
+func (m *MessageData) IsComponentsV2() bool {
+	return len(m.LayoutComponents) > 0 || m.Flags&MessageFlagIsComponentsV2 != 0
+}
+
+// EachComponent calls f for every interactive component of the message, including the ones nested in layout components.
+func (m *MessageData) EachComponent(f func(c *ComponentData)) {
+	for r := range m.Components {
+		row := &m.Components[r]
+		for c := range row.Components {
+			f(&row.Components[c])
+		}
+	}
+
+	for l := range m.LayoutComponents {
+		m.LayoutComponents[l].eachComponent(f)
+	}
+}
+
+// HasComponents returns true if the message has at least one interactive component.
+func (m *MessageData) HasComponents() bool {
+	res := false
+	m.EachComponent(func(c *ComponentData) {
+		res = true
+	})
+	return res
 }
 
 func (m *MessageData) EachString(replace func(s *string) error) error {
@@ -99,24 +152,45 @@ func (m *MessageData) EachString(replace func(s *string) error) error {
 	}
 
 	for c := range m.Components {
-		component := &m.Components[c]
+		row := &m.Components[c]
 
-		for _, option := range component.Components {
-			if err := replace(&option.Label); err != nil {
+		for i := range row.Components {
+			if err := row.Components[i].eachString(replace); err != nil {
 				return err
 			}
+		}
+	}
 
-			if err := replace(&option.Placeholder); err != nil {
-				return err
-			}
+	for l := range m.LayoutComponents {
+		if err := m.LayoutComponents[l].eachString(replace); err != nil {
+			return err
+		}
+	}
+
+	// The content of generated attachments isn't included because it doesn't have to evaluate to a string
+	for a := range m.Attachments {
+		if err := replace(&m.Attachments[a].Filename); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// MessageAttachment is either an uploaded asset or a file that is generated when the message is sent.
 type MessageAttachment struct {
 	AssetID string `json:"asset_id,omitempty"`
+
+	// Filename is the name of the generated file, attachments of Discord messages keep their name by default.
+	Filename string `json:"filename,omitempty"`
+	// Content is a template that evaluates to the content of the generated file.
+	// It can be text, an HTTP response or one or more attachments of a Discord message.
+	Content string `json:"content,omitempty"`
+}
+
+// IsGenerated returns true if the attachment is generated when the message is sent instead of referencing an asset.
+func (a *MessageAttachment) IsGenerated() bool {
+	return a.Content != ""
 }
 
 type EmbedData struct {
@@ -188,6 +262,18 @@ type ComponentData struct {
 	FlowSourceID string `json:"flow_source_id,omitempty"`
 }
 
+func (c *ComponentData) eachString(replace func(s *string) error) error {
+	if err := replace(&c.Label); err != nil {
+		return err
+	}
+
+	if err := replace(&c.Placeholder); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 type ComponentSelectOptionData struct {
 	ID int `json:"id,omitempty"`
 
@@ -208,3 +294,118 @@ type ComponentEmojiData struct {
 type AllowedMentionsData struct {
 	Parse []string `json:"parse,omitempty"`
 }
+
+// LayoutComponentData is one of the layout components that can be used instead of content and embeds.
+// Only the fields of the component type are used.
+type LayoutComponentData struct {
+	ID int `json:"id,omitempty"`
+
+	Type int `json:"type,omitempty"`
+
+	// Action Row
+	Components []ComponentData `json:"components,omitempty"`
+
+	// Section (text displays only) and Container
+	Children []LayoutComponentData `json:"children,omitempty"`
+
+	// Section
+	Accessory *SectionAccessoryData `json:"accessory,omitempty"`
+
+	// Text Display
+	Content string `json:"content,omitempty"`
+
+	// Media Gallery
+	Items []MediaGalleryItemData `json:"items,omitempty"`
+
+	// Separator
+	HideDivider bool `json:"hide_divider,omitempty"`
+	Spacing     int  `json:"spacing,omitempty"`
+
+	// Container
+	AccentColor int  `json:"accent_color,omitempty"`
+	Spoiler     bool `json:"spoiler,omitempty"`
+}
+
+func (c *LayoutComponentData) eachComponent(f func(c *ComponentData)) {
+	for i := range c.Components {
+		f(&c.Components[i])
+	}
+
+	if c.Accessory != nil && c.Accessory.Button != nil {
+		f(c.Accessory.Button)
+	}
+
+	for i := range c.Children {
+		c.Children[i].eachComponent(f)
+	}
+}
+
+func (c *LayoutComponentData) eachString(replace func(s *string) error) error {
+	if err := replace(&c.Content); err != nil {
+		return err
+	}
+
+	for i := range c.Components {
+		if err := c.Components[i].eachString(replace); err != nil {
+			return err
+		}
+	}
+
+	if c.Accessory != nil {
+		if c.Accessory.Button != nil {
+			if err := c.Accessory.Button.eachString(replace); err != nil {
+				return err
+			}
+		}
+
+		if c.Accessory.Thumbnail != nil {
+			if err := replace(&c.Accessory.Thumbnail.URL); err != nil {
+				return err
+			}
+
+			if err := replace(&c.Accessory.Thumbnail.Description); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := range c.Items {
+		item := &c.Items[i]
+
+		if err := replace(&item.URL); err != nil {
+			return err
+		}
+
+		if err := replace(&item.Description); err != nil {
+			return err
+		}
+	}
+
+	for i := range c.Children {
+		if err := c.Children[i].eachString(replace); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SectionAccessoryData is displayed next to the text of a section, either a button or a thumbnail.
+type SectionAccessoryData struct {
+	Button    *ComponentData `json:"button,omitempty"`
+	Thumbnail *ThumbnailData `json:"thumbnail,omitempty"`
+}
+
+type ThumbnailData struct {
+	URL         string `json:"url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Spoiler     bool   `json:"spoiler,omitempty"`
+}
+
+type MediaGalleryItemData struct {
+	ID int `json:"id,omitempty"`
+
+	URL         string `json:"url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Spoiler     bool   `json:"spoiler,omitempty"`
+}
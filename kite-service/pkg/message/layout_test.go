@@ -0,0 +1,125 @@
+package message
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLayoutMessage() MessageData {
+	return MessageData{
+		Content: "ignored",
+		LayoutComponents: []LayoutComponentData{
+			{
+				Type:        ComponentTypeContainer,
+				AccentColor: 0xff0000,
+				Children: []LayoutComponentData{
+					{
+						Type: ComponentTypeSection,
+						Children: []LayoutComponentData{
+							{Type: ComponentTypeTextDisplay, Content: "Hello {{user.name}}"},
+						},
+						Accessory: &SectionAccessoryData{
+							Button: &ComponentData{ID: 1, Type: ComponentTypeButton, Label: "Buy {{item}}", FlowSourceID: "buy"},
+						},
+					},
+					{Type: ComponentTypeSeparator},
+					{
+						Type:  ComponentTypeMediaGallery,
+						Items: []MediaGalleryItemData{{URL: "https://example.com/{{image}}.png"}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestLayoutComponentsToSendMessageData(t *testing.T) {
+	data := testLayoutMessage()
+
+	res := data.ToSendMessageData(ConvertOptions{})
+	assert.Empty(t, res.Content)
+	assert.NotZero(t, res.Flags&MessageFlagIsComponentsV2)
+
+	raw, err := json.Marshal(res.Components)
+	require.NoError(t, err)
+
+	var components []map[string]any
+	require.NoError(t, json.Unmarshal(raw, &components))
+	require.Len(t, components, 1)
+
+	container := components[0]
+	assert.EqualValues(t, ComponentTypeContainer, container["type"])
+	assert.EqualValues(t, 0xff0000, container["accent_color"])
+
+	children := container["components"].([]any)
+	require.Len(t, children, 3)
+
+	section := children[0].(map[string]any)
+	assert.EqualValues(t, ComponentTypeSection, section["type"])
+	accessory := section["accessory"].(map[string]any)
+	assert.EqualValues(t, discord.ButtonComponentType, accessory["type"])
+	assert.Equal(t, "buy", accessory["custom_id"])
+
+	separator := children[1].(map[string]any)
+	assert.Equal(t, true, separator["divider"])
+
+	gallery := children[2].(map[string]any)
+	items := gallery["items"].([]any)
+	assert.Equal(t, "https://example.com/{{image}}.png", items[0].(map[string]any)["media"].(map[string]any)["url"])
+}
+
+func TestLayoutComponentsEachStringAndCopy(t *testing.T) {
+	data := testLayoutMessage()
+	copied := data.Copy()
+
+	err := copied.EachString(func(s *string) error {
+		*s = strings.NewReplacer("{{user.name}}", "Kite", "{{item}}", "Sword", "{{image}}", "sword").Replace(*s)
+		return nil
+	})
+	require.NoError(t, err)
+
+	container := copied.LayoutComponents[0]
+	assert.Equal(t, "Hello Kite", container.Children[0].Children[0].Content)
+	assert.Equal(t, "Buy Sword", container.Children[0].Accessory.Button.Label)
+	assert.Equal(t, "https://example.com/sword.png", container.Children[2].Items[0].URL)
+
+	// The original must not be changed
+	original := data.LayoutComponents[0]
+	assert.Equal(t, "Hello {{user.name}}", original.Children[0].Children[0].Content)
+	assert.Equal(t, "Buy {{item}}", original.Children[0].Accessory.Button.Label)
+
+	assert.True(t, copied.HasComponents())
+}
+
+func TestLayoutComponentsValidate(t *testing.T) {
+	data := testLayoutMessage()
+	assert.NoError(t, data.Validate())
+
+	nested := MessageData{LayoutComponents: []LayoutComponentData{
+		{Type: ComponentTypeContainer, Children: []LayoutComponentData{
+			{Type: ComponentTypeContainer, Children: []LayoutComponentData{{Type: ComponentTypeSeparator}}},
+		}},
+	}}
+	assert.Error(t, nested.Validate())
+
+	noAccessory := MessageData{LayoutComponents: []LayoutComponentData{
+		{Type: ComponentTypeSection, Children: []LayoutComponentData{{Type: ComponentTypeTextDisplay, Content: "a"}}},
+	}}
+	assert.Error(t, noAccessory.Validate())
+
+	tooMany := MessageData{}
+	for i := 0; i < maxLayoutComponents+1; i++ {
+		tooMany.LayoutComponents = append(tooMany.LayoutComponents, LayoutComponentData{Type: ComponentTypeSeparator})
+	}
+	assert.Error(t, tooMany.Validate())
+
+	tooLong := MessageData{LayoutComponents: []LayoutComponentData{
+		{Type: ComponentTypeTextDisplay, Content: strings.Repeat("a", maxLayoutTextLength+1)},
+	}}
+	assert.Error(t, tooLong.Validate())
+}
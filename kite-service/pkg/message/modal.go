@@ -0,0 +1,121 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+)
+
+// ModalSubmitComponent is a submitted select menu or file upload of a modal.
+// arikawa only knows about text inputs in modals, so these are parsed by ParseModalInteraction.
+type ModalSubmitComponent struct {
+	discord.UnknownComponent
+
+	ComponentType discord.ComponentType
+	CustomID      discord.ComponentID
+	// Values contains the selected values of a select menu or the attachment IDs of a file upload.
+	Values []string
+	// Attachments contains the resolved attachments of a file upload.
+	Attachments []discord.Attachment
+}
+
+func (c *ModalSubmitComponent) ID() discord.ComponentID {
+	return c.CustomID
+}
+
+func (c *ModalSubmitComponent) Type() discord.ComponentType {
+	return c.ComponentType
+}
+
+func (c *ModalSubmitComponent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"type":      c.ComponentType,
+		"custom_id": c.CustomID,
+		"values":    c.Values,
+	})
+}
+
+type modalSubmitComponentData struct {
+	Type       int                        `json:"type"`
+	CustomID   discord.ComponentID        `json:"custom_id"`
+	Value      string                     `json:"value"`
+	Values     []string                   `json:"values"`
+	Components []modalSubmitComponentData `json:"components"`
+	Component  *modalSubmitComponentData  `json:"component"`
+}
+
+// ParseModalInteraction parses the data of a modal submit interaction.
+// The components of labels are put into action rows, so all submitted components
+// can be read the same way as the text inputs of modals without labels.
+func ParseModalInteraction(b []byte) (*discord.ModalInteraction, error) {
+	var data struct {
+		CustomID   discord.ComponentID        `json:"custom_id"`
+		Components []modalSubmitComponentData `json:"components"`
+		Resolved   struct {
+			Attachments map[discord.AttachmentID]discord.Attachment `json:"attachments"`
+		} `json:"resolved"`
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal modal interaction: %w", err)
+	}
+
+	res := &discord.ModalInteraction{
+		CustomID:   data.CustomID,
+		Components: make(discord.ContainerComponents, 0, len(data.Components)),
+	}
+
+	for _, component := range data.Components {
+		var children []modalSubmitComponentData
+		switch component.Type {
+		case ComponentTypeActionRow:
+			children = component.Components
+		case ComponentTypeLabel:
+			if component.Component != nil {
+				children = []modalSubmitComponentData{*component.Component}
+			}
+		default:
+			// Text displays don't have a value
+			continue
+		}
+
+		row := make(discord.ActionRowComponent, 0, len(children))
+		for _, child := range children {
+			row = append(row, child.toComponent(data.Resolved.Attachments))
+		}
+		res.Components = append(res.Components, &row)
+	}
+
+	return res, nil
+}
+
+func (c modalSubmitComponentData) toComponent(attachments map[discord.AttachmentID]discord.Attachment) discord.InteractiveComponent {
+	if c.Type == ComponentTypeTextInput {
+		return &discord.TextInputComponent{
+			CustomID: c.CustomID,
+			Value:    c.Value,
+		}
+	}
+
+	res := &ModalSubmitComponent{
+		ComponentType: discord.ComponentType(c.Type),
+		CustomID:      c.CustomID,
+		Values:        c.Values,
+	}
+
+	if c.Type == ComponentTypeFileUpload {
+		res.Attachments = make([]discord.Attachment, 0, len(c.Values))
+		for _, value := range c.Values {
+			id, err := discord.ParseSnowflake(value)
+			if err != nil {
+				continue
+			}
+
+			if attachment, ok := attachments[discord.AttachmentID(id)]; ok {
+				res.Attachments = append(res.Attachments, attachment)
+			}
+		}
+	}
+
+	return res
+}
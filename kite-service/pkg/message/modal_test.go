@@ -0,0 +1,46 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testModalSubmit = `{
+	"custom_id": "resume:abc",
+	"components": [
+		{"type": 1, "components": [{"type": 4, "custom_id": "name", "value": "Kite"}]},
+		{"type": 18, "id": 2, "component": {"type": 3, "custom_id": "roles", "values": ["a", "b"]}},
+		{"type": 10, "id": 3},
+		{"type": 18, "id": 4, "component": {"type": 19, "custom_id": "files", "values": ["123"]}}
+	],
+	"resolved": {
+		"attachments": {
+			"123": {"id": "123", "filename": "cv.pdf", "url": "https://cdn.example.com/cv.pdf"}
+		}
+	}
+}`
+
+func TestParseModalInteraction(t *testing.T) {
+	data, err := ParseModalInteraction([]byte(testModalSubmit))
+	require.NoError(t, err)
+
+	assert.Equal(t, discord.ComponentID("resume:abc"), data.CustomID)
+	require.Len(t, data.Components, 3)
+
+	name, ok := data.Components.Find("name").(*discord.TextInputComponent)
+	require.True(t, ok)
+	assert.Equal(t, "Kite", name.Value)
+
+	roles, ok := data.Components.Find("roles").(*ModalSubmitComponent)
+	require.True(t, ok)
+	assert.EqualValues(t, ComponentTypeStringSelect, roles.Type())
+	assert.Equal(t, []string{"a", "b"}, roles.Values)
+
+	files, ok := data.Components.Find("files").(*ModalSubmitComponent)
+	require.True(t, ok)
+	require.Len(t, files.Attachments, 1)
+	assert.Equal(t, "cv.pdf", files.Attachments[0].Filename)
+}
@@ -0,0 +1,149 @@
+package message
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	maxLayoutComponents       = 40
+	maxLayoutTextLength       = 4000
+	maxSectionTextDisplays    = 3
+	maxMediaGalleryItems      = 10
+	maxActionRowComponents    = 5
+	maxSeparatorSpacing       = 2
+	maxMediaDescriptionLength = 1024
+	maxAttachments            = 10
+)
+
+// Validate checks that the attachments and layout components of the message are within the limits of Discord.
+func (m MessageData) Validate() error {
+	if len(m.Attachments) > maxAttachments {
+		return fmt.Errorf("message can't have more than %d attachments, has %d", maxAttachments, len(m.Attachments))
+	}
+
+	for _, attachment := range m.Attachments {
+		if err := attachment.validate(); err != nil {
+			return err
+		}
+	}
+
+	if len(m.LayoutComponents) == 0 {
+		return nil
+	}
+
+	v := layoutValidator{}
+	for _, component := range m.LayoutComponents {
+		if err := v.validate(component, false); err != nil {
+			return err
+		}
+	}
+
+	if v.components > maxLayoutComponents {
+		return fmt.Errorf("message can't have more than %d components, has %d", maxLayoutComponents, v.components)
+	}
+
+	if v.textLength > maxLayoutTextLength {
+		return fmt.Errorf("text of message can't be longer than %d characters, is %d", maxLayoutTextLength, v.textLength)
+	}
+
+	return nil
+}
+
+func (a MessageAttachment) validate() error {
+	if a.IsGenerated() {
+		if a.AssetID != "" {
+			return errors.New("attachment can't have both an asset and generated content")
+		}
+	} else if a.AssetID == "" {
+		return errors.New("attachment must have either an asset or generated content")
+	}
+
+	return nil
+}
+
+type layoutValidator struct {
+	components int
+	textLength int
+}
+
+func (v *layoutValidator) validate(c LayoutComponentData, inContainer bool) error {
+	v.components++
+
+	switch c.Type {
+	case ComponentTypeActionRow:
+		if len(c.Components) == 0 || len(c.Components) > maxActionRowComponents {
+			return fmt.Errorf("action row must have between 1 and %d components", maxActionRowComponents)
+		}
+		v.components += len(c.Components)
+	case ComponentTypeSection:
+		if len(c.Children) == 0 || len(c.Children) > maxSectionTextDisplays {
+			return fmt.Errorf("section must have between 1 and %d text displays", maxSectionTextDisplays)
+		}
+		for _, child := range c.Children {
+			if child.Type != ComponentTypeTextDisplay {
+				return errors.New("section can only contain text displays")
+			}
+			if err := v.validate(child, inContainer); err != nil {
+				return err
+			}
+		}
+
+		if c.Accessory == nil || (c.Accessory.Button == nil) == (c.Accessory.Thumbnail == nil) {
+			return errors.New("section must have either a button or a thumbnail as accessory")
+		}
+		if c.Accessory.Thumbnail != nil {
+			if err := validateMedia(c.Accessory.Thumbnail.URL, c.Accessory.Thumbnail.Description); err != nil {
+				return fmt.Errorf("section thumbnail: %w", err)
+			}
+		}
+		v.components++
+	case ComponentTypeTextDisplay:
+		if c.Content == "" {
+			return errors.New("text display must have content")
+		}
+		v.textLength += utf8.RuneCountInString(c.Content)
+	case ComponentTypeMediaGallery:
+		if len(c.Items) == 0 || len(c.Items) > maxMediaGalleryItems {
+			return fmt.Errorf("media gallery must have between 1 and %d items", maxMediaGalleryItems)
+		}
+		for _, item := range c.Items {
+			if err := validateMedia(item.URL, item.Description); err != nil {
+				return fmt.Errorf("media gallery item: %w", err)
+			}
+		}
+	case ComponentTypeSeparator:
+		if c.Spacing < 0 || c.Spacing > maxSeparatorSpacing {
+			return fmt.Errorf("separator spacing must be between 0 and %d", maxSeparatorSpacing)
+		}
+	case ComponentTypeContainer:
+		if inContainer {
+			return errors.New("containers can't be nested")
+		}
+		if len(c.Children) == 0 {
+			return errors.New("container must have at least one component")
+		}
+		for _, child := range c.Children {
+			if err := v.validate(child, true); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported layout component type %d", c.Type)
+	}
+
+	return nil
+}
+
+func validateMedia(url string, description string) error {
+	if url == "" {
+		return errors.New("url is required")
+	}
+
+	if utf8.RuneCountInString(description) > maxMediaDescriptionLength {
+		return fmt.Errorf("description can't be longer than %d characters", maxMediaDescriptionLength)
+	}
+
+	return nil
+}
@@ -2,8 +2,27 @@ package plugin
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/diamondburned/arikawa/v3/discord"
 )
 
+// ConfigKeyGuilds is the key of the per-guild overrides in the config values.
+// The overrides are a map from guild ID to config values and can only contain fields of per-guild sections.
+const ConfigKeyGuilds = "guilds"
+
+const maxEmojiLength = 64
+
+var customEmojiRegex = regexp.MustCompile(`^<(a?):(\w{2,32}):(\d+)>$`)
+
+var errInvalidEmoji = errors.New("invalid emoji")
+
 type ConfigValues map[string]json.RawMessage
 
 func NewConfigValues() ConfigValues {
@@ -11,43 +30,108 @@ func NewConfigValues() ConfigValues {
 }
 
 func (c ConfigValues) GetString(key string) string {
-	return UnmarshalConfigValue[string](c[key])
+	return getConfigValue[string](c, key)
 }
 
 func (c ConfigValues) GetInt(key string) int {
-	return UnmarshalConfigValue[int](c[key])
+	return getConfigValue[int](c, key)
 }
 
 func (c ConfigValues) GetBool(key string) bool {
-	return UnmarshalConfigValue[bool](c[key])
+	return getConfigValue[bool](c, key)
 }
 
 func (c ConfigValues) GetFloat(key string) float64 {
-	return UnmarshalConfigValue[float64](c[key])
+	return getConfigValue[float64](c, key)
 }
 
 func (c ConfigValues) GetStringArray(key string) []string {
-	return UnmarshalConfigValue[[]string](c[key])
+	return getConfigValue[[]string](c, key)
 }
 
 func (c ConfigValues) GetIntArray(key string) []int {
-	return UnmarshalConfigValue[[]int](c[key])
+	return getConfigValue[[]int](c, key)
 }
 
 func (c ConfigValues) GetFloatArray(key string) []float64 {
-	return UnmarshalConfigValue[[]float64](c[key])
+	return getConfigValue[[]float64](c, key)
 }
 
 func (c ConfigValues) GetBoolArray(key string) []bool {
-	return UnmarshalConfigValue[[]bool](c[key])
+	return getConfigValue[[]bool](c, key)
 }
 
-func UnmarshalConfigValue[T any](v json.RawMessage) T {
-	var t T
-	_ = json.Unmarshal(v, &t)
+func (c ConfigValues) GetChannelID(key string) discord.ChannelID {
+	return discord.ChannelID(c.getSnowflake(key))
+}
+
+func (c ConfigValues) GetRoleID(key string) discord.RoleID {
+	return discord.RoleID(c.getSnowflake(key))
+}
+
+// GetEmoji returns the emoji of a discord_emoji field or nil if it isn't set or invalid.
+func (c ConfigValues) GetEmoji(key string) *discord.Emoji {
+	emoji, _ := ParseEmoji(c.GetString(key))
+	return emoji
+}
+
+func (c ConfigValues) getSnowflake(key string) discord.Snowflake {
+	id, err := discord.ParseSnowflake(c.GetString(key))
+	if err != nil {
+		return discord.NullSnowflake
+	}
+	return id
+}
+
+// ForGuild returns the config values with the overrides of the guild applied.
+func (c ConfigValues) ForGuild(guildID discord.GuildID) ConfigValues {
+	overrides := c.guildOverrides()[guildID.String()]
+
+	res := make(ConfigValues, len(c)+len(overrides))
+	maps.Copy(res, c)
+	delete(res, ConfigKeyGuilds)
+	for key, value := range overrides {
+		if !isNullValue(value) {
+			res[key] = value
+		}
+	}
+
+	return res
+}
+
+func (c ConfigValues) guildOverrides() map[string]ConfigValues {
+	return getConfigValue[map[string]ConfigValues](c, ConfigKeyGuilds)
+}
+
+// getConfigValue returns the value of the key or the zero value if it's missing or invalid.
+// Values are validated when the config is saved, so invalid values can only come from configs
+// that were saved for an older version of the plugin.
+func getConfigValue[T any](c ConfigValues, key string) T {
+	t, err := UnmarshalConfigValue[T](c[key])
+	if err != nil {
+		slog.Warn(
+			"Invalid plugin config value",
+			slog.String("key", key),
+			slog.String("error", err.Error()),
+		)
+	}
 	return t
 }
 
+// UnmarshalConfigValue decodes a config value. Missing and null values decode to the zero value of T.
+func UnmarshalConfigValue[T any](v json.RawMessage) (T, error) {
+	var t T
+	if isNullValue(v) {
+		return t, nil
+	}
+
+	if err := json.Unmarshal(v, &t); err != nil {
+		var zero T
+		return zero, fmt.Errorf("failed to unmarshal config value: %w", err)
+	}
+	return t, nil
+}
+
 type Config struct {
 	Sections []ConfigSection `json:"sections"`
 }
@@ -56,6 +140,8 @@ type ConfigSection struct {
 	Name        string        `json:"name"`
 	Description string        `json:"description"`
 	Fields      []ConfigField `json:"fields"`
+	// PerGuild allows the fields of the section to be overridden for each guild.
+	PerGuild bool `json:"per_guild"`
 }
 
 type ConfigField struct {
@@ -65,6 +151,19 @@ type ConfigField struct {
 	Name        string          `json:"name"`
 	Description string          `json:"description"`
 	Required    bool            `json:"required"`
+	// Default is used when no value has been configured for the field.
+	Default any `json:"default,omitempty"`
+	// Min is the minimum value for numbers and the minimum length for strings and arrays.
+	Min *float64 `json:"min,omitempty"`
+	// Max is the maximum value for numbers and the maximum length for strings and arrays.
+	Max *float64 `json:"max,omitempty"`
+	// Options restricts the value, or the items of an array, to one of the given options.
+	Options []ConfigFieldOption `json:"options,omitempty"`
+}
+
+type ConfigFieldOption struct {
+	Label string `json:"label"`
+	Value any    `json:"value"`
 }
 
 type ConfigFieldType string
@@ -75,4 +174,133 @@ const (
 	ConfigFieldTypeBool   ConfigFieldType = "bool"
 	ConfigFieldTypeFloat  ConfigFieldType = "float"
 	ConfigFieldTypeArray  ConfigFieldType = "array"
+
+	// ConfigFieldTypeDiscordChannel is the ID of a channel as a string.
+	ConfigFieldTypeDiscordChannel ConfigFieldType = "discord_channel"
+	// ConfigFieldTypeDiscordRole is the ID of a role as a string.
+	ConfigFieldTypeDiscordRole ConfigFieldType = "discord_role"
+	// ConfigFieldTypeDiscordEmoji is a unicode emoji or a custom emoji in the format <:name:id>.
+	ConfigFieldTypeDiscordEmoji ConfigFieldType = "discord_emoji"
+	// ConfigFieldTypeMessageTemplate is the ID of a message template of the app.
+	ConfigFieldTypeMessageTemplate ConfigFieldType = "message_template"
 )
+
+// Fields returns all fields of all sections.
+func (c Config) Fields() []ConfigField {
+	var fields []ConfigField
+	for _, section := range c.Sections {
+		fields = append(fields, section.Fields...)
+	}
+	return fields
+}
+
+// WithDefaults returns a copy of the values where every missing field is set to its default value.
+func (c Config) WithDefaults(values ConfigValues) ConfigValues {
+	res := make(ConfigValues, len(values))
+	maps.Copy(res, values)
+
+	for _, field := range c.Fields() {
+		if field.Default == nil || !isNullValue(res[field.Key]) {
+			continue
+		}
+
+		raw, err := json.Marshal(field.Default)
+		if err != nil {
+			continue
+		}
+		res[field.Key] = raw
+	}
+
+	return res
+}
+
+// StringValuesOfType returns the values of all fields with the given type, including the items of arrays
+// and per-guild overrides. This is used to check that referenced resources exist.
+func (c Config) StringValuesOfType(values ConfigValues, t ConfigFieldType) []string {
+	var res []string
+
+	collect := func(values ConfigValues, field ConfigField) {
+		switch {
+		case field.Type == t:
+			if v := values.GetString(field.Key); v != "" {
+				res = append(res, v)
+			}
+		case field.Type == ConfigFieldTypeArray && field.ItemType == t:
+			for _, v := range values.GetStringArray(field.Key) {
+				if v != "" {
+					res = append(res, v)
+				}
+			}
+		}
+	}
+
+	for _, section := range c.Sections {
+		for _, field := range section.Fields {
+			collect(values, field)
+			if section.PerGuild {
+				for _, overrides := range values.guildOverrides() {
+					collect(overrides, field)
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+// ParseEmoji parses a unicode emoji or a custom emoji in the format <:name:id>.
+// It returns nil if the string is empty and an error if it isn't a valid emoji.
+func ParseEmoji(raw string) (*discord.Emoji, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	if matches := customEmojiRegex.FindStringSubmatch(raw); matches != nil {
+		id, err := discord.ParseSnowflake(matches[3])
+		if err != nil || !id.IsValid() {
+			return nil, errInvalidEmoji
+		}
+		return &discord.Emoji{
+			ID:       discord.EmojiID(id),
+			Name:     matches[2],
+			Animated: matches[1] == "a",
+		}, nil
+	}
+
+	if !isUnicodeEmoji(raw) {
+		return nil, errInvalidEmoji
+	}
+	return &discord.Emoji{Name: raw}, nil
+}
+
+// isUnicodeEmoji reports whether the string is a single unicode emoji, including modifiers and ZWJ sequences.
+// It doesn't check against the full emoji list, but rejects text that can't be part of an emoji.
+func isUnicodeEmoji(s string) bool {
+	if len(s) > maxEmojiLength {
+		return false
+	}
+
+	keycap := strings.HasSuffix(s, "\u20e3")
+	hasSymbol := false
+	for i, r := range s {
+		switch {
+		case unicode.Is(unicode.So, r) || unicode.Is(unicode.Sk, r):
+			// Pictographs, symbols and skin tone modifiers
+			hasSymbol = true
+		case r == '\u200d' || r == '\ufe0e' || r == '\ufe0f' || r == '\u20e3':
+			// Zero width joiners, variation selectors and the keycap combining mark
+		case r >= 0xe0020 && r <= 0xe007f:
+			// Tags of subdivision flags
+		case keycap && i == 0 && (r == '#' || r == '*' || (r >= '0' && r <= '9')):
+			hasSymbol = true
+		default:
+			return false
+		}
+	}
+
+	return hasSymbol
+}
+
+func isNullValue(v json.RawMessage) bool {
+	return len(v) == 0 || string(v) == "null"
+}
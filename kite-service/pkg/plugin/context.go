@@ -13,6 +13,7 @@ type Context interface {
 	provider.ValueProvider
 
 	Discord() provider.DiscordProvider
+	Asset() provider.AssetProvider
 }
 
 var ErrValueNotFound = errors.New("value not found")
@@ -0,0 +1,383 @@
+package leveling
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+	"github.com/kitecloud/kite/kite-service/pkg/plugin"
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+)
+
+const (
+	configKeyXPPerMessage         = "xp_per_message"
+	configKeyCooldownSeconds      = "cooldown_seconds"
+	configKeyLevelBaseXP          = "level_base_xp"
+	configKeyLevelExponent        = "level_exponent"
+	configKeyDisableAnnouncements = "disable_announcements"
+	configKeyLevelUpMessage       = "level_up_message"
+)
+
+const (
+	defaultXPPerMessage    = 15
+	defaultCooldownSeconds = 60
+	defaultLevelBaseXP     = 100
+	defaultLevelExponent   = 1.5
+	defaultLevelUpMessage  = "GG {user}, you just reached **level {level}**!"
+)
+
+const leaderboardPageSize = 10
+
+type LevelingPluginInstance struct {
+	appID  string
+	config plugin.ConfigValues
+}
+
+func (p *LevelingPluginInstance) Update(ctx context.Context, config plugin.ConfigValues) error {
+	p.config = config
+	return nil
+}
+
+func (p *LevelingPluginInstance) HandleEvent(c plugin.Context, event gateway.Event) error {
+	e, ok := event.(*gateway.MessageCreateEvent)
+	if !ok {
+		return nil
+	}
+
+	if !e.GuildID.IsValid() || e.Author.Bot || e.WebhookID.IsValid() {
+		return nil
+	}
+
+	if cooldown := time.Duration(p.cooldownSeconds()) * time.Second; cooldown > 0 {
+		// The cooldown value expires by itself, so it only exists while the user is on cooldown.
+		// Swapping it makes sure that concurrent messages can't award XP twice.
+		started, err := c.CompareAndSwapValue(
+			c,
+			levelCooldownKey(e.GuildID, e.Author.ID),
+			thing.Null,
+			thing.NewInt(time.Now().UTC().Unix()),
+			cooldown,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to start cooldown: %w", err)
+		}
+		if !started {
+			return nil
+		}
+	}
+
+	xpPerMessage := int64(p.xpPerMessage())
+	newXP, err := c.UpdateValue(c, levelXPKey(e.GuildID, e.Author.ID), provider.VariableOperationIncrement, thing.NewInt(xpPerMessage))
+	if err != nil {
+		return fmt.Errorf("failed to add xp: %w", err)
+	}
+
+	curve := p.curve()
+	oldLevel := curve.levelForXP(newXP.Int() - xpPerMessage)
+	newLevel := curve.levelForXP(newXP.Int())
+	if newLevel <= oldLevel {
+		return nil
+	}
+
+	config := p.config.ForGuild(e.GuildID)
+	if !config.GetBool(configKeyDisableAnnouncements) {
+		content := config.GetString(configKeyLevelUpMessage)
+		content = strings.ReplaceAll(content, "{user}", e.Author.Mention())
+		content = strings.ReplaceAll(content, "{level}", fmt.Sprintf("%d", newLevel))
+
+		_, err := c.Discord().CreateMessage(c, e.ChannelID, api.SendMessageData{
+			Content: content,
+			Reference: &discord.MessageReference{
+				MessageID: e.ID,
+			},
+			AllowedMentions: &api.AllowedMentions{
+				Users: []discord.UserID{e.Author.ID},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to announce level up: %w", err)
+		}
+	}
+
+	rewards, err := getLevelRewards(c, e.GuildID)
+	if err != nil {
+		return fmt.Errorf("failed to get level rewards: %w", err)
+	}
+
+	for _, reward := range rewards {
+		if reward.Level <= oldLevel || reward.Level > newLevel {
+			continue
+		}
+
+		err := c.Discord().AddMemberRole(
+			c,
+			e.GuildID,
+			e.Author.ID,
+			reward.RoleID,
+			api.AuditLogReason(fmt.Sprintf("Reached level %d", reward.Level)),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add reward role: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (p *LevelingPluginInstance) HandleCommand(c plugin.Context, event *gateway.InteractionCreateEvent) error {
+	data, ok := event.Data.(*discord.CommandInteraction)
+	if !ok || !event.GuildID.IsValid() {
+		return nil
+	}
+
+	switch data.Name {
+	case "rank":
+		userID := event.SenderID()
+		for _, opt := range data.Options {
+			if opt.Name == "user" {
+				_ = opt.Value.UnmarshalTo(&userID)
+			}
+		}
+		return p.handleRank(c, event, userID)
+	case "leaderboard":
+		page := 1
+		for _, opt := range data.Options {
+			if opt.Name == "page" {
+				_ = opt.Value.UnmarshalTo(&page)
+			}
+		}
+		return p.handleLeaderboard(c, event, max(page, 1))
+	case "leveling":
+		if len(data.Options) == 0 {
+			return nil
+		}
+		opt := data.Options[0]
+
+		switch opt.Name {
+		case "add-reward":
+			var level int
+			var roleID discord.RoleID
+			for _, subOpt := range opt.Options {
+				switch subOpt.Name {
+				case "level":
+					_ = subOpt.Value.UnmarshalTo(&level)
+				case "role":
+					_ = subOpt.Value.UnmarshalTo(&roleID)
+				}
+			}
+			return p.handleAddReward(c, event, level, roleID)
+		case "remove-reward":
+			var level int
+			for _, subOpt := range opt.Options {
+				if subOpt.Name == "level" {
+					_ = subOpt.Value.UnmarshalTo(&level)
+				}
+			}
+			return p.handleRemoveReward(c, event, level)
+		case "rewards":
+			return p.handleListRewards(c, event)
+		}
+	}
+
+	return nil
+}
+
+func (p *LevelingPluginInstance) HandleComponent(c plugin.Context, event *gateway.InteractionCreateEvent) error {
+	return nil
+}
+
+func (p *LevelingPluginInstance) HandleModal(c plugin.Context, event *gateway.InteractionCreateEvent) error {
+	return nil
+}
+
+func (p *LevelingPluginInstance) Close() error {
+	return nil
+}
+
+func (p *LevelingPluginInstance) handleRank(c plugin.Context, event *gateway.InteractionCreateEvent, userID discord.UserID) error {
+	xpValue, err := c.GetValue(c, levelXPKey(event.GuildID, userID))
+	if err != nil {
+		return fmt.Errorf("failed to get xp: %w", err)
+	}
+
+	if xpValue == thing.Null {
+		return respond(c, event, fmt.Sprintf("<@%d> hasn't earned any XP in this server yet.", userID))
+	}
+
+	xp := xpValue.Int()
+	above, err := c.CountValuesByPrefixAbove(c, levelXPKeyPrefix(event.GuildID), float64(xp))
+	if err != nil {
+		return fmt.Errorf("failed to get rank: %w", err)
+	}
+
+	curve := p.curve()
+	level := curve.levelForXP(xp)
+	levelXP := curve.xpForLevel(level)
+	nextLevelXP := curve.xpForLevel(level + 1)
+
+	return respondEmbed(c, event, discord.Embed{
+		Description: fmt.Sprintf("<@%d>", userID),
+		Color:       levelingColor,
+		Fields: []discord.EmbedField{
+			{Name: "Rank", Value: fmt.Sprintf("#%d", above+1), Inline: true},
+			{Name: "Level", Value: fmt.Sprintf("%d", level), Inline: true},
+			{Name: "XP", Value: fmt.Sprintf("%d", xp), Inline: true},
+			{
+				Name:  "Next Level",
+				Value: fmt.Sprintf("%d / %d XP", xp-levelXP, nextLevelXP-levelXP),
+			},
+		},
+	})
+}
+
+func (p *LevelingPluginInstance) handleLeaderboard(c plugin.Context, event *gateway.InteractionCreateEvent, page int) error {
+	prefix := levelXPKeyPrefix(event.GuildID)
+	offset := (page - 1) * leaderboardPageSize
+
+	entries, err := c.ListValuesByPrefix(c, prefix, leaderboardPageSize, offset)
+	if err != nil {
+		return fmt.Errorf("failed to get leaderboard: %w", err)
+	}
+
+	if len(entries) == 0 {
+		if page == 1 {
+			return respond(c, event, "Nobody has earned any XP in this server yet.")
+		}
+		return respond(c, event, fmt.Sprintf("There is nobody on page %d of the leaderboard.", page))
+	}
+
+	curve := p.curve()
+
+	var b strings.Builder
+	for i, entry := range entries {
+		userID := strings.TrimPrefix(entry.Key, prefix)
+		xp := entry.Value.Int()
+		fmt.Fprintf(&b, "**#%d** <@%s> • Level %d • %d XP\n", offset+i+1, userID, curve.levelForXP(xp), xp)
+	}
+
+	return respondEmbed(c, event, discord.Embed{
+		Title:       "Leaderboard",
+		Description: b.String(),
+		Color:       levelingColor,
+		Footer: &discord.EmbedFooter{
+			Text: fmt.Sprintf("Page %d", page),
+		},
+	})
+}
+
+func (p *LevelingPluginInstance) handleAddReward(c plugin.Context, event *gateway.InteractionCreateEvent, level int, roleID discord.RoleID) error {
+	rewards, err := getLevelRewards(c, event.GuildID)
+	if err != nil {
+		return fmt.Errorf("failed to get level rewards: %w", err)
+	}
+
+	replaced := false
+	for i := range rewards {
+		if rewards[i].Level == level {
+			rewards[i].RoleID = roleID
+			replaced = true
+		}
+	}
+	if !replaced {
+		rewards = append(rewards, LevelReward{Level: level, RoleID: roleID})
+	}
+
+	if err := setLevelRewards(c, event.GuildID, rewards); err != nil {
+		return fmt.Errorf("failed to save level rewards: %w", err)
+	}
+
+	return respond(c, event, fmt.Sprintf("Members will get <@&%d> when they reach level %d.", roleID, level))
+}
+
+func (p *LevelingPluginInstance) handleRemoveReward(c plugin.Context, event *gateway.InteractionCreateEvent, level int) error {
+	rewards, err := getLevelRewards(c, event.GuildID)
+	if err != nil {
+		return fmt.Errorf("failed to get level rewards: %w", err)
+	}
+
+	remaining := make([]LevelReward, 0, len(rewards))
+	for _, reward := range rewards {
+		if reward.Level != level {
+			remaining = append(remaining, reward)
+		}
+	}
+
+	if len(remaining) == len(rewards) {
+		return respond(c, event, fmt.Sprintf("There is no reward for level %d.", level))
+	}
+
+	if err := setLevelRewards(c, event.GuildID, remaining); err != nil {
+		return fmt.Errorf("failed to save level rewards: %w", err)
+	}
+
+	return respond(c, event, fmt.Sprintf("The reward for level %d has been removed.", level))
+}
+
+func (p *LevelingPluginInstance) handleListRewards(c plugin.Context, event *gateway.InteractionCreateEvent) error {
+	rewards, err := getLevelRewards(c, event.GuildID)
+	if err != nil {
+		return fmt.Errorf("failed to get level rewards: %w", err)
+	}
+
+	if len(rewards) == 0 {
+		return respond(c, event, "There are no role rewards yet, use `/leveling add-reward` to add one.")
+	}
+
+	var b strings.Builder
+	for _, reward := range rewards {
+		fmt.Fprintf(&b, "Level %d • <@&%d>\n", reward.Level, reward.RoleID)
+	}
+
+	return respond(c, event, b.String())
+}
+
+func (p *LevelingPluginInstance) xpPerMessage() int {
+	return p.config.GetInt(configKeyXPPerMessage)
+}
+
+func (p *LevelingPluginInstance) cooldownSeconds() int {
+	return p.config.GetInt(configKeyCooldownSeconds)
+}
+
+// curve returns the level curve of the config. The defaults are still used as a fallback
+// because an invalid curve would make levelForXP loop forever.
+func (p *LevelingPluginInstance) curve() levelCurve {
+	curve := levelCurve{
+		baseXP:   defaultLevelBaseXP,
+		exponent: defaultLevelExponent,
+	}
+	if v := p.config.GetInt(configKeyLevelBaseXP); v > 0 {
+		curve.baseXP = float64(v)
+	}
+	if v := p.config.GetFloat(configKeyLevelExponent); v > 0 {
+		curve.exponent = v
+	}
+	return curve
+}
+
+func respond(c plugin.Context, event *gateway.InteractionCreateEvent, content string) error {
+	_, err := c.Discord().CreateInteractionResponse(c, event.ID, event.Token, api.InteractionResponse{
+		Type: api.MessageInteractionWithSource,
+		Data: &api.InteractionResponseData{
+			Content: option.NewNullableString(content),
+			Flags:   discord.EphemeralMessage,
+		},
+	})
+	return err
+}
+
+func respondEmbed(c plugin.Context, event *gateway.InteractionCreateEvent, embed discord.Embed) error {
+	_, err := c.Discord().CreateInteractionResponse(c, event.ID, event.Token, api.InteractionResponse{
+		Type: api.MessageInteractionWithSource,
+		Data: &api.InteractionResponseData{
+			Embeds: &[]discord.Embed{embed},
+		},
+	})
+	return err
+}
@@ -0,0 +1,104 @@
+package leveling
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/kitecloud/kite/kite-service/pkg/plugin"
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+)
+
+const levelingColor = 0xf1c40f
+
+// levelCurve describes how much XP is required for each level.
+// The total XP that is required to reach a level is baseXP * level^exponent.
+type levelCurve struct {
+	baseXP   float64
+	exponent float64
+}
+
+// xpForLevel returns the total XP that is required to reach the given level.
+func (c levelCurve) xpForLevel(level int) int64 {
+	if level <= 0 {
+		return 0
+	}
+	return int64(math.Round(c.baseXP * math.Pow(float64(level), c.exponent)))
+}
+
+// levelForXP returns the highest level that has been reached with the given total XP.
+func (c levelCurve) levelForXP(xp int64) int {
+	if xp <= 0 {
+		return 0
+	}
+
+	// The estimate can be off by one because of rounding, so we correct it in both directions.
+	level := int(math.Pow(float64(xp)/c.baseXP, 1/c.exponent))
+	for level > 0 && c.xpForLevel(level) > xp {
+		level--
+	}
+	for c.xpForLevel(level+1) <= xp {
+		level++
+	}
+	return level
+}
+
+type LevelReward struct {
+	Level  int            `json:"level"`
+	RoleID discord.RoleID `json:"role_id"`
+}
+
+func getLevelRewards(c plugin.Context, guildID discord.GuildID) ([]LevelReward, error) {
+	raw, err := c.GetValue(c, levelRewardsKey(guildID))
+	if err != nil {
+		return nil, err
+	}
+
+	if raw == thing.Null {
+		return nil, nil
+	}
+
+	var rewards []LevelReward
+	if err := json.Unmarshal([]byte(raw.String()), &rewards); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal level rewards: %w", err)
+	}
+
+	return rewards, nil
+}
+
+func setLevelRewards(c plugin.Context, guildID discord.GuildID, rewards []LevelReward) error {
+	if len(rewards) == 0 {
+		return c.DeleteValue(c, levelRewardsKey(guildID))
+	}
+
+	sort.Slice(rewards, func(i, j int) bool {
+		return rewards[i].Level < rewards[j].Level
+	})
+
+	raw, err := json.Marshal(rewards)
+	if err != nil {
+		return fmt.Errorf("failed to marshal level rewards: %w", err)
+	}
+
+	_, err = c.UpdateValue(c, levelRewardsKey(guildID), provider.VariableOperationOverwrite, thing.NewString(string(raw)))
+	return err
+}
+
+func levelXPKeyPrefix(guildID discord.GuildID) string {
+	return "level-xp:" + guildID.String() + ":"
+}
+
+func levelXPKey(guildID discord.GuildID, userID discord.UserID) string {
+	return levelXPKeyPrefix(guildID) + userID.String()
+}
+
+func levelCooldownKey(guildID discord.GuildID, userID discord.UserID) string {
+	return "level-cooldown:" + guildID.String() + ":" + userID.String()
+}
+
+func levelRewardsKey(guildID discord.GuildID) string {
+	return "level-rewards:" + guildID.String()
+}
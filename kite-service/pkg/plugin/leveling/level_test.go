@@ -0,0 +1,182 @@
+package leveling
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/kitecloud/kite/kite-service/pkg/plugin"
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testGuildID   discord.GuildID   = 1
+	testChannelID discord.ChannelID = 2
+	testUserID    discord.UserID    = 3
+	testRoleID    discord.RoleID    = 4
+)
+
+func TestLevelCurve(t *testing.T) {
+	curve := levelCurve{baseXP: 100, exponent: 1.5}
+
+	assert.Equal(t, int64(0), curve.xpForLevel(0))
+	assert.Equal(t, int64(100), curve.xpForLevel(1))
+	assert.Equal(t, int64(283), curve.xpForLevel(2))
+	assert.Equal(t, int64(3162), curve.xpForLevel(10))
+
+	assert.Equal(t, 0, curve.levelForXP(-5))
+	assert.Equal(t, 0, curve.levelForXP(0))
+	assert.Equal(t, 0, curve.levelForXP(99))
+	assert.Equal(t, 1, curve.levelForXP(100))
+	assert.Equal(t, 1, curve.levelForXP(282))
+	assert.Equal(t, 2, curve.levelForXP(283))
+	assert.Equal(t, 9, curve.levelForXP(3161))
+	assert.Equal(t, 10, curve.levelForXP(3162))
+}
+
+func TestLevelCurveRoundTrip(t *testing.T) {
+	curves := []levelCurve{
+		{baseXP: 100, exponent: 1.5},
+		{baseXP: 50, exponent: 2},
+		{baseXP: 1, exponent: 1},
+		{baseXP: 7, exponent: 0.5},
+	}
+
+	// levelForXP must return the highest level whose required XP has been reached
+	for _, curve := range curves {
+		for xp := int64(0); xp <= 5000; xp++ {
+			level := curve.levelForXP(xp)
+			assert.LessOrEqual(t, curve.xpForLevel(level), xp, "curve %+v, xp %d", curve, xp)
+			assert.Greater(t, curve.xpForLevel(level+1), xp, "curve %+v, xp %d", curve, xp)
+		}
+	}
+}
+
+// testDiscordProvider records the requests of the plugin that matter for the tests.
+type testDiscordProvider struct {
+	provider.MockDiscordProvider
+
+	messages []api.SendMessageData
+	roles    []discord.RoleID
+}
+
+func (p *testDiscordProvider) CreateMessage(ctx context.Context, channelID discord.ChannelID, message api.SendMessageData) (*discord.Message, error) {
+	p.messages = append(p.messages, message)
+	return &discord.Message{}, nil
+}
+
+func (p *testDiscordProvider) AddMemberRole(ctx context.Context, guildID discord.GuildID, userID discord.UserID, roleID discord.RoleID, reason api.AuditLogReason) error {
+	p.roles = append(p.roles, roleID)
+	return nil
+}
+
+type testContext struct {
+	context.Context
+	*provider.MockValueProvider
+
+	discord *testDiscordProvider
+}
+
+func (c *testContext) Discord() provider.DiscordProvider {
+	return c.discord
+}
+
+func (c *testContext) Asset() provider.AssetProvider {
+	return &provider.MockAssetProvider{}
+}
+
+func newTestInstance(t *testing.T, values map[string]any) *LevelingPluginInstance {
+	t.Helper()
+
+	config := plugin.ConfigValues{}
+	for key, value := range values {
+		raw, err := json.Marshal(value)
+		require.NoError(t, err)
+		config[key] = raw
+	}
+
+	instance, err := NewLevelingPlugin().Instance(context.Background(), "app", NewLevelingPlugin().Config().WithDefaults(config))
+	require.NoError(t, err)
+	return instance.(*LevelingPluginInstance)
+}
+
+func testMessage(id discord.MessageID) *gateway.MessageCreateEvent {
+	return &gateway.MessageCreateEvent{
+		Message: discord.Message{
+			ID:        id,
+			ChannelID: testChannelID,
+			GuildID:   testGuildID,
+			Author:    discord.User{ID: testUserID},
+		},
+	}
+}
+
+func TestAwardXP(t *testing.T) {
+	values := &provider.MockValueProvider{}
+	c := &testContext{Context: context.Background(), MockValueProvider: values, discord: &testDiscordProvider{}}
+	p := newTestInstance(t, map[string]any{
+		configKeyXPPerMessage:    60,
+		configKeyCooldownSeconds: 60,
+	})
+
+	require.NoError(t, setLevelRewards(c, testGuildID, []LevelReward{{Level: 1, RoleID: testRoleID}}))
+
+	require.NoError(t, p.HandleEvent(c, testMessage(1)))
+	xp, err := c.GetValue(c, levelXPKey(testGuildID, testUserID))
+	require.NoError(t, err)
+	assert.Equal(t, int64(60), xp.Int())
+	assert.Empty(t, c.discord.messages)
+
+	// Messages during the cooldown don't award any XP
+	require.NoError(t, p.HandleEvent(c, testMessage(2)))
+	xp, err = c.GetValue(c, levelXPKey(testGuildID, testUserID))
+	require.NoError(t, err)
+	assert.Equal(t, int64(60), xp.Int())
+
+	// After the cooldown the member reaches level 1
+	values.Now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	require.NoError(t, p.HandleEvent(c, testMessage(3)))
+	xp, err = c.GetValue(c, levelXPKey(testGuildID, testUserID))
+	require.NoError(t, err)
+	assert.Equal(t, int64(120), xp.Int())
+
+	require.Len(t, c.discord.messages, 1)
+	assert.Contains(t, c.discord.messages[0].Content, "**level 1**")
+	assert.Equal(t, []discord.RoleID{testRoleID}, c.discord.roles)
+}
+
+func TestAwardXPIgnoresBots(t *testing.T) {
+	c := &testContext{Context: context.Background(), MockValueProvider: &provider.MockValueProvider{}, discord: &testDiscordProvider{}}
+	p := newTestInstance(t, nil)
+
+	event := testMessage(1)
+	event.Author.Bot = true
+	require.NoError(t, p.HandleEvent(c, event))
+
+	xp, err := c.GetValue(c, levelXPKey(testGuildID, testUserID))
+	require.NoError(t, err)
+	assert.True(t, xp.IsNil())
+}
+
+func TestAwardXPWithoutAnnouncements(t *testing.T) {
+	c := &testContext{Context: context.Background(), MockValueProvider: &provider.MockValueProvider{}, discord: &testDiscordProvider{}}
+	p := newTestInstance(t, map[string]any{
+		configKeyXPPerMessage:         1000,
+		configKeyCooldownSeconds:      0,
+		configKeyDisableAnnouncements: true,
+	})
+
+	require.NoError(t, p.HandleEvent(c, testMessage(1)))
+	require.NoError(t, p.HandleEvent(c, testMessage(2)))
+
+	xp, err := c.GetValue(c, levelXPKey(testGuildID, testUserID))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2000), xp.Int(), "without a cooldown every message awards XP")
+	assert.Empty(t, c.discord.messages)
+}
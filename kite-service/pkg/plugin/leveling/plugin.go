@@ -0,0 +1,202 @@
+package leveling
+
+import (
+	"context"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+	"github.com/kitecloud/kite/kite-service/pkg/plugin"
+)
+
+type LevelingPlugin struct {
+}
+
+func NewLevelingPlugin() *LevelingPlugin {
+	return &LevelingPlugin{}
+}
+
+func (p *LevelingPlugin) Instance(ctx context.Context, appID string, config plugin.ConfigValues) (plugin.PluginInstance, error) {
+	return &LevelingPluginInstance{
+		appID:  appID,
+		config: config,
+	}, nil
+}
+
+func (p *LevelingPlugin) ID() string {
+	return "leveling"
+}
+
+func (p *LevelingPlugin) Metadata() plugin.Metadata {
+	return plugin.Metadata{
+		Name:        "Leveling",
+		Description: "Reward active members with XP, levels and roles.",
+		Icon:        "trophy",
+		Author:      "Merlin",
+	}
+}
+
+func (p *LevelingPlugin) Config() plugin.Config {
+	return plugin.Config{
+		Sections: []plugin.ConfigSection{
+			{
+				Name:        "XP",
+				Description: "How members earn XP by sending messages.",
+				Fields: []plugin.ConfigField{
+					{
+						Key:         configKeyXPPerMessage,
+						Type:        plugin.ConfigFieldTypeInt,
+						Name:        "XP per Message",
+						Description: "The XP that a member earns for a message.",
+						Default:     defaultXPPerMessage,
+						Min:         ptr(1),
+					},
+					{
+						Key:         configKeyCooldownSeconds,
+						Type:        plugin.ConfigFieldTypeInt,
+						Name:        "Cooldown",
+						Description: "The number of seconds before a member can earn XP again.",
+						Default:     defaultCooldownSeconds,
+						Min:         ptr(0),
+					},
+				},
+			},
+			{
+				Name:        "Levels",
+				Description: "How much XP is required for each level. The total XP for a level is the base XP multiplied by the level to the power of the exponent.",
+				Fields: []plugin.ConfigField{
+					{
+						Key:         configKeyLevelBaseXP,
+						Type:        plugin.ConfigFieldTypeInt,
+						Name:        "Base XP",
+						Description: "The XP that is required for the first level.",
+						Default:     defaultLevelBaseXP,
+						Min:         ptr(1),
+					},
+					{
+						Key:         configKeyLevelExponent,
+						Type:        plugin.ConfigFieldTypeFloat,
+						Name:        "Exponent",
+						Description: "How much steeper each level gets.",
+						Default:     defaultLevelExponent,
+						Min:         ptr(0.1),
+						Max:         ptr(10),
+					},
+				},
+			},
+			{
+				Name:        "Announcements",
+				Description: "The message that is sent when a member reaches a new level.",
+				PerGuild:    true,
+				Fields: []plugin.ConfigField{
+					{
+						Key:         configKeyDisableAnnouncements,
+						Type:        plugin.ConfigFieldTypeBool,
+						Name:        "Disable Announcements",
+						Description: "Don't announce when a member reaches a new level.",
+					},
+					{
+						Key:         configKeyLevelUpMessage,
+						Type:        plugin.ConfigFieldTypeString,
+						Name:        "Level Up Message",
+						Description: "The message that is sent in the channel of the member. {user} is replaced with a mention of the member and {level} with the new level.",
+						Default:     defaultLevelUpMessage,
+						Max:         ptr(2000),
+					},
+				},
+			},
+		},
+	}
+}
+
+func (p *LevelingPlugin) Events() []plugin.Event {
+	return []plugin.Event{
+		{
+			ID:          "event_message_create",
+			Source:      plugin.EventSourceDiscord,
+			Type:        plugin.EventTypeMessageCreate,
+			Description: "Give XP to the author of the message and check if they have reached a new level",
+		},
+	}
+}
+
+func (p *LevelingPlugin) Commands() []plugin.Command {
+	perms := discord.PermissionManageGuild
+
+	return []plugin.Command{
+		{
+			ID: "cmd_rank",
+			Data: api.CreateCommandData{
+				Name:        "rank",
+				Description: "Show the level and rank of a member",
+				Options: discord.CommandOptions{
+					&discord.UserOption{
+						OptionName:  "user",
+						Description: "The member to show the rank of, defaults to yourself",
+					},
+				},
+			},
+		},
+		{
+			ID: "cmd_leaderboard",
+			Data: api.CreateCommandData{
+				Name:        "leaderboard",
+				Description: "Show the members with the most XP in the current server",
+				Options: discord.CommandOptions{
+					&discord.IntegerOption{
+						OptionName:  "page",
+						Description: "The page of the leaderboard to show",
+						Min:         option.NewInt(1),
+					},
+				},
+			},
+		},
+		{
+			ID: "cmd_leveling",
+			Data: api.CreateCommandData{
+				Name:                     "leveling",
+				Description:              "Configure the role rewards for levels in the current server",
+				DefaultMemberPermissions: &perms,
+				Options: discord.CommandOptions{
+					&discord.SubcommandOption{
+						OptionName:  "add-reward",
+						Description: "Give a role to members when they reach a level",
+						Options: []discord.CommandOptionValue{
+							&discord.IntegerOption{
+								OptionName:  "level",
+								Description: "The level that members have to reach",
+								Required:    true,
+								Min:         option.NewInt(1),
+							},
+							&discord.RoleOption{
+								OptionName:  "role",
+								Description: "The role to give to members",
+								Required:    true,
+							},
+						},
+					},
+					&discord.SubcommandOption{
+						OptionName:  "remove-reward",
+						Description: "Stop giving a role to members when they reach a level",
+						Options: []discord.CommandOptionValue{
+							&discord.IntegerOption{
+								OptionName:  "level",
+								Description: "The level of the reward",
+								Required:    true,
+								Min:         option.NewInt(1),
+							},
+						},
+					},
+					&discord.SubcommandOption{
+						OptionName:  "rewards",
+						Description: "List the role rewards of the current server",
+					},
+				},
+			},
+		},
+	}
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
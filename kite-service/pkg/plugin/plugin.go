@@ -19,6 +19,9 @@ type Plugin interface {
 	Instance(ctx context.Context, appID string, config ConfigValues) (PluginInstance, error)
 }
 
+// PluginInstance handles the events of a plugin for one app.
+// The custom IDs of components and modals created by a plugin must start with the plugin ID followed by a colon,
+// that's how interactions are routed to the plugin they belong to.
 type PluginInstance interface {
 	Update(ctx context.Context, config ConfigValues) error
 	HandleEvent(c Context, event gateway.Event) error
@@ -64,3 +67,8 @@ type Command struct {
 	ID   string                `json:"id"`
 	Data api.CreateCommandData `json:"data"`
 }
+
+// IsPluginCustomID returns true if the component or modal custom ID belongs to the plugin with the given ID.
+func IsPluginCustomID(pluginID string, customID string) bool {
+	return strings.HasPrefix(customID, pluginID+":")
+}
@@ -0,0 +1,15 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPluginCustomID(t *testing.T) {
+	assert.True(t, IsPluginCustomID("ticket", "ticket:open"))
+	assert.True(t, IsPluginCustomID("roles", "roles:123"))
+	assert.False(t, IsPluginCustomID("roles", "ticket:open"))
+	assert.False(t, IsPluginCustomID("ticket", "tickets:open"))
+	assert.False(t, IsPluginCustomID("ticket", "ticket"))
+}
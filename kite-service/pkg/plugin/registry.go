@@ -1,5 +1,7 @@
 package plugin
 
+import "fmt"
+
 type Registry struct {
 	plugins map[string]Plugin
 }
@@ -10,8 +12,12 @@ func NewRegistry() *Registry {
 	}
 }
 
+// Register adds the plugins to the registry. It panics if the config of a plugin is malformed.
 func (r *Registry) Register(plugins ...Plugin) {
 	for _, p := range plugins {
+		if err := p.Config().ValidateSchema(); err != nil {
+			panic(fmt.Sprintf("invalid config of plugin %s: %v", p.ID(), err))
+		}
 		r.plugins[p.ID()] = p
 	}
 }
@@ -0,0 +1,438 @@
+package roles
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+	"github.com/kitecloud/kite/kite-service/pkg/plugin"
+)
+
+type RolesPluginInstance struct {
+	appID  string
+	config plugin.ConfigValues
+}
+
+func (p *RolesPluginInstance) Update(ctx context.Context, config plugin.ConfigValues) error {
+	p.config = config
+	return nil
+}
+
+func (p *RolesPluginInstance) HandleEvent(c plugin.Context, event gateway.Event) error {
+	switch e := event.(type) {
+	case *gateway.MessageReactionAddEvent:
+		if !e.GuildID.IsValid() || e.Member == nil || e.Member.User.Bot {
+			return nil
+		}
+		return p.handleReaction(c, e.GuildID, e.UserID, e.MessageID, e.Emoji, e.Member.RoleIDs, roleActionSelect)
+	case *gateway.MessageReactionRemoveEvent:
+		if !e.GuildID.IsValid() {
+			return nil
+		}
+		return p.handleReaction(c, e.GuildID, e.UserID, e.MessageID, e.Emoji, nil, roleActionDeselect)
+	}
+
+	return nil
+}
+
+func (p *RolesPluginInstance) handleReaction(
+	c plugin.Context,
+	guildID discord.GuildID,
+	userID discord.UserID,
+	messageID discord.MessageID,
+	emoji discord.Emoji,
+	memberRoles []discord.RoleID,
+	action roleAction,
+) error {
+	panel, err := getRolePanel(c, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to get role panel: %w", err)
+	}
+
+	if panel == nil || panel.Type != PanelTypeReactions {
+		return nil
+	}
+
+	binding := panel.bindingByEmoji(emoji)
+	if binding == nil {
+		return nil
+	}
+
+	add, remove := panel.roleChanges(binding, memberRoles, action)
+	return applyRoleChanges(c, guildID, userID, add, remove)
+}
+
+func (p *RolesPluginInstance) HandleCommand(c plugin.Context, event *gateway.InteractionCreateEvent) error {
+	data, ok := event.Data.(*discord.CommandInteraction)
+	if !ok || !event.GuildID.IsValid() {
+		return nil
+	}
+
+	if data.Name != "roles" || len(data.Options) == 0 {
+		return nil
+	}
+	opt := data.Options[0]
+
+	switch opt.Name {
+	case "panel":
+		return p.handlePanel(c, event, opt.Options)
+	case "add":
+		return p.handleAdd(c, event, data, opt.Options)
+	case "remove":
+		return p.handleRemove(c, event, opt.Options)
+	case "delete":
+		return p.handleDelete(c, event, opt.Options)
+	}
+
+	return nil
+}
+
+func (p *RolesPluginInstance) HandleComponent(c plugin.Context, event *gateway.InteractionCreateEvent) error {
+	data, ok := event.Data.(*discord.ButtonInteraction)
+	if !ok || !event.GuildID.IsValid() || event.Message == nil || event.Member == nil {
+		return nil
+	}
+
+	rawRoleID, ok := strings.CutPrefix(string(data.CustomID), customIDPrefix)
+	if !ok {
+		return nil
+	}
+
+	roleID, err := discord.ParseSnowflake(rawRoleID)
+	if err != nil {
+		return nil
+	}
+
+	panel, err := getRolePanel(c, event.Message.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get role panel: %w", err)
+	}
+
+	var binding *RoleBinding
+	if panel != nil {
+		binding = panel.bindingByRole(discord.RoleID(roleID))
+	}
+	if binding == nil {
+		return respond(c, event, "This role is no longer part of the panel.")
+	}
+
+	add, remove := panel.roleChanges(binding, event.Member.RoleIDs, roleActionToggle)
+	if err := applyRoleChanges(c, event.GuildID, event.Member.User.ID, add, remove); err != nil {
+		return err
+	}
+
+	var changes []string
+	for _, roleID := range add {
+		changes = append(changes, fmt.Sprintf("You now have <@&%d>.", roleID))
+	}
+	for _, roleID := range remove {
+		changes = append(changes, fmt.Sprintf("You no longer have <@&%d>.", roleID))
+	}
+	if len(changes) == 0 {
+		switch panel.Mode {
+		case PanelModeVerify:
+			changes = append(changes, fmt.Sprintf("You already have <@&%d>.", binding.RoleID))
+		case PanelModeDrop:
+			changes = append(changes, fmt.Sprintf("You don't have <@&%d>.", binding.RoleID))
+		}
+	}
+
+	return respond(c, event, strings.Join(changes, "\n"))
+}
+
+func (p *RolesPluginInstance) HandleModal(c plugin.Context, event *gateway.InteractionCreateEvent) error {
+	return nil
+}
+
+func (p *RolesPluginInstance) Close() error {
+	return nil
+}
+
+func (p *RolesPluginInstance) handlePanel(c plugin.Context, event *gateway.InteractionCreateEvent, options []discord.CommandInteractionOption) error {
+	panel := RolePanel{
+		ChannelID: event.ChannelID,
+		Type:      PanelTypeButtons,
+		Mode:      PanelModeNormal,
+	}
+	for _, opt := range options {
+		switch opt.Name {
+		case "title":
+			_ = opt.Value.UnmarshalTo(&panel.Title)
+		case "description":
+			_ = opt.Value.UnmarshalTo(&panel.Description)
+		case "type":
+			_ = opt.Value.UnmarshalTo(&panel.Type)
+		case "mode":
+			_ = opt.Value.UnmarshalTo(&panel.Mode)
+		case "channel":
+			_ = opt.Value.UnmarshalTo(&panel.ChannelID)
+		}
+	}
+
+	embed, components := panel.messageData()
+	msg, err := c.Discord().CreateMessage(c, panel.ChannelID, api.SendMessageData{
+		Embeds:     []discord.Embed{embed},
+		Components: components,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send role panel: %w", err)
+	}
+
+	if err := setRolePanel(c, msg.ID, &panel); err != nil {
+		return fmt.Errorf("failed to save role panel: %w", err)
+	}
+
+	return respond(c, event, fmt.Sprintf(
+		"The role panel has been sent to <#%d>. Use `/roles add message_id:%d` to add roles to it.",
+		panel.ChannelID,
+		msg.ID,
+	))
+}
+
+func (p *RolesPluginInstance) handleAdd(
+	c plugin.Context,
+	event *gateway.InteractionCreateEvent,
+	data *discord.CommandInteraction,
+	options []discord.CommandInteractionOption,
+) error {
+	var roleID discord.RoleID
+	var rawEmoji, label string
+	for _, opt := range options {
+		switch opt.Name {
+		case "role":
+			_ = opt.Value.UnmarshalTo(&roleID)
+		case "emoji":
+			_ = opt.Value.UnmarshalTo(&rawEmoji)
+		case "label":
+			_ = opt.Value.UnmarshalTo(&label)
+		}
+	}
+
+	messageID, panel, err := panelFromOptions(c, options)
+	if err != nil {
+		return err
+	}
+	if panel == nil {
+		return respond(c, event, "There is no role panel with this message ID.")
+	}
+
+	if panel.bindingByRole(roleID) != nil {
+		return respond(c, event, fmt.Sprintf("<@&%d> is already part of the panel.", roleID))
+	}
+
+	reason, err := unassignableReason(c, event, roleID)
+	if err != nil {
+		return err
+	}
+	if reason != "" {
+		return respond(c, event, reason)
+	}
+
+	if len(panel.Bindings) >= panel.maxBindings() {
+		return respond(c, event, fmt.Sprintf("A panel can't have more than %d roles.", panel.maxBindings()))
+	}
+
+	emoji, err := parseEmoji(rawEmoji)
+	if err != nil {
+		return respond(c, event, "The emoji is invalid.")
+	}
+
+	if panel.Type == PanelTypeReactions {
+		if emoji == nil {
+			return respond(c, event, "Roles on a reaction panel need an emoji.")
+		}
+		if panel.bindingByEmoji(*emoji) != nil {
+			return respond(c, event, fmt.Sprintf("%s is already used for another role of the panel.", emoji))
+		}
+	} else if label == "" {
+		if role, ok := data.Resolved.Roles[roleID]; ok {
+			label = role.Name
+		}
+	}
+
+	panel.Bindings = append(panel.Bindings, RoleBinding{
+		RoleID: roleID,
+		Emoji:  emoji,
+		Label:  label,
+	})
+
+	if panel.Type == PanelTypeReactions {
+		if err := c.Discord().CreateMessageReaction(c, panel.ChannelID, messageID, emoji.APIString()); err != nil {
+			return respond(c, event, "Failed to react with the emoji, make sure that it's an emoji that the bot can use.")
+		}
+	}
+
+	if err := panel.updateMessage(c, messageID); err != nil {
+		return fmt.Errorf("failed to update role panel message: %w", err)
+	}
+
+	if err := setRolePanel(c, messageID, panel); err != nil {
+		return fmt.Errorf("failed to save role panel: %w", err)
+	}
+
+	return respond(c, event, fmt.Sprintf("<@&%d> has been added to the panel.", roleID))
+}
+
+// unassignableReason returns why the role can't be added to a panel, or an empty string if it can.
+// Without these checks members could use the bot to hand out roles that they aren't allowed to manage themselves.
+func unassignableReason(c plugin.Context, event *gateway.InteractionCreateEvent, roleID discord.RoleID) (string, error) {
+	if discord.GuildID(roleID) == event.GuildID {
+		return "@everyone can't be added to a panel.", nil
+	}
+
+	roles, err := c.Discord().GuildRoles(c, event.GuildID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get guild roles: %w", err)
+	}
+
+	positions := make(map[discord.RoleID]int, len(roles))
+	var role *discord.Role
+	for i, r := range roles {
+		positions[r.ID] = r.Position
+		if r.ID == roleID {
+			role = &roles[i]
+		}
+	}
+	if role == nil {
+		return "The role doesn't exist.", nil
+	}
+
+	if role.Managed {
+		return fmt.Sprintf("<@&%d> is managed by an integration and can't be assigned to members.", roleID), nil
+	}
+
+	bot, err := c.Discord().Member(c, event.GuildID, discord.UserID(event.AppID))
+	if err != nil {
+		return "", fmt.Errorf("failed to get bot member: %w", err)
+	}
+	if highestRolePosition(bot.RoleIDs, positions) <= role.Position {
+		return fmt.Sprintf("<@&%d> must be below the highest role of the bot.", roleID), nil
+	}
+
+	if event.Member == nil {
+		return "", nil
+	}
+
+	guild, err := c.Discord().Guild(c, event.GuildID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get guild: %w", err)
+	}
+	if event.Member.User.ID != guild.OwnerID && highestRolePosition(event.Member.RoleIDs, positions) <= role.Position {
+		return fmt.Sprintf("<@&%d> must be below your highest role.", roleID), nil
+	}
+
+	return "", nil
+}
+
+// highestRolePosition returns the position of the highest of the roles, 0 being the position of @everyone.
+func highestRolePosition(roleIDs []discord.RoleID, positions map[discord.RoleID]int) int {
+	highest := 0
+	for _, roleID := range roleIDs {
+		if position := positions[roleID]; position > highest {
+			highest = position
+		}
+	}
+	return highest
+}
+
+func (p *RolesPluginInstance) handleRemove(c plugin.Context, event *gateway.InteractionCreateEvent, options []discord.CommandInteractionOption) error {
+	var roleID discord.RoleID
+	for _, opt := range options {
+		if opt.Name == "role" {
+			_ = opt.Value.UnmarshalTo(&roleID)
+		}
+	}
+
+	messageID, panel, err := panelFromOptions(c, options)
+	if err != nil {
+		return err
+	}
+	if panel == nil {
+		return respond(c, event, "There is no role panel with this message ID.")
+	}
+
+	binding := panel.bindingByRole(roleID)
+	if binding == nil {
+		return respond(c, event, fmt.Sprintf("<@&%d> isn't part of the panel.", roleID))
+	}
+	emoji := binding.Emoji
+
+	bindings := make([]RoleBinding, 0, len(panel.Bindings))
+	for _, b := range panel.Bindings {
+		if b.RoleID != roleID {
+			bindings = append(bindings, b)
+		}
+	}
+	panel.Bindings = bindings
+
+	if err := setRolePanel(c, messageID, panel); err != nil {
+		return fmt.Errorf("failed to save role panel: %w", err)
+	}
+
+	if panel.Type == PanelTypeReactions && emoji != nil {
+		if err := c.Discord().DeleteMessageReaction(c, panel.ChannelID, messageID, emoji.APIString()); err != nil {
+			return fmt.Errorf("failed to remove reaction: %w", err)
+		}
+	}
+
+	if err := panel.updateMessage(c, messageID); err != nil {
+		return fmt.Errorf("failed to update role panel message: %w", err)
+	}
+
+	return respond(c, event, fmt.Sprintf("<@&%d> has been removed from the panel.", roleID))
+}
+
+func (p *RolesPluginInstance) handleDelete(c plugin.Context, event *gateway.InteractionCreateEvent, options []discord.CommandInteractionOption) error {
+	messageID, panel, err := panelFromOptions(c, options)
+	if err != nil {
+		return err
+	}
+	if panel == nil {
+		return respond(c, event, "There is no role panel with this message ID.")
+	}
+
+	if err := c.DeleteValue(c, rolePanelKey(messageID)); err != nil {
+		return fmt.Errorf("failed to delete role panel: %w", err)
+	}
+
+	// The message may have been deleted already, the panel is gone either way.
+	_ = c.Discord().DeleteMessage(c, panel.ChannelID, messageID, "Role panel deleted")
+
+	return respond(c, event, "The role panel has been deleted.")
+}
+
+func panelFromOptions(c plugin.Context, options []discord.CommandInteractionOption) (discord.MessageID, *RolePanel, error) {
+	var rawMessageID string
+	for _, opt := range options {
+		if opt.Name == "message_id" {
+			_ = opt.Value.UnmarshalTo(&rawMessageID)
+		}
+	}
+
+	messageID, err := discord.ParseSnowflake(strings.TrimSpace(rawMessageID))
+	if err != nil {
+		return 0, nil, nil
+	}
+
+	panel, err := getRolePanel(c, discord.MessageID(messageID))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get role panel: %w", err)
+	}
+
+	return discord.MessageID(messageID), panel, nil
+}
+
+func respond(c plugin.Context, event *gateway.InteractionCreateEvent, content string) error {
+	_, err := c.Discord().CreateInteractionResponse(c, event.ID, event.Token, api.InteractionResponse{
+		Type: api.MessageInteractionWithSource,
+		Data: &api.InteractionResponseData{
+			Content: option.NewNullableString(content),
+			Flags:   discord.EphemeralMessage,
+		},
+	})
+	return err
+}
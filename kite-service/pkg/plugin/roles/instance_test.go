@@ -0,0 +1,238 @@
+package roles
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/utils/json"
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testGuildID     discord.GuildID   = 1
+	testChannelID   discord.ChannelID = 2
+	testMessageID   discord.MessageID = 3
+	testAppID       discord.AppID     = 4
+	testUserID      discord.UserID    = 5
+	testOwnerID     discord.UserID    = 6
+	testBotRoleID   discord.RoleID    = 20
+	testStaffRoleID discord.RoleID    = 21
+	testRoleID      discord.RoleID    = 22
+	testManagedID   discord.RoleID    = 23
+	testHighRoleID  discord.RoleID    = 24
+)
+
+// testDiscordProvider serves a guild where the bot has the highest role, followed by the staff role.
+type testDiscordProvider struct {
+	provider.MockDiscordProvider
+
+	responses []string
+	edits     int
+}
+
+func (p *testDiscordProvider) Guild(ctx context.Context, guildID discord.GuildID) (*discord.Guild, error) {
+	return &discord.Guild{ID: guildID, OwnerID: testOwnerID}, nil
+}
+
+func (p *testDiscordProvider) GuildRoles(ctx context.Context, guildID discord.GuildID) ([]discord.Role, error) {
+	return []discord.Role{
+		{ID: discord.RoleID(guildID), Name: "@everyone", Position: 0},
+		{ID: testRoleID, Name: "Member", Position: 1},
+		{ID: testManagedID, Name: "Integration", Position: 2, Managed: true},
+		{ID: testStaffRoleID, Name: "Staff", Position: 3},
+		{ID: testHighRoleID, Name: "Admin", Position: 4},
+		{ID: testBotRoleID, Name: "Bot", Position: 5},
+	}, nil
+}
+
+func (p *testDiscordProvider) Member(ctx context.Context, guildID discord.GuildID, userID discord.UserID) (*discord.Member, error) {
+	if userID != discord.UserID(testAppID) {
+		return nil, fmt.Errorf("unknown member %d", userID)
+	}
+	return &discord.Member{User: discord.User{ID: userID}, RoleIDs: []discord.RoleID{testBotRoleID}}, nil
+}
+
+func (p *testDiscordProvider) EditMessage(ctx context.Context, channelID discord.ChannelID, messageID discord.MessageID, message api.EditMessageData) (*discord.Message, error) {
+	p.edits++
+	return &discord.Message{ID: messageID, ChannelID: channelID}, nil
+}
+
+func (p *testDiscordProvider) CreateInteractionResponse(ctx context.Context, interactionID discord.InteractionID, interactionToken string, response api.InteractionResponse) (*provider.InteractionResponseResource, error) {
+	p.responses = append(p.responses, response.Data.Content.Val)
+	return nil, nil
+}
+
+func (p *testDiscordProvider) lastResponse() string {
+	if len(p.responses) == 0 {
+		return ""
+	}
+	return p.responses[len(p.responses)-1]
+}
+
+type testContext struct {
+	context.Context
+	*provider.MockValueProvider
+
+	discord *testDiscordProvider
+}
+
+func (c *testContext) Discord() provider.DiscordProvider {
+	return c.discord
+}
+
+func (c *testContext) Asset() provider.AssetProvider {
+	return &provider.MockAssetProvider{}
+}
+
+func newTestContext(t *testing.T) *testContext {
+	t.Helper()
+
+	c := &testContext{
+		Context:           context.Background(),
+		MockValueProvider: &provider.MockValueProvider{},
+		discord:           &testDiscordProvider{},
+	}
+
+	err := setRolePanel(c, testMessageID, &RolePanel{
+		ChannelID: testChannelID,
+		Type:      PanelTypeButtons,
+		Mode:      PanelModeNormal,
+	})
+	require.NoError(t, err)
+
+	return c
+}
+
+func testAddCommand(userID discord.UserID, roleID discord.RoleID, memberRoles ...discord.RoleID) (*gateway.InteractionCreateEvent, *discord.CommandInteraction) {
+	data := &discord.CommandInteraction{
+		Name: "roles",
+		Options: []discord.CommandInteractionOption{{
+			Name: "add",
+			Type: discord.SubcommandOptionType,
+			Options: []discord.CommandInteractionOption{
+				{Name: "message_id", Type: discord.StringOptionType, Value: json.Raw(fmt.Sprintf(`"%d"`, testMessageID))},
+				{Name: "role", Type: discord.RoleOptionType, Value: json.Raw(fmt.Sprintf(`"%d"`, roleID))},
+			},
+		}},
+	}
+	data.Resolved.Roles = map[discord.RoleID]discord.Role{roleID: {ID: roleID, Name: "Resolved"}}
+
+	event := &gateway.InteractionCreateEvent{
+		InteractionEvent: discord.InteractionEvent{
+			ID:        1,
+			AppID:     testAppID,
+			GuildID:   testGuildID,
+			ChannelID: testChannelID,
+			Token:     "token",
+			Member: &discord.Member{
+				User:    discord.User{ID: userID},
+				RoleIDs: memberRoles,
+			},
+			Data: data,
+		},
+	}
+
+	return event, data
+}
+
+func TestHandleAdd(t *testing.T) {
+	c := newTestContext(t)
+	p := &RolesPluginInstance{}
+
+	event, data := testAddCommand(testUserID, testRoleID, testStaffRoleID)
+	require.NoError(t, p.handleAdd(c, event, data, data.Options[0].Options))
+	assert.Equal(t, fmt.Sprintf("<@&%d> has been added to the panel.", testRoleID), c.discord.lastResponse())
+	assert.Equal(t, 1, c.discord.edits)
+
+	panel, err := getRolePanel(c, testMessageID)
+	require.NoError(t, err)
+	assert.Equal(t, []RoleBinding{{RoleID: testRoleID, Label: "Resolved"}}, panel.Bindings)
+
+	require.NoError(t, p.handleAdd(c, event, data, data.Options[0].Options))
+	assert.Equal(t, fmt.Sprintf("<@&%d> is already part of the panel.", testRoleID), c.discord.lastResponse())
+}
+
+func TestHandleAddRejectsRoles(t *testing.T) {
+	tests := []struct {
+		name        string
+		userID      discord.UserID
+		roleID      discord.RoleID
+		memberRoles []discord.RoleID
+		response    string
+	}{
+		{
+			name:        "everyone",
+			userID:      testUserID,
+			roleID:      discord.RoleID(testGuildID),
+			memberRoles: []discord.RoleID{testStaffRoleID},
+			response:    "@everyone can't be added to a panel.",
+		},
+		{
+			name:        "managed",
+			userID:      testUserID,
+			roleID:      testManagedID,
+			memberRoles: []discord.RoleID{testStaffRoleID},
+			response:    fmt.Sprintf("<@&%d> is managed by an integration and can't be assigned to members.", testManagedID),
+		},
+		{
+			name:        "unknown",
+			userID:      testUserID,
+			roleID:      99,
+			memberRoles: []discord.RoleID{testStaffRoleID},
+			response:    "The role doesn't exist.",
+		},
+		{
+			name:        "above the bot",
+			userID:      testOwnerID,
+			roleID:      testBotRoleID,
+			memberRoles: nil,
+			response:    fmt.Sprintf("<@&%d> must be below the highest role of the bot.", testBotRoleID),
+		},
+		{
+			name:        "equal to the invoker",
+			userID:      testUserID,
+			roleID:      testStaffRoleID,
+			memberRoles: []discord.RoleID{testStaffRoleID},
+			response:    fmt.Sprintf("<@&%d> must be below your highest role.", testStaffRoleID),
+		},
+		{
+			name:        "above the invoker",
+			userID:      testUserID,
+			roleID:      testHighRoleID,
+			memberRoles: []discord.RoleID{testStaffRoleID},
+			response:    fmt.Sprintf("<@&%d> must be below your highest role.", testHighRoleID),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newTestContext(t)
+			p := &RolesPluginInstance{}
+
+			event, data := testAddCommand(test.userID, test.roleID, test.memberRoles...)
+			require.NoError(t, p.handleAdd(c, event, data, data.Options[0].Options))
+			assert.Equal(t, test.response, c.discord.lastResponse())
+			assert.Zero(t, c.discord.edits)
+
+			panel, err := getRolePanel(c, testMessageID)
+			require.NoError(t, err)
+			assert.Empty(t, panel.Bindings)
+		})
+	}
+}
+
+func TestHandleAddAsOwner(t *testing.T) {
+	c := newTestContext(t)
+	p := &RolesPluginInstance{}
+
+	// The owner can add roles above their own highest role, as long as the bot can assign them
+	event, data := testAddCommand(testOwnerID, testHighRoleID)
+	require.NoError(t, p.handleAdd(c, event, data, data.Options[0].Options))
+	assert.Equal(t, fmt.Sprintf("<@&%d> has been added to the panel.", testHighRoleID), c.discord.lastResponse())
+}
@@ -0,0 +1,251 @@
+package roles
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/kitecloud/kite/kite-service/pkg/plugin"
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+)
+
+const panelColor = 0x5865f2
+
+const (
+	maxButtonBindings   = 25
+	maxReactionBindings = 20
+)
+
+const customIDPrefix = "roles:"
+
+type PanelType string
+
+const (
+	PanelTypeButtons   PanelType = "buttons"
+	PanelTypeReactions PanelType = "reactions"
+)
+
+type PanelMode string
+
+const (
+	// PanelModeNormal lets members pick and remove any role of the panel.
+	PanelModeNormal PanelMode = "normal"
+	// PanelModeUnique lets members have only one role of the panel at a time.
+	PanelModeUnique PanelMode = "unique"
+	// PanelModeVerify only gives roles and never takes them away.
+	PanelModeVerify PanelMode = "verify"
+	// PanelModeDrop only takes roles away and never gives them.
+	PanelModeDrop PanelMode = "drop"
+)
+
+type RolePanel struct {
+	ChannelID   discord.ChannelID `json:"channel_id"`
+	Type        PanelType         `json:"type"`
+	Mode        PanelMode         `json:"mode"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Bindings    []RoleBinding     `json:"bindings"`
+}
+
+type RoleBinding struct {
+	RoleID discord.RoleID `json:"role_id"`
+	Emoji  *discord.Emoji `json:"emoji,omitempty"`
+	Label  string         `json:"label,omitempty"`
+}
+
+func (p *RolePanel) maxBindings() int {
+	if p.Type == PanelTypeReactions {
+		return maxReactionBindings
+	}
+	return maxButtonBindings
+}
+
+func (p *RolePanel) bindingByRole(roleID discord.RoleID) *RoleBinding {
+	for i := range p.Bindings {
+		if p.Bindings[i].RoleID == roleID {
+			return &p.Bindings[i]
+		}
+	}
+	return nil
+}
+
+func (p *RolePanel) bindingByEmoji(emoji discord.Emoji) *RoleBinding {
+	for i := range p.Bindings {
+		b := p.Bindings[i]
+		if b.Emoji != nil && b.Emoji.ID == emoji.ID && b.Emoji.Name == emoji.Name {
+			return &p.Bindings[i]
+		}
+	}
+	return nil
+}
+
+// messageData renders the panel message including the buttons for button panels.
+func (p *RolePanel) messageData() (discord.Embed, discord.ContainerComponents) {
+	var b strings.Builder
+	if p.Description != "" {
+		b.WriteString(p.Description)
+		b.WriteString("\n\n")
+	}
+	for _, binding := range p.Bindings {
+		if binding.Emoji != nil {
+			fmt.Fprintf(&b, "%s ", binding.Emoji)
+		}
+		fmt.Fprintf(&b, "<@&%d>\n", binding.RoleID)
+	}
+
+	embed := discord.Embed{
+		Title:       p.Title,
+		Description: b.String(),
+		Color:       panelColor,
+	}
+
+	components := discord.ContainerComponents{}
+	if p.Type != PanelTypeButtons {
+		return embed, components
+	}
+
+	var row *discord.ActionRowComponent
+	for i, binding := range p.Bindings {
+		if i%5 == 0 {
+			row = &discord.ActionRowComponent{}
+			components = append(components, row)
+		}
+
+		button := &discord.ButtonComponent{
+			CustomID: discord.ComponentID(customIDPrefix + binding.RoleID.String()),
+			Label:    binding.Label,
+			Style:    discord.SecondaryButtonStyle(),
+		}
+		if binding.Emoji != nil {
+			button.Emoji = &discord.ComponentEmoji{
+				ID:       binding.Emoji.ID,
+				Name:     binding.Emoji.Name,
+				Animated: binding.Emoji.Animated,
+			}
+		}
+		*row = append(*row, button)
+	}
+
+	return embed, components
+}
+
+// updateMessage re-renders the panel message after the bindings have changed.
+func (p *RolePanel) updateMessage(c plugin.Context, messageID discord.MessageID) error {
+	embed, components := p.messageData()
+	_, err := c.Discord().EditMessage(c, p.ChannelID, messageID, api.EditMessageData{
+		Embeds:     &[]discord.Embed{embed},
+		Components: &components,
+	})
+	return err
+}
+
+type roleAction int
+
+const (
+	// roleActionSelect is used when a member adds a reaction.
+	roleActionSelect roleAction = iota
+	// roleActionDeselect is used when a member removes a reaction.
+	roleActionDeselect
+	// roleActionToggle is used when a member clicks a button.
+	roleActionToggle
+)
+
+// roleChanges returns which roles the member should get and lose after the action on the given binding.
+// The roles of the member are only known for select and toggle actions.
+func (p *RolePanel) roleChanges(binding *RoleBinding, memberRoles []discord.RoleID, action roleAction) (add []discord.RoleID, remove []discord.RoleID) {
+	hasRole := slices.Contains(memberRoles, binding.RoleID)
+
+	if action == roleActionToggle {
+		action = roleActionSelect
+		if hasRole && (p.Mode == PanelModeNormal || p.Mode == PanelModeUnique) {
+			action = roleActionDeselect
+		}
+	}
+
+	switch p.Mode {
+	case PanelModeVerify:
+		if action == roleActionSelect && !hasRole {
+			add = append(add, binding.RoleID)
+		}
+	case PanelModeDrop:
+		if action == roleActionSelect && hasRole {
+			remove = append(remove, binding.RoleID)
+		}
+	default:
+		if action == roleActionDeselect {
+			remove = append(remove, binding.RoleID)
+			break
+		}
+
+		if !hasRole {
+			add = append(add, binding.RoleID)
+		}
+		if p.Mode == PanelModeUnique {
+			for _, other := range p.Bindings {
+				if other.RoleID != binding.RoleID && slices.Contains(memberRoles, other.RoleID) {
+					remove = append(remove, other.RoleID)
+				}
+			}
+		}
+	}
+
+	return add, remove
+}
+
+func applyRoleChanges(c plugin.Context, guildID discord.GuildID, userID discord.UserID, add []discord.RoleID, remove []discord.RoleID) error {
+	reason := api.AuditLogReason("Role panel")
+
+	for _, roleID := range remove {
+		if err := c.Discord().RemoveMemberRole(c, guildID, userID, roleID, reason); err != nil {
+			return fmt.Errorf("failed to remove role: %w", err)
+		}
+	}
+
+	for _, roleID := range add {
+		if err := c.Discord().AddMemberRole(c, guildID, userID, roleID, reason); err != nil {
+			return fmt.Errorf("failed to add role: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func parseEmoji(raw string) (*discord.Emoji, error) {
+	return plugin.ParseEmoji(strings.TrimSpace(raw))
+}
+
+func getRolePanel(c plugin.Context, messageID discord.MessageID) (*RolePanel, error) {
+	raw, err := c.GetValue(c, rolePanelKey(messageID))
+	if err != nil {
+		return nil, err
+	}
+
+	if raw == thing.Null {
+		return nil, nil
+	}
+
+	panel := RolePanel{}
+	if err := json.Unmarshal([]byte(raw.String()), &panel); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal role panel: %w", err)
+	}
+
+	return &panel, nil
+}
+
+func setRolePanel(c plugin.Context, messageID discord.MessageID, panel *RolePanel) error {
+	raw, err := json.Marshal(panel)
+	if err != nil {
+		return fmt.Errorf("failed to marshal role panel: %w", err)
+	}
+
+	_, err = c.UpdateValue(c, rolePanelKey(messageID), provider.VariableOperationOverwrite, thing.NewString(string(raw)))
+	return err
+}
+
+func rolePanelKey(messageID discord.MessageID) string {
+	return "role-panel:" + messageID.String()
+}
@@ -0,0 +1,77 @@
+package roles
+
+import (
+	"testing"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleChanges(t *testing.T) {
+	const (
+		roleA discord.RoleID = 10
+		roleB discord.RoleID = 11
+		roleC discord.RoleID = 12
+	)
+
+	tests := []struct {
+		name        string
+		mode        PanelMode
+		memberRoles []discord.RoleID
+		action      roleAction
+		add         []discord.RoleID
+		remove      []discord.RoleID
+	}{
+		{name: "normal select", mode: PanelModeNormal, action: roleActionSelect, add: []discord.RoleID{roleA}},
+		{name: "normal select owned", mode: PanelModeNormal, memberRoles: []discord.RoleID{roleA}, action: roleActionSelect},
+		{name: "normal deselect", mode: PanelModeNormal, action: roleActionDeselect, remove: []discord.RoleID{roleA}},
+		{name: "normal toggle on", mode: PanelModeNormal, action: roleActionToggle, add: []discord.RoleID{roleA}},
+		{name: "normal toggle off", mode: PanelModeNormal, memberRoles: []discord.RoleID{roleA}, action: roleActionToggle, remove: []discord.RoleID{roleA}},
+		{
+			name:        "unique select removes other roles",
+			mode:        PanelModeUnique,
+			memberRoles: []discord.RoleID{roleB, roleC, 99},
+			action:      roleActionSelect,
+			add:         []discord.RoleID{roleA},
+			remove:      []discord.RoleID{roleB, roleC},
+		},
+		{name: "unique toggle off", mode: PanelModeUnique, memberRoles: []discord.RoleID{roleA, roleB}, action: roleActionToggle, remove: []discord.RoleID{roleA}},
+		{name: "verify select", mode: PanelModeVerify, action: roleActionSelect, add: []discord.RoleID{roleA}},
+		{name: "verify toggle owned", mode: PanelModeVerify, memberRoles: []discord.RoleID{roleA}, action: roleActionToggle},
+		{name: "verify deselect", mode: PanelModeVerify, memberRoles: []discord.RoleID{roleA}, action: roleActionDeselect},
+		{name: "drop select", mode: PanelModeDrop, memberRoles: []discord.RoleID{roleA}, action: roleActionSelect, remove: []discord.RoleID{roleA}},
+		{name: "drop toggle", mode: PanelModeDrop, memberRoles: []discord.RoleID{roleA}, action: roleActionToggle, remove: []discord.RoleID{roleA}},
+		{name: "drop toggle without role", mode: PanelModeDrop, action: roleActionToggle},
+		{name: "drop deselect", mode: PanelModeDrop, memberRoles: []discord.RoleID{roleA}, action: roleActionDeselect},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			panel := &RolePanel{
+				Mode:     test.mode,
+				Bindings: []RoleBinding{{RoleID: roleA}, {RoleID: roleB}, {RoleID: roleC}},
+			}
+
+			add, remove := panel.roleChanges(&panel.Bindings[0], test.memberRoles, test.action)
+			assert.Equal(t, test.add, add)
+			assert.Equal(t, test.remove, remove)
+		})
+	}
+}
+
+func TestParseEmoji(t *testing.T) {
+	emoji, err := parseEmoji("  ")
+	assert.NoError(t, err)
+	assert.Nil(t, emoji)
+
+	emoji, err = parseEmoji("👍")
+	assert.NoError(t, err)
+	assert.Equal(t, &discord.Emoji{Name: "👍"}, emoji)
+
+	emoji, err = parseEmoji("<a:party:123>")
+	assert.NoError(t, err)
+	assert.Equal(t, &discord.Emoji{ID: 123, Name: "party", Animated: true}, emoji)
+
+	_, err = parseEmoji("party")
+	assert.Error(t, err)
+}
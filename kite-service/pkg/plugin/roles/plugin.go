@@ -0,0 +1,157 @@
+package roles
+
+import (
+	"context"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/kitecloud/kite/kite-service/pkg/plugin"
+)
+
+type RolesPlugin struct {
+}
+
+func NewRolesPlugin() *RolesPlugin {
+	return &RolesPlugin{}
+}
+
+func (p *RolesPlugin) Instance(ctx context.Context, appID string, config plugin.ConfigValues) (plugin.PluginInstance, error) {
+	return &RolesPluginInstance{
+		appID:  appID,
+		config: config,
+	}, nil
+}
+
+func (p *RolesPlugin) ID() string {
+	return "roles"
+}
+
+func (p *RolesPlugin) Metadata() plugin.Metadata {
+	return plugin.Metadata{
+		Name:        "Reaction Roles",
+		Description: "Let members pick their own roles with reactions or buttons.",
+		Icon:        "user-check",
+		Author:      "Merlin",
+	}
+}
+
+func (p *RolesPlugin) Config() plugin.Config {
+	return plugin.Config{}
+}
+
+func (p *RolesPlugin) Events() []plugin.Event {
+	return []plugin.Event{
+		{
+			ID:          "event_message_reaction_add",
+			Source:      plugin.EventSourceDiscord,
+			Type:        plugin.EventTypeMessageReactionAdd,
+			Description: "Give the role of the reaction to the member",
+		},
+		{
+			ID:          "event_message_reaction_remove",
+			Source:      plugin.EventSourceDiscord,
+			Type:        plugin.EventTypeMessageReactionRemove,
+			Description: "Take the role of the reaction from the member",
+		},
+	}
+}
+
+func (p *RolesPlugin) Commands() []plugin.Command {
+	perms := discord.PermissionManageRoles
+
+	messageIDOption := &discord.StringOption{
+		OptionName:  "message_id",
+		Description: "The ID of the role panel message",
+		Required:    true,
+	}
+
+	return []plugin.Command{
+		{
+			ID: "cmd_roles",
+			Data: api.CreateCommandData{
+				Name:                     "roles",
+				Description:              "Configure role panels that members use to pick their roles",
+				DefaultMemberPermissions: &perms,
+				Options: discord.CommandOptions{
+					&discord.SubcommandOption{
+						OptionName:  "panel",
+						Description: "Send a new role panel",
+						Options: []discord.CommandOptionValue{
+							&discord.StringOption{
+								OptionName:  "title",
+								Description: "The title of the panel",
+								Required:    true,
+							},
+							&discord.StringOption{
+								OptionName:  "type",
+								Description: "Whether members pick their roles with reactions or buttons, defaults to buttons",
+								Choices: []discord.StringChoice{
+									{Name: "Buttons", Value: string(PanelTypeButtons)},
+									{Name: "Reactions", Value: string(PanelTypeReactions)},
+								},
+							},
+							&discord.StringOption{
+								OptionName:  "mode",
+								Description: "How roles are given and taken, defaults to normal",
+								Choices: []discord.StringChoice{
+									{Name: "Normal - Members can pick and remove any role", Value: string(PanelModeNormal)},
+									{Name: "Unique - Members can only have one role of the panel", Value: string(PanelModeUnique)},
+									{Name: "Verify - Roles can only be picked but not removed", Value: string(PanelModeVerify)},
+									{Name: "Drop - Roles can only be removed but not picked", Value: string(PanelModeDrop)},
+								},
+							},
+							&discord.StringOption{
+								OptionName:  "description",
+								Description: "The description of the panel",
+							},
+							&discord.ChannelOption{
+								OptionName:   "channel",
+								Description:  "The channel to send the panel to, defaults to the current channel",
+								ChannelTypes: []discord.ChannelType{discord.GuildText, discord.GuildAnnouncement},
+							},
+						},
+					},
+					&discord.SubcommandOption{
+						OptionName:  "add",
+						Description: "Add a role to a role panel",
+						Options: []discord.CommandOptionValue{
+							messageIDOption,
+							&discord.RoleOption{
+								OptionName:  "role",
+								Description: "The role to add to the panel",
+								Required:    true,
+							},
+							&discord.StringOption{
+								OptionName:  "emoji",
+								Description: "The emoji for the role, required for reaction panels",
+							},
+							&discord.StringOption{
+								OptionName:  "label",
+								Description: "The label of the button, defaults to the name of the role",
+							},
+						},
+					},
+					&discord.SubcommandOption{
+						OptionName:  "remove",
+						Description: "Remove a role from a role panel",
+						Options: []discord.CommandOptionValue{
+							messageIDOption,
+							&discord.RoleOption{
+								OptionName:  "role",
+								Description: "The role to remove from the panel",
+								Required:    true,
+							},
+						},
+					},
+					&discord.SubcommandOption{
+						OptionName:  "delete",
+						Description: "Delete a role panel, members keep the roles they have picked",
+						Options: []discord.CommandOptionValue{
+							messageIDOption,
+						},
+					},
+				},
+			},
+		},
+	}
+}
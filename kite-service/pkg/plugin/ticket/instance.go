@@ -0,0 +1,233 @@
+package ticket
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+	"github.com/kitecloud/kite/kite-service/pkg/plugin"
+)
+
+const (
+	configKeyPanelTitle       = "panel_title"
+	configKeyPanelDescription = "panel_description"
+	configKeyButtonLabel      = "button_label"
+	configKeyWelcomeMessage   = "welcome_message"
+)
+
+const (
+	defaultPanelTitle       = "Support Tickets"
+	defaultPanelDescription = "Click the button below to open a private ticket with our staff."
+	defaultButtonLabel      = "Open Ticket"
+	defaultWelcomeMessage   = "Thanks for opening a ticket, {user}! Please describe your issue, our staff will be with you shortly."
+)
+
+const (
+	customIDOpen   = "ticket:open"
+	customIDClaim  = "ticket:claim"
+	customIDClose  = "ticket:close"
+	customIDReopen = "ticket:reopen"
+)
+
+type TicketPluginInstance struct {
+	appID  string
+	config plugin.ConfigValues
+}
+
+func (p *TicketPluginInstance) Update(ctx context.Context, config plugin.ConfigValues) error {
+	p.config = config
+	return nil
+}
+
+func (p *TicketPluginInstance) HandleEvent(c plugin.Context, event gateway.Event) error {
+	return nil
+}
+
+func (p *TicketPluginInstance) HandleCommand(c plugin.Context, event *gateway.InteractionCreateEvent) error {
+	data, ok := event.Data.(*discord.CommandInteraction)
+	if !ok || !event.GuildID.IsValid() {
+		return nil
+	}
+
+	if len(data.Options) == 0 {
+		return nil
+	}
+	opt := data.Options[0]
+
+	switch data.Name {
+	case "tickets":
+		switch opt.Name {
+		case "setup":
+			return p.handleSetup(c, event, opt.Options)
+		case "panel":
+			return p.handlePanel(c, event, opt.Options)
+		case "disable":
+			return p.handleDisable(c, event)
+		}
+	case "ticket":
+		switch opt.Name {
+		case "claim":
+			return p.claimTicket(c, event)
+		case "close":
+			var reason string
+			for _, subOpt := range opt.Options {
+				if subOpt.Name == "reason" {
+					_ = subOpt.Value.UnmarshalTo(&reason)
+				}
+			}
+			return p.closeTicket(c, event, reason)
+		case "reopen":
+			return p.reopenTicket(c, event)
+		}
+	}
+
+	return nil
+}
+
+func (p *TicketPluginInstance) HandleComponent(c plugin.Context, event *gateway.InteractionCreateEvent) error {
+	data, ok := event.Data.(*discord.ButtonInteraction)
+	if !ok || !event.GuildID.IsValid() {
+		return nil
+	}
+
+	switch string(data.CustomID) {
+	case customIDOpen:
+		return p.openTicket(c, event)
+	case customIDClaim:
+		return p.claimTicket(c, event)
+	case customIDClose:
+		return p.closeTicket(c, event, "")
+	case customIDReopen:
+		return p.reopenTicket(c, event)
+	}
+
+	return nil
+}
+
+func (p *TicketPluginInstance) HandleModal(c plugin.Context, event *gateway.InteractionCreateEvent) error {
+	return nil
+}
+
+func (p *TicketPluginInstance) Close() error {
+	return nil
+}
+
+func (p *TicketPluginInstance) handleSetup(c plugin.Context, event *gateway.InteractionCreateEvent, options []discord.CommandInteractionOption) error {
+	config := TicketConfig{
+		Mode: TicketModeChannel,
+	}
+	for _, opt := range options {
+		switch opt.Name {
+		case "staff_role":
+			_ = opt.Value.UnmarshalTo(&config.StaffRoleID)
+		case "mode":
+			_ = opt.Value.UnmarshalTo(&config.Mode)
+		case "category":
+			_ = opt.Value.UnmarshalTo(&config.CategoryID)
+		case "log_channel":
+			_ = opt.Value.UnmarshalTo(&config.LogChannelID)
+		}
+	}
+
+	if err := setJSONValue(c, ticketConfigKey(event.GuildID), config); err != nil {
+		return fmt.Errorf("failed to save ticket config: %w", err)
+	}
+
+	return respond(c, event, fmt.Sprintf(
+		"Tickets have been set up for this server and can be handled by <@&%d>. Use `/tickets panel` to send the panel that members use to open a ticket.",
+		config.StaffRoleID,
+	))
+}
+
+func (p *TicketPluginInstance) handlePanel(c plugin.Context, event *gateway.InteractionCreateEvent, options []discord.CommandInteractionOption) error {
+	config, err := getTicketConfig(c, event.GuildID)
+	if err != nil {
+		return fmt.Errorf("failed to get ticket config: %w", err)
+	}
+
+	if config == nil {
+		return respond(c, event, "Tickets haven't been set up for this server yet, use `/tickets setup` first.")
+	}
+
+	channelID := event.ChannelID
+	for _, opt := range options {
+		if opt.Name == "channel" {
+			_ = opt.Value.UnmarshalTo(&channelID)
+		}
+	}
+
+	_, err = c.Discord().CreateMessage(c, channelID, api.SendMessageData{
+		Embeds: []discord.Embed{
+			{
+				Title:       p.configString(event.GuildID, configKeyPanelTitle, defaultPanelTitle),
+				Description: p.configString(event.GuildID, configKeyPanelDescription, defaultPanelDescription),
+				Color:       ticketColor,
+			},
+		},
+		Components: discord.ContainerComponents{
+			&discord.ActionRowComponent{
+				&discord.ButtonComponent{
+					CustomID: customIDOpen,
+					Label:    p.configString(event.GuildID, configKeyButtonLabel, defaultButtonLabel),
+					Style:    discord.PrimaryButtonStyle(),
+					Emoji:    &discord.ComponentEmoji{Name: "🎫"},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send ticket panel: %w", err)
+	}
+
+	return respond(c, event, fmt.Sprintf("The ticket panel has been sent to <#%d>.", channelID))
+}
+
+func (p *TicketPluginInstance) handleDisable(c plugin.Context, event *gateway.InteractionCreateEvent) error {
+	if err := c.DeleteValue(c, ticketConfigKey(event.GuildID)); err != nil {
+		return fmt.Errorf("failed to delete ticket config: %w", err)
+	}
+
+	return respond(c, event, "Tickets have been disabled for this server. Existing tickets can still be closed.")
+}
+
+func (p *TicketPluginInstance) configString(guildID discord.GuildID, key string, fallback string) string {
+	value := strings.TrimSpace(p.config.ForGuild(guildID).GetString(key))
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func respond(c plugin.Context, event *gateway.InteractionCreateEvent, content string) error {
+	_, err := c.Discord().CreateInteractionResponse(c, event.ID, event.Token, api.InteractionResponse{
+		Type: api.MessageInteractionWithSource,
+		Data: &api.InteractionResponseData{
+			Content: option.NewNullableString(content),
+			Flags:   discord.EphemeralMessage,
+		},
+	})
+	return err
+}
+
+// deferResponse acknowledges the interaction for actions that make multiple requests to Discord.
+// The response has to be sent with editResponse afterwards.
+func deferResponse(c plugin.Context, event *gateway.InteractionCreateEvent) error {
+	_, err := c.Discord().CreateInteractionResponse(c, event.ID, event.Token, api.InteractionResponse{
+		Type: api.DeferredMessageInteractionWithSource,
+		Data: &api.InteractionResponseData{
+			Flags: discord.EphemeralMessage,
+		},
+	})
+	return err
+}
+
+func editResponse(c plugin.Context, event *gateway.InteractionCreateEvent, content string) error {
+	_, err := c.Discord().EditInteractionResponse(c, event.AppID, event.Token, api.EditInteractionResponseData{
+		Content: option.NewNullableString(content),
+	})
+	return err
+}
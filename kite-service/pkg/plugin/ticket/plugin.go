@@ -0,0 +1,185 @@
+package ticket
+
+import (
+	"context"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/kitecloud/kite/kite-service/pkg/plugin"
+)
+
+type TicketPlugin struct {
+}
+
+func NewTicketPlugin() *TicketPlugin {
+	return &TicketPlugin{}
+}
+
+func (p *TicketPlugin) Instance(ctx context.Context, appID string, config plugin.ConfigValues) (plugin.PluginInstance, error) {
+	return &TicketPluginInstance{
+		appID:  appID,
+		config: config,
+	}, nil
+}
+
+func (p *TicketPlugin) ID() string {
+	return "ticket"
+}
+
+func (p *TicketPlugin) Metadata() plugin.Metadata {
+	return plugin.Metadata{
+		Name:        "Tickets",
+		Description: "Let members open private support tickets that your staff can claim and close.",
+		Icon:        "ticket",
+		Author:      "Merlin",
+	}
+}
+
+func (p *TicketPlugin) Config() plugin.Config {
+	maxTitleLength := 256.0
+	maxDescriptionLength := 4096.0
+	maxButtonLabelLength := 80.0
+
+	return plugin.Config{
+		Sections: []plugin.ConfigSection{
+			{
+				Name:        "Panel",
+				Description: "The message that members use to open a ticket.",
+				PerGuild:    true,
+				Fields: []plugin.ConfigField{
+					{
+						Key:         configKeyPanelTitle,
+						Type:        plugin.ConfigFieldTypeString,
+						Name:        "Title",
+						Description: "The title of the panel message.",
+						Default:     defaultPanelTitle,
+						Max:         &maxTitleLength,
+					},
+					{
+						Key:         configKeyPanelDescription,
+						Type:        plugin.ConfigFieldTypeString,
+						Name:        "Description",
+						Description: "The description of the panel message.",
+						Default:     defaultPanelDescription,
+						Max:         &maxDescriptionLength,
+					},
+					{
+						Key:         configKeyButtonLabel,
+						Type:        plugin.ConfigFieldTypeString,
+						Name:        "Button Label",
+						Description: "The label of the button that opens a ticket.",
+						Default:     defaultButtonLabel,
+						Max:         &maxButtonLabelLength,
+					},
+				},
+			},
+			{
+				Name:        "Tickets",
+				Description: "How tickets look once they have been opened.",
+				PerGuild:    true,
+				Fields: []plugin.ConfigField{
+					{
+						Key:         configKeyWelcomeMessage,
+						Type:        plugin.ConfigFieldTypeString,
+						Name:        "Welcome Message",
+						Description: "The message that is sent when a ticket is opened. {user} is replaced with a mention of the member.",
+						Default:     defaultWelcomeMessage,
+						Max:         &maxDescriptionLength,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (p *TicketPlugin) Events() []plugin.Event {
+	return []plugin.Event{}
+}
+
+func (p *TicketPlugin) Commands() []plugin.Command {
+	perms := discord.PermissionManageGuild
+
+	return []plugin.Command{
+		{
+			ID: "cmd_tickets",
+			Data: api.CreateCommandData{
+				Name:                     "tickets",
+				Description:              "Configure the ticket system for the current server",
+				DefaultMemberPermissions: &perms,
+				Options: discord.CommandOptions{
+					&discord.SubcommandOption{
+						OptionName:  "setup",
+						Description: "Set up the ticket system for the current server",
+						Options: []discord.CommandOptionValue{
+							&discord.RoleOption{
+								OptionName:  "staff_role",
+								Description: "The role that can see, claim and close tickets",
+								Required:    true,
+							},
+							&discord.StringOption{
+								OptionName:  "mode",
+								Description: "Whether tickets are private channels or private threads",
+								Choices: []discord.StringChoice{
+									{Name: "Channels", Value: string(TicketModeChannel)},
+									{Name: "Threads", Value: string(TicketModeThread)},
+								},
+							},
+							&discord.ChannelOption{
+								OptionName:   "category",
+								Description:  "The category to create ticket channels in",
+								ChannelTypes: []discord.ChannelType{discord.GuildCategory},
+							},
+							&discord.ChannelOption{
+								OptionName:   "log_channel",
+								Description:  "The channel to send transcripts of closed tickets to",
+								ChannelTypes: []discord.ChannelType{discord.GuildText},
+							},
+						},
+					},
+					&discord.SubcommandOption{
+						OptionName:  "panel",
+						Description: "Send the panel that members use to open a ticket",
+						Options: []discord.CommandOptionValue{
+							&discord.ChannelOption{
+								OptionName:   "channel",
+								Description:  "The channel to send the panel to, defaults to the current channel",
+								ChannelTypes: []discord.ChannelType{discord.GuildText},
+							},
+						},
+					},
+					&discord.SubcommandOption{
+						OptionName:  "disable",
+						Description: "Disable the ticket system for the current server",
+					},
+				},
+			},
+		},
+		{
+			ID: "cmd_ticket",
+			Data: api.CreateCommandData{
+				Name:        "ticket",
+				Description: "Manage the current ticket",
+				Options: discord.CommandOptions{
+					&discord.SubcommandOption{
+						OptionName:  "claim",
+						Description: "Claim the current ticket",
+					},
+					&discord.SubcommandOption{
+						OptionName:  "close",
+						Description: "Close the current ticket and save a transcript",
+						Options: []discord.CommandOptionValue{
+							&discord.StringOption{
+								OptionName:  "reason",
+								Description: "Why the ticket is being closed",
+							},
+						},
+					},
+					&discord.SubcommandOption{
+						OptionName:  "reopen",
+						Description: "Reopen the current ticket",
+					},
+				},
+			},
+		},
+	}
+}
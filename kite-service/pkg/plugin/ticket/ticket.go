@@ -0,0 +1,623 @@
+package ticket
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+	"github.com/diamondburned/arikawa/v3/utils/sendpart"
+	"github.com/kitecloud/kite/kite-service/pkg/plugin"
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+)
+
+const ticketColor = 0x5865f2
+
+// openTicketPending is stored as the open ticket of a member while the ticket is being created.
+var openTicketPending = thing.NewInt(0)
+
+// openTicketPendingTTL releases the reservation of a ticket that never finished being created.
+const openTicketPendingTTL = 5 * time.Minute
+
+// closingTicketTTL releases the reservation of a ticket that never finished being closed.
+const closingTicketTTL = 5 * time.Minute
+
+const ticketPermissions = discord.PermissionViewChannel |
+	discord.PermissionSendMessages |
+	discord.PermissionReadMessageHistory |
+	discord.PermissionAttachFiles |
+	discord.PermissionEmbedLinks
+
+type TicketMode string
+
+const (
+	TicketModeChannel TicketMode = "channel"
+	TicketModeThread  TicketMode = "thread"
+)
+
+type TicketStatus string
+
+const (
+	TicketStatusOpen   TicketStatus = "open"
+	TicketStatusClosed TicketStatus = "closed"
+)
+
+// TicketConfig is the ticket configuration of a guild.
+type TicketConfig struct {
+	StaffRoleID  discord.RoleID    `json:"staff_role_id"`
+	Mode         TicketMode        `json:"mode"`
+	CategoryID   discord.ChannelID `json:"category_id,omitempty"`
+	LogChannelID discord.ChannelID `json:"log_channel_id,omitempty"`
+}
+
+type Ticket struct {
+	Number            int               `json:"number"`
+	GuildID           discord.GuildID   `json:"guild_id"`
+	ChannelID         discord.ChannelID `json:"channel_id"`
+	Mode              TicketMode        `json:"mode"`
+	UserID            discord.UserID    `json:"user_id"`
+	StaffRoleID       discord.RoleID    `json:"staff_role_id"`
+	ClaimedBy         discord.UserID    `json:"claimed_by,omitempty"`
+	Status            TicketStatus      `json:"status"`
+	TranscriptAssetID string            `json:"transcript_asset_id,omitempty"`
+	CreatedAt         time.Time         `json:"created_at"`
+	ClosedAt          *time.Time        `json:"closed_at,omitempty"`
+}
+
+func (t *Ticket) Name() string {
+	return fmt.Sprintf("ticket-%04d", t.Number)
+}
+
+func (p *TicketPluginInstance) openTicket(c plugin.Context, event *gateway.InteractionCreateEvent) error {
+	config, err := getTicketConfig(c, event.GuildID)
+	if err != nil {
+		return fmt.Errorf("failed to get ticket config: %w", err)
+	}
+
+	if config == nil {
+		return respond(c, event, "Tickets haven't been set up for this server.")
+	}
+
+	userID := event.SenderID()
+	key := openTicketKey(event.GuildID, userID)
+
+	// Reserving the ticket first makes sure that a double click doesn't open two tickets
+	reserved, err := c.CompareAndSwapValue(c, key, thing.Null, openTicketPending, openTicketPendingTTL)
+	if err != nil {
+		return fmt.Errorf("failed to reserve open ticket: %w", err)
+	}
+
+	if !reserved {
+		openChannelID, err := c.GetValue(c, key)
+		if err != nil {
+			return fmt.Errorf("failed to get open ticket: %w", err)
+		}
+
+		if openChannelID.Int() == openTicketPending.Int() {
+			return respond(c, event, "Your ticket is already being opened.")
+		}
+		return respond(c, event, fmt.Sprintf("You already have an open ticket: <#%d>", openChannelID.Int()))
+	}
+
+	if err := deferResponse(c, event); err != nil {
+		_ = c.DeleteValue(c, key)
+		return fmt.Errorf("failed to defer response: %w", err)
+	}
+
+	channelID, err := p.createTicket(c, event, config)
+	if err != nil {
+		_ = c.DeleteValue(c, key)
+		return failDeferred(c, event, "Failed to open your ticket, please try again later.", err)
+	}
+
+	return editResponse(c, event, fmt.Sprintf("Your ticket has been opened: <#%d>", channelID))
+}
+
+// createTicket creates the channel or thread of a new ticket and stores it as the open ticket of the member.
+func (p *TicketPluginInstance) createTicket(c plugin.Context, event *gateway.InteractionCreateEvent, config *TicketConfig) (discord.ChannelID, error) {
+	userID := event.SenderID()
+
+	number, err := c.UpdateValue(c, ticketCounterKey(event.GuildID), provider.VariableOperationIncrement, thing.NewInt(1))
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment ticket counter: %w", err)
+	}
+
+	ticket := &Ticket{
+		Number:      int(number.Int()),
+		GuildID:     event.GuildID,
+		Mode:        config.Mode,
+		UserID:      userID,
+		StaffRoleID: config.StaffRoleID,
+		Status:      TicketStatusOpen,
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	var channel *discord.Channel
+	if ticket.Mode == TicketModeThread {
+		channel, err = c.Discord().StartThreadWithoutMessage(c, event.ChannelID, api.StartThreadData{
+			Name:                ticket.Name(),
+			Type:                discord.GuildPrivateThread,
+			AutoArchiveDuration: discord.SevenDaysArchive,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to create ticket thread: %w", err)
+		}
+
+		if err := c.Discord().AddThreadMember(c, channel.ID, userID); err != nil {
+			return 0, deleteTicketChannel(c, channel.ID, fmt.Errorf("failed to add member to ticket thread: %w", err))
+		}
+	} else {
+		channel, err = c.Discord().CreateChannel(c, event.GuildID, api.CreateChannelData{
+			Name:       ticket.Name(),
+			Type:       discord.GuildText,
+			CategoryID: config.CategoryID,
+			Overwrites: ticketOverwrites(ticket, event.AppID),
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to create ticket channel: %w", err)
+		}
+	}
+
+	ticket.ChannelID = channel.ID
+	if err := saveTicket(c, ticket); err != nil {
+		return 0, deleteTicketChannel(c, channel.ID, err)
+	}
+
+	_, err = c.UpdateValue(c, openTicketKey(event.GuildID, userID), provider.VariableOperationOverwrite, thing.NewInt(int64(channel.ID)))
+	if err != nil {
+		return 0, deleteTicketChannel(c, channel.ID, fmt.Errorf("failed to save open ticket: %w", err))
+	}
+
+	welcome := strings.ReplaceAll(
+		p.configString(event.GuildID, configKeyWelcomeMessage, defaultWelcomeMessage),
+		"{user}",
+		fmt.Sprintf("<@%d>", userID),
+	)
+
+	// Mentioning the staff role also adds the staff members to private threads
+	_, err = c.Discord().CreateMessage(c, channel.ID, api.SendMessageData{
+		Content: fmt.Sprintf("<@%d> <@&%d>", userID, ticket.StaffRoleID),
+		Embeds: []discord.Embed{
+			{
+				Title:       fmt.Sprintf("Ticket #%04d", ticket.Number),
+				Description: welcome,
+				Color:       ticketColor,
+			},
+		},
+		Components: ticketComponents(ticket),
+		AllowedMentions: &api.AllowedMentions{
+			Users: []discord.UserID{userID},
+			Roles: []discord.RoleID{ticket.StaffRoleID},
+		},
+	})
+	if err != nil {
+		return 0, deleteTicketChannel(c, channel.ID, fmt.Errorf("failed to send welcome message: %w", err))
+	}
+
+	return channel.ID, nil
+}
+
+// deleteTicketChannel deletes the channel or thread of a ticket that couldn't be opened,
+// so it isn't left behind without a ticket that could close it. It returns the original error.
+func deleteTicketChannel(c plugin.Context, channelID discord.ChannelID, err error) error {
+	if deleteErr := c.DeleteValue(c, ticketKey(channelID)); deleteErr != nil {
+		err = errors.Join(err, fmt.Errorf("failed to delete ticket: %w", deleteErr))
+	}
+	if deleteErr := c.Discord().DeleteChannel(c, channelID, "Failed to open ticket"); deleteErr != nil {
+		err = errors.Join(err, fmt.Errorf("failed to delete ticket channel: %w", deleteErr))
+	}
+	return err
+}
+
+func (p *TicketPluginInstance) claimTicket(c plugin.Context, event *gateway.InteractionCreateEvent) error {
+	ticket, err := getTicket(c, event.ChannelID)
+	if err != nil {
+		return fmt.Errorf("failed to get ticket: %w", err)
+	}
+
+	if ticket == nil {
+		return respond(c, event, "This channel isn't a ticket.")
+	}
+
+	if !isStaff(event, ticket) {
+		return respond(c, event, "Only staff can claim tickets.")
+	}
+
+	if ticket.Status != TicketStatusOpen {
+		return respond(c, event, "This ticket is closed.")
+	}
+
+	if ticket.ClaimedBy.IsValid() {
+		return respond(c, event, fmt.Sprintf("This ticket has already been claimed by <@%d>.", ticket.ClaimedBy))
+	}
+
+	ticket.ClaimedBy = event.SenderID()
+	if err := saveTicket(c, ticket); err != nil {
+		return err
+	}
+
+	return announce(c, event, fmt.Sprintf("<@%d> has claimed this ticket and will take care of it.", ticket.ClaimedBy))
+}
+
+func (p *TicketPluginInstance) closeTicket(c plugin.Context, event *gateway.InteractionCreateEvent, reason string) error {
+	ticket, err := getTicket(c, event.ChannelID)
+	if err != nil {
+		return fmt.Errorf("failed to get ticket: %w", err)
+	}
+
+	if ticket == nil {
+		return respond(c, event, "This channel isn't a ticket.")
+	}
+
+	userID := event.SenderID()
+	if userID != ticket.UserID && !isStaff(event, ticket) {
+		return respond(c, event, "Only staff and the member who opened the ticket can close it.")
+	}
+
+	if ticket.Status != TicketStatusOpen {
+		return respond(c, event, "This ticket is already closed.")
+	}
+
+	// Reserving the close first makes sure that a double click doesn't archive the ticket twice
+	key := closingTicketKey(ticket.ChannelID)
+	reserved, err := c.CompareAndSwapValue(c, key, thing.Null, thing.NewBool(true), closingTicketTTL)
+	if err != nil {
+		return fmt.Errorf("failed to reserve ticket close: %w", err)
+	}
+
+	if !reserved {
+		return respond(c, event, "This ticket is already being closed.")
+	}
+	defer func() {
+		_ = c.DeleteValue(c, key)
+	}()
+
+	// Another click may have closed the ticket since it has been read
+	ticket, err = getTicket(c, event.ChannelID)
+	if err != nil {
+		return fmt.Errorf("failed to get ticket: %w", err)
+	}
+
+	if ticket == nil || ticket.Status != TicketStatusOpen {
+		return respond(c, event, "This ticket is already closed.")
+	}
+
+	if err := deferResponse(c, event); err != nil {
+		return fmt.Errorf("failed to defer response: %w", err)
+	}
+
+	if err := p.archiveTicket(c, event, ticket, reason); err != nil {
+		return failDeferred(c, event, "Failed to close the ticket, please try again later.", err)
+	}
+
+	return editResponse(c, event, "The ticket has been closed and a transcript has been saved.")
+}
+
+// archiveTicket saves the transcript of a ticket, closes it and sends the transcript to the log channel.
+func (p *TicketPluginInstance) archiveTicket(c plugin.Context, event *gateway.InteractionCreateEvent, ticket *Ticket, reason string) error {
+	userID := event.SenderID()
+
+	messages, err := c.Discord().ChannelMessages(c, ticket.ChannelID, maxTranscriptMessages)
+	if err != nil {
+		return fmt.Errorf("failed to get ticket messages: %w", err)
+	}
+
+	now := time.Now().UTC()
+	ticket.Status = TicketStatusClosed
+	ticket.ClosedAt = &now
+
+	transcript := renderTranscript(ticket, messages)
+	transcriptName := fmt.Sprintf("transcript-%s.txt", ticket.Name())
+
+	asset, err := c.Asset().CreateAsset(c, transcriptName, "text/plain; charset=utf-8", transcript)
+	if err != nil {
+		return fmt.Errorf("failed to save transcript: %w", err)
+	}
+	ticket.TranscriptAssetID = asset.ID
+
+	if err := saveTicket(c, ticket); err != nil {
+		return err
+	}
+
+	if err := c.DeleteValue(c, openTicketKey(ticket.GuildID, ticket.UserID)); err != nil {
+		return fmt.Errorf("failed to delete open ticket: %w", err)
+	}
+
+	description := fmt.Sprintf("This ticket has been closed by <@%d>.", userID)
+	if reason != "" {
+		description += "\n\n**Reason:** " + reason
+	}
+
+	_, err = c.Discord().CreateMessage(c, ticket.ChannelID, api.SendMessageData{
+		Embeds: []discord.Embed{
+			{
+				Title:       "Ticket Closed",
+				Description: description,
+				Color:       ticketColor,
+			},
+		},
+		Components: ticketComponents(ticket),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send close message: %w", err)
+	}
+
+	if err := setTicketAccess(c, ticket, event.AppID); err != nil {
+		return err
+	}
+
+	config, err := getTicketConfig(c, ticket.GuildID)
+	if err != nil {
+		return fmt.Errorf("failed to get ticket config: %w", err)
+	}
+
+	if config != nil && config.LogChannelID.IsValid() {
+		_, err = c.Discord().CreateMessage(c, config.LogChannelID, api.SendMessageData{
+			Embeds: []discord.Embed{
+				{
+					Title:       fmt.Sprintf("Ticket #%04d Closed", ticket.Number),
+					Description: description,
+					Color:       ticketColor,
+					Fields: []discord.EmbedField{
+						{Name: "Opened By", Value: fmt.Sprintf("<@%d>", ticket.UserID), Inline: true},
+						{Name: "Claimed By", Value: claimedByText(ticket), Inline: true},
+						{Name: "Channel", Value: fmt.Sprintf("<#%d>", ticket.ChannelID), Inline: true},
+					},
+				},
+			},
+			Files: []sendpart.File{
+				{Name: transcriptName, Reader: bytes.NewReader(transcript)},
+			},
+			AllowedMentions: &api.AllowedMentions{},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to send transcript to log channel: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (p *TicketPluginInstance) reopenTicket(c plugin.Context, event *gateway.InteractionCreateEvent) error {
+	ticket, err := getTicket(c, event.ChannelID)
+	if err != nil {
+		return fmt.Errorf("failed to get ticket: %w", err)
+	}
+
+	if ticket == nil {
+		return respond(c, event, "This channel isn't a ticket.")
+	}
+
+	if !isStaff(event, ticket) {
+		return respond(c, event, "Only staff can reopen tickets.")
+	}
+
+	if ticket.Status != TicketStatusClosed {
+		return respond(c, event, "This ticket is already open.")
+	}
+
+	key := openTicketKey(ticket.GuildID, ticket.UserID)
+	reserved, err := c.CompareAndSwapValue(c, key, thing.Null, thing.NewInt(int64(ticket.ChannelID)), 0)
+	if err != nil {
+		return fmt.Errorf("failed to save open ticket: %w", err)
+	}
+
+	if !reserved {
+		return respond(c, event, "The member already has another open ticket.")
+	}
+
+	ticket.Status = TicketStatusOpen
+	ticket.ClosedAt = nil
+	if err := saveTicket(c, ticket); err != nil {
+		_ = c.DeleteValue(c, key)
+		return err
+	}
+
+	if err := setTicketAccess(c, ticket, event.AppID); err != nil {
+		return err
+	}
+
+	return announce(c, event, fmt.Sprintf("This ticket has been reopened by <@%d>.", event.SenderID()))
+}
+
+// setTicketAccess gives the member who opened the ticket access to it while it's open and takes it away when it's closed.
+func setTicketAccess(c plugin.Context, ticket *Ticket, appID discord.AppID) error {
+	if ticket.Mode == TicketModeThread {
+		locked := option.False
+		if ticket.Status == TicketStatusClosed {
+			locked = option.True
+		}
+
+		err := c.Discord().EditChannel(c, ticket.ChannelID, api.ModifyChannelData{
+			Locked: locked,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update ticket thread: %w", err)
+		}
+
+		if ticket.Status == TicketStatusClosed {
+			err = c.Discord().RemoveThreadMember(c, ticket.ChannelID, ticket.UserID)
+		} else {
+			err = c.Discord().AddThreadMember(c, ticket.ChannelID, ticket.UserID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update ticket thread member: %w", err)
+		}
+
+		return nil
+	}
+
+	overwrites := ticketOverwrites(ticket, appID)
+	err := c.Discord().EditChannel(c, ticket.ChannelID, api.ModifyChannelData{
+		Overwrites: &overwrites,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update ticket channel: %w", err)
+	}
+
+	return nil
+}
+
+func ticketOverwrites(ticket *Ticket, appID discord.AppID) []discord.Overwrite {
+	member := discord.Overwrite{
+		ID:    discord.Snowflake(ticket.UserID),
+		Type:  discord.OverwriteMember,
+		Allow: ticketPermissions,
+	}
+	if ticket.Status == TicketStatusClosed {
+		member.Allow = 0
+		member.Deny = discord.PermissionViewChannel
+	}
+
+	return []discord.Overwrite{
+		{
+			// The ID of the @everyone role is the same as the guild ID
+			ID:   discord.Snowflake(ticket.GuildID),
+			Type: discord.OverwriteRole,
+			Deny: discord.PermissionViewChannel,
+		},
+		{
+			ID:    discord.Snowflake(ticket.StaffRoleID),
+			Type:  discord.OverwriteRole,
+			Allow: ticketPermissions,
+		},
+		{
+			// The bot user has the same ID as the application
+			ID:    discord.Snowflake(appID),
+			Type:  discord.OverwriteMember,
+			Allow: ticketPermissions | discord.PermissionManageChannels,
+		},
+		member,
+	}
+}
+
+func ticketComponents(ticket *Ticket) discord.ContainerComponents {
+	if ticket.Status == TicketStatusClosed {
+		return discord.ContainerComponents{
+			&discord.ActionRowComponent{
+				&discord.ButtonComponent{
+					CustomID: customIDReopen,
+					Label:    "Reopen",
+					Style:    discord.SuccessButtonStyle(),
+				},
+			},
+		}
+	}
+
+	return discord.ContainerComponents{
+		&discord.ActionRowComponent{
+			&discord.ButtonComponent{
+				CustomID: customIDClaim,
+				Label:    "Claim",
+				Style:    discord.SecondaryButtonStyle(),
+			},
+			&discord.ButtonComponent{
+				CustomID: customIDClose,
+				Label:    "Close",
+				Style:    discord.DangerButtonStyle(),
+			},
+		},
+	}
+}
+
+func claimedByText(ticket *Ticket) string {
+	if !ticket.ClaimedBy.IsValid() {
+		return "Nobody"
+	}
+	return fmt.Sprintf("<@%d>", ticket.ClaimedBy)
+}
+
+func isStaff(event *gateway.InteractionCreateEvent, ticket *Ticket) bool {
+	return event.Member != nil && slices.Contains(event.Member.RoleIDs, ticket.StaffRoleID)
+}
+
+// failDeferred tells the member that the action has failed, so they aren't left with a deferred response forever.
+// It returns the original error.
+func failDeferred(c plugin.Context, event *gateway.InteractionCreateEvent, content string, err error) error {
+	if editErr := editResponse(c, event, content); editErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to edit response: %w", editErr))
+	}
+	return err
+}
+
+func announce(c plugin.Context, event *gateway.InteractionCreateEvent, content string) error {
+	_, err := c.Discord().CreateInteractionResponse(c, event.ID, event.Token, api.InteractionResponse{
+		Type: api.MessageInteractionWithSource,
+		Data: &api.InteractionResponseData{
+			Content:         option.NewNullableString(content),
+			AllowedMentions: &api.AllowedMentions{},
+		},
+	})
+	return err
+}
+
+func getTicketConfig(c plugin.Context, guildID discord.GuildID) (*TicketConfig, error) {
+	return getJSONValue[TicketConfig](c, ticketConfigKey(guildID))
+}
+
+func getTicket(c plugin.Context, channelID discord.ChannelID) (*Ticket, error) {
+	return getJSONValue[Ticket](c, ticketKey(channelID))
+}
+
+func saveTicket(c plugin.Context, ticket *Ticket) error {
+	if err := setJSONValue(c, ticketKey(ticket.ChannelID), ticket); err != nil {
+		return fmt.Errorf("failed to save ticket: %w", err)
+	}
+	return nil
+}
+
+func getJSONValue[T any](c plugin.Context, key string) (*T, error) {
+	raw, err := c.GetValue(c, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if raw == thing.Null {
+		return nil, nil
+	}
+
+	var v T
+	if err := json.Unmarshal([]byte(raw.String()), &v); err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func setJSONValue(c plugin.Context, key string, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.UpdateValue(c, key, provider.VariableOperationOverwrite, thing.NewString(string(raw)))
+	return err
+}
+
+func ticketConfigKey(guildID discord.GuildID) string {
+	return "ticket-config:" + guildID.String()
+}
+
+func ticketCounterKey(guildID discord.GuildID) string {
+	return "ticket-counter:" + guildID.String()
+}
+
+func ticketKey(channelID discord.ChannelID) string {
+	return "ticket:" + channelID.String()
+}
+
+func openTicketKey(guildID discord.GuildID, userID discord.UserID) string {
+	return "ticket-open:" + guildID.String() + ":" + userID.String()
+}
+
+func closingTicketKey(channelID discord.ChannelID) string {
+	return "ticket-closing:" + channelID.String()
+}
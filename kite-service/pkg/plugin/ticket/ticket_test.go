@@ -0,0 +1,291 @@
+package ticket
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testGuildID     discord.GuildID   = 1
+	testChannelID   discord.ChannelID = 2
+	testUserID      discord.UserID    = 3
+	testStaffRoleID discord.RoleID    = 4
+	testStaffUserID discord.UserID    = 5
+)
+
+// testDiscordProvider records the requests of the plugin that matter for the tests.
+type testDiscordProvider struct {
+	provider.MockDiscordProvider
+
+	createChannelErr error
+	createMessageErr error
+
+	channels        []api.CreateChannelData
+	deletedChannels []discord.ChannelID
+	responses       []api.InteractionResponse
+	edits           []string
+}
+
+func (p *testDiscordProvider) CreateChannel(ctx context.Context, guildID discord.GuildID, data api.CreateChannelData) (*discord.Channel, error) {
+	if p.createChannelErr != nil {
+		return nil, p.createChannelErr
+	}
+
+	p.channels = append(p.channels, data)
+	return &discord.Channel{ID: discord.ChannelID(100 + len(p.channels)), GuildID: guildID, Name: data.Name}, nil
+}
+
+func (p *testDiscordProvider) DeleteChannel(ctx context.Context, channelID discord.ChannelID, reason api.AuditLogReason) error {
+	p.deletedChannels = append(p.deletedChannels, channelID)
+	return nil
+}
+
+func (p *testDiscordProvider) CreateMessage(ctx context.Context, channelID discord.ChannelID, message api.SendMessageData) (*discord.Message, error) {
+	return nil, p.createMessageErr
+}
+
+func (p *testDiscordProvider) CreateInteractionResponse(ctx context.Context, interactionID discord.InteractionID, interactionToken string, response api.InteractionResponse) (*provider.InteractionResponseResource, error) {
+	p.responses = append(p.responses, response)
+	return nil, nil
+}
+
+func (p *testDiscordProvider) EditInteractionResponse(ctx context.Context, applicationID discord.AppID, token string, response api.EditInteractionResponseData) (*discord.Message, error) {
+	p.edits = append(p.edits, response.Content.Val)
+	return nil, nil
+}
+
+func (p *testDiscordProvider) lastResponse() string {
+	if len(p.responses) == 0 {
+		return ""
+	}
+	return p.responses[len(p.responses)-1].Data.Content.Val
+}
+
+type testContext struct {
+	context.Context
+	*provider.MockValueProvider
+
+	discord *testDiscordProvider
+	asset   *provider.MockAssetProvider
+}
+
+func (c *testContext) Discord() provider.DiscordProvider {
+	return c.discord
+}
+
+func (c *testContext) Asset() provider.AssetProvider {
+	return c.asset
+}
+
+func newTestContext(t *testing.T) *testContext {
+	t.Helper()
+
+	c := &testContext{
+		Context:           context.Background(),
+		MockValueProvider: &provider.MockValueProvider{},
+		discord:           &testDiscordProvider{},
+		asset:             &provider.MockAssetProvider{},
+	}
+
+	err := setJSONValue(c, ticketConfigKey(testGuildID), TicketConfig{
+		StaffRoleID: testStaffRoleID,
+		Mode:        TicketModeChannel,
+	})
+	require.NoError(t, err)
+
+	return c
+}
+
+func testInteraction(channelID discord.ChannelID, userID discord.UserID, roleIDs ...discord.RoleID) *gateway.InteractionCreateEvent {
+	return &gateway.InteractionCreateEvent{
+		InteractionEvent: discord.InteractionEvent{
+			ID:        1,
+			AppID:     10,
+			GuildID:   testGuildID,
+			ChannelID: channelID,
+			Token:     "token",
+			Member: &discord.Member{
+				User:    discord.User{ID: userID},
+				RoleIDs: roleIDs,
+			},
+			Data: &discord.ButtonInteraction{CustomID: customIDOpen},
+		},
+	}
+}
+
+func TestOpenTicket(t *testing.T) {
+	c := newTestContext(t)
+	p := &TicketPluginInstance{}
+
+	require.NoError(t, p.openTicket(c, testInteraction(testChannelID, testUserID)))
+
+	require.Len(t, c.discord.channels, 1)
+	assert.Equal(t, "ticket-0001", c.discord.channels[0].Name)
+	assert.Equal(t, api.DeferredMessageInteractionWithSource, c.discord.responses[0].Type)
+	assert.Equal(t, []string{"Your ticket has been opened: <#101>"}, c.discord.edits)
+
+	openChannelID, err := c.GetValue(c, openTicketKey(testGuildID, testUserID))
+	require.NoError(t, err)
+	assert.Equal(t, int64(101), openChannelID.Int())
+	assert.NotContains(t, c.ExpiresAt, openTicketKey(testGuildID, testUserID), "the open ticket must not expire")
+
+	ticket, err := getTicket(c, 101)
+	require.NoError(t, err)
+	require.NotNil(t, ticket)
+	assert.Equal(t, 1, ticket.Number)
+	assert.Equal(t, testUserID, ticket.UserID)
+	assert.Equal(t, TicketStatusOpen, ticket.Status)
+}
+
+func TestOpenTicketLimit(t *testing.T) {
+	c := newTestContext(t)
+	p := &TicketPluginInstance{}
+
+	require.NoError(t, p.openTicket(c, testInteraction(testChannelID, testUserID)))
+	require.NoError(t, p.openTicket(c, testInteraction(testChannelID, testUserID)))
+
+	assert.Len(t, c.discord.channels, 1, "members can only have one open ticket")
+	assert.Equal(t, "You already have an open ticket: <#101>", c.discord.lastResponse())
+
+	// Other members can still open their own ticket
+	require.NoError(t, p.openTicket(c, testInteraction(testChannelID, testStaffUserID)))
+	assert.Len(t, c.discord.channels, 2)
+}
+
+func TestOpenTicketWhilePending(t *testing.T) {
+	c := newTestContext(t)
+	p := &TicketPluginInstance{}
+
+	// Another click is still creating the ticket
+	swapped, err := c.CompareAndSwapValue(c, openTicketKey(testGuildID, testUserID), thing.Null, openTicketPending, openTicketPendingTTL)
+	require.NoError(t, err)
+	require.True(t, swapped)
+
+	require.NoError(t, p.openTicket(c, testInteraction(testChannelID, testUserID)))
+
+	assert.Empty(t, c.discord.channels)
+	assert.Equal(t, "Your ticket is already being opened.", c.discord.lastResponse())
+}
+
+func TestOpenTicketFailure(t *testing.T) {
+	c := newTestContext(t)
+	c.discord.createChannelErr = errors.New("missing permissions")
+	p := &TicketPluginInstance{}
+
+	err := p.openTicket(c, testInteraction(testChannelID, testUserID))
+	assert.ErrorContains(t, err, "missing permissions")
+
+	// The deferred response is edited, so the member isn't left waiting
+	assert.Equal(t, []string{"Failed to open your ticket, please try again later."}, c.discord.edits)
+
+	// The reservation is released, so the member can try again
+	openChannelID, err := c.GetValue(c, openTicketKey(testGuildID, testUserID))
+	require.NoError(t, err)
+	assert.Equal(t, thing.Null, openChannelID)
+
+	c.discord.createChannelErr = nil
+	require.NoError(t, p.openTicket(c, testInteraction(testChannelID, testUserID)))
+	assert.Len(t, c.discord.channels, 1)
+}
+
+func TestOpenTicketWelcomeFailure(t *testing.T) {
+	c := newTestContext(t)
+	c.discord.createMessageErr = errors.New("missing access")
+	p := &TicketPluginInstance{}
+
+	err := p.openTicket(c, testInteraction(testChannelID, testUserID))
+	assert.ErrorContains(t, err, "missing access")
+
+	// The channel is deleted, so it isn't left behind without a ticket
+	assert.Equal(t, []discord.ChannelID{101}, c.discord.deletedChannels)
+
+	ticket, err := getTicket(c, 101)
+	require.NoError(t, err)
+	assert.Nil(t, ticket)
+
+	openChannelID, err := c.GetValue(c, openTicketKey(testGuildID, testUserID))
+	require.NoError(t, err)
+	assert.Equal(t, thing.Null, openChannelID)
+}
+
+func TestCloseTicket(t *testing.T) {
+	c := newTestContext(t)
+	p := &TicketPluginInstance{}
+
+	require.NoError(t, p.openTicket(c, testInteraction(testChannelID, testUserID)))
+
+	// Other members can't close the ticket
+	require.NoError(t, p.closeTicket(c, testInteraction(101, 999), ""))
+	assert.Equal(t, "Only staff and the member who opened the ticket can close it.", c.discord.lastResponse())
+
+	require.NoError(t, p.closeTicket(c, testInteraction(101, testStaffUserID, testStaffRoleID), "resolved"))
+	assert.Equal(t, "The ticket has been closed and a transcript has been saved.", c.discord.edits[len(c.discord.edits)-1])
+
+	ticket, err := getTicket(c, 101)
+	require.NoError(t, err)
+	assert.Equal(t, TicketStatusClosed, ticket.Status)
+	assert.NotNil(t, ticket.ClosedAt)
+
+	openChannelID, err := c.GetValue(c, openTicketKey(testGuildID, testUserID))
+	require.NoError(t, err)
+	assert.Equal(t, thing.Null, openChannelID)
+
+	closing, err := c.GetValue(c, closingTicketKey(101))
+	require.NoError(t, err)
+	assert.Equal(t, thing.Null, closing, "the close reservation is released")
+
+	require.NoError(t, p.closeTicket(c, testInteraction(101, testUserID), ""))
+	assert.Equal(t, "This ticket is already closed.", c.discord.lastResponse())
+
+	// After closing the ticket the member can open a new one
+	require.NoError(t, p.openTicket(c, testInteraction(testChannelID, testUserID)))
+	assert.Len(t, c.discord.channels, 2)
+	assert.Equal(t, "ticket-0002", c.discord.channels[1].Name)
+}
+
+func TestCloseTicketWhileClosing(t *testing.T) {
+	c := newTestContext(t)
+	p := &TicketPluginInstance{}
+
+	require.NoError(t, p.openTicket(c, testInteraction(testChannelID, testUserID)))
+
+	// Another click is still closing the ticket
+	swapped, err := c.CompareAndSwapValue(c, closingTicketKey(101), thing.Null, thing.NewBool(true), closingTicketTTL)
+	require.NoError(t, err)
+	require.True(t, swapped)
+
+	require.NoError(t, p.closeTicket(c, testInteraction(101, testUserID), ""))
+	assert.Equal(t, "This ticket is already being closed.", c.discord.lastResponse())
+
+	ticket, err := getTicket(c, 101)
+	require.NoError(t, err)
+	assert.Equal(t, TicketStatusOpen, ticket.Status)
+	assert.Empty(t, ticket.TranscriptAssetID, "no transcript is written")
+}
+
+func TestReopenTicketLimit(t *testing.T) {
+	c := newTestContext(t)
+	p := &TicketPluginInstance{}
+	staff := testInteraction(101, testStaffUserID, testStaffRoleID)
+
+	require.NoError(t, p.openTicket(c, testInteraction(testChannelID, testUserID)))
+	require.NoError(t, p.closeTicket(c, staff, ""))
+	require.NoError(t, p.openTicket(c, testInteraction(testChannelID, testUserID)))
+
+	// The first ticket can't be reopened while the member has another open ticket
+	require.NoError(t, p.reopenTicket(c, staff))
+	assert.Equal(t, "The member already has another open ticket.", c.discord.lastResponse())
+
+	ticket, err := getTicket(c, 101)
+	require.NoError(t, err)
+	assert.Equal(t, TicketStatusClosed, ticket.Status)
+}
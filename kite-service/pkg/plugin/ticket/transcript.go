@@ -0,0 +1,69 @@
+package ticket
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+)
+
+const maxTranscriptMessages = 1000
+
+const transcriptTimeFormat = "2006-01-02 15:04:05"
+
+// renderTranscript renders the messages of a ticket as plain text.
+// The messages are expected newest first, as they are returned by Discord.
+func renderTranscript(ticket *Ticket, messages []discord.Message) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Transcript of ticket #%04d\n", ticket.Number)
+	fmt.Fprintf(&b, "Opened by %d at %s UTC\n", ticket.UserID, ticket.CreatedAt.UTC().Format(transcriptTimeFormat))
+	if ticket.ClaimedBy.IsValid() {
+		fmt.Fprintf(&b, "Claimed by %d\n", ticket.ClaimedBy)
+	}
+	if ticket.ClosedAt != nil {
+		fmt.Fprintf(&b, "Closed at %s UTC\n", ticket.ClosedAt.UTC().Format(transcriptTimeFormat))
+	}
+	if len(messages) >= maxTranscriptMessages {
+		fmt.Fprintf(&b, "Only the last %d messages are included\n", maxTranscriptMessages)
+	}
+
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg := messages[i]
+
+		fmt.Fprintf(
+			&b,
+			"\n[%s] %s (%d)\n",
+			msg.Timestamp.Time().UTC().Format(transcriptTimeFormat),
+			msg.Author.Username,
+			msg.Author.ID,
+		)
+
+		if msg.Content != "" {
+			writeIndented(&b, msg.Content)
+		}
+
+		for _, embed := range msg.Embeds {
+			if embed.Title != "" {
+				writeIndented(&b, "Embed: "+embed.Title)
+			}
+			if embed.Description != "" {
+				writeIndented(&b, embed.Description)
+			}
+		}
+
+		for _, attachment := range msg.Attachments {
+			writeIndented(&b, "Attachment: "+attachment.URL)
+		}
+	}
+
+	return []byte(b.String())
+}
+
+func writeIndented(b *strings.Builder, s string) {
+	for _, line := range strings.Split(s, "\n") {
+		b.WriteString("    ")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+}
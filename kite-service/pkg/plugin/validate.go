@@ -0,0 +1,226 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"unicode/utf8"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Validate checks the values against the fields of the config.
+// The returned error is a validation.Errors that maps the keys of invalid fields to their errors.
+// Values for unknown keys are ignored, so configs that were saved for an older version of a plugin stay valid.
+func (c Config) Validate(values ConfigValues) error {
+	errs := validation.Errors{}
+	perGuildFields := make(map[string]ConfigField)
+
+	for _, section := range c.Sections {
+		for _, field := range section.Fields {
+			if section.PerGuild {
+				perGuildFields[field.Key] = field
+			}
+
+			value := values[field.Key]
+			if isNullValue(value) {
+				if field.Required && field.Default == nil {
+					errs[field.Key] = validation.ErrRequired
+				}
+				continue
+			}
+
+			if err := field.validate(value); err != nil {
+				errs[field.Key] = err
+			}
+		}
+	}
+
+	if raw := values[ConfigKeyGuilds]; !isNullValue(raw) {
+		if err := validateGuildOverrides(raw, perGuildFields); err != nil {
+			errs[ConfigKeyGuilds] = err
+		}
+	}
+
+	return errs.Filter()
+}
+
+// ValidateSchema checks the fields of the config itself. Keys must be unique across all sections
+// and can't be reserved keys like ConfigKeyGuilds, which would clash with the stored values.
+func (c Config) ValidateSchema() error {
+	keys := make(map[string]struct{})
+	for _, field := range c.Fields() {
+		if field.Key == "" {
+			return errors.New("config field without a key")
+		}
+		if field.Key == ConfigKeyGuilds {
+			return fmt.Errorf("config field key %q is reserved", field.Key)
+		}
+		if _, ok := keys[field.Key]; ok {
+			return fmt.Errorf("duplicate config field key %q", field.Key)
+		}
+		keys[field.Key] = struct{}{}
+	}
+	return nil
+}
+
+func validateGuildOverrides(raw json.RawMessage, fields map[string]ConfigField) error {
+	var guilds map[string]ConfigValues
+	if err := json.Unmarshal(raw, &guilds); err != nil {
+		return errors.New("must be an object of guild IDs to config values")
+	}
+
+	errs := validation.Errors{}
+	for guildID, overrides := range guilds {
+		if _, err := discord.ParseSnowflake(guildID); err != nil {
+			errs[guildID] = errors.New("must be a guild ID")
+			continue
+		}
+
+		guildErrs := validation.Errors{}
+		for key, value := range overrides {
+			field, ok := fields[key]
+			if !ok {
+				guildErrs[key] = errors.New("can't be overridden per guild")
+				continue
+			}
+
+			if isNullValue(value) {
+				continue
+			}
+
+			if err := field.validate(value); err != nil {
+				guildErrs[key] = err
+			}
+		}
+
+		if len(guildErrs) > 0 {
+			errs[guildID] = guildErrs
+		}
+	}
+
+	return errs.Filter()
+}
+
+func (f ConfigField) validate(raw json.RawMessage) error {
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return errors.New("must be valid JSON")
+	}
+
+	if f.Type == ConfigFieldTypeArray {
+		items, ok := v.([]any)
+		if !ok {
+			return errors.New("must be an array")
+		}
+
+		if err := validateRange(float64(len(items)), f.Min, f.Max, "must have at least %v items", "must have at most %v items"); err != nil {
+			return err
+		}
+
+		for i, item := range items {
+			if err := validateValue(f.ItemType, item); err != nil {
+				return fmt.Errorf("item %d %w", i+1, err)
+			}
+			if err := f.validateOption(item); err != nil {
+				return fmt.Errorf("item %d %w", i+1, err)
+			}
+		}
+
+		return nil
+	}
+
+	if err := validateValue(f.Type, v); err != nil {
+		return err
+	}
+
+	switch f.Type {
+	case ConfigFieldTypeString:
+		length := float64(utf8.RuneCountInString(v.(string)))
+		if err := validateRange(length, f.Min, f.Max, "must be at least %v characters long", "must be at most %v characters long"); err != nil {
+			return err
+		}
+	case ConfigFieldTypeInt, ConfigFieldTypeFloat:
+		if err := validateRange(v.(float64), f.Min, f.Max, "must be at least %v", "must be at most %v"); err != nil {
+			return err
+		}
+	}
+
+	return f.validateOption(v)
+}
+
+func (f ConfigField) validateOption(v any) error {
+	if len(f.Options) == 0 {
+		return nil
+	}
+
+	// Options are compared by their JSON representation so that numbers match regardless of their Go type.
+	raw, _ := json.Marshal(v)
+	for _, option := range f.Options {
+		optionRaw, err := json.Marshal(option.Value)
+		if err == nil && string(optionRaw) == string(raw) {
+			return nil
+		}
+	}
+
+	return errors.New("must be one of the options")
+}
+
+func validateValue(t ConfigFieldType, v any) error {
+	switch t {
+	case ConfigFieldTypeString:
+		if _, ok := v.(string); !ok {
+			return errors.New("must be a string")
+		}
+	case ConfigFieldTypeInt:
+		n, ok := v.(float64)
+		if !ok || n != math.Trunc(n) {
+			return errors.New("must be an integer")
+		}
+	case ConfigFieldTypeFloat:
+		if _, ok := v.(float64); !ok {
+			return errors.New("must be a number")
+		}
+	case ConfigFieldTypeBool:
+		if _, ok := v.(bool); !ok {
+			return errors.New("must be a boolean")
+		}
+	case ConfigFieldTypeDiscordChannel, ConfigFieldTypeDiscordRole:
+		s, ok := v.(string)
+		if !ok {
+			return errors.New("must be a string")
+		}
+		if id, err := discord.ParseSnowflake(s); err != nil || !id.IsValid() {
+			return errors.New("must be a valid ID")
+		}
+	case ConfigFieldTypeDiscordEmoji:
+		s, ok := v.(string)
+		if !ok {
+			return errors.New("must be a string")
+		}
+		if emoji, err := ParseEmoji(s); err != nil || emoji == nil {
+			return errors.New("must be a valid emoji")
+		}
+	case ConfigFieldTypeMessageTemplate:
+		s, ok := v.(string)
+		if !ok || s == "" {
+			return errors.New("must be a message template ID")
+		}
+	default:
+		return fmt.Errorf("has unsupported type %q", t)
+	}
+
+	return nil
+}
+
+func validateRange(v float64, min *float64, max *float64, minMsg string, maxMsg string) error {
+	if min != nil && v < *min {
+		return fmt.Errorf(minMsg, *min)
+	}
+	if max != nil && v > *max {
+		return fmt.Errorf(maxMsg, *max)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package plugin
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig() Config {
+	min, max := 1.0, 10.0
+
+	return Config{
+		Sections: []ConfigSection{
+			{
+				Name: "General",
+				Fields: []ConfigField{
+					{Key: "name", Type: ConfigFieldTypeString, Required: true, Max: &max},
+					{Key: "count", Type: ConfigFieldTypeInt, Default: 5, Min: &min, Max: &max},
+					{Key: "ratio", Type: ConfigFieldTypeFloat},
+					{Key: "enabled", Type: ConfigFieldTypeBool},
+					{Key: "channel", Type: ConfigFieldTypeDiscordChannel},
+					{Key: "emoji", Type: ConfigFieldTypeDiscordEmoji},
+					{
+						Key:      "modes",
+						Type:     ConfigFieldTypeArray,
+						ItemType: ConfigFieldTypeString,
+						Max:      &max,
+						Options: []ConfigFieldOption{
+							{Label: "A", Value: "a"},
+							{Label: "B", Value: "b"},
+						},
+					},
+				},
+			},
+			{
+				Name:     "Messages",
+				PerGuild: true,
+				Fields: []ConfigField{
+					{Key: "welcome", Type: ConfigFieldTypeString},
+					{Key: "template", Type: ConfigFieldTypeMessageTemplate},
+				},
+			},
+		},
+	}
+}
+
+func parseConfigValues(t *testing.T, raw string) ConfigValues {
+	var values ConfigValues
+	require.NoError(t, json.Unmarshal([]byte(raw), &values))
+	return values
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    string
+		errFields []string
+	}{
+		{
+			name:   "valid",
+			values: `{"name": "kite", "count": 3, "ratio": 0.5, "enabled": true, "channel": "123", "emoji": "<:kite:456>", "modes": ["a", "b"]}`,
+		},
+		{
+			name:   "unknown keys are ignored",
+			values: `{"name": "kite", "removed": 1}`,
+		},
+		{
+			name:      "required without default",
+			values:    `{"count": 3}`,
+			errFields: []string{"name"},
+		},
+		{
+			name:      "null counts as missing",
+			values:    `{"name": null}`,
+			errFields: []string{"name"},
+		},
+		{
+			name:      "wrong types",
+			values:    `{"name": 1, "count": "3", "ratio": true, "enabled": "yes", "channel": 123}`,
+			errFields: []string{"name", "count", "ratio", "enabled", "channel"},
+		},
+		{
+			name:      "int must be integral",
+			values:    `{"name": "kite", "count": 2.5}`,
+			errFields: []string{"count"},
+		},
+		{
+			name:      "min and max",
+			values:    `{"name": "a very long name", "count": 0}`,
+			errFields: []string{"name", "count"},
+		},
+		{
+			name:      "invalid channel and emoji",
+			values:    `{"name": "kite", "channel": "general", "emoji": "not an emoji"}`,
+			errFields: []string{"channel", "emoji"},
+		},
+		{
+			name:      "text as emoji",
+			values:    `{"name": "kite", "emoji": "kite"}`,
+			errFields: []string{"emoji"},
+		},
+		{
+			name:      "malformed custom emoji",
+			values:    `{"name": "kite", "emoji": "<:kite:abc>"}`,
+			errFields: []string{"emoji"},
+		},
+		{
+			name:      "array items must be options",
+			values:    `{"name": "kite", "modes": ["a", "c"]}`,
+			errFields: []string{"modes"},
+		},
+		{
+			name:   "guild overrides",
+			values: `{"name": "kite", "guilds": {"123": {"welcome": "hi", "template": "abc"}}}`,
+		},
+		{
+			name:      "guild overrides of global fields",
+			values:    `{"name": "kite", "guilds": {"123": {"count": 3}}}`,
+			errFields: []string{"guilds"},
+		},
+		{
+			name:      "guild overrides with invalid guild ID",
+			values:    `{"name": "kite", "guilds": {"abc": {"welcome": "hi"}}}`,
+			errFields: []string{"guilds"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := testConfig().Validate(parseConfigValues(t, test.values))
+			if len(test.errFields) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var errs validation.Errors
+			require.ErrorAs(t, err, &errs)
+
+			fields := make([]string, 0, len(errs))
+			for field := range errs {
+				fields = append(fields, field)
+			}
+			assert.ElementsMatch(t, test.errFields, fields)
+		})
+	}
+}
+
+func TestConfigWithDefaults(t *testing.T) {
+	config := testConfig()
+
+	values := config.WithDefaults(parseConfigValues(t, `{"name": "kite"}`))
+	assert.Equal(t, 5, values.GetInt("count"))
+
+	values = config.WithDefaults(parseConfigValues(t, `{"name": "kite", "count": 7}`))
+	assert.Equal(t, 7, values.GetInt("count"))
+}
+
+func TestConfigValuesForGuild(t *testing.T) {
+	values := parseConfigValues(t, `{"welcome": "hello", "count": 3, "guilds": {"123": {"welcome": "hi"}}}`)
+
+	guild := values.ForGuild(discord.GuildID(123))
+	assert.Equal(t, "hi", guild.GetString("welcome"))
+	assert.Equal(t, 3, guild.GetInt("count"))
+	assert.NotContains(t, guild, ConfigKeyGuilds)
+
+	other := values.ForGuild(discord.GuildID(456))
+	assert.Equal(t, "hello", other.GetString("welcome"))
+
+	templates := testConfig().StringValuesOfType(
+		parseConfigValues(t, `{"template": "a", "guilds": {"123": {"template": "b"}}}`),
+		ConfigFieldTypeMessageTemplate,
+	)
+	assert.ElementsMatch(t, []string{"a", "b"}, templates)
+}
+
+func TestParseEmoji(t *testing.T) {
+	valid := map[string]*discord.Emoji{
+		"":                  nil,
+		"👍":                 {Name: "👍"},
+		"👍🏽":                {Name: "👍🏽"},
+		"❤️":                {Name: "❤️"},
+		"👨‍👩‍👧":             {Name: "👨‍👩‍👧"},
+		"🇩🇪":                {Name: "🇩🇪"},
+		"1️⃣":               {Name: "1️⃣"},
+		"<:kite:456>":       {ID: 456, Name: "kite"},
+		"<a:kite_party:78>": {ID: 78, Name: "kite_party", Animated: true},
+	}
+	for raw, expected := range valid {
+		emoji, err := ParseEmoji(raw)
+		if assert.NoError(t, err, raw) {
+			assert.Equal(t, expected, emoji, raw)
+		}
+	}
+
+	invalid := []string{"kite", "1", "👍 ", "a👍", ":kite:", "<:kite:0>", "<:k:456>", "<:kite:456> ", "<@123>"}
+	for _, raw := range invalid {
+		_, err := ParseEmoji(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestConfigValidateSchema(t *testing.T) {
+	assert.NoError(t, testConfig().ValidateSchema())
+
+	reserved := Config{Sections: []ConfigSection{{Fields: []ConfigField{{Key: ConfigKeyGuilds, Type: ConfigFieldTypeString}}}}}
+	assert.Error(t, reserved.ValidateSchema())
+
+	duplicate := testConfig()
+	duplicate.Sections[1].Fields = append(duplicate.Sections[1].Fields, ConfigField{Key: "name", Type: ConfigFieldTypeString})
+	assert.Error(t, duplicate.ValidateSchema())
+}
+
+func TestUnmarshalConfigValue(t *testing.T) {
+	v, err := UnmarshalConfigValue[int](json.RawMessage(`3`))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, v)
+
+	v, err = UnmarshalConfigValue[int](nil)
+	assert.NoError(t, err)
+	assert.Zero(t, v)
+
+	v, err = UnmarshalConfigValue[int](json.RawMessage(`"3"`))
+	assert.Error(t, err)
+	assert.Zero(t, v)
+
+	values := parseConfigValues(t, `{"count": "3", "guilds": []}`)
+	assert.Zero(t, values.GetInt("count"), "invalid values fall back to the zero value")
+	assert.Equal(t, 0, values.ForGuild(123).GetInt("count"))
+}
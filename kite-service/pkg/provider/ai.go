@@ -7,6 +7,7 @@ import (
 // AIProvider provides access to AI services.
 type AIProvider interface {
 	CreateResponse(ctx context.Context, opts CreateResponseOpts) (string, error)
+	ModerateContent(ctx context.Context, opts ModerateContentOpts) (*ModerationResult, error)
 }
 
 type CreateResponseOpts struct {
@@ -23,8 +24,28 @@ const (
 	AIToolTypeWebSearchPreview AIToolType = "web_search_preview"
 )
 
+type ModerateContentOpts struct {
+	Text      string
+	ImageURLs []string
+}
+
+// ModerationResult is the classification of a piece of content.
+// Category names are provider specific, e.g. "harassment" or "violence/graphic".
+type ModerationResult struct {
+	Flagged        bool
+	Categories     map[string]bool
+	CategoryScores map[string]float64
+}
+
 type MockAIProvider struct{}
 
 func (m *MockAIProvider) CreateResponse(ctx context.Context, opts CreateResponseOpts) (string, error) {
 	return "", nil
 }
+
+func (m *MockAIProvider) ModerateContent(ctx context.Context, opts ModerateContentOpts) (*ModerationResult, error) {
+	return &ModerationResult{
+		Categories:     map[string]bool{},
+		CategoryScores: map[string]float64{},
+	}, nil
+}
@@ -0,0 +1,37 @@
+package provider
+
+import "context"
+
+type Asset struct {
+	ID          string
+	Name        string
+	ContentType string
+	Content     []byte
+}
+
+// AssetProvider provides access to the assets of the app.
+type AssetProvider interface {
+	Asset(ctx context.Context, id string) (*Asset, error)
+	// CreateAsset creates an asset that is kept for as long as it's referenced.
+	CreateAsset(ctx context.Context, name string, contentType string, content []byte) (*Asset, error)
+	// CreateTemporaryAsset creates an asset for a generated file that expires after some time.
+	CreateTemporaryAsset(ctx context.Context, name string, contentType string, content []byte) (*Asset, error)
+}
+
+type MockAssetProvider struct{}
+
+func (p *MockAssetProvider) Asset(ctx context.Context, id string) (*Asset, error) {
+	return nil, ErrNotFound
+}
+
+func (p *MockAssetProvider) CreateAsset(ctx context.Context, name string, contentType string, content []byte) (*Asset, error) {
+	return p.CreateTemporaryAsset(ctx, name, contentType, content)
+}
+
+func (p *MockAssetProvider) CreateTemporaryAsset(ctx context.Context, name string, contentType string, content []byte) (*Asset, error) {
+	return &Asset{
+		Name:        name,
+		ContentType: contentType,
+		Content:     content,
+	}, nil
+}
@@ -17,6 +17,8 @@ type DiscordProvider interface {
 	Role(ctx context.Context, guildID discord.GuildID, roleID discord.RoleID) (*discord.Role, error)
 	Member(ctx context.Context, guildID discord.GuildID, userID discord.UserID) (*discord.Member, error)
 	Message(ctx context.Context, channelID discord.ChannelID, messageID discord.MessageID) (*discord.Message, error)
+	// ChannelMessages returns up to limit of the most recent messages in the channel, newest first.
+	ChannelMessages(ctx context.Context, channelID discord.ChannelID, limit int) ([]discord.Message, error)
 
 	CreateInteractionResponse(ctx context.Context, interactionID discord.InteractionID, interactionToken string, response api.InteractionResponse) (*InteractionResponseResource, error)
 	EditInteractionResponse(ctx context.Context, applicationID discord.AppID, token string, response api.EditInteractionResponseData) (*discord.Message, error)
@@ -90,6 +92,10 @@ func (p *MockDiscordProvider) Message(ctx context.Context, channelID discord.Cha
 	return nil, nil
 }
 
+func (p *MockDiscordProvider) ChannelMessages(ctx context.Context, channelID discord.ChannelID, limit int) ([]discord.Message, error) {
+	return nil, nil
+}
+
 func (p *MockDiscordProvider) CreateInteractionResponse(ctx context.Context, interactionID discord.InteractionID, interactionToken string, response api.InteractionResponse) (*InteractionResponseResource, error) {
 	return nil, nil
 }
@@ -2,69 +2,214 @@ package provider
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/kitecloud/kite/kite-service/pkg/thing"
 )
 
 // ValueProvider provides access to arbitrary key-value pairs.
 // Values are usually scoped to a specific plugin or other entity.
+// Values can have a TTL after which they are treated as if they didn't exist.
 type ValueProvider interface {
+	// UpdateValue applies the operation to the current value and returns the new value.
+	// Overwriting removes the TTL of the value, all other operations keep it.
 	UpdateValue(ctx context.Context, key string, op VariableOperation, value thing.Thing) (thing.Thing, error)
+	// SetValue sets the value for the given key, a ttl of 0 means that the value never expires.
+	SetValue(ctx context.Context, key string, value thing.Thing, ttl time.Duration) error
+	// SetValues sets multiple values at once, either all or none of the values are set.
+	SetValues(ctx context.Context, values []KeyValue, ttl time.Duration) error
+	// CompareAndSwapValue only sets the value when the current value equals old.
+	// thing.Null as old means that the key must not have a value. It returns whether the value has been set.
+	CompareAndSwapValue(ctx context.Context, key string, old thing.Thing, new thing.Thing, ttl time.Duration) (bool, error)
 	// GetValue returns the value for the given key.
 	// If the value is not found, it returns thing.Null and no error.
 	GetValue(ctx context.Context, key string) (thing.Thing, error)
+	// GetValues returns the values for the given keys, keys without a value are omitted.
+	GetValues(ctx context.Context, keys []string) (map[string]thing.Thing, error)
 	DeleteValue(ctx context.Context, key string) error
+	// ListValuesByPrefix returns the values whose key starts with the given prefix.
+	// Numeric values come first ordered from highest to lowest, followed by all other values.
+	ListValuesByPrefix(ctx context.Context, prefix string, limit int, offset int) ([]KeyValue, error)
+	// CountValuesByPrefixAbove returns the number of numeric values whose key starts with
+	// the given prefix and which are greater than the given value.
+	CountValuesByPrefixAbove(ctx context.Context, prefix string, value float64) (int, error)
 }
 
+type KeyValue struct {
+	Key   string
+	Value thing.Thing
+}
+
+// MockValueProvider keeps values in memory and implements the same semantics as the real provider.
 type MockValueProvider struct {
 	Values map[string]thing.Thing
+	// ExpiresAt contains the expiry of values that have a TTL.
+	ExpiresAt map[string]time.Time
+	// Now returns the current time, defaults to time.Now.
+	Now func() time.Time
 }
 
 func (p *MockValueProvider) UpdateValue(ctx context.Context, key string, op VariableOperation, value thing.Thing) (thing.Thing, error) {
-	currentValue, err := p.GetValue(ctx, key)
+	currentValue, ok := p.value(key)
+	if !ok || op == VariableOperationOverwrite {
+		p.set(key, value, 0)
+		return value, nil
+	}
+
+	newValue, _, err := op.Apply(&currentValue, value, VariableOperationArgs{})
 	if err != nil {
-		if errors.Is(err, ErrNotFound) {
-			p.Values[key] = value
-			return value, nil
-		}
 		return thing.Null, err
 	}
 
-	newValue := currentValue
+	p.Values[key] = newValue
+	return newValue, nil
+}
 
-	switch op {
-	case VariableOperationOverwrite:
-		newValue = value
-	case VariableOperationAppend:
-		newValue = currentValue.Append(value)
-	case VariableOperationPrepend:
-		newValue = value.Append(currentValue)
-	case VariableOperationIncrement:
-		newValue = currentValue.Add(value)
-	case VariableOperationDecrement:
-		newValue = currentValue.Sub(value)
+func (p *MockValueProvider) SetValue(ctx context.Context, key string, value thing.Thing, ttl time.Duration) error {
+	p.set(key, value, ttl)
+	return nil
+}
+
+func (p *MockValueProvider) SetValues(ctx context.Context, values []KeyValue, ttl time.Duration) error {
+	for _, v := range values {
+		p.set(v.Key, v.Value, ttl)
 	}
+	return nil
+}
 
-	p.Values[key] = newValue
-	return newValue, nil
+func (p *MockValueProvider) CompareAndSwapValue(ctx context.Context, key string, old thing.Thing, new thing.Thing, ttl time.Duration) (bool, error) {
+	currentValue, ok := p.value(key)
+	if old.IsNil() {
+		if ok {
+			return false, nil
+		}
+	} else if !ok || !jsonEqual(currentValue, old) {
+		return false, nil
+	}
+
+	p.set(key, new, ttl)
+	return true, nil
 }
 
 func (p *MockValueProvider) GetValue(ctx context.Context, key string) (thing.Thing, error) {
-	v, ok := p.Values[key]
+	v, ok := p.value(key)
 	if !ok {
 		return thing.Null, nil
 	}
 	return v, nil
 }
 
-func (p *MockValueProvider) DeleteValue(ctx context.Context, key string) (thing.Thing, error) {
+func (p *MockValueProvider) GetValues(ctx context.Context, keys []string) (map[string]thing.Thing, error) {
+	res := make(map[string]thing.Thing, len(keys))
+	for _, key := range keys {
+		if v, ok := p.value(key); ok {
+			res[key] = v
+		}
+	}
+	return res, nil
+}
+
+func (p *MockValueProvider) DeleteValue(ctx context.Context, key string) error {
+	delete(p.Values, key)
+	delete(p.ExpiresAt, key)
+	return nil
+}
+
+func (p *MockValueProvider) ListValuesByPrefix(ctx context.Context, prefix string, limit int, offset int) ([]KeyValue, error) {
+	var res []KeyValue
+	for key := range p.Values {
+		if value, ok := p.value(key); ok && strings.HasPrefix(key, prefix) {
+			res = append(res, KeyValue{Key: key, Value: value})
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		iNum, jNum := isNumericValue(res[i].Value), isNumericValue(res[j].Value)
+		if iNum != jNum {
+			return iNum
+		}
+		if iNum && res[i].Value.Float() != res[j].Value.Float() {
+			return res[i].Value.Float() > res[j].Value.Float()
+		}
+		return res[i].Key < res[j].Key
+	})
+
+	if offset >= len(res) {
+		return nil, nil
+	}
+	res = res[offset:]
+	if limit < len(res) {
+		res = res[:limit]
+	}
+
+	return res, nil
+}
+
+func (p *MockValueProvider) CountValuesByPrefixAbove(ctx context.Context, prefix string, value float64) (int, error) {
+	count := 0
+	for key := range p.Values {
+		v, ok := p.value(key)
+		if ok && strings.HasPrefix(key, prefix) && isNumericValue(v) && v.Float() > value {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// value returns the value for the key if it exists and hasn't expired.
+func (p *MockValueProvider) value(key string) (thing.Thing, bool) {
 	v, ok := p.Values[key]
 	if !ok {
-		return thing.Null, ErrNotFound
+		return thing.Null, false
 	}
 
-	delete(p.Values, key)
+	if expiresAt, ok := p.ExpiresAt[key]; ok && !expiresAt.After(p.now()) {
+		return thing.Null, false
+	}
 
-	return v, nil
+	return v, true
+}
+
+func (p *MockValueProvider) set(key string, value thing.Thing, ttl time.Duration) {
+	if p.Values == nil {
+		p.Values = make(map[string]thing.Thing)
+	}
+	p.Values[key] = value
+
+	if ttl == 0 {
+		delete(p.ExpiresAt, key)
+		return
+	}
+
+	if p.ExpiresAt == nil {
+		p.ExpiresAt = make(map[string]time.Time)
+	}
+	p.ExpiresAt[key] = p.now().Add(ttl)
+}
+
+func (p *MockValueProvider) now() time.Time {
+	if p.Now != nil {
+		return p.Now()
+	}
+	return time.Now()
+}
+
+func isNumericValue(v thing.Thing) bool {
+	return v.Type == thing.TypeInt || v.Type == thing.TypeFloat
+}
+
+// jsonEqual compares values the same way the database does, by their JSON representation.
+func jsonEqual(a, b thing.Thing) bool {
+	aJSON, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bJSON, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(aJSON) == string(bJSON)
 }
@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockValueProviderTTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := &MockValueProvider{Now: func() time.Time { return now }}
+
+	require.NoError(t, p.SetValue(ctx, "a", thing.NewInt(1), time.Minute))
+	require.NoError(t, p.SetValue(ctx, "b", thing.NewInt(2), 0))
+
+	v, err := p.GetValue(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, thing.NewInt(1), v)
+
+	// Incrementing keeps the expiry of the value
+	_, err = p.UpdateValue(ctx, "a", VariableOperationIncrement, thing.NewInt(1))
+	require.NoError(t, err)
+
+	now = now.Add(time.Minute)
+
+	v, err = p.GetValue(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, thing.Null, v)
+
+	values, err := p.GetValues(ctx, []string{"a", "b", "c"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]thing.Thing{"b": thing.NewInt(2)}, values)
+
+	list, err := p.ListValuesByPrefix(ctx, "", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []KeyValue{{Key: "b", Value: thing.NewInt(2)}}, list)
+}
+
+func TestMockValueProviderCompareAndSwap(t *testing.T) {
+	ctx := context.Background()
+	p := &MockValueProvider{}
+
+	swapped, err := p.CompareAndSwapValue(ctx, "key", thing.Null, thing.NewString("first"), 0)
+	require.NoError(t, err)
+	assert.True(t, swapped)
+
+	swapped, err = p.CompareAndSwapValue(ctx, "key", thing.Null, thing.NewString("second"), 0)
+	require.NoError(t, err)
+	assert.False(t, swapped)
+
+	swapped, err = p.CompareAndSwapValue(ctx, "key", thing.NewString("other"), thing.NewString("second"), 0)
+	require.NoError(t, err)
+	assert.False(t, swapped)
+
+	swapped, err = p.CompareAndSwapValue(ctx, "key", thing.NewString("first"), thing.NewString("second"), 0)
+	require.NoError(t, err)
+	assert.True(t, swapped)
+
+	require.NoError(t, p.DeleteValue(ctx, "key"))
+
+	v, err := p.GetValue(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, thing.Null, v)
+}
@@ -2,6 +2,8 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/kitecloud/kite/kite-service/pkg/thing"
 	"gopkg.in/guregu/null.v4"
@@ -15,22 +17,233 @@ const (
 	VariableOperationPrepend   VariableOperation = "prepend"
 	VariableOperationIncrement VariableOperation = "increment"
 	VariableOperationDecrement VariableOperation = "decrement"
+	VariableOperationMultiply  VariableOperation = "multiply"
+	// VariableOperationMin keeps the smaller of the current and the new value, it can be used to clamp values.
+	VariableOperationMin VariableOperation = "min"
+	// VariableOperationMax keeps the larger of the current and the new value, it can be used to clamp values.
+	VariableOperationMax         VariableOperation = "max"
+	VariableOperationSetIfAbsent VariableOperation = "set_if_absent"
+	// VariableOperationCompareAndSwap only sets the value when the current value equals VariableOperationArgs.Expected.
+	VariableOperationCompareAndSwap VariableOperation = "compare_and_swap"
+	// VariableOperationRemoveItem removes all items that equal the value from an array.
+	VariableOperationRemoveItem VariableOperation = "remove_item"
+	// VariableOperationSetKey sets VariableOperationArgs.Key inside an object to the value.
+	VariableOperationSetKey VariableOperation = "set_key"
+	// VariableOperationDeleteKey deletes VariableOperationArgs.Key from an object.
+	VariableOperationDeleteKey VariableOperation = "delete_key"
+	// VariableOperationPush adds the value to the end of an array and removes the oldest items
+	// when the array is longer than VariableOperationArgs.MaxLength.
+	VariableOperationPush VariableOperation = "push"
 )
 
 func (o VariableOperation) IsOverwrite() bool {
 	return o == VariableOperationOverwrite || o == ""
 }
 
+// VariableOperationArgs are the additional arguments of operations that need more than a value.
+type VariableOperationArgs struct {
+	// Key is the object key for set_key and delete_key.
+	Key string
+	// Expected is the value that compare_and_swap expects the current value to be, it's required for compare_and_swap.
+	// A pointer to thing.Null means that there must not be a current value.
+	Expected *thing.Thing
+	// MaxLength is the maximum length of the array for push, 0 means no limit.
+	MaxLength int
+	// Default is used as the current value when there is no value, except for set_if_absent and compare_and_swap.
+	Default thing.Thing
+	// Convert is called with the result before it's stored, it can convert the result or reject it by returning an error.
+	Convert func(thing.Thing) (thing.Thing, error)
+}
+
+// Apply returns the result of applying the operation to the current value, current is nil if there is no value.
+// When ok is false the operation doesn't change anything and the current value should be kept as it is.
+// remove_item compares items by their string representation, so values from templates match stored numbers.
+// compare_and_swap converts the expected value like the result and then compares it with thing.Thing.EqualsStrict.
+func (o VariableOperation) Apply(current *thing.Thing, value thing.Thing, args VariableOperationArgs) (res thing.Thing, ok bool, err error) {
+	res, ok, err = o.apply(current, value, args)
+	if err != nil || !ok || args.Convert == nil {
+		return res, ok, err
+	}
+
+	res, err = args.Convert(res)
+	if err != nil {
+		return thing.Null, false, err
+	}
+	return res, true, nil
+}
+
+func (o VariableOperation) apply(current *thing.Thing, value thing.Thing, args VariableOperationArgs) (thing.Thing, bool, error) {
+	if o.IsOverwrite() {
+		return value, true, nil
+	}
+
+	if o == VariableOperationCompareAndSwap {
+		if args.Expected == nil {
+			return thing.Null, false, errors.New("compare_and_swap requires an expected value")
+		}
+
+		expected := *args.Expected
+		if current == nil {
+			return value, expected.IsNil(), nil
+		}
+		if expected.IsNil() {
+			return *current, false, nil
+		}
+
+		if args.Convert != nil {
+			var err error
+			expected, err = args.Convert(expected)
+			if err != nil {
+				// A value that can't be stored can't be the current value either
+				return *current, false, nil
+			}
+		}
+
+		if !current.EqualsStrict(&expected) {
+			return *current, false, nil
+		}
+		return value, true, nil
+	}
+
+	if current == nil && !args.Default.IsNil() && o != VariableOperationSetIfAbsent {
+		current = &args.Default
+	}
+
+	if current == nil {
+		switch o {
+		case VariableOperationRemoveItem, VariableOperationDeleteKey:
+			return thing.Null, false, nil
+		case VariableOperationSetKey:
+			current = &thing.Thing{Type: thing.TypeObject, Value: map[string]thing.Thing{}}
+		case VariableOperationPush:
+			current = &thing.Thing{Type: thing.TypeArray, Value: []thing.Thing{}}
+		default:
+			return value, true, nil
+		}
+	}
+
+	switch o {
+	case VariableOperationAppend:
+		return current.Append(value), true, nil
+	case VariableOperationPrepend:
+		return value.Append(*current), true, nil
+	case VariableOperationIncrement:
+		return current.Add(value), true, nil
+	case VariableOperationDecrement:
+		return current.Sub(value), true, nil
+	case VariableOperationMultiply:
+		return current.Mul(value), true, nil
+	case VariableOperationMin:
+		if value.Float() < current.Float() {
+			return value, true, nil
+		}
+		return *current, false, nil
+	case VariableOperationMax:
+		if value.Float() > current.Float() {
+			return value, true, nil
+		}
+		return *current, false, nil
+	case VariableOperationSetIfAbsent:
+		return *current, false, nil
+	case VariableOperationRemoveItem:
+		if current.Type != thing.TypeArray {
+			return thing.Null, false, errors.New("value is not an array")
+		}
+
+		items := make([]thing.Thing, 0, len(current.Array()))
+		for _, item := range current.Array() {
+			if item.String() != value.String() {
+				items = append(items, item)
+			}
+		}
+		return thing.NewArray(items), true, nil
+	case VariableOperationPush:
+		if current.Type != thing.TypeArray {
+			return thing.Null, false, errors.New("value is not an array")
+		}
+
+		items := append(append([]thing.Thing{}, current.Array()...), value)
+		if args.MaxLength > 0 && len(items) > args.MaxLength {
+			items = items[len(items)-args.MaxLength:]
+		}
+		return thing.NewArray(items), true, nil
+	case VariableOperationSetKey, VariableOperationDeleteKey:
+		if current.Type != thing.TypeObject {
+			return thing.Null, false, errors.New("value is not an object")
+		}
+		if args.Key == "" {
+			return thing.Null, false, errors.New("key is required")
+		}
+
+		object := make(map[string]thing.Thing, len(current.Object())+1)
+		for k, v := range current.Object() {
+			object[k] = v
+		}
+
+		if o == VariableOperationSetKey {
+			object[args.Key] = value
+		} else {
+			if _, exists := object[args.Key]; !exists {
+				return *current, false, nil
+			}
+			delete(object, args.Key)
+		}
+		return thing.NewObject(object), true, nil
+	}
+
+	return thing.Null, false, fmt.Errorf("unknown variable operation: %s", o)
+}
+
+type VariableQueryOrder string
+
+const (
+	VariableQueryOrderValueDesc VariableQueryOrder = "value_desc"
+	VariableQueryOrderValueAsc  VariableQueryOrder = "value_asc"
+	VariableQueryOrderScopeAsc  VariableQueryOrder = "scope_asc"
+	VariableQueryOrderScopeDesc VariableQueryOrder = "scope_desc"
+)
+
+// VariableQuery selects the values of a scoped variable.
+type VariableQuery struct {
+	// ScopePrefix only includes scopes that start with it, an empty prefix includes all scopes.
+	// The unscoped value is never included.
+	ScopePrefix string
+	// Order defaults to VariableQueryOrderScopeAsc. When ordering by value, non-numeric values come last.
+	Order  VariableQueryOrder
+	Limit  int
+	Offset int
+}
+
+type VariableScopeValue struct {
+	Scope null.String
+	Value thing.Thing
+}
+
+// VariableAggregate summarizes the scoped values of a variable.
+// Sum, Average, Min and Max only include numeric values. If there are none Sum is 0 and the others are null.
+type VariableAggregate struct {
+	Count   int
+	Sum     float64
+	Average null.Float
+	Min     null.Float
+	Max     null.Float
+}
+
 // VariableProvider provides access to user-defined variables and their values.
 type VariableProvider interface {
-	UpdateVariable(ctx context.Context, id string, scope null.String, operation VariableOperation, value thing.Thing) (thing.Thing, error)
+	// UpdateVariable atomically applies the operation to the current value and returns the resulting value.
+	UpdateVariable(ctx context.Context, id string, scope null.String, operation VariableOperation, value thing.Thing, args VariableOperationArgs) (thing.Thing, error)
 	Variable(ctx context.Context, id string, scope null.String) (thing.Thing, error)
 	DeleteVariable(ctx context.Context, id string, scope null.String) error
+	// QueryVariable returns the values of all scopes that match the query.
+	QueryVariable(ctx context.Context, id string, query VariableQuery) ([]VariableScopeValue, error)
+	// AggregateVariable aggregates the values of all scopes that start with the scope prefix, the unscoped value isn't included.
+	AggregateVariable(ctx context.Context, id string, scopePrefix string) (VariableAggregate, error)
 }
 
 type MockVariableProvider struct{}
 
-func (p *MockVariableProvider) UpdateVariable(ctx context.Context, id string, scope null.String, operation VariableOperation, value thing.Thing) (thing.Thing, error) {
+func (p *MockVariableProvider) UpdateVariable(ctx context.Context, id string, scope null.String, operation VariableOperation, value thing.Thing, args VariableOperationArgs) (thing.Thing, error) {
 	return thing.Null, nil
 }
 
@@ -41,3 +254,11 @@ func (p *MockVariableProvider) Variable(ctx context.Context, id string, scope nu
 func (p *MockVariableProvider) DeleteVariable(ctx context.Context, id string, scope null.String) error {
 	return nil
 }
+
+func (p *MockVariableProvider) QueryVariable(ctx context.Context, id string, query VariableQuery) ([]VariableScopeValue, error) {
+	return nil, nil
+}
+
+func (p *MockVariableProvider) AggregateVariable(ctx context.Context, id string, scopePrefix string) (VariableAggregate, error) {
+	return VariableAggregate{}, nil
+}
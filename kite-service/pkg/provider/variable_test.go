@@ -0,0 +1,241 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariableOperationApply(t *testing.T) {
+	array := func(items ...thing.Thing) *thing.Thing {
+		v := thing.NewArray(items)
+		return &v
+	}
+	object := func(items map[string]thing.Thing) *thing.Thing {
+		v := thing.NewObject(items)
+		return &v
+	}
+	float := func(v float64) *thing.Thing {
+		t := thing.NewFloat(v)
+		return &t
+	}
+	expected := func(v thing.Thing) *thing.Thing {
+		return &v
+	}
+
+	tests := []struct {
+		name      string
+		operation VariableOperation
+		current   *thing.Thing
+		value     thing.Thing
+		args      VariableOperationArgs
+		want      thing.Thing
+		wantOK    bool
+	}{
+		{
+			name:      "multiply",
+			operation: VariableOperationMultiply,
+			current:   float(3),
+			value:     thing.NewString("2"),
+			want:      thing.NewFloat(6.0),
+			wantOK:    true,
+		},
+		{
+			name:      "multiply without value",
+			operation: VariableOperationMultiply,
+			value:     thing.NewInt(2),
+			want:      thing.NewInt(2),
+			wantOK:    true,
+		},
+		{
+			name:      "min keeps smaller current value",
+			operation: VariableOperationMin,
+			current:   float(3),
+			value:     thing.NewInt(5),
+			want:      thing.NewFloat(3.0),
+		},
+		{
+			name:      "max replaces smaller current value",
+			operation: VariableOperationMax,
+			current:   float(3),
+			value:     thing.NewInt(5),
+			want:      thing.NewInt(5),
+			wantOK:    true,
+		},
+		{
+			name:      "set if absent with value",
+			operation: VariableOperationSetIfAbsent,
+			current:   float(1),
+			value:     thing.NewInt(5),
+			want:      thing.NewFloat(1.0),
+		},
+		{
+			name:      "set if absent without value",
+			operation: VariableOperationSetIfAbsent,
+			value:     thing.NewInt(5),
+			want:      thing.NewInt(5),
+			wantOK:    true,
+		},
+		{
+			name:      "compare and swap matching",
+			operation: VariableOperationCompareAndSwap,
+			current:   float(1),
+			value:     thing.NewInt(2),
+			args:      VariableOperationArgs{Expected: expected(thing.NewInt(1))},
+			want:      thing.NewInt(2),
+			wantOK:    true,
+		},
+		{
+			name:      "compare and swap not matching",
+			operation: VariableOperationCompareAndSwap,
+			current:   float(1),
+			value:     thing.NewInt(2),
+			args:      VariableOperationArgs{Expected: expected(thing.NewInt(3))},
+			want:      thing.NewFloat(1.0),
+		},
+		{
+			name:      "compare and swap different type",
+			operation: VariableOperationCompareAndSwap,
+			current:   float(1),
+			value:     thing.NewInt(2),
+			args:      VariableOperationArgs{Expected: expected(thing.NewString("1"))},
+			want:      thing.NewFloat(1.0),
+		},
+		{
+			name:      "compare and swap converted expected value",
+			operation: VariableOperationCompareAndSwap,
+			current:   float(1),
+			value:     thing.NewInt(2),
+			args: VariableOperationArgs{
+				Expected: expected(thing.NewString("1")),
+				Convert: func(v thing.Thing) (thing.Thing, error) {
+					return v.ConvertTo(thing.TypeFloat)
+				},
+			},
+			want:   thing.NewFloat(2.0),
+			wantOK: true,
+		},
+		{
+			name:      "compare and swap absent",
+			operation: VariableOperationCompareAndSwap,
+			value:     thing.NewInt(2),
+			args:      VariableOperationArgs{Expected: expected(thing.Null)},
+			want:      thing.NewInt(2),
+			wantOK:    true,
+		},
+		{
+			name:      "compare and swap expected absent",
+			operation: VariableOperationCompareAndSwap,
+			current:   float(1),
+			value:     thing.NewInt(2),
+			args:      VariableOperationArgs{Expected: expected(thing.Null)},
+			want:      thing.NewFloat(1.0),
+		},
+		{
+			name:      "remove item",
+			operation: VariableOperationRemoveItem,
+			current:   array(thing.NewString("a"), thing.NewString("b"), thing.NewString("a")),
+			value:     thing.NewString("a"),
+			want:      thing.NewArray([]thing.Thing{thing.NewString("b")}),
+			wantOK:    true,
+		},
+		{
+			name:      "set key",
+			operation: VariableOperationSetKey,
+			current:   object(map[string]thing.Thing{"a": thing.NewInt(1)}),
+			value:     thing.NewInt(2),
+			args:      VariableOperationArgs{Key: "b"},
+			want:      thing.NewObject(map[string]thing.Thing{"a": thing.NewInt(1), "b": thing.NewInt(2)}),
+			wantOK:    true,
+		},
+		{
+			name:      "delete key",
+			operation: VariableOperationDeleteKey,
+			current:   object(map[string]thing.Thing{"a": thing.NewInt(1), "b": thing.NewInt(2)}),
+			args:      VariableOperationArgs{Key: "a"},
+			want:      thing.NewObject(map[string]thing.Thing{"b": thing.NewInt(2)}),
+			wantOK:    true,
+		},
+		{
+			name:      "push with max length",
+			operation: VariableOperationPush,
+			current:   array(thing.NewInt(1), thing.NewInt(2)),
+			value:     thing.NewInt(3),
+			args:      VariableOperationArgs{MaxLength: 2},
+			want:      thing.NewArray([]thing.Thing{thing.NewInt(2), thing.NewInt(3)}),
+			wantOK:    true,
+		},
+		{
+			name:      "push without value",
+			operation: VariableOperationPush,
+			value:     thing.NewInt(1),
+			want:      thing.NewArray([]thing.Thing{thing.NewInt(1)}),
+			wantOK:    true,
+		},
+		{
+			name:      "increment default",
+			operation: VariableOperationIncrement,
+			value:     thing.NewInt(5),
+			args:      VariableOperationArgs{Default: thing.NewInt(100)},
+			want:      thing.NewInt(105),
+			wantOK:    true,
+		},
+		{
+			name:      "set if absent ignores default",
+			operation: VariableOperationSetIfAbsent,
+			value:     thing.NewInt(5),
+			args:      VariableOperationArgs{Default: thing.NewInt(100)},
+			want:      thing.NewInt(5),
+			wantOK:    true,
+		},
+		{
+			name:      "convert result",
+			operation: VariableOperationIncrement,
+			current:   float(1),
+			value:     thing.NewInt(2),
+			args: VariableOperationArgs{Convert: func(v thing.Thing) (thing.Thing, error) {
+				return v.ConvertTo(thing.TypeInt)
+			}},
+			want:   thing.NewInt(3),
+			wantOK: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, ok, err := test.operation.Apply(test.current, test.value, test.args)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantOK, ok)
+			assert.Equal(t, test.want, res)
+		})
+	}
+}
+
+func TestVariableOperationApplyErrors(t *testing.T) {
+	current := thing.NewString("not a collection")
+	float := func(v float64) *thing.Thing {
+		t := thing.NewFloat(v)
+		return &t
+	}
+
+	_, _, err := VariableOperationPush.Apply(&current, thing.NewInt(1), VariableOperationArgs{})
+	require.Error(t, err)
+
+	_, _, err = VariableOperationSetKey.Apply(&current, thing.NewInt(1), VariableOperationArgs{Key: "a"})
+	require.Error(t, err)
+
+	_, _, err = VariableOperationMultiply.Apply(float(1), thing.NewFloat(1.5), VariableOperationArgs{
+		Convert: func(v thing.Thing) (thing.Thing, error) {
+			return v.ConvertTo(thing.TypeInt)
+		},
+	})
+	require.Error(t, err)
+
+	_, _, err = VariableOperationCompareAndSwap.Apply(float(1), thing.NewInt(2), VariableOperationArgs{})
+	require.ErrorContains(t, err, "requires an expected value")
+
+	_, _, err = VariableOperation("unknown").Apply(&current, thing.NewInt(1), VariableOperationArgs{})
+	require.Error(t, err)
+}
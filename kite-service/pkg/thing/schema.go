@@ -0,0 +1,262 @@
+package thing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is the subset of JSON Schema that can be used to validate things.
+// ParseSchema rejects unknown keywords, so users don't rely on keywords that aren't enforced.
+type Schema struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Type string `json:"type,omitempty"`
+	Enum []any  `json:"enum,omitempty"`
+
+	// Object
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+
+	// Array
+	Items    *Schema `json:"items,omitempty"`
+	MinItems *int    `json:"minItems,omitempty"`
+	MaxItems *int    `json:"maxItems,omitempty"`
+
+	// String
+	MinLength *int `json:"minLength,omitempty"`
+	MaxLength *int `json:"maxLength,omitempty"`
+
+	// Number
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+}
+
+// ParseSchema decodes and checks a schema.
+func ParseSchema(raw []byte) (*Schema, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+
+	var s Schema
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	if err := s.check(); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	return &s, nil
+}
+
+func (s *Schema) check() error {
+	switch s.Type {
+	case "", "object", "array", "string", "integer", "number", "boolean", "null":
+	default:
+		return fmt.Errorf("unknown type: %s", s.Type)
+	}
+
+	for key, property := range s.Properties {
+		if property == nil {
+			return fmt.Errorf("property %s is null", key)
+		}
+		if err := property.check(); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	if s.Items != nil {
+		if err := s.Items.check(); err != nil {
+			return fmt.Errorf("items: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Validate returns an error describing the first part of the thing that doesn't match the schema.
+func (s *Schema) Validate(t Thing) error {
+	return s.validate(t, "value")
+}
+
+func (s *Schema) validate(t Thing, path string) error {
+	if t.Type == TypeAny && !t.IsNil() {
+		t = NewGuessTypeRecursive(t.Value)
+	}
+
+	if s.Type != "" && !schemaTypeMatches(s.Type, t) {
+		return fmt.Errorf("%s must be of type %s", path, s.Type)
+	}
+
+	if len(s.Enum) != 0 {
+		found := false
+		for _, v := range s.Enum {
+			if NewGuessTypeRecursive(v).String() == t.String() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s must be one of the allowed values", path)
+		}
+	}
+
+	switch t.Type {
+	case TypeObject:
+		object := t.Object()
+		for _, key := range s.Required {
+			if _, ok := object[key]; !ok {
+				return fmt.Errorf("%s.%s is required", path, key)
+			}
+		}
+
+		for key, value := range object {
+			property, ok := s.Properties[key]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					return fmt.Errorf("%s.%s is not allowed", path, key)
+				}
+				continue
+			}
+
+			if err := property.validate(value, path+"."+key); err != nil {
+				return err
+			}
+		}
+	case TypeArray:
+		items := t.Array()
+		if s.MinItems != nil && len(items) < *s.MinItems {
+			return fmt.Errorf("%s must have at least %d items", path, *s.MinItems)
+		}
+		if s.MaxItems != nil && len(items) > *s.MaxItems {
+			return fmt.Errorf("%s must have at most %d items", path, *s.MaxItems)
+		}
+
+		if s.Items != nil {
+			for i, item := range items {
+				if err := s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case TypeString:
+		length := utf8.RuneCountInString(t.String())
+		if s.MinLength != nil && length < *s.MinLength {
+			return fmt.Errorf("%s must be at least %d characters long", path, *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			return fmt.Errorf("%s must be at most %d characters long", path, *s.MaxLength)
+		}
+	case TypeInt, TypeFloat:
+		if s.Minimum != nil && t.Float() < *s.Minimum {
+			return fmt.Errorf("%s must be at least %v", path, *s.Minimum)
+		}
+		if s.Maximum != nil && t.Float() > *s.Maximum {
+			return fmt.Errorf("%s must be at most %v", path, *s.Maximum)
+		}
+	}
+
+	return nil
+}
+
+func schemaTypeMatches(schemaType string, t Thing) bool {
+	switch schemaType {
+	case "object":
+		return t.Type == TypeObject
+	case "array":
+		return t.Type == TypeArray
+	case "string":
+		return t.Type == TypeString
+	case "integer":
+		return t.Type == TypeInt || (t.Type == TypeFloat && t.Float() == math.Trunc(t.Float()))
+	case "number":
+		return t.Type == TypeInt || t.Type == TypeFloat
+	case "boolean":
+		return t.Type == TypeBool
+	case "null":
+		return t.IsNil()
+	}
+	return false
+}
+
+// ConvertTo converts the thing to the given type.
+// Strings are parsed and numbers are converted as long as no information is lost, all other mismatches are an error.
+// TypeAny accepts every value as it is.
+func (w Thing) ConvertTo(t Type) (Thing, error) {
+	if t == "" || t == TypeAny || w.Type == t {
+		return w, nil
+	}
+
+	if w.IsNil() {
+		return Null, errors.New("value is not set")
+	}
+
+	if w.Type == TypeAny {
+		guessed := NewGuessTypeRecursive(w.Value)
+		if guessed.Type != TypeAny {
+			return guessed.ConvertTo(t)
+		}
+	}
+
+	switch t {
+	case TypeInt:
+		switch w.Type {
+		case TypeFloat:
+			f := w.Float()
+			if f == math.Trunc(f) && f >= math.MinInt64 && f <= math.MaxInt64 {
+				return NewInt(int64(f)), nil
+			}
+			return Null, fmt.Errorf("%v is not a whole number", f)
+		case TypeString:
+			s := strings.TrimSpace(w.String())
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return NewInt(i), nil
+			}
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return NewFloat(f).ConvertTo(TypeInt)
+			}
+		}
+	case TypeFloat:
+		switch w.Type {
+		case TypeInt:
+			return NewFloat(float64(w.Int())), nil
+		case TypeString:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(w.String()), 64); err == nil {
+				return NewFloat(f), nil
+			}
+		}
+	case TypeString:
+		switch w.Type {
+		case TypeInt, TypeFloat, TypeBool, TypeTime, TypeDuration:
+			return NewString(w.String()), nil
+		}
+	case TypeBool:
+		if w.Type == TypeString {
+			switch w.String() {
+			case "true":
+				return NewBool(true), nil
+			case "false":
+				return NewBool(false), nil
+			}
+		}
+	case TypeArray, TypeObject:
+		if w.Type == TypeString {
+			var v any
+			if err := json.Unmarshal([]byte(w.String()), &v); err == nil {
+				res := NewGuessTypeRecursive(v)
+				if res.Type == t {
+					return res, nil
+				}
+			}
+		}
+	}
+
+	return Null, fmt.Errorf("%s can't be converted to %s", w.Type, t)
+}
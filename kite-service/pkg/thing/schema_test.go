@@ -0,0 +1,116 @@
+package thing
+
+import (
+	"testing"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchema(t *testing.T) {
+	_, err := ParseSchema([]byte(`{"type": "object", "properties": {"balance": {"type": "integer", "minimum": 0}}}`))
+	require.NoError(t, err)
+
+	_, err = ParseSchema([]byte(`{"type": "object", "pattern": "^a"}`))
+	require.Error(t, err)
+
+	_, err = ParseSchema([]byte(`{"type": "object", "properties": {"balance": {"type": "money"}}}`))
+	require.Error(t, err)
+}
+
+func TestSchemaValidate(t *testing.T) {
+	schema, err := ParseSchema([]byte(`{
+		"type": "object",
+		"required": ["balance"],
+		"additionalProperties": false,
+		"properties": {
+			"balance": {"type": "integer", "minimum": 0},
+			"tier": {"enum": ["free", "premium"]},
+			"items": {"type": "array", "maxItems": 2, "items": {"type": "string", "minLength": 1}}
+		}
+	}`))
+	require.NoError(t, err)
+
+	object := func(v map[string]Thing) Thing {
+		return NewObject(v)
+	}
+
+	tests := []struct {
+		name  string
+		value Thing
+		err   string
+	}{
+		{name: "valid", value: object(map[string]Thing{
+			"balance": NewInt(10),
+			"tier":    NewString("premium"),
+			"items":   NewArray([]Thing{NewString("sword")}),
+		})},
+		{name: "legacy any value", value: NewAny(map[string]any{"balance": float64(3)})},
+		{name: "wrong type", value: NewInt(1), err: "value must be of type object"},
+		{name: "missing required", value: object(map[string]Thing{}), err: "value.balance is required"},
+		{name: "below minimum", value: object(map[string]Thing{"balance": NewInt(-1)}), err: "value.balance must be at least 0"},
+		{name: "float balance", value: object(map[string]Thing{"balance": NewFloat(1.5)}), err: "value.balance must be of type integer"},
+		{name: "not in enum", value: object(map[string]Thing{"balance": NewInt(1), "tier": NewString("gold")}), err: "value.tier must be one of the allowed values"},
+		{name: "additional property", value: object(map[string]Thing{"balance": NewInt(1), "other": NewInt(1)}), err: "value.other is not allowed"},
+		{name: "too many items", value: object(map[string]Thing{
+			"balance": NewInt(1),
+			"items":   NewArray([]Thing{NewString("a"), NewString("b"), NewString("c")}),
+		}), err: "value.items must have at most 2 items"},
+		{name: "invalid item", value: object(map[string]Thing{
+			"balance": NewInt(1),
+			"items":   NewArray([]Thing{NewString("")}),
+		}), err: "value.items[0] must be at least 1 characters long"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := schema.Validate(test.value)
+			if test.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestConvertTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    Thing
+		typ      Type
+		expected Thing
+		err      bool
+	}{
+		{name: "any", value: NewString("1"), typ: TypeAny, expected: NewString("1")},
+		{name: "same type", value: NewInt(1), typ: TypeInt, expected: NewInt(1)},
+		{name: "string to int", value: NewString(" 42 "), typ: TypeInt, expected: NewInt(42)},
+		{name: "whole float to int", value: NewFloat(3.0), typ: TypeInt, expected: NewInt(3)},
+		{name: "fraction to int", value: NewFloat(2.5), typ: TypeInt, err: true},
+		{name: "text to int", value: NewString("abc"), typ: TypeInt, err: true},
+		{name: "int to float", value: NewInt(2), typ: TypeFloat, expected: NewFloat(2.0)},
+		{name: "string to float", value: NewString("2.5"), typ: TypeFloat, expected: NewFloat(2.5)},
+		{name: "int to string", value: NewInt(2), typ: TypeString, expected: NewString("2")},
+		{name: "string to bool", value: NewString("true"), typ: TypeBool, expected: NewBool(true)},
+		{name: "json to array", value: NewString(`[1, "a"]`), typ: TypeArray, expected: NewArray([]Thing{NewInt(1), NewString("a")})},
+		{name: "json to object", value: NewString(`{"a": 1}`), typ: TypeObject, expected: NewObject(map[string]Thing{"a": NewInt(1)})},
+		{name: "array to object", value: NewString(`[1]`), typ: TypeObject, err: true},
+		{name: "null to int", value: Null, typ: TypeInt, err: true},
+		{name: "user to string", value: NewDiscordUser(discord.User{ID: 1}), typ: TypeString, err: true},
+		{name: "string to user", value: NewString("1"), typ: TypeDiscordUser, err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := test.value.ConvertTo(test.typ)
+			if test.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
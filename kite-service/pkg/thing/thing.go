@@ -3,10 +3,12 @@ package thing
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/diamondburned/arikawa/v3/discord"
 )
@@ -21,6 +23,8 @@ const (
 	TypeInt            Type = "int"
 	TypeFloat          Type = "float"
 	TypeBool           Type = "bool"
+	TypeTime           Type = "time"
+	TypeDuration       Type = "duration"
 	TypeDiscordMessage Type = "discord_message"
 	TypeDiscordUser    Type = "discord_user"
 	TypeDiscordMember  Type = "discord_member"
@@ -73,6 +77,16 @@ func (w *Thing) UnmarshalJSON(data []byte) error {
 			if err != nil {
 				return err
 			}
+		case TypeTime:
+			w.Value, err = UnmarshalValue[time.Time](aux.Value)
+			if err != nil {
+				return err
+			}
+		case TypeDuration:
+			w.Value, err = UnmarshalValue[time.Duration](aux.Value)
+			if err != nil {
+				return err
+			}
 		case TypeDiscordMessage:
 			w.Value, err = UnmarshalValue[discord.Message](aux.Value)
 			if err != nil {
@@ -207,6 +221,10 @@ func NewGuessType(v any) (Thing, error) {
 		return NewFloat(v), nil
 	case bool:
 		return NewBool(v), nil
+	case time.Time:
+		return NewTime(v), nil
+	case time.Duration:
+		return NewDuration(v), nil
 	case []byte:
 		return NewString(string(v)), nil
 	case []Thing:
@@ -244,6 +262,27 @@ func NewGuessTypeWithFallback(v any) Thing {
 	return res
 }
 
+// NewGuessTypeRecursive is like NewGuessTypeWithFallback but also converts
+// generic maps and slices (e.g. decoded JSON) into objects and arrays of things.
+func NewGuessTypeRecursive(v any) Thing {
+	switch v := v.(type) {
+	case map[string]any:
+		obj := make(map[string]Thing, len(v))
+		for key, value := range v {
+			obj[key] = NewGuessTypeRecursive(value)
+		}
+		return NewObject(obj)
+	case []any:
+		arr := make([]Thing, len(v))
+		for i, value := range v {
+			arr[i] = NewGuessTypeRecursive(value)
+		}
+		return NewArray(arr)
+	default:
+		return NewGuessTypeWithFallback(v)
+	}
+}
+
 func NewString(v string) Thing {
 	return Thing{
 		Type:  TypeString,
@@ -272,6 +311,20 @@ func NewBool(v bool) Thing {
 	}
 }
 
+func NewTime(v time.Time) Thing {
+	return Thing{
+		Type:  TypeTime,
+		Value: v.UTC(),
+	}
+}
+
+func NewDuration(v time.Duration) Thing {
+	return Thing{
+		Type:  TypeDuration,
+		Value: v,
+	}
+}
+
 func NewDiscordMessage(v discord.Message) Thing {
 	return Thing{
 		Type:  TypeDiscordMessage,
@@ -361,6 +414,10 @@ func (w Thing) String() string {
 		return strconv.FormatFloat(w.Value.(float64), 'f', -1, 64)
 	case TypeBool:
 		return strconv.FormatBool(w.Value.(bool))
+	case TypeTime:
+		return w.Value.(time.Time).Format(time.RFC3339)
+	case TypeDuration:
+		return w.Value.(time.Duration).String()
 	case TypeDiscordMessage:
 		return w.Value.(discord.Message).Content
 	case TypeDiscordUser:
@@ -447,6 +504,10 @@ func (w Thing) Int() int64 {
 			return 1
 		}
 		return 0
+	case TypeTime:
+		return w.Value.(time.Time).Unix()
+	case TypeDuration:
+		return int64(w.Value.(time.Duration).Seconds())
 	case TypeArray:
 		return int64(len(w.Array()))
 	case TypeObject:
@@ -491,6 +552,10 @@ func (w Thing) Float() float64 {
 			return 1
 		}
 		return 0
+	case TypeTime:
+		return float64(w.Value.(time.Time).UnixMilli()) / 1000
+	case TypeDuration:
+		return w.Value.(time.Duration).Seconds()
 	case TypeArray:
 		return float64(len(w.Array()))
 	case TypeObject:
@@ -532,6 +597,10 @@ func (w Thing) Bool() bool {
 	case TypeString:
 		v := w.Value.(string)
 		return v != "" && v != "null" && v != "undefined" && v != "0" && v != "false"
+	case TypeTime:
+		return !w.Value.(time.Time).IsZero()
+	case TypeDuration:
+		return w.Value.(time.Duration) != 0
 	case TypeArray:
 		return len(w.Array()) > 0
 	case TypeObject:
@@ -559,6 +628,67 @@ func (w Thing) Array() []Thing {
 	}
 }
 
+// Time returns the value as a point in time.
+// Strings are parsed as RFC3339 and numbers are interpreted as unix seconds.
+func (w Thing) Time() time.Time {
+	switch w.Type {
+	case TypeTime:
+		return w.Value.(time.Time)
+	case TypeString:
+		t, err := time.Parse(time.RFC3339, w.Value.(string))
+		if err != nil {
+			return time.Time{}
+		}
+		return t.UTC()
+	case TypeInt:
+		return time.Unix(w.Value.(int64), 0).UTC()
+	case TypeFloat:
+		return time.UnixMilli(int64(w.Value.(float64) * 1000)).UTC()
+	default:
+		return time.Time{}
+	}
+}
+
+// Duration returns the value as a duration.
+// Strings are parsed with ParseDuration and numbers are interpreted as seconds.
+func (w Thing) Duration() time.Duration {
+	switch w.Type {
+	case TypeDuration:
+		return w.Value.(time.Duration)
+	case TypeString:
+		d, _ := ParseDuration(w.Value.(string))
+		return d
+	case TypeInt:
+		return time.Duration(w.Value.(int64)) * time.Second
+	case TypeFloat:
+		return time.Duration(w.Value.(float64) * float64(time.Second))
+	default:
+		return 0
+	}
+}
+
+// ParseDuration parses a Go duration (e.g. "1h30m"), a number of days (e.g. "2d") or a number of seconds.
+func ParseDuration(s string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+
+	// Go durations don't support days, so we handle them ourselves
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse duration: %w", err)
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse duration: %w", err)
+	}
+	return d, nil
+}
+
 func (w Thing) DiscordMessage() discord.Message {
 	if w.Type == TypeDiscordMessage {
 		return w.Value.(discord.Message)
@@ -624,6 +754,79 @@ func (w Thing) Equals(other *Thing) bool {
 	return reflect.DeepEqual(w.Value, other.Value)
 }
 
+// EqualsStrict reports whether both values have the same type and are equal.
+// Ints and floats are compared numerically and arrays and objects are compared item by item,
+// unlike Equals it never matches values of different types, e.g. the string "1" and the int 1.
+func (w Thing) EqualsStrict(other *Thing) bool {
+	a, b := w.guessAny(), other.guessAny()
+	if a.isNumber() && b.isNumber() {
+		if a.Type == TypeInt && b.Type == TypeInt {
+			return a.Int() == b.Int()
+		}
+		return a.Float() == b.Float()
+	}
+
+	if a.Type != b.Type {
+		return false
+	}
+
+	switch a.Type {
+	case TypeArray:
+		if len(a.Array()) != len(b.Array()) {
+			return false
+		}
+		for i, item := range a.Array() {
+			if !item.EqualsStrict(&b.Array()[i]) {
+				return false
+			}
+		}
+		return true
+	case TypeObject:
+		if len(a.Object()) != len(b.Object()) {
+			return false
+		}
+		for key, item := range a.Object() {
+			otherItem, ok := b.Object()[key]
+			if !ok || !item.EqualsStrict(&otherItem) {
+				return false
+			}
+		}
+		return true
+	case TypeTime:
+		return a.Time().Equal(b.Time())
+	}
+
+	return reflect.DeepEqual(a.Value, b.Value)
+}
+
+// guessAny replaces values of TypeAny with their guessed type, so decoded JSON can be compared with typed values.
+func (w Thing) guessAny() Thing {
+	if w.Type == TypeAny {
+		return NewGuessTypeRecursive(w.Value)
+	}
+	return w
+}
+
+func (w Thing) isNumber() bool {
+	return w.Type == TypeInt || w.Type == TypeFloat
+}
+
+// integer returns the value as an int if it is an int or a string containing an int.
+func (w Thing) integer() (int64, bool) {
+	switch w.Type {
+	case TypeInt:
+		return w.Value.(int64), true
+	case TypeString:
+		i, err := strconv.ParseInt(strings.TrimSpace(w.Value.(string)), 10, 64)
+		return i, err == nil
+	case TypeAny:
+		if v := w.guessAny(); v.Type == TypeInt {
+			return v.Value.(int64), true
+		}
+	}
+	return 0, false
+}
+
 func (w Thing) GreaterThan(other *Thing) bool {
 	return w.Float() > other.Float()
 }
@@ -661,19 +864,97 @@ func (w Thing) IsNil() bool {
 	return w.Value == nil
 }
 
+// Plain returns the value without type information, so it's encoded as plain JSON.
+func (w Thing) Plain() any {
+	switch w.Type {
+	case TypeArray:
+		res := make([]any, len(w.Array()))
+		for i, v := range w.Array() {
+			res[i] = v.Plain()
+		}
+		return res
+	case TypeObject:
+		res := make(map[string]any, len(w.Object()))
+		for k, v := range w.Object() {
+			res[k] = v.Plain()
+		}
+		return res
+	default:
+		return w.Value
+	}
+}
+
 func (w Thing) Append(other Thing) Thing {
 	// TODO: implement for arrays
 	return NewString(w.String() + other.String())
 }
 
 func (w Thing) Add(other Thing) Thing {
+	switch w.Type {
+	case TypeTime:
+		return NewTime(w.Time().Add(other.Duration()))
+	case TypeDuration:
+		return NewDuration(w.Duration() + other.Duration())
+	}
+
+	// Ints stay ints unless the result overflows
+	if a, b, ok := integers(w, other); ok {
+		if res := a + b; (res > a) == (b > 0) {
+			return NewInt(res)
+		}
+	}
+
 	return NewFloat(w.Float() + other.Float())
 }
 
 func (w Thing) Sub(other Thing) Thing {
+	switch w.Type {
+	case TypeTime:
+		if other.Type == TypeTime {
+			return NewDuration(w.Time().Sub(other.Time()))
+		}
+		return NewTime(w.Time().Add(-other.Duration()))
+	case TypeDuration:
+		return NewDuration(w.Duration() - other.Duration())
+	}
+
+	if a, b, ok := integers(w, other); ok {
+		if res := a - b; (res < a) == (b > 0) {
+			return NewInt(res)
+		}
+	}
+
 	return NewFloat(w.Float() - other.Float())
 }
 
+func (w Thing) Mul(other Thing) Thing {
+	if w.Type == TypeDuration {
+		return NewDuration(time.Duration(float64(w.Duration()) * other.Float()))
+	}
+
+	if a, b, ok := integers(w, other); ok {
+		res := a * b
+		if a == 0 || (res/a == b && !(a == -1 && b == math.MinInt64) && !(b == -1 && a == math.MinInt64)) {
+			return NewInt(res)
+		}
+	}
+
+	return NewFloat(w.Float() * other.Float())
+}
+
+// integers returns both values as ints if both of them are ints.
+func integers(a, b Thing) (int64, int64, bool) {
+	x, ok := a.integer()
+	if !ok {
+		return 0, 0, false
+	}
+	y, ok := b.integer()
+	if !ok {
+		return 0, 0, false
+	}
+	return x, y, true
+}
+
 type ToThing interface {
 	Thing() Thing
 }
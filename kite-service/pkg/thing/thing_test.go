@@ -2,10 +2,13 @@ package thing
 
 import (
 	"encoding/json"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/diamondburned/arikawa/v3/discord"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGuessType(t *testing.T) {
@@ -18,6 +21,8 @@ func TestGuessType(t *testing.T) {
 		{name: "int", value: 1, expected: TypeInt},
 		{name: "float", value: 1.1, expected: TypeFloat},
 		{name: "bool", value: true, expected: TypeBool},
+		{name: "time", value: time.Now(), expected: TypeTime},
+		{name: "duration", value: time.Minute, expected: TypeDuration},
 		{name: "array", value: []Thing{NewInt(1), NewInt(2), NewInt(3)}, expected: TypeArray},
 		{name: "object", value: map[string]Thing{"a": NewInt(1), "b": NewInt(2)}, expected: TypeObject},
 		{name: "discord_message", value: discord.Message{ID: 123}, expected: TypeDiscordMessage},
@@ -46,6 +51,10 @@ func TestMarshalUnmarshalJSON(t *testing.T) {
 		{name: "int", value: 1},
 		{name: "float", value: 1.0},
 		{name: "bool", value: true},
+		{name: "time", value: time.Date(2024, 5, 1, 12, 30, 0, 500, time.UTC), equalValue: func(t *testing.T, old, new Thing) {
+			assert.True(t, old.Time().Equal(new.Time()))
+		}},
+		{name: "duration", value: 90 * time.Minute},
 		{name: "array", value: []Thing{NewInt(1), NewInt(2), NewObject(map[string]Thing{"a": NewInt(3)})}},
 		{name: "object", value: map[string]Thing{"a": NewInt(1), "b": NewObject(map[string]Thing{"c": NewInt(2)})}},
 		{name: "discord_message", value: discord.Message{ID: 123}, equalValue: func(t *testing.T, old, new Thing) {
@@ -250,3 +259,93 @@ func TestStringConversions(t *testing.T) {
 		})
 	}
 }
+
+func TestTimeArithmetic(t *testing.T) {
+	start := NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	end := start.Add(NewDuration(36 * time.Hour))
+
+	assert.Equal(t, TypeTime, end.Type)
+	assert.Equal(t, time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC), end.Time())
+	assert.Equal(t, NewDuration(36*time.Hour), end.Sub(start))
+	assert.Equal(t, start.Time(), end.Sub(NewString("36h")).Time())
+	assert.True(t, end.GreaterThan(&start))
+	assert.Equal(t, NewDuration(2*time.Minute), NewDuration(time.Minute).Add(NewInt(60)))
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{value: "1h30m", expected: 90 * time.Minute},
+		{value: "2d", expected: 48 * time.Hour},
+		{value: "0.5d", expected: 12 * time.Hour},
+		{value: "60", expected: time.Minute},
+		{value: "1.5", expected: 1500 * time.Millisecond},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			d, err := ParseDuration(test.value)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, d)
+			assert.Equal(t, test.expected, NewString(test.value).Duration())
+		})
+	}
+
+	for _, value := range []string{"", "forever", "xd"} {
+		_, err := ParseDuration(value)
+		assert.Error(t, err, value)
+	}
+
+	start := NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), start.Add(NewString("2d")).Time())
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 1, 0, 0, time.UTC), start.Add(NewString("60")).Time())
+}
+
+func TestIntArithmetic(t *testing.T) {
+	assert.Equal(t, NewInt(5), NewInt(2).Add(NewInt(3)))
+	assert.Equal(t, NewInt(-1), NewInt(2).Sub(NewInt(3)))
+	assert.Equal(t, NewInt(6), NewInt(2).Mul(NewInt(3)))
+	assert.Equal(t, NewInt(3), NewInt(2).Add(NewString("1")), "strings containing ints are ints")
+	assert.Equal(t, NewInt(3), NewAny(float64(2)).Add(NewInt(1)), "decoded JSON numbers are ints when they are integral")
+
+	assert.Equal(t, NewFloat(3.5), NewInt(2).Add(NewFloat(1.5)))
+	assert.Equal(t, NewFloat(2.5), NewInt(5).Mul(NewFloat(0.5)))
+
+	// Results that don't fit into an int fall back to floats
+	assert.Equal(t, TypeFloat, NewInt(math.MaxInt64).Add(NewInt(1)).Type)
+	assert.Equal(t, TypeFloat, NewInt(math.MinInt64).Sub(NewInt(1)).Type)
+	assert.Equal(t, TypeFloat, NewInt(math.MaxInt64).Mul(NewInt(2)).Type)
+	assert.Equal(t, TypeFloat, NewInt(-1).Mul(NewInt(math.MinInt64)).Type)
+}
+
+func TestEqualsStrict(t *testing.T) {
+	tests := []struct {
+		name  string
+		a     Thing
+		b     Thing
+		equal bool
+	}{
+		{name: "same int", a: NewInt(1), b: NewInt(1), equal: true},
+		{name: "int and float", a: NewInt(1), b: NewFloat(1.0), equal: true},
+		{name: "different int", a: NewInt(1), b: NewInt(2)},
+		{name: "int and string", a: NewInt(1), b: NewString("1")},
+		{name: "bool and string", a: NewBool(true), b: NewString("true")},
+		{name: "null and empty string", a: Null, b: NewString("")},
+		{name: "null", a: Null, b: Null, equal: true},
+		{name: "decoded json", a: NewAny(float64(5)), b: NewInt(5), equal: true},
+		{name: "array", a: NewArray([]Thing{NewInt(1), NewString("a")}), b: NewArray([]Thing{NewFloat(1.0), NewString("a")}), equal: true},
+		{name: "array with different item", a: NewArray([]Thing{NewInt(1)}), b: NewArray([]Thing{NewString("1")})},
+		{name: "object", a: NewObject(map[string]Thing{"a": NewInt(1)}), b: NewObject(map[string]Thing{"a": NewInt(1)}), equal: true},
+		{name: "object with different key", a: NewObject(map[string]Thing{"a": NewInt(1)}), b: NewObject(map[string]Thing{"b": NewInt(1)})},
+		{name: "time in different zones", a: NewTime(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)), b: NewTime(time.Date(2024, 1, 1, 13, 0, 0, 0, time.FixedZone("CET", 3600))), equal: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.equal, test.a.EqualsStrict(&test.b))
+			assert.Equal(t, test.equal, test.b.EqualsStrict(&test.a))
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package wasm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+const hostModuleName = "kite"
+
+// maxHTTPResponseBodySize is the maximum size of HTTP response bodies that are passed to modules in bytes.
+const maxHTTPResponseBodySize = 1024 * 1024
+
+// Host gives modules access to the same providers as flows.
+type Host struct {
+	Discord  provider.DiscordProvider
+	HTTP     provider.HTTPProvider
+	Log      provider.LogProvider
+	Variable provider.VariableProvider
+
+	// StartOperation is called with the credit cost before every host call.
+	// When it returns an error, the module is aborted and the error is returned from Runtime.Call.
+	StartOperation func(credits int) error
+}
+
+type hostContextKey struct{}
+
+func withHost(ctx context.Context, host *Host) context.Context {
+	return context.WithValue(ctx, hostContextKey{}, host)
+}
+
+func hostFromContext(ctx context.Context) *Host {
+	host, _ := ctx.Value(hostContextKey{}).(*Host)
+	return host
+}
+
+type hostCallRequest struct {
+	Op   string          `json:"op"`
+	Args json.RawMessage `json:"args"`
+}
+
+type hostCallResponse struct {
+	Result any    `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func instantiateHostModule(ctx context.Context, r wazero.Runtime, maxIOSize int) error {
+	_, err := r.NewHostModuleBuilder(hostModuleName).
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, mod api.Module, level uint32, ptr uint32, size uint32) {
+			host := mustHost(ctx)
+			host.mustStartOperation(1)
+
+			if maxIOSize != 0 && int(size) > maxIOSize {
+				panic(fmt.Errorf("log message exceeds maximum size of %d bytes", maxIOSize))
+			}
+
+			msg, ok := mod.Memory().Read(ptr, size)
+			if !ok {
+				panic(errors.New("log message is out of range"))
+			}
+
+			if host.Log != nil {
+				host.Log.CreateLogEntry(ctx, logLevel(level), string(msg))
+			}
+		}).
+		Export("log").
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, mod api.Module, ptr uint32, size uint32) uint64 {
+			host := mustHost(ctx)
+
+			if maxIOSize != 0 && int(size) > maxIOSize {
+				panic(fmt.Errorf("host call exceeds maximum size of %d bytes", maxIOSize))
+			}
+
+			raw, ok := mod.Memory().Read(ptr, size)
+			if !ok {
+				panic(errors.New("host call is out of range"))
+			}
+
+			var req hostCallRequest
+			var res hostCallResponse
+			if err := json.Unmarshal(raw, &req); err != nil {
+				res.Error = fmt.Sprintf("invalid host call: %v", err)
+			} else {
+				host.mustStartOperation(opCreditsCost(req.Op))
+
+				result, err := host.call(ctx, req.Op, req.Args)
+				if err != nil {
+					res.Error = err.Error()
+				} else {
+					res.Result = result
+				}
+			}
+
+			out, err := json.Marshal(res)
+			if err != nil {
+				panic(fmt.Errorf("failed to encode host call response: %w", err))
+			}
+
+			if maxIOSize != 0 && len(out) > maxIOSize {
+				out, _ = json.Marshal(hostCallResponse{
+					Error: fmt.Sprintf("host call response exceeds maximum size of %d bytes", maxIOSize),
+				})
+			}
+
+			outPtr, err := writeGuestBytes(ctx, mod, out)
+			if err != nil {
+				panic(err)
+			}
+
+			return packGuestPointer(outPtr, uint32(len(out)))
+		}).
+		Export("call").
+		Instantiate(ctx)
+	return err
+}
+
+func mustHost(ctx context.Context) *Host {
+	host := hostFromContext(ctx)
+	if host == nil {
+		panic(errors.New("module was called without a host"))
+	}
+	return host
+}
+
+// mustStartOperation aborts the module by panicking when a limit has been reached.
+// wazero recovers the panic and returns the error from the function call.
+func (h *Host) mustStartOperation(credits int) {
+	if h.StartOperation == nil {
+		return
+	}
+
+	if err := h.StartOperation(credits); err != nil {
+		panic(err)
+	}
+}
+
+func (h *Host) call(ctx context.Context, op string, args json.RawMessage) (any, error) {
+	handler, ok := hostOps[op]
+	if !ok {
+		return nil, fmt.Errorf("unknown host call: %s", op)
+	}
+
+	if strings.HasPrefix(op, "discord.") && h.Discord == nil {
+		return nil, errors.New("discord is not available")
+	}
+
+	return handler(ctx, h, args)
+}
+
+// opCreditsCost returns the credits for a host call, it matches the cost of the equivalent flow node.
+func opCreditsCost(op string) int {
+	switch op {
+	case "http.request":
+		return 3
+	default:
+		return 1
+	}
+}
+
+func logLevel(level uint32) provider.LogLevel {
+	switch level {
+	case 0:
+		return provider.LogLevelDebug
+	case 1:
+		return provider.LogLevelInfo
+	case 2:
+		return provider.LogLevelWarn
+	default:
+		return provider.LogLevelError
+	}
+}
+
+type httpRequestArgs struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+type httpResponseResult struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+func httpRequest(ctx context.Context, h *Host, args httpRequestArgs) (any, error) {
+	if h.HTTP == nil {
+		return nil, errors.New("http is not available")
+	}
+
+	method := args.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var body io.Reader
+	if args.Body != "" {
+		body = bytes.NewReader([]byte(args.Body))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, args.URL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range args.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := h.HTTP.HTTPRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPResponseBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	headers := make(map[string]string, len(resp.Header))
+	for key := range resp.Header {
+		headers[key] = resp.Header.Get(key)
+	}
+
+	return httpResponseResult{
+		Status:  resp.StatusCode,
+		Headers: headers,
+		Body:    string(respBody),
+	}, nil
+}
@@ -0,0 +1,544 @@
+package wasm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+const fuelExportName = "kite_fuel"
+
+const (
+	sectionCustom    = 0
+	sectionImport    = 2
+	sectionGlobal    = 6
+	sectionExport    = 7
+	sectionCode      = 10
+	importKindGlobal = 3
+	exportKindGlobal = 3
+)
+
+// sectionOrder is the order in which the known sections must appear in a binary.
+var sectionOrder = map[byte]int{1: 1, 2: 2, 3: 3, 4: 4, 5: 5, 13: 6, 6: 7, 7: 8, 8: 9, 9: 10, 12: 11, 10: 12, 11: 13}
+
+var errUnexpectedEnd = errors.New("unexpected end of binary")
+
+type wasmSection struct {
+	id      byte
+	content []byte
+}
+
+// meterModule instruments the binary so that it consumes fuel while it runs.
+//
+// A mutable i64 global that starts at maxFuel is added and exported as "kite_fuel".
+// Every function entry and every loop iteration subtracts the number of instructions of its body,
+// not counting nested loops that are charged on their own, and traps once the fuel is negative.
+// This is an upper bound of the executed instructions that doesn't depend on the speed of the host.
+// Every call runs in a fresh instance, so maxFuel is the limit of a single call.
+func meterModule(wasmBytes []byte, maxFuel int64) ([]byte, error) {
+	if len(wasmBytes) < 8 || !bytes.Equal(wasmBytes[:8], []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}) {
+		return nil, errors.New("invalid header")
+	}
+
+	var sections []wasmSection
+	r := &wasmReader{data: wasmBytes, pos: 8}
+	for !r.done() {
+		id, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		size, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		content, err := r.bytes(int(size))
+		if err != nil {
+			return nil, err
+		}
+		sections = append(sections, wasmSection{id: id, content: content})
+	}
+
+	importedGlobals, err := countImportedGlobals(sections)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read imports: %w", err)
+	}
+
+	definedGlobals := uint32(0)
+	for _, s := range sections {
+		if s.id == sectionGlobal {
+			if definedGlobals, err = (&wasmReader{data: s.content}).u32(); err != nil {
+				return nil, fmt.Errorf("failed to read globals: %w", err)
+			}
+		}
+	}
+	fuelGlobal := importedGlobals + definedGlobals
+
+	fuelGlobalEntry := concatBytes([]byte{0x7e, 0x01, 0x42}, appendSLEB(nil, maxFuel), []byte{0x0b})
+	fuelExportEntry := concatBytes(appendULEB(nil, uint64(len(fuelExportName))), []byte(fuelExportName), []byte{exportKindGlobal}, appendULEB(nil, uint64(fuelGlobal)))
+
+	sections, err = appendToSection(sections, sectionGlobal, fuelGlobalEntry, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add fuel global: %w", err)
+	}
+	sections, err = appendToSection(sections, sectionExport, fuelExportEntry, checkFuelExport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add fuel export: %w", err)
+	}
+
+	for i, s := range sections {
+		if s.id != sectionCode {
+			continue
+		}
+		content, err := meterCode(s.content, fuelGlobal)
+		if err != nil {
+			return nil, fmt.Errorf("failed to meter code: %w", err)
+		}
+		sections[i].content = content
+	}
+
+	res := append([]byte{}, wasmBytes[:8]...)
+	for _, s := range sections {
+		res = append(res, s.id)
+		res = appendULEB(res, uint64(len(s.content)))
+		res = append(res, s.content...)
+	}
+
+	return res, nil
+}
+
+func countImportedGlobals(sections []wasmSection) (uint32, error) {
+	var count uint32
+	for _, s := range sections {
+		if s.id != sectionImport {
+			continue
+		}
+
+		r := &wasmReader{data: s.content}
+		n, err := r.u32()
+		if err != nil {
+			return 0, err
+		}
+
+		for i := uint32(0); i < n; i++ {
+			if err := r.skipName(); err != nil {
+				return 0, err
+			}
+			if err := r.skipName(); err != nil {
+				return 0, err
+			}
+			kind, err := r.byte()
+			if err != nil {
+				return 0, err
+			}
+
+			switch kind {
+			case 0: // function
+				err = r.skipLEB()
+			case 1: // table
+				if err = r.skip(1); err == nil {
+					err = r.skipLimits()
+				}
+			case 2: // memory
+				err = r.skipLimits()
+			case importKindGlobal:
+				count++
+				err = r.skip(2)
+			default:
+				err = fmt.Errorf("unsupported import kind %d", kind)
+			}
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return count, nil
+}
+
+// appendToSection adds the entry to the vector of the section and creates the section if it doesn't exist.
+func appendToSection(sections []wasmSection, id byte, entry []byte, check func(r *wasmReader, count uint32) error) ([]wasmSection, error) {
+	for i, s := range sections {
+		if s.id != id {
+			continue
+		}
+
+		r := &wasmReader{data: s.content}
+		count, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		entries := r.data[r.pos:]
+
+		if check != nil {
+			if err := check(r, count); err != nil {
+				return nil, err
+			}
+		}
+
+		sections[i].content = concatBytes(appendULEB(nil, uint64(count)+1), entries, entry)
+		return sections, nil
+	}
+
+	section := wasmSection{id: id, content: concatBytes([]byte{1}, entry)}
+	for i, s := range sections {
+		if s.id != sectionCustom && sectionOrder[s.id] > sectionOrder[id] {
+			return append(sections[:i], append([]wasmSection{section}, sections[i:]...)...), nil
+		}
+	}
+	return append(sections, section), nil
+}
+
+func checkFuelExport(r *wasmReader, count uint32) error {
+	for i := uint32(0); i < count; i++ {
+		size, err := r.u32()
+		if err != nil {
+			return err
+		}
+		name, err := r.bytes(int(size))
+		if err != nil {
+			return err
+		}
+		if string(name) == fuelExportName {
+			return fmt.Errorf("export %q is reserved", fuelExportName)
+		}
+		if err := r.skip(1); err != nil {
+			return err
+		}
+		if err := r.skipLEB(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func meterCode(content []byte, fuelGlobal uint32) ([]byte, error) {
+	r := &wasmReader{data: content}
+	count, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+
+	res := appendULEB(nil, uint64(count))
+	for i := uint32(0); i < count; i++ {
+		size, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		body, err := r.bytes(int(size))
+		if err != nil {
+			return nil, err
+		}
+
+		metered, err := meterFunction(body, fuelGlobal)
+		if err != nil {
+			return nil, fmt.Errorf("function %d: %w", i, err)
+		}
+		res = appendULEB(res, uint64(len(metered)))
+		res = append(res, metered...)
+	}
+
+	return res, nil
+}
+
+// meterScope is the function body or a loop body that is charged as a whole.
+type meterScope struct {
+	// pos is the offset in the function body where the charge is inserted.
+	pos  int
+	cost int64
+}
+
+func meterFunction(body []byte, fuelGlobal uint32) ([]byte, error) {
+	r := &wasmReader{data: body}
+
+	locals, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint32(0); i < locals; i++ {
+		if err := r.skipLEB(); err != nil {
+			return nil, err
+		}
+		if err := r.skip(1); err != nil {
+			return nil, err
+		}
+	}
+
+	scopes := []meterScope{{pos: r.pos}}
+	// open contains the index of the scope of every open loop and -1 for other blocks.
+	var open []int
+	// current contains the indexes of the scopes of the open loops, the function scope is always at the bottom.
+	current := []int{0}
+
+	for !r.done() {
+		scopes[current[len(current)-1]].cost++
+
+		op, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		if err := skipImmediates(r, op); err != nil {
+			return nil, err
+		}
+
+		switch op {
+		case 0x02, 0x04: // block, if
+			open = append(open, -1)
+		case 0x03: // loop
+			scopes = append(scopes, meterScope{pos: r.pos})
+			open = append(open, len(scopes)-1)
+			current = append(current, len(scopes)-1)
+		case 0x0b: // end
+			if len(open) == 0 {
+				if !r.done() {
+					return nil, errors.New("instructions after the end of the function")
+				}
+				break
+			}
+			if open[len(open)-1] >= 0 {
+				current = current[:len(current)-1]
+			}
+			open = open[:len(open)-1]
+		}
+	}
+
+	res := make([]byte, 0, len(body)+len(scopes)*20)
+	last := 0
+	for _, scope := range scopes {
+		res = append(res, body[last:scope.pos]...)
+		res = appendFuelCharge(res, fuelGlobal, scope.cost)
+		last = scope.pos
+	}
+	res = append(res, body[last:]...)
+
+	return res, nil
+}
+
+// appendFuelCharge appends instructions that subtract the cost from the fuel and trap when it's negative.
+func appendFuelCharge(res []byte, fuelGlobal uint32, cost int64) []byte {
+	res = append(res, 0x23) // global.get
+	res = appendULEB(res, uint64(fuelGlobal))
+	res = append(res, 0x42) // i64.const
+	res = appendSLEB(res, cost)
+	res = append(res, 0x7d, 0x24) // i64.sub, global.set
+	res = appendULEB(res, uint64(fuelGlobal))
+	res = append(res, 0x23) // global.get
+	res = appendULEB(res, uint64(fuelGlobal))
+	// i64.const 0, i64.lt_s, if, unreachable, end
+	return append(res, 0x42, 0x00, 0x53, 0x04, 0x40, 0x00, 0x0b)
+}
+
+func skipImmediates(r *wasmReader, op byte) error {
+	switch {
+	case op == 0x00 || op == 0x01 || op == 0x05 || op == 0x0b || op == 0x0f || op == 0x1a || op == 0x1b || op == 0xd1:
+		return nil
+	case op >= 0x45 && op <= 0xc4: // numeric instructions
+		return nil
+	case op == 0x02 || op == 0x03 || op == 0x04:
+		// Block types are a single byte or a signed type index.
+		return r.skipLEB()
+	case op == 0x0c || op == 0x0d || op == 0x10 || op == 0x12 || (op >= 0x20 && op <= 0x26) || op == 0x3f || op == 0x40 || op == 0xd2:
+		return r.skipLEB()
+	case op == 0x11 || op == 0x13:
+		return r.skipLEBs(2)
+	case op == 0x0e: // br_table
+		n, err := r.u32()
+		if err != nil {
+			return err
+		}
+		return r.skipLEBs(int(n) + 1)
+	case op == 0x1c: // select with types
+		n, err := r.u32()
+		if err != nil {
+			return err
+		}
+		return r.skip(int(n))
+	case op >= 0x28 && op <= 0x3e: // loads and stores
+		return r.skipMemArg()
+	case op == 0x41 || op == 0x42:
+		return r.skipLEB()
+	case op == 0x43:
+		return r.skip(4)
+	case op == 0x44:
+		return r.skip(8)
+	case op == 0xd0: // ref.null
+		return r.skip(1)
+	case op == 0xfc:
+		sub, err := r.u32()
+		if err != nil {
+			return err
+		}
+		switch {
+		case sub <= 7:
+			return nil
+		case sub == 8 || sub == 10 || sub == 12 || sub == 14:
+			return r.skipLEBs(2)
+		case sub <= 17:
+			return r.skipLEB()
+		}
+		return fmt.Errorf("unsupported instruction 0xfc %d", sub)
+	case op == 0xfd: // SIMD
+		sub, err := r.u32()
+		if err != nil {
+			return err
+		}
+		switch {
+		case sub <= 11 || sub == 92 || sub == 93:
+			return r.skipMemArg()
+		case sub == 12 || sub == 13:
+			return r.skip(16)
+		case sub >= 21 && sub <= 34:
+			return r.skip(1)
+		case sub >= 84 && sub <= 91:
+			if err := r.skipMemArg(); err != nil {
+				return err
+			}
+			return r.skip(1)
+		}
+		return nil
+	case op == 0xfe: // atomics
+		sub, err := r.u32()
+		if err != nil {
+			return err
+		}
+		if sub == 3 {
+			return r.skip(1)
+		}
+		return r.skipMemArg()
+	}
+
+	return fmt.Errorf("unsupported instruction 0x%02x", op)
+}
+
+type wasmReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *wasmReader) done() bool {
+	return r.pos >= len(r.data)
+}
+
+func (r *wasmReader) byte() (byte, error) {
+	if r.done() {
+		return 0, errUnexpectedEnd
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *wasmReader) bytes(n int) ([]byte, error) {
+	if n < 0 || r.pos+n > len(r.data) {
+		return nil, errUnexpectedEnd
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+func (r *wasmReader) skip(n int) error {
+	_, err := r.bytes(n)
+	return err
+}
+
+func (r *wasmReader) u32() (uint32, error) {
+	var res uint32
+	for shift := 0; shift < 35; shift += 7 {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		res |= uint32(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return res, nil
+		}
+	}
+	return 0, errors.New("integer is too long")
+}
+
+// skipLEB skips a signed or unsigned LEB128 integer of up to 64 bits.
+func (r *wasmReader) skipLEB() error {
+	for i := 0; i < 10; i++ {
+		b, err := r.byte()
+		if err != nil {
+			return err
+		}
+		if b&0x80 == 0 {
+			return nil
+		}
+	}
+	return errors.New("integer is too long")
+}
+
+func (r *wasmReader) skipLEBs(n int) error {
+	for i := 0; i < n; i++ {
+		if err := r.skipLEB(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *wasmReader) skipName() error {
+	size, err := r.u32()
+	if err != nil {
+		return err
+	}
+	return r.skip(int(size))
+}
+
+func (r *wasmReader) skipLimits() error {
+	flags, err := r.byte()
+	if err != nil {
+		return err
+	}
+	if flags&0x01 != 0 {
+		return r.skipLEBs(2)
+	}
+	return r.skipLEB()
+}
+
+func (r *wasmReader) skipMemArg() error {
+	align, err := r.u32()
+	if err != nil {
+		return err
+	}
+	// Bit 6 of the alignment signals an explicit memory index.
+	if align&0x40 != 0 {
+		if err := r.skipLEB(); err != nil {
+			return err
+		}
+	}
+	return r.skipLEB()
+}
+
+func appendULEB(res []byte, v uint64) []byte {
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v != 0 {
+			res = append(res, b|0x80)
+			continue
+		}
+		return append(res, b)
+	}
+}
+
+func appendSLEB(res []byte, v int64) []byte {
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0) {
+			return append(res, b)
+		}
+		res = append(res, b|0x80)
+	}
+}
+
+func concatBytes(parts ...[]byte) []byte {
+	var res []byte
+	for _, part := range parts {
+		res = append(res, part...)
+	}
+	return res
+}
@@ -0,0 +1,197 @@
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"gopkg.in/guregu/null.v4"
+)
+
+type hostOp func(ctx context.Context, h *Host, args json.RawMessage) (any, error)
+
+// hostOps contains the operations that modules can perform through the "call" host function.
+// The arguments and results are JSON objects.
+var hostOps = map[string]hostOp{
+	"variable.get":    op(variableGet),
+	"variable.set":    op(variableSet),
+	"variable.delete": op(variableDelete),
+
+	"http.request": op(httpRequest),
+
+	"discord.get_guild": op(func(ctx context.Context, h *Host, args discordArgs) (any, error) {
+		return notFoundAsNil(h.Discord.Guild(ctx, args.GuildID))
+	}),
+	"discord.get_channel": op(func(ctx context.Context, h *Host, args discordArgs) (any, error) {
+		return notFoundAsNil(h.Discord.Channel(ctx, args.ChannelID))
+	}),
+	"discord.get_user": op(func(ctx context.Context, h *Host, args discordArgs) (any, error) {
+		return notFoundAsNil(h.Discord.User(ctx, args.UserID))
+	}),
+	"discord.get_member": op(func(ctx context.Context, h *Host, args discordArgs) (any, error) {
+		return notFoundAsNil(h.Discord.Member(ctx, args.GuildID, args.UserID))
+	}),
+	"discord.get_role": op(func(ctx context.Context, h *Host, args discordArgs) (any, error) {
+		return notFoundAsNil(h.Discord.Role(ctx, args.GuildID, args.RoleID))
+	}),
+	"discord.get_message": op(func(ctx context.Context, h *Host, args discordArgs) (any, error) {
+		return notFoundAsNil(h.Discord.Message(ctx, args.ChannelID, args.MessageID))
+	}),
+	"discord.create_message": op(func(ctx context.Context, h *Host, args discordMessageArgs[api.SendMessageData]) (any, error) {
+		return h.Discord.CreateMessage(ctx, args.ChannelID, args.Data)
+	}),
+	"discord.edit_message": op(func(ctx context.Context, h *Host, args discordMessageArgs[api.EditMessageData]) (any, error) {
+		return h.Discord.EditMessage(ctx, args.ChannelID, args.MessageID, args.Data)
+	}),
+	"discord.delete_message": op(func(ctx context.Context, h *Host, args discordArgs) (any, error) {
+		return nil, h.Discord.DeleteMessage(ctx, args.ChannelID, args.MessageID, args.Reason)
+	}),
+	"discord.add_member_role": op(func(ctx context.Context, h *Host, args discordArgs) (any, error) {
+		return nil, h.Discord.AddMemberRole(ctx, args.GuildID, args.UserID, args.RoleID, args.Reason)
+	}),
+	"discord.remove_member_role": op(func(ctx context.Context, h *Host, args discordArgs) (any, error) {
+		return nil, h.Discord.RemoveMemberRole(ctx, args.GuildID, args.UserID, args.RoleID, args.Reason)
+	}),
+	"discord.kick_member": op(func(ctx context.Context, h *Host, args discordArgs) (any, error) {
+		return nil, h.Discord.KickMember(ctx, args.GuildID, args.UserID, args.Reason)
+	}),
+	"discord.ban_member": op(func(ctx context.Context, h *Host, args discordMessageArgs[api.BanData]) (any, error) {
+		return nil, h.Discord.BanMember(ctx, args.GuildID, args.UserID, args.Data)
+	}),
+	"discord.create_interaction_response": op(func(ctx context.Context, h *Host, args discordInteractionArgs[api.InteractionResponse]) (any, error) {
+		res, err := h.Discord.CreateInteractionResponse(ctx, args.InteractionID, args.InteractionToken, args.Data)
+		if err != nil || res == nil {
+			return nil, err
+		}
+		return res.Message, nil
+	}),
+	"discord.edit_interaction_response": op(func(ctx context.Context, h *Host, args discordInteractionArgs[api.EditInteractionResponseData]) (any, error) {
+		return h.Discord.EditInteractionResponse(ctx, args.ApplicationID, args.InteractionToken, args.Data)
+	}),
+	"discord.create_interaction_followup": op(func(ctx context.Context, h *Host, args discordInteractionArgs[api.InteractionResponseData]) (any, error) {
+		return h.Discord.CreateInteractionFollowup(ctx, args.ApplicationID, args.InteractionToken, args.Data)
+	}),
+}
+
+func op[T any](fn func(ctx context.Context, h *Host, args T) (any, error)) hostOp {
+	return func(ctx context.Context, h *Host, raw json.RawMessage) (any, error) {
+		var args T
+		if len(raw) != 0 {
+			if err := json.Unmarshal(raw, &args); err != nil {
+				return nil, fmt.Errorf("invalid arguments: %w", err)
+			}
+		}
+
+		return fn(ctx, h, args)
+	}
+}
+
+type discordArgs struct {
+	GuildID   discord.GuildID    `json:"guild_id"`
+	ChannelID discord.ChannelID  `json:"channel_id"`
+	UserID    discord.UserID     `json:"user_id"`
+	RoleID    discord.RoleID     `json:"role_id"`
+	MessageID discord.MessageID  `json:"message_id"`
+	Reason    api.AuditLogReason `json:"reason"`
+}
+
+type discordMessageArgs[T any] struct {
+	discordArgs
+	Data T `json:"data"`
+}
+
+type discordInteractionArgs[T any] struct {
+	InteractionID    discord.InteractionID `json:"interaction_id"`
+	InteractionToken string                `json:"interaction_token"`
+	ApplicationID    discord.AppID         `json:"application_id"`
+	Data             T                     `json:"data"`
+}
+
+// notFoundAsNil turns not found errors into a null result, so modules can tell them apart from failures.
+func notFoundAsNil[T any](v *T, err error) (any, error) {
+	if errors.Is(err, provider.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil || v == nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+type variableArgs struct {
+	VariableID string                     `json:"variable_id"`
+	Scope      null.String                `json:"scope"`
+	Operation  provider.VariableOperation `json:"operation"`
+	Value      any                        `json:"value"`
+	Key        string                     `json:"key"`
+	// Expected is raw so a missing value can be told apart from null, compare_and_swap requires it.
+	Expected  json.RawMessage `json:"expected"`
+	MaxLength int             `json:"max_length"`
+}
+
+func variableGet(ctx context.Context, h *Host, args variableArgs) (any, error) {
+	if h.Variable == nil {
+		return nil, errors.New("variables are not available")
+	}
+
+	value, err := h.Variable.Variable(ctx, args.VariableID, args.Scope)
+	if err != nil {
+		if errors.Is(err, provider.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return value.Plain(), nil
+}
+
+func variableSet(ctx context.Context, h *Host, args variableArgs) (any, error) {
+	if h.Variable == nil {
+		return nil, errors.New("variables are not available")
+	}
+
+	operation := args.Operation
+	if operation == "" {
+		operation = provider.VariableOperationOverwrite
+	}
+
+	opArgs := provider.VariableOperationArgs{
+		Key:       args.Key,
+		MaxLength: args.MaxLength,
+	}
+	if len(args.Expected) != 0 {
+		var expected any
+		if err := json.Unmarshal(args.Expected, &expected); err != nil {
+			return nil, fmt.Errorf("invalid expected value: %w", err)
+		}
+		v := thing.NewGuessTypeRecursive(expected)
+		opArgs.Expected = &v
+	}
+
+	value, err := h.Variable.UpdateVariable(
+		ctx,
+		args.VariableID,
+		args.Scope,
+		operation,
+		thing.NewGuessTypeRecursive(args.Value),
+		opArgs,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return value.Plain(), nil
+}
+
+func variableDelete(ctx context.Context, h *Host, args variableArgs) (any, error) {
+	if h.Variable == nil {
+		return nil, errors.New("variables are not available")
+	}
+
+	return nil, h.Variable.DeleteVariable(ctx, args.VariableID, args.Scope)
+}
@@ -0,0 +1,374 @@
+// Package wasm runs user-uploaded WebAssembly modules in a sandbox.
+//
+// Modules talk to the host through a small JSON based ABI:
+//   - The module must export its "memory" and a "kite_alloc(size i32) i32" function
+//     that the host uses to pass data into the module.
+//   - Callable functions have the signature "(ptr i32, len i32) i64". They receive their JSON input
+//     at ptr and return the location of their JSON output packed as ptr<<32 | len, 0 means no output.
+//   - The host module "kite" provides "log(level i32, ptr i32, len i32)" and
+//     "call(ptr i32, len i32) i64" to access Discord, variables and HTTP, see Host.
+//
+// WASI is available without any filesystem, network or environment access
+// so modules built with the standard toolchains of most languages can be used.
+//
+// Modules are metered with fuel, see meterModule, so that a call is cut off after a fixed amount
+// of work regardless of the load of the host. The duration limit is only a fallback for host calls.
+package wasm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+const (
+	// PageSize is the size of a WebAssembly memory page in bytes.
+	PageSize = 65536
+
+	allocFunctionName = "kite_alloc"
+	memoryExportName  = "memory"
+)
+
+var (
+	ErrFunctionNotFound = errors.New("function not found")
+	ErrTimeout          = errors.New("module execution timed out")
+	ErrFuelExhausted    = errors.New("module ran out of fuel")
+	ErrInvalidModule    = errors.New("invalid module")
+)
+
+type RuntimeConfig struct {
+	// MaxMemory is the maximum memory of a module instance in bytes, 0 means the WebAssembly maximum of 4 GiB.
+	MaxMemory int
+	// MaxDuration is the maximum duration of a single function call, 0 means no limit.
+	MaxDuration time.Duration
+	// MaxFuel is the maximum number of instructions of a single function call, 0 means no limit.
+	MaxFuel int64
+	// MaxIOSize is the maximum size of the input and output of a function and of host calls in bytes, 0 means no limit.
+	MaxIOSize int
+	// MaxCompiledModules is the number of compiled modules that are kept in memory, defaults to 100.
+	MaxCompiledModules int
+}
+
+// Module is a WASM binary that can be executed by the Runtime.
+type Module struct {
+	// Hash identifies the binary and is used as the key for caching compiled modules.
+	Hash  string
+	Bytes []byte
+	// Load returns the binary if Bytes is empty. It's only called when the module isn't compiled yet,
+	// so binaries don't have to be loaded from the database for every call.
+	Load func(ctx context.Context) ([]byte, error)
+}
+
+type Runtime struct {
+	config  RuntimeConfig
+	runtime wazero.Runtime
+	// inspector compiles binaries that are validated or listed. wazero shares the compiled code of identical binaries,
+	// so closing them in the main runtime would also close a cached module of the same binary.
+	inspector wazero.Runtime
+
+	mu       sync.Mutex
+	compiled map[string]*compiledModule
+	// order contains the hashes of the compiled modules from oldest to newest.
+	order []string
+}
+
+// compiledModule counts the calls that use a compiled module,
+// so it's only closed once it has been evicted and the last call using it has finished.
+type compiledModule struct {
+	module  wazero.CompiledModule
+	refs    int
+	evicted bool
+}
+
+func NewRuntime(ctx context.Context, config RuntimeConfig) (*Runtime, error) {
+	if config.MaxCompiledModules == 0 {
+		config.MaxCompiledModules = 100
+	}
+
+	runtimeConfig := wazero.NewRuntimeConfig().WithCloseOnContextDone(true)
+	if config.MaxMemory != 0 {
+		pages := config.MaxMemory / PageSize
+		if pages < 1 {
+			pages = 1
+		}
+		runtimeConfig = runtimeConfig.WithMemoryLimitPages(uint32(pages))
+	}
+
+	r := &Runtime{
+		config:    config,
+		runtime:   wazero.NewRuntimeWithConfig(ctx, runtimeConfig),
+		inspector: wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfigInterpreter()),
+		compiled:  make(map[string]*compiledModule),
+	}
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, r.runtime); err != nil {
+		return nil, fmt.Errorf("failed to instantiate WASI: %w", err)
+	}
+
+	if err := instantiateHostModule(ctx, r.runtime, config.MaxIOSize); err != nil {
+		return nil, fmt.Errorf("failed to instantiate host module: %w", err)
+	}
+
+	return r, nil
+}
+
+// Validate checks that the binary is a valid module that implements the ABI.
+func (r *Runtime) Validate(ctx context.Context, wasmBytes []byte) error {
+	compiled, err := r.inspector.CompileModule(ctx, wasmBytes)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidModule, err)
+	}
+	defer compiled.Close(ctx)
+
+	if r.config.MaxFuel != 0 {
+		if _, err := meterModule(wasmBytes, r.config.MaxFuel); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidModule, err)
+		}
+	}
+
+	if _, ok := compiled.ExportedMemories()[memoryExportName]; !ok {
+		return fmt.Errorf("%w: memory must be exported as %q", ErrInvalidModule, memoryExportName)
+	}
+
+	alloc, ok := compiled.ExportedFunctions()[allocFunctionName]
+	if !ok || !hasSignature(alloc, []api.ValueType{api.ValueTypeI32}, []api.ValueType{api.ValueTypeI32}) {
+		return fmt.Errorf("%w: function %q with signature (i32) -> i32 must be exported", ErrInvalidModule, allocFunctionName)
+	}
+
+	return nil
+}
+
+// Functions returns the names of the functions of the binary that can be called by the host.
+func (r *Runtime) Functions(ctx context.Context, wasmBytes []byte) ([]string, error) {
+	compiled, err := r.inspector.CompileModule(ctx, wasmBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidModule, err)
+	}
+	defer compiled.Close(ctx)
+
+	var names []string
+	for name, def := range compiled.ExportedFunctions() {
+		if name != allocFunctionName && isCallable(def) {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// Call calls the exported function of the module with the input and returns its output.
+// Every call runs in a fresh instance of the module, so no state is shared between calls.
+func (r *Runtime) Call(ctx context.Context, module Module, function string, input []byte, host *Host) ([]byte, error) {
+	if r.config.MaxIOSize != 0 && len(input) > r.config.MaxIOSize {
+		return nil, fmt.Errorf("input exceeds maximum size of %d bytes", r.config.MaxIOSize)
+	}
+
+	compiled, release, err := r.compile(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if r.config.MaxDuration != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.config.MaxDuration)
+		defer cancel()
+	}
+	ctx = withHost(ctx, host)
+
+	instance, err := r.runtime.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().
+		WithName("").
+		WithStartFunctions("_initialize"),
+	)
+	if err != nil {
+		return nil, convertError(nil, fmt.Errorf("failed to instantiate module: %w", err))
+	}
+	defer instance.Close(context.Background())
+
+	fn := instance.ExportedFunction(function)
+	if fn == nil || function == allocFunctionName || !isCallable(fn.Definition()) {
+		return nil, fmt.Errorf("%w: %s", ErrFunctionNotFound, function)
+	}
+
+	ptr, err := writeGuestBytes(ctx, instance, input)
+	if err != nil {
+		return nil, convertError(instance, err)
+	}
+
+	res, err := fn.Call(ctx, uint64(ptr), uint64(len(input)))
+	if err != nil {
+		return nil, convertError(instance, err)
+	}
+
+	output, err := readGuestBytes(instance, res[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if r.config.MaxIOSize != 0 && len(output) > r.config.MaxIOSize {
+		return nil, fmt.Errorf("output exceeds maximum size of %d bytes", r.config.MaxIOSize)
+	}
+
+	return output, nil
+}
+
+func (r *Runtime) Close(ctx context.Context) error {
+	if err := r.inspector.Close(ctx); err != nil {
+		return err
+	}
+	return r.runtime.Close(ctx)
+}
+
+// compile returns the compiled module and a function that must be called once it isn't used anymore.
+func (r *Runtime) compile(ctx context.Context, module Module) (wazero.CompiledModule, func(), error) {
+	r.mu.Lock()
+	if compiled, ok := r.compiled[module.Hash]; ok {
+		defer r.mu.Unlock()
+		return r.acquire(compiled)
+	}
+	r.mu.Unlock()
+
+	// Compiling can take a while, so we don't hold the lock here and accept that
+	// the same module is compiled twice when it's called concurrently for the first time.
+	wasmBytes := module.Bytes
+	if len(wasmBytes) == 0 && module.Load != nil {
+		var err error
+		if wasmBytes, err = module.Load(ctx); err != nil {
+			return nil, nil, fmt.Errorf("failed to load module: %w", err)
+		}
+	}
+
+	if r.config.MaxFuel != 0 {
+		var err error
+		if wasmBytes, err = meterModule(wasmBytes, r.config.MaxFuel); err != nil {
+			return nil, nil, fmt.Errorf("%w: %w", ErrInvalidModule, err)
+		}
+	}
+
+	wazeroModule, err := r.runtime.CompileModule(ctx, wasmBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidModule, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The duplicate isn't closed, because it shares the compiled code with the existing module
+	if existing, ok := r.compiled[module.Hash]; ok {
+		return r.acquire(existing)
+	}
+
+	compiled := &compiledModule{module: wazeroModule}
+	r.compiled[module.Hash] = compiled
+	r.order = append(r.order, module.Hash)
+
+	for len(r.order) > r.config.MaxCompiledModules {
+		oldest := r.order[0]
+		r.order = r.order[1:]
+
+		// Modules that are in use are closed once they are released.
+		// Instances that are still running keep working after the compiled module has been closed.
+		evicted := r.compiled[oldest]
+		evicted.evicted = true
+		if evicted.refs == 0 {
+			evicted.module.Close(ctx)
+		}
+		delete(r.compiled, oldest)
+	}
+
+	return r.acquire(compiled)
+}
+
+// acquire must be called with r.mu held.
+func (r *Runtime) acquire(compiled *compiledModule) (wazero.CompiledModule, func(), error) {
+	compiled.refs++
+
+	release := func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		compiled.refs--
+		if compiled.refs == 0 && compiled.evicted {
+			compiled.module.Close(context.Background())
+		}
+	}
+
+	return compiled.module, release, nil
+}
+
+func writeGuestBytes(ctx context.Context, mod api.Module, data []byte) (uint32, error) {
+	if len(data) == 0 {
+		return 0, nil
+	}
+
+	alloc := mod.ExportedFunction(allocFunctionName)
+	if alloc == nil {
+		return 0, fmt.Errorf("%w: function %q must be exported", ErrInvalidModule, allocFunctionName)
+	}
+
+	res, err := alloc.Call(ctx, uint64(len(data)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate guest memory: %w", err)
+	}
+
+	ptr := uint32(res[0])
+	if !mod.Memory().Write(ptr, data) {
+		return 0, fmt.Errorf("allocated guest memory is out of range")
+	}
+
+	return ptr, nil
+}
+
+func readGuestBytes(mod api.Module, packed uint64) ([]byte, error) {
+	ptr, size := uint32(packed>>32), uint32(packed)
+	if size == 0 {
+		return nil, nil
+	}
+
+	data, ok := mod.Memory().Read(ptr, size)
+	if !ok {
+		return nil, fmt.Errorf("guest memory is out of range")
+	}
+
+	// The returned slice is a view of the guest memory which is gone once the instance is closed.
+	res := make([]byte, len(data))
+	copy(res, data)
+	return res, nil
+}
+
+func packGuestPointer(ptr uint32, size uint32) uint64 {
+	return uint64(ptr)<<32 | uint64(size)
+}
+
+func isCallable(def api.FunctionDefinition) bool {
+	return hasSignature(
+		def,
+		[]api.ValueType{api.ValueTypeI32, api.ValueTypeI32},
+		[]api.ValueType{api.ValueTypeI64},
+	)
+}
+
+func hasSignature(def api.FunctionDefinition, params []api.ValueType, results []api.ValueType) bool {
+	return string(def.ParamTypes()) == string(params) && string(def.ResultTypes()) == string(results)
+}
+
+// convertError converts errors of a call to the errors of the limits that caused them.
+// The instance is used to tell traps of exhausted fuel from other traps and may be nil.
+func convertError(instance api.Module, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+
+	if instance != nil {
+		if fuel := instance.ExportedGlobal(fuelExportName); fuel != nil && int64(fuel.Get()) < 0 {
+			return ErrFuelExhausted
+		}
+	}
+	return err
+}
@@ -0,0 +1,338 @@
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kitecloud/kite/kite-service/pkg/provider"
+	"github.com/kitecloud/kite/kite-service/pkg/thing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tetratelabs/wazero"
+	"gopkg.in/guregu/null.v4"
+)
+
+// testModuleBytes is a hand-assembled module that implements the ABI with a bump allocator.
+// It exports these functions:
+//   - echo: returns its input
+//   - call_host: passes its input to the "call" host function and returns the response
+//   - log_info: logs its input with the info level and returns nothing
+//   - spin: never returns
+func testModuleBytes() []byte {
+	const (
+		i32 = 0x7f
+		i64 = 0x7e
+	)
+
+	types := vec(
+		[]byte{0x60, 2, i32, i32, 1, i64}, // 0: (i32, i32) -> i64
+		[]byte{0x60, 3, i32, i32, i32, 0}, // 1: (i32, i32, i32) -> ()
+		[]byte{0x60, 1, i32, 1, i32},      // 2: (i32) -> i32
+	)
+	imports := vec(
+		concat(name("kite"), name("call"), []byte{0x00, 0}),
+		concat(name("kite"), name("log"), []byte{0x00, 1}),
+	)
+	functions := vec([]byte{2}, []byte{0}, []byte{0}, []byte{0}, []byte{0})
+	memory := vec([]byte{0x00, 1})
+	globals := vec([]byte{i32, 0x01, 0x41, 0x80, 0x08, 0x0b}) // mut i32 = 1024
+	exports := vec(
+		concat(name("memory"), []byte{0x02, 0}),
+		concat(name("kite_alloc"), []byte{0x00, 2}),
+		concat(name("echo"), []byte{0x00, 3}),
+		concat(name("call_host"), []byte{0x00, 4}),
+		concat(name("log_info"), []byte{0x00, 5}),
+		concat(name("spin"), []byte{0x00, 6}),
+	)
+	code := vec(
+		// kite_alloc: ptr = heap; heap += size; return ptr
+		body(0x23, 0, 0x23, 0, 0x20, 0, 0x6a, 0x24, 0, 0x0b),
+		// echo: return ptr << 32 | len
+		body(0x20, 0, 0xad, 0x42, 32, 0x86, 0x20, 1, 0xad, 0x84, 0x0b),
+		// call_host: return call(ptr, len)
+		body(0x20, 0, 0x20, 1, 0x10, 0, 0x0b),
+		// log_info: log(1, ptr, len); return 0
+		body(0x41, 1, 0x20, 0, 0x20, 1, 0x10, 1, 0x42, 0, 0x0b),
+		// spin: loop { br 0 }
+		body(0x03, 0x40, 0x0c, 0, 0x0b, 0x00, 0x0b),
+	)
+
+	return concat(
+		[]byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00},
+		section(1, types),
+		section(2, imports),
+		section(3, functions),
+		section(5, memory),
+		section(6, globals),
+		section(7, exports),
+		section(10, code),
+	)
+}
+
+func uleb(v int) []byte {
+	var res []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v != 0 {
+			res = append(res, b|0x80)
+			continue
+		}
+		return append(res, b)
+	}
+}
+
+func concat(parts ...[]byte) []byte {
+	var res []byte
+	for _, part := range parts {
+		res = append(res, part...)
+	}
+	return res
+}
+
+func vec(items ...[]byte) []byte {
+	return concat(uleb(len(items)), concat(items...))
+}
+
+func name(s string) []byte {
+	return concat(uleb(len(s)), []byte(s))
+}
+
+func section(id byte, content []byte) []byte {
+	return concat([]byte{id}, uleb(len(content)), content)
+}
+
+func body(instructions ...byte) []byte {
+	// No locals besides the parameters
+	content := concat([]byte{0}, instructions)
+	return concat(uleb(len(content)), content)
+}
+
+type testVariableProvider struct {
+	provider.MockVariableProvider
+	values map[string]thing.Thing
+}
+
+func (p *testVariableProvider) UpdateVariable(ctx context.Context, id string, scope null.String, operation provider.VariableOperation, value thing.Thing, args provider.VariableOperationArgs) (thing.Thing, error) {
+	p.values[id] = value
+	return value, nil
+}
+
+func (p *testVariableProvider) Variable(ctx context.Context, id string, scope null.String) (thing.Thing, error) {
+	value, ok := p.values[id]
+	if !ok {
+		return thing.Null, provider.ErrNotFound
+	}
+	return value, nil
+}
+
+type testLogProvider struct {
+	entries []string
+}
+
+func (p *testLogProvider) CreateLogEntry(ctx context.Context, level provider.LogLevel, message string) {
+	p.entries = append(p.entries, string(level)+": "+message)
+}
+
+func newTestRuntime(t *testing.T, config RuntimeConfig) *Runtime {
+	r, err := NewRuntime(context.Background(), config)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		r.Close(context.Background())
+	})
+	return r
+}
+
+var testModule = Module{Hash: "test", Bytes: testModuleBytes()}
+
+func TestRuntimeValidate(t *testing.T) {
+	r := newTestRuntime(t, RuntimeConfig{})
+
+	require.NoError(t, r.Validate(context.Background(), testModule.Bytes))
+	require.ErrorIs(t, r.Validate(context.Background(), []byte("not wasm")), ErrInvalidModule)
+
+	functions, err := r.Functions(context.Background(), testModule.Bytes)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"echo", "call_host", "log_info", "spin"}, functions)
+}
+
+func TestRuntimeInspectKeepsCompiledModules(t *testing.T) {
+	r := newTestRuntime(t, RuntimeConfig{})
+
+	_, err := r.Call(context.Background(), testModule, "echo", nil, &Host{})
+	require.NoError(t, err)
+
+	// Inspecting the same binary must not close the compiled module of the calls
+	require.NoError(t, r.Validate(context.Background(), testModule.Bytes))
+	_, err = r.Functions(context.Background(), testModule.Bytes)
+	require.NoError(t, err)
+
+	_, err = r.Call(context.Background(), testModule, "echo", nil, &Host{})
+	require.NoError(t, err)
+}
+
+func TestRuntimeCall(t *testing.T) {
+	r := newTestRuntime(t, RuntimeConfig{})
+
+	output, err := r.Call(context.Background(), testModule, "echo", []byte(`{"hello":"world"}`), &Host{})
+	require.NoError(t, err)
+	assert.Equal(t, `{"hello":"world"}`, string(output))
+
+	_, err = r.Call(context.Background(), testModule, "missing", nil, &Host{})
+	require.ErrorIs(t, err, ErrFunctionNotFound)
+
+	_, err = r.Call(context.Background(), testModule, "kite_alloc", nil, &Host{})
+	require.ErrorIs(t, err, ErrFunctionNotFound)
+}
+
+func TestRuntimeHostCalls(t *testing.T) {
+	r := newTestRuntime(t, RuntimeConfig{})
+
+	credits := 0
+	logs := &testLogProvider{}
+	host := &Host{
+		Log:      logs,
+		Variable: &testVariableProvider{values: map[string]thing.Thing{}},
+		StartOperation: func(c int) error {
+			credits += c
+			return nil
+		},
+	}
+
+	call := func(req string) map[string]any {
+		output, err := r.Call(context.Background(), testModule, "call_host", []byte(req), host)
+		require.NoError(t, err)
+
+		var res map[string]any
+		require.NoError(t, json.Unmarshal(output, &res))
+		return res
+	}
+
+	res := call(`{"op": "variable.get", "args": {"variable_id": "counter"}}`)
+	assert.Equal(t, map[string]any{}, res)
+
+	res = call(`{"op": "variable.set", "args": {"variable_id": "counter", "value": {"count": 1}}}`)
+	assert.Equal(t, map[string]any{"count": float64(1)}, res["result"])
+
+	res = call(`{"op": "variable.get", "args": {"variable_id": "counter"}}`)
+	assert.Equal(t, map[string]any{"count": float64(1)}, res["result"])
+
+	res = call(`{"op": "discord.get_user", "args": {"user_id": "123"}}`)
+	assert.Equal(t, "discord is not available", res["error"])
+
+	res = call(`{"op": "unknown"}`)
+	assert.Equal(t, "unknown host call: unknown", res["error"])
+
+	_, err := r.Call(context.Background(), testModule, "log_info", []byte("hello"), host)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"info: hello"}, logs.entries)
+
+	assert.Equal(t, 6, credits)
+}
+
+func TestRuntimeLimits(t *testing.T) {
+	r := newTestRuntime(t, RuntimeConfig{
+		MaxDuration: 50 * time.Millisecond,
+		MaxIOSize:   16,
+	})
+
+	_, err := r.Call(context.Background(), testModule, "spin", nil, &Host{})
+	require.ErrorIs(t, err, ErrTimeout)
+
+	_, err = r.Call(context.Background(), testModule, "echo", []byte("this input is too long"), &Host{})
+	require.Error(t, err)
+
+	errLimit := errors.New("max credits reached")
+	_, err = r.Call(context.Background(), testModule, "log_info", []byte("hello"), &Host{
+		StartOperation: func(credits int) error {
+			return errLimit
+		},
+	})
+	require.ErrorIs(t, err, errLimit)
+}
+
+func TestRuntimeFuel(t *testing.T) {
+	r := newTestRuntime(t, RuntimeConfig{
+		MaxDuration: time.Minute,
+		MaxFuel:     100_000,
+	})
+
+	require.NoError(t, r.Validate(context.Background(), testModule.Bytes))
+
+	output, err := r.Call(context.Background(), testModule, "echo", []byte(`{"hello":"world"}`), &Host{})
+	require.NoError(t, err)
+	assert.Equal(t, `{"hello":"world"}`, string(output))
+
+	// The busy loop is cut off by the fuel long before the duration limit is reached
+	start := time.Now()
+	_, err = r.Call(context.Background(), testModule, "spin", nil, &Host{})
+	require.ErrorIs(t, err, ErrFuelExhausted)
+	assert.Less(t, time.Since(start), 10*time.Second)
+
+	// Every call starts with the full fuel
+	_, err = r.Call(context.Background(), testModule, "echo", []byte("again"), &Host{})
+	require.NoError(t, err)
+}
+
+func TestMeterModule(t *testing.T) {
+	metered, err := meterModule(testModule.Bytes, 1000)
+	require.NoError(t, err)
+
+	r := newTestRuntime(t, RuntimeConfig{})
+	require.NoError(t, r.Validate(context.Background(), metered))
+
+	// The fuel global is exported and the metered module can't be metered again
+	_, err = meterModule(metered, 1000)
+	require.ErrorContains(t, err, "reserved")
+
+	_, err = meterModule([]byte("not wasm"), 1000)
+	require.Error(t, err)
+}
+
+func TestRuntimeLoadsModuleOnce(t *testing.T) {
+	r := newTestRuntime(t, RuntimeConfig{})
+
+	loads := 0
+	module := Module{
+		Hash: "lazy",
+		Load: func(ctx context.Context) ([]byte, error) {
+			loads++
+			return testModuleBytes(), nil
+		},
+	}
+
+	for i := 0; i < 3; i++ {
+		output, err := r.Call(context.Background(), module, "echo", []byte("hello"), &Host{})
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(output))
+	}
+	assert.Equal(t, 1, loads, "the binary is only loaded when the module isn't compiled yet")
+}
+
+func TestRuntimeEvictionKeepsModulesInUse(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRuntime(t, RuntimeConfig{MaxCompiledModules: 1})
+
+	first := Module{Hash: "first", Bytes: testModuleBytes()}
+	compiled, release, err := r.compile(ctx, first)
+	require.NoError(t, err)
+
+	// Compiling another module evicts the first one while it's still about to be instantiated.
+	// The custom section makes the binary differ, identical binaries share their compiled code.
+	second := append(testModuleBytes(), 0x00, 0x03, 0x01, 'x', 0x00)
+	_, releaseSecond, err := r.compile(ctx, Module{Hash: "second", Bytes: second})
+	require.NoError(t, err)
+	releaseSecond()
+
+	instance, err := r.runtime.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().WithName(""))
+	require.NoError(t, err)
+	require.NoError(t, instance.Close(ctx))
+	release()
+
+	output, err := r.Call(ctx, first, "echo", []byte("hello"), &Host{})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(output), "evicted modules are compiled again")
+}
@@ -57,6 +57,7 @@ export default function CommandCreateDialog({
       {
         flow_source: getInitialFlowData(data.name, data.description),
         enabled: true,
+        module_id: null,
       },
       {
         onSuccess(res) {
@@ -62,6 +62,7 @@ export default function CommandDuplicateDialog({
           data.description
         ),
         enabled: true,
+        module_id: command.module_id,
       },
       {
         onSuccess(res) {
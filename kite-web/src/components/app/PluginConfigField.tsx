@@ -0,0 +1,287 @@
+import { useMessages } from "@/lib/hooks/api";
+import { ConfigField } from "@/lib/types/plugin.gen";
+import { XIcon } from "lucide-react";
+import ChannelSelect from "../common/ChannelSelect";
+import EmojiPicker, { PickerEmoji } from "../common/EmojiPicker";
+import { Button } from "../ui/button";
+import { Input } from "../ui/input";
+import {
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue,
+} from "../ui/select";
+import { Switch } from "../ui/switch";
+
+export function PluginConfigField({
+  field,
+  value,
+  onChange,
+  guildId,
+  error,
+}: {
+  field: ConfigField;
+  value: any;
+  onChange: (value: any) => void;
+  guildId: string | null;
+  error?: string;
+}) {
+  return (
+    <div>
+      <div className="mb-3">
+        <div className="flex items-center gap-2">
+          <div className="font-medium mb-0.5">{field.name}</div>
+          <div className="text-xs text-muted-foreground">
+            {field.required ? (
+              <span className="text-red-500">required</span>
+            ) : (
+              <span className="text-muted-foreground">optional</span>
+            )}
+          </div>
+        </div>
+        <div className="text-sm text-muted-foreground">
+          {field.description}
+        </div>
+      </div>
+      <PluginConfigFieldInput
+        field={field}
+        value={value}
+        onChange={onChange}
+        guildId={guildId}
+      />
+      {error && <div className="text-sm text-red-500 mt-1">{error}</div>}
+    </div>
+  );
+}
+
+function PluginConfigFieldInput({
+  field,
+  value,
+  onChange,
+  guildId,
+}: {
+  field: ConfigField;
+  value: any;
+  onChange: (value: any) => void;
+  guildId: string | null;
+}) {
+  const placeholder =
+    field.default !== undefined && field.default !== null
+      ? String(field.default)
+      : undefined;
+
+  if (field.options?.length && field.type !== "array") {
+    return (
+      <OptionSelect field={field} value={value} onChange={onChange} />
+    );
+  }
+
+  switch (field.type) {
+    case "int":
+    case "float":
+      return (
+        <Input
+          type="number"
+          min={field.min}
+          max={field.max}
+          step={field.type === "int" ? 1 : "any"}
+          placeholder={placeholder}
+          value={value ?? ""}
+          onChange={(e) => {
+            if (e.target.value === "") {
+              onChange(undefined);
+              return;
+            }
+            const n =
+              field.type === "int"
+                ? parseInt(e.target.value)
+                : parseFloat(e.target.value);
+            onChange(isNaN(n) ? undefined : n);
+          }}
+        />
+      );
+    case "bool":
+      return (
+        <Switch
+          checked={value ?? field.default ?? false}
+          onCheckedChange={onChange}
+        />
+      );
+    case "array":
+      return (
+        <Input
+          placeholder="Separate multiple values with commas"
+          value={Array.isArray(value) ? value.join(", ") : ""}
+          onChange={(e) => {
+            const items = e.target.value
+              .split(",")
+              .map((v) => v.trim())
+              .filter((v) => v !== "")
+              .map((v) =>
+                field.item_type === "int" || field.item_type === "float"
+                  ? Number(v)
+                  : v
+              );
+            onChange(items.length ? items : undefined);
+          }}
+        />
+      );
+    case "discord_channel":
+      if (guildId) {
+        return (
+          <ChannelSelect
+            guildId={guildId}
+            value={value ?? null}
+            onChange={(v) => onChange(v || undefined)}
+          />
+        );
+      }
+      return (
+        <Input
+          placeholder="Channel ID"
+          value={value ?? ""}
+          onChange={(e) => onChange(e.target.value.trim() || undefined)}
+        />
+      );
+    case "discord_role":
+      return (
+        <Input
+          placeholder="Role ID"
+          value={value ?? ""}
+          onChange={(e) => onChange(e.target.value.trim() || undefined)}
+        />
+      );
+    case "discord_emoji":
+      return <EmojiInput value={value} onChange={onChange} />;
+    case "message_template":
+      return <MessageTemplateSelect value={value} onChange={onChange} />;
+    default:
+      return (
+        <Input
+          placeholder={placeholder}
+          maxLength={field.max}
+          value={value ?? ""}
+          onChange={(e) => onChange(e.target.value || undefined)}
+        />
+      );
+  }
+}
+
+function OptionSelect({
+  field,
+  value,
+  onChange,
+}: {
+  field: ConfigField;
+  value: any;
+  onChange: (value: any) => void;
+}) {
+  // Option values can be of any type, so they are identified by their JSON representation
+  const current = value ?? field.default;
+
+  return (
+    <Select
+      value={current !== undefined ? JSON.stringify(current) : undefined}
+      onValueChange={(v) => onChange(JSON.parse(v))}
+    >
+      <SelectTrigger>
+        <SelectValue placeholder="Select option..." />
+      </SelectTrigger>
+      <SelectContent>
+        {field.options?.map((option) => (
+          <SelectItem
+            key={JSON.stringify(option.value)}
+            value={JSON.stringify(option.value)}
+          >
+            {option.label}
+          </SelectItem>
+        ))}
+      </SelectContent>
+    </Select>
+  );
+}
+
+function MessageTemplateSelect({
+  value,
+  onChange,
+}: {
+  value: any;
+  onChange: (value: any) => void;
+}) {
+  const messages = useMessages();
+
+  return (
+    <div className="flex gap-2">
+      <Select value={value ?? ""} onValueChange={(v) => onChange(v)}>
+        <SelectTrigger>
+          <SelectValue placeholder="Select message template..." />
+        </SelectTrigger>
+        <SelectContent>
+          {messages?.map((message) => (
+            <SelectItem key={message!.id} value={message!.id}>
+              {message!.name}
+            </SelectItem>
+          ))}
+        </SelectContent>
+      </Select>
+      {value && (
+        <Button
+          variant="outline"
+          size="icon"
+          onClick={() => onChange(undefined)}
+        >
+          <XIcon className="h-5 w-5" />
+        </Button>
+      )}
+    </div>
+  );
+}
+
+function EmojiInput({
+  value,
+  onChange,
+}: {
+  value: any;
+  onChange: (value: any) => void;
+}) {
+  const custom =
+    typeof value === "string" ? value.match(/^<(a?):(\w+):(\d+)>$/) : null;
+
+  function onEmojiSelect(emoji: PickerEmoji) {
+    if (emoji.native) {
+      onChange(emoji.name);
+    } else {
+      onChange(`<${emoji.animated ? "a" : ""}:${emoji.name}:${emoji.id}>`);
+    }
+  }
+
+  return (
+    <div className="flex gap-2">
+      <EmojiPicker onEmojiSelect={onEmojiSelect}>
+        <Button size="icon" variant="outline">
+          {custom ? (
+            <img
+              src={`https://cdn.discordapp.com/emojis/${custom[3]}.${
+                custom[1] ? "gif" : "webp"
+              }`}
+              alt=""
+              className="h-6 w-6"
+            />
+          ) : (
+            <span className="text-xl">{value || "🙂"}</span>
+          )}
+        </Button>
+      </EmojiPicker>
+      {value && (
+        <Button
+          variant="outline"
+          size="icon"
+          onClick={() => onChange(undefined)}
+        >
+          <XIcon className="h-5 w-5" />
+        </Button>
+      )}
+    </div>
+  );
+}
@@ -2,10 +2,15 @@ import {
   usePluginInstanceCreateMutation,
   usePluginInstanceUpdateMutation,
 } from "@/lib/api/mutations";
+import { APIResponse } from "@/lib/api/response";
 import { useAppId } from "@/lib/hooks/params";
 import { Plugin, PluginInstance } from "@/lib/types/wire.gen";
+import { ConfigKeyGuilds, ConfigSection } from "@/lib/types/plugin.gen";
 import { SatelliteDishIcon, SlashSquareIcon } from "lucide-react";
 import { ReactNode, useCallback, useEffect, useState } from "react";
+import { toast } from "sonner";
+import GuildSelect from "../common/GuildSelect";
+import { PluginConfigField } from "./PluginConfigField";
 import { Button } from "../ui/button";
 import { Card, CardDescription, CardTitle } from "../ui/card";
 import {
@@ -18,7 +23,6 @@ import {
   DialogTitle,
   DialogTrigger,
 } from "../ui/dialog";
-import { Input } from "../ui/input";
 import { Switch } from "../ui/switch";
 
 export function PluginConfigureDialog({
@@ -39,6 +43,60 @@ export function PluginConfigureDialog({
   const [enabled, setEnabled] = useState(false);
   const [config, setConfig] = useState<Record<string, any>>({});
   const [enabledResourceIds, setEnabledResourceIds] = useState<string[]>([]);
+  const [guildId, setGuildId] = useState<string | null>(null);
+  const [errors, setErrors] = useState<Record<string, any>>({});
+
+  const hasPerGuildSections = !!plugin.config.sections?.some(
+    (s) => s.per_guild
+  );
+
+  const getValue = useCallback(
+    (section: ConfigSection, key: string) => {
+      if (section.per_guild && guildId) {
+        return config[ConfigKeyGuilds]?.[guildId]?.[key];
+      }
+      return config[key];
+    },
+    [config, guildId]
+  );
+
+  const setValue = useCallback(
+    (section: ConfigSection, key: string, value: any) => {
+      if (!section.per_guild || !guildId) {
+        setConfig({ ...config, [key]: value });
+        return;
+      }
+
+      // Per-guild overrides are removed again when they are cleared
+      const guilds = { ...config[ConfigKeyGuilds] };
+      const overrides = { ...guilds[guildId], [key]: value };
+      if (value === undefined) {
+        delete overrides[key];
+      }
+
+      if (Object.keys(overrides).length) {
+        guilds[guildId] = overrides;
+      } else {
+        delete guilds[guildId];
+      }
+
+      setConfig({
+        ...config,
+        [ConfigKeyGuilds]: Object.keys(guilds).length ? guilds : undefined,
+      });
+    },
+    [config, guildId]
+  );
+
+  const getError = useCallback(
+    (section: ConfigSection, key: string): string | undefined => {
+      if (section.per_guild && guildId) {
+        return errors[ConfigKeyGuilds]?.[guildId]?.[key];
+      }
+      return errors[key];
+    },
+    [errors, guildId]
+  );
 
   useEffect(() => {
     if (instance) {
@@ -55,22 +113,42 @@ export function PluginConfigureDialog({
   }, [plugin, instance]);
 
   const handleSave = useCallback(() => {
+    const callbacks = {
+      onSuccess(res: APIResponse<PluginInstance>) {
+        if (res.success) {
+          setErrors({});
+          setDialogOpen(false);
+        } else if (res.error.code === "validation_failed") {
+          setErrors(res.error.data?.config || {});
+          toast.error("Some fields of the plugin config are invalid.");
+        } else {
+          toast.error(
+            `Failed to save plugin: ${res.error.message} (${res.error.code})`
+          );
+        }
+      },
+    };
+
     if (instance) {
-      updateMutation.mutate({
-        enabled: enabled,
-        config: config,
-        enabled_resource_ids: enabledResourceIds,
-      });
+      updateMutation.mutate(
+        {
+          enabled: enabled,
+          config: config,
+          enabled_resource_ids: enabledResourceIds,
+        },
+        callbacks
+      );
     } else {
-      createMutation.mutate({
-        plugin_id: plugin.id,
-        config: config,
-        enabled_resource_ids: enabledResourceIds,
-        enabled: enabled,
-      });
+      createMutation.mutate(
+        {
+          plugin_id: plugin.id,
+          config: config,
+          enabled_resource_ids: enabledResourceIds,
+          enabled: enabled,
+        },
+        callbacks
+      );
     }
-
-    setDialogOpen(false);
   }, [
     plugin,
     instance,
@@ -97,42 +175,42 @@ export function PluginConfigureDialog({
             <Switch checked={enabled} onCheckedChange={setEnabled} />
           </div>
 
+          {hasPerGuildSections && (
+            <div>
+              <div className="mb-3">
+                <div className="font-medium mb-0.5">Server</div>
+                <div className="text-sm text-muted-foreground">
+                  Select a server to override some of the settings only for
+                  that server.
+                </div>
+              </div>
+              <GuildSelect value={guildId} onChange={setGuildId} />
+            </div>
+          )}
+
           {!!plugin.config.sections && (
-            <div className="flex flex-col gap-3">
+            <div className="flex flex-col gap-5">
               {plugin.config.sections.map((section) => (
                 <div key={section.name}>
+                  <div className="mb-3">
+                    <div className="font-medium text-lg">{section.name}</div>
+                    <div className="text-sm text-muted-foreground">
+                      {section.description}
+                      {section.per_guild &&
+                        guildId &&
+                        " Only applies to the selected server."}
+                    </div>
+                  </div>
                   <div className="flex flex-col gap-3">
                     {section.fields.map((field) => (
-                      <div key={field.key}>
-                        <div className="mb-3">
-                          <div className="flex items-center gap-2">
-                            <div className="font-medium mb-0.5">
-                              {field.name}
-                            </div>
-                            <div className="text-xs text-muted-foreground">
-                              {field.required ? (
-                                <span className="text-red-500">required</span>
-                              ) : (
-                                <span className="text-muted-foreground">
-                                  optional
-                                </span>
-                              )}
-                            </div>
-                          </div>
-                          <div className="text-sm text-muted-foreground">
-                            {field.description}
-                          </div>
-                        </div>
-                        <Input
-                          value={config[field.key] || ""}
-                          onChange={(e) =>
-                            setConfig({
-                              ...config,
-                              [field.key]: e.target.value || undefined,
-                            })
-                          }
-                        />
-                      </div>
+                      <PluginConfigField
+                        key={field.key}
+                        field={field}
+                        value={getValue(section, field.key)}
+                        onChange={(v) => setValue(section, field.key, v)}
+                        guildId={section.per_guild ? guildId : null}
+                        error={getError(section, field.key)}
+                      />
                     ))}
                   </div>
                 </div>
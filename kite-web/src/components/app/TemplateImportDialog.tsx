@@ -57,6 +57,7 @@ export function TemplateImportDialog({
       .map((c) => ({
         enabled: true,
         flow_source: prepareTemplateFlow(c.flowSource(inputValues)),
+        module_id: null,
       }));
     const eventListeners = template.eventListeners
       .filter((_, i) => !disabledEventListeners.includes(i))
@@ -25,10 +25,12 @@ import { setValidationErrors } from "@/lib/form";
 import LoadingButton from "../common/LoadingButton";
 import { useAppId } from "@/lib/hooks/params";
 import { Switch } from "../ui/switch";
+import VariableTypeSelect from "./VariableTypeSelect";
 
 interface FormFields {
   name: string;
   scoped: boolean;
+  type: string;
 }
 
 export default function VariableCreateDialog({
@@ -47,6 +49,7 @@ export default function VariableCreateDialog({
     defaultValues: {
       name: "",
       scoped: false,
+      type: "any",
     },
   });
 
@@ -57,6 +60,10 @@ export default function VariableCreateDialog({
       {
         name: data.name,
         scoped: data.scoped,
+        type: data.type,
+        schema: null,
+        default_value: null,
+        value_ttl_seconds: 0,
       },
       {
         onSuccess(res) {
@@ -106,6 +113,25 @@ export default function VariableCreateDialog({
                 </FormItem>
               )}
             />
+            <FormField
+              control={form.control}
+              name="type"
+              render={({ field }) => (
+                <FormItem>
+                  <FormLabel>Type</FormLabel>
+                  <FormControl>
+                    <VariableTypeSelect
+                      value={field.value}
+                      onChange={field.onChange}
+                    />
+                  </FormControl>
+                  <FormDescription>
+                    Values that don&apos;t match the type are rejected.
+                  </FormDescription>
+                  <FormMessage />
+                </FormItem>
+              )}
+            />
             <FormField
               control={form.control}
               name="scoped"
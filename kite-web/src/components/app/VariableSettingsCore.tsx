@@ -26,10 +26,26 @@ import { toast } from "sonner";
 import { setValidationErrors } from "@/lib/form";
 import ConfirmDialog from "../common/ConfirmDialog";
 import { Switch } from "../ui/switch";
+import { Textarea } from "../ui/textarea";
+import VariableTypeSelect from "./VariableTypeSelect";
 
 interface FormFields {
   name: string;
   scoped: boolean;
+  type: string;
+  schema: string;
+  default_value: string;
+  value_ttl_seconds: number;
+}
+
+function parseJSONField(value: string): [any, boolean] {
+  if (!value.trim()) return [null, true];
+
+  try {
+    return [JSON.parse(value), true];
+  } catch {
+    return [null, false];
+  }
 }
 
 export default function VariableSettingsCore() {
@@ -39,6 +55,10 @@ export default function VariableSettingsCore() {
     defaultValues: {
       name: "",
       scoped: false,
+      type: "any",
+      schema: "",
+      default_value: "",
+      value_ttl_seconds: 0,
     },
   });
 
@@ -47,6 +67,14 @@ export default function VariableSettingsCore() {
       form.reset({
         name: variable.name,
         scoped: variable.scoped,
+        type: variable.type || "any",
+        schema: variable.schema ? JSON.stringify(variable.schema, null, 2) : "",
+        default_value:
+          variable.default_value !== null &&
+          variable.default_value !== undefined
+            ? JSON.stringify(variable.default_value)
+            : "",
+        value_ttl_seconds: variable.value_ttl_seconds,
       });
     }
   }, [variable, form]);
@@ -56,27 +84,56 @@ export default function VariableSettingsCore() {
   const saveSettings = useCallback(() => {
     const data = form.getValues();
 
-    updateMutation.mutate(
-      {
-        name: data.name,
-        scoped: data.scoped,
-      },
-      {
-        onSuccess(res) {
-          if (res.success) {
-            toast.success("Settings saved!");
-          } else {
-            if (res.error.code === "validation_failed") {
+    const [schema, schemaValid] = parseJSONField(data.schema);
+    if (!schemaValid) {
+      form.setError("schema", { message: "Must be valid JSON" });
+      return;
+    }
+
+    const [defaultValue, defaultValueValid] = parseJSONField(
+      data.default_value
+    );
+    if (!defaultValueValid) {
+      form.setError("default_value", { message: "Must be valid JSON" });
+      return;
+    }
+
+    // Changing the type or scope deletes all values, so the user has to confirm it
+    const update = (resetValues: boolean) =>
+      updateMutation.mutate(
+        {
+          name: data.name,
+          scoped: data.scoped,
+          type: data.type,
+          schema: schema,
+          default_value: defaultValue,
+          value_ttl_seconds: Number(data.value_ttl_seconds) || 0,
+          reset_values: resetValues,
+        },
+        {
+          onSuccess(res) {
+            if (res.success) {
+              toast.success("Settings saved!");
+            } else if (res.error.code === "validation_failed") {
               setValidationErrors(form, res.error.data);
+            } else if (res.error.code === "variable_has_values") {
+              if (
+                confirm(
+                  "Changing the type or scope deletes all values of the variable. Are you sure you want to continue?"
+                )
+              ) {
+                update(true);
+              }
             } else {
               toast.error(
                 `Failed to update app: ${res.error.message} (${res.error.code})`
               );
             }
-          }
-        },
-      }
-    );
+          },
+        }
+      );
+
+    update(false);
   }, [form, updateMutation]);
 
   return (
@@ -126,12 +183,85 @@ export default function VariableSettingsCore() {
                 </FormItem>
               )}
             />
+            <FormField
+              control={form.control}
+              name="type"
+              render={({ field }) => (
+                <FormItem>
+                  <FormLabel>Type</FormLabel>
+                  <FormControl>
+                    <VariableTypeSelect
+                      value={field.value}
+                      onChange={field.onChange}
+                    />
+                  </FormControl>
+                  <FormDescription>
+                    Values that don&apos;t match the type are rejected.
+                  </FormDescription>
+                  <FormMessage />
+                </FormItem>
+              )}
+            />
+            <FormField
+              control={form.control}
+              name="default_value"
+              render={({ field }) => (
+                <FormItem>
+                  <FormLabel>Default Value</FormLabel>
+                  <FormControl>
+                    <Input type="text" placeholder="0" {...field} />
+                  </FormControl>
+                  <FormDescription>
+                    The value as JSON that is used when the variable has no
+                    value yet.
+                  </FormDescription>
+                  <FormMessage />
+                </FormItem>
+              )}
+            />
+            <FormField
+              control={form.control}
+              name="schema"
+              render={({ field }) => (
+                <FormItem>
+                  <FormLabel>Schema</FormLabel>
+                  <FormControl>
+                    <Textarea
+                      className="font-mono"
+                      placeholder='{"type": "object", "properties": {}}'
+                      {...field}
+                    />
+                  </FormControl>
+                  <FormDescription>
+                    An optional JSON schema that all values must match.
+                  </FormDescription>
+                  <FormMessage />
+                </FormItem>
+              )}
+            />
+            <FormField
+              control={form.control}
+              name="value_ttl_seconds"
+              render={({ field }) => (
+                <FormItem>
+                  <FormLabel>Value Expiry (seconds)</FormLabel>
+                  <FormControl>
+                    <Input type="number" min={0} {...field} />
+                  </FormControl>
+                  <FormDescription>
+                    Values are deleted after this many seconds without being
+                    written. 0 keeps them forever.
+                  </FormDescription>
+                  <FormMessage />
+                </FormItem>
+              )}
+            />
           </CardContent>
 
           <CardFooter className="border-t px-6 py-4">
             <ConfirmDialog
               title="Are you sure that you want to update the variable settings?"
-              description="Changing if the variable is scoped or not will delete all associated data and cannot be undone."
+              description="Changing if the variable is scoped or its type will delete all associated data and cannot be undone."
               onConfirm={saveSettings}
             >
               <Button>Save settings</Button>
@@ -0,0 +1,48 @@
+import {
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue,
+} from "../ui/select";
+
+export const variableTypes = [
+  { value: "any", label: "Any" },
+  { value: "string", label: "Text" },
+  { value: "int", label: "Integer" },
+  { value: "float", label: "Decimal" },
+  { value: "bool", label: "True / False" },
+  { value: "time", label: "Time" },
+  { value: "duration", label: "Duration" },
+  { value: "array", label: "List" },
+  { value: "object", label: "Object" },
+  { value: "discord_user", label: "Discord User" },
+  { value: "discord_member", label: "Discord Member" },
+  { value: "discord_channel", label: "Discord Channel" },
+  { value: "discord_guild", label: "Discord Server" },
+  { value: "discord_role", label: "Discord Role" },
+  { value: "discord_message", label: "Discord Message" },
+];
+
+export default function VariableTypeSelect({
+  value,
+  onChange,
+}: {
+  value: string;
+  onChange: (value: string) => void;
+}) {
+  return (
+    <Select onValueChange={onChange} value={value}>
+      <SelectTrigger>
+        <SelectValue placeholder="Select type" />
+      </SelectTrigger>
+      <SelectContent>
+        {variableTypes.map((type) => (
+          <SelectItem key={type.value} value={type.value}>
+            {type.label}
+          </SelectItem>
+        ))}
+      </SelectContent>
+    </Select>
+  );
+}
@@ -4,13 +4,19 @@ import {
   permissionBits,
 } from "@/lib/discord/permissions";
 import { getNodeId, useNodeValues } from "@/lib/flow/nodes";
-import { useMessages, useVariables } from "@/lib/hooks/api";
+import {
+  useMessages,
+  useSharedVariables,
+  useVariables,
+} from "@/lib/hooks/api";
 import { useAppId } from "@/lib/hooks/params";
 import {
   CommandArgumentChoiceData,
   EmojiData,
   HTTPRequestData,
   ModalComponentData,
+  ModalComponentValidationData,
+  ModalSelectOptionData,
   PermissionOverwriteData,
 } from "@/lib/types/flow.gen";
 import { Node, useNodes, useReactFlow, useStoreApi } from "@xyflow/react";
@@ -114,6 +120,10 @@ const intputs: Record<string, any> = {
   variable_scope: VariableScopeInput,
   variable_operation: VariableOperationInput,
   variable_value: VariableValueInput,
+  variable_key: VariableKeyInput,
+  variable_expected: VariableExpectedInput,
+  variable_max_length: VariableMaxLengthInput,
+  variable_query_data: VariableQueryDataInput,
   http_request_data: HttpRequestDataInput,
   ai_chat_completion_data: AiChatCompletionDataInput,
   ai_web_search_data: AiWebSearchDataInput,
@@ -1490,22 +1500,17 @@ function ModalDataInput({ data, updateData, errors }: InputProps) {
                       type="select"
                       field={`modal_data.components.${r}.components.${c}.type`}
                       title="Type"
-                      value={component.style?.toString() || "1"}
-                      options={[
-                        {
-                          label: "Short",
-                          value: "1",
-                        },
-                        {
-                          label: "Paragraph",
-                          value: "2",
-                        },
-                      ]}
-                      updateValue={(v) =>
+                      value={modalComponentTypeValue(component)}
+                      options={modalComponentTypes}
+                      updateValue={(v) => {
+                        const [type, style] = v.split(":").map(Number);
                         updateComponentField(r, c, {
-                          style: parseInt(v) || 1,
-                        })
-                      }
+                          type: type === 4 ? undefined : type,
+                          style: type === 4 ? style || 1 : undefined,
+                          options: type === 3 ? component.options || [] : undefined,
+                          validation: type === 4 ? component.validation : undefined,
+                        });
+                      }}
                       errors={errors}
                     />
                     <BaseCheckbox
@@ -1549,17 +1554,78 @@ function ModalDataInput({ data, updateData, errors }: InputProps) {
                   />
                   <BaseInput
                     type="text"
-                    field={`modal_data.components.${r}.components.${c}.placeholder`}
-                    title="Placeholder"
-                    value={component?.placeholder || ""}
+                    field={`modal_data.components.${r}.components.${c}.description`}
+                    title="Description"
+                    value={component?.description || ""}
                     updateValue={(v) =>
                       updateComponentField(r, c, {
-                        placeholder: v || undefined,
+                        description: v || undefined,
                       })
                     }
                     errors={errors}
-                    placeholders
                   />
+                  {component.type !== 19 && (
+                    <BaseInput
+                      type="text"
+                      field={`modal_data.components.${r}.components.${c}.placeholder`}
+                      title="Placeholder"
+                      value={component?.placeholder || ""}
+                      updateValue={(v) =>
+                        updateComponentField(r, c, {
+                          placeholder: v || undefined,
+                        })
+                      }
+                      errors={errors}
+                      placeholders
+                    />
+                  )}
+                  {component.type === 3 && (
+                    <ModalSelectOptionsInput
+                      field={`modal_data.components.${r}.components.${c}.options`}
+                      options={component.options || []}
+                      updateOptions={(options) =>
+                        updateComponentField(r, c, { options })
+                      }
+                      errors={errors}
+                    />
+                  )}
+                  {component.type && component.type !== 4 ? (
+                    <div className="flex space-x-3">
+                      <BaseInput
+                        type="text"
+                        field={`modal_data.components.${r}.components.${c}.min_values`}
+                        title="Min Values"
+                        value={component.min_values?.toString() || ""}
+                        updateValue={(v) =>
+                          updateComponentField(r, c, {
+                            min_values: parseInt(v) || undefined,
+                          })
+                        }
+                        errors={errors}
+                      />
+                      <BaseInput
+                        type="text"
+                        field={`modal_data.components.${r}.components.${c}.max_values`}
+                        title="Max Values"
+                        value={component.max_values?.toString() || ""}
+                        updateValue={(v) =>
+                          updateComponentField(r, c, {
+                            max_values: parseInt(v) || undefined,
+                          })
+                        }
+                        errors={errors}
+                      />
+                    </div>
+                  ) : (
+                    <ModalValidationInput
+                      field={`modal_data.components.${r}.components.${c}.validation`}
+                      validation={component.validation}
+                      updateValidation={(validation) =>
+                        updateComponentField(r, c, { validation })
+                      }
+                      errors={errors}
+                    />
+                  )}
                 </Card>
               ))
             )}
@@ -1582,6 +1648,149 @@ function ModalDataInput({ data, updateData, errors }: InputProps) {
   );
 }
 
+const modalComponentTypes = [
+  { label: "Short Text", value: "4:1" },
+  { label: "Paragraph", value: "4:2" },
+  { label: "Text Select", value: "3" },
+  { label: "User Select", value: "5" },
+  { label: "Role Select", value: "6" },
+  { label: "Mentionable Select", value: "7" },
+  { label: "Channel Select", value: "8" },
+  { label: "File Upload", value: "19" },
+];
+
+function modalComponentTypeValue(component: ModalComponentData) {
+  if (!component.type || component.type === 4) {
+    return `4:${component.style || 1}`;
+  }
+  return component.type.toString();
+}
+
+function ModalSelectOptionsInput({
+  field,
+  options,
+  updateOptions,
+  errors,
+}: {
+  field: string;
+  options: ModalSelectOptionData[];
+  updateOptions: (options: ModalSelectOptionData[]) => void;
+  errors: Record<string, string>;
+}) {
+  const updateOption = (i: number, newData: Partial<ModalSelectOptionData>) =>
+    updateOptions(
+      options.map((option, j) => (i === j ? { ...option, ...newData } : option))
+    );
+
+  return (
+    <div className="space-y-3">
+      <div className="font-medium text-foreground">Options</div>
+      {options.map((option, i) => (
+        <div className="flex space-x-3 items-end" key={i}>
+          <BaseInput
+            type="text"
+            field={`${field}.${i}.label`}
+            title="Label"
+            value={option.label || ""}
+            updateValue={(v) => updateOption(i, { label: v || undefined })}
+            errors={errors}
+          />
+          <BaseInput
+            type="text"
+            field={`${field}.${i}.value`}
+            title="Value"
+            value={option.value || ""}
+            updateValue={(v) => updateOption(i, { value: v || undefined })}
+            errors={errors}
+          />
+          <Button
+            variant="outline"
+            size="icon"
+            className="flex-none"
+            onClick={() => updateOptions(options.filter((_, j) => i !== j))}
+          >
+            <TrashIcon className="h-5 w-5" />
+          </Button>
+        </div>
+      ))}
+      <Button
+        variant="outline"
+        onClick={() => updateOptions([...options, {}])}
+        disabled={options.length >= 25}
+      >
+        Add Option
+      </Button>
+    </div>
+  );
+}
+
+function ModalValidationInput({
+  field,
+  validation,
+  updateValidation,
+  errors,
+}: {
+  field: string;
+  validation?: ModalComponentValidationData;
+  updateValidation: (validation?: ModalComponentValidationData) => void;
+  errors: Record<string, string>;
+}) {
+  const update = (newData: Partial<ModalComponentValidationData>) => {
+    const res = { ...validation, ...newData };
+    const empty =
+      !res.pattern &&
+      res.min == null &&
+      res.max == null &&
+      !res.error_message;
+    updateValidation(empty ? undefined : res);
+  };
+
+  const parseNumber = (v: string) => {
+    const n = parseFloat(v);
+    return isNaN(n) ? null : n;
+  };
+
+  return (
+    <div className="space-y-3">
+      <BaseInput
+        type="text"
+        field={`${field}.pattern`}
+        title="Pattern"
+        description="A regular expression the input must match. If the input is invalid, the flow continues at the invalid handle."
+        value={validation?.pattern || ""}
+        updateValue={(v) => update({ pattern: v || undefined })}
+        errors={errors}
+      />
+      <div className="flex space-x-3">
+        <BaseInput
+          type="text"
+          field={`${field}.min`}
+          title="Min Number"
+          value={validation?.min?.toString() || ""}
+          updateValue={(v) => update({ min: parseNumber(v) })}
+          errors={errors}
+        />
+        <BaseInput
+          type="text"
+          field={`${field}.max`}
+          title="Max Number"
+          value={validation?.max?.toString() || ""}
+          updateValue={(v) => update({ max: parseNumber(v) })}
+          errors={errors}
+        />
+      </div>
+      <BaseInput
+        type="text"
+        field={`${field}.error_message`}
+        title="Error Message"
+        value={validation?.error_message || ""}
+        updateValue={(v) => update({ error_message: v || undefined })}
+        errors={errors}
+      />
+    </div>
+  );
+}
+
 function ChannelDataInput({ data, updateData, errors }: InputProps) {
   const addOverwrite = useCallback(() => {
     updateData({
@@ -2059,25 +2268,43 @@ function RoleTargetInput({ data, updateData, errors }: InputProps) {
 
 function VariableIdInput({ data, updateData, errors }: InputProps) {
   const variables = useVariables();
+  const sharedVariables = useSharedVariables();
 
   const appId = useAppId();
 
+  const options = useMemo(
+    () => [
+      ...(variables || []).map((v) => ({
+        value: v!.id,
+        label: v!.name,
+      })),
+      ...(sharedVariables || []).map((v) => ({
+        value: v!.variable!.id,
+        label: `${v!.namespace_name} / ${v!.variable!.name}`,
+      })),
+    ],
+    [variables, sharedVariables]
+  );
+
+  const isShared = useMemo(
+    () =>
+      !!sharedVariables?.some((v) => v?.variable?.id === data.variable_id),
+    [sharedVariables, data.variable_id]
+  );
+
   return (
     <div className="flex space-x-2 items-end">
       <BaseInput
         type="select"
         field="variable_id"
         title="Variable"
-        options={variables?.map((v) => ({
-          value: v!.id,
-          label: v!.name,
-        }))}
+        options={options}
         value={data.variable_id || ""}
         updateValue={(v) => updateData({ variable_id: v || undefined })}
         errors={errors}
         clearable
       />
-      {data.variable_id ? (
+      {isShared ? null : data.variable_id ? (
         <Tooltip>
           <TooltipTrigger asChild>
             <Button variant="outline" size="icon" asChild>
@@ -2109,11 +2336,15 @@ function VariableIdInput({ data, updateData, errors }: InputProps) {
 
 function VariableScopeInput({ data, updateData, errors }: InputProps) {
   const variables = useVariables();
+  const sharedVariables = useSharedVariables();
 
   const scoped = useMemo(() => {
-    const variable = variables?.find((v) => v?.id === data.variable_id);
+    const variable =
+      variables?.find((v) => v?.id === data.variable_id) ||
+      sharedVariables?.find((v) => v?.variable?.id === data.variable_id)
+        ?.variable;
     return variable?.scoped;
-  }, [variables, data]);
+  }, [variables, sharedVariables, data]);
 
   useEffect(() => {
     if (scoped === false) {
@@ -2136,6 +2367,114 @@ function VariableScopeInput({ data, updateData, errors }: InputProps) {
   );
 }
 
+function VariableQueryDataInput({ data, updateData, errors }: InputProps) {
+  const mode = data.variable_query_data?.mode || "list";
+
+  return (
+    <>
+      <BaseInput
+        type="select"
+        field="variable_query_data.mode"
+        title="Result"
+        value={mode}
+        updateValue={(v) =>
+          updateData({
+            variable_query_data: {
+              ...data.variable_query_data,
+              mode: v || undefined,
+            },
+          })
+        }
+        options={[
+          { value: "list", label: "List of scopes and values" },
+          { value: "count", label: "Number of scopes" },
+          { value: "sum", label: "Sum of values" },
+          { value: "average", label: "Average of values" },
+          { value: "min", label: "Smallest value" },
+          { value: "max", label: "Largest value" },
+        ]}
+        errors={errors}
+      />
+      <BaseInput
+        type="text"
+        field="variable_query_data.scope_prefix"
+        title="Scope Prefix"
+        description="Only include scopes that start with this. Leave empty to include all scopes."
+        value={data.variable_query_data?.scope_prefix || ""}
+        updateValue={(v) =>
+          updateData({
+            variable_query_data: {
+              ...data.variable_query_data,
+              scope_prefix: v || undefined,
+            },
+          })
+        }
+        errors={errors}
+        placeholders
+      />
+      {mode === "list" && (
+        <>
+          <BaseInput
+            type="select"
+            field="variable_query_data.order"
+            title="Order"
+            value={data.variable_query_data?.order || "scope_asc"}
+            updateValue={(v) =>
+              updateData({
+                variable_query_data: {
+                  ...data.variable_query_data,
+                  order: v || undefined,
+                },
+              })
+            }
+            options={[
+              { value: "value_desc", label: "Highest value first" },
+              { value: "value_asc", label: "Lowest value first" },
+              { value: "scope_asc", label: "Scope (A-Z)" },
+              { value: "scope_desc", label: "Scope (Z-A)" },
+            ]}
+            errors={errors}
+          />
+          <BaseInput
+            type="text"
+            field="variable_query_data.limit"
+            title="Limit"
+            description="The maximum number of values to return, up to 100. Defaults to 10."
+            value={data.variable_query_data?.limit || ""}
+            updateValue={(v) =>
+              updateData({
+                variable_query_data: {
+                  ...data.variable_query_data,
+                  limit: v || undefined,
+                },
+              })
+            }
+            errors={errors}
+            placeholders
+          />
+          <BaseInput
+            type="text"
+            field="variable_query_data.offset"
+            title="Offset"
+            description="The number of values to skip, use this to show further pages."
+            value={data.variable_query_data?.offset || ""}
+            updateValue={(v) =>
+              updateData({
+                variable_query_data: {
+                  ...data.variable_query_data,
+                  offset: v || undefined,
+                },
+              })
+            }
+            errors={errors}
+            placeholders
+          />
+        </>
+      )}
+    </>
+  );
+}
+
 function VariableOperationInput({ data, updateData, errors }: InputProps) {
   return (
     <BaseInput
@@ -2150,12 +2489,76 @@ function VariableOperationInput({ data, updateData, errors }: InputProps) {
         { value: "prepend", label: "Prepend" },
         { value: "increment", label: "Increment" },
         { value: "decrement", label: "Decrement" },
+        { value: "multiply", label: "Multiply" },
+        { value: "min", label: "Minimum (keep smaller value)" },
+        { value: "max", label: "Maximum (keep larger value)" },
+        { value: "set_if_absent", label: "Set if not set" },
+        { value: "compare_and_swap", label: "Set if current value matches" },
+        { value: "push", label: "Add item to list" },
+        { value: "remove_item", label: "Remove item from list" },
+        { value: "set_key", label: "Set key of object" },
+        { value: "delete_key", label: "Delete key of object" },
       ]}
       errors={errors}
     />
   );
 }
 
+function VariableKeyInput({ data, updateData, errors }: InputProps) {
+  if (
+    data.variable_operation !== "set_key" &&
+    data.variable_operation !== "delete_key"
+  )
+    return null;
+
+  return (
+    <BaseInput
+      type="text"
+      field="variable_key"
+      title="Key"
+      value={data.variable_key || ""}
+      updateValue={(v) => updateData({ variable_key: v || undefined })}
+      errors={errors}
+      placeholders
+    />
+  );
+}
+
+function VariableExpectedInput({ data, updateData, errors }: InputProps) {
+  if (data.variable_operation !== "compare_and_swap") return null;
+
+  return (
+    <BaseInput
+      type="text"
+      field="variable_expected"
+      title="Expected Value"
+      description="The value is only set when the current value matches this. Use the set if absent operation to only set the value when it isn't set yet."
+      value={data.variable_expected || ""}
+      updateValue={(v) => updateData({ variable_expected: v || undefined })}
+      errors={errors}
+      placeholders
+    />
+  );
+}
+
+function VariableMaxLengthInput({ data, updateData, errors }: InputProps) {
+  if (data.variable_operation !== "push") return null;
+
+  return (
+    <BaseInput
+      type="text"
+      field="variable_max_length"
+      title="Maximum Length"
+      description="The oldest items are removed when the list gets longer than this."
+      value={data.variable_max_length?.toString() || ""}
+      updateValue={(v) =>
+        updateData({ variable_max_length: parseInt(v) || undefined })
+      }
+      errors={errors}
+    />
+  );
+}
+
 function VariableValueInput({ data, updateData, errors }: InputProps) {
   return (
     <BaseInput
@@ -104,6 +104,7 @@ const nodeCategories = {
         "action_variable_set",
         "action_variable_delete",
         "action_variable_get",
+        "action_variable_query",
       ],
       contextTypes: null,
     },
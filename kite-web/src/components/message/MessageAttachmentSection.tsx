@@ -3,6 +3,7 @@ import CollapsibleSection from "./MessageCollapsibleSection";
 import { useShallow } from "zustand/react/shallow";
 import { Button } from "../ui/button";
 import MessageAttachment from "./MessageAttachment";
+import MessageGeneratedAttachment from "./MessageGeneratedAttachment";
 import { ChangeEvent, useCallback, useRef } from "react";
 import { useAssetCreateMutation } from "@/lib/api/mutations";
 import { useAppId } from "@/lib/hooks/params";
@@ -53,9 +54,13 @@ export default function MessageAttachmentSection() {
       className="space-y-4"
     >
       <div className="flex flex-wrap gap-4">
-        {attachments.map((id, i) => (
-          <MessageAttachment key={id} attachmentIndex={i} assetId={id} />
-        ))}
+        {attachments.map((id, i) =>
+          id ? (
+            <MessageAttachment key={id} attachmentIndex={i} assetId={id} />
+          ) : (
+            <MessageGeneratedAttachment key={i} attachmentIndex={i} />
+          )
+        )}
       </div>
       <div className="space-x-3">
         <Button
@@ -64,6 +69,13 @@ export default function MessageAttachmentSection() {
         >
           Add Attachment
         </Button>
+        <Button
+          onClick={() => addAttachment({ content: "" })}
+          disabled={attachments.length >= 10}
+          variant="outline"
+        >
+          Add Generated File
+        </Button>
         <Button onClick={clearAttachments} variant="outline">
           Clear Attachments
         </Button>
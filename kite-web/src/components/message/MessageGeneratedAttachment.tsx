@@ -0,0 +1,59 @@
+import { useShallow } from "zustand/react/shallow";
+import { useCurrentMessage } from "@/lib/message/state";
+import { Card } from "../ui/card";
+import { Button } from "../ui/button";
+import { TrashIcon } from "lucide-react";
+import { useCallback } from "react";
+import MessageInput from "./MessageInput";
+
+export default function MessageGeneratedAttachment({
+  attachmentIndex,
+}: {
+  attachmentIndex: number;
+}) {
+  const [filename, setFilename] = useCurrentMessage(
+    useShallow((state) => [
+      state.attachments[attachmentIndex]?.filename,
+      state.setAttachmentFilename,
+    ])
+  );
+  const [content, setContent] = useCurrentMessage(
+    useShallow((state) => [
+      state.attachments[attachmentIndex]?.content,
+      state.setAttachmentContent,
+    ])
+  );
+  const deleteAttachment = useCurrentMessage((state) => state.deleteAttachment);
+
+  const remove = useCallback(() => {
+    deleteAttachment(attachmentIndex);
+  }, [deleteAttachment, attachmentIndex]);
+
+  return (
+    <Card className="p-3 space-y-3 w-full">
+      <div className="flex gap-2 items-end">
+        <MessageInput
+          type="text"
+          label="Filename"
+          maxLength={256}
+          value={filename || ""}
+          onChange={(v) => setFilename(attachmentIndex, v || undefined)}
+          validationPath={`attachments.${attachmentIndex}.filename`}
+          placeholders
+        />
+        <Button variant="outline" size="icon" className="flex-none h-8 w-8">
+          <TrashIcon className="h-4 w-4" onClick={remove} />
+        </Button>
+      </div>
+      <MessageInput
+        type="textarea"
+        label="Content"
+        description="Text, an HTTP response or message attachments, e.g. {{nodes.abc.result}} or {{event.message.attachments}}."
+        value={content || ""}
+        onChange={(v) => setContent(attachmentIndex, v)}
+        validationPath={`attachments.${attachmentIndex}.content`}
+        placeholders
+      />
+    </Card>
+  );
+}
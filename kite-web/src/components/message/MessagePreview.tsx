@@ -110,14 +110,16 @@ export default function MessagePreview({
           );
         })}
 
-        {msg.attachments.length != 0 && (
+        {msg.attachments.some((a) => a.asset_id) && (
           <DiscordAttachments slot="attachments">
-            {msg.attachments.map((attachment) => (
-              <MessagePreviewAttachment
-                key={attachment.asset_id}
-                assetId={attachment.asset_id}
-              />
-            ))}
+            {msg.attachments.map((attachment) =>
+              attachment.asset_id ? (
+                <MessagePreviewAttachment
+                  key={attachment.asset_id}
+                  assetId={attachment.asset_id}
+                />
+              ) : null
+            )}
           </DiscordAttachments>
         )}
 
@@ -1,4 +1,4 @@
-import { ReactNode, useCallback, useState } from "react";
+import { ReactNode, useCallback, useEffect, useState } from "react";
 import {
   Dialog,
   DialogClose,
@@ -12,14 +12,18 @@ import {
 import { Button } from "../ui/button";
 import LoadingButton from "../common/LoadingButton";
 import { Separator } from "../ui/separator";
-import { useMessageInstances } from "@/lib/hooks/api";
+import { useMessageInstances, useMessageInstanceSync } from "@/lib/hooks/api";
 import GuildSelect from "../common/GuildSelect";
 import ChannelSelect from "../common/ChannelSelect";
 import MessageSendInstanceEntry from "./MessageSendInstanceEntry";
-import { useMessageInstanceCreateMutation } from "@/lib/api/mutations";
+import {
+  useMessageInstanceCreateMutation,
+  useMessageInstanceSyncStartMutation,
+} from "@/lib/api/mutations";
 import { useAppId, useMessageId } from "@/lib/hooks/params";
 import { toast } from "sonner";
 import { ScrollArea } from "../ui/scroll-area";
+import { useQueryClient } from "@tanstack/react-query";
 
 export default function MessageSendDialog({
   children,
@@ -36,6 +40,60 @@ export default function MessageSendDialog({
     useMessageId()
   );
 
+  const queryClient = useQueryClient();
+  const appId = useAppId();
+  const messageId = useMessageId();
+
+  // The ID of the sync job that has been started from this dialog
+  const [syncJobId, setSyncJobId] = useState<string | null>(null);
+  const syncing = syncJobId !== null;
+
+  const sync = useMessageInstanceSync(syncing ? 2000 : undefined);
+  const syncMutation = useMessageInstanceSyncStartMutation(appId, messageId);
+
+  useEffect(() => {
+    if (!sync || sync.id !== syncJobId) return;
+    if (sync.status !== "completed" && sync.status !== "failed") return;
+
+    setSyncJobId(null);
+    queryClient.invalidateQueries({
+      queryKey: ["apps", appId, "messages", messageId, "instances"],
+    });
+
+    if (sync.status === "failed") {
+      toast.error(`Failed to update message instances: ${sync.error}`);
+      return;
+    }
+
+    const failed = sync.results.filter((r) => r.status === "failed").length;
+    const deleted = sync.results.filter((r) => r.status === "deleted").length;
+    if (failed > 0) {
+      toast.error(`Failed to update ${failed} message instance(s)`);
+    } else {
+      toast.success(
+        deleted > 0
+          ? `Message instances updated, ${deleted} deleted message(s) have been removed!`
+          : "Message instances updated!"
+      );
+    }
+  }, [sync, syncJobId, queryClient, appId, messageId]);
+
+  const syncInstances = useCallback(() => {
+    if (syncMutation.isPending || syncing) return;
+
+    syncMutation.mutate(undefined, {
+      onSuccess(res) {
+        if (res.success) {
+          setSyncJobId(res.data.id);
+        } else {
+          toast.error(
+            `Failed to update message instances: ${res.error.message} (${res.error.code})`
+          );
+        }
+      },
+    });
+  }, [syncMutation, syncing]);
+
   const createInstance = useCallback(() => {
     if (createMutation.isPending || !guildId || !channelId) return;
 
@@ -104,7 +162,16 @@ export default function MessageSendDialog({
         </ScrollArea>
         <Separator />
 
-        <DialogFooter>
+        <DialogFooter className="sm:justify-between">
+          <LoadingButton
+            onClick={syncInstances}
+            loading={syncMutation.isPending || syncing}
+            variant="secondary"
+          >
+            {syncing && sync?.id === syncJobId
+              ? `Updating ${sync.results.length}/${instances?.length ?? 0}`
+              : "Update All"}
+          </LoadingButton>
           <DialogClose asChild>
             <Button variant="outline">Cancel</Button>
           </DialogClose>
@@ -41,6 +41,7 @@ import {
   MessageInstanceCreateRequest,
   MessageInstanceCreateResponse,
   MessageInstanceDeleteResponse,
+  MessageInstanceSyncStartResponse,
   MessageInstanceUpdateRequest,
   MessageInstanceUpdateResponse,
   MessagesImportRequest,
@@ -631,6 +632,28 @@ export function useMessageInstanceDeleteMutation(
   });
 }
 
+export function useMessageInstanceSyncStartMutation(
+  appId: string,
+  messageId: string
+) {
+  const client = useQueryClient();
+
+  return useMutation({
+    mutationFn: () =>
+      apiRequest<MessageInstanceSyncStartResponse>(
+        `/v1/apps/${appId}/messages/${messageId}/instances/sync`,
+        {
+          method: "POST",
+        }
+      ),
+    onSuccess: () => {
+      client.invalidateQueries({
+        queryKey: ["apps", appId, "messages", messageId, "instances"],
+      });
+    },
+  });
+}
+
 export function useAppStateGuildLeaveMutation(appId: string) {
   const client = useQueryClient();
 
@@ -18,10 +18,12 @@ import {
   LogSummaryGetResponse,
   MessageGetResponse,
   MessageInstanceListResponse,
+  MessageInstanceSyncGetResponse,
   MessageListResponse,
   PluginInstanceGetResponse,
   PluginInstanceListResponse,
   PluginListResponse,
+  SharedVariableListResponse,
   StateGuildChannelListResponse,
   StateGuildListResponse,
   StateStatusGetResponse,
@@ -193,6 +195,17 @@ export function useVariablesQuery(appId: string) {
   });
 }
 
+export function useSharedVariablesQuery(appId: string) {
+  return useQuery({
+    queryKey: ["apps", appId, "shared-variables"],
+    queryFn: () =>
+      apiRequest<SharedVariableListResponse>(
+        `/v1/apps/${appId}/shared-variables`
+      ),
+    enabled: !!appId,
+  });
+}
+
 export function useVariableQuery(appId: string, variableId: string) {
   return useQuery({
     queryKey: ["apps", appId, "variables", variableId],
@@ -233,6 +246,22 @@ export function useMessageInstancesQuery(appId: string, messageId: string) {
   });
 }
 
+export function useMessageInstanceSyncQuery(
+  appId: string,
+  messageId: string,
+  refetchInterval?: number
+) {
+  return useQuery({
+    queryKey: ["apps", appId, "messages", messageId, "instances", "sync"],
+    queryFn: () =>
+      apiRequest<MessageInstanceSyncGetResponse>(
+        `/v1/apps/${appId}/messages/${messageId}/instances/sync`
+      ),
+    enabled: !!appId && !!messageId,
+    refetchInterval,
+  });
+}
+
 export function useAssetQuery(appId: string, assetId: string) {
   return useQuery({
     queryKey: ["apps", appId, "assets", assetId],
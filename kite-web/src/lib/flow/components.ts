@@ -16,7 +16,7 @@ import FlowNodeConditionChannel from "@/components/flow/FlowNodeConditionChannel
 import FlowNodeConditionRole from "@/components/flow/FlowNodeConditionRole";
 import FlowNodeControlSleep from "@/components/flow/FlowNodeControlSleep";
 import FlowNodeEntryComponentButton from "@/components/flow/FlowNodeEntryComponentButton";
-import FlowNodeSuspendBase from "@/components/flow/FlowNodeSuspendBase";
+import FlowNodeSuspendResponseModal from "@/components/flow/FlowNodeSuspendResponseModal";
 import FlowNodeActionMessage from "@/components/flow/FlowNodeActionMessage";
 import FlowNodeBase from "@/components/flow/FlowNodeBase";
 import FlowNodeControlErrorHandler from "@/components/flow/FlowNodeControlErrorHandler";
@@ -65,6 +65,7 @@ export const nodeTypes = {
   action_variable_set: FlowNodeActionBase,
   action_variable_delete: FlowNodeActionBase,
   action_variable_get: FlowNodeActionBase,
+  action_variable_query: FlowNodeActionBase,
   action_http_request: FlowNodeActionBase,
   action_ai_chat_completion: FlowNodeActionBase,
   action_ai_web_search: FlowNodeActionBase,
@@ -88,7 +89,7 @@ export const nodeTypes = {
   control_loop_exit: FlowNodeControlLoopExit,
   control_sleep: FlowNodeControlSleep,
 
-  suspend_response_modal: FlowNodeSuspendBase,
+  suspend_response_modal: FlowNodeSuspendResponseModal,
 };
 
 export const edgeTypes = {
@@ -184,14 +184,47 @@ export const nodeSuspendResponseModalDataSchema = nodeBaseDataSchema.extend({
           components: z
             .array(
               z.object({
+                type: z
+                  .union([
+                    z.literal(3),
+                    z.literal(4),
+                    z.literal(5),
+                    z.literal(6),
+                    z.literal(7),
+                    z.literal(8),
+                    z.literal(19),
+                  ])
+                  .optional(),
                 custom_id: z.string().max(100).min(1),
                 label: z.string().max(45).min(1),
-                style: z.literal(1).or(z.literal(2)),
+                description: z.string().max(100).min(1).optional(),
+                style: z.literal(1).or(z.literal(2)).optional(),
                 required: z.boolean().optional(),
                 min_length: z.number().optional(),
                 max_length: z.number().optional(),
                 value: z.string().max(4000).min(1).optional(),
                 placeholder: z.string().max(4000).min(1).optional(),
+                min_values: z.number().min(0).max(25).optional(),
+                max_values: z.number().min(1).max(25).optional(),
+                options: z
+                  .array(
+                    z.object({
+                      label: z.string().max(100).min(1),
+                      value: z.string().max(100).min(1),
+                      description: z.string().max(100).min(1).optional(),
+                      default: z.boolean().optional(),
+                    })
+                  )
+                  .max(25)
+                  .optional(),
+                validation: z
+                  .object({
+                    pattern: z.string().max(1000).optional(),
+                    min: z.number().nullable().optional(),
+                    max: z.number().nullable().optional(),
+                    error_message: z.string().max(1000).optional(),
+                  })
+                  .optional(),
               })
             )
             .min(1)
@@ -553,17 +586,40 @@ export const nodeActionRobloxUserGetDataSchema = nodeBaseDataSchema.extend({
   custom_label: z.string().optional(),
 });
 
-export const nodeActionVariableSetSchema = nodeBaseDataSchema.extend({
-  variable_id: z.string(),
-  variable_scope: z.string().optional(),
-  variable_value: z.string(),
-  variable_operation: z
-    .literal("overwrite")
-    .or(z.literal("append"))
-    .or(z.literal("prepend"))
-    .or(z.literal("increment"))
-    .or(z.literal("decrement")),
-});
+export const nodeActionVariableSetSchema = nodeBaseDataSchema
+  .extend({
+    variable_id: z.string(),
+    variable_scope: z.string().optional(),
+    variable_value: z.string(),
+    variable_operation: z
+      .literal("overwrite")
+      .or(z.literal("append"))
+      .or(z.literal("prepend"))
+      .or(z.literal("increment"))
+      .or(z.literal("decrement"))
+      .or(z.literal("multiply"))
+      .or(z.literal("min"))
+      .or(z.literal("max"))
+      .or(z.literal("set_if_absent"))
+      .or(z.literal("compare_and_swap"))
+      .or(z.literal("push"))
+      .or(z.literal("remove_item"))
+      .or(z.literal("set_key"))
+      .or(z.literal("delete_key")),
+    variable_key: z.string().optional(),
+    variable_expected: z.string().optional(),
+    variable_max_length: z.number().int().min(0).optional(),
+  })
+  .refine(
+    (data) =>
+      data.variable_operation !== "compare_and_swap" ||
+      !!data.variable_expected,
+    {
+      message:
+        "An expected value is required, use set if absent to only set missing values",
+      path: ["variable_expected"],
+    }
+  );
 
 export const nodeActionVariableDeleteSchema = nodeBaseDataSchema.extend({
   variable_id: z.string(),
@@ -575,6 +631,29 @@ export const nodeActionVariableGetSchema = nodeBaseDataSchema.extend({
   variable_scope: z.string().optional(),
 });
 
+export const nodeActionVariableQuerySchema = nodeBaseDataSchema.extend({
+  variable_id: z.string(),
+  variable_query_data: z.object({
+    mode: z
+      .literal("list")
+      .or(z.literal("count"))
+      .or(z.literal("sum"))
+      .or(z.literal("average"))
+      .or(z.literal("min"))
+      .or(z.literal("max"))
+      .optional(),
+    scope_prefix: z.string().optional(),
+    order: z
+      .literal("value_desc")
+      .or(z.literal("value_asc"))
+      .or(z.literal("scope_asc"))
+      .or(z.literal("scope_desc"))
+      .optional(),
+    limit: z.string().optional(),
+    offset: z.string().optional(),
+  }),
+});
+
 export const nodeActionHttpRequestDataSchema = nodeBaseDataSchema.extend({
   http_request_data: z.object({
     url: z.string().url(),
@@ -43,6 +43,7 @@ import {
   nodeActionUserGetDataSchema,
   nodeActionVariableDeleteSchema,
   nodeActionVariableGetSchema,
+  nodeActionVariableQuerySchema,
   nodeActionVariableSetSchema,
   nodeConditionCompareDataSchema,
   nodeConditionItemCompareDataSchema,
@@ -556,6 +557,9 @@ export const nodeTypes: Record<string, NodeValues> = {
       "variable_scope",
       "variable_operation",
       "variable_value",
+      "variable_key",
+      "variable_expected",
+      "variable_max_length",
       "temporary_name",
       "custom_label",
     ],
@@ -584,6 +588,21 @@ export const nodeTypes: Record<string, NodeValues> = {
     ],
     creditsCost: 1,
   },
+  action_variable_query: {
+    color: actionColor,
+    icon: "variable",
+    defaultTitle: "Query stored variable",
+    defaultDescription:
+      "List, count or sum up the values of a scoped variable, for example to build a leaderboard",
+    dataSchema: nodeActionVariableQuerySchema,
+    dataFields: [
+      "variable_id",
+      "variable_query_data",
+      "temporary_name",
+      "custom_label",
+    ],
+    creditsCost: 1,
+  },
   action_http_request: {
     color: actionColor,
     icon: "webhook",
@@ -855,7 +874,7 @@ export const nodeTypes: Record<string, NodeValues> = {
     icon: "picture-in-picture-2",
     defaultTitle: "Show Modal",
     defaultDescription:
-      "Show a modal to the user and suspend the flow until the user submits the modal.",
+      "Show a modal to the user and suspend the flow until the user submits the modal. Inputs that don't pass validation continue at the invalid handle.",
     dataSchema: nodeSuspendResponseModalDataSchema,
     dataFields: ["modal_data", "custom_label"],
   },
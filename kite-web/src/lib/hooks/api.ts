@@ -17,6 +17,7 @@ import {
   useAppFeaturesQuery,
   useLogSummaryQuery,
   useMessageInstancesQuery,
+  useMessageInstanceSyncQuery,
   useMessageQuery,
   useMessagesQuery,
   useUsageCreditsByDayQuery,
@@ -25,6 +26,7 @@ import {
   useUserQuery,
   useVariableQuery,
   useVariablesQuery,
+  useSharedVariablesQuery,
   usePluginsQuery,
   usePluginInstanceQuery,
   usePluginInstancesQuery,
@@ -45,10 +47,12 @@ import {
   LogSummaryGetResponse,
   MessageGetResponse,
   MessageInstanceListResponse,
+  MessageInstanceSyncGetResponse,
   MessageListResponse,
   PluginInstanceGetResponse,
   PluginInstanceListResponse,
   PluginListResponse,
+  SharedVariableListResponse,
   StateGuildChannelListResponse,
   StateGuildListResponse,
   SubscriptionListResponse,
@@ -168,6 +172,15 @@ export function useVariables(
   return useResponseData(query, callback);
 }
 
+export function useSharedVariables(
+  callback?: (res: APIResponse<SharedVariableListResponse>) => void
+) {
+  const router = useRouter();
+
+  const query = useSharedVariablesQuery(router.query.appId as string);
+  return useResponseData(query, callback);
+}
+
 export function useVariable(
   callback?: (res: APIResponse<VariableGetResponse>) => void
 ) {
@@ -213,6 +226,20 @@ export function useMessageInstances(
   return useResponseData(query, callback);
 }
 
+export function useMessageInstanceSync(
+  refetchInterval?: number,
+  callback?: (res: APIResponse<MessageInstanceSyncGetResponse>) => void
+) {
+  const router = useRouter();
+
+  const query = useMessageInstanceSyncQuery(
+    router.query.appId as string,
+    router.query.messageId as string,
+    refetchInterval
+  );
+  return useResponseData(query, callback);
+}
+
 export function useLogSummary(
   callback?: (res: APIResponse<LogSummaryGetResponse>) => void
 ) {
@@ -27,6 +27,8 @@ export interface MessageStore extends Message {
   addAttachment: (attachment: MessageAttachment) => void;
   clearAttachments: () => void;
   deleteAttachment: (i: number) => void;
+  setAttachmentFilename: (i: number, filename: string | undefined) => void;
+  setAttachmentContent: (i: number, content: string) => void;
   addEmbed: (embed: MessageEmbed) => void;
   clearEmbeds: () => void;
   moveEmbedDown: (i: number) => void;
@@ -169,6 +171,22 @@ export const createMessageStore = (initial?: Message) => {
               }
               state.attachments.splice(i, 1);
             }),
+          setAttachmentFilename: (i: number, filename: string | undefined) =>
+            set((state) => {
+              const attachment = state.attachments && state.attachments[i];
+              if (!attachment) {
+                return;
+              }
+              attachment.filename = filename;
+            }),
+          setAttachmentContent: (i: number, content: string) =>
+            set((state) => {
+              const attachment = state.attachments && state.attachments[i];
+              if (!attachment) {
+                return;
+              }
+              attachment.content = content;
+            }),
           addEmbed: (embed: MessageEmbed) =>
             set((state) => {
               if (!state.embeds) {
@@ -339,9 +339,16 @@ export const messageAllowedMentionsSchema = z.optional(
 
 export const messageThreadNameSchema = z.optional(z.string().max(100));
 
-export const attachmentSchema = z.object({
-  asset_id: z.string(),
-});
+export const attachmentSchema = z
+  .object({
+    asset_id: z.string().optional(),
+    filename: z.string().max(256).optional(),
+    content: z.string().optional(),
+  })
+  .refine((a) => !!a.asset_id || !!a.content, {
+    message: "Generated files must have content",
+    path: ["content"],
+  });
 
 export type MessageAttachment = z.infer<typeof attachmentSchema>;
 
@@ -400,7 +400,9 @@ export const messageAllowedMentionsSchema = z.preprocess(
 export const messageThreadNameSchema = z.optional(z.string());
 
 export const attachmentSchema = z.object({
-  asset_id: z.preprocess((d) => d ?? undefined, z.string().default("")),
+  asset_id: z.preprocess((d) => d ?? undefined, z.string().optional()),
+  filename: z.preprocess((d) => d ?? undefined, z.string().optional()),
+  content: z.preprocess((d) => d ?? undefined, z.string().optional()),
 });
 
 export type MessageAttachment = z.infer<typeof attachmentSchema>;
@@ -16,6 +16,7 @@ export const FlowNodeTypeEntryComponentButton: FlowNodeType = "entry_component_b
 export const FlowNodeTypeOptionCommandArgument: FlowNodeType = "option_command_argument";
 export const FlowNodeTypeOptionCommandPermissions: FlowNodeType = "option_command_permissions";
 export const FlowNodeTypeOptionCommandContexts: FlowNodeType = "option_command_contexts";
+export const FlowNodeTypeOptionCommandCooldown: FlowNodeType = "option_command_cooldown";
 export const FlowNodeTypeOptionEventFilter: FlowNodeType = "option_event_filter";
 export const FlowNodeTypeActionResponseCreate: FlowNodeType = "action_response_create";
 export const FlowNodeTypeActionResponseEdit: FlowNodeType = "action_response_edit";
@@ -51,12 +52,15 @@ export const FlowNodeTypeActionRobloxUserGet: FlowNodeType = "action_roblox_user
 export const FlowNodeTypeActionHTTPRequest: FlowNodeType = "action_http_request";
 export const FlowNodeTypeActionAIChatCompletion: FlowNodeType = "action_ai_chat_completion";
 export const FlowNodeTypeActionAISearchWeb: FlowNodeType = "action_ai_web_search";
+export const FlowNodeTypeActionAIModeration: FlowNodeType = "action_ai_moderation";
 export const FlowNodeTypeActionExpressionEvaluate: FlowNodeType = "action_expression_evaluate";
 export const FlowNodeTypeActionRandomGenerate: FlowNodeType = "action_random_generate";
 export const FlowNodeTypeActionLog: FlowNodeType = "action_log";
 export const FlowNodeTypeActionVariableSet: FlowNodeType = "action_variable_set";
 export const FlowNodeTypeActionVariableDelete: FlowNodeType = "action_variable_delete";
 export const FlowNodeTypeActionVariableGet: FlowNodeType = "action_variable_get";
+export const FlowNodeTypeActionVariableQuery: FlowNodeType = "action_variable_query";
+export const FlowNodeTypeActionModuleCall: FlowNodeType = "action_module_call";
 export const FlowNodeTypeControlConditionCompare: FlowNodeType = "control_condition_compare";
 export const FlowNodeTypeControlConditionItemCompare: FlowNodeType = "control_condition_item_compare";
 export const FlowNodeTypeControlConditionUser: FlowNodeType = "control_condition_user";
@@ -112,6 +116,10 @@ export interface FlowNodeData {
    * Command Installations
    */
   command_disabled_integrations?: CommandDisabledIntegrationType[];
+  /**
+   * Command Cooldown
+   */
+  command_cooldown_data?: CommandCooldownData;
   /**
    * Guild Get
    */
@@ -160,6 +168,16 @@ export interface FlowNodeData {
   variable_scope?: string;
   variable_value?: string;
   variable_operation?: any /* provider.VariableOperation */;
+  /**
+   * Variable Set with the set_key, delete_key, compare_and_swap or push operation
+   */
+  variable_key?: string;
+  variable_expected?: string;
+  variable_max_length?: number /* int */;
+  /**
+   * Variable Query
+   */
+  variable_query_data?: VariableQueryData;
   /**
    * HTTP Request
    */
@@ -168,6 +186,14 @@ export interface FlowNodeData {
    * AI Chat Completion
    */
   ai_chat_completion_data?: AIChatCompletionData;
+  /**
+   * Module Call
+   */
+  module_call_data?: ModuleCallData;
+  /**
+   * AI Moderation
+   */
+  ai_moderation_data?: AIModerationData;
   /**
    * Random Generate
    */
@@ -250,6 +276,24 @@ export const EventFilterTypeChannelID: EventFilterTarget = "channel_id";
 export type RobloxLookupType = string;
 export const RobloxLookupTypeID: RobloxLookupType = "id";
 export const RobloxLookupTypeName: RobloxLookupType = "username";
+export type CommandCooldownBucket = string;
+export const CommandCooldownBucketUser: CommandCooldownBucket = "user";
+export const CommandCooldownBucketMember: CommandCooldownBucket = "member";
+export const CommandCooldownBucketChannel: CommandCooldownBucket = "channel";
+export const CommandCooldownBucketGuild: CommandCooldownBucket = "guild";
+export const CommandCooldownBucketGlobal: CommandCooldownBucket = "global";
+export interface CommandCooldownData {
+  bucket?: CommandCooldownBucket;
+  duration_seconds?: number /* int */;
+  /**
+   * Limit is the number of uses allowed per window, 0 or 1 means a simple cooldown.
+   */
+  limit?: number /* int */;
+  /**
+   * Message is sent as an ephemeral response when the cooldown is active.
+   */
+  message?: string;
+}
 export interface CommandArgumentChoiceData {
   name?: string;
   value?: string;
@@ -297,15 +341,52 @@ export interface ModalData {
   components?: ModalComponentData[];
 }
 export interface ModalComponentData {
+  /**
+   * Type is one of the message component types that can be used in modals, text inputs are used by default.
+   */
+  type?: number /* int */;
   custom_id?: string;
   style?: number /* int */;
   label?: string;
+  description?: string;
   min_length?: number /* int */;
   max_length?: number /* int */;
   required?: boolean;
   value?: string;
   placeholder?: string;
   components?: ModalComponentData[];
+  /**
+   * Select Menu and File Upload
+   */
+  min_values?: number /* int */;
+  max_values?: number /* int */;
+  options?: ModalSelectOptionData[];
+  validation?: ModalComponentValidationData;
+}
+export interface ModalSelectOptionData {
+  label?: string;
+  value?: string;
+  description?: string;
+  default?: boolean;
+}
+/**
+ * ModalComponentValidationData contains rules that the submitted value of a text input must meet.
+ * If a rule isn't met the flow continues with the "invalid" handle of the modal node.
+ */
+export interface ModalComponentValidationData {
+  /**
+   * Pattern is a regular expression that must match the value.
+   */
+  pattern?: string;
+  /**
+   * Min and Max require the value to be a number in the range.
+   */
+  min?: null | number /* float64 */;
+  max?: null | number /* float64 */;
+  /**
+   * ErrorMessage replaces the default error message.
+   */
+  error_message?: string;
 }
 export interface HTTPRequestData {
   url?: string;
@@ -318,12 +399,49 @@ export interface HTTPRequestDataKeyValue {
   key: string;
   value: string;
 }
+export type VariableQueryMode = string;
+export const VariableQueryModeList: VariableQueryMode = "list";
+export const VariableQueryModeCount: VariableQueryMode = "count";
+export const VariableQueryModeSum: VariableQueryMode = "sum";
+export const VariableQueryModeAverage: VariableQueryMode = "average";
+export const VariableQueryModeMin: VariableQueryMode = "min";
+export const VariableQueryModeMax: VariableQueryMode = "max";
+export interface VariableQueryData {
+  /**
+   * Mode defaults to list, which returns an array of {scope, value} objects.
+   * All other modes return a single number.
+   */
+  mode?: VariableQueryMode;
+  scope_prefix?: string;
+  order?: any /* provider.VariableQueryOrder */;
+  limit?: string;
+  offset?: string;
+}
+export interface ModuleCallData {
+  module_id?: string;
+  function?: string;
+  /**
+   * Input is evaluated as a template and passed to the function, it's encoded as a JSON string if it isn't valid JSON.
+   */
+  input?: string;
+}
 export interface AIChatCompletionData {
   model?: string;
   system_prompt?: string;
   prompt?: string;
   max_completion_tokens?: string;
 }
+export interface AIModerationData {
+  text?: string;
+  /**
+   * ImageURLs can contain templates that evaluate to a single URL or an array of URLs / attachments.
+   */
+  image_urls?: string[];
+  /**
+   * Threshold overrides the provider's flagging when set. Content is flagged if any category score reaches it.
+   */
+  threshold?: string;
+}
 export interface FlowNodePosition {
   x: number /* float64 */;
   y: number /* float64 */;
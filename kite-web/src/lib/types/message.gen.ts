@@ -3,6 +3,26 @@
 //////////
 // source: data.go
 
+export const ComponentTypeActionRow = 1;
+export const ComponentTypeButton = 2;
+export const ComponentTypeStringSelect = 3;
+export const ComponentTypeTextInput = 4;
+export const ComponentTypeUserSelect = 5;
+export const ComponentTypeRoleSelect = 6;
+export const ComponentTypeMentionableSelect = 7;
+export const ComponentTypeChannelSelect = 8;
+export const ComponentTypeSection = 9;
+export const ComponentTypeTextDisplay = 10;
+export const ComponentTypeThumbnail = 11;
+export const ComponentTypeMediaGallery = 12;
+export const ComponentTypeSeparator = 14;
+export const ComponentTypeContainer = 17;
+export const ComponentTypeLabel = 18;
+export const ComponentTypeFileUpload = 19;
+/**
+ * MessageFlagIsComponentsV2 tells Discord that the message uses layout components instead of content and embeds.
+ */
+export const MessageFlagIsComponentsV2 = 1 << 15;
 export interface MessageData {
   content?: string;
   flags?: number /* int */;
@@ -10,9 +30,25 @@ export interface MessageData {
   embeds?: EmbedData[];
   components?: ComponentRowData[];
   allowed_mentions?: AllowedMentionsData;
+  /**
+   * LayoutComponents replace the content, embeds and components of the message when set.
+   */
+  layout_components?: LayoutComponentData[];
 }
+/**
+ * MessageAttachment is either an uploaded asset or a file that is generated when the message is sent.
+ */
 export interface MessageAttachment {
   asset_id?: string;
+  /**
+   * Filename is the name of the generated file, attachments of Discord messages keep their name by default.
+   */
+  filename?: string;
+  /**
+   * Content is a template that evaluates to the content of the generated file.
+   * It can be text, an HTTP response or one or more attachments of a Discord message.
+   */
+  content?: string;
 }
 export interface EmbedData {
   id?: number /* int */;
@@ -88,3 +124,56 @@ export interface ComponentEmojiData {
 export interface AllowedMentionsData {
   parse?: string[];
 }
+/**
+ * LayoutComponentData is one of the layout components that can be used instead of content and embeds.
+ * Only the fields of the component type are used.
+ */
+export interface LayoutComponentData {
+  id?: number /* int */;
+  type?: number /* int */;
+  /**
+   * Action Row
+   */
+  components?: ComponentData[];
+  /**
+   * Section (text displays only) and Container
+   */
+  children?: LayoutComponentData[];
+  /**
+   * Section
+   */
+  accessory?: SectionAccessoryData;
+  /**
+   * Text Display
+   */
+  content?: string;
+  /**
+   * Media Gallery
+   */
+  items?: MediaGalleryItemData[];
+  /**
+   * Separator
+   */
+  hide_divider?: boolean;
+  spacing?: number /* int */;
+  /**
+   * Container
+   */
+  accent_color?: number /* int */;
+  spoiler?: boolean;
+}
+export interface SectionAccessoryData {
+  button?: ComponentData;
+  thumbnail?: ThumbnailData;
+}
+export interface ThumbnailData {
+  url?: string;
+  description?: string;
+  spoiler?: boolean;
+}
+export interface MediaGalleryItemData {
+  id?: number /* int */;
+  url?: string;
+  description?: string;
+  spoiler?: boolean;
+}
@@ -3,6 +3,11 @@
 //////////
 // source: config.go
 
+/**
+ * ConfigKeyGuilds is the key of the per-guild overrides in the config values.
+ * The overrides are a map from guild ID to config values and can only contain fields of per-guild sections.
+ */
+export const ConfigKeyGuilds = "guilds";
 export type ConfigValues = { [key: string]: Record<string, any> | null};
 export interface Config {
   sections: ConfigSection[];
@@ -11,6 +16,10 @@ export interface ConfigSection {
   name: string;
   description: string;
   fields: ConfigField[];
+  /**
+   * PerGuild allows the fields of the section to be overridden for each guild.
+   */
+  per_guild: boolean;
 }
 export interface ConfigField {
   key: string;
@@ -19,6 +28,26 @@ export interface ConfigField {
   name: string;
   description: string;
   required: boolean;
+  /**
+   * Default is used when no value has been configured for the field.
+   */
+  default?: any;
+  /**
+   * Min is the minimum value for numbers and the minimum length for strings and arrays.
+   */
+  min?: number /* float64 */;
+  /**
+   * Max is the maximum value for numbers and the maximum length for strings and arrays.
+   */
+  max?: number /* float64 */;
+  /**
+   * Options restricts the value, or the items of an array, to one of the given options.
+   */
+  options?: ConfigFieldOption[];
+}
+export interface ConfigFieldOption {
+  label: string;
+  value: any;
 }
 export type ConfigFieldType = string;
 export const ConfigFieldTypeString: ConfigFieldType = "string";
@@ -26,6 +55,22 @@ export const ConfigFieldTypeInt: ConfigFieldType = "int";
 export const ConfigFieldTypeBool: ConfigFieldType = "bool";
 export const ConfigFieldTypeFloat: ConfigFieldType = "float";
 export const ConfigFieldTypeArray: ConfigFieldType = "array";
+/**
+ * ConfigFieldTypeDiscordChannel is the ID of a channel as a string.
+ */
+export const ConfigFieldTypeDiscordChannel: ConfigFieldType = "discord_channel";
+/**
+ * ConfigFieldTypeDiscordRole is the ID of a role as a string.
+ */
+export const ConfigFieldTypeDiscordRole: ConfigFieldType = "discord_role";
+/**
+ * ConfigFieldTypeDiscordEmoji is a unicode emoji or a custom emoji in the format <:name:id>.
+ */
+export const ConfigFieldTypeDiscordEmoji: ConfigFieldType = "discord_emoji";
+/**
+ * ConfigFieldTypeMessageTemplate is the ID of a message template of the app.
+ */
+export const ConfigFieldTypeMessageTemplate: ConfigFieldType = "message_template";
 
 //////////
 // source: plugin.go
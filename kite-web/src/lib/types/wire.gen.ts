@@ -239,6 +239,10 @@ export type CommandListResponse = (Command | undefined)[];
 export interface CommandCreateRequest {
   flow_source: FlowData;
   enabled: boolean;
+  /**
+   * ModuleID is the module that handles the command instead of its flow.
+   */
+  module_id: null | string;
 }
 export type CommandCreateResponse = Command;
 export interface CommandsImportRequest {
@@ -248,6 +252,7 @@ export type CommandsImportResponse = (Command | undefined)[];
 export interface CommandUpdateRequest {
   flow_source: FlowData;
   enabled: boolean;
+  module_id: null | string;
 }
 export type CommandUpdateResponse = Command;
 export interface CommandUpdateEnabledRequest {
@@ -372,6 +377,10 @@ export interface MessageUpdateRequest {
   description: null | string;
   data: MessageData;
   flow_sources: { [key: string]: FlowData};
+  /**
+   * SyncInstances re-renders and edits all instances of the message in the background
+   */
+  sync_instances: boolean;
 }
 export type MessageUpdateResponse = Message;
 export type MessageDeleteResponse = Empty;
@@ -395,6 +404,116 @@ export interface MessageInstanceUpdateRequest {
 }
 export type MessageInstanceUpdateResponse = MessageInstance;
 export type MessageInstanceDeleteResponse = Empty;
+export interface MessageSyncJob {
+  id: string;
+  message_id: string;
+  status: string;
+  error: null | string;
+  results: MessageInstanceSyncResult[];
+  created_at: string /* RFC3339 */;
+  started_at: null | string /* RFC3339 */;
+  completed_at: null | string /* RFC3339 */;
+}
+export interface MessageInstanceSyncResult {
+  instance_id: number /* uint64 */;
+  discord_guild_id: string;
+  discord_channel_id: string;
+  status: string;
+  error: null | string;
+}
+export type MessageInstanceSyncStartResponse = MessageSyncJob;
+export type MessageInstanceSyncGetResponse = MessageSyncJob;
+
+//////////
+// source: module.go
+
+export interface Module {
+  id: string;
+  name: string;
+  description: string;
+  enabled: boolean;
+  app_id: string;
+  creator_user_id: string;
+  wasm_hash: string;
+  wasm_size: number /* int */;
+  created_at: string /* RFC3339 */;
+  updated_at: string /* RFC3339 */;
+}
+export type ModuleGetResponse = Module;
+export type ModuleListResponse = (Module | undefined)[];
+/**
+ * ModuleCreateResponse is returned after uploading a module as multipart form with the fields "file", "name" and "description".
+ */
+export type ModuleCreateResponse = Module;
+export interface ModuleUpdateRequest {
+  name: string;
+  description: string;
+  enabled: boolean;
+}
+export type ModuleUpdateResponse = Module;
+/**
+ * ModuleWasmUpdateResponse is returned after uploading a new binary as multipart form with the field "file".
+ */
+export type ModuleWasmUpdateResponse = Module;
+export type ModuleDeleteResponse = Empty;
+
+//////////
+// source: namespace.go
+
+export interface Namespace {
+  id: string;
+  name: string;
+  description: null | string;
+  owner_user_id: string;
+  created_at: string /* RFC3339 */;
+  updated_at: string /* RFC3339 */;
+}
+export type NamespaceGetResponse = Namespace;
+export type NamespaceListResponse = (Namespace | undefined)[];
+export interface NamespaceCreateRequest {
+  name: string;
+  description: null | string;
+}
+export type NamespaceCreateResponse = Namespace;
+export interface NamespaceUpdateRequest {
+  name: string;
+  description: null | string;
+}
+export type NamespaceUpdateResponse = Namespace;
+export type NamespaceDeleteResponse = Empty;
+export interface NamespaceGrant {
+  namespace_id: string;
+  app_id: string;
+  access: string;
+  created_at: string /* RFC3339 */;
+  updated_at: string /* RFC3339 */;
+}
+export type NamespaceGrantListResponse = (NamespaceGrant | undefined)[];
+export interface NamespaceGrantUpdateRequest {
+  access: string;
+}
+export type NamespaceGrantUpdateResponse = NamespaceGrant;
+export type NamespaceGrantDeleteResponse = Empty;
+export interface NamespaceMember {
+  user: User;
+  created_at: string /* RFC3339 */;
+  updated_at: string /* RFC3339 */;
+}
+export type NamespaceMemberListResponse = (NamespaceMember | undefined)[];
+export interface NamespaceMemberCreateRequest {
+  discord_user_id: string;
+}
+export type NamespaceMemberCreateResponse = NamespaceMember;
+export type NamespaceMemberDeleteResponse = Empty;
+/**
+ * SharedVariable is a variable from a namespace that an app has been granted access to.
+ */
+export interface SharedVariable {
+  variable?: Variable;
+  namespace_name: string;
+  access: string;
+}
+export type SharedVariableListResponse = (SharedVariable | undefined)[];
 
 //////////
 // source: plugin.go
@@ -480,7 +599,12 @@ export interface Variable {
   name: string;
   scoped: boolean;
   app_id: string;
+  namespace_id: null | string;
   module_id: null | string;
+  type: string;
+  schema?: any /* thing.Schema */;
+  default_value: any;
+  value_ttl_seconds: number /* int */;
   total_values: null | number;
   created_at: string /* RFC3339 */;
   updated_at: string /* RFC3339 */;
@@ -490,6 +614,10 @@ export type VariableListResponse = (Variable | undefined)[];
 export interface VariableCreateRequest {
   name: string;
   scoped: boolean;
+  type: string;
+  schema: Record<string, any> | null;
+  default_value: any;
+  value_ttl_seconds: number /* int */;
 }
 export type VariableCreateResponse = Variable;
 export interface VariablesImportRequest {
@@ -499,6 +627,14 @@ export type VariablesImportResponse = (Variable | undefined)[];
 export interface VariableUpdateRequest {
   name: string;
   scoped: boolean;
+  type: string;
+  schema: Record<string, any> | null;
+  default_value: any;
+  value_ttl_seconds: number /* int */;
+  /**
+   * ResetValues confirms that all values are deleted when the type or the scoped flag changes.
+   */
+  reset_values: boolean;
 }
 export type VariableUpdateResponse = Variable;
 export type VariableDeleteResponse = Empty;
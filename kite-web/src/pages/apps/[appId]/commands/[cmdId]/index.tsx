@@ -55,6 +55,7 @@ export default function AppCommandPage() {
         {
           flow_source: data,
           enabled: true,
+          module_id: cmd?.module_id ?? null,
         },
         {
           onSuccess(res) {
@@ -74,7 +75,7 @@ export default function AppCommandPage() {
         }
       );
     },
-    [setHasUnsavedChanges, updateMutation]
+    [setHasUnsavedChanges, updateMutation, cmd]
   );
 
   const hasUndeployedChanges = useMemo(() => {
@@ -97,6 +97,7 @@ function AppMessagePageInner() {
         description: message.description,
         data: data,
         flow_sources: flowSources,
+        sync_instances: false,
       },
       {
         onSuccess(res) {